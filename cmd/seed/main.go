@@ -0,0 +1,43 @@
+// Command seed fills a local, dev or staging database with the embedded fixture dataset
+// (past, live and upcoming IPOs, GMP histories and cached allotment results) so the API
+// can be exercised without live scraping. Run it from the repository root:
+//
+//	go run ./cmd/seed
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/config"
+	"github.com/fenilmodi00/ipo-backend/database"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "database/schema.sql", "schema file applied before seeding")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+
+	if err := database.Connect(cfg.DatabaseURL); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(*schemaPath); err != nil {
+		log.Printf("Migration warning: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	summary, err := services.NewSeedService(database.DB).Seed(ctx, time.Now())
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	log.Printf("Seeded %d IPOs, %d GMP points and %d cached results",
+		summary.IPOs, summary.GMPPoints, summary.CachedResults)
+}
@@ -1,21 +1,88 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	ServerPort      string
-	DatabaseURL     string
-	AdminToken      string
-	CacheTTLHours   string
-	LogLevel        string
-	IPOAlertsAPIKey string
+	ServerPort           string
+	DatabaseURL          string
+	AdminToken           string
+	CacheTTLHours        string
+	LogLevel             string
+	IPOAlertsAPIKey      string
+	WebhookURLs          string
+	CheckRegistrarLimits string
+	CheckDailyLimit      string
+	HTTPHostBudgets      string
+	ResponseCacheTTL     string
+	ResponseMeta         string
+	QuoteCacheTTL        string
+	RetryPolicyHTTP      string
+	RetryPolicyDatabase  string
+	AlertSlackWebhookURL string
+	AlertEmailTo         string
+	AlertEmailFrom       string
+	AlertSMTPAddr        string
+	AlertSMTPUsername    string
+	AlertSMTPPassword    string
+	AlertThresholds      string
+	AlertCooldown        string
+	MaxRequestBodyBytes  string
+	PANVaultKey          string
+	AuthMagicLinkURL     string
+	AuthSessionTTL       string
+	AuthEmailFrom        string
+	AuthSMTPAddr         string
+	AuthSMTPUsername     string
+	AuthSMTPPassword     string
+	AuthSMSWebhookURL    string
+	SchemaRemediation    string
+	SchemaMigrationsDir  string
+	StartupPhaseTimeout  string
+	TelegramBotToken     string
+	TelegramHookSecret   string
+	TelegramChatLimit    string
+	ExportAPIKeys        string
+	CheckSandboxKeys     string
+	ExportRateLimit      string
+	IPOListMirrorURLs    string
+	ScraperHTTPCache     string
+	ScraperCacheDir      string
+	ScraperCacheMaxAge   string
+	ScraperShadow        string
+	ScraperImpl          string
+	DescriptionPreview   string
+	AboutPreview         string
+	GMPOutlierThreshold  string
+	SignedURLSecret      string
+	SignedURLTTL         string
+	CacheStoreKeys       string
+	FingerprintKey       string
+	ResultCheckMaxAge    string
+	MandateReminderLead  string
+	ResultWatchInterval  string
+	ResultWatchWindow    string
+	HTTPRecording        string
+	HTTPRecordingSize    string
+	AdminIPAllowlist     string
+	AdminClientIPHeader  string
+	AdminTrustedProxies  string
+	ScrapePoliteness     string
+	ScrapePolitePause    string
+	RedisURL             string
+	CacheShadowMode      string
+	JobDeadlines         string
+	JobDefaultDeadline   string
 }
 
 // SimplifiedRateLimitConfig holds simplified rate limiting configuration
@@ -61,6 +128,539 @@ func (c *Config) GetCacheTTL() time.Duration {
 	return time.Duration(hours) * time.Hour
 }
 
+// GetCheckRegistrarLimits parses CHECK_REGISTRAR_LIMITS into lower-cased registrar limits
+func (c *Config) GetCheckRegistrarLimits() map[string]int {
+	limits := make(map[string]int)
+	if c.CheckRegistrarLimits == "" {
+		return limits
+	}
+
+	for _, pair := range strings.Split(c.CheckRegistrarLimits, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit <= 0 {
+			logrus.Warnf("Invalid CHECK_REGISTRAR_LIMITS entry: %s", pair)
+			continue
+		}
+		limits[strings.ToLower(strings.TrimSpace(parts[0]))] = limit
+	}
+
+	return limits
+}
+
+// GetCheckDailyLimit returns the daily registrar lookups allowed per PAN and IPO
+func (c *Config) GetCheckDailyLimit() int {
+	limit, err := strconv.Atoi(c.CheckDailyLimit)
+	if err != nil || limit < 0 {
+		logrus.Warnf("Invalid CHECK_DAILY_LIMIT value: %s, using default 10", c.CheckDailyLimit)
+		return 10
+	}
+	return limit
+}
+
+// GetResponseCacheTTL returns how long public GET responses are cached
+func (c *Config) GetResponseCacheTTL() time.Duration {
+	seconds, err := strconv.Atoi(c.ResponseCacheTTL)
+	if err != nil || seconds <= 0 {
+		logrus.Warnf("Invalid RESPONSE_CACHE_TTL_SECONDS value: %s, using default 30 seconds", c.ResponseCacheTTL)
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetResponseMetaEnabled reports whether list responses carry a meta block
+func (c *Config) GetResponseMetaEnabled() bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(c.ResponseMeta))
+	if err != nil {
+		logrus.Warnf("Invalid RESPONSE_META value: %s, using default true", c.ResponseMeta)
+		return true
+	}
+	return enabled
+}
+
+// GetQuoteCacheTTL returns how long live market quotes for listed IPOs are cached
+func (c *Config) GetQuoteCacheTTL() time.Duration {
+	seconds, err := strconv.Atoi(c.QuoteCacheTTL)
+	if err != nil || seconds <= 0 {
+		logrus.Warnf("Invalid QUOTE_CACHE_TTL_SECONDS value: %s, using default 60 seconds", c.QuoteCacheTTL)
+		return time.Minute
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetMaxRequestBodyBytes returns the largest JSON body accepted on admin and check routes
+func (c *Config) GetMaxRequestBodyBytes() int {
+	size, err := strconv.Atoi(c.MaxRequestBodyBytes)
+	if err != nil || size <= 0 {
+		logrus.Warnf("Invalid MAX_REQUEST_BODY_BYTES value: %s, using default 65536 bytes", c.MaxRequestBodyBytes)
+		return 64 * 1024
+	}
+	return size
+}
+
+// GetPANVaultKey decodes PAN_VAULT_KEY (base64, 32 bytes). Returns nil, leaving the
+// saved PAN vault disabled, when the key is unset or invalid.
+func (c *Config) GetPANVaultKey() []byte {
+	if c.PANVaultKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.PANVaultKey)
+	if err != nil || len(key) != 32 {
+		logrus.Warn("Invalid PAN_VAULT_KEY: expected 32 bytes encoded as base64, PAN vault disabled")
+		return nil
+	}
+	return key
+}
+
+// GetHTTPHostBudgets parses HTTP_HOST_BUDGETS into per-host concurrent request limits
+func (c *Config) GetHTTPHostBudgets() map[string]int {
+	budgets := make(map[string]int)
+	if c.HTTPHostBudgets == "" {
+		return budgets
+	}
+
+	for _, pair := range strings.Split(c.HTTPHostBudgets, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit <= 0 {
+			logrus.Warnf("Invalid HTTP_HOST_BUDGETS entry: %s", pair)
+			continue
+		}
+		budgets[strings.ToLower(strings.TrimSpace(parts[0]))] = limit
+	}
+
+	return budgets
+}
+
+// GetRetryPolicies returns the default retry policies with RETRY_POLICY_HTTP and
+// RETRY_POLICY_DATABASE overrides applied, e.g. "attempts=5,base=500ms,max=10s,jitter=full"
+func (c *Config) GetRetryPolicies() shared.RetryPolicies {
+	policies := shared.DefaultRetryPolicies()
+
+	if policy, err := shared.ParseRetryPolicy(c.RetryPolicyHTTP, policies.HTTP); err != nil {
+		logrus.Warnf("Invalid RETRY_POLICY_HTTP value: %v, using defaults", err)
+	} else {
+		policies.HTTP = policy
+	}
+
+	if policy, err := shared.ParseRetryPolicy(c.RetryPolicyDatabase, policies.Database); err != nil {
+		logrus.Warnf("Invalid RETRY_POLICY_DATABASE value: %v, using defaults", err)
+	} else {
+		policies.Database = policy
+	}
+
+	return policies
+}
+
+// GetAlertThresholds parses ALERT_THRESHOLDS, e.g. "field_extraction=70,gmp_parse=80",
+// into success-rate percentages per alert metric. Unlisted metrics keep their defaults.
+func (c *Config) GetAlertThresholds() map[string]float64 {
+	thresholds := make(map[string]float64)
+	if c.AlertThresholds == "" {
+		return thresholds
+	}
+
+	for _, pair := range strings.Split(c.AlertThresholds, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || threshold < 0 || threshold > 100 {
+			logrus.Warnf("Invalid ALERT_THRESHOLDS entry: %s", pair)
+			continue
+		}
+		thresholds[strings.ToLower(strings.TrimSpace(parts[0]))] = threshold
+	}
+
+	return thresholds
+}
+
+// GetAlertCooldown returns the minimum time between repeated alerts for the same metric
+func (c *Config) GetAlertCooldown() time.Duration {
+	cooldown, err := time.ParseDuration(c.AlertCooldown)
+	if err != nil || cooldown <= 0 {
+		logrus.Warnf("Invalid ALERT_COOLDOWN value: %s, using default 1h", c.AlertCooldown)
+		return time.Hour
+	}
+	return cooldown
+}
+
+// GetAuthSessionTTL returns how long end-user login sessions stay valid
+func (c *Config) GetAuthSessionTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.AuthSessionTTL)
+	if err != nil || ttl <= 0 {
+		logrus.Warnf("Invalid AUTH_SESSION_TTL value: %s, using default 720h", c.AuthSessionTTL)
+		return 30 * 24 * time.Hour
+	}
+	return ttl
+}
+
+// GetSchemaRemediationMode returns the startup schema remediation mode: off, generate or apply
+func (c *Config) GetSchemaRemediationMode() string {
+	mode := strings.ToLower(strings.TrimSpace(c.SchemaRemediation))
+	switch mode {
+	case "off", "generate", "apply":
+		return mode
+	default:
+		logrus.Warnf("Invalid SCHEMA_REMEDIATION value: %s, using default off", c.SchemaRemediation)
+		return "off"
+	}
+}
+
+// GetStartupPhaseTimeout returns how long each startup phase (database readiness, schema
+// validation, cache warmup) may take before background jobs start
+func (c *Config) GetStartupPhaseTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.StartupPhaseTimeout)
+	if err != nil || timeout <= 0 {
+		logrus.Warnf("Invalid STARTUP_PHASE_TIMEOUT value: %s, using default 1m", c.StartupPhaseTimeout)
+		return time.Minute
+	}
+	return timeout
+}
+
+// GetTelegramChatLimit returns the bot commands allowed per chat per minute
+func (c *Config) GetTelegramChatLimit() int {
+	limit, err := strconv.Atoi(c.TelegramChatLimit)
+	if err != nil || limit <= 0 {
+		logrus.Warnf("Invalid TELEGRAM_CHAT_RATE_LIMIT value: %s, using default 10", c.TelegramChatLimit)
+		return 10
+	}
+	return limit
+}
+
+// GetExportAPIKeys returns the comma-separated keys accepted by the export API
+func (c *Config) GetExportAPIKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(c.ExportAPIKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetCheckSandboxKeys returns the comma-separated keys that put POST /check in sandbox mode
+func (c *Config) GetCheckSandboxKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(c.CheckSandboxKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetExportRateLimit returns the export requests allowed per API key per minute
+func (c *Config) GetExportRateLimit() int {
+	limit, err := strconv.Atoi(c.ExportRateLimit)
+	if err != nil || limit <= 0 {
+		logrus.Warnf("Invalid EXPORT_RATE_LIMIT value: %s, using default 5", c.ExportRateLimit)
+		return 5
+	}
+	return limit
+}
+
+// GetIPOListMirrorURLs returns the comma-separated mirrors of the IPO list API
+func (c *Config) GetIPOListMirrorURLs() []string {
+	var urls []string
+	for _, url := range strings.Split(c.IPOListMirrorURLs, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// GetScraperHTTPCacheDir returns the on-disk scraper HTTP cache directory, or "" when
+// SCRAPER_HTTP_CACHE is off
+func (c *Config) GetScraperHTTPCacheDir() string {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(c.ScraperHTTPCache))
+	if err != nil {
+		logrus.Warnf("Invalid SCRAPER_HTTP_CACHE value: %s, using default false", c.ScraperHTTPCache)
+		return ""
+	}
+	if !enabled {
+		return ""
+	}
+	if dir := strings.TrimSpace(c.ScraperCacheDir); dir != "" {
+		return dir
+	}
+	return ".cache/scraper-http"
+}
+
+// GetScraperCacheMaxAge returns how long scraper responses are replayed from disk
+func (c *Config) GetScraperCacheMaxAge() time.Duration {
+	maxAge, err := time.ParseDuration(c.ScraperCacheMaxAge)
+	if err != nil || maxAge < 0 {
+		logrus.Warnf("Invalid SCRAPER_HTTP_CACHE_MAX_AGE value: %s, using default 6h", c.ScraperCacheMaxAge)
+		return 6 * time.Hour
+	}
+	return maxAge
+}
+
+// GetScraperShadowExtraction returns the extraction strategy (json or html) to run in
+// shadow on IPO detail pages, or "" when shadow extraction is off
+func (c *Config) GetScraperShadowExtraction() string {
+	strategy := strings.ToLower(strings.TrimSpace(c.ScraperShadow))
+	switch strategy {
+	case "", "off", "false":
+		return ""
+	case "json", "html":
+		return strategy
+	default:
+		logrus.Warnf("Invalid SCRAPER_SHADOW_EXTRACTION value: %s, shadow extraction disabled", c.ScraperShadow)
+		return ""
+	}
+}
+
+// GetScraperImpl returns the GMP scraper implementation: simplified or enhanced
+func (c *Config) GetScraperImpl() string {
+	impl := strings.ToLower(strings.TrimSpace(c.ScraperImpl))
+	switch impl {
+	case "simplified", "enhanced":
+		return impl
+	default:
+		logrus.Warnf("Invalid SCRAPER_IMPL value: %s, using default simplified", c.ScraperImpl)
+		return "simplified"
+	}
+}
+
+// GetDescriptionPreviewLength returns how many bytes of an IPO's description public
+// responses show; 0 shows the full text
+func (c *Config) GetDescriptionPreviewLength() int {
+	return parsePreviewLength("DESCRIPTION_PREVIEW_LENGTH", c.DescriptionPreview, 2000)
+}
+
+// GetAboutPreviewLength returns how many bytes of an IPO's about text public responses
+// show; 0 shows the full text
+func (c *Config) GetAboutPreviewLength() int {
+	return parsePreviewLength("ABOUT_PREVIEW_LENGTH", c.AboutPreview, 5000)
+}
+
+func parsePreviewLength(name, value string, fallback int) int {
+	length, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || length < 0 {
+		logrus.Warnf("Invalid %s value: %s, using default %d", name, value, fallback)
+		return fallback
+	}
+	return length
+}
+
+// GetGMPOutlierThreshold returns the percent deviation from the GMP moving average above
+// which an uncorroborated scraped GMP is rejected
+func (c *Config) GetGMPOutlierThreshold() float64 {
+	threshold, err := strconv.ParseFloat(c.GMPOutlierThreshold, 64)
+	if err != nil || threshold <= 0 {
+		logrus.Warnf("Invalid GMP_OUTLIER_THRESHOLD_PERCENT value: %s, using default 50", c.GMPOutlierThreshold)
+		return 50
+	}
+	return threshold
+}
+
+// GetSignedURLTTL returns how long signed admin URLs stay valid when a request sets none
+func (c *Config) GetSignedURLTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.SignedURLTTL)
+	if err != nil || ttl <= 0 || ttl > 24*time.Hour {
+		logrus.Warnf("Invalid SIGNED_URL_TTL value: %s, using default 15m", c.SignedURLTTL)
+		return 15 * time.Minute
+	}
+	return ttl
+}
+
+// GetClientFingerprintKey returns the secret client fingerprints are keyed with and device
+// tokens are signed with. Without CLIENT_FINGERPRINT_KEY a random key is used, so
+// fingerprints and device tokens only hold within one process and stop after a restart.
+func (c *Config) GetClientFingerprintKey() []byte {
+	if c.FingerprintKey != "" {
+		return []byte(c.FingerprintKey)
+	}
+	logrus.Warn("CLIENT_FINGERPRINT_KEY not set, using a random key: client fingerprints and device tokens will not match across restarts or replicas")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		logrus.WithError(err).Fatal("Failed to generate a client fingerprint key")
+	}
+	return key
+}
+
+// GetCacheStoreClientKeys returns the keys clients sign POST /cache/store requests with,
+// by client ID, from comma-separated "client_id:key" pairs
+func (c *Config) GetCacheStoreClientKeys() map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(c.CacheStoreKeys, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		client, key, found := strings.Cut(pair, ":")
+		client, key = strings.TrimSpace(client), strings.TrimSpace(key)
+		if !found || client == "" || key == "" {
+			logrus.Warnf("Invalid CACHE_STORE_CLIENT_KEYS entry for client %q, skipping it", client)
+			continue
+		}
+		keys[client] = key
+	}
+	return keys
+}
+
+// GetResultCheckMaxAge returns how recent the daily IPO update's last success must be
+// for the result release check to run
+func (c *Config) GetResultCheckMaxAge() time.Duration {
+	maxAge, err := time.ParseDuration(c.ResultCheckMaxAge)
+	if err != nil || maxAge <= 0 {
+		logrus.Warnf("Invalid RESULT_CHECK_DAILY_MAX_AGE value: %s, using default 12h", c.ResultCheckMaxAge)
+		return 12 * time.Hour
+	}
+	return maxAge
+}
+
+// GetMandateReminderLead returns how long before the UPI mandate deadline reminders go out
+func (c *Config) GetMandateReminderLead() time.Duration {
+	lead, err := time.ParseDuration(c.MandateReminderLead)
+	if err != nil || lead <= 0 {
+		logrus.Warnf("Invalid MANDATE_REMINDER_LEAD value: %s, using default 3h", c.MandateReminderLead)
+		return 3 * time.Hour
+	}
+	return lead
+}
+
+// GetResultWatchInterval returns how often IPOs past their result date are probed for
+// released results
+func (c *Config) GetResultWatchInterval() time.Duration {
+	interval, err := time.ParseDuration(c.ResultWatchInterval)
+	if err != nil || interval < time.Minute {
+		logrus.Warnf("Invalid RESULT_WATCH_INTERVAL value: %s, using default 10m", c.ResultWatchInterval)
+		return 10 * time.Minute
+	}
+	return interval
+}
+
+// GetScrapePoliteness parses SCRAPE_POLITENESS_WINDOWS, e.g. "09:30-10:30=skip,14:00-15:00=slow"
+// (IST), with SCRAPE_POLITENESS_PAUSE between requests in slow windows. Invalid windows
+// disable the schedule with a warning.
+func (c *Config) GetScrapePoliteness() *shared.PolitenessSchedule {
+	windows, err := shared.ParsePolitenessWindows(c.ScrapePoliteness)
+	if err != nil {
+		logrus.Warnf("Invalid SCRAPE_POLITENESS_WINDOWS value: %v, scraping without politeness windows", err)
+		windows = nil
+	}
+	schedule := shared.NewPolitenessSchedule(windows)
+
+	pause, err := time.ParseDuration(c.ScrapePolitePause)
+	if err != nil || pause < 0 {
+		logrus.Warnf("Invalid SCRAPE_POLITENESS_PAUSE value: %s, using default %v", c.ScrapePolitePause, shared.DefaultPolitenessPause)
+		pause = shared.DefaultPolitenessPause
+	}
+	schedule.Pause = pause
+	return schedule
+}
+
+// GetCacheShadowMode returns CACHE_SHADOW_MODE: "off", or "shadow" to also write the data
+// cache to REDIS_URL and compare reads with it
+func (c *Config) GetCacheShadowMode() string {
+	mode := strings.ToLower(strings.TrimSpace(c.CacheShadowMode))
+	switch mode {
+	case "off", "shadow":
+		return mode
+	}
+	logrus.Warnf("Invalid CACHE_SHADOW_MODE value: %s, using default off", c.CacheShadowMode)
+	return "off"
+}
+
+// GetJobDeadlines parses JOB_DEADLINES, e.g. "daily_ipo_update=20m,gmp_update=10m", into
+// per-job run deadlines overriding the jobs' own
+func (c *Config) GetJobDeadlines() map[string]time.Duration {
+	deadlines := make(map[string]time.Duration)
+	if c.JobDeadlines == "" {
+		return deadlines
+	}
+
+	for _, pair := range strings.Split(c.JobDeadlines, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			logrus.Warnf("Invalid JOB_DEADLINES entry: %s", pair)
+			continue
+		}
+		deadline, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || deadline <= 0 {
+			logrus.Warnf("Invalid JOB_DEADLINES entry: %s", pair)
+			continue
+		}
+		deadlines[strings.TrimSpace(parts[0])] = deadline
+	}
+
+	return deadlines
+}
+
+// GetJobDefaultDeadline returns the run deadline of jobs that have none of their own
+func (c *Config) GetJobDefaultDeadline() time.Duration {
+	deadline, err := time.ParseDuration(c.JobDefaultDeadline)
+	if err != nil || deadline <= 0 {
+		logrus.Warnf("Invalid JOB_DEFAULT_DEADLINE value: %s, using default 30m", c.JobDefaultDeadline)
+		return 30 * time.Minute
+	}
+	return deadline
+}
+
+// GetResultWatchWindow returns how long after the start of an IPO's result date it is
+// probed every result watch interval before falling back to hourly
+func (c *Config) GetResultWatchWindow() time.Duration {
+	window, err := time.ParseDuration(c.ResultWatchWindow)
+	if err != nil || window <= 0 {
+		logrus.Warnf("Invalid RESULT_WATCH_WINDOW value: %s, using default 48h", c.ResultWatchWindow)
+		return 48 * time.Hour
+	}
+	return window
+}
+
+// GetHTTPRecordingSize returns how many failed outbound requests are kept for
+// /admin/debug/http, or 0 when HTTP_RECORDING is off
+func (c *Config) GetHTTPRecordingSize() int {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(c.HTTPRecording))
+	if err != nil {
+		logrus.Warnf("Invalid HTTP_RECORDING value: %s, using default false", c.HTTPRecording)
+		return 0
+	}
+	if !enabled {
+		return 0
+	}
+	size, err := strconv.Atoi(c.HTTPRecordingSize)
+	if err != nil || size <= 0 || size > 1000 {
+		logrus.Warnf("Invalid HTTP_RECORDING_SIZE value: %s, using default 100", c.HTTPRecordingSize)
+		return 100
+	}
+	return size
+}
+
+// GetAdminIPAllowlist returns the CIDRs and addresses allowed to reach the admin routes;
+// empty when ADMIN_IP_ALLOWLIST is unset, leaving them open to every address
+func (c *Config) GetAdminIPAllowlist() []string {
+	var entries []string
+	for _, entry := range strings.Split(c.AdminIPAllowlist, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// GetAdminTrustedProxies returns the CIDRs and addresses of the reverse proxies whose
+// ADMIN_CLIENT_IP_HEADER the admin allowlist believes
+func (c *Config) GetAdminTrustedProxies() []string {
+	var entries []string
+	for _, entry := range strings.Split(c.AdminTrustedProxies, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
@@ -68,12 +668,75 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		ServerPort:      getEnv("SERVER_PORT", "8080"),
-		DatabaseURL:     getEnv("DATABASE_URL", ""),
-		AdminToken:      getEnv("ADMIN_TOKEN", ""),
-		CacheTTLHours:   getEnv("CACHE_TTL_HOURS", "24"),
-		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		IPOAlertsAPIKey: getEnv("IPO_ALERTS_API_KEY", ""),
+		ServerPort:           getEnv("SERVER_PORT", "8080"),
+		DatabaseURL:          getEnv("DATABASE_URL", ""),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		CacheTTLHours:        getEnv("CACHE_TTL_HOURS", "24"),
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		IPOAlertsAPIKey:      getEnv("IPO_ALERTS_API_KEY", ""),
+		WebhookURLs:          getEnv("WEBHOOK_URLS", ""),
+		CheckRegistrarLimits: getEnv("CHECK_REGISTRAR_LIMITS", ""),
+		CheckDailyLimit:      getEnv("CHECK_DAILY_LIMIT", "10"),
+		HTTPHostBudgets:      getEnv("HTTP_HOST_BUDGETS", ""),
+		ResponseCacheTTL:     getEnv("RESPONSE_CACHE_TTL_SECONDS", "30"),
+		ResponseMeta:         getEnv("RESPONSE_META", "true"),
+		QuoteCacheTTL:        getEnv("QUOTE_CACHE_TTL_SECONDS", "60"),
+		RetryPolicyHTTP:      getEnv("RETRY_POLICY_HTTP", ""),
+		RetryPolicyDatabase:  getEnv("RETRY_POLICY_DATABASE", ""),
+		AlertSlackWebhookURL: getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+		AlertEmailTo:         getEnv("ALERT_EMAIL_TO", ""),
+		AlertEmailFrom:       getEnv("ALERT_EMAIL_FROM", "alerts@localhost"),
+		AlertSMTPAddr:        getEnv("ALERT_SMTP_ADDR", ""),
+		AlertSMTPUsername:    getEnv("ALERT_SMTP_USERNAME", ""),
+		AlertSMTPPassword:    getEnv("ALERT_SMTP_PASSWORD", ""),
+		AlertThresholds:      getEnv("ALERT_THRESHOLDS", ""),
+		AlertCooldown:        getEnv("ALERT_COOLDOWN", "1h"),
+		MaxRequestBodyBytes:  getEnv("MAX_REQUEST_BODY_BYTES", "65536"),
+		PANVaultKey:          getEnv("PAN_VAULT_KEY", ""),
+		AuthMagicLinkURL:     getEnv("AUTH_MAGIC_LINK_URL", "http://localhost:3000/auth/verify"),
+		AuthSessionTTL:       getEnv("AUTH_SESSION_TTL", "720h"),
+		AuthEmailFrom:        getEnv("AUTH_EMAIL_FROM", "no-reply@localhost"),
+		AuthSMTPAddr:         getEnv("AUTH_SMTP_ADDR", ""),
+		AuthSMTPUsername:     getEnv("AUTH_SMTP_USERNAME", ""),
+		AuthSMTPPassword:     getEnv("AUTH_SMTP_PASSWORD", ""),
+		AuthSMSWebhookURL:    getEnv("AUTH_SMS_WEBHOOK_URL", ""),
+		SchemaRemediation:    getEnv("SCHEMA_REMEDIATION", "off"),
+		SchemaMigrationsDir:  getEnv("SCHEMA_MIGRATIONS_DIR", "database/migrations"),
+		StartupPhaseTimeout:  getEnv("STARTUP_PHASE_TIMEOUT", "1m"),
+		TelegramBotToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramHookSecret:   getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+		TelegramChatLimit:    getEnv("TELEGRAM_CHAT_RATE_LIMIT", "10"),
+		ExportAPIKeys:        getEnv("EXPORT_API_KEYS", ""),
+		CheckSandboxKeys:     getEnv("CHECK_SANDBOX_KEYS", ""),
+		ExportRateLimit:      getEnv("EXPORT_RATE_LIMIT", "5"),
+		IPOListMirrorURLs:    getEnv("IPO_LIST_MIRROR_URLS", ""),
+		ScraperHTTPCache:     getEnv("SCRAPER_HTTP_CACHE", "false"),
+		ScraperCacheDir:      getEnv("SCRAPER_HTTP_CACHE_DIR", ".cache/scraper-http"),
+		ScraperCacheMaxAge:   getEnv("SCRAPER_HTTP_CACHE_MAX_AGE", "6h"),
+		ScraperShadow:        getEnv("SCRAPER_SHADOW_EXTRACTION", ""),
+		ScraperImpl:          getEnv("SCRAPER_IMPL", "simplified"),
+		DescriptionPreview:   getEnv("DESCRIPTION_PREVIEW_LENGTH", "2000"),
+		AboutPreview:         getEnv("ABOUT_PREVIEW_LENGTH", "5000"),
+		GMPOutlierThreshold:  getEnv("GMP_OUTLIER_THRESHOLD_PERCENT", "50"),
+		SignedURLSecret:      getEnv("SIGNED_URL_SECRET", ""),
+		SignedURLTTL:         getEnv("SIGNED_URL_TTL", "15m"),
+		CacheStoreKeys:       getEnv("CACHE_STORE_CLIENT_KEYS", ""),
+		FingerprintKey:       getEnv("CLIENT_FINGERPRINT_KEY", ""),
+		ResultCheckMaxAge:    getEnv("RESULT_CHECK_DAILY_MAX_AGE", "12h"),
+		MandateReminderLead:  getEnv("MANDATE_REMINDER_LEAD", "3h"),
+		ResultWatchInterval:  getEnv("RESULT_WATCH_INTERVAL", "10m"),
+		ResultWatchWindow:    getEnv("RESULT_WATCH_WINDOW", "48h"),
+		HTTPRecording:        getEnv("HTTP_RECORDING", "false"),
+		HTTPRecordingSize:    getEnv("HTTP_RECORDING_SIZE", "100"),
+		AdminIPAllowlist:     getEnv("ADMIN_IP_ALLOWLIST", ""),
+		AdminClientIPHeader:  getEnv("ADMIN_CLIENT_IP_HEADER", ""),
+		AdminTrustedProxies:  getEnv("ADMIN_TRUSTED_PROXIES", ""),
+		ScrapePoliteness:     getEnv("SCRAPE_POLITENESS_WINDOWS", ""),
+		ScrapePolitePause:    getEnv("SCRAPE_POLITENESS_PAUSE", "5s"),
+		RedisURL:             getEnv("REDIS_URL", ""),
+		CacheShadowMode:      getEnv("CACHE_SHADOW_MODE", "off"),
+		JobDeadlines:         getEnv("JOB_DEADLINES", ""),
+		JobDefaultDeadline:   getEnv("JOB_DEFAULT_DEADLINE", "30m"),
 	}
 }
 
@@ -0,0 +1,433 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// DataContractField maps a field the scrapers produce to the column that stores it.
+// Field is the Go field path within the model, dotted for nested structs
+// ("IssueStructure.OFSShares"); Type is the expected column type, written as in the
+// schema validator's required columns.
+type DataContractField struct {
+	Field  string
+	Table  string
+	Column string
+	Type   string
+}
+
+// DataContract lists where each field of a scraper output model is stored. Every
+// exported field must be mapped in Fields or listed in Skipped with the reason it is
+// not stored, so adding a field to the model without a column fails the contract check
+// instead of the save silently dropping it.
+type DataContract struct {
+	Model   any
+	Fields  []DataContractField
+	Skipped map[string]string
+}
+
+// ScraperDataContracts are the contracts of the IPO, GMP and subscription scrapers' output
+var ScraperDataContracts = []DataContract{
+	{
+		Model: models.IPO{},
+		Fields: []DataContractField{
+			{"ID", "ipo_list", "id", "uuid"},
+			{"StockID", "ipo_list", "stock_id", "varchar(100)"},
+			{"Name", "ipo_list", "name", "varchar(255)"},
+			{"CompanyCode", "ipo_list", "company_code", "varchar(50)"},
+			{"Symbol", "ipo_list", "symbol", "varchar(50)"},
+			{"Registrar", "ipo_list", "registrar", "varchar(255)"},
+			{"OpenDate", "ipo_list", "open_date", "date"},
+			{"CloseDate", "ipo_list", "close_date", "date"},
+			{"ResultDate", "ipo_list", "result_date", "date"},
+			{"ListingDate", "ipo_list", "listing_date", "date"},
+			{"PriceBandLow", "ipo_list", "price_band_low", "decimal(10,2)"},
+			{"PriceBandHigh", "ipo_list", "price_band_high", "decimal(10,2)"},
+			{"IssueSize", "ipo_list", "issue_size", "varchar(100)"},
+			{"MinQty", "ipo_list", "min_qty", "integer"},
+			{"MinAmount", "ipo_list", "min_amount", "integer"},
+			{"IssueSizeAmount", "ipo_list", "issue_size_amount", "decimal(18,2)"},
+			{"Status", "ipo_list", "status", "varchar(50)"},
+			{"SubscriptionStatus", "ipo_list", "subscription_status", "varchar(100)"},
+			{"ListingGain", "ipo_list", "listing_gain", "varchar(50)"},
+			{"LogoURL", "ipo_list", "logo_url", "varchar(500)"},
+			{"Description", "ipo_list", "description_full", "text"},
+			{"About", "ipo_list", "about_full", "text"},
+			{"Slug", "ipo_list", "slug", "varchar(255)"},
+			{"FormURL", "ipo_list", "form_url", "varchar(500)"},
+			{"FormFields", "ipo_list", "form_fields", "jsonb"},
+			{"FormHeaders", "ipo_list", "form_headers", "jsonb"},
+			{"ParserConfig", "ipo_list", "parser_config", "jsonb"},
+			{"Strengths", "ipo_list", "strengths", "jsonb"},
+			{"Risks", "ipo_list", "risks", "jsonb"},
+			{"AnchorAllocation.IPOID", "ipo_anchor_allocations", "ipo_id", "uuid"},
+			{"AnchorAllocation.BidDate", "ipo_anchor_allocations", "bid_date", "date"},
+			{"AnchorAllocation.SharesOffered", "ipo_anchor_allocations", "shares_offered", "bigint"},
+			{"AnchorAllocation.AmountCrore", "ipo_anchor_allocations", "amount_crore", "decimal(12,2)"},
+			{"AnchorAllocation.LockIn30Date", "ipo_anchor_allocations", "lockin_30_date", "date"},
+			{"AnchorAllocation.LockIn90Date", "ipo_anchor_allocations", "lockin_90_date", "date"},
+			{"AnchorAllocation.LockInEstimated", "ipo_anchor_allocations", "lockin_estimated", "boolean"},
+			{"AnchorAllocation.UpdatedAt", "ipo_anchor_allocations", "updated_at", "timestamp"},
+			{"FAQ", "ipo_list", "faq", "jsonb"},
+			{"Timetable", "ipo_list", "timetable", "jsonb"},
+			{"IssueStructure.FreshIssueShares", "ipo_list", "fresh_issue_shares", "bigint"},
+			{"IssueStructure.FreshIssueAmount", "ipo_list", "fresh_issue_amount", "decimal(18,2)"},
+			{"IssueStructure.OFSShares", "ipo_list", "ofs_shares", "bigint"},
+			{"IssueStructure.OFSAmount", "ipo_list", "ofs_amount", "decimal(18,2)"},
+			{"IssueStructure.OFSPercent", "ipo_list", "ofs_percent", "decimal(5,2)"},
+			{"PromoterHolding.PreIssuePercent", "ipo_list", "promoter_holding_pre", "decimal(5,2)"},
+			{"PromoterHolding.PostIssuePercent", "ipo_list", "promoter_holding_post", "decimal(5,2)"},
+			{"ManualCheck.URL", "ipo_list", "manual_check_url", "varchar(500)"},
+			{"ManualCheck.Instructions", "ipo_list", "manual_check_instructions", "text"},
+			{"RetailCutoff", "ipo_list", "retail_cutoff", "time"},
+			{"HNICutoff", "ipo_list", "hni_cutoff", "time"},
+			{"Lineage", "ipo_list", "field_lineage", "jsonb"},
+			{"CreatedAt", "ipo_list", "created_at", "timestamp"},
+			{"UpdatedAt", "ipo_list", "updated_at", "timestamp"},
+			{"CreatedBy", "ipo_list", "created_by", "varchar(100)"},
+		},
+		Skipped: map[string]string{
+			"MandateDeadline":                "computed from the close date",
+			"CloseTimes":                     "computed from the close date and cutoffs",
+			"SecondsRemaining":               "computed from the close times",
+			"DisplayStatus":                  "computed from the status in the requested language",
+			"DescriptionTruncated":           "set when a response cuts the description",
+			"AboutTruncated":                 "set when a response cuts the about text",
+			"PromoterHolding.DilutionPoints": "computed from the pre and post issue holding",
+			"PriceRevisions":                 "recorded in ipo_price_revisions when the price band changes",
+			"PreviousSlugs":                  "recorded in ipo_slug_history when the slug changes",
+		},
+	},
+	{
+		Model: models.EnhancedGMPData{},
+		Fields: []DataContractField{
+			{"ID", "ipo_gmp", "id", "varchar(100)"},
+			{"IPOName", "ipo_gmp", "ipo_name", "varchar(255)"},
+			{"CompanyCode", "ipo_gmp", "company_code", "varchar(50)"},
+			{"IPOPrice", "ipo_gmp", "ipo_price", "decimal(10,2)"},
+			{"GMPValue", "ipo_gmp", "gmp_value", "decimal(10,2)"},
+			{"EstimatedListing", "ipo_gmp", "estimated_listing", "decimal(10,2)"},
+			{"GainPercent", "ipo_gmp", "gain_percent", "decimal(10,2)"},
+			{"Sub2", "ipo_gmp", "sub2", "decimal(10,2)"},
+			{"Kostak", "ipo_gmp", "kostak", "decimal(10,2)"},
+			{"LastUpdated", "ipo_gmp", "last_updated", "timestamp"},
+			{"StockID", "ipo_gmp", "stock_id", "varchar(100)"},
+			{"SubscriptionStatus", "ipo_gmp", "subscription_status", "varchar(100)"},
+			{"ListingGain", "ipo_gmp", "listing_gain", "varchar(50)"},
+			{"IPOStatus", "ipo_gmp", "ipo_status", "varchar(50)"},
+			{"DataSource", "ipo_gmp", "data_source", "varchar(100)"},
+			{"ExtractionMetadata", "ipo_gmp", "extraction_metadata", "jsonb"},
+			{"Smoothing.RawGMPValue", "ipo_gmp", "raw_gmp_value", "decimal(10,2)"},
+			{"Smoothing.SmoothedGMPValue", "ipo_gmp", "smoothed_gmp_value", "decimal(10,2)"},
+			{"Smoothing.OutlierRejected", "ipo_gmp", "gmp_outlier_rejected", "boolean"},
+			{"Attribution.Providers", "ipo_gmp", "gmp_providers", "text[]"},
+			{"Attribution.ScrapedAt", "ipo_gmp", "gmp_scraped_at", "timestamp"},
+			{"Attribution.ManualOverride", "ipo_gmp", "is_manual_override", "boolean"},
+		},
+		Skipped: map[string]string{
+			"ListingDate":                   "kept on ipo_list; the GMP save does not write it",
+			"Rating":                        "display only; the GMP save does not write it",
+			"UpdatedOn":                     "display only; the GMP save does not write it",
+			"TrendSignals":                  "computed from ipo_gmp_history after the save",
+			"IndicativeListingPrice":        "fetched by the pre-open service, not the GMP scrape",
+			"Attribution.Source":            "the same as DataSource",
+			"Attribution.ProvidersAgreeing": "the number of providers",
+			"Attribution.Unofficial":        "always true",
+		},
+	},
+	{
+		Model: models.SubscriptionSnapshot{},
+		Fields: []DataContractField{
+			{"Multiples", "ipo_subscription_history", "multiple", "decimal(10,2)"},
+			{"Applications", "ipo_subscription_history", "applications", "bigint"},
+			{"SharesOffered", "ipo_subscription_history", "shares_offered", "bigint"},
+		},
+	},
+}
+
+// CheckDataContractCoverage checks the contracts against their models: every exported
+// field, and every field of a nested struct the contract maps into, is mapped or
+// skipped, and every mapped or skipped path names a field. Returns the problems found.
+func CheckDataContractCoverage(contracts []DataContract) []string {
+	var problems []string
+	for _, contract := range contracts {
+		model := reflect.TypeOf(contract.Model)
+		covered := make(map[string]bool, len(contract.Fields)+len(contract.Skipped))
+		for _, field := range contract.Fields {
+			covered[field.Field] = true
+		}
+		for path := range contract.Skipped {
+			covered[path] = true
+		}
+
+		for path := range covered {
+			if _, ok := contractFieldType(model, path); !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: no such field", model.Name(), path))
+			}
+		}
+		problems = append(problems, uncoveredFields(model.Name(), model, "", covered)...)
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// uncoveredFields lists the exported fields of a struct type that are neither covered
+// themselves nor, for nested structs the contract maps into, through their fields
+func uncoveredFields(modelName string, model reflect.Type, prefix string, covered map[string]bool) []string {
+	var problems []string
+	for i := 0; i < model.NumField(); i++ {
+		field := model.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := prefix + field.Name
+		if covered[path] {
+			continue
+		}
+		nested := field.Type
+		for nested.Kind() == reflect.Pointer {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && coversWithin(covered, path) {
+			problems = append(problems, uncoveredFields(modelName, nested, path+".", covered)...)
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s.%s: not mapped to a column or skipped", modelName, path))
+	}
+	return problems
+}
+
+func coversWithin(covered map[string]bool, path string) bool {
+	for candidate := range covered {
+		if strings.HasPrefix(candidate, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// contractFieldType resolves a dotted field path within a struct type
+func contractFieldType(model reflect.Type, path string) (reflect.Type, bool) {
+	current := model
+	for _, name := range strings.Split(path, ".") {
+		for current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+		if current.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field, ok := current.FieldByName(name)
+		if !ok {
+			return nil, false
+		}
+		current = field.Type
+	}
+	return current, true
+}
+
+// CheckDataContractColumns checks every mapped field against the table columns, by
+// table then column name, with information_schema data types. Missing columns and
+// incompatible types are reported per table, as the schema validator reports them.
+func CheckDataContractColumns(contracts []DataContract, columns map[string]map[string]string) []ValidationResult {
+	validator := &SchemaValidator{}
+	byTable := make(map[string]*ValidationResult)
+	var tables []string
+	for _, contract := range contracts {
+		for _, field := range contract.Fields {
+			result, ok := byTable[field.Table]
+			if !ok {
+				result = &ValidationResult{
+					TableName:          field.Table,
+					IsValid:            true,
+					MissingColumns:     make([]string, 0),
+					MissingIndexes:     make([]string, 0),
+					InvalidConstraints: make([]string, 0),
+					Recommendations:    make([]string, 0),
+				}
+				byTable[field.Table] = result
+				tables = append(tables, field.Table)
+			}
+
+			actualType, exists := columns[field.Table][field.Column]
+			switch {
+			case !exists:
+				result.IsValid = false
+				result.MissingColumns = append(result.MissingColumns, fmt.Sprintf("%s (%s)", field.Column, field.Type))
+			case !validator.isCompatibleType(actualType, field.Type):
+				result.IsValid = false
+				result.InvalidConstraints = append(result.InvalidConstraints,
+					fmt.Sprintf("column %s has type %s, expected %s for scraped field %s", field.Column, actualType, field.Type, field.Field))
+			}
+		}
+	}
+
+	sort.Strings(tables)
+	results := make([]ValidationResult, 0, len(tables))
+	for _, table := range tables {
+		result := byTable[table]
+		if !result.IsValid {
+			result.Recommendations = append(result.Recommendations,
+				fmt.Sprintf("Add or retype the %s columns scraped data is saved to, or update the scraper data contract", table))
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// validateDataContract checks the scraper data contracts against the models and the
+// live schema. Coverage problems are reported under the "data_contract" table, as
+// they need a code change rather than a migration.
+func (v *SchemaValidator) validateDataContract() ([]ValidationResult, error) {
+	columns := make(map[string]map[string]string)
+	for _, contract := range ScraperDataContracts {
+		for _, field := range contract.Fields {
+			if _, loaded := columns[field.Table]; loaded {
+				continue
+			}
+			tableColumns, err := v.getTableColumns(field.Table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s columns: %w", field.Table, err)
+			}
+			columns[field.Table] = tableColumns
+		}
+	}
+
+	results := CheckDataContractColumns(ScraperDataContracts, columns)
+	if problems := CheckDataContractCoverage(ScraperDataContracts); len(problems) > 0 {
+		results = append(results, ValidationResult{
+			TableName:          "data_contract",
+			IsValid:            false,
+			MissingColumns:     make([]string, 0),
+			MissingIndexes:     make([]string, 0),
+			InvalidConstraints: problems,
+			Recommendations:    []string{"Map new scraper model fields to columns in ScraperDataContracts or list them as skipped"},
+		})
+	}
+	for _, result := range results {
+		if result.IsValid {
+			continue
+		}
+		v.logger.WithFields(logrus.Fields{
+			"table":           result.TableName,
+			"missing_columns": result.MissingColumns,
+			"invalid":         result.InvalidConstraints,
+		}).Error("Scraper data contract violated; scraped fields would be dropped or rejected")
+	}
+	return results, nil
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE TABLE\s+(?:IF NOT EXISTS\s+)?(\w+)\s*\((.*)\)\s*$`)
+	addColumnPattern   = regexp.MustCompile(`(?is)^ALTER TABLE\s+(\w+)\s+ADD COLUMN\s+(?:IF NOT EXISTS\s+)?(\w+)\s+(.*)$`)
+	alterTypePattern   = regexp.MustCompile(`(?is)^ALTER TABLE\s+(\w+)\s+ALTER COLUMN\s+(\w+)\s+TYPE\s+(.*)$`)
+)
+
+// columnTypeStops end a column's type in a definition
+var columnTypeStops = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true,
+	"REFERENCES": true, "CHECK": true, "CONSTRAINT": true, "GENERATED": true, "USING": true,
+}
+
+// SchemaFileColumns reads the columns a schema file defines, by table then column name,
+// from its CREATE TABLE, ADD COLUMN and ALTER COLUMN ... TYPE statements. Types are
+// given as information_schema reports them, so the result can be checked like a live
+// database.
+func SchemaFileColumns(content string) map[string]map[string]string {
+	tables := make(map[string]map[string]string)
+	define := func(table, column, definition string) {
+		table, column = strings.ToLower(table), strings.ToLower(column)
+		if tables[table] == nil {
+			tables[table] = make(map[string]string)
+		}
+		tables[table][column] = informationSchemaType(definition)
+	}
+
+	for _, statement := range parseSQLStatements(content) {
+		statement = strings.TrimSpace(statement)
+		if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+			for _, item := range splitTopLevel(match[2]) {
+				words := strings.Fields(item)
+				if len(words) < 2 {
+					continue
+				}
+				switch strings.ToUpper(words[0]) {
+				case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE":
+					continue
+				}
+				define(match[1], words[0], strings.Join(words[1:], " "))
+			}
+			continue
+		}
+		if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+			define(match[1], match[2], match[3])
+			continue
+		}
+		if match := alterTypePattern.FindStringSubmatch(statement); match != nil {
+			define(match[1], match[2], match[3])
+		}
+	}
+	return tables
+}
+
+// splitTopLevel splits a CREATE TABLE body on the commas outside parentheses
+func splitTopLevel(body string) []string {
+	var items []string
+	depth, start := 0, 0
+	for i, char := range body {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(body[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(items, strings.TrimSpace(body[start:]))
+}
+
+// informationSchemaType turns the type at the start of a column definition into the
+// data_type information_schema.columns reports for it
+func informationSchemaType(definition string) string {
+	var words []string
+	for _, word := range strings.Fields(definition) {
+		if columnTypeStops[strings.ToUpper(word)] {
+			break
+		}
+		words = append(words, word)
+	}
+	columnType := strings.ToLower(strings.Join(words, " "))
+	base := columnType
+	if open := strings.Index(base, "("); open >= 0 {
+		base = strings.TrimSpace(base[:open])
+	}
+
+	switch {
+	case strings.HasSuffix(columnType, "[]"):
+		return "ARRAY"
+	case base == "varchar" || strings.HasPrefix(base, "character varying"):
+		return "character varying"
+	case base == "decimal" || base == "numeric":
+		return "numeric"
+	case base == "int" || base == "integer" || base == "int4" || base == "serial":
+		return "integer"
+	case base == "bigint" || base == "int8" || base == "bigserial":
+		return "bigint"
+	case base == "bool" || base == "boolean":
+		return "boolean"
+	case base == "timestamptz" || strings.HasPrefix(base, "timestamp with time zone"):
+		return "timestamp with time zone"
+	case strings.HasPrefix(base, "timestamp"):
+		return "timestamp without time zone"
+	}
+	return base
+}
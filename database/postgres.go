@@ -339,6 +339,13 @@ func (v *SchemaValidator) ValidateSchemaCompatibility() (*SchemaCompatibilityRep
 	}
 	report.ValidationResults = append(report.ValidationResults, *auditTableResult)
 
+	// Validate that every scraped field has a column of a compatible type
+	contractResults, err := v.validateDataContract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate scraper data contract: %w", err)
+	}
+	report.ValidationResults = append(report.ValidationResults, contractResults...)
+
 	// Validate indexes for optimized queries
 	indexResult, err := v.validateOptimizedIndexes()
 	if err != nil {
@@ -398,10 +405,10 @@ func (v *SchemaValidator) validateIPOTableStructure() (*ValidationResult, error)
 		"company_code":        "varchar(50)",
 		"symbol":              "varchar(50)",
 		"registrar":           "varchar(255)",
-		"open_date":           "timestamp",
-		"close_date":          "timestamp",
-		"result_date":         "timestamp",
-		"listing_date":        "timestamp",
+		"open_date":           "date",
+		"close_date":          "date",
+		"result_date":         "date",
+		"listing_date":        "date",
 		"price_band_low":      "decimal(10,2)",
 		"price_band_high":     "decimal(10,2)",
 		"issue_size":          "varchar(100)",
@@ -413,6 +420,9 @@ func (v *SchemaValidator) validateIPOTableStructure() (*ValidationResult, error)
 		"logo_url":            "varchar(500)",
 		"description":         "text",
 		"about":               "text",
+		"description_full":    "text",
+		"about_full":          "text",
+		"results_released_at": "timestamp",
 		"slug":                "varchar(255)",
 		"form_url":            "varchar(500)",
 		"form_fields":         "jsonb",
@@ -576,6 +586,9 @@ func (v *SchemaValidator) validateResultCacheTableStructure() (*ValidationResult
 		"expires_at":         "timestamp",
 		"confidence_score":   "integer",
 		"duplicate_count":    "integer",
+		"source_channel":     "varchar(20)",
+		"client_fingerprint": "varchar(64)",
+		"sources":            "text[]",
 	}
 
 	// Check for missing columns
@@ -835,9 +848,16 @@ func (v *SchemaValidator) isCompatibleType(actualType, expectedType string) bool
 		"varchar(500)":  {"character varying", "varchar", "text"},
 		"text":          {"text", "character varying", "varchar"},
 		"timestamp":     {"timestamp without time zone", "timestamp", "timestamptz"},
+		"date":          {"date"},
 		"decimal(10,2)": {"numeric", "decimal", "real", "double precision"},
+		"decimal(5,2)":  {"numeric", "decimal"},
+		"decimal(12,2)": {"numeric", "decimal"},
+		"decimal(18,2)": {"numeric", "decimal"},
 		"integer":       {"integer", "int", "int4"},
+		"bigint":        {"bigint", "int8"},
+		"boolean":       {"boolean", "bool"},
 		"jsonb":         {"jsonb", "json"},
+		"text[]":        {"array"},
 	}
 
 	if compatibleTypes, exists := typeMapping[expectedType]; exists {
@@ -18,10 +18,10 @@ CREATE TABLE ipo_list (
     registrar VARCHAR(255) NOT NULL,
     
     -- Date Information (from IPODateInformation)
-    open_date TIMESTAMP,
-    close_date TIMESTAMP,
-    result_date TIMESTAMP,
-    listing_date TIMESTAMP,
+    open_date DATE,
+    close_date DATE,
+    result_date DATE,
+    listing_date DATE,
     
     -- Pricing Information (from IPOPricingInformation)
     price_band_low DECIMAL(10, 2),
@@ -81,6 +81,64 @@ ALTER TABLE ipo_list ADD CONSTRAINT ipo_list_price_band_logic CHECK (
 ALTER TABLE ipo_list ADD CONSTRAINT ipo_list_min_qty_positive CHECK (min_qty IS NULL OR min_qty > 0);
 ALTER TABLE ipo_list ADD CONSTRAINT ipo_list_min_amount_positive CHECK (min_amount IS NULL OR min_amount > 0);
 
+-- Data completeness (percentage of key fields populated), recomputed on every upsert
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS completeness_score DECIMAL(5, 2);
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS missing_fields JSONB DEFAULT '[]';
+
+-- Structured page content: FAQ question/answer pairs and the published timetable rows
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS faq JSONB;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS timetable JSONB;
+
+-- Issue size in rupees parsed from the free-text issue_size, for sorting and size filters.
+-- Rows written before the column existed are filled by the issue size backfill job.
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS issue_size_amount DECIMAL(18, 2);
+
+-- Fresh issue and offer for sale breakdown from the issue details table, amounts in rupees.
+-- ofs_percent is the OFS share of the issue, for the analytics screener.
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS fresh_issue_shares BIGINT;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS fresh_issue_amount DECIMAL(18, 2);
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS ofs_shares BIGINT;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS ofs_amount DECIMAL(18, 2);
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS ofs_percent DECIMAL(5, 2);
+
+-- Promoter shareholding before and after the issue, in percent
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS promoter_holding_pre DECIMAL(5, 2);
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS promoter_holding_post DECIMAL(5, 2);
+
+-- Where each field's value came from: field name -> {source, fetched_at}
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS field_lineage JSONB NOT NULL DEFAULT '{}';
+
+-- Full scraped description and about texts; responses cut previews from them. The older
+-- description and about columns hold texts truncated at scrape time, until the next scrape
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS description_full TEXT;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS about_full TEXT;
+
+-- When the registrar was first seen publishing the IPO's allotment results; set with the
+-- RESULT_OUT status by the result release watcher
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS results_released_at TIMESTAMP;
+
+-- Admin-set link and instructions for checking allotment on the registrar's site, for
+-- registrars the allotment checker does not support
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS manual_check_url VARCHAR(500);
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS manual_check_instructions TEXT;
+
+-- Timetable dates are IST calendar days; earlier schemas stored them as TIMESTAMP, which
+-- shifted the day for clients outside IST. Converting existing DATE columns is a no-op.
+ALTER TABLE ipo_list ALTER COLUMN open_date TYPE DATE;
+ALTER TABLE ipo_list ALTER COLUMN close_date TYPE DATE;
+ALTER TABLE ipo_list ALTER COLUMN result_date TYPE DATE;
+ALTER TABLE ipo_list ALTER COLUMN listing_date TYPE DATE;
+
+-- IPOs merged into another by an admin or dedup are soft-deleted: reads skip rows with
+-- deleted_at set, and merged_into_ipo_id points at the IPO that replaced them
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS merged_into_ipo_id UUID;
+
+-- Bidding cutoffs on the close date as IST times of day, set by admins when an IPO's
+-- differ from the defaults (17:00 for retail bids, 16:00 for HNI bids)
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS retail_cutoff TIME;
+ALTER TABLE ipo_list ADD COLUMN IF NOT EXISTS hni_cutoff TIME;
+
 -- Performance Indexes for optimized query performance
 -- These indexes are designed for common query patterns in the IPO backend
 
@@ -120,6 +178,9 @@ CREATE INDEX idx_ipo_name_gin ON ipo_list USING gin(to_tsvector('english', name)
 -- Index for recent IPOs (commonly queried)
 CREATE INDEX idx_ipo_recent ON ipo_list(created_at DESC, status) WHERE created_at >= CURRENT_DATE - INTERVAL '1 year';
 
+-- Index for the admin completeness report
+CREATE INDEX idx_ipo_completeness ON ipo_list(completeness_score);
+
 -- Supporting Tables
 
 -- IPO Grey Market Premium (GMP) data table
@@ -152,6 +213,52 @@ ALTER TABLE ipo_gmp ADD CONSTRAINT ipo_gmp_ipo_name_not_empty CHECK (ipo_name !=
 ALTER TABLE ipo_gmp ADD CONSTRAINT ipo_gmp_company_code_not_empty CHECK (company_code != '');
 ALTER TABLE ipo_gmp ADD CONSTRAINT ipo_gmp_ipo_price_positive CHECK (ipo_price >= 0);
 
+-- Derived GMP trend signals, refreshed from ipo_gmp_history on every GMP update
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_change_24h DECIMAL(10, 2);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_momentum_3d DECIMAL(10, 2);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_volatility DECIMAL(10, 4);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS days_to_listing INTEGER;
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS listing_decay DECIMAL(6, 4);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS signals_updated_at TIMESTAMP;
+
+-- Manual GMP overrides; the GMP job skips rows with an unexpired override
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS is_manual_override BOOLEAN DEFAULT FALSE;
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS override_expires_at TIMESTAMP;
+
+-- Fields corrected by an admin (field name -> time set); the GMP job keeps their values
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS admin_fields JSONB DEFAULT '{}';
+
+-- Last scraped GMP and its moving average; outliers keep the previous gmp_value
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS raw_gmp_value DECIMAL(10, 2);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS smoothed_gmp_value DECIMAL(10, 2);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_outlier_rejected BOOLEAN DEFAULT FALSE;
+
+-- Providers agreeing on the published GMP and when it was scraped; outliers keep both
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_providers TEXT[] DEFAULT '{}';
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS gmp_scraped_at TIMESTAMP;
+
+-- Hash of the scraped content last written and when the source last confirmed it; the GMP
+-- job skips rewriting rows whose hash is unchanged and only moves checked_at
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+ALTER TABLE ipo_gmp ADD COLUMN IF NOT EXISTS checked_at TIMESTAMP;
+
+-- Slugs IPOs had before a rename; requests for them are redirected to the current slug
+CREATE TABLE ipo_slug_history (
+    slug VARCHAR(255) PRIMARY KEY,
+    ipo_id UUID NOT NULL REFERENCES ipo_list(id) ON DELETE CASCADE,
+    replaced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- GMP history series, one row per observation
+CREATE TABLE ipo_gmp_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ipo_name VARCHAR(255) NOT NULL,
+    company_code VARCHAR(50) NOT NULL,
+    gmp_value DECIMAL(10, 2) NOT NULL,
+    gain_percent DECIMAL(10, 2) NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- IPO Result Cache table for storing allotment check results
 CREATE TABLE ipo_result_cache (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -180,6 +287,19 @@ ALTER TABLE ipo_result_cache ADD CONSTRAINT ipo_result_cache_confidence_score_ra
 ALTER TABLE ipo_result_cache ADD CONSTRAINT ipo_result_cache_duplicate_count_non_negative CHECK (duplicate_count >= 0);
 ALTER TABLE ipo_result_cache ADD CONSTRAINT ipo_result_cache_expires_after_timestamp CHECK (expires_at > timestamp);
 
+-- Check audit columns: which channel wrote the result and a hashed client fingerprint
+ALTER TABLE ipo_result_cache ADD COLUMN IF NOT EXISTS source_channel VARCHAR(20) DEFAULT 'api';
+ALTER TABLE ipo_result_cache ADD COLUMN IF NOT EXISTS client_fingerprint VARCHAR(64);
+
+-- Sources (channel, check source and client) that returned the cached result; each new
+-- source returning the same result bumps duplicate_count and confidence_score
+ALTER TABLE ipo_result_cache ADD COLUMN IF NOT EXISTS sources TEXT[] NOT NULL DEFAULT '{}';
+
+-- Hashed account or device token the result was bound to when first stored by /check or
+-- /cache/store; a bound result can only be fetched back by PAN hash by its owner, an
+-- unbound one (NULL) by anyone
+ALTER TABLE ipo_result_cache ADD COLUMN IF NOT EXISTS owner_hash VARCHAR(64);
+
 -- IPO Update Log table for audit trail
 CREATE TABLE ipo_update_log (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -197,6 +317,344 @@ CREATE TABLE ipo_update_log (
 -- Add constraints for update log table
 ALTER TABLE ipo_update_log ADD CONSTRAINT ipo_update_log_field_name_not_empty CHECK (field_name != '');
 
+-- Hotness scores for not-yet-listed IPOs, rebuilt by the hotness job
+CREATE TABLE ipo_hotness (
+    ipo_id UUID PRIMARY KEY,
+    score DECIMAL(6, 2) NOT NULL DEFAULT 0,
+    breakdown JSONB NOT NULL DEFAULT '{}',
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_hotness_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Anchor investor allocation and lock-in expiries per IPO
+CREATE TABLE ipo_anchor_allocations (
+    ipo_id UUID PRIMARY KEY,
+    bid_date DATE,
+    shares_offered BIGINT,
+    amount_crore DECIMAL(12, 2),
+    lockin_30_date DATE,
+    lockin_90_date DATE,
+    lockin_estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_anchor_allocations_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Daily registrar lookup counters per PAN hash and IPO
+CREATE TABLE check_quota_usage (
+    pan_hash VARCHAR(255) NOT NULL,
+    ipo_id UUID NOT NULL,
+    usage_date DATE NOT NULL,
+    lookup_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (pan_hash, ipo_id, usage_date)
+);
+
+-- Transactional outbox for events emitted alongside IPO/GMP updates
+CREATE TABLE event_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+-- Add constraints for outbox table
+ALTER TABLE event_outbox ADD CONSTRAINT event_outbox_event_type_not_empty CHECK (event_type != '');
+ALTER TABLE event_outbox ADD CONSTRAINT event_outbox_status_valid CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED'));
+
+-- Notifiers that have received the event, so retries only go to the ones that failed
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS delivered_to TEXT[] NOT NULL DEFAULT '{}';
+
+-- Navigation and boilerplate regexes stripped from scraped description/about text
+CREATE TABLE scraper_text_patterns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL,
+    pattern TEXT NOT NULL,
+    description TEXT,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (kind, pattern)
+);
+
+-- Add constraints for text pattern table
+ALTER TABLE scraper_text_patterns ADD CONSTRAINT scraper_text_patterns_kind_valid CHECK (kind IN ('navigation', 'boilerplate'));
+ALTER TABLE scraper_text_patterns ADD CONSTRAINT scraper_text_patterns_pattern_not_empty CHECK (pattern != '');
+
+-- Single row recording that scraper_text_patterns got its built-in defaults, so they are
+-- seeded once and an admin can still remove every pattern
+CREATE TABLE scraper_text_patterns_seeded (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    seeded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Per-run reports of the scraper jobs for operational review and trend detection
+CREATE TABLE scrape_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    total_items INTEGER NOT NULL DEFAULT 0,
+    success_count INTEGER NOT NULL DEFAULT 0,
+    partial_count INTEGER NOT NULL DEFAULT 0,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    unchanged_count INTEGER NOT NULL DEFAULT 0,
+    field_stats JSONB NOT NULL DEFAULT '{}',
+    extraction_metrics JSONB NOT NULL DEFAULT '{}',
+    error_samples JSONB NOT NULL DEFAULT '[]'
+);
+
+-- Add constraints for scrape run table
+ALTER TABLE scrape_runs ADD CONSTRAINT scrape_runs_status_valid CHECK (status IN ('SUCCEEDED', 'PARTIAL', 'FAILED'));
+
+-- Opt-in saved PANs, encrypted at rest (AES-256-GCM) and keyed by a hash of the device/account ID
+CREATE TABLE saved_pans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_hash VARCHAR(64) NOT NULL,
+    pan_hash VARCHAR(64) NOT NULL,
+    pan_ciphertext BYTEA NOT NULL,
+    masked_pan VARCHAR(10) NOT NULL,
+    label VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_checked_at TIMESTAMP,
+    UNIQUE (owner_hash, pan_hash)
+);
+
+-- End-user accounts, identified by the email or phone used for passwordless login
+CREATE TABLE accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) UNIQUE,
+    phone VARCHAR(20) UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP
+);
+
+-- Add constraints for accounts table
+ALTER TABLE accounts ADD CONSTRAINT accounts_identity_present CHECK (email IS NOT NULL OR phone IS NOT NULL);
+
+-- Pending magic link / OTP logins; only hashes of the secrets are stored
+CREATE TABLE account_login_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel VARCHAR(10) NOT NULL,
+    destination VARCHAR(255) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add constraints for login challenge table
+ALTER TABLE account_login_challenges ADD CONSTRAINT account_login_challenges_channel_valid CHECK (channel IN ('email', 'sms'));
+
+-- Bearer sessions issued after login; tokens are stored hashed
+CREATE TABLE account_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CONSTRAINT fk_account_sessions_account_id FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Remediation migrations applied by the schema validator
+CREATE TABLE schema_migrations (
+    filename VARCHAR(255) PRIMARY KEY,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hourly category-wise subscription multiples of live IPOs, for subscription build-up charts
+CREATE TABLE ipo_subscription_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ipo_id UUID NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    multiple DECIMAL(10, 2) NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_subscription_history_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Application count and shares offered of the category, when the exchange data has them
+ALTER TABLE ipo_subscription_history ADD COLUMN IF NOT EXISTS applications BIGINT;
+ALTER TABLE ipo_subscription_history ADD COLUMN IF NOT EXISTS shares_offered BIGINT;
+
+-- Audit trail of admin corrections to GMP rows
+CREATE TABLE ipo_gmp_edit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gmp_id VARCHAR(100) NOT NULL,
+    company_code VARCHAR(50) NOT NULL,
+    field_name VARCHAR(50) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    edited_by VARCHAR(100) NOT NULL,
+    reason TEXT,
+    edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_gmp_edit_log_gmp_id FOREIGN KEY (gmp_id) REFERENCES ipo_gmp(id) ON DELETE CASCADE
+);
+
+-- Latest pre-open call auction price of IPOs on listing morning
+CREATE TABLE ipo_preopen_prices (
+    ipo_id UUID PRIMARY KEY,
+    symbol VARCHAR(50) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    total_buy_quantity BIGINT NOT NULL DEFAULT 0,
+    total_sell_quantity BIGINT NOT NULL DEFAULT 0,
+    market_time TIMESTAMP NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_preopen_prices_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Merge decisions of the IPO dedup pass; merged-away stock IDs are not recreated by the scraper
+CREATE TABLE ipo_merges (
+    duplicate_stock_id VARCHAR(100) PRIMARY KEY,
+    duplicate_name VARCHAR(255) NOT NULL,
+    canonical_ipo_id UUID NOT NULL,
+    canonical_stock_id VARCHAR(100) NOT NULL,
+    reason TEXT,
+    merged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_merges_canonical_ipo_id FOREIGN KEY (canonical_ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Who merged the IPOs: the admin for the merge tool, 'dedup' for automatic merges
+ALTER TABLE ipo_merges ADD COLUMN IF NOT EXISTS merged_by VARCHAR(100);
+
+-- User reports of wrong or stale allotment check results, one per check
+CREATE TABLE check_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    check_id VARCHAR(100) NOT NULL UNIQUE,
+    ipo_id UUID NOT NULL,
+    registrar VARCHAR(255) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    reported_status VARCHAR(100) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_check_feedback_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Price band revisions; ipo_list keeps the current band
+CREATE TABLE ipo_price_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ipo_id UUID NOT NULL,
+    price_band_low DECIMAL(10,2),
+    price_band_high DECIMAL(10,2),
+    previous_price_band_low DECIMAL(10,2),
+    previous_price_band_high DECIMAL(10,2),
+    source VARCHAR(100) NOT NULL,
+    revised_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ipo_price_revisions_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Nonces of redeemed signed URLs, kept until the URL expires so each works once
+CREATE TABLE signed_url_redemptions (
+    nonce VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Category-wise allotment ratios parsed from a registrar's basis of allotment PDF
+CREATE TABLE ipo_allotment_ratios (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ipo_id UUID NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    subscription_times DECIMAL(12,2),
+    allottees INTEGER NOT NULL,
+    applicants INTEGER NOT NULL,
+    source_url TEXT NOT NULL,
+    ingested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_ipo_allotment_ratios_category UNIQUE (ipo_id, category),
+    CONSTRAINT fk_ipo_allotment_ratios_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Allotment checks answered before results were declared, re-run by the result release
+-- check. The PAN is encrypted with PAN_VAULT_KEY and cleared once the re-check completes
+-- or expires.
+CREATE TABLE allotment_rechecks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ipo_id UUID NOT NULL,
+    pan_hash VARCHAR(255) NOT NULL,
+    pan_encrypted BYTEA,
+    source_channel VARCHAR(20) NOT NULL,
+    telegram_chat_id BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    result_status VARCHAR(50),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT uq_allotment_rechecks_ipo_pan UNIQUE (ipo_id, pan_hash),
+    CONSTRAINT fk_allotment_rechecks_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Hashed account that asked for the check, so the re-check's cached result is bound to it
+ALTER TABLE allotment_rechecks ADD COLUMN IF NOT EXISTS owner_hash VARCHAR(64);
+
+-- Telegram chats that asked with /remind for a reminder before an IPO's UPI mandate
+-- deadline; reminded_at is set once the reminder is sent
+CREATE TABLE mandate_reminder_subscriptions (
+    ipo_id UUID NOT NULL,
+    telegram_chat_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reminded_at TIMESTAMP,
+
+    CONSTRAINT pk_mandate_reminder_subscriptions PRIMARY KEY (ipo_id, telegram_chat_id),
+    CONSTRAINT fk_mandate_reminder_subscriptions_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- IPOs whose ipo.mandate_reminder event was emitted, so each IPO's event goes out once
+CREATE TABLE mandate_reminders (
+    ipo_id UUID PRIMARY KEY,
+    mandate_deadline TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_mandate_reminders_ipo_id FOREIGN KEY (ipo_id) REFERENCES ipo_list(id) ON DELETE CASCADE
+);
+
+-- Accounts that get the weekly IPO digest by email on Sunday evenings; last_sent_week is
+-- the Monday of the last week whose digest the account was sent
+CREATE TABLE digest_subscriptions (
+    account_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_sent_week DATE,
+    last_sent_at TIMESTAMP,
+
+    CONSTRAINT fk_digest_subscriptions_account_id FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Last completed run period of each periodic scheduled job, so a replica skips a period
+-- another replica already ran; periods are UTC and the lease expires when the period ends
+CREATE TABLE job_run_leases (
+    job_name VARCHAR(100) PRIMARY KEY,
+    period_start TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    instance_id VARCHAR(255) NOT NULL
+);
+
 -- Indexes for supporting tables
 
 -- GMP table indexes
@@ -207,6 +665,8 @@ CREATE INDEX idx_ipo_gmp_listing_date ON ipo_gmp(listing_date) WHERE listing_dat
 CREATE INDEX idx_ipo_gmp_stock_id ON ipo_gmp(stock_id) WHERE stock_id IS NOT NULL;
 CREATE INDEX idx_ipo_gmp_ipo_status ON ipo_gmp(ipo_status) WHERE ipo_status IS NOT NULL;
 CREATE INDEX idx_ipo_gmp_data_source ON ipo_gmp(data_source) WHERE data_source IS NOT NULL;
+CREATE INDEX idx_ipo_gmp_history_name_time ON ipo_gmp_history(ipo_name, recorded_at DESC);
+CREATE INDEX idx_ipo_gmp_manual_override ON ipo_gmp(override_expires_at) WHERE is_manual_override = TRUE;
 
 -- Result cache table indexes
 CREATE INDEX idx_ipo_result_cache_pan_hash ON ipo_result_cache(pan_hash);
@@ -215,9 +675,63 @@ CREATE INDEX idx_ipo_result_cache_expires_at ON ipo_result_cache(expires_at);
 CREATE INDEX idx_ipo_result_cache_timestamp ON ipo_result_cache(timestamp DESC);
 CREATE UNIQUE INDEX idx_ipo_result_cache_unique_check ON ipo_result_cache(pan_hash, ipo_id, application_number) WHERE application_number IS NOT NULL;
 CREATE UNIQUE INDEX idx_ipo_result_cache_pan_ipo ON ipo_result_cache(pan_hash, ipo_id);
+CREATE INDEX idx_ipo_result_cache_channel ON ipo_result_cache(source_channel, timestamp DESC);
+CREATE INDEX idx_ipo_result_cache_fingerprint ON ipo_result_cache(client_fingerprint) WHERE client_fingerprint IS NOT NULL;
 
 -- Update log table indexes
 CREATE INDEX idx_ipo_update_log_ipo_id ON ipo_update_log(ipo_id);
 CREATE INDEX idx_ipo_update_log_timestamp ON ipo_update_log(timestamp DESC);
 CREATE INDEX idx_ipo_update_log_field_name ON ipo_update_log(field_name);
-CREATE INDEX idx_ipo_update_log_source ON ipo_update_log(source) WHERE source IS NOT NULL;
\ No newline at end of file
+CREATE INDEX idx_ipo_update_log_source ON ipo_update_log(source) WHERE source IS NOT NULL;
+
+-- Hotness table indexes
+CREATE INDEX idx_ipo_hotness_score ON ipo_hotness(score DESC);
+
+-- Anchor lock-in indexes
+CREATE INDEX idx_ipo_anchor_lockin_30 ON ipo_anchor_allocations(lockin_30_date) WHERE lockin_30_date IS NOT NULL;
+CREATE INDEX idx_ipo_anchor_lockin_90 ON ipo_anchor_allocations(lockin_90_date) WHERE lockin_90_date IS NOT NULL;
+
+-- Check quota table indexes
+CREATE INDEX idx_check_quota_usage_date ON check_quota_usage(usage_date);
+
+-- Outbox table indexes
+CREATE INDEX idx_event_outbox_pending ON event_outbox(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_event_outbox_delivered_at ON event_outbox(delivered_at) WHERE delivered_at IS NOT NULL;
+
+-- Scrape run indexes
+CREATE INDEX idx_scrape_runs_job_started ON scrape_runs(job_name, started_at DESC);
+CREATE INDEX idx_scrape_runs_started_at ON scrape_runs(started_at DESC);
+
+-- Account indexes
+CREATE INDEX idx_account_login_challenges_secret ON account_login_challenges(secret_hash);
+CREATE INDEX idx_account_login_challenges_destination ON account_login_challenges(destination, created_at DESC);
+CREATE INDEX idx_account_sessions_account_id ON account_sessions(account_id);
+CREATE INDEX idx_account_sessions_expires_at ON account_sessions(expires_at);
+
+-- Subscription history indexes
+CREATE INDEX idx_ipo_subscription_history_ipo_time ON ipo_subscription_history(ipo_id, recorded_at);
+
+-- GMP edit log indexes
+CREATE INDEX idx_ipo_gmp_edit_log_company_code ON ipo_gmp_edit_log(company_code, edited_at DESC);
+
+-- IPO merge indexes
+CREATE INDEX idx_ipo_merges_canonical_ipo_id ON ipo_merges(canonical_ipo_id);
+
+-- Check feedback indexes
+CREATE INDEX idx_check_feedback_created_at ON check_feedback(created_at DESC);
+CREATE INDEX idx_check_feedback_ipo_id ON check_feedback(ipo_id);
+
+-- Price revision indexes
+CREATE INDEX idx_ipo_price_revisions_ipo_id ON ipo_price_revisions(ipo_id, revised_at);
+CREATE INDEX idx_ipo_slug_history_ipo_id ON ipo_slug_history(ipo_id, replaced_at DESC);
+
+-- Signed URL redemption indexes
+CREATE INDEX idx_signed_url_redemptions_expires_at ON signed_url_redemptions(expires_at);
+
+-- Issue size indexes
+CREATE INDEX idx_ipo_list_issue_size_amount ON ipo_list(issue_size_amount);
+CREATE INDEX idx_ipo_list_ofs_percent ON ipo_list(ofs_percent);
+CREATE INDEX idx_ipo_list_merged_into ON ipo_list(merged_into_ipo_id) WHERE merged_into_ipo_id IS NOT NULL;
+
+-- Allotment re-check indexes
+CREATE INDEX idx_allotment_rechecks_pending ON allotment_rechecks(ipo_id, created_at) WHERE status = 'PENDING';
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Schema remediation modes (SCHEMA_REMEDIATION)
+const (
+	RemediationOff      = "off"      // Validate only
+	RemediationGenerate = "generate" // Write a migration file for review
+	RemediationApply    = "apply"    // Write the migration file and apply it
+)
+
+// addConstraintPattern captures the table and constraint name of an ADD CONSTRAINT statement
+var addConstraintPattern = regexp.MustCompile(`(?i)^ALTER TABLE\s+(\w+)\s+ADD CONSTRAINT\s+(\w+)\s`)
+
+// RemediationPlan holds the statements that fix a schema validation report. Statements
+// are safe to apply automatically; ManualReview lists issues, such as column type
+// mismatches or missing tables, that need a human-written migration.
+type RemediationPlan struct {
+	Statements   []string
+	ManualReview []string
+}
+
+// Empty reports whether the plan has nothing to write
+func (p *RemediationPlan) Empty() bool {
+	return len(p.Statements) == 0 && len(p.ManualReview) == 0
+}
+
+// SQL renders the plan as a migration file
+func (p *RemediationPlan) SQL(generatedAt time.Time) string {
+	return remediationHeader(generatedAt) + p.body()
+}
+
+// remediationHeader is the comment opening a migration file, ending in a blank line
+func remediationHeader(generatedAt time.Time) string {
+	return "-- Schema remediation generated by SchemaValidator\n" +
+		fmt.Sprintf("-- Generated at %s; review before applying\n\n", generatedAt.UTC().Format(time.RFC3339))
+}
+
+// body renders the plan's statements and manual review notes, without the header
+func (p *RemediationPlan) body() string {
+	var builder strings.Builder
+	for _, statement := range p.Statements {
+		builder.WriteString(statement)
+		builder.WriteString(";\n")
+	}
+
+	if len(p.ManualReview) > 0 {
+		if len(p.Statements) > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("-- Manual review required:\n")
+		for _, issue := range p.ManualReview {
+			builder.WriteString("--   " + issue + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// schemaConstraintDefinitions indexes the ADD CONSTRAINT statements of the schema file by
+// constraint name, so missing constraints are recreated exactly as schema.sql defines them
+func schemaConstraintDefinitions(schemaPath string) (map[string]string, error) {
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	definitions := make(map[string]string)
+	for _, statement := range parseSQLStatements(string(content)) {
+		if match := addConstraintPattern.FindStringSubmatch(statement); match != nil {
+			definitions[match[2]] = statement
+		}
+	}
+	return definitions, nil
+}
+
+// PlanRemediation turns a validation report into ALTER TABLE statements: missing columns
+// are added with their expected type (nullable, so existing rows are unaffected) and
+// missing constraints are taken from the schema file. Missing indexes are left to
+// CreateMissingIndexes, which builds them concurrently outside a transaction. Statements
+// are sorted, columns before the constraints that may use them, so the same report
+// always gives the same plan.
+func (v *SchemaValidator) PlanRemediation(report *SchemaCompatibilityReport, schemaPath string) (*RemediationPlan, error) {
+	definitions, err := schemaConstraintDefinitions(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RemediationPlan{}
+	var constraints []string
+	for _, result := range report.ValidationResults {
+		for _, missing := range result.MissingColumns {
+			if missing == "entire table missing" {
+				plan.ManualReview = append(plan.ManualReview,
+					fmt.Sprintf("table %s is missing; run schema.sql to create it", result.TableName))
+				continue
+			}
+			column, columnType, found := strings.Cut(missing, " (")
+			if !found {
+				plan.ManualReview = append(plan.ManualReview, fmt.Sprintf("%s: missing column %s", result.TableName, missing))
+				continue
+			}
+			columnType = strings.ToUpper(strings.TrimSuffix(columnType, ")"))
+			plan.Statements = append(plan.Statements,
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", result.TableName, column, columnType))
+		}
+
+		for _, issue := range result.InvalidConstraints {
+			if definition, ok := definitions[issue]; ok {
+				constraints = append(constraints, definition)
+				continue
+			}
+			plan.ManualReview = append(plan.ManualReview, fmt.Sprintf("%s: %s", result.TableName, issue))
+		}
+	}
+	sort.Strings(plan.Statements)
+	sort.Strings(constraints)
+	sort.Strings(plan.ManualReview)
+	plan.Statements = append(plan.Statements, constraints...)
+	return plan, nil
+}
+
+// WriteMigrationFile writes the plan to a timestamped file in dir and returns its path.
+// When the latest remediation file in dir already holds the same plan, nothing is
+// written and that file's path is returned with written false, so restarts against an
+// unchanged schema do not pile up identical files.
+func WriteMigrationFile(dir string, plan *RemediationPlan, now time.Time) (path string, written bool, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", false, fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	latest, err := filepath.Glob(filepath.Join(dir, "*_schema_remediation.sql"))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list migration files: %w", err)
+	}
+	if len(latest) > 0 {
+		// Names start with their UTC timestamp, so the last one in order is the newest
+		sort.Strings(latest)
+		previous := latest[len(latest)-1]
+		content, err := os.ReadFile(previous)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read migration file: %w", err)
+		}
+		if _, body, found := strings.Cut(string(content), "\n\n"); found && body == plan.body() {
+			return previous, false, nil
+		}
+	}
+
+	path = filepath.Join(dir, now.UTC().Format("20060102150405")+"_schema_remediation.sql")
+	if err := os.WriteFile(path, []byte(plan.SQL(now)), 0o644); err != nil {
+		return "", false, fmt.Errorf("failed to write migration file: %w", err)
+	}
+	return path, true, nil
+}
+
+// ApplyMigrationFile runs a migration file in one transaction and records it in
+// schema_migrations; files already recorded are skipped
+func (v *SchemaValidator) ApplyMigrationFile(ctx context.Context, path string) error {
+	name := filepath.Base(path)
+
+	var applied bool
+	if err := v.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE filename = $1)`, name).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to check migration history: %w", err)
+	}
+	if applied {
+		v.logger.WithField("migration", name).Info("Migration already applied, skipping")
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file: %w", err)
+	}
+
+	tx, err := v.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range parseSQLStatements(string(content)) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %s failed on %q: %w", name, statement, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (filename) VALUES ($1)`, name); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	v.logger.WithField("migration", name).Info("Applied schema remediation migration")
+	return nil
+}
+
+// RemediateSchema validates the schema and, outside "off" mode, writes a migration for
+// missing columns and constraints to migrationsDir, applying it in "apply" mode. It returns
+// the written file's path, or "" when the schema needs no remediation.
+func RemediateSchema(schemaPath, migrationsDir, mode string) (string, error) {
+	if DB == nil {
+		return "", fmt.Errorf("database connection not established")
+	}
+	if mode == RemediationOff {
+		return "", nil
+	}
+
+	validator := NewSchemaValidator(DB)
+	report, err := validator.ValidateSchemaCompatibility()
+	if err != nil {
+		return "", fmt.Errorf("failed to validate schema compatibility: %w", err)
+	}
+
+	plan, err := validator.PlanRemediation(report, schemaPath)
+	if err != nil {
+		return "", err
+	}
+	if plan.Empty() {
+		logrus.Info("Schema validation found nothing to remediate")
+		return "", nil
+	}
+
+	path, written, err := WriteMigrationFile(migrationsDir, plan, time.Now())
+	if err != nil {
+		return "", err
+	}
+	message := "Wrote schema remediation migration"
+	if !written {
+		message = "Schema remediation unchanged since the last migration file"
+	}
+	logrus.WithFields(logrus.Fields{
+		"migration":     path,
+		"statements":    len(plan.Statements),
+		"manual_review": len(plan.ManualReview),
+	}).Warn(message)
+
+	if mode == RemediationApply && len(plan.Statements) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if err := validator.ApplyMigrationFile(ctx, path); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fenilmodi00/ipo-backend/jobs"
 	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type AdminHandler struct {
 	IPOService *services.IPOService
 	GMPJob     *jobs.GMPUpdateJob
+	// Invalidation, when set, clears the overridden IPO's cached data on every replica
+	Invalidation *services.CacheInvalidationBus
 }
 
 func NewAdminHandler(ipoService *services.IPOService, gmpJob *jobs.GMPUpdateJob) *AdminHandler {
@@ -24,11 +30,8 @@ func NewAdminHandler(ipoService *services.IPOService, gmpJob *jobs.GMPUpdateJob)
 
 func (h *AdminHandler) CreateIPO(c *fiber.Ctx) error {
 	var ipo models.IPO
-	if err := c.BodyParser(&ipo); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Invalid request body",
-		})
+	if err := parseJSONBody(c, &ipo); err != nil {
+		return invalidBodyResponse(c, err)
 	}
 
 	if err := h.IPOService.CreateIPO(c.Context(), &ipo); err != nil {
@@ -44,14 +47,99 @@ func (h *AdminHandler) CreateIPO(c *fiber.Ctx) error {
 	})
 }
 
+// GetIPO returns an IPO's detail with the lineage of each field, i.e. which source set
+// its current value and when, to debug conflicting data
+func (h *AdminHandler) GetIPO(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	ipo, err := h.IPOService.GetIPODetail(c.Context(), ipoID.String())
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if ipo == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+	lineage, err := h.IPOService.GetFieldLineage(c.Context(), ipoID)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, "Failed to load field lineage: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    ipo,
+		"lineage": lineage,
+	})
+}
+
+// GetIPOAsOf returns an IPO as it was at ?ts=, an RFC 3339 time or a YYYY-MM-DD date,
+// rebuilt from its update log, to settle what users saw at that time
+func (h *AdminHandler) GetIPOAsOf(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	raw := strings.TrimSpace(c.Query("ts"))
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		date, dateErr := models.ParseDate(raw)
+		if dateErr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "ts must be an RFC 3339 time or a YYYY-MM-DD date",
+			})
+		}
+		at = date.Time
+	}
+	if at.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "ts must not be in the future",
+		})
+	}
+
+	snapshot, err := h.IPOService.GetIPOAsOf(c.Context(), ipoID, at)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if snapshot == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    snapshot,
+	})
+}
+
 // TriggerGMPUpdate manually triggers the GMP update job
 func (h *AdminHandler) TriggerGMPUpdate(c *fiber.Ctx) error {
 	logrus.Info("Manual GMP update triggered via admin endpoint")
 
 	startTime := time.Now()
 
-	// Run the GMP update job
-	h.GMPJob.Run()
+	// Run the GMP update job, unless another replica is already running it
+	if !h.GMPJob.RunExclusive() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "GMP update job is already running on another instance",
+		})
+	}
 
 	duration := time.Since(startTime)
 
@@ -63,48 +151,427 @@ func (h *AdminHandler) TriggerGMPUpdate(c *fiber.Ctx) error {
 	})
 }
 
-// GetGMPData returns all GMP data in the database for debugging
-func (h *AdminHandler) GetGMPData(c *fiber.Ctx) error {
-	query := `
-		SELECT ipo_name, company_code, gmp_value, gain_percent, estimated_listing, last_updated
-		FROM ipo_gmp 
-		ORDER BY last_updated DESC
-		LIMIT 20
-	`
+// gmpOverrideRequest is the body accepted by SetGMPOverride
+type gmpOverrideRequest struct {
+	GMPValue           *float64 `json:"gmp_value"`
+	IPOPrice           *float64 `json:"ipo_price"`
+	SubscriptionStatus *string  `json:"subscription_status"`
+	ExpiresAt          string   `json:"expires_at"`
+	ExpiresInHours     int      `json:"expires_in_hours"`
+	SetBy              string   `json:"set_by"`
+	Reason             string   `json:"reason"`
+}
 
-	rows, err := h.IPOService.DB.QueryContext(c.Context(), query)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+// maxGMPOverrideDuration caps how long a manual GMP value can block the GMP job
+const maxGMPOverrideDuration = 30 * 24 * time.Hour
+
+// SetGMPOverride manually sets the GMP for an IPO; the GMP job leaves it alone until it expires
+func (h *AdminHandler) SetGMPOverride(c *fiber.Ctx) error {
+	ipoID := c.Params("id")
+	if _, err := uuid.Parse(ipoID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to query GMP data: " + err.Error(),
+			"error":   "Invalid IPO ID format",
 		})
 	}
-	defer rows.Close()
 
-	var gmpData []map[string]interface{}
-	for rows.Next() {
-		var ipoName, companyCode string
-		var gmpValue, gainPercent, estimatedListing float64
-		var lastUpdated time.Time
+	var req gmpOverrideRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
 
-		err := rows.Scan(&ipoName, &companyCode, &gmpValue, &gainPercent, &estimatedListing, &lastUpdated)
+	if req.GMPValue == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "gmp_value is required",
+		})
+	}
+	if req.IPOPrice != nil && *req.IPOPrice < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "ipo_price must not be negative",
+		})
+	}
+
+	// Default to a 24 hour override when no expiry is given
+	now := time.Now()
+	expiresAt := now.Add(24 * time.Hour)
+	if req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
 		if err != nil {
-			continue
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "expires_at must be an RFC3339 timestamp",
+			})
 		}
+		expiresAt = parsed
+	} else if req.ExpiresInHours > 0 {
+		expiresAt = now.Add(time.Duration(req.ExpiresInHours) * time.Hour)
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > maxGMPOverrideDuration {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Override expiry must be in the future and at most 30 days away",
+		})
+	}
+
+	ipo, err := h.IPOService.GetIPOByID(c.Context(), ipoID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to load IPO",
+		})
+	}
+	if ipo == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
 
-		gmpData = append(gmpData, map[string]interface{}{
-			"ipo_name":          ipoName,
-			"company_code":      companyCode,
-			"gmp_value":         gmpValue,
-			"gain_percent":      gainPercent,
-			"estimated_listing": estimatedListing,
-			"last_updated":      lastUpdated,
+	gmpData, err := h.GMPJob.SimpleGMPService.SetGMPOverride(c.Context(), ipo, models.GMPOverride{
+		GMPValue:           *req.GMPValue,
+		IPOPrice:           req.IPOPrice,
+		SubscriptionStatus: req.SubscriptionStatus,
+		ExpiresAt:          expiresAt,
+		SetBy:              req.SetBy,
+		Reason:             req.Reason,
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("ipo_id", ipoID).Error("Failed to save GMP override")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to save GMP override",
 		})
 	}
 
+	// Public responses may embed the old GMP value
+	h.GMPJob.ResponseCache.Invalidate()
+	if h.Invalidation != nil {
+		if _, err := h.Invalidation.Publish(c.Context(), services.CacheInvalidation{IPOID: ipoID}); err != nil {
+			logrus.WithError(err).WithField("ipo_id", ipoID).Warn("Failed to broadcast cache invalidation for GMP override")
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    gmpData,
-		"count":   len(gmpData),
+	})
+}
+
+// gmpCachePrefixes are the cache keys that embed GMP rows
+var gmpCachePrefixes = []string{"active_ipos_with_gmp", "ipo_with_gmp:", "/api/v1"}
+
+// PatchGMP corrects individual fields of a company's GMP row (sub2, kostak, subscription
+// status, listing gain); the GMP job merges around the corrected fields from then on
+func (h *AdminHandler) PatchGMP(c *fiber.Ctx) error {
+	companyCode := c.Params("company_code")
+
+	var patch models.GMPPatch
+	if err := parseJSONBody(c, &patch); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	result, err := h.GMPJob.SimpleGMPService.PatchGMP(c.Context(), companyCode, patch)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	h.GMPJob.ResponseCache.Invalidate()
+	if h.Invalidation != nil {
+		for _, prefix := range gmpCachePrefixes {
+			if _, err := h.Invalidation.Publish(c.Context(), services.CacheInvalidation{Prefix: prefix}); err != nil {
+				logrus.WithError(err).WithField("company_code", companyCode).Warn("Failed to broadcast cache invalidation for GMP patch")
+				break
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// MergeIPOs folds a duplicate IPO record into the one it duplicates: GMP history,
+// result-cache rows and other per-IPO data move to the target, and the source is
+// soft-deleted
+func (h *AdminHandler) MergeIPOs(c *fiber.Ctx) error {
+	var request models.IPOMergeRequest
+	if err := parseJSONBody(c, &request); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	result, err := h.IPOService.MergeIPOs(c.Context(), request)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	// Listings and GMP responses may still show the source
+	h.GMPJob.ResponseCache.Invalidate()
+	if h.Invalidation != nil {
+		for _, invalidation := range []services.CacheInvalidation{
+			{IPOID: result.SourceID.String()}, {IPOID: result.TargetID.String()}, {Prefix: "/api/v1"},
+		} {
+			if _, err := h.Invalidation.Publish(c.Context(), invalidation); err != nil {
+				logrus.WithError(err).WithField("source_id", result.SourceID).Warn("Failed to broadcast cache invalidation for IPO merge")
+				break
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SetManualCheck sets the link and instructions /check answers with for an IPO whose
+// registrar the checker cannot query
+func (h *AdminHandler) SetManualCheck(c *fiber.Ctx) error {
+	var check models.ManualCheck
+	if err := parseJSONBody(c, &check); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+	return h.saveManualCheck(c, &check)
+}
+
+// ClearManualCheck removes an IPO's manual check link and instructions
+func (h *AdminHandler) ClearManualCheck(c *fiber.Ctx) error {
+	return h.saveManualCheck(c, nil)
+}
+
+// closeCutoffsRequest is the body accepted by SetCloseCutoffs
+type closeCutoffsRequest struct {
+	RetailCutoff string `json:"retail_cutoff"`
+	HNICutoff    string `json:"hni_cutoff"`
+}
+
+// SetCloseCutoffs sets the IST times of day bidding closes at on an IPO's close date for
+// retail and HNI bids; an empty cutoff restores the default
+func (h *AdminHandler) SetCloseCutoffs(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	var req closeCutoffsRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	found, err := h.IPOService.SetCloseCutoffs(c.Context(), ipoID, req.RetailCutoff, req.HNICutoff)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	// The IPO responses carry the close times and status computed from the cutoffs
+	if h.Invalidation != nil {
+		if _, err := h.Invalidation.Publish(c.Context(), services.CacheInvalidation{IPOID: ipoID.String()}); err != nil {
+			logrus.WithError(err).WithField("ipo_id", ipoID).Warn("Failed to broadcast cache invalidation for close cutoffs")
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    req,
+	})
+}
+
+// GetExtractionOverride returns the extraction override of the IPO in the path, with
+// the fields its selectors can set
+func (h *AdminHandler) GetExtractionOverride(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	override, found, err := h.IPOService.GetExtractionOverride(c.Context(), ipoID)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":         true,
+		"data":            override,
+		"selector_fields": services.ExtractionSelectorFields(),
+	})
+}
+
+// SetExtractionOverride sets the selectors and manual fields the scraper applies over
+// what it extracts for an IPO whose page breaks the generic extractor
+func (h *AdminHandler) SetExtractionOverride(c *fiber.Ctx) error {
+	var override models.ExtractionOverride
+	if err := parseJSONBody(c, &override); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+	return h.saveExtractionOverride(c, &override)
+}
+
+// ClearExtractionOverride removes an IPO's extraction override
+func (h *AdminHandler) ClearExtractionOverride(c *fiber.Ctx) error {
+	return h.saveExtractionOverride(c, nil)
+}
+
+// saveExtractionOverride stores or, for nil, clears the extraction override of the IPO
+// in the path. It takes effect on the IPO's next scrape.
+func (h *AdminHandler) saveExtractionOverride(c *fiber.Ctx, override *models.ExtractionOverride) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	found, err := h.IPOService.SetExtractionOverride(c.Context(), ipoID, override)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    override,
+	})
+}
+
+// saveManualCheck stores or, for nil, clears the manual check of the IPO in the path
+func (h *AdminHandler) saveManualCheck(c *fiber.Ctx, check *models.ManualCheck) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	found, err := h.IPOService.SetManualCheck(c.Context(), ipoID, check)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	// The IPO detail embeds the manual check
+	if h.Invalidation != nil {
+		if _, err := h.Invalidation.Publish(c.Context(), services.CacheInvalidation{IPOID: ipoID.String()}); err != nil {
+			logrus.WithError(err).WithField("ipo_id", ipoID).Warn("Failed to broadcast cache invalidation for manual check")
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    check,
+	})
+}
+
+// GetIPOCompleteness lists IPOs whose completeness score is below the threshold
+// query parameter (default 80) along with the key fields they are missing
+func (h *AdminHandler) GetIPOCompleteness(c *fiber.Ctx) error {
+	threshold := services.DefaultCompletenessThreshold
+	if raw := c.Query("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 || parsed > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "threshold must be a number between 0 and 100",
+			})
+		}
+		threshold = parsed
+	}
+
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	reports, err := h.IPOService.GetIncompleteIPOs(c.Context(), threshold, limit)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, "Failed to load IPO completeness: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success":   true,
+		"data":      reports,
+		"count":     len(reports),
+		"threshold": threshold,
+	})
+}
+
+// GetGMPData returns one page of GMP rows for the admin UI. Supports page, per_page,
+// sort (last_updated or gain_percent), order (asc or desc), status (comma-separated
+// ipo_status values) and q (name or company code search).
+func (h *AdminHandler) GetGMPData(c *fiber.Ctx) error {
+	page, perPage := c.QueryInt("page", 1), c.QueryInt("per_page", 50)
+	if page < 1 || perPage < 1 || perPage > services.MaxGMPDataPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("page must be at least 1 and per_page between 1 and %d", services.MaxGMPDataPageSize),
+		})
+	}
+
+	filter := services.GMPDataFilter{
+		Search: c.Query("q"),
+		SortBy: c.Query("sort", "last_updated"),
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	}
+	switch strings.ToLower(c.Query("order", "desc")) {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "order must be asc or desc",
+		})
+	}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	gmpData, total, err := h.GMPJob.SimpleGMPService.ListGMPData(c.Context(), filter)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, "Failed to query GMP data: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success":     true,
+		"data":        gmpData,
+		"count":       len(gmpData),
+		"total":       total,
+		"page":        page,
+		"per_page":    perPage,
+		"total_pages": (total + perPage - 1) / perPage,
 	})
 }
@@ -0,0 +1,304 @@
+// Admin UI: plain pages over the admin API. Every call goes to the same origin, so the
+// admin IP allowlist applies to the operator's browser.
+(function () {
+  "use strict";
+
+  var API = "/api/v1";
+
+  function $(selector) {
+    return document.querySelector(selector);
+  }
+
+  function showStatus(message, isError) {
+    var status = $("#status");
+    status.textContent = message || "";
+    status.className = isError ? "error" : "";
+  }
+
+  // api calls the admin API and returns its parsed JSON, throwing the API's error message
+  // on failure
+  function api(method, path, body) {
+    var options = { method: method, headers: {} };
+    if (body !== undefined) {
+      options.headers["Content-Type"] = "application/json";
+      options.body = JSON.stringify(body);
+    }
+    return fetch(API + path, options).then(function (response) {
+      return response.json().catch(function () {
+        return {};
+      }).then(function (payload) {
+        if (!response.ok || payload.success === false) {
+          throw new Error(payload.error || response.status + " " + response.statusText);
+        }
+        return payload;
+      });
+    });
+  }
+
+  function failed(err) {
+    showStatus(err.message, true);
+  }
+
+  function format(value) {
+    if (value === null || value === undefined) {
+      return "";
+    }
+    if (typeof value === "object") {
+      return JSON.stringify(value);
+    }
+    return String(value);
+  }
+
+  // renderTable fills table with one row per item; columns are [heading, field or
+  // function(item)] pairs. onClick, when set, is called with the clicked row's item.
+  function renderTable(table, columns, items, onClick) {
+    table.textContent = "";
+    var head = table.createTHead().insertRow();
+    columns.forEach(function (column) {
+      var cell = document.createElement("th");
+      cell.textContent = column[0];
+      head.appendChild(cell);
+    });
+    var body = table.createTBody();
+    if (!items || items.length === 0) {
+      var empty = body.insertRow().insertCell();
+      empty.colSpan = columns.length;
+      empty.className = "empty";
+      empty.textContent = "Nothing to show";
+      return;
+    }
+    items.forEach(function (item) {
+      var row = body.insertRow();
+      columns.forEach(function (column) {
+        var value = typeof column[1] === "function" ? column[1](item) : item[column[1]];
+        row.insertCell().textContent = format(value);
+      });
+      if (onClick) {
+        row.className = "clickable";
+        row.addEventListener("click", function () {
+          onClick(item);
+        });
+      }
+    });
+  }
+
+  function renderDetail(container, title, value) {
+    container.textContent = "";
+    var heading = document.createElement("h2");
+    heading.textContent = title;
+    var pre = document.createElement("pre");
+    pre.textContent = JSON.stringify(value, null, 2);
+    container.appendChild(heading);
+    container.appendChild(pre);
+  }
+
+  // formValues returns a form's non-empty fields, numbers parsed for number inputs
+  function formValues(form) {
+    var values = {};
+    Array.prototype.forEach.call(form.elements, function (input) {
+      if (!input.name || input.value === "") {
+        return;
+      }
+      values[input.name] = input.type === "number" ? Number(input.value) : input.value.trim();
+    });
+    return values;
+  }
+
+  function query(values) {
+    var params = new URLSearchParams();
+    Object.keys(values).forEach(function (key) {
+      params.set(key, values[key]);
+    });
+    var encoded = params.toString();
+    return encoded ? "?" + encoded : "";
+  }
+
+  // Jobs
+
+  function loadJobs() {
+    api("GET", "/performance/metrics").then(function (payload) {
+      var runs = payload.data.job_runs || {};
+      renderTable($("#job-runs"), [
+        ["Job", "job"], ["Started", "started_at"], ["Deadline", "deadline"],
+        ["Overdue", "overdue"], ["Overran", "overran"]
+      ], runs.active);
+    }).catch(failed);
+
+    api("GET", "/admin/jobs/dependencies").then(function (payload) {
+      renderTable($("#job-status"), [
+        ["Job", "job"], ["Last outcome", "last_outcome"], ["Last run", "last_run_at"],
+        ["Blocked by", "blocked_by"], ["Deferred until", "deferred_until"], ["Runs", "runs"],
+        ["Skipped", "skipped"], ["Queued", "queued"], ["Deferred", "deferred"]
+      ], payload.data.jobs);
+      renderTable($("#job-dependencies"), [
+        ["Job", "job"], ["Depends on", "depends_on"], ["Max age", "max_age"],
+        ["On unmet", "on_unmet"], ["Last success", "dependency_last_success"],
+        ["Satisfied", "satisfied"], ["Error", "error"]
+      ], payload.data.dependencies);
+    }).catch(failed);
+
+    api("GET", "/admin/jobs/locks").then(function (payload) {
+      $("#job-instance").textContent = "instance " + payload.instance;
+      renderTable($("#job-locks"), [
+        ["Job", "job_name"], ["Held", "held"], ["Acquired", "acquired"], ["Skipped", "skipped"],
+        ["Errors", "errors"], ["Lost", "lost"], ["Last acquired", "last_acquired"],
+        ["Last released", "last_released"], ["Last hold", "last_hold_time"]
+      ], payload.data);
+    }).catch(failed);
+  }
+
+  function triggerGMPUpdate() {
+    var button = $("#gmp-trigger");
+    button.disabled = true;
+    showStatus("Running the GMP update...");
+    api("POST", "/admin/gmp/update").then(function (payload) {
+      showStatus(payload.message + " in " + payload.duration);
+      loadJobs();
+    }).catch(failed).then(function () {
+      button.disabled = false;
+    });
+  }
+
+  // Scrape runs
+
+  function loadRuns(event) {
+    if (event) {
+      event.preventDefault();
+    }
+    api("GET", "/admin/scrape-runs" + query(formValues($("#runs-filter")))).then(function (payload) {
+      renderTable($("#runs-list"), [
+        ["Job", "job_name"], ["Status", "status"], ["Started", "started_at"],
+        ["Duration ms", "duration_ms"], ["Items", "total_items"], ["Succeeded", "success_count"],
+        ["Partial", "partial_count"], ["Failed", "failure_count"]
+      ], payload.data, showRun);
+      $("#run-detail").textContent = "";
+    }).catch(failed);
+  }
+
+  function showRun(run) {
+    api("GET", "/admin/scrape-runs/" + encodeURIComponent(run.id)).then(function (payload) {
+      renderDetail($("#run-detail"), "Run " + run.id, payload.data);
+    }).catch(failed);
+  }
+
+  // Data review
+
+  function loadReview(event) {
+    if (event) {
+      event.preventDefault();
+    }
+    api("GET", "/admin/ipos/completeness" + query(formValues($("#review-filter")))).then(function (payload) {
+      renderTable($("#review-list"), [
+        ["IPO", "name"], ["Status", "status"], ["Score", "completeness_score"],
+        ["Missing", function (item) { return (item.missing_fields || []).join(", "); }],
+        ["Updated", "updated_at"]
+      ], payload.data, showIPO);
+      $("#review-detail").textContent = "";
+    }).catch(failed);
+  }
+
+  function showIPO(item) {
+    var id = encodeURIComponent(item.ipo_id);
+    Promise.all([
+      api("GET", "/admin/ipos/" + id),
+      api("GET", "/admin/ipos/" + id + "/extraction-override")
+    ]).then(function (payloads) {
+      renderDetail($("#review-detail"), item.name, {
+        ipo: payloads[0].data,
+        lineage: payloads[0].lineage,
+        extraction_override: payloads[1].data
+      });
+    }).catch(failed);
+  }
+
+  // GMP
+
+  var gmpPage = 1;
+
+  function loadGMP(event) {
+    if (event) {
+      event.preventDefault();
+      gmpPage = 1;
+    }
+    var values = formValues($("#gmp-filter"));
+    values.page = gmpPage;
+    api("GET", "/admin/gmp/data" + query(values)).then(function (payload) {
+      $("#gmp-page").textContent = "Page " + payload.page + " of " + payload.total_pages + " (" + payload.total + " rows)";
+      $("#gmp-prev").disabled = payload.page <= 1;
+      $("#gmp-next").disabled = payload.page >= payload.total_pages;
+      renderTable($("#gmp-list"), [
+        ["IPO", "ipo_name"], ["Code", "company_code"], ["Status", "ipo_status"], ["Price", "ipo_price"],
+        ["GMP", "gmp_value"], ["Gain %", "gain_percent"], ["Sub2", "sub2"], ["Kostak", "kostak"],
+        ["Subscription", "subscription_status"], ["Override", "is_manual_override"], ["Updated", "last_updated"]
+      ], payload.data, function (row) {
+        $("#gmp-patch").elements.company_code.value = row.company_code;
+      });
+    }).catch(failed);
+  }
+
+  function patchGMP(event) {
+    event.preventDefault();
+    var values = formValues(event.target);
+    var code = values.company_code;
+    delete values.company_code;
+    if (values.unlock) {
+      values.unlock = values.unlock.split(",").map(function (field) { return field.trim(); }).filter(Boolean);
+    }
+    api("PATCH", "/admin/gmp/" + encodeURIComponent(code), values).then(function (payload) {
+      showStatus("Patched " + code + ": " + (payload.data.edits || []).length + " edits");
+      loadGMP();
+    }).catch(failed);
+  }
+
+  function overrideGMP(event) {
+    event.preventDefault();
+    var values = formValues(event.target);
+    var id = values.ipo_id;
+    delete values.ipo_id;
+    if (!window.confirm("Override the GMP of " + id + " with " + values.gmp_value + "?")) {
+      return;
+    }
+    api("PUT", "/admin/ipos/" + encodeURIComponent(id) + "/gmp", values).then(function () {
+      showStatus("GMP override saved");
+      loadGMP();
+    }).catch(failed);
+  }
+
+  // Tabs
+
+  var loaders = { jobs: loadJobs, runs: loadRuns, review: loadReview, gmp: loadGMP };
+
+  function openTab(name) {
+    Array.prototype.forEach.call(document.querySelectorAll("nav button"), function (button) {
+      button.classList.toggle("active", button.dataset.tab === name);
+    });
+    Array.prototype.forEach.call(document.querySelectorAll(".tab"), function (tab) {
+      tab.hidden = tab.id !== "tab-" + name;
+    });
+    showStatus("");
+    loaders[name]();
+  }
+
+  Array.prototype.forEach.call(document.querySelectorAll("nav button"), function (button) {
+    button.addEventListener("click", function () {
+      openTab(button.dataset.tab);
+    });
+  });
+  $("#jobs-refresh").addEventListener("click", loadJobs);
+  $("#gmp-trigger").addEventListener("click", triggerGMPUpdate);
+  $("#runs-filter").addEventListener("submit", loadRuns);
+  $("#review-filter").addEventListener("submit", loadReview);
+  $("#gmp-filter").addEventListener("submit", loadGMP);
+  $("#gmp-prev").addEventListener("click", function () {
+    gmpPage--;
+    loadGMP();
+  });
+  $("#gmp-next").addEventListener("click", function () {
+    gmpPage++;
+    loadGMP();
+  });
+  $("#gmp-patch").addEventListener("submit", patchGMP);
+  $("#gmp-override").addEventListener("submit", overrideGMP);
+
+  openTab("jobs");
+})();
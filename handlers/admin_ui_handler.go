@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+//go:embed admin_ui
+var adminUIFiles embed.FS
+
+// AdminUIHandler serves the admin UI, static pages built into the binary that call the
+// admin API from the operator's browser
+type AdminUIHandler struct {
+	Files http.FileSystem
+}
+
+func NewAdminUIHandler() *AdminUIHandler {
+	files, err := fs.Sub(adminUIFiles, "admin_ui")
+	if err != nil {
+		panic(err)
+	}
+	return &AdminUIHandler{Files: http.FS(files)}
+}
+
+// GetAsset serves one file of the UI, or its index page for the UI's root. Pages only
+// load scripts and styles from this server and cannot be framed.
+func (h *AdminUIHandler) GetAsset(c *fiber.Ctx) error {
+	name := strings.TrimPrefix(c.Params("*"), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'self'; frame-ancestors 'none'")
+	c.Set(fiber.HeaderXFrameOptions, "DENY")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	if err := filesystem.SendFile(c, h.Files, name); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Not found",
+		})
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AllotmentRatioHandler serves allotment chances and ingests basis of allotment PDFs
+type AllotmentRatioHandler struct {
+	Ratios *services.AllotmentRatioService
+}
+
+func NewAllotmentRatioHandler(ratios *services.AllotmentRatioService) *AllotmentRatioHandler {
+	return &AllotmentRatioHandler{Ratios: ratios}
+}
+
+// GetAllotmentProbability returns an IPO's per-category allotment chances: official once
+// the basis of allotment is ingested, estimated from subscription before that
+func (h *AllotmentRatioHandler) GetAllotmentProbability(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	probability, err := h.Ratios.GetAllotmentProbability(c.Context(), ipoID)
+	if err != nil {
+		return errorResponse(c, "allotment_api", err, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    probability,
+	})
+}
+
+type basisOfAllotmentRequest struct {
+	PDFURL string `json:"pdf_url"`
+}
+
+// IngestBasisOfAllotment downloads and parses a registrar's basis of allotment PDF,
+// replacing the IPO's stored ratios
+func (h *AllotmentRatioHandler) IngestBasisOfAllotment(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+	var req basisOfAllotmentRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	ratios, err := h.Ratios.IngestBasisOfAllotment(c.Context(), ipoID, req.PDFURL)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    ratios,
+		"count":   len(ratios),
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AnalyticsHandler serves derived market-event views over the IPO data
+type AnalyticsHandler struct {
+	IPOService *services.IPOService
+	// Leaderboard, when set, serves the listing gain leaderboard
+	Leaderboard *services.LeaderboardService
+}
+
+func NewAnalyticsHandler(ipoService *services.IPOService) *AnalyticsHandler {
+	return &AnalyticsHandler{IPOService: ipoService}
+}
+
+// GetLockInCalendar lists anchor lock-in expiries in the next ?days (default 90, max 365)
+func (h *AnalyticsHandler) GetLockInCalendar(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 90)
+	if days < 1 || days > 365 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "days must be between 1 and 365",
+		})
+	}
+
+	expiries, err := h.IPOService.GetLockInCalendar(c.Context(), days)
+	if err != nil {
+		return errorResponse(c, "analytics_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    expiries,
+		"count":   len(expiries),
+	})
+}
+
+// GetScreener lists IPOs with their size and fresh issue / OFS breakdown. It takes the
+// ?status= filter and the sort, order, issue size and OFS percentage parameters of the
+// IPO list.
+func (h *AnalyticsHandler) GetScreener(c *fiber.Ctx) error {
+	options, err := parseIPOListOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	entries, err := h.IPOService.ScreenIPOs(c.Context(), c.Query("status", "all"), options)
+	if err != nil {
+		return errorResponse(c, "analytics_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    entries,
+		"count":   len(entries),
+	})
+}
+
+// firstLeaderboardYear is the earliest year the leaderboard accepts
+const firstLeaderboardYear = 2000
+
+// GetLeaderboard returns the best and worst IPOs of ?year (default the current year) by
+// ?metric (listing_gain), ?limit (default 10, max 50) of each
+func (h *AnalyticsHandler) GetLeaderboard(c *fiber.Ctx) error {
+	if h.Leaderboard == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Leaderboard is not available",
+		})
+	}
+
+	currentYear := h.Leaderboard.CurrentYear()
+	year := c.QueryInt("year", currentYear)
+	if year < firstLeaderboardYear || year > currentYear {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("year must be between %d and %d", firstLeaderboardYear, currentYear),
+		})
+	}
+	metric := c.Query("metric", services.LeaderboardMetricListingGain)
+	if !slices.Contains(services.LeaderboardMetrics, metric) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "metric must be one of: " + strings.Join(services.LeaderboardMetrics, ", "),
+		})
+	}
+	limit := c.QueryInt("limit", services.DefaultLeaderboardSize)
+	if limit < 1 || limit > services.MaxLeaderboardSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("limit must be between 1 and %d", services.MaxLeaderboardSize),
+		})
+	}
+
+	leaderboard, err := h.Leaderboard.GetLeaderboard(c.Context(), year, metric, limit)
+	if err != nil {
+		return errorResponse(c, "analytics_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    leaderboard,
+	})
+}
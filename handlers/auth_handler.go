@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthHandler serves passwordless end-user login and session endpoints
+type AuthHandler struct {
+	Accounts *services.AccountService
+}
+
+func NewAuthHandler(accounts *services.AccountService) *AuthHandler {
+	return &AuthHandler{Accounts: accounts}
+}
+
+// StartLogin sends a magic link (email) or one-time code (phone)
+func (h *AuthHandler) StartLogin(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+	}
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	channel, destination := "", ""
+	switch {
+	case req.Email != "" && req.Phone != "":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Provide either email or phone, not both",
+		})
+	case req.Email != "":
+		channel, destination = models.LoginChannelEmail, req.Email
+	case req.Phone != "":
+		channel, destination = models.LoginChannelSMS, req.Phone
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "email or phone is required",
+		})
+	}
+
+	challenge, err := h.Accounts.StartLogin(c.Context(), channel, destination)
+	if err != nil {
+		return errorResponse(c, "auth", err, err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"data":    challenge,
+	})
+}
+
+// VerifyLogin exchanges a magic link token or challenge code for a session token
+func (h *AuthHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req struct {
+		ChallengeID string `json:"challenge_id"`
+		Code        string `json:"code"`
+		Token       string `json:"token"`
+	}
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	session, err := h.Accounts.VerifyLogin(c.Context(), req.ChallengeID, req.Code, req.Token)
+	if err != nil {
+		return errorResponse(c, "auth", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// GetMe returns the signed-in account
+func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    middleware.AccountFromContext(c),
+	})
+}
+
+// Logout revokes the current session token
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	if err := h.Accounts.Logout(c.Context(), middleware.BearerTokenFromContext(c)); err != nil {
+		return errorResponse(c, "auth", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{"success": true})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseJSONBody strictly decodes the request body into out. Unlike c.BodyParser it rejects
+// unknown fields, so a misspelled key fails loudly instead of being silently dropped,
+// and it rejects trailing data after the JSON value.
+func parseJSONBody(c *fiber.Ctx, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("request body is empty")
+		}
+		return err
+	}
+	if decoder.More() {
+		return errors.New("request body must contain a single JSON object")
+	}
+	return nil
+}
+
+// invalidBodyResponse answers a request whose body failed parseJSONBody
+func invalidBodyResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error":   "Invalid request body: " + err.Error(),
+	})
+}
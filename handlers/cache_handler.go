@@ -1,29 +1,85 @@
 package handlers
 
 import (
+	"github.com/fenilmodi00/ipo-backend/middleware"
 	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
+// DeviceTokenHeader carries a server-issued device token that binds results to an
+// anonymous device
+const DeviceTokenHeader = "X-Device-Token"
+
 type CacheHandler struct {
 	Service *services.CacheService
+	// FingerprintKey keys the client fingerprints stored with submitted results and signs
+	// device tokens
+	FingerprintKey []byte
 }
 
 func NewCacheHandler(service *services.CacheService) *CacheHandler {
 	return &CacheHandler{Service: service}
 }
 
+// resultOwner identifies who checks, stores or fetches a result: the signed-in account if
+// any, otherwise the device of an X-Device-Token header signed with key. Both are
+// optional, so the owner may be empty; a token that doesn't verify is an error.
+func resultOwner(c *fiber.Ctx, key []byte) (string, error) {
+	if account := middleware.AccountFromContext(c); account != nil {
+		return services.AccountOwnerHash(account.ID), nil
+	}
+	if c.Get(DeviceTokenHeader) == "" {
+		return "", nil
+	}
+	return services.DeviceTokenOwnerHash(key, c.Get(DeviceTokenHeader))
+}
+
+// IssueDeviceToken issues an anonymous device token. Sending it as X-Device-Token when
+// checking or storing results binds them to the device, so only requests with the same
+// token can fetch them back by PAN hash.
+func (h *CacheHandler) IssueDeviceToken(c *fiber.Ctx) error {
+	token, err := services.NewDeviceToken(h.FingerprintKey)
+	if err != nil {
+		return errorResponse(c, "cache_api", err, "Failed to issue device token")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"device_token": token},
+	})
+}
+
+// StoreResult stores a result submitted by a client. The route only admits requests
+// signed by a known client (middleware.RequestSignatures). A result first stored by a
+// signed-in account or with a device token is bound to it, and only that owner can fetch
+// it back by PAN hash. A result already bound to another owner is left unchanged, with
+// the same response, so storing cannot probe which PAN hashes have results.
 func (h *CacheHandler) StoreResult(c *fiber.Ctx) error {
 	var result models.IPOResultCache
-	if err := c.BodyParser(&result); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Invalid request body",
-		})
+	if err := parseJSONBody(c, &result); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+	if err := h.Service.ValidateSubmittedResult(c.Context(), &result); err != nil {
+		return errorResponse(c, "cache_api", err, err.Error())
+	}
+	owner, err := resultOwner(c, h.FingerprintKey)
+	if err != nil {
+		return errorResponse(c, "cache_api", err, err.Error())
 	}
+	result.OwnerHash = owner
 
-	if err := h.Service.StoreResult(c.Context(), &result); err != nil {
+	// Audit fields are derived from the request, never trusted from the body. The
+	// fingerprint is the signing client's, so one client agreeing with itself from other
+	// addresses does not raise the result's confidence.
+	result.UserAgent = c.Get(fiber.HeaderUserAgent)
+	result.SourceChannel = models.SourceChannelAPI
+	client, _ := c.Locals(middleware.RequestClientLocal).(string)
+	result.ClientFingerprint = shared.ClientFingerprint(h.FingerprintKey, client, "")
+
+	if _, err := h.Service.StoreSubmittedResult(c.Context(), &result); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"error":   err.Error(),
@@ -36,16 +92,26 @@ func (h *CacheHandler) StoreResult(c *fiber.Ctx) error {
 	})
 }
 
+// GetCachedResult returns a cached result by IPO and PAN hash. Anyone may fetch an
+// unbound result; one bound to another account or device gets the same 404 as a missing
+// one, so PAN hashes can't be probed for bound results.
 func (h *CacheHandler) GetCachedResult(c *fiber.Ctx) error {
-	ipoID := c.Params("ipo_id")
+	ipoID, err := uuid.Parse(c.Params("ipo_id"))
+	if err != nil {
+		return errorResponse(c, "cache_api", shared.ValidationErrorf("invalid ipo_id"), "ipo_id must be a UUID")
+	}
 	panHash := c.Params("pan_hash")
+	if !shared.IsValidPANHash(panHash) {
+		return errorResponse(c, "cache_api", shared.ValidationErrorf("invalid pan_hash"), "pan_hash must be 64 lowercase hex characters")
+	}
+	owner, err := resultOwner(c, h.FingerprintKey)
+	if err != nil {
+		return errorResponse(c, "cache_api", err, err.Error())
+	}
 
-	result, err := h.Service.GetCachedResult(c.Context(), ipoID, panHash)
+	result, err := h.Service.GetCachedResultFor(c.Context(), ipoID.String(), panHash, owner)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return errorResponse(c, "cache_api", err, "Failed to fetch cached result")
 	}
 	if result == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -1,44 +1,88 @@
 package handlers
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 type CheckHandler struct {
 	IPOService       *services.IPOService
 	AllotmentChecker *services.AllotmentChecker
 	CacheService     *services.CacheService
+	CheckQueue       *services.AllotmentCheckQueue
+	QuotaService     *services.CheckQuotaService
+	// Feedback, when set, stores user reports of wrong or stale results
+	Feedback *services.CheckFeedbackService
+	// IPOCache, when set, serves IPO lookups through the per-IPO cache, which also
+	// remembers unknown IPO IDs for a short while
+	IPOCache *services.CachedIPOService
+	// Sandbox, when enabled, answers checks sent with a sandbox key with synthetic results
+	Sandbox *services.CheckSandbox
+	// FingerprintKey keys the client fingerprints recorded with each check
+	FingerprintKey []byte
+	// SyncWait is how long POST /check waits for a result before answering
+	// with a check_id; fast registrars finish inside it and respond synchronously
+	SyncWait time.Duration
 }
 
-func NewCheckHandler(ipo *services.IPOService, allotmentChecker *services.AllotmentChecker, cache *services.CacheService) *CheckHandler {
+func NewCheckHandler(ipo *services.IPOService, allotmentChecker *services.AllotmentChecker, cache *services.CacheService, queue *services.AllotmentCheckQueue, quota *services.CheckQuotaService) *CheckHandler {
 	return &CheckHandler{
 		IPOService:       ipo,
 		AllotmentChecker: allotmentChecker,
 		CacheService:     cache,
+		CheckQueue:       queue,
+		QuotaService:     quota,
+		SyncWait:         5 * time.Second,
 	}
 }
 
+// CheckAllotment submits an allotment check. The result is returned directly when the
+// registrar answers within SyncWait; otherwise a check_id is returned for polling.
+// Pass ?async=true to always get a check_id immediately, and ?debug=true to get the
+// time spent in each stage of the check in meta.stages.
 func (h *CheckHandler) CheckAllotment(c *fiber.Ctx) error {
+	stages := newCheckStages(c)
+	endValidation := stages.timer.Start(services.CheckStageValidation)
+
 	type Request struct {
 		IPOID string `json:"ipo_id"`
 		PAN   string `json:"pan"`
 	}
 	var req Request
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	if err := parseJSONBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request: " + err.Error()})
 	}
 
-	// 1. Check Cache First
-	// TODO: Hash PAN before checking cache (omitted for brevity in this step)
-	// cached, _ := h.CacheService.GetCachedResult(c.Context(), req.IPOID, req.PAN)
-	// if cached != nil { ... return cached ... }
+	if !shared.IsValidPAN(req.PAN) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid PAN format"})
+	}
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	owner, err := resultOwner(c, h.FingerprintKey)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	panHash := shared.HashPAN(req.PAN)
+	endValidation()
 
-	// 2. Get IPO Details
-	ipo, err := h.IPOService.GetIPOByID(c.Context(), req.IPOID)
+	// 1. Get IPO Details
+	endIPOLookup := stages.timer.Start(services.CheckStageIPOLookup)
+	var ipo *models.IPO
+	if h.IPOCache != nil {
+		ipo, err = h.IPOCache.GetIPOByID(c.Context(), req.IPOID)
+	} else {
+		ipo, err = h.IPOService.GetIPOByID(c.Context(), req.IPOID)
+	}
+	endIPOLookup()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -46,25 +90,441 @@ func (h *CheckHandler) CheckAllotment(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "IPO not found"})
 	}
 
-	// 3. Check Allotment Status
-	status, shares, err := h.AllotmentChecker.CheckAllotmentStatus(c.Context(), ipo, req.PAN)
+	// Sandbox checks never reach the cache, quota or registrar
+	if key := c.Get(services.SandboxKeyHeader); key != "" {
+		return h.sandboxCheck(c, ipo, req.PAN, key, lang)
+	}
+
+	// 2. Check Cache First; cached results don't count against the quota
+	endCacheLookup := stages.timer.Start(services.CheckStageCacheLookup)
+	cached, err := h.CacheService.GetCachedResult(c.Context(), ipo.ID.String(), panHash)
+	endCacheLookup()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to read cached allotment result")
+	}
+	if cached != nil {
+		return c.JSON(stages.finish(h.CheckQueue, fiber.Map{
+			"success": true,
+			"cached":  true,
+			"data":    services.LabelResult(cached, lang),
+		}))
+	}
+
+	// Registrars the checker does not support are checked by hand, when an admin has
+	// said how; such checks do not count against the quota
+	if !services.CheckerSupports(ipo) {
+		if manual := h.manualCheck(c, ipo.ID); manual != nil {
+			return manualCheckResponse(c, ipo.ID, ipo.Registrar, manual, "This IPO's registrar cannot be checked automatically")
+		}
+	}
+
+	// 3. Enforce the daily per-PAN quota before hitting the registrar
+	var quota *services.CheckQuotaResult
+	if h.QuotaService != nil {
+		endQuota := stages.timer.Start(services.CheckStageQuota)
+		quota, err = h.QuotaService.Consume(c.Context(), panHash, ipo.ID.String())
+		endQuota()
+		if err != nil {
+			// Fail open: a quota store outage shouldn't block checks
+			logrus.WithError(err).Warn("Check quota unavailable, allowing request")
+		} else {
+			c.Set("X-RateLimit-Limit", strconv.Itoa(quota.Limit))
+			c.Set("X-RateLimit-Remaining", strconv.Itoa(quota.Remaining))
+			c.Set("X-RateLimit-Reset", strconv.FormatInt(quota.ResetAt.Unix(), 10))
+
+			if !quota.Allowed {
+				c.Set("Retry-After", strconv.Itoa(int(time.Until(quota.ResetAt).Seconds())+1))
+				return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+					"success":  false,
+					"error":    "Daily check limit reached for this PAN and IPO",
+					"limit":    quota.Limit,
+					"used":     quota.Used,
+					"reset_at": quota.ResetAt,
+				})
+			}
+		}
+	}
+
+	// 4. Queue the allotment check
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	check, done, err := h.CheckQueue.Submit(ipo, shared.NormalizePAN(req.PAN), services.CheckRequestMeta{
+		SourceChannel:     models.SourceChannelAPI,
+		ClientFingerprint: shared.ClientFingerprint(h.FingerprintKey, c.IP(), userAgent),
+		UserAgent:         userAgent,
+		OwnerHash:         owner,
+	})
 	if err != nil {
-		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Failed to check status: " + err.Error()})
+		if h.refundQuota(c, panHash, ipo.ID.String(), quota) {
+			c.Set("X-RateLimit-Remaining", strconv.Itoa(quota.Remaining+1))
+		}
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+	stages.checkID = check.CheckID
+
+	// 5. Wait briefly for fast registrars, unless the caller asked for async mode
+	if !c.QueryBool("async") {
+		select {
+		case <-done:
+			return h.syncCheckResponse(c, h.CheckQueue.Get(check.CheckID), lang, stages)
+		case <-time.After(h.SyncWait):
+		case <-c.Context().Done():
+		}
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(stages.finish(h.CheckQueue, fiber.Map{
+		"success": true,
+		"data":    check,
+	}))
+}
+
+// checkStages times the stages of one POST /check request
+type checkStages struct {
+	timer   *shared.StageTimer
+	started time.Time
+	debug   bool
+	// checkID is the queued check, whose stages the queue times
+	checkID string
+}
+
+func newCheckStages(c *fiber.Ctx) *checkStages {
+	return &checkStages{timer: shared.NewStageTimer(), started: time.Now(), debug: c.QueryBool("debug")}
+}
+
+// finish records the request's stages and total time in the check stage metrics, where
+// the queue records its own, and returns response with every stage so far, the queued
+// check's included, in meta.stages when debugging
+func (s *checkStages) finish(queue *services.AllotmentCheckQueue, response fiber.Map) fiber.Map {
+	s.timer.Add(services.CheckStageTotal, time.Since(s.started))
+	timed := s.timer.Stages()
+	services.DefaultCheckStageMetrics.Record(timed)
+	if !s.debug {
+		return response
 	}
 
-	// 4. Cache Result
-	result := models.IPOResultCache{
-		PanHash:        req.PAN, // In real app, hash this!
-		IPOID:          ipo.ID,
-		Status:         status,
-		SharesAllotted: shares,
-		Source:         "live_check",
-		Timestamp:      time.Now(),
+	var stages []shared.StageTiming
+	var total shared.StageTiming
+	for _, stage := range timed {
+		if stage.Stage == services.CheckStageTotal {
+			total = stage
+			continue
+		}
+		stages = append(stages, stage)
+	}
+	if s.checkID != "" {
+		stages = append(stages, queue.Stages(s.checkID)...)
+	}
+	response["meta"] = fiber.Map{"stages": append(stages, total)}
+	return response
+}
+
+// sandboxCheck answers a check sent with a sandbox key with the sandbox's synthetic
+// result, flagged with "sandbox": true and an X-Sandbox header
+func (h *CheckHandler) sandboxCheck(c *fiber.Ctx, ipo *models.IPO, pan, key, lang string) error {
+	if !h.Sandbox.Enabled() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "error": "Sandbox mode is not enabled"})
+	}
+	if !h.Sandbox.Allows(key) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"success": false, "error": "Invalid sandbox key"})
+	}
+	c.Set("X-Sandbox", "true")
+
+	result, err := h.Sandbox.Result(ipo, pan, time.Now())
+	if err != nil {
+		category := shared.ErrorCategoryOf(err)
+		check := &models.AllotmentCheck{ErrorCategory: string(category)}
+		return c.Status(checkFailureStatus(check)).JSON(fiber.Map{
+			"success":        false,
+			"sandbox":        true,
+			"error":          "Failed to check status: " + err.Error(),
+			"error_category": category,
+		})
+	}
+
+	response := fiber.Map{
+		"success": true,
+		"sandbox": true,
+		"data":    services.LabelResult(result, lang),
+	}
+	addResultNotDeclared(response, &models.AllotmentCheck{Result: result})
+	return c.JSON(response)
+}
+
+// refundQuota gives back a lookup consumed for a check the queue did not take, since the
+// registrar was never asked. It reports whether a lookup was refunded.
+func (h *CheckHandler) refundQuota(c *fiber.Ctx, panHash, ipoID string, quota *services.CheckQuotaResult) bool {
+	if h.QuotaService == nil || quota == nil || !quota.Allowed || quota.Limit <= 0 {
+		return false
+	}
+	if err := h.QuotaService.Refund(c.Context(), panHash, ipoID, quota); err != nil {
+		logrus.WithError(err).Warn("Failed to refund check quota")
+		return false
+	}
+	return true
+}
+
+// checkOnBehalf runs one PAN through the same cache, quota and queue steps as POST /check
+// without waiting, recording it under the bulk channel. Used for vault "check all" requests.
+func (h *CheckHandler) checkOnBehalf(c *fiber.Ctx, ipo *models.IPO, pan string) models.VaultCheckEntry {
+	owner, err := resultOwner(c, h.FingerprintKey)
+	if err != nil {
+		return models.VaultCheckEntry{Outcome: models.VaultCheckFailed, Error: err.Error()}
+	}
+	panHash := shared.HashPAN(pan)
+
+	cached, err := h.CacheService.GetCachedResult(c.Context(), ipo.ID.String(), panHash)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to read cached allotment result")
+	}
+	if cached != nil {
+		return models.VaultCheckEntry{Outcome: models.VaultCheckCached, Result: cached}
+	}
+
+	var quota *services.CheckQuotaResult
+	if h.QuotaService != nil {
+		quota, err = h.QuotaService.Consume(c.Context(), panHash, ipo.ID.String())
+		if err != nil {
+			logrus.WithError(err).Warn("Check quota unavailable, allowing request")
+		} else if !quota.Allowed {
+			return models.VaultCheckEntry{
+				Outcome: models.VaultCheckRateLimited,
+				Error:   "Daily check limit reached for this PAN and IPO",
+			}
+		}
+	}
+
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	check, _, err := h.CheckQueue.Submit(ipo, shared.NormalizePAN(pan), services.CheckRequestMeta{
+		SourceChannel:     models.SourceChannelBulk,
+		ClientFingerprint: shared.ClientFingerprint(h.FingerprintKey, c.IP(), userAgent),
+		UserAgent:         userAgent,
+		OwnerHash:         owner,
+	})
+	if err != nil {
+		h.refundQuota(c, panHash, ipo.ID.String(), quota)
+		return models.VaultCheckEntry{Outcome: models.VaultCheckFailed, Error: err.Error()}
+	}
+	return models.VaultCheckEntry{Outcome: models.VaultCheckQueued, CheckID: check.CheckID}
+}
+
+// GetCheckStatus returns the status of an async allotment check
+func (h *CheckHandler) GetCheckStatus(c *fiber.Ctx) error {
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "error": err.Error()})
+	}
+	check := h.CheckQueue.Get(c.Params("check_id"))
+	if check == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Check not found or expired",
+		})
+	}
+
+	return h.checkResponse(c, labelCheck(check, lang))
+}
+
+// syncCheckResponse renders a check completed within SyncWait in the original sync response shape
+func (h *CheckHandler) syncCheckResponse(c *fiber.Ctx, check *models.AllotmentCheck, lang string, stages *checkStages) error {
+	if check == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Check result expired"})
+	}
+	check = labelCheck(check, lang)
+	if check.Status == models.CheckStatusFailed {
+		if manual := h.manualCheckAfterFailure(c, check); manual != nil {
+			return manualCheckResponse(c, check.IPOID, check.Registrar, manual, check.Error)
+		}
+		return c.Status(checkFailureStatus(check)).JSON(stages.finish(h.CheckQueue, fiber.Map{
+			"error":          check.Error,
+			"error_category": check.ErrorCategory,
+		}))
+	}
+
+	response := fiber.Map{
+		"success":  true,
+		"check_id": check.CheckID,
+		"data":     check.Result,
+	}
+	addResultNotDeclared(response, check)
+	return c.JSON(stages.finish(h.CheckQueue, response))
+}
+
+// labelCheck returns a copy of check whose result carries its display status in lang
+func labelCheck(check *models.AllotmentCheck, lang string) *models.AllotmentCheck {
+	if check == nil {
+		return nil
+	}
+	labelled := *check
+	labelled.Result = services.LabelResult(check.Result, lang)
+	return &labelled
+}
+
+// addResultNotDeclared marks a response whose check found results not declared yet, so
+// clients can tell it apart from a PAN the registrar does not know
+func addResultNotDeclared(response fiber.Map, check *models.AllotmentCheck) {
+	if check.Result == nil || check.Result.Status != models.AllotmentStatusNotDeclared {
+		return
+	}
+	response["result_declared"] = false
+	response["recheck_scheduled"] = check.RecheckScheduled
+	if check.RecheckScheduled {
+		response["message"] = "Allotment results are not declared yet. The check will be re-run automatically once they are, and the result sent to you."
+	} else {
+		response["message"] = "Allotment results are not declared yet. Please check again later."
+	}
+}
+
+// manualCheck returns the IPO's manual check, or nil when it has none or it could not be
+// loaded
+func (h *CheckHandler) manualCheck(c *fiber.Ctx, ipoID uuid.UUID) *models.ManualCheck {
+	manual, err := h.IPOService.GetManualCheck(c.Context(), ipoID)
+	if err != nil {
+		logrus.WithError(err).WithField("ipo_id", ipoID).Warn("Failed to load manual check")
+		return nil
+	}
+	return manual
+}
+
+// manualCheckAfterFailure returns the manual check of a failed check's IPO. Registrar
+// throttling and timeouts pass, so trying again later is left to the user as before.
+func (h *CheckHandler) manualCheckAfterFailure(c *fiber.Ctx, check *models.AllotmentCheck) *models.ManualCheck {
+	switch shared.ErrorCategory(check.ErrorCategory) {
+	case shared.ErrorCategoryRateLimited, shared.ErrorCategoryTimeout:
+		return nil
+	}
+	return h.manualCheck(c, check.IPOID)
+}
+
+// manualCheckResponse tells the user to check allotment on the registrar's site, with
+// the IPO's link and instructions, instead of failing the check
+func manualCheckResponse(c *fiber.Ctx, ipoID uuid.UUID, registrar string, manual *models.ManualCheck, reason string) error {
+	return c.JSON(fiber.Map{
+		"success":               true,
+		"manual_check_required": true,
+		"message":               reason,
+		"data": fiber.Map{
+			"ipo_id":           ipoID,
+			"registrar":        registrar,
+			"manual_check_url": manual.URL,
+			"instructions":     manual.Instructions,
+		},
+	})
+}
+
+// checkFailureStatus maps a failed check's error category to an HTTP status. Upstream
+// problems map to 502 and registrar throttling to 429; other failures stay 502.
+func checkFailureStatus(check *models.AllotmentCheck) int {
+	status := shared.HTTPStatusForCategory(shared.ErrorCategory(check.ErrorCategory))
+	if status == fiber.StatusInternalServerError {
+		return fiber.StatusBadGateway
+	}
+	return status
+}
+
+// checkResponse renders a check, mapping failed checks by error category as in sync mode
+func (h *CheckHandler) checkResponse(c *fiber.Ctx, check *models.AllotmentCheck) error {
+	if check == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Check not found or expired",
+		})
+	}
+
+	if check.Status == models.CheckStatusFailed {
+		if manual := h.manualCheckAfterFailure(c, check); manual != nil {
+			return manualCheckResponse(c, check.IPOID, check.Registrar, manual, check.Error)
+		}
+		return c.Status(checkFailureStatus(check)).JSON(fiber.Map{
+			"success": false,
+			"error":   check.Error,
+			"data":    check,
+		})
+	}
+
+	response := fiber.Map{
+		"success": true,
+		"data":    check,
+	}
+	addResultNotDeclared(response, check)
+	return c.JSON(response)
+}
+
+// SubmitCheckFeedback lets a user flag the result of a completed check as wrong or stale
+func (h *CheckHandler) SubmitCheckFeedback(c *fiber.Ctx) error {
+	if h.Feedback == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Check feedback is not available",
+		})
+	}
+
+	type Request struct {
+		Reason  string `json:"reason"`
+		Comment string `json:"comment"`
+	}
+	var req Request
+	if err := parseJSONBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "error": "Invalid request: " + err.Error()})
+	}
+
+	check := h.CheckQueue.Get(c.Params("check_id"))
+	if check == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Check not found or expired",
+		})
+	}
+
+	feedback, err := h.Feedback.Record(c.Context(), check, req.Reason, req.Comment)
+	if err != nil {
+		return errorResponse(c, "check_feedback", err, err.Error())
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"check_id":  feedback.CheckID,
+		"registrar": feedback.Registrar,
+		"reason":    feedback.Reason,
+		"status":    feedback.ReportedStatus,
+	}).Info("Received allotment result feedback")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// GetRecentChecks returns recent allotment checks for abuse investigation.
+// Supports ipo_id, channel, fingerprint, status, since (RFC3339) and limit filters.
+func (h *CheckHandler) GetRecentChecks(c *fiber.Ctx) error {
+	filter := services.CheckAuditFilter{
+		IPOID:             c.Query("ipo_id"),
+		SourceChannel:     c.Query("channel"),
+		ClientFingerprint: c.Query("fingerprint"),
+		Status:            c.Query("status"),
+		Limit:             c.QueryInt("limit", 100),
+	}
+
+	if since := c.Query("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid since parameter, expected RFC3339 timestamp",
+			})
+		}
+		filter.Since = &parsed
+	}
+
+	checks, err := h.CacheService.GetRecentChecks(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
-	// h.CacheService.StoreResult(c.Context(), &result) // Fire and forget or wait
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data":    result,
+		"data":    checks,
+		"count":   len(checks),
 	})
 }
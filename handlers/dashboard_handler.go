@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/database"
+	"github.com/fenilmodi00/ipo-backend/jobs"
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DashboardHandler aggregates system state into one payload for an ops UI
+type DashboardHandler struct {
+	IPOService    *services.IPOService
+	ScrapeRuns    *services.ScrapeRunService
+	Outbox        *services.OutboxDispatcher
+	CheckQueue    *services.AllotmentCheckQueue
+	Locker        *jobs.JobLocker
+	ResponseCache *middleware.ResponseCache
+	// Feedback, when set, adds user reports of wrong or stale results per registrar
+	Feedback *services.CheckFeedbackService
+}
+
+func NewDashboardHandler(ipoService *services.IPOService, scrapeRuns *services.ScrapeRunService, outbox *services.OutboxDispatcher, checkQueue *services.AllotmentCheckQueue, locker *jobs.JobLocker, responseCache *middleware.ResponseCache) *DashboardHandler {
+	return &DashboardHandler{
+		IPOService:    ipoService,
+		ScrapeRuns:    scrapeRuns,
+		Outbox:        outbox,
+		CheckQueue:    checkQueue,
+		Locker:        locker,
+		ResponseCache: responseCache,
+	}
+}
+
+// dashboardScrapeRun is the per-job summary of the latest scrape run
+type dashboardScrapeRun struct {
+	JobName             string    `json:"job_name"`
+	Status              string    `json:"status"`
+	FinishedAt          time.Time `json:"finished_at"`
+	DurationMs          int64     `json:"duration_ms"`
+	TotalItems          int       `json:"total_items"`
+	FailureCount        int       `json:"failure_count"`
+	FieldExtractionRate *float64  `json:"field_extraction_rate,omitempty"`
+	ErrorSamples        []string  `json:"error_samples,omitempty"`
+}
+
+// GetDashboard returns IPO counts, job and scrape run state, success rates, cache and
+// database pool stats, and pending failures. A section that can't be loaded is reported
+// under "errors" instead of failing the whole response.
+func (h *DashboardHandler) GetDashboard(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	data := make(map[string]interface{})
+	sectionErrors := make(map[string]string)
+
+	ipoCounts, err := h.IPOService.CountIPOsByStatus(ctx)
+	if err != nil {
+		sectionErrors["ipos"] = err.Error()
+	} else {
+		total := 0
+		for _, count := range ipoCounts {
+			total += count
+		}
+		data["ipos"] = fiber.Map{"total": total, "by_status": ipoCounts}
+	}
+
+	if h.Locker != nil {
+		data["jobs"] = fiber.Map{"instance": h.Locker.InstanceID, "locks": h.Locker.GetStats()}
+	}
+
+	failedItems := 0
+	if h.ScrapeRuns != nil {
+		runs, err := h.ScrapeRuns.Latest(ctx)
+		if err != nil {
+			sectionErrors["scrape_runs"] = err.Error()
+		} else {
+			summaries := make([]dashboardScrapeRun, 0, len(runs))
+			for i := range runs {
+				summaries = append(summaries, summarizeScrapeRun(&runs[i]))
+				failedItems += runs[i].FailureCount
+			}
+			data["scrape_runs"] = summaries
+		}
+	}
+
+	data["success_rates"] = services.DefaultAlerter.Snapshot()
+
+	cacheStats := fiber.Map{}
+	if h.ResponseCache != nil {
+		cacheStats["response_cache"] = h.ResponseCache.GetStats()
+	}
+	data["cache"] = cacheStats
+
+	dbStats := database.GetConnectionStats()
+	data["database"] = fiber.Map{
+		"max_open_connections": dbStats.MaxOpenConnections,
+		"open_connections":     dbStats.OpenConnections,
+		"in_use":               dbStats.InUse,
+		"idle":                 dbStats.Idle,
+		"wait_count":           dbStats.WaitCount,
+		"wait_duration_ms":     dbStats.WaitDuration.Milliseconds(),
+	}
+
+	if h.CheckQueue != nil {
+		data["checks"] = h.CheckQueue.Stats()
+	}
+
+	if h.Feedback != nil {
+		feedback, err := h.Feedback.Summary(ctx, time.Now().Add(-checkFeedbackWindow))
+		if err != nil {
+			sectionErrors["check_feedback"] = err.Error()
+		} else {
+			data["check_feedback"] = summarizeCheckFeedback(feedback)
+		}
+	}
+
+	failures := fiber.Map{
+		"latest_scrape_failed_items": failedItems,
+		"errors_by_category":         shared.DefaultErrorCounter.Snapshot(),
+	}
+	if h.Outbox != nil {
+		outboxCounts, err := h.Outbox.StatusCounts(ctx)
+		if err != nil {
+			sectionErrors["outbox"] = err.Error()
+		} else {
+			failures["outbox_pending"] = outboxCounts["PENDING"]
+			failures["outbox_failed"] = outboxCounts["FAILED"]
+		}
+	}
+	data["failures"] = failures
+
+	if len(sectionErrors) > 0 {
+		data["errors"] = sectionErrors
+	}
+	data["generated_at"] = time.Now()
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// checkFeedbackWindow is how far back the dashboard counts result feedback
+const checkFeedbackWindow = 7 * 24 * time.Hour
+
+// summarizeCheckFeedback totals feedback per registrar, keeping the per-IPO rows so a
+// parser regression can be traced to the IPOs it affects
+func summarizeCheckFeedback(summaries []models.CheckFeedbackSummary) fiber.Map {
+	byRegistrar := make(map[string]int)
+	for _, summary := range summaries {
+		byRegistrar[summary.Registrar] += summary.Total
+	}
+	return fiber.Map{
+		"window_hours": int(checkFeedbackWindow.Hours()),
+		"by_registrar": byRegistrar,
+		"by_ipo":       summaries,
+	}
+}
+
+// summarizeScrapeRun trims a run report to what the dashboard shows
+func summarizeScrapeRun(run *models.ScrapeRun) dashboardScrapeRun {
+	summary := dashboardScrapeRun{
+		JobName:      run.JobName,
+		Status:       run.Status,
+		FinishedAt:   run.FinishedAt,
+		DurationMs:   run.DurationMs,
+		TotalItems:   run.TotalItems,
+		FailureCount: run.FailureCount,
+		ErrorSamples: run.ErrorSamples,
+	}
+	if rate, ok := services.FieldExtractionRate(run); ok {
+		summary.FieldExtractionRate = &rate
+	}
+	return summary
+}
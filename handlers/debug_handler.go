@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DebugHandler exposes recorded outbound HTTP failures for diagnosing upstream changes
+type DebugHandler struct {
+	Recorder *shared.HTTPRecorder
+	// IPOs, when set, serves the status simulation
+	IPOs *services.IPOService
+}
+
+func NewDebugHandler(recorder *shared.HTTPRecorder) *DebugHandler {
+	return &DebugHandler{Recorder: recorder}
+}
+
+// GetHTTPRecordings returns the recorded failed requests, newest first. ?source= keeps
+// recordings from one caller (http_client or registrar) and ?host= those whose URL
+// contains the given host.
+func (h *DebugHandler) GetHTTPRecordings(c *fiber.Ctx) error {
+	if !h.Recorder.Enabled() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "HTTP recording is not enabled",
+		})
+	}
+
+	source := strings.TrimSpace(c.Query("source"))
+	host := strings.ToLower(strings.TrimSpace(c.Query("host")))
+	recordings := []shared.HTTPRecording{}
+	for _, recording := range h.Recorder.Recordings() {
+		if source != "" && recording.Source != source {
+			continue
+		}
+		if host != "" && !strings.Contains(strings.ToLower(recording.URL), host) {
+			continue
+		}
+		recordings = append(recordings, recording)
+	}
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"data":     recordings,
+		"count":    len(recordings),
+		"capacity": h.Recorder.Capacity(),
+	})
+}
+
+// ClearHTTPRecordings drops every recording
+func (h *DebugHandler) ClearHTTPRecordings(c *fiber.Ctx) error {
+	h.Recorder.Clear()
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "HTTP recordings cleared",
+	})
+}
+
+// SimulateStatuses returns the status each IPO would have at ?at=, an RFC 3339 time or a
+// YYYY-MM-DD date (midnight IST at the start of that day), next to its status now.
+// ?changes_only=true keeps only IPOs whose status would differ.
+func (h *DebugHandler) SimulateStatuses(c *fiber.Ctx) error {
+	if h.IPOs == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Status simulation is not available",
+		})
+	}
+
+	raw := strings.TrimSpace(c.Query("at"))
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		date, dateErr := models.ParseDate(raw)
+		if dateErr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "at must be an RFC 3339 time or a YYYY-MM-DD date",
+			})
+		}
+		at = date.Time
+	}
+
+	projections, err := h.IPOs.SimulateStatuses(c.Context(), at)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, "Failed to simulate IPO statuses")
+	}
+	if c.QueryBool("changes_only") {
+		changed := []services.IPOStatusProjection{}
+		for _, projection := range projections {
+			if projection.Changes {
+				changed = append(changed, projection)
+			}
+		}
+		projections = changed
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    projections,
+		"count":   len(projections),
+		"at":      at,
+	})
+}
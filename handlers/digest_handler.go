@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DigestHandler serves the weekly IPO digest and its email subscription
+type DigestHandler struct {
+	Digests *services.WeeklyDigestService
+}
+
+func NewDigestHandler(digests *services.WeeklyDigestService) *DigestHandler {
+	return &DigestHandler{Digests: digests}
+}
+
+// GetWeeklyDigest returns the IPOs opening, closing and listing in the coming week
+func (h *DigestHandler) GetWeeklyDigest(c *fiber.Ctx) error {
+	digest, err := h.Digests.Upcoming(c.Context())
+	if err != nil {
+		return errorResponse(c, "digest_api", err, "Failed to generate weekly digest")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    digest,
+	})
+}
+
+// GetSubscription reports whether the signed-in account gets the digest email
+func (h *DigestHandler) GetSubscription(c *fiber.Ctx) error {
+	subscribed, err := h.Digests.Subscribed(c.Context(), middleware.AccountFromContext(c).ID)
+	if err != nil {
+		return errorResponse(c, "digest_api", err, "Failed to load weekly digest subscription")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"subscribed": subscribed},
+	})
+}
+
+// Subscribe signs the signed-in account up for the Sunday digest email
+func (h *DigestHandler) Subscribe(c *fiber.Ctx) error {
+	if err := h.Digests.Subscribe(c.Context(), middleware.AccountFromContext(c)); err != nil {
+		return errorResponse(c, "digest_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"subscribed": true},
+	})
+}
+
+// Unsubscribe stops the signed-in account's digest email
+func (h *DigestHandler) Unsubscribe(c *fiber.Ctx) error {
+	if err := h.Digests.Unsubscribe(c.Context(), middleware.AccountFromContext(c).ID); err != nil {
+		return errorResponse(c, "digest_api", err, "Failed to unsubscribe from weekly digest")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"subscribed": false},
+	})
+}
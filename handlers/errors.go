@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorResponse writes a failure response whose status code follows the error's
+// category and counts the error under component for the metrics endpoint
+func errorResponse(c *fiber.Ctx, component string, err error, message string) error {
+	shared.DefaultErrorCounter.Record(component, err)
+	return c.Status(shared.HTTPStatusForError(err)).JSON(fiber.Map{
+		"success":        false,
+		"error":          message,
+		"error_category": shared.ErrorCategoryOf(err),
+	})
+}
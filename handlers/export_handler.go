@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// exportFlushEvery is how many NDJSON lines are buffered before flushing to the client
+const exportFlushEvery = 100
+
+// ExportHandler serves bulk research exports
+type ExportHandler struct {
+	Export *services.ExportService
+	// Timeout bounds how long one export may stream
+	Timeout time.Duration
+}
+
+func NewExportHandler(export *services.ExportService) *ExportHandler {
+	return &ExportHandler{Export: export, Timeout: 5 * time.Minute}
+}
+
+// parseExportRange reads ?from= and ?to= (YYYY-MM-DD, both inclusive); to defaults to
+// today and from to one year before to
+func parseExportRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		}
+		to = parsed
+	}
+	from := to.AddDate(-1, 0, 0)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		}
+		from = parsed
+	}
+
+	// Make to inclusive by ending the range at the start of the next day
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "from must not be after to")
+	}
+	if to.Sub(from) > services.MaxExportRange {
+		return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "date range must not exceed 5 years")
+	}
+	return from, to, nil
+}
+
+// ExportIPOs streams IPOs that opened in the requested range as newline-delimited JSON
+func (h *ExportHandler) ExportIPOs(c *fiber.Ctx) error {
+	from, to, err := parseExportRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="ipos-`+from.Format("20060102")+`-`+to.AddDate(0, 0, -1).Format("20060102")+`.ndjson"`)
+
+	// The stream writer runs after the handler returns, so it gets its own context
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+		defer cancel()
+
+		// Flushing every few lines keeps the client receiving data, and a failed flush
+		// means it went away, which stops the export
+		encoder := json.NewEncoder(w)
+		lines := 0
+		written, err := h.Export.StreamIPOs(ctx, from, to, func(record *models.IPOExportRecord) error {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			if lines++; lines%exportFlushEvery == 0 {
+				return w.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			// Headers are already sent; the client sees a truncated stream
+			logrus.WithError(err).WithField("rows", written).Warn("IPO export stream aborted")
+			return
+		}
+		logrus.WithFields(logrus.Fields{"rows": written, "from": from, "to": to}).Info("IPO export streamed")
+	})
+	return nil
+}
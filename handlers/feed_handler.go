@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeedHandler serves IPO data as subscribable RSS and iCalendar feeds
+type FeedHandler struct {
+	IPOService *services.IPOService
+}
+
+func NewFeedHandler(ipoService *services.IPOService) *FeedHandler {
+	return &FeedHandler{IPOService: ipoService}
+}
+
+// feedBaseURL is the public API root that feed links point to
+func feedBaseURL(c *fiber.Ctx) string {
+	return c.BaseURL() + "/api/v1"
+}
+
+// GetIPOsRSS returns new and upcoming IPO announcements as RSS 2.0
+func (h *FeedHandler) GetIPOsRSS(c *fiber.Ctx) error {
+	ipos, err := h.IPOService.GetIPOs(c.Context(), "all")
+	if err != nil {
+		return errorResponse(c, "feeds", err, err.Error())
+	}
+
+	body, err := services.RenderIPORSS(ipos, feedBaseURL(c), c.BaseURL()+c.OriginalURL(), time.Now())
+	if err != nil {
+		return errorResponse(c, "feeds", err, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "application/rss+xml; charset=utf-8")
+	return c.Send(body)
+}
+
+// GetIPOCalendar returns IPO open, close, allotment and listing dates as an iCalendar file
+func (h *FeedHandler) GetIPOCalendar(c *fiber.Ctx) error {
+	ipos, err := h.IPOService.GetIPOs(c.Context(), "all")
+	if err != nil {
+		return errorResponse(c, "feeds", err, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="ipo-calendar.ics"`)
+	return c.Send(services.RenderIPOCalendar(ipos, feedBaseURL(c), time.Now()))
+}
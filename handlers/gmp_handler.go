@@ -3,14 +3,25 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 )
 
+// MaxBulkGMPIPOs is the most IPOs one bulk GMP request may ask for
+const MaxBulkGMPIPOs = 50
+
 type GMPHandler struct {
 	DB *sql.DB
+	// PreOpen, when set, adds the listing-morning pre-open price to GMP responses
+	PreOpen *services.PreOpenService
 }
 
 func NewGMPHandler(db *sql.DB) *GMPHandler {
@@ -33,7 +44,7 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 	var stockID *string
 	var companyCode string
 	err := h.DB.QueryRow(`
-		SELECT stock_id, company_code FROM ipo_list WHERE id = $1
+		SELECT stock_id, company_code FROM ipo_list WHERE id = $1 AND deleted_at IS NULL
 	`, ipoID).Scan(&stockID, &companyCode)
 
 	if err == sql.ErrNoRows {
@@ -51,19 +62,14 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 	}
 
 	// Now query enhanced GMP data using stock_id as primary key, company_code as fallback
-	var gmpData models.EnhancedGMPData
-	var extractionMetadataBytes sql.NullString
 	var query string
 	var args []interface{}
 
 	if stockID != nil && *stockID != "" {
 		// Use stock_id as primary linking key
 		query = `
-			SELECT id, ipo_name, company_code, ipo_price, gmp_value, 
-			       estimated_listing, gain_percent, sub2, kostak, last_updated,
-			       stock_id, subscription_status, listing_gain, ipo_status, 
-			       data_source, extraction_metadata
-			FROM ipo_gmp 
+			SELECT ` + enhancedGMPColumns + `
+			FROM ipo_gmp
 			WHERE (stock_id = $1 OR company_code = $2)
 			ORDER BY 
 				CASE WHEN stock_id = $1 THEN 1 ELSE 2 END,
@@ -74,11 +80,8 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 	} else {
 		// Fallback to company_code matching only
 		query = `
-			SELECT id, ipo_name, company_code, ipo_price, gmp_value, 
-			       estimated_listing, gain_percent, sub2, kostak, last_updated,
-			       stock_id, subscription_status, listing_gain, ipo_status, 
-			       data_source, extraction_metadata
-			FROM ipo_gmp 
+			SELECT ` + enhancedGMPColumns + `
+			FROM ipo_gmp
 			WHERE company_code = $1
 			ORDER BY last_updated DESC
 			LIMIT 1
@@ -86,7 +89,150 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 		args = []interface{}{companyCode}
 	}
 
-	err = h.DB.QueryRow(query, args...).Scan(
+	gmpData, err := scanEnhancedGMP(h.DB.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "GMP data not found for this IPO",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch GMP data",
+		})
+	}
+
+	if h.PreOpen != nil {
+		indicative, err := h.PreOpen.GetIndicativePrice(c.Context(), uuid.MustParse(ipoID))
+		if err != nil {
+			logrus.WithError(err).WithField("ipo_id", ipoID).Warn("Failed to load pre-open price")
+		}
+		gmpData.IndicativeListingPrice = indicative
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    gmpData,
+	})
+}
+
+// GetGMPByIPOs returns the latest GMP of up to MaxBulkGMPIPOs IPOs given as
+// ?ipo_ids=a,b,c, keyed by IPO ID, so a watchlist loads in one request. IPOs that do not
+// exist or have no GMP are listed under "not_found". Each IPO is matched like
+// GetGMPByIPO: stock_id first, company_code as fallback.
+func (h *GMPHandler) GetGMPByIPOs(c *fiber.Ctx) error {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, value := range strings.Split(c.Query("ipo_ids"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid IPO ID format: " + value,
+			})
+		}
+		if !seen[id.String()] {
+			seen[id.String()] = true
+			ids = append(ids, id.String())
+		}
+	}
+	if len(ids) == 0 || len(ids) > MaxBulkGMPIPOs {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("ipo_ids must list between 1 and %d IPO IDs", MaxBulkGMPIPOs),
+		})
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	rows, err := h.DB.QueryContext(c.Context(), `
+		SELECT l.id, g.*
+		FROM ipo_list l
+		CROSS JOIN LATERAL (
+			SELECT `+enhancedGMPColumns+`
+			FROM ipo_gmp
+			WHERE (COALESCE(l.stock_id, '') <> '' AND stock_id = l.stock_id)
+			   OR company_code = l.company_code
+			ORDER BY
+				CASE WHEN COALESCE(l.stock_id, '') <> '' AND stock_id = l.stock_id THEN 1 ELSE 2 END,
+				last_updated DESC
+			LIMIT 1
+		) g
+		WHERE l.id IN (`+strings.Join(placeholders, ", ")+`) AND l.deleted_at IS NULL
+	`, args...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch GMP data",
+		})
+	}
+	defer rows.Close()
+
+	data := make(map[string]*models.EnhancedGMPData, len(ids))
+	for rows.Next() {
+		var ipoID string
+		gmpData, err := scanEnhancedGMP(rows, &ipoID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch GMP data",
+			})
+		}
+		data[ipoID] = gmpData
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch GMP data",
+		})
+	}
+
+	notFound := []string{}
+	for _, id := range ids {
+		if data[id] == nil {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success":   true,
+		"data":      data,
+		"count":     len(data),
+		"not_found": notFound,
+	})
+}
+
+// enhancedGMPColumns are the ipo_gmp columns read by scanEnhancedGMP, in scan order
+const enhancedGMPColumns = `id, ipo_name, company_code, ipo_price, gmp_value,
+			       estimated_listing, gain_percent, sub2, kostak, last_updated,
+			       stock_id, subscription_status, listing_gain, ipo_status,
+			       data_source, extraction_metadata,
+			       gmp_change_24h, gmp_momentum_3d, gmp_volatility,
+			       days_to_listing, listing_decay, signals_updated_at,
+			       raw_gmp_value, smoothed_gmp_value, COALESCE(gmp_outlier_rejected, FALSE),
+			       gmp_providers, COALESCE(gmp_scraped_at, last_updated)`
+
+// scanEnhancedGMP scans one row of enhancedGMPColumns, preceded by dest when given
+func scanEnhancedGMP(row interface{ Scan(...interface{}) error }, dest ...interface{}) (*models.EnhancedGMPData, error) {
+	var gmpData models.EnhancedGMPData
+	var extractionMetadataBytes sql.NullString
+	var signals models.GMPTrendSignals
+	var rawGMP, smoothedGMP sql.NullFloat64
+	var outlierRejected bool
+	var providers []string
+	var scrapedAt time.Time
+
+	err := row.Scan(append(dest,
 		&gmpData.ID,
 		&gmpData.IPOName,
 		&gmpData.CompanyCode,
@@ -103,20 +249,20 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 		&gmpData.IPOStatus,
 		&gmpData.DataSource,
 		&extractionMetadataBytes,
-	)
-
-	if err == sql.ErrNoRows {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error":   "GMP data not found for this IPO",
-		})
-	}
-
+		&signals.Change24h,
+		&signals.Momentum3d,
+		&signals.Volatility,
+		&signals.DaysToListing,
+		&signals.ListingDecay,
+		&signals.ComputedAt,
+		&rawGMP,
+		&smoothedGMP,
+		&outlierRejected,
+		pq.Array(&providers),
+		&scrapedAt,
+	)...)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   "Failed to fetch GMP data",
-		})
+		return nil, err
 	}
 
 	// Parse extraction metadata JSON if present
@@ -127,8 +273,19 @@ func (h *GMPHandler) GetGMPByIPO(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    gmpData,
-	})
+	if signals.ComputedAt != nil {
+		gmpData.TrendSignals = &signals
+	}
+
+	if rawGMP.Valid && smoothedGMP.Valid {
+		gmpData.Smoothing = &models.GMPSmoothing{
+			RawGMPValue:      rawGMP.Float64,
+			SmoothedGMPValue: smoothedGMP.Float64,
+			OutlierRejected:  outlierRejected,
+		}
+	}
+
+	gmpData.Attribution = models.NewGMPAttribution(gmpData.DataSource, providers, scrapedAt,
+		gmpData.DataSource == services.GMPManualOverrideSource)
+	return &gmpData, nil
 }
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HotnessHandler struct {
+	Service *services.HotnessService
+}
+
+func NewHotnessHandler(service *services.HotnessService) *HotnessHandler {
+	return &HotnessHandler{Service: service}
+}
+
+// GetTrendingIPOs returns the top-N IPOs ranked by hotness score with score breakdown
+func (h *HotnessHandler) GetTrendingIPOs(c *fiber.Ctx) error {
+	trending, err := h.Service.GetTrending(c.Context(), c.QueryInt("limit", 10))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    trending,
+		"count":   len(trending),
+	})
+}
@@ -1,12 +1,25 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 type IPOHandler struct {
 	Service *services.IPOService
+	// FormMetadata, when set, adds live registrar form values to the form config
+	FormMetadata *services.RegistrarFormService
+	// Cache, when set, serves single IPO detail reads through the per-IPO cache
+	Cache *services.CachedIPOService
 }
 
 func NewIPOHandler(service *services.IPOService) *IPOHandler {
@@ -15,13 +28,25 @@ func NewIPOHandler(service *services.IPOService) *IPOHandler {
 
 func (h *IPOHandler) GetIPOs(c *fiber.Ctx) error {
 	status := c.Query("status", "all")
-	ipos, err := h.Service.GetIPOs(c.Context(), status)
+	options, err := parseIPOListOptions(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error":   err.Error(),
 		})
 	}
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	ipos, err := h.Service.ListIPOs(c.Context(), status, options)
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	for i := range ipos {
+		h.Service.TextLimits.Apply(&ipos[i])
+		services.LabelIPO(&ipos[i], lang)
+	}
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    ipos,
@@ -29,27 +54,89 @@ func (h *IPOHandler) GetIPOs(c *fiber.Ctx) error {
 }
 
 func (h *IPOHandler) GetActiveIPOs(c *fiber.Ctx) error {
-	ipos, err := h.Service.GetActiveIPOs(c.Context())
+	options, err := parseIPOListOptions(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error":   err.Error(),
 		})
 	}
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	ipos, err := h.Service.ListActiveIPOs(c.Context(), options)
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	for i := range ipos {
+		h.Service.TextLimits.Apply(&ipos[i])
+		services.LabelIPO(&ipos[i], lang)
+	}
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    ipos,
 	})
 }
 
+// parseIPOListOptions reads ?sort= (created_at, issue_size or ofs_percent), ?order= (asc
+// or desc), the ?min_issue_size= / ?max_issue_size= bounds in rupees and the
+// ?min_ofs_pct= / ?max_ofs_pct= bounds in percent
+func parseIPOListOptions(c *fiber.Ctx) (services.IPOListOptions, error) {
+	var options services.IPOListOptions
+
+	switch sort := strings.ToLower(strings.TrimSpace(c.Query("sort"))); sort {
+	case "", services.IPOSortCreated:
+		options.Sort = services.IPOSortCreated
+	case services.IPOSortIssueSize, services.IPOSortOFSPercent:
+		options.Sort = sort
+	default:
+		return options, fmt.Errorf("invalid sort %q: use created_at, issue_size or ofs_percent", sort)
+	}
+
+	switch order := strings.ToLower(strings.TrimSpace(c.Query("order"))); order {
+	case "", "desc":
+	case "asc":
+		options.Ascending = true
+	default:
+		return options, fmt.Errorf("invalid order %q: use asc or desc", order)
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target **float64
+		max    float64
+		unit   string
+	}{
+		{"min_issue_size", &options.MinIssueSize, math.MaxFloat64, "a non-negative amount in rupees"},
+		{"max_issue_size", &options.MaxIssueSize, math.MaxFloat64, "a non-negative amount in rupees"},
+		{"min_ofs_pct", &options.MinOFSPercent, 100, "a percentage between 0 and 100"},
+		{"max_ofs_pct", &options.MaxOFSPercent, 100, "a percentage between 0 and 100"},
+	} {
+		raw := strings.TrimSpace(c.Query(bound.name))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 || value > bound.max || math.IsNaN(value) {
+			return options, fmt.Errorf("invalid %s %q: must be %s", bound.name, raw, bound.unit)
+		}
+		*bound.target = &value
+	}
+	if options.MinIssueSize != nil && options.MaxIssueSize != nil && *options.MinIssueSize > *options.MaxIssueSize {
+		return options, fmt.Errorf("min_issue_size must not exceed max_issue_size")
+	}
+	if options.MinOFSPercent != nil && options.MaxOFSPercent != nil && *options.MinOFSPercent > *options.MaxOFSPercent {
+		return options, fmt.Errorf("min_ofs_pct must not exceed max_ofs_pct")
+	}
+	return options, nil
+}
+
 func (h *IPOHandler) GetIPOFormConfig(c *fiber.Ctx) error {
 	id := c.Params("ipo_id")
 	ipo, err := h.Service.GetIPOByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return errorResponse(c, "ipo_api", err, err.Error())
 	}
 	if ipo == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -57,41 +144,202 @@ func (h *IPOHandler) GetIPOFormConfig(c *fiber.Ctx) error {
 			"error":   "IPO not found",
 		})
 	}
-	return c.JSON(fiber.Map{
+
+	response := fiber.Map{
 		"success": true,
 		"data":    ipo,
-	})
+	}
+	// Live values are best effort; the static config is still useful without them
+	if h.FormMetadata != nil && ipo.FormURL != nil {
+		metadata, err := h.FormMetadata.GetFormMetadata(c.Context(), ipo)
+		if err != nil {
+			shared.DefaultErrorCounter.Record("registrar_form", err)
+			logrus.WithError(err).WithField("ipo_id", ipo.ID).Warn("Failed to load registrar form metadata")
+			response["registrar_metadata_error"] = shared.ErrorCategoryOf(err)
+		} else {
+			response["registrar_metadata"] = metadata
+		}
+	}
+	return c.JSON(response)
 }
 
+// GetIPOByID returns an IPO's detail by ID or slug. Description and about are cut to
+// their preview lengths unless ?full_text=true; display_status follows ?lang=.
 func (h *IPOHandler) GetIPOByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	ipo, err := h.Service.GetIPOByID(c.Context(), id)
+	return h.getIPODetail(c, c.Params("id"), "/api/v1/ipos/")
+}
+
+// GetIPOBySlug returns an IPO's detail by slug with its canonical slug. A slug the IPO
+// had before a rename gets a 301 to the canonical slug's URL with the redirect in the body.
+func (h *IPOHandler) GetIPOBySlug(c *fiber.Ctx) error {
+	return h.getIPODetail(c, c.Params("slug"), "/api/v1/ipos/slug/")
+}
+
+// getIPODetail serves an IPO's detail by ID or slug. A replaced slug is redirected to
+// basePath followed by the canonical slug.
+func (h *IPOHandler) getIPODetail(c *fiber.Ctx, idOrSlug, basePath string) error {
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	var ipo *models.IPO
+	if h.Cache != nil {
+		ipo, err = h.Cache.GetIPODetail(c.Context(), idOrSlug)
+	} else {
+		ipo, err = h.Service.GetIPODetail(c.Context(), idOrSlug)
+	}
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
 	}
 	if ipo == nil {
+		if _, parseErr := uuid.Parse(idOrSlug); parseErr != nil {
+			redirect, err := h.Service.ResolveSlugRedirect(c.Context(), idOrSlug)
+			if err != nil {
+				return errorResponse(c, "ipo_api", err, err.Error())
+			}
+			if redirect != nil {
+				location := basePath + redirect.CanonicalSlug
+				c.Set(fiber.HeaderLocation, location)
+				return c.Status(fiber.StatusMovedPermanently).JSON(fiber.Map{
+					"success":  false,
+					"error":    "IPO slug has changed",
+					"redirect": redirect,
+					"location": location,
+				})
+			}
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "IPO not found",
 		})
 	}
-	return c.JSON(fiber.Map{
+
+	if !c.QueryBool("full_text", false) {
+		h.Service.TextLimits.Apply(ipo)
+	}
+	services.LabelIPO(ipo, lang)
+
+	response := fiber.Map{
 		"success": true,
 		"data":    ipo,
+	}
+	if ipo.Slug != nil && *ipo.Slug != "" {
+		response["canonical_slug"] = *ipo.Slug
+	}
+	return c.JSON(response)
+}
+
+// GetIPOsBatch returns the detail of up to services.MaxIPOBatchSize IPOs given as
+// ?ids=a,b,c in one round trip, for compare and watchlist screens. IPOs come back in the
+// order asked for; unknown IDs are listed under "not_found". With ?with_gmp=true each IPO
+// carries its GMP fields like GET /ipos/:id/with-gmp.
+func (h *IPOHandler) GetIPOsBatch(c *fiber.Ctx) error {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, value := range strings.Split(c.Query("ids"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid IPO ID format: " + value,
+			})
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > services.MaxIPOBatchSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("ids must list between 1 and %d IPO IDs", services.MaxIPOBatchSize),
+		})
+	}
+
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	ipos, err := h.Service.GetIPODetailsByIDs(c.Context(), ids, c.QueryBool("with_gmp", false))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	for i := range ipos {
+		h.Service.TextLimits.Apply(&ipos[i].IPO)
+		services.LabelIPO(&ipos[i].IPO, lang)
+	}
+
+	found := make(map[uuid.UUID]bool, len(ipos))
+	for _, ipo := range ipos {
+		found[ipo.ID] = true
+	}
+	notFound := []string{}
+	for _, id := range ids {
+		if !found[id] {
+			notFound = append(notFound, id.String())
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success":   true,
+		"data":      ipos,
+		"count":     len(ipos),
+		"not_found": notFound,
+	})
+}
+
+// GetStatusLabels returns the display label of every IPO and allotment status in the
+// language asked for with ?lang=, so clients need no label table of their own
+func (h *IPOHandler) GetStatusLabels(c *fiber.Ctx) error {
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"lang":    lang,
+		"data":    services.StatusLabels(lang),
+	})
+}
+
+// GetIPOFAQ returns the FAQ extracted from an IPO's page
+func (h *IPOHandler) GetIPOFAQ(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	faq, err := h.Service.GetIPOFAQ(c.Context(), ipoID)
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    faq,
+		"count":   len(faq),
 	})
 }
 
 // GetActiveIPOsWithGMP returns active IPOs with GMP data joined by company_code
 func (h *IPOHandler) GetActiveIPOsWithGMP(c *fiber.Ctx) error {
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
 	ipos, err := h.Service.GetActiveIPOsWithGMP(c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	for i := range ipos {
+		h.Service.TextLimits.Apply(&ipos[i].IPO)
+		services.LabelIPO(&ipos[i].IPO, lang)
 	}
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -101,13 +349,14 @@ func (h *IPOHandler) GetActiveIPOsWithGMP(c *fiber.Ctx) error {
 
 // GetIPOByIDWithGMP returns a single IPO with GMP data joined by company_code
 func (h *IPOHandler) GetIPOByIDWithGMP(c *fiber.Ctx) error {
+	lang, err := services.ParseLabelLanguage(c.Query("lang"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
 	id := c.Params("id")
 	ipo, err := h.Service.GetIPOByIDWithGMP(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return errorResponse(c, "ipo_api", err, err.Error())
 	}
 	if ipo == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -115,6 +364,13 @@ func (h *IPOHandler) GetIPOByIDWithGMP(c *fiber.Ctx) error {
 			"error":   "IPO not found",
 		})
 	}
+	if err := h.Service.LoadIPODetail(c.Context(), &ipo.IPO); err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	if !c.QueryBool("full_text", false) {
+		h.Service.TextLimits.Apply(&ipo.IPO)
+	}
+	services.LabelIPO(&ipo.IPO, lang)
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    ipo,
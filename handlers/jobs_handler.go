@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/jobs"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobsHandler struct {
+	Locker *jobs.JobLocker
+	// Scheduler, when set, reports job dependencies and scheduling decisions
+	Scheduler *jobs.JobScheduler
+}
+
+func NewJobsHandler(locker *jobs.JobLocker) *JobsHandler {
+	return &JobsHandler{Locker: locker}
+}
+
+// GetJobLocks returns lock statistics for scheduled jobs on this instance
+func (h *JobsHandler) GetJobLocks(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"instance": h.Locker.InstanceID,
+		"data":     h.Locker.GetStats(),
+	})
+}
+
+// GetJobDependencies returns the declared job dependencies with whether each is met now,
+// the last scheduling decision (ran, skipped, queued, locked, deferred) of each job and
+// the scraping politeness windows
+func (h *JobsHandler) GetJobDependencies(c *fiber.Ctx) error {
+	if h.Scheduler == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Job scheduler not available",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"instance": h.Locker.InstanceID,
+		"data": fiber.Map{
+			"dependencies": h.Scheduler.DependencyStatus(c.Context()),
+			"jobs":         h.Scheduler.JobStatus(),
+			"politeness":   h.politeness(),
+		},
+	})
+}
+
+// politeness returns the scraping politeness windows and the one open now, if any
+func (h *JobsHandler) politeness() fiber.Map {
+	schedule := h.Scheduler.Politeness
+	windows := []shared.PolitenessWindow{}
+	if schedule != nil {
+		windows = append(windows, schedule.Windows...)
+	}
+	politeness := fiber.Map{"windows": windows, "scrape_jobs": h.Scheduler.ScrapeJobs}
+	if window, ends, ok := schedule.Active(); ok {
+		politeness["active"] = window
+		politeness["active_until"] = ends
+	}
+	return politeness
+}
@@ -1,56 +1,39 @@
 package handlers
 
 import (
-	"github.com/fenilmodi00/ipo-backend/models"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/services"
 	"github.com/gofiber/fiber/v2"
 )
 
-type MarketHandler struct{}
+// MarketHandler serves market index values with their intraday sparkline
+type MarketHandler struct {
+	Indices *services.MarketIndexService
+}
 
-func NewMarketHandler() *MarketHandler {
-	return &MarketHandler{}
+func NewMarketHandler(indices *services.MarketIndexService) *MarketHandler {
+	return &MarketHandler{Indices: indices}
 }
 
-// GetMarketIndices returns current market indices with mock data
+// GetMarketIndices returns the indices selected by ?symbols= (comma-separated index IDs),
+// or every index when it is absent
 func (h *MarketHandler) GetMarketIndices(c *fiber.Ctx) error {
-	// Mock data for market indices
-	indices := []models.MarketIndex{
-		{
-			ID:            "nifty50",
-			Name:          "NIFTY 50",
-			Value:         21453.95,
-			Change:        125.30,
-			ChangePercent: 0.59,
-			IsPositive:    true,
-		},
-		{
-			ID:            "sensex",
-			Name:          "SENSEX",
-			Value:         71315.09,
-			Change:        418.75,
-			ChangePercent: 0.59,
-			IsPositive:    true,
-		},
-		{
-			ID:            "banknifty",
-			Name:          "BANK NIFTY",
-			Value:         45892.35,
-			Change:        -89.45,
-			ChangePercent: -0.19,
-			IsPositive:    false,
-		},
-		{
-			ID:            "niftymidcap",
-			Name:          "NIFTY MIDCAP 100",
-			Value:         48765.20,
-			Change:        234.80,
-			ChangePercent: 0.48,
-			IsPositive:    true,
-		},
+	var ids []string
+	for _, id := range strings.Split(c.Query("symbols"), ",") {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	indices, err := h.Indices.Get(c.Context(), ids)
+	if err != nil {
+		return errorResponse(c, "market_api", err, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    indices,
+		"count":   len(indices),
 	})
 }
@@ -3,16 +3,24 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
+	"github.com/fenilmodi00/ipo-backend/jobs"
+	"github.com/fenilmodi00/ipo-backend/middleware"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 type PerformanceHandler struct {
 	DB               *sql.DB
 	IPOService       *services.IPOService
 	CachedIPOService *services.CachedIPOService
+	ResponseCache    *middleware.ResponseCache
+	Invalidation     *services.CacheInvalidationBus
 }
 
 func NewPerformanceHandler(db *sql.DB, ipoService *services.IPOService, cachedIPOService *services.CachedIPOService) *PerformanceHandler {
@@ -23,7 +31,8 @@ func NewPerformanceHandler(db *sql.DB, ipoService *services.IPOService, cachedIP
 	}
 }
 
-// GetPerformanceMetrics returns current performance metrics
+// GetPerformanceMetrics returns current performance metrics: query timing, both cache
+// layers (data and response) per key family, the database pool and scraper health
 func (h *PerformanceHandler) GetPerformanceMetrics(c *fiber.Ctx) error {
 	ctx := context.Background()
 
@@ -45,29 +54,26 @@ func (h *PerformanceHandler) GetPerformanceMetrics(c *fiber.Ctx) error {
 		"cached":      false,
 	}
 
-	// Test 2: Cached query performance
+	// Cache layers; the cached query is no longer timed here, as its lookup would count
+	// in the hit rates reported (see POST /performance/test)
+	cacheLayers := make(map[string]interface{})
 	if h.CachedIPOService != nil {
-		start = time.Now()
-		cachedIPOs, err := h.CachedIPOService.GetActiveIPOsWithGMP(ctx)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"error":   "Failed to test cached GetActiveIPOsWithGMP: " + err.Error(),
-			})
-		}
-		metrics["get_active_ipos_with_gmp_cached"] = map[string]interface{}{
-			"duration_ms": time.Since(start).Milliseconds(),
-			"count":       len(cachedIPOs),
-			"cached":      true,
-		}
-
-		// Cache statistics
-		metrics["cache_stats"] = h.CachedIPOService.GetCacheStats()
+		cacheLayers["data"] = h.CachedIPOService.GetCacheStats()
 	}
+	if h.ResponseCache != nil {
+		cacheLayers["response"] = h.ResponseCache.Stats(shared.DefaultHottestCacheKeys)
+	}
+	metrics["cache"] = cacheLayers
 
-	// Test 3: Database connection pool stats
+	// Database connection pool stats
 	dbStats := h.DB.Stats()
+	utilization := 0.0
+	if dbStats.MaxOpenConnections > 0 {
+		utilization = float64(dbStats.InUse) / float64(dbStats.MaxOpenConnections)
+	}
 	metrics["database_stats"] = map[string]interface{}{
+		"max_open_connections": dbStats.MaxOpenConnections,
+		"utilization":          utilization,
 		"open_connections":     dbStats.OpenConnections,
 		"in_use":               dbStats.InUse,
 		"idle":                 dbStats.Idle,
@@ -78,7 +84,15 @@ func (h *PerformanceHandler) GetPerformanceMetrics(c *fiber.Ctx) error {
 		"max_lifetime_closed":  dbStats.MaxLifetimeClosed,
 	}
 
-	// Test 4: Index usage statistics
+	// Upstream HTTP metrics per scraped host
+	metrics["http_hosts"] = shared.DefaultHTTPHostMetrics.Snapshot()
+	metrics["errors_by_category"] = shared.DefaultErrorCounter.Snapshot()
+	metrics["check_stages"] = services.DefaultCheckStageMetrics.Snapshot()
+	metrics["text_quality"] = services.DefaultTextQualityMetrics.Snapshot()
+	metrics["alerts"] = services.DefaultAlerter.Snapshot()
+	metrics["job_runs"] = jobs.DefaultJobRuns.Snapshot()
+
+	// Index usage statistics
 	indexStats, err := h.getIndexUsageStats(ctx)
 	if err != nil {
 		metrics["index_stats_error"] = err.Error()
@@ -174,6 +188,13 @@ func (h *PerformanceHandler) RunPerformanceTest(c *fiber.Ctx) error {
 
 // ClearCache clears all cached data
 func (h *PerformanceHandler) ClearCache(c *fiber.Ctx) error {
+	if h.Invalidation != nil {
+		if _, err := h.Invalidation.Publish(c.Context(), services.CacheInvalidation{All: true}); err != nil {
+			logrus.WithError(err).Warn("Cache cleared locally but broadcast to other replicas failed")
+		}
+	}
+	h.ResponseCache.Invalidate()
+
 	if h.CachedIPOService != nil {
 		h.CachedIPOService.InvalidateAllIPOCache()
 		return c.JSON(fiber.Map{
@@ -188,6 +209,48 @@ func (h *PerformanceHandler) ClearCache(c *fiber.Ctx) error {
 	})
 }
 
+// InvalidateCache drops cached data on every replica. ?ipo_id= clears one IPO's entries
+// and the IPO list responses, ?prefix= clears keys starting with the prefix (data cache
+// keys such as "ipo:" or "active_ipos", response cache paths such as "/api/v1/ipos"),
+// and with neither everything is cleared.
+func (h *PerformanceHandler) InvalidateCache(c *fiber.Ctx) error {
+	invalidation := services.CacheInvalidation{
+		Prefix: strings.TrimSpace(c.Query("prefix")),
+		IPOID:  strings.TrimSpace(c.Query("ipo_id")),
+	}
+	if invalidation.Prefix != "" && invalidation.IPOID != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Use either prefix or ipo_id, not both",
+		})
+	}
+	if invalidation.IPOID != "" {
+		if _, err := uuid.Parse(invalidation.IPOID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "ipo_id must be a valid UUID",
+			})
+		}
+	}
+	invalidation.All = invalidation.Prefix == "" && invalidation.IPOID == ""
+
+	removed, err := h.Invalidation.Publish(c.Context(), invalidation)
+	if err != nil {
+		// The local caches are already cleared; only the other replicas may be stale
+		return errorResponse(c, "PerformanceHandler", err, "Cache cleared on this replica but broadcast failed")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"all":     invalidation.All,
+			"prefix":  invalidation.Prefix,
+			"ipo_id":  invalidation.IPOID,
+			"removed": removed,
+		},
+	})
+}
+
 // WarmupCache pre-loads frequently accessed data
 func (h *PerformanceHandler) WarmupCache(c *fiber.Ctx) error {
 	if h.CachedIPOService != nil {
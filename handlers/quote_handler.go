@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// QuoteHandler serves live market prices for listed IPOs
+type QuoteHandler struct {
+	IPOService *services.IPOService
+	Quotes     *services.QuoteService
+}
+
+func NewQuoteHandler(ipoService *services.IPOService, quotes *services.QuoteService) *QuoteHandler {
+	return &QuoteHandler{IPOService: ipoService, Quotes: quotes}
+}
+
+// GetIPOQuote returns the current price, day change and gain over the issue price
+func (h *QuoteHandler) GetIPOQuote(c *fiber.Ctx) error {
+	ipo, err := h.IPOService.GetIPOByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return errorResponse(c, "ipo_api", err, err.Error())
+	}
+	if ipo == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	quote, err := h.Quotes.GetIPOQuote(c.Context(), ipo)
+	if err != nil {
+		return errorResponse(c, "quote_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    quote,
+	})
+}
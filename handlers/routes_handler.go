@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutesHandler lists the API's routes with their auth, rate limit and cache policies
+type RoutesHandler struct {
+	App     *fiber.App
+	Catalog *middleware.RouteCatalog
+}
+
+func NewRoutesHandler(app *fiber.App, catalog *middleware.RouteCatalog) *RoutesHandler {
+	return &RoutesHandler{App: app, Catalog: catalog}
+}
+
+// GetRoutes returns every registered route. ?prefix= keeps routes whose path starts with
+// it and ?auth= those with the given auth policy (e.g. none or admin_allowlist).
+func (h *RoutesHandler) GetRoutes(c *fiber.Ctx) error {
+	prefix := strings.TrimSpace(c.Query("prefix"))
+	auth := strings.TrimSpace(c.Query("auth"))
+
+	routes := []middleware.RouteInfo{}
+	for _, route := range h.Catalog.Routes(h.App) {
+		if prefix != "" && !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+		if auth != "" && route.Auth != auth {
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    routes,
+		"count":   len(routes),
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ScrapeRunHandler serves persisted scraper run reports for operational review
+type ScrapeRunHandler struct {
+	ScrapeRuns *services.ScrapeRunService
+	Scraper    *services.ChittorgarhIPOScrapingService
+}
+
+func NewScrapeRunHandler(scrapeRuns *services.ScrapeRunService) *ScrapeRunHandler {
+	return &ScrapeRunHandler{ScrapeRuns: scrapeRuns}
+}
+
+// GetScrapeRuns lists recent run reports, newest first
+func (h *ScrapeRunHandler) GetScrapeRuns(c *fiber.Ctx) error {
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 200 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "limit must be between 1 and 200",
+			})
+		}
+		limit = parsed
+	}
+
+	runs, err := h.ScrapeRuns.List(c.Context(), c.Query("job"), limit)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    runs,
+		"count":   len(runs),
+	})
+}
+
+// GetScrapeRun returns one run report with its per-field stats and error samples
+func (h *ScrapeRunHandler) GetScrapeRun(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid scrape run ID",
+		})
+	}
+
+	run, err := h.ScrapeRuns.Get(c.Context(), id)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// GetIPOListSources reports the health and failover state of each IPO list source
+func (h *ScrapeRunHandler) GetIPOListSources(c *fiber.Ctx) error {
+	sources := h.Scraper.IPOListSourceHealth()
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sources,
+		"count":   len(sources),
+	})
+}
+
+// GetShadowExtraction reports how the shadow extraction strategy agrees with the primary,
+// per field with recent mismatch examples
+func (h *ScrapeRunHandler) GetShadowExtraction(c *fiber.Ctx) error {
+	report := h.Scraper.ShadowExtractionReport()
+	if report == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Shadow extraction is not enabled",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ResetShadowExtraction drops the shadow comparisons so agreement is measured afresh
+func (h *ScrapeRunHandler) ResetShadowExtraction(c *fiber.Ctx) error {
+	h.Scraper.ResetShadowExtraction()
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Shadow extraction stats reset",
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ScraperPatternHandler manages the navigation and boilerplate patterns stripped
+// from scraped company text
+type ScraperPatternHandler struct {
+	Patterns *services.TextPatternStore
+}
+
+func NewScraperPatternHandler(patterns *services.TextPatternStore) *ScraperPatternHandler {
+	return &ScraperPatternHandler{Patterns: patterns}
+}
+
+// textPatternRequest is the body accepted by AddPattern and TestPattern
+type textPatternRequest struct {
+	Kind        string  `json:"kind"`
+	Pattern     string  `json:"pattern"`
+	Description *string `json:"description"`
+	CreatedBy   *string `json:"created_by"`
+	Text        string  `json:"text"`
+}
+
+// GetPatterns lists the patterns currently applied by the extractors
+func (h *ScraperPatternHandler) GetPatterns(c *fiber.Ctx) error {
+	patterns, loadedAt := h.Patterns.Patterns()
+
+	if kind := c.Query("kind"); kind != "" {
+		filtered := patterns[:0]
+		for _, pattern := range patterns {
+			if pattern.Kind == kind {
+				filtered = append(filtered, pattern)
+			}
+		}
+		patterns = filtered
+	}
+
+	return c.JSON(fiber.Map{
+		"success":   true,
+		"data":      patterns,
+		"count":     len(patterns),
+		"loaded_at": loadedAt,
+	})
+}
+
+// AddPattern persists a new pattern and reloads the pattern set
+func (h *ScraperPatternHandler) AddPattern(c *fiber.Ctx) error {
+	var req textPatternRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	pattern, err := h.Patterns.AddPattern(c.Context(), models.TextPattern{
+		Kind:        strings.TrimSpace(req.Kind),
+		Pattern:     req.Pattern,
+		Description: req.Description,
+		CreatedBy:   req.CreatedBy,
+	})
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"kind":    pattern.Kind,
+		"pattern": pattern.Pattern,
+	}).Info("Scraper text pattern added via admin endpoint")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    pattern,
+	})
+}
+
+// RemovePattern deletes a pattern and reloads the pattern set
+func (h *ScraperPatternHandler) RemovePattern(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid pattern ID",
+		})
+	}
+
+	if err := h.Patterns.RemovePattern(c.Context(), id); err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	logrus.WithField("pattern_id", id).Info("Scraper text pattern removed via admin endpoint")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Pattern removed",
+	})
+}
+
+// TestPattern applies a candidate pattern, or the current set for a kind, to sample text
+func (h *ScraperPatternHandler) TestPattern(c *fiber.Ctx) error {
+	var req textPatternRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+	if req.Text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "text is required",
+		})
+	}
+
+	result, err := h.Patterns.TestPattern(strings.TrimSpace(req.Kind), req.Pattern, req.Text)
+	if err != nil {
+		return errorResponse(c, "admin_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
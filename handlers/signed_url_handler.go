@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Resources a signed URL can grant access to
+const (
+	SignedResourceIPOExport = "ipo_export"
+	SignedResourceScrapeRun = "scrape_run"
+)
+
+// SignedURLHandler issues signed URLs for admin resources, so downstream tools can fetch
+// one export or run report without admin credentials
+type SignedURLHandler struct {
+	URLs *middleware.SignedURLs
+	// DefaultTTL is used when a request sets no ttl_seconds
+	DefaultTTL time.Duration
+}
+
+func NewSignedURLHandler(urls *middleware.SignedURLs, defaultTTL time.Duration) *SignedURLHandler {
+	return &SignedURLHandler{URLs: urls, DefaultTTL: defaultTTL}
+}
+
+type signedURLRequest struct {
+	Resource   string            `json:"resource"`
+	ID         string            `json:"id"`
+	Query      map[string]string `json:"query"`
+	TTLSeconds int               `json:"ttl_seconds"`
+}
+
+// CreateSignedURL signs a URL for one resource: an IPO export (query from and to) or a
+// scrape run report (id)
+func (h *SignedURLHandler) CreateSignedURL(c *fiber.Ctx) error {
+	var req signedURLRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	query := url.Values{}
+	var path string
+	switch req.Resource {
+	case SignedResourceIPOExport:
+		path = "/api/v1/signed/export/ipos"
+		for key, value := range req.Query {
+			if key != "from" && key != "to" {
+				return badSignedURLRequest(c, "ipo_export accepts only the from and to query parameters")
+			}
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return badSignedURLRequest(c, key+" must be a date in YYYY-MM-DD format")
+			}
+			query.Set(key, value)
+		}
+	case SignedResourceScrapeRun:
+		id, err := uuid.Parse(req.ID)
+		if err != nil {
+			return badSignedURLRequest(c, "id must be a scrape run ID")
+		}
+		path = "/api/v1/signed/scrape-runs/" + id.String()
+	default:
+		return badSignedURLRequest(c, "resource must be one of ipo_export, scrape_run")
+	}
+
+	ttl := h.DefaultTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+		if ttl <= 0 || ttl > middleware.MaxSignedURLTTL {
+			return badSignedURLRequest(c, "ttl_seconds must be between 1 and 86400")
+		}
+	}
+
+	signedURL, expiresAt, err := h.URLs.Sign(path, query, ttl)
+	if errors.Is(err, middleware.ErrSignedURLsDisabled) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	if err != nil {
+		return errorResponse(c, "admin_api", err, "Failed to sign URL")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"resource":   req.Resource,
+			"url":        signedURL,
+			"expires_at": expiresAt,
+			"single_use": true,
+		},
+	})
+}
+
+func badSignedURLRequest(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error":   message,
+	})
+}
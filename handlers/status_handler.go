@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/jobs"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Source health states on the public status page
+const (
+	SourceHealthy  = "healthy"
+	SourceDegraded = "degraded" // recent failures, or the last run saved only part of the data
+	SourceDown     = "down"     // benched after repeated failures, or the last run saved nothing
+	SourceUnknown  = "unknown"  // not used since this instance started
+)
+
+// StatusHandler serves the public status page: how fresh each kind of data is and
+// whether its sources are healthy, for "data updated X minutes ago" banners
+type StatusHandler struct {
+	ScrapeRuns *services.ScrapeRunService
+	Freshness  *services.DataFreshnessService
+	// IPOListSources, when set, reports the failover state of each IPO list source
+	IPOListSources func() []services.IPOListSourceHealth
+}
+
+func NewStatusHandler(scrapeRuns *services.ScrapeRunService, freshness *services.DataFreshnessService) *StatusHandler {
+	return &StatusHandler{ScrapeRuns: scrapeRuns, Freshness: freshness}
+}
+
+// dataFreshness is when one kind of data was last updated; AgeSeconds is measured on the
+// server so client clock skew does not distort the banner
+type dataFreshness struct {
+	UpdatedAt  *time.Time `json:"updated_at"`
+	AgeSeconds *int64     `json:"age_seconds"`
+}
+
+// sourceHealth is the public health summary of one data source. It leaves out error
+// messages, which can carry internal URLs.
+type sourceHealth struct {
+	Name          string     `json:"name"`
+	Kind          string     `json:"kind"` // "ipo_list_source" or "data_feed"
+	Status        string     `json:"status"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+}
+
+// GetStatus returns the last successful daily scrape, the last GMP, subscription and IPO
+// list updates, and per-source health. A section that can't be loaded is reported under
+// "errors" instead of failing the whole response.
+func (h *StatusHandler) GetStatus(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	data := fiber.Map{}
+	sectionErrors := make(map[string]string)
+
+	dailyScrape, err := h.ScrapeRuns.LastSucceeded(ctx, jobs.DailyIPOUpdateJobName)
+	if err != nil {
+		sectionErrors["daily_scrape"] = "unavailable"
+	} else {
+		data["daily_scrape"] = newDataFreshness(dailyScrape, now)
+	}
+
+	freshness, err := h.Freshness.Freshness(ctx)
+	if err != nil {
+		sectionErrors["data"] = "unavailable"
+	} else {
+		data["ipo_list"] = newDataFreshness(freshness.IPOListUpdatedAt, now)
+		data["gmp"] = newDataFreshness(freshness.GMPUpdatedAt, now)
+		data["subscription"] = newDataFreshness(freshness.SubscriptionUpdatedAt, now)
+	}
+
+	sources := []sourceHealth{}
+	if h.IPOListSources != nil {
+		for _, source := range h.IPOListSources() {
+			sources = append(sources, ipoListSourceHealth(source, now))
+		}
+	}
+	runs, err := h.ScrapeRuns.Latest(ctx)
+	if err != nil {
+		sectionErrors["sources"] = "unavailable"
+	} else {
+		sources = append(sources, dataFeedHealth(runs)...)
+	}
+	data["sources"] = sources
+
+	if len(sectionErrors) > 0 {
+		data["errors"] = sectionErrors
+	}
+	data["generated_at"] = now
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func newDataFreshness(updatedAt *time.Time, now time.Time) dataFreshness {
+	freshness := dataFreshness{UpdatedAt: updatedAt}
+	if updatedAt != nil {
+		age := int64(now.Sub(*updatedAt).Seconds())
+		if age < 0 {
+			age = 0
+		}
+		freshness.AgeSeconds = &age
+	}
+	return freshness
+}
+
+// ipoListSourceHealth summarizes one IPO list source's failover state on this instance
+func ipoListSourceHealth(source services.IPOListSourceHealth, now time.Time) sourceHealth {
+	health := sourceHealth{Name: source.Name, Kind: "ipo_list_source", LastSuccessAt: source.LastSuccessAt}
+	health.LastCheckedAt = source.LastSuccessAt
+	if source.LastFailureAt != nil && (health.LastCheckedAt == nil || source.LastFailureAt.After(*health.LastCheckedAt)) {
+		health.LastCheckedAt = source.LastFailureAt
+	}
+
+	switch {
+	case source.CooldownUntil != nil && now.Before(*source.CooldownUntil):
+		health.Status = SourceDown
+	case source.ConsecutiveFailures > 0:
+		health.Status = SourceDegraded
+	case source.Successes == 0:
+		health.Status = SourceUnknown
+	default:
+		health.Status = SourceHealthy
+	}
+	return health
+}
+
+// dataFeedHealth derives the health of the scraped data feeds from the latest run
+// reports: the IPO list from the daily update, and each refresh task from the latest
+// orchestrated refresh
+func dataFeedHealth(runs []models.ScrapeRun) []sourceHealth {
+	feeds := []sourceHealth{}
+	for i := range runs {
+		run := &runs[i]
+		switch run.JobName {
+		case jobs.DailyIPOUpdateJobName:
+			feed := sourceHealth{Name: "ipo_details", Kind: "data_feed", Status: SourceHealthy, LastCheckedAt: &run.FinishedAt}
+			switch run.Status {
+			case models.ScrapeRunPartial:
+				feed.Status = SourceDegraded
+			case models.ScrapeRunFailed:
+				feed.Status = SourceDown
+			}
+			if feed.Status != SourceDown {
+				feed.LastSuccessAt = &run.FinishedAt
+			}
+			feeds = append(feeds, feed)
+		case jobs.RefreshOrchestratorName:
+			tasks, _ := run.ExtractionMetrics["tasks"].(map[string]interface{})
+			for name, value := range tasks {
+				task, _ := value.(map[string]interface{})
+				if skipped, _ := task["skipped"].(bool); skipped {
+					continue
+				}
+				feed := sourceHealth{Name: name, Kind: "data_feed", Status: SourceHealthy, LastCheckedAt: &run.FinishedAt}
+				if message, _ := task["error"].(string); message != "" {
+					feed.Status = SourceDown
+				} else {
+					feed.LastSuccessAt = &run.FinishedAt
+				}
+				feeds = append(feeds, feed)
+			}
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].Name < feeds[j].Name })
+	return feeds
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SubscriptionHandler serves category-wise subscription time series for charts
+type SubscriptionHandler struct {
+	Subscriptions *services.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptions *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{Subscriptions: subscriptions}
+}
+
+// GetSubscriptionHistory returns hourly subscription multiples per category for an IPO
+func (h *SubscriptionHandler) GetSubscriptionHistory(c *fiber.Ctx) error {
+	ipoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	history, err := h.Subscriptions.GetHistory(c.Context(), ipoID)
+	if err != nil {
+		return errorResponse(c, "subscription_api", err, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    history,
+		"count":   len(history.Points),
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TelegramSecretHeader carries the secret_token registered with setWebhook
+const TelegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramHandler receives Telegram bot updates in webhook mode
+type TelegramHandler struct {
+	Bot    *services.TelegramBot
+	Secret string
+}
+
+func NewTelegramHandler(bot *services.TelegramBot, secret string) *TelegramHandler {
+	return &TelegramHandler{Bot: bot, Secret: secret}
+}
+
+// Webhook verifies the secret token and handles the update in the background, so
+// Telegram gets its 200 before slow allotment checks finish
+func (h *TelegramHandler) Webhook(c *fiber.Ctx) error {
+	if subtle.ConstantTimeCompare([]byte(c.Get(TelegramSecretHeader)), []byte(h.Secret)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid webhook secret",
+		})
+	}
+
+	var update services.TelegramUpdate
+	if err := json.Unmarshal(c.Body(), &update); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	go h.Bot.HandleUpdate(context.Background(), update)
+	return c.SendStatus(fiber.StatusOK)
+}
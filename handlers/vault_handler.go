@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DeviceIDHeader carries the anonymous device ID that owns saved PANs for signed-out users
+const DeviceIDHeader = "X-Device-ID"
+
+// VaultHandler serves the opt-in saved PAN vault
+type VaultHandler struct {
+	Vault  *services.PANVaultService
+	Checks *CheckHandler
+}
+
+func NewVaultHandler(vault *services.PANVaultService, checks *CheckHandler) *VaultHandler {
+	return &VaultHandler{Vault: vault, Checks: checks}
+}
+
+// vaultErrorResponse maps a disabled vault to 503 and other errors by category
+func vaultErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrPANVaultDisabled) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return errorResponse(c, "pan_vault", err, err.Error())
+}
+
+// ownerHash identifies the vault owner: the signed-in account if any, so saved PANs
+// follow the user across devices, otherwise the X-Device-ID header
+func (h *VaultHandler) ownerHash(c *fiber.Ctx) (string, error) {
+	if account := middleware.AccountFromContext(c); account != nil {
+		return services.AccountOwnerHash(account.ID), nil
+	}
+	return services.DeviceOwnerHash(c.Get(DeviceIDHeader))
+}
+
+// GetSavedPANs lists the device's saved PANs in masked form
+func (h *VaultHandler) GetSavedPANs(c *fiber.Ctx) error {
+	owner, err := h.ownerHash(c)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	saved, err := h.Vault.List(c.Context(), owner)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    saved,
+		"count":   len(saved),
+	})
+}
+
+// AddSavedPAN saves a PAN for the device, encrypted at rest
+func (h *VaultHandler) AddSavedPAN(c *fiber.Ctx) error {
+	var req struct {
+		PAN   string `json:"pan"`
+		Label string `json:"label"`
+	}
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+
+	owner, err := h.ownerHash(c)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	saved, err := h.Vault.Add(c.Context(), owner, req.PAN, req.Label)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    saved,
+	})
+}
+
+// RemoveSavedPAN deletes a saved PAN from the device's vault
+func (h *VaultHandler) RemoveSavedPAN(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid saved PAN ID",
+		})
+	}
+
+	owner, err := h.ownerHash(c)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	if err := h.Vault.Remove(c.Context(), owner, id); err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// CheckSavedPANs checks every saved PAN of the device against one IPO. Cached results
+// are returned inline; other PANs are queued and return a check_id to poll.
+func (h *VaultHandler) CheckSavedPANs(c *fiber.Ctx) error {
+	var req struct {
+		IPOID string `json:"ipo_id"`
+	}
+	if err := parseJSONBody(c, &req); err != nil {
+		return invalidBodyResponse(c, err)
+	}
+	if _, err := uuid.Parse(req.IPOID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid IPO ID format",
+		})
+	}
+
+	owner, err := h.ownerHash(c)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+
+	saved, err := h.Vault.Unlock(c.Context(), owner)
+	if err != nil {
+		return vaultErrorResponse(c, err)
+	}
+	if len(saved) == 0 {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    []models.VaultCheckEntry{},
+			"count":   0,
+		})
+	}
+
+	ipo, err := h.Checks.IPOService.GetIPOByID(c.Context(), req.IPOID)
+	if err != nil {
+		return errorResponse(c, "pan_vault", err, err.Error())
+	}
+	if ipo == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "IPO not found",
+		})
+	}
+
+	entries := make([]models.VaultCheckEntry, 0, len(saved))
+	checked := make([]uuid.UUID, 0, len(saved))
+	for _, pan := range saved {
+		entry := h.Checks.checkOnBehalf(c, ipo, pan.PAN)
+		entry.SavedPANID = pan.ID
+		entry.MaskedPAN = pan.MaskedPAN
+		entry.Label = pan.Label
+		entries = append(entries, entry)
+		if entry.Outcome != models.VaultCheckFailed {
+			checked = append(checked, pan.ID)
+		}
+	}
+
+	if err := h.Vault.MarkChecked(c.Context(), checked); err != nil {
+		logrus.WithError(err).Warn("Failed to record saved PAN check time")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    entries,
+		"count":   len(entries),
+	})
+}
@@ -8,8 +8,13 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// CacheCleanupJobName is the lock name of the cache cleanup job
+const CacheCleanupJobName = "cache_cleanup"
+
 type CacheCleanupJob struct {
 	CacheService *services.CacheService
+	QuotaService *services.CheckQuotaService
+	Accounts     *services.AccountService
 }
 
 func NewCacheCleanupJob(cacheService *services.CacheService) *CacheCleanupJob {
@@ -18,8 +23,8 @@ func NewCacheCleanupJob(cacheService *services.CacheService) *CacheCleanupJob {
 
 func (j *CacheCleanupJob) Run() {
 	logrus.Info("Starting Cache Cleanup Job")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
+	ctx, done := DefaultJobRuns.Start(context.Background(), CacheCleanupJobName, 10*time.Minute)
+	defer done()
 
 	// Use ctx to avoid lint error
 	select {
@@ -28,5 +33,23 @@ func (j *CacheCleanupJob) Run() {
 	default:
 	}
 	// j.CacheService.CleanupExpired(ctx)
+
+	if j.QuotaService != nil {
+		removed, err := j.QuotaService.CleanupExpired(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to clean up check quota counters: %v", err)
+		} else {
+			logrus.Infof("Removed %d expired check quota counters", removed)
+		}
+	}
+
+	if j.Accounts != nil {
+		removed, err := j.Accounts.CleanupExpired(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to clean up account sessions: %v", err)
+		} else {
+			logrus.Infof("Removed %d expired account sessions and login challenges", removed)
+		}
+	}
 	logrus.Info("Cache Cleanup Job completed")
 }
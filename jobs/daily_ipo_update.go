@@ -2,10 +2,13 @@ package jobs
 
 import (
 	"context"
+	"strconv"
 	"time"
 
+	"github.com/fenilmodi00/ipo-backend/middleware"
 	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/sirupsen/logrus"
 )
 
@@ -13,8 +16,15 @@ type DailyIPOUpdateJob struct {
 	ScrapingService *services.ChittorgarhIPOScrapingService
 	IPOService      *services.IPOService
 	UtilityService  *services.UtilityService
+	ResponseCache   *middleware.ResponseCache
+	ScrapeRuns      *services.ScrapeRunService
+	// Politeness, when set, pauses between IPO detail pages in its slow windows
+	Politeness *shared.PolitenessSchedule
 }
 
+// DailyIPOUpdateJobName is the lock and scrape run name used for the daily IPO update job
+const DailyIPOUpdateJobName = "daily_ipo_update"
+
 func NewDailyIPOUpdateJob(scrapingService *services.ChittorgarhIPOScrapingService, ipoService *services.IPOService, utilityService *services.UtilityService) *DailyIPOUpdateJob {
 	return &DailyIPOUpdateJob{
 		ScrapingService: scrapingService,
@@ -25,23 +35,56 @@ func NewDailyIPOUpdateJob(scrapingService *services.ChittorgarhIPOScrapingServic
 
 func (j *DailyIPOUpdateJob) Run() {
 	logrus.Info("Starting Simplified Daily IPO Update Job")
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-	defer cancel()
+	ctx, done := DefaultJobRuns.Start(context.Background(), DailyIPOUpdateJobName, 15*time.Minute)
+	defer done()
+
+	// Collect a run report; extraction counters are reset so they cover this run only
+	recorder := services.NewScrapeRunRecorder(DailyIPOUpdateJobName)
+	j.ScrapingService.ResetExtractionMetrics()
+	defer func() {
+		run := recorder.Finish(j.ScrapingService.GetExtractionMetrics())
+		j.saveRunReport(run)
+		j.evaluateAlerts(run)
+		j.ScrapingService.LogShadowExtractionSummary()
+	}()
 
 	logrus.Info("Fetching IPO list from simplified scraping service...")
-	items, err := j.ScrapingService.FetchAvailableIPOList()
+	items, err := j.ScrapingService.FetchAvailableIPOList(ctx)
 	if err != nil {
 		logrus.Errorf("Failed to run Daily IPO Update Job: failed to fetch IPO list: %v", err)
+		recorder.RecordError("ipo_list", err)
 		return
 	}
 
+	// Skip list entries already merged into another IPO so they are not recreated
+	if merged, err := j.IPOService.MergedStockIDs(ctx); err != nil {
+		logrus.Warnf("Failed to load merged IPOs, processing the full list: %v", err)
+	} else if len(merged) > 0 {
+		kept := items[:0]
+		for _, item := range items {
+			if canonical, ok := merged[strconv.Itoa(item.ID)]; ok {
+				logrus.Debugf("Skipping %s, merged into stock ID %s", item.IPONewsTitle, canonical)
+				continue
+			}
+			kept = append(kept, item)
+		}
+		items = kept
+	}
+
 	logrus.Infof("Fetched %d IPOs from Chittorgarh for processing", len(items))
+	recorder.SetTotal(len(items))
 
 	successCount := 0
 	failureCount := 0
 	partialSuccessCount := 0
 
 	for i, item := range items {
+		if i > 0 {
+			if err := j.Politeness.Wait(ctx); err != nil {
+				logrus.Warnf("Daily IPO Update Job stopped during politeness pause: %v", err)
+				break
+			}
+		}
 		logrus.WithFields(logrus.Fields{
 			"ipo_index":  i + 1,
 			"total_ipos": len(items),
@@ -49,9 +92,10 @@ func (j *DailyIPOUpdateJob) Run() {
 		}).Infof("Processing IPO %d/%d: %s", i+1, len(items), item.IPONewsTitle)
 
 		// Scrape detailed IPO data using simplified scraper
-		ipoModel, err := j.ScrapingService.ScrapeDetailedIPOInformation(item)
+		ipoModel, err := j.ScrapingService.ScrapeDetailedIPOInformation(ctx, item)
 		if err != nil {
 			logrus.Errorf("Failed to scrape details for %s: %v", item.IPONewsTitle, err)
+			recorder.RecordFailure(item.IPONewsTitle, err)
 			failureCount++
 			continue
 		}
@@ -62,16 +106,21 @@ func (j *DailyIPOUpdateJob) Run() {
 
 		// Analyze data completeness
 		completeness := j.analyzeDataCompleteness(ipoModel)
+		recorder.RecordFields(completeness.PopulatedFieldNames,
+			append(completeness.MissingCriticalFields, completeness.MissingOptionalFields...))
 
 		// Log field population status
 		j.logFieldPopulation(ipoModel, completeness)
 
 		// Persist to ipos table with comprehensive error handling
-		if err := j.IPOService.UpsertIPO(ctx, *ipoModel); err != nil {
+		outcome, err := j.IPOService.UpsertIPOWithOutcome(ctx, *ipoModel)
+		if err != nil {
 			logrus.Errorf("Failed to upsert IPO %s to ipos table: %v", item.IPONewsTitle, err)
+			recorder.RecordFailure(item.IPONewsTitle, err)
 			failureCount++
 			continue
 		}
+		recorder.RecordSaved(completeness.CriticalFieldsComplete && completeness.OverallCompleteness >= 80.0, outcome)
 
 		// Categorize success type
 		if completeness.CriticalFieldsComplete {
@@ -100,6 +149,23 @@ func (j *DailyIPOUpdateJob) Run() {
 		}
 	}
 
+	// Fold near-identical IPOs listed under several entries into the newest one
+	mergedCount := 0
+	if merged, err := j.IPOService.DeduplicateIPOs(ctx); err != nil {
+		logrus.Errorf("Failed to run IPO dedup pass: %v", err)
+		recorder.RecordError("ipo_dedup", err)
+	} else {
+		mergedCount = len(merged)
+		if mergedCount > 0 {
+			logrus.Infof("Merged %d duplicate IPOs", mergedCount)
+		}
+	}
+
+	// Drop cached API responses once any IPO has been written
+	if successCount+partialSuccessCount+mergedCount > 0 {
+		j.ResponseCache.Invalidate()
+	}
+
 	// Log comprehensive job completion summary
 	totalProcessed := successCount + partialSuccessCount + failureCount
 	logrus.WithFields(logrus.Fields{
@@ -124,6 +190,7 @@ type DataCompleteness struct {
 	CriticalCompleteness   float64  `json:"critical_completeness"`
 	MissingCriticalFields  []string `json:"missing_critical_fields"`
 	MissingOptionalFields  []string `json:"missing_optional_fields"`
+	PopulatedFieldNames    []string `json:"populated_field_names"`
 }
 
 // analyzeDataCompleteness analyzes the completeness of IPO data
@@ -165,6 +232,7 @@ func (j *DailyIPOUpdateJob) analyzeDataCompleteness(ipo *models.IPO) DataComplet
 	criticalFieldsComplete := 0
 	var missingCriticalFields []string
 	var missingOptionalFields []string
+	var populatedFieldNames []string
 
 	// Check critical fields
 	for fieldName, value := range criticalFields {
@@ -179,6 +247,7 @@ func (j *DailyIPOUpdateJob) analyzeDataCompleteness(ipo *models.IPO) DataComplet
 	for fieldName, value := range allFields {
 		if j.isFieldPopulated(value) {
 			populatedFields++
+			populatedFieldNames = append(populatedFieldNames, fieldName)
 		} else {
 			// Check if it's a critical field
 			if _, isCritical := criticalFields[fieldName]; !isCritical {
@@ -201,9 +270,48 @@ func (j *DailyIPOUpdateJob) analyzeDataCompleteness(ipo *models.IPO) DataComplet
 		CriticalCompleteness:   criticalCompleteness,
 		MissingCriticalFields:  missingCriticalFields,
 		MissingOptionalFields:  missingOptionalFields,
+		PopulatedFieldNames:    populatedFieldNames,
 	}
 }
 
+// evaluateAlerts raises an alert when the run's field extraction rate is below threshold.
+// A run that failed before extracting anything counts as 0%.
+func (j *DailyIPOUpdateJob) evaluateAlerts(run *models.ScrapeRun) {
+	rate, ok := services.FieldExtractionRate(run)
+	if !ok && run.Status != models.ScrapeRunFailed {
+		return
+	}
+
+	services.DefaultAlerter.Evaluate(services.AlertFieldExtraction, "", rate, map[string]interface{}{
+		"run_id":        run.ID.String(),
+		"status":        run.Status,
+		"total_items":   run.TotalItems,
+		"failure_count": run.FailureCount,
+		"error_samples": run.ErrorSamples,
+	})
+}
+
+// saveRunReport persists the run report; failures are logged and never fail the job
+func (j *DailyIPOUpdateJob) saveRunReport(run *models.ScrapeRun) {
+	if j.ScrapeRuns == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := j.ScrapeRuns.Save(ctx, run); err != nil {
+		logrus.WithError(err).Warn("Failed to save daily IPO scrape run report")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"run_id":      run.ID,
+		"status":      run.Status,
+		"duration_ms": run.DurationMs,
+	}).Info("Saved daily IPO scrape run report")
+}
+
 // isFieldPopulated checks if a field has meaningful data using utility service
 func (j *DailyIPOUpdateJob) isFieldPopulated(value interface{}) bool {
 	switch v := value.(type) {
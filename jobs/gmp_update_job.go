@@ -1,56 +1,161 @@
 package jobs
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
+	"github.com/fenilmodi00/ipo-backend/middleware"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/sirupsen/logrus"
 )
 
 type GMPUpdateJob struct {
-	DB               *sql.DB
+	DB *sql.DB
+	// Scraper fetches GMP data; SimpleGMPService saves it whichever implementation fetched
+	Scraper          services.GMPScraper
 	SimpleGMPService *services.SimpleGMPService
+	Locker           *JobLocker
+	ResponseCache    *middleware.ResponseCache
+	// Schedule decides per IPO when its GMP is next refreshed
+	Schedule *services.GMPSchedule
+	// Politeness, when set, holds scheduled refreshes in its skip windows
+	Politeness *shared.PolitenessSchedule
 }
 
+// GMPUpdateJobName is the lock name used for the GMP update job
+const GMPUpdateJobName = "gmp_update"
+
+// gmpUpdateDeadline bounds one GMP refresh
+const gmpUpdateDeadline = 10 * time.Minute
+
 func NewGMPUpdateJob(db *sql.DB) *GMPUpdateJob {
+	simpleGMPService := services.NewSimpleGMPService(db)
 	return &GMPUpdateJob{
 		DB:               db,
-		SimpleGMPService: services.NewSimpleGMPService(db),
+		Scraper:          simpleGMPService,
+		SimpleGMPService: simpleGMPService,
+		Schedule:         services.NewGMPSchedule(db),
 	}
 }
 
+// Start refreshes due GMP rows, then sleeps until the next IPO is due: hourly normally,
+// every 10 minutes for IPOs within two days of listing. Listed IPOs are no longer tracked.
+// Inside a skip politeness window the job sleeps until the window ends.
 func (j *GMPUpdateJob) Start() {
-	logrus.Info("Starting GMP Update Job (runs every 1 hour)...")
-	ticker := time.NewTicker(1 * time.Hour) // Run every 1 hour
+	logrus.Infof("Starting GMP Update Job (every %v, every %v within %v of listing)...",
+		j.Schedule.Interval, j.Schedule.NearListingInterval, j.Schedule.NearListingWindow)
 
 	go func() {
-		// Run immediately on start
-		j.Run()
-
-		for range ticker.C {
-			j.Run()
+		for {
+			if until, ok := j.Politeness.SkipUntil(); ok {
+				logrus.WithField("until", until.Format(time.RFC3339)).Info("Politeness window is open, holding GMP refresh")
+				time.Sleep(time.Until(until))
+				continue
+			}
+			j.Locker.RunExclusive(GMPUpdateJobName, func() {
+				ctx, done := DefaultJobRuns.Start(context.Background(), GMPUpdateJobName, gmpUpdateDeadline)
+				defer done()
+				if _, err := j.RefreshDue(ctx); err != nil {
+					logrus.Errorf("GMP Update Job failed: %v", err)
+				}
+			})
+			time.Sleep(j.nextWait())
 		}
 	}()
 }
 
+// nextWait is how long to sleep until the next GMP row is due
+func (j *GMPUpdateJob) nextWait() time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tracking, err := j.Schedule.Tracking(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load GMP schedule, retrying after the near-listing interval")
+		return j.Schedule.NearListingInterval
+	}
+	return j.Schedule.NextWait(tracking)
+}
+
+// RunExclusive runs the job only if no other replica is currently running it
+func (j *GMPUpdateJob) RunExclusive() bool {
+	return j.Locker.RunExclusive(GMPUpdateJobName, j.Run)
+}
+
 func (j *GMPUpdateJob) Run() {
 	startTime := time.Now()
 	logrus.Info("Running GMP Update Job with SimpleGMPService...")
 
-	// Fetch and save GMP data using the simple service (handles modern InvestorGain structure)
-	gmpData, err := j.SimpleGMPService.FetchAndSaveGMPData()
+	saved, err := j.Refresh()
 	if err != nil {
-		logrus.Errorf("GMP Update Job failed: error fetching GMP data: %v", err)
+		logrus.Errorf("GMP Update Job failed: %v", err)
 		return
 	}
 
+	duration := time.Since(startTime)
+	logrus.Infof("GMP Update Job completed successfully: wrote %d GMP records, %d unchanged (took %v)",
+		saved.Written, saved.Unchanged, duration)
+}
+
+// Refresh fetches GMP data and saves every IPO that has not listed
+func (j *GMPUpdateJob) Refresh() (services.GMPSaveResult, error) {
+	ctx, done := DefaultJobRuns.Start(context.Background(), GMPUpdateJobName, gmpUpdateDeadline)
+	defer done()
+	return j.refresh(ctx, true)
+}
+
+// RefreshDue fetches GMP data only if some IPO is due by the schedule, and saves only the
+// due IPOs and ones not tracked yet. It returns the number of records written and of
+// records left unchanged.
+func (j *GMPUpdateJob) RefreshDue(ctx context.Context) (RefreshCount, error) {
+	saved, err := j.refresh(ctx, false)
+	return RefreshCount{Items: saved.Written, Unchanged: saved.Unchanged}, err
+}
+
+func (j *GMPUpdateJob) refresh(ctx context.Context, force bool) (services.GMPSaveResult, error) {
+	var saved services.GMPSaveResult
+	tracking, err := j.Schedule.Tracking(ctx)
+	if err != nil {
+		return saved, err
+	}
+	if !force && !j.Schedule.AnyDue(tracking) {
+		logrus.Debug("No IPO GMP due for refresh")
+		return saved, nil
+	}
+
+	gmpData, err := j.Scraper.FetchGMPData()
+	if err != nil {
+		services.DefaultAlerter.Evaluate(services.AlertGMPParse, "", 0, map[string]interface{}{"error": err.Error()})
+		return saved, fmt.Errorf("error fetching GMP data: %w", err)
+	}
+
 	if len(gmpData) == 0 {
-		logrus.Warn("GMP Update Job: no GMP data fetched from source")
-		return
+		services.DefaultAlerter.Evaluate(services.AlertGMPParse, "", 0, map[string]interface{}{"rows": 0})
+		return saved, fmt.Errorf("no GMP data fetched from source")
 	}
 
-	duration := time.Since(startTime)
-	logrus.Infof("GMP Update Job completed successfully: processed %d GMP records (took %v)",
-		len(gmpData), duration)
+	services.DefaultAlerter.Evaluate(services.AlertGMPParse, "", services.GMPParseSuccessRate(gmpData),
+		map[string]interface{}{"rows": len(gmpData)})
+
+	selected := j.Schedule.Select(tracking, gmpData, force)
+	if len(selected) == 0 {
+		return saved, nil
+	}
+	// The scraper takes no context; do not save after the run's deadline has passed
+	if err := ctx.Err(); err != nil {
+		return saved, fmt.Errorf("GMP refresh stopped before saving: %w", err)
+	}
+	saved, err = j.SimpleGMPService.SaveGMPData(selected)
+	if err != nil {
+		return saved, fmt.Errorf("error saving GMP data: %w", err)
+	}
+
+	// Drop cached API responses so clients see the new GMP values
+	if saved.Written > 0 {
+		j.ResponseCache.Invalidate()
+	}
+	return saved, nil
 }
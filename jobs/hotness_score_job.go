@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// HotnessScoreJobName is the lock name of the hotness score job
+const HotnessScoreJobName = "hotness_score"
+
+type HotnessScoreJob struct {
+	HotnessService *services.HotnessService
+}
+
+func NewHotnessScoreJob(hotnessService *services.HotnessService) *HotnessScoreJob {
+	return &HotnessScoreJob{HotnessService: hotnessService}
+}
+
+func (j *HotnessScoreJob) Run() {
+	logrus.Info("Starting Hotness Score Job")
+	ctx, done := DefaultJobRuns.Start(context.Background(), HotnessScoreJobName, 5*time.Minute)
+	defer done()
+
+	scored, err := j.HotnessService.ComputeScores(ctx)
+	if err != nil {
+		logrus.Errorf("Hotness Score Job failed: %v", err)
+		return
+	}
+
+	logrus.Infof("Hotness Score Job completed: scored %d IPOs", scored)
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// IssueSizeBackfillJobName is the lock name of the issue size backfill job
+const IssueSizeBackfillJobName = "issue_size_backfill"
+
+// IssueSizeBackfillJob fills issue_size_amount for IPOs stored before the column existed
+type IssueSizeBackfillJob struct {
+	IPOService *services.IPOService
+}
+
+func NewIssueSizeBackfillJob(ipoService *services.IPOService) *IssueSizeBackfillJob {
+	return &IssueSizeBackfillJob{IPOService: ipoService}
+}
+
+func (j *IssueSizeBackfillJob) Run() {
+	logrus.Info("Starting Issue Size Backfill Job")
+	ctx, done := DefaultJobRuns.Start(context.Background(), IssueSizeBackfillJobName, 5*time.Minute)
+	defer done()
+
+	filled, err := j.IPOService.BackfillIssueSizeAmounts(ctx)
+	if err != nil {
+		logrus.Errorf("Issue Size Backfill Job failed: %v", err)
+		return
+	}
+
+	logrus.Infof("Issue Size Backfill Job completed: filled %d IPOs", filled)
+}
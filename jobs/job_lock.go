@@ -0,0 +1,348 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jobLockNamespace is the first key of the two-key advisory lock form so job
+// locks never collide with other advisory lock users of the same database
+const jobLockNamespace int32 = 0x4a4f4253 // "JOBS"
+
+// Outcomes of RunLocked
+const (
+	LockOutcomeRan    = "ran"
+	LockOutcomeLocked = "locked" // another replica held the lock, or it could not be taken
+	// The job already completed its current period, on this or another replica
+	LockOutcomeDone = "done"
+)
+
+// JobLockStats tracks lock activity for a single job
+type JobLockStats struct {
+	JobName       string     `json:"job_name"`
+	Acquired      int64      `json:"acquired"`
+	Skipped       int64      `json:"skipped"`
+	PeriodDone    int64      `json:"period_done"`
+	Errors        int64      `json:"errors"`
+	Lost          int64      `json:"lost"`
+	Held          bool       `json:"held"`
+	LastAcquired  *time.Time `json:"last_acquired,omitempty"`
+	LastReleased  *time.Time `json:"last_released,omitempty"`
+	LastLost      *time.Time `json:"last_lost,omitempty"`
+	LastHoldTime  string     `json:"last_hold_time,omitempty"`
+	LastSkippedAt *time.Time `json:"last_skipped_at,omitempty"`
+	// Start of the last period this replica found already completed
+	LastPeriodDone *time.Time `json:"last_period_done,omitempty"`
+}
+
+// JobLocker ensures scheduled jobs run on exactly one replica using
+// Postgres session-level advisory locks held on a dedicated connection.
+// Jobs with a period also record each completed period in job_run_leases, so
+// a replica whose ticker fires after another replica finished the period skips
+// the run instead of repeating it.
+type JobLocker struct {
+	DB                *sql.DB
+	InstanceID        string
+	HeartbeatInterval time.Duration
+	// Periods maps job names to the length of their run periods. Periods are
+	// aligned to the Unix epoch, so every replica agrees on their boundaries.
+	Periods map[string]time.Duration
+
+	mutex sync.Mutex
+	stats map[string]*JobLockStats
+}
+
+// NewJobLocker creates a new job locker backed by the given database
+func NewJobLocker(db *sql.DB) *JobLocker {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+
+	return &JobLocker{
+		DB:                db,
+		InstanceID:        fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		HeartbeatInterval: 30 * time.Second,
+		stats:             make(map[string]*JobLockStats),
+	}
+}
+
+// jobLockKey derives a stable advisory lock key from the job name
+func jobLockKey(jobName string) int32 {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(jobName))
+	return int32(hasher.Sum32())
+}
+
+// JobPeriodStart returns the start of the period of length period containing at
+func JobPeriodStart(at time.Time, period time.Duration) time.Time {
+	return at.UTC().Truncate(period)
+}
+
+// RunExclusive runs job only if this replica acquires the lock for jobName.
+// It returns true when the job ran and false when another replica holds the lock
+// or the job's current period has already completed.
+func (l *JobLocker) RunExclusive(jobName string, job func()) bool {
+	return l.RunLocked(jobName, job) == LockOutcomeRan
+}
+
+// RunLocked runs job if this replica acquires the lock for jobName and, for jobs
+// with a period, no replica has completed the current period yet. It returns
+// LockOutcomeRan, LockOutcomeLocked or LockOutcomeDone.
+func (l *JobLocker) RunLocked(jobName string, job func()) string {
+	if l == nil || l.DB == nil {
+		job()
+		return LockOutcomeRan
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "job_lock",
+		"job":       jobName,
+		"instance":  l.InstanceID,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		l.recordError(jobName)
+		logger.WithError(err).Error("Failed to obtain connection for job lock, skipping run")
+		return LockOutcomeLocked
+	}
+	defer conn.Close()
+
+	key := jobLockKey(jobName)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", jobLockNamespace, key).Scan(&acquired); err != nil {
+		l.recordError(jobName)
+		logger.WithError(err).Error("Failed to acquire job lock, skipping run")
+		return LockOutcomeLocked
+	}
+
+	if !acquired {
+		l.recordSkipped(jobName)
+		logger.Info("Job lock held by another replica, skipping run")
+		return LockOutcomeLocked
+	}
+
+	period, hasPeriod := l.Periods[jobName]
+	hasPeriod = hasPeriod && period > 0
+	var periodStart time.Time
+	if hasPeriod {
+		periodStart = JobPeriodStart(time.Now(), period)
+		var done bool
+		err := conn.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM job_run_leases WHERE job_name = $1 AND period_start >= $2
+			)
+		`, jobName, periodStart).Scan(&done)
+		if err != nil {
+			l.unlock(conn, key, logger)
+			l.recordError(jobName)
+			logger.WithError(err).Error("Failed to read job run lease, skipping run")
+			return LockOutcomeLocked
+		}
+		if done {
+			l.unlock(conn, key, logger)
+			l.recordPeriodDone(jobName, periodStart)
+			logger.WithField("period_start", periodStart.Format(time.RFC3339)).Info("Job period already completed, skipping run")
+			return LockOutcomeDone
+		}
+	}
+
+	acquiredAt := time.Now()
+	l.recordAcquired(jobName, acquiredAt)
+	logger.Debug("Job lock acquired")
+
+	// Heartbeat verifies the lock is still held by our session; a dropped
+	// connection silently releases session locks, letting another replica in
+	lockLost := false
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		lockLost = l.heartbeat(ctx, conn, jobName, key, logger)
+	}()
+
+	job()
+
+	cancel()
+	<-heartbeatDone
+
+	if hasPeriod {
+		l.completePeriod(conn, jobName, periodStart, period, logger)
+	}
+
+	released := l.unlock(conn, key, logger)
+	if !released && !lockLost {
+		l.recordLost(jobName)
+		logger.Warn("Job lock was no longer held at release time")
+	}
+
+	l.recordReleased(jobName, time.Since(acquiredAt))
+	return LockOutcomeRan
+}
+
+// completePeriod records that jobName completed the period starting at
+// periodStart. The lease expires at the end of the period.
+func (l *JobLocker) completePeriod(conn *sql.Conn, jobName string, periodStart time.Time, period time.Duration, logger *logrus.Entry) {
+	// Use a fresh context since the run context has been cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := conn.ExecContext(ctx, `
+		INSERT INTO job_run_leases (job_name, period_start, expires_at, completed_at, instance_id)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, $4)
+		ON CONFLICT (job_name) DO UPDATE SET
+			period_start = EXCLUDED.period_start,
+			expires_at = EXCLUDED.expires_at,
+			completed_at = EXCLUDED.completed_at,
+			instance_id = EXCLUDED.instance_id
+	`, jobName, periodStart, periodStart.Add(period), l.InstanceID)
+	if err != nil {
+		l.recordError(jobName)
+		logger.WithError(err).Warn("Failed to record job run lease; another replica may repeat this period")
+	}
+}
+
+// unlock releases the advisory lock for key and reports whether it was still held
+func (l *JobLocker) unlock(conn *sql.Conn, key int32, logger *logrus.Entry) bool {
+	// Use a fresh context since the run context may have been cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var released bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1, $2)", jobLockNamespace, key).Scan(&released); err != nil {
+		logger.WithError(err).Warn("Failed to release job lock")
+		return true
+	}
+	return released
+}
+
+// heartbeat periodically checks that the advisory lock is still owned by conn
+// and reports whether the lock was lost during the run
+func (l *JobLocker) heartbeat(ctx context.Context, conn *sql.Conn, jobName string, key int32, logger *logrus.Entry) bool {
+	interval := l.HeartbeatInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory'
+			  AND classid = $1::oid
+			  AND objid = $2::oid
+			  AND objsubid = 2
+			  AND pid = pg_backend_pid()
+			  AND granted
+		)
+	`
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			var held bool
+			// pg_locks exposes the keys as unsigned oids
+			err := conn.QueryRowContext(ctx, query, uint32(jobLockNamespace), uint32(key)).Scan(&held)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				l.recordLost(jobName)
+				logger.WithError(err).Error("Job lock heartbeat failed; lock may have been lost")
+				return true
+			}
+			if !held {
+				l.recordLost(jobName)
+				logger.Error("Job lock stolen: advisory lock no longer held by this session")
+				return true
+			}
+		}
+	}
+}
+
+// GetStats returns a snapshot of lock statistics for all jobs
+func (l *JobLocker) GetStats() []JobLockStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	result := make([]JobLockStats, 0, len(l.stats))
+	for _, stat := range l.stats {
+		result = append(result, *stat)
+	}
+	return result
+}
+
+func (l *JobLocker) getStats(jobName string) *JobLockStats {
+	stat, exists := l.stats[jobName]
+	if !exists {
+		stat = &JobLockStats{JobName: jobName}
+		l.stats[jobName] = stat
+	}
+	return stat
+}
+
+func (l *JobLocker) recordAcquired(jobName string, at time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	stat := l.getStats(jobName)
+	stat.Acquired++
+	stat.Held = true
+	stat.LastAcquired = &at
+}
+
+func (l *JobLocker) recordReleased(jobName string, holdTime time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	stat := l.getStats(jobName)
+	stat.Held = false
+	stat.LastReleased = &now
+	stat.LastHoldTime = holdTime.String()
+}
+
+func (l *JobLocker) recordSkipped(jobName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	stat := l.getStats(jobName)
+	stat.Skipped++
+	stat.LastSkippedAt = &now
+}
+
+func (l *JobLocker) recordPeriodDone(jobName string, periodStart time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	stat := l.getStats(jobName)
+	stat.PeriodDone++
+	stat.LastPeriodDone = &periodStart
+}
+
+func (l *JobLocker) recordLost(jobName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	stat := l.getStats(jobName)
+	stat.Lost++
+	stat.LastLost = &now
+}
+
+func (l *JobLocker) recordError(jobName string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.getStats(jobName).Errors++
+}
@@ -0,0 +1,215 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultJobDeadline bounds runs of jobs that have no deadline of their own
+	DefaultJobDeadline = 30 * time.Minute
+	// DefaultJobOverrunGrace is how long a run may outlive its deadline before it is
+	// reported as leaked
+	DefaultJobOverrunGrace = time.Minute
+)
+
+// DefaultJobRuns tracks the runs of every background job in the process
+var DefaultJobRuns = NewJobRuns()
+
+// JobRuns bounds each job run by a deadline and tracks the runs in flight, so a job stuck
+// on a hung registrar or database call ends with its context instead of running forever.
+// A run still going OverrunGrace after its deadline ignores its context; it is logged and
+// counted so the leaked goroutine shows up. Safe for concurrent use.
+type JobRuns struct {
+	// Deadlines overrides the deadline of runs by job name
+	Deadlines map[string]time.Duration
+	// DefaultDeadline bounds runs of jobs with neither an override nor a deadline of
+	// their own
+	DefaultDeadline time.Duration
+	// OverrunGrace is how long a run may take to wind down after its deadline
+	OverrunGrace time.Duration
+
+	mutex    sync.Mutex
+	nextID   int64
+	active   map[int64]*jobRun
+	started  int64
+	timedOut int64
+	overran  int64
+}
+
+type jobRun struct {
+	job       string
+	startedAt time.Time
+	deadline  time.Time
+	overran   bool
+}
+
+// ActiveJobRun is a job run in flight
+type ActiveJobRun struct {
+	Job       string    `json:"job"`
+	StartedAt time.Time `json:"started_at"`
+	Deadline  time.Time `json:"deadline"`
+	// Overdue is set once the run is past its deadline
+	Overdue bool `json:"overdue,omitempty"`
+	// Overran is set once the run is past its deadline and grace, likely a leaked goroutine
+	Overran bool `json:"overran,omitempty"`
+}
+
+// JobRunStats is the in-flight gauge and run counters of a JobRuns
+type JobRunStats struct {
+	InFlight      int            `json:"in_flight"`
+	InFlightByJob map[string]int `json:"in_flight_by_job"`
+	Overdue       int            `json:"overdue"`
+	Started       int64          `json:"started"`
+	TimedOut      int64          `json:"timed_out"`
+	Overran       int64          `json:"overran"`
+	Active        []ActiveJobRun `json:"active"`
+	// Deadlines are the configured per-job overrides
+	Deadlines       map[string]string `json:"deadlines,omitempty"`
+	DefaultDeadline string            `json:"default_deadline"`
+}
+
+func NewJobRuns() *JobRuns {
+	return &JobRuns{
+		Deadlines:       make(map[string]time.Duration),
+		DefaultDeadline: DefaultJobDeadline,
+		OverrunGrace:    DefaultJobOverrunGrace,
+		active:          make(map[int64]*jobRun),
+	}
+}
+
+// Deadline returns how long a run of jobName may take: its configured override, else
+// fallback, the job's own deadline, else DefaultDeadline
+func (r *JobRuns) Deadline(jobName string, fallback time.Duration) time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if deadline, ok := r.Deadlines[jobName]; ok && deadline > 0 {
+		return deadline
+	}
+	if fallback > 0 {
+		return fallback
+	}
+	if r.DefaultDeadline > 0 {
+		return r.DefaultDeadline
+	}
+	return DefaultJobDeadline
+}
+
+// Start registers a run of jobName and returns its context, which ends at the run's
+// deadline (see Deadline) or when parent ends. The caller must call done when the run
+// returns; done cancels the context and counts the run as timed out if its deadline
+// passed.
+func (r *JobRuns) Start(parent context.Context, jobName string, fallback time.Duration) (context.Context, func()) {
+	deadline := r.Deadline(jobName, fallback)
+	ctx, cancel := context.WithTimeout(parent, deadline)
+	run := &jobRun{job: jobName, startedAt: time.Now()}
+	run.deadline, _ = ctx.Deadline()
+
+	r.mutex.Lock()
+	r.nextID++
+	id := r.nextID
+	r.active[id] = run
+	r.started++
+	grace := r.OverrunGrace
+	r.mutex.Unlock()
+
+	watchdog := time.AfterFunc(time.Until(run.deadline)+grace, func() { r.overrun(id) })
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			watchdog.Stop()
+			timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+			cancel()
+
+			r.mutex.Lock()
+			delete(r.active, id)
+			if timedOut {
+				r.timedOut++
+			}
+			r.mutex.Unlock()
+
+			if timedOut {
+				logrus.WithFields(logrus.Fields{
+					"job":      jobName,
+					"deadline": deadline.String(),
+					"took":     time.Since(run.startedAt).Round(time.Millisecond).String(),
+				}).Warn("Job run hit its deadline")
+			}
+		})
+	}
+	return ctx, done
+}
+
+// overrun flags a run still going after its deadline and grace
+func (r *JobRuns) overrun(id int64) {
+	r.mutex.Lock()
+	run, ok := r.active[id]
+	if ok {
+		run.overran = true
+		r.overran++
+	}
+	r.mutex.Unlock()
+
+	if ok {
+		logrus.WithFields(logrus.Fields{
+			"job":        run.job,
+			"started_at": run.startedAt.Format(time.RFC3339),
+			"deadline":   run.deadline.Format(time.RFC3339),
+		}).Error("Job run is still going after its deadline; a call is ignoring its context")
+	}
+}
+
+// InFlight returns the number of job runs in flight
+func (r *JobRuns) InFlight() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.active)
+}
+
+// Snapshot returns the runs in flight, oldest first, with the run counters
+func (r *JobRuns) Snapshot() JobRunStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	stats := JobRunStats{
+		InFlight:        len(r.active),
+		InFlightByJob:   make(map[string]int),
+		Started:         r.started,
+		TimedOut:        r.timedOut,
+		Overran:         r.overran,
+		Active:          make([]ActiveJobRun, 0, len(r.active)),
+		DefaultDeadline: r.DefaultDeadline.String(),
+	}
+	for _, run := range r.active {
+		overdue := now.After(run.deadline)
+		if overdue {
+			stats.Overdue++
+		}
+		stats.InFlightByJob[run.job]++
+		stats.Active = append(stats.Active, ActiveJobRun{
+			Job:       run.job,
+			StartedAt: run.startedAt,
+			Deadline:  run.deadline,
+			Overdue:   overdue,
+			Overran:   run.overran,
+		})
+	}
+	sort.Slice(stats.Active, func(i, j int) bool {
+		return stats.Active[i].StartedAt.Before(stats.Active[j].StartedAt)
+	})
+	if len(r.Deadlines) > 0 {
+		stats.Deadlines = make(map[string]string, len(r.Deadlines))
+		for job, deadline := range r.Deadlines {
+			stats.Deadlines[job] = deadline.String()
+		}
+	}
+	return stats
+}
@@ -0,0 +1,314 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// What a scheduled run does when a dependency has no recent enough success
+const (
+	DependencySkip  = "skip"  // drop the run; the next tick tries again
+	DependencyQueue = "queue" // hold the run until the dependency next succeeds
+)
+
+// Outcomes of a scheduled run, reported by the jobs admin API
+const (
+	JobOutcomeRan     = "ran"
+	JobOutcomeSkipped = "skipped" // a dependency was stale and the run was dropped
+	JobOutcomeQueued  = "queued"  // a dependency was stale and the run is waiting for it
+	JobOutcomeLocked  = "locked"  // another replica held the job's lock
+	JobOutcomeDone    = "done"    // the job's current period already completed
+	// A politeness window was open and the run waits for it to end
+	JobOutcomeDeferred = "deferred"
+)
+
+// JobDependency declares that Job only runs after DependsOn succeeded within MaxAge.
+// Jobs without a declaration run on every tick.
+type JobDependency struct {
+	Job       string
+	DependsOn string
+	MaxAge    time.Duration
+	OnUnmet   string // DependencySkip or DependencyQueue
+}
+
+// JobDependencyStatus is a dependency declaration with its current state
+type JobDependencyStatus struct {
+	Job                   string     `json:"job"`
+	DependsOn             string     `json:"depends_on"`
+	MaxAge                string     `json:"max_age"`
+	OnUnmet               string     `json:"on_unmet"`
+	DependencyLastSuccess *time.Time `json:"dependency_last_success,omitempty"`
+	Satisfied             bool       `json:"satisfied"`
+	Error                 string     `json:"error,omitempty"`
+}
+
+// ScheduledJobStatus is the last scheduling decision for one job on this instance
+type ScheduledJobStatus struct {
+	Job         string     `json:"job"`
+	LastOutcome string     `json:"last_outcome,omitempty"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastBlocked *time.Time `json:"last_blocked_at,omitempty"`
+	BlockedBy   string     `json:"blocked_by,omitempty"`
+	QueuedSince *time.Time `json:"queued_since,omitempty"`
+	// DeferredUntil is when the run held back by a politeness window starts
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+	Runs          int64      `json:"runs"`
+	Skipped       int64      `json:"skipped"`
+	Queued        int64      `json:"queued"`
+	Deferred      int64      `json:"deferred"`
+}
+
+// LastSuccessFunc reports when a job last succeeded on any replica, or nil if never
+type LastSuccessFunc func(ctx context.Context, jobName string) (*time.Time, error)
+
+// JobScheduler runs scheduled jobs under their locks, holding back jobs whose
+// dependencies have not succeeded recently. Success is read from the shared run
+// reports, so a dependency that ran on another replica counts. Scraping jobs are also
+// held back while a skip politeness window is open.
+type JobScheduler struct {
+	Locker       *JobLocker
+	LastSuccess  LastSuccessFunc
+	Dependencies []JobDependency
+	// Politeness, when set, defers runs of ScrapeJobs in its skip windows to the window end
+	Politeness *shared.PolitenessSchedule
+	// ScrapeJobs are the jobs that scrape external sources
+	ScrapeJobs []string
+	// Clock is the time dependency ages and run times are measured against
+	Clock shared.Clock
+
+	mutex  sync.Mutex
+	status map[string]*ScheduledJobStatus
+	queued map[string]func()
+}
+
+func NewJobScheduler(locker *JobLocker, lastSuccess LastSuccessFunc, dependencies ...JobDependency) *JobScheduler {
+	return &JobScheduler{
+		Locker:       locker,
+		LastSuccess:  lastSuccess,
+		Dependencies: dependencies,
+		Clock:        shared.DefaultClock,
+		status:       make(map[string]*ScheduledJobStatus),
+		queued:       make(map[string]func()),
+	}
+}
+
+// Run runs job under the lock for jobName once its dependencies are met, then runs any
+// queued jobs this run unblocked. It returns the run's outcome.
+func (s *JobScheduler) Run(jobName string, job func()) string {
+	outcome := s.run(jobName, job)
+	if outcome == JobOutcomeRan {
+		s.releaseQueued()
+	}
+	return outcome
+}
+
+func (s *JobScheduler) run(jobName string, job func()) string {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "job_scheduler",
+		"job":       jobName,
+	})
+
+	if until, ok := s.politeUntil(jobName); ok {
+		s.recordDeferred(jobName, until, job)
+		logger.WithField("until", until.Format(time.RFC3339)).Info("Politeness window is open, deferring run")
+		return JobOutcomeDeferred
+	}
+
+	if unmet, ok := s.unmetDependency(jobName); !ok {
+		if unmet.OnUnmet == DependencyQueue {
+			s.recordBlocked(jobName, unmet.DependsOn, JobOutcomeQueued, job)
+			logger.WithField("depends_on", unmet.DependsOn).Info("Dependency has no recent success, queueing run")
+			return JobOutcomeQueued
+		}
+		s.recordBlocked(jobName, unmet.DependsOn, JobOutcomeSkipped, nil)
+		logger.WithField("depends_on", unmet.DependsOn).Info("Dependency has no recent success, skipping run")
+		return JobOutcomeSkipped
+	}
+
+	switch s.Locker.RunLocked(jobName, job) {
+	case LockOutcomeRan:
+		s.recordOutcome(jobName, JobOutcomeRan)
+		return JobOutcomeRan
+	case LockOutcomeDone:
+		s.recordOutcome(jobName, JobOutcomeDone)
+		return JobOutcomeDone
+	default:
+		s.recordOutcome(jobName, JobOutcomeLocked)
+		return JobOutcomeLocked
+	}
+}
+
+// politeUntil reports whether jobName scrapes sources and a skip politeness window is
+// open, and until when
+func (s *JobScheduler) politeUntil(jobName string) (time.Time, bool) {
+	for _, name := range s.ScrapeJobs {
+		if name == jobName {
+			return s.Politeness.SkipUntil()
+		}
+	}
+	return time.Time{}, false
+}
+
+// releaseQueued runs the queued jobs whose dependencies are now met
+func (s *JobScheduler) releaseQueued() {
+	s.mutex.Lock()
+	names := make([]string, 0, len(s.queued))
+	for name := range s.queued {
+		names = append(names, name)
+	}
+	s.mutex.Unlock()
+
+	for _, name := range names {
+		if _, ok := s.unmetDependency(name); !ok {
+			continue
+		}
+		s.mutex.Lock()
+		job, queued := s.queued[name]
+		delete(s.queued, name)
+		s.mutex.Unlock()
+		if queued {
+			logrus.WithField("job", name).Info("Dependencies met, running queued job")
+			s.Run(name, job)
+		}
+	}
+}
+
+// unmetDependency returns the first dependency of jobName without a recent enough
+// success; ok is true when every dependency is met
+func (s *JobScheduler) unmetDependency(jobName string) (JobDependency, bool) {
+	for _, dependency := range s.Dependencies {
+		if dependency.Job != jobName {
+			continue
+		}
+		if status := s.checkDependency(context.Background(), dependency); !status.Satisfied {
+			return dependency, false
+		}
+	}
+	return JobDependency{}, true
+}
+
+// checkDependency reads the dependency's last success. A failed lookup counts as unmet,
+// so a job never runs on data it cannot vouch for.
+func (s *JobScheduler) checkDependency(ctx context.Context, dependency JobDependency) JobDependencyStatus {
+	status := JobDependencyStatus{
+		Job:       dependency.Job,
+		DependsOn: dependency.DependsOn,
+		MaxAge:    dependency.MaxAge.String(),
+		OnUnmet:   dependency.OnUnmet,
+	}
+	if s.LastSuccess == nil {
+		status.Error = "no run history available"
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	lastSuccess, err := s.LastSuccess(ctx, dependency.DependsOn)
+	if err != nil {
+		logrus.WithError(err).WithField("depends_on", dependency.DependsOn).Warn("Failed to check job dependency")
+		status.Error = err.Error()
+		return status
+	}
+	status.DependencyLastSuccess = lastSuccess
+	status.Satisfied = lastSuccess != nil && s.now().Sub(*lastSuccess) <= dependency.MaxAge
+	return status
+}
+
+// DependencyStatus returns every declared dependency with its current state
+func (s *JobScheduler) DependencyStatus(ctx context.Context) []JobDependencyStatus {
+	result := make([]JobDependencyStatus, 0, len(s.Dependencies))
+	for _, dependency := range s.Dependencies {
+		result = append(result, s.checkDependency(ctx, dependency))
+	}
+	return result
+}
+
+// JobStatus returns the last scheduling decision of every job run through the scheduler
+func (s *JobScheduler) JobStatus() []ScheduledJobStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]ScheduledJobStatus, 0, len(s.status))
+	for _, status := range s.status {
+		result = append(result, *status)
+	}
+	return result
+}
+
+func (s *JobScheduler) now() time.Time {
+	return shared.ClockOrDefault(s.Clock).Now()
+}
+
+func (s *JobScheduler) getStatus(jobName string) *ScheduledJobStatus {
+	status, exists := s.status[jobName]
+	if !exists {
+		status = &ScheduledJobStatus{Job: jobName}
+		s.status[jobName] = status
+	}
+	return status
+}
+
+// recordOutcome records a run that got past its dependencies: it ran, another
+// replica held the lock, or the job's period had already completed
+func (s *JobScheduler) recordOutcome(jobName, outcome string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// The run supersedes any run still queued for the job
+	delete(s.queued, jobName)
+	status := s.getStatus(jobName)
+	status.LastOutcome = outcome
+	status.QueuedSince = nil
+	if outcome == JobOutcomeRan {
+		now := s.now()
+		status.Runs++
+		status.LastRunAt = &now
+		status.BlockedBy = ""
+	}
+}
+
+// recordBlocked records a run held back by dependsOn; a queued job replaces any run
+// already waiting, so a job is queued at most once
+func (s *JobScheduler) recordBlocked(jobName, dependsOn, outcome string, job func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.now()
+	status := s.getStatus(jobName)
+	status.LastOutcome = outcome
+	status.LastBlocked = &now
+	status.BlockedBy = dependsOn
+	if outcome == JobOutcomeQueued {
+		status.Queued++
+		if status.QueuedSince == nil {
+			status.QueuedSince = &now
+		}
+		s.queued[jobName] = job
+		return
+	}
+	status.Skipped++
+}
+
+// recordDeferred records a run held back by a politeness window and runs the job once
+// the window ends. Runs deferred while one is already waiting are dropped, so a job is
+// deferred at most once.
+func (s *JobScheduler) recordDeferred(jobName string, until time.Time, job func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status := s.getStatus(jobName)
+	status.LastOutcome = JobOutcomeDeferred
+	status.Deferred++
+	if status.DeferredUntil != nil {
+		return
+	}
+	status.DeferredUntil = &until
+	time.AfterFunc(until.Sub(s.now()), func() {
+		s.mutex.Lock()
+		s.getStatus(jobName).DeferredUntil = nil
+		s.mutex.Unlock()
+		logrus.WithField("job", jobName).Info("Politeness window ended, running deferred job")
+		s.Run(jobName, job)
+	})
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// MandateReminderJobName is the lock name of the UPI mandate reminder job
+const MandateReminderJobName = "mandate_reminder"
+
+// MandateReminderJob sends UPI mandate reminders for IPOs closing today once their
+// reminder window opens, checking every Interval
+type MandateReminderJob struct {
+	Reminders *services.MandateReminderService
+	Locker    *JobLocker
+	Interval  time.Duration
+}
+
+func NewMandateReminderJob(reminders *services.MandateReminderService, locker *JobLocker) *MandateReminderJob {
+	return &MandateReminderJob{Reminders: reminders, Locker: locker, Interval: 10 * time.Minute}
+}
+
+// Start runs the job now and then every Interval in the background
+func (j *MandateReminderJob) Start() {
+	logrus.Infof("Starting Mandate Reminder Job (runs every %v)...", j.Interval)
+	ticker := time.NewTicker(j.Interval)
+
+	go func() {
+		j.Locker.RunExclusive(MandateReminderJobName, j.Run)
+		for range ticker.C {
+			j.Locker.RunExclusive(MandateReminderJobName, j.Run)
+		}
+	}()
+}
+
+func (j *MandateReminderJob) Run() {
+	ctx, done := DefaultJobRuns.Start(context.Background(), MandateReminderJobName, j.Interval)
+	defer done()
+
+	sent, err := j.Reminders.RunDue(ctx)
+	if err != nil {
+		logrus.Errorf("Mandate Reminder Job failed: %v", err)
+		return
+	}
+	if sent > 0 {
+		logrus.Infof("Mandate Reminder Job completed: sent %d reminders", sent)
+	}
+}
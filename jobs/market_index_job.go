@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// MarketIndexRefreshName is the lock name of the market index task of the refresh orchestrator
+const MarketIndexRefreshName = "market_index_refresh"
+
+// MarketIndexJobName is the run name of the market index poll during trading hours
+const MarketIndexJobName = "market_index"
+
+// MarketIndexJob polls market index values into the in-memory sparkline series. Each
+// replica serves its own series, so the intraday poll runs on every replica without a lock.
+type MarketIndexJob struct {
+	Indices  *services.MarketIndexService
+	Interval time.Duration
+	// Clock decides whether the market is open on each tick
+	Clock shared.Clock
+}
+
+func NewMarketIndexJob(indices *services.MarketIndexService) *MarketIndexJob {
+	return &MarketIndexJob{Indices: indices, Interval: 5 * time.Minute, Clock: shared.DefaultClock}
+}
+
+// Start polls every Interval while the market is open. The startup and hourly polls are
+// a task of the refresh orchestrator.
+func (j *MarketIndexJob) Start() {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if j.Indices.MarketOpen(shared.ClockOrDefault(j.Clock).Now()) {
+				j.Run()
+			}
+		}
+	}()
+}
+
+func (j *MarketIndexJob) Run() {
+	ctx, done := DefaultJobRuns.Start(context.Background(), MarketIndexJobName, time.Minute)
+	defer done()
+
+	if _, err := j.Indices.Poll(ctx); err != nil {
+		logrus.Errorf("Market Index Job failed: %v", err)
+		return
+	}
+	logrus.Debug("Market Index Job completed")
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// PreOpenPriceJobName is the lock name of the listing-morning pre-open price poll
+const PreOpenPriceJobName = "pre_open_price"
+
+// PreOpenPriceJob polls the exchange pre-open auction of IPOs listing today. It sleeps
+// until each morning's pre-open window and, when an IPO lists that day, polls every
+// Interval until the window closes.
+type PreOpenPriceJob struct {
+	PreOpen       *services.PreOpenService
+	Locker        *JobLocker
+	ResponseCache *middleware.ResponseCache
+	Interval      time.Duration
+	// Clock decides which day's listings and pre-open window the job works on
+	Clock shared.Clock
+}
+
+func NewPreOpenPriceJob(preOpen *services.PreOpenService, locker *JobLocker) *PreOpenPriceJob {
+	return &PreOpenPriceJob{PreOpen: preOpen, Locker: locker, Interval: time.Minute, Clock: shared.DefaultClock}
+}
+
+// Start runs the daily window loop in the background
+func (j *PreOpenPriceJob) Start() {
+	go func() {
+		for {
+			start, end := j.PreOpen.NextWindow(j.now())
+			if wait := start.Sub(j.now()); wait > 0 {
+				time.Sleep(wait)
+			}
+			j.runWindow(end)
+		}
+	}()
+}
+
+// runWindow polls until end if any IPO lists today
+func (j *PreOpenPriceJob) runWindow(end time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ipos, err := j.PreOpen.ListingToday(ctx, j.now())
+	cancel()
+	if err != nil {
+		logrus.Errorf("Pre-open Price Job failed to load listing IPOs: %v", err)
+	}
+	if len(ipos) == 0 {
+		time.Sleep(end.Sub(j.now()))
+		return
+	}
+
+	logrus.Infof("Pre-open Price Job polling %d listing IPOs until %s", len(ipos), end.Format(time.RFC3339))
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		j.Locker.RunExclusive(PreOpenPriceJobName, j.Run)
+		if !j.now().Add(j.Interval).Before(end) {
+			return
+		}
+		<-ticker.C
+	}
+}
+
+func (j *PreOpenPriceJob) now() time.Time {
+	return shared.ClockOrDefault(j.Clock).Now()
+}
+
+func (j *PreOpenPriceJob) Run() {
+	ctx, done := DefaultJobRuns.Start(context.Background(), PreOpenPriceJobName, j.Interval)
+	defer done()
+
+	ipos, err := j.PreOpen.ListingToday(ctx, j.now())
+	if err != nil {
+		logrus.Errorf("Pre-open Price Job failed to load listing IPOs: %v", err)
+		return
+	}
+
+	updated := 0
+	for i := range ipos {
+		price, err := j.PreOpen.Refresh(ctx, &ipos[i])
+		if err != nil {
+			logrus.WithError(err).WithField("ipo", ipos[i].Name).Warn("Failed to refresh pre-open price")
+			continue
+		}
+		updated++
+		logrus.WithFields(logrus.Fields{
+			"ipo":   ipos[i].Name,
+			"price": price.Price,
+		}).Debug("Recorded pre-open price")
+	}
+
+	if updated > 0 && j.ResponseCache != nil {
+		j.ResponseCache.InvalidatePrefix("/api/v1/ipos")
+	}
+	logrus.Infof("Pre-open Price Job completed: updated %d of %d listing IPOs", updated, len(ipos))
+}
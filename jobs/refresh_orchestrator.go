@@ -0,0 +1,279 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// RefreshOrchestratorName is the job name of the orchestrated refresh's run reports
+const RefreshOrchestratorName = "refresh"
+
+// RefreshTask is one update run by the RefreshOrchestrator. Run returns the number of
+// items it updated. LockName, when set, is taken for the task so it never overlaps
+// a run of the same job on another replica or from an admin trigger.
+type RefreshTask struct {
+	Name     string
+	LockName string
+	Run      func(ctx context.Context) (int, error)
+	// RunCounted, when set, runs instead of Run for tasks that also skip unchanged items
+	RunCounted func(ctx context.Context) (RefreshCount, error)
+	// LastObserved, when set, returns when the task's series last got an observation, or
+	// nil if never; CatchUp uses it to skip series that are still fresh
+	LastObserved func(ctx context.Context) (*time.Time, error)
+}
+
+// RefreshCount is what a RefreshTask.RunCounted did: items written and items it found
+// unchanged and left alone
+type RefreshCount struct {
+	Items     int
+	Unchanged int
+}
+
+// RefreshTaskResult is the outcome of one task in an orchestrated refresh
+type RefreshTaskResult struct {
+	Name       string `json:"name"`
+	Items      int    `json:"items"`
+	Unchanged  int    `json:"unchanged,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Skipped    bool   `json:"skipped,omitempty"` // another replica held the task's lock
+	Error      string `json:"error,omitempty"`
+}
+
+// RefreshOrchestrator runs the periodic refresh tasks (GMP, subscription, market indices)
+// in parallel under one shared deadline and records a single run report. Task starts
+// are staggered so the scrapers do not hit their sources at the same instant.
+type RefreshOrchestrator struct {
+	Tasks      []RefreshTask
+	Locker     *JobLocker
+	ScrapeRuns *services.ScrapeRunService
+	Interval   time.Duration
+	Deadline   time.Duration
+	Stagger    time.Duration
+	// Clock is the time series staleness is measured against at catch-up
+	Clock shared.Clock
+	// Politeness, when set, defers refreshes in its skip windows to the window end and
+	// runs tasks one at a time in its slow windows
+	Politeness *shared.PolitenessSchedule
+
+	running  atomic.Bool
+	deferred atomic.Bool
+}
+
+func NewRefreshOrchestrator(locker *JobLocker, scrapeRuns *services.ScrapeRunService, tasks ...RefreshTask) *RefreshOrchestrator {
+	return &RefreshOrchestrator{
+		Tasks:      tasks,
+		Locker:     locker,
+		ScrapeRuns: scrapeRuns,
+		Interval:   time.Hour,
+		Deadline:   15 * time.Minute,
+		Stagger:    20 * time.Second,
+		Clock:      shared.DefaultClock,
+	}
+}
+
+// Start catches up stale series, then runs a full refresh every Interval
+func (o *RefreshOrchestrator) Start() {
+	logrus.Infof("Starting Refresh Orchestrator with %d tasks (runs every %v)", len(o.Tasks), o.Interval)
+	ticker := time.NewTicker(o.Interval)
+
+	go func() {
+		o.CatchUp()
+		for range ticker.C {
+			o.Run()
+		}
+	}()
+}
+
+// CatchUp runs the tasks whose series have no observation within the last Interval, such
+// as after the service was down for hours, so their gaps close before the next scheduled
+// refresh. Tasks that cannot report their last observation always run.
+func (o *RefreshOrchestrator) CatchUp() []RefreshTaskResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := shared.ClockOrDefault(o.Clock).Now()
+	var stale []RefreshTask
+	for _, task := range o.Tasks {
+		if task.LastObserved == nil {
+			stale = append(stale, task)
+			continue
+		}
+		logger := logrus.WithField("task", task.Name)
+		lastObserved, err := task.LastObserved(ctx)
+		switch {
+		case err != nil:
+			logger.WithError(err).Warn("Failed to read last observation, catching up")
+		case lastObserved == nil:
+			logger.Info("No observations stored yet, catching up")
+		case now.Sub(*lastObserved) > o.Interval:
+			logger.WithField("gap", now.Sub(*lastObserved).Round(time.Minute).String()).Info("Series is stale, catching up")
+		default:
+			continue
+		}
+		stale = append(stale, task)
+	}
+
+	if len(stale) == 0 {
+		logrus.Info("Refresh Orchestrator catch-up: every series is fresh")
+		return nil
+	}
+	return o.run(stale)
+}
+
+// Run executes every task once and returns their results in task order. A task still
+// running at the deadline is reported as timed out; the run does not wait for it.
+func (o *RefreshOrchestrator) Run() []RefreshTaskResult {
+	return o.run(o.Tasks)
+}
+
+// run executes tasks as one refresh. A refresh started while another is still running
+// on this instance is skipped and returns nil, so catch-up never overlaps a scheduled
+// run; task locks keep other replicas out.
+func (o *RefreshOrchestrator) run(tasks []RefreshTask) []RefreshTaskResult {
+	if until, ok := o.Politeness.SkipUntil(); ok {
+		o.deferUntil(until, tasks)
+		return nil
+	}
+	if !o.running.CompareAndSwap(false, true) {
+		logrus.Info("Refresh Orchestrator is already running, skipping this refresh")
+		return nil
+	}
+	defer o.running.Store(false)
+
+	ctx, finish := DefaultJobRuns.Start(context.Background(), RefreshOrchestratorName, o.Deadline)
+	defer finish()
+
+	recorder := services.NewScrapeRunRecorder(RefreshOrchestratorName)
+	// Buffered so tasks finishing after the deadline never block
+	done := make(chan refreshTaskDone, len(tasks))
+	// In a slow politeness window tasks take turns instead of running in parallel
+	var turn chan struct{}
+	if o.Politeness.Slow() {
+		logrus.Info("Politeness window is open, running refresh tasks one at a time")
+		turn = make(chan struct{}, 1)
+	}
+	for i, task := range tasks {
+		go o.runTask(ctx, task, i, time.Duration(i)*o.Stagger, turn, done)
+	}
+
+	results := make([]RefreshTaskResult, len(tasks))
+	for i, task := range tasks {
+		results[i] = RefreshTaskResult{Name: task.Name, Error: "deadline exceeded"}
+	}
+collect:
+	for remaining := len(tasks); remaining > 0; remaining-- {
+		select {
+		case finished := <-done:
+			results[finished.index] = finished.result
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	ran, failed := 0, 0
+	metrics := make(map[string]interface{}, len(tasks))
+	for _, result := range results {
+		metrics[result.Name] = result
+
+		switch {
+		case result.Skipped:
+			continue
+		case result.Error != "":
+			recorder.RecordFailure(result.Name, errors.New(result.Error))
+			failed++
+		default:
+			recorder.RecordSaved(true, "")
+		}
+		ran++
+	}
+	recorder.SetTotal(ran)
+	run := recorder.Finish(nil)
+	run.ExtractionMetrics = map[string]interface{}{"tasks": metrics}
+
+	logrus.Infof("Refresh Orchestrator completed: %d tasks ran, %d failed, %d skipped (took %dms)",
+		ran, failed, len(tasks)-ran, run.DurationMs)
+
+	// Replicas that only skipped locked tasks have nothing to report
+	if ran > 0 && o.ScrapeRuns != nil {
+		saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer saveCancel()
+		if err := o.ScrapeRuns.Save(saveCtx, run); err != nil {
+			logrus.WithError(err).Warn("Failed to save refresh run report")
+		}
+	}
+	return results
+}
+
+// deferUntil runs tasks as one refresh once the politeness window ends. A refresh
+// deferred while another is already waiting is dropped.
+func (o *RefreshOrchestrator) deferUntil(until time.Time, tasks []RefreshTask) {
+	if !o.deferred.CompareAndSwap(false, true) {
+		return
+	}
+	logrus.WithField("until", until.Format(time.RFC3339)).Info("Politeness window is open, deferring refresh")
+	time.AfterFunc(time.Until(until), func() {
+		o.deferred.Store(false)
+		o.run(tasks)
+	})
+}
+
+// refreshTaskDone carries a finished task's result back to Run
+type refreshTaskDone struct {
+	index  int
+	result RefreshTaskResult
+}
+
+// runTask waits for the task's staggered start and, when turn is set, for its turn, runs
+// it under its lock and reports the result
+func (o *RefreshOrchestrator) runTask(ctx context.Context, task RefreshTask, index int, delay time.Duration, turn chan struct{}, done chan<- refreshTaskDone) {
+	result := RefreshTaskResult{Name: task.Name}
+	defer func() { done <- refreshTaskDone{index: index, result: result} }()
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		result.Error = "deadline exceeded before start"
+		return
+	}
+	if turn != nil {
+		select {
+		case turn <- struct{}{}:
+			defer func() { <-turn }()
+		case <-ctx.Done():
+			result.Error = "deadline exceeded before start"
+			return
+		}
+	}
+
+	// Tracked as a run of its own, since the refresh does not wait for a task past the
+	// deadline
+	taskCtx, finish := DefaultJobRuns.Start(ctx, RefreshOrchestratorName+"."+task.Name, 0)
+	defer finish()
+
+	start := time.Now()
+	run := func() {
+		var count RefreshCount
+		var err error
+		if task.RunCounted != nil {
+			count, err = task.RunCounted(taskCtx)
+		} else {
+			count.Items, err = task.Run(taskCtx)
+		}
+		result.Items, result.Unchanged = count.Items, count.Unchanged
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+	if task.LockName == "" {
+		run()
+	} else if !o.Locker.RunExclusive(task.LockName, run) {
+		result.Skipped = true
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+}
@@ -8,8 +8,21 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ResultReleaseCheckJobName is the lock name of the result release check job
+const ResultReleaseCheckJobName = "result_release_check"
+
+// ResultReleaseWatchJobName is the lock name of the result release watch
+const ResultReleaseWatchJobName = "result_release_watch"
+
 type ResultReleaseCheckJob struct {
 	IPOService *services.IPOService
+	// Rechecks, when set, re-runs allotment checks that were answered before results
+	// were declared, once the registrar has published them
+	Rechecks *services.AllotmentRecheckService
+	// Watcher, when set, probes IPOs past their result date every Watcher.Interval so
+	// released results are detected within minutes, and re-checks run right away
+	Watcher *services.ResultReleaseWatcher
+	Locker  *JobLocker
 }
 
 func NewResultReleaseCheckJob(ipoService *services.IPOService) *ResultReleaseCheckJob {
@@ -18,16 +31,58 @@ func NewResultReleaseCheckJob(ipoService *services.IPOService) *ResultReleaseChe
 
 func (j *ResultReleaseCheckJob) Run() {
 	logrus.Info("Starting Result Release Check Job")
-	// Placeholder logic
-	// In reality, this would check each IPO's result date and maybe ping the registrar
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
-
-	// Use ctx to avoid lint error
-	select {
-	case <-ctx.Done():
-		return
-	default:
+	// Re-checks are rate limited by the allotment checker, so a full batch takes minutes
+	ctx, done := DefaultJobRuns.Start(context.Background(), ResultReleaseCheckJobName, 10*time.Minute)
+	defer done()
+
+	if j.Rechecks.Enabled() {
+		completed, err := j.Rechecks.RunDue(ctx)
+		if err != nil {
+			logrus.Errorf("Result Release Check Job failed to run allotment re-checks: %v", err)
+			return
+		}
+		logrus.Infof("Result Release Check Job completed %d allotment re-checks", completed)
 	}
 	logrus.Info("Result Release Check Job completed")
 }
+
+// Start runs the result release watch now and then every Watcher.Interval in the
+// background. Without a Watcher only the scheduled Run checks for results.
+func (j *ResultReleaseCheckJob) Start() {
+	if j.Watcher == nil {
+		return
+	}
+	logrus.Infof("Starting Result Release Watch (runs every %v)...", j.Watcher.Interval)
+	ticker := time.NewTicker(j.Watcher.Interval)
+
+	go func() {
+		j.Locker.RunExclusive(ResultReleaseWatchJobName, j.Watch)
+		for range ticker.C {
+			j.Locker.RunExclusive(ResultReleaseWatchJobName, j.Watch)
+		}
+	}()
+}
+
+// Watch probes the IPOs awaiting results and, when any were released, runs their
+// pending re-checks without waiting for the next hourly run
+func (j *ResultReleaseCheckJob) Watch() {
+	ctx, done := DefaultJobRuns.Start(context.Background(), ResultReleaseWatchJobName, j.Watcher.Interval)
+	defer done()
+
+	released, err := j.Watcher.RunDue(ctx)
+	if err != nil {
+		logrus.Errorf("Result Release Watch failed: %v", err)
+	}
+	if released == 0 {
+		return
+	}
+	logrus.Infof("Result Release Watch detected results of %d IPOs", released)
+	if j.Rechecks.Enabled() {
+		completed, err := j.Rechecks.RunDue(ctx)
+		if err != nil {
+			logrus.Errorf("Result Release Watch failed to run allotment re-checks: %v", err)
+			return
+		}
+		logrus.Infof("Result Release Watch completed %d allotment re-checks", completed)
+	}
+}
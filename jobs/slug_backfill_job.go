@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// SlugBackfillJobName is the lock name of the slug backfill job
+const SlugBackfillJobName = "slug_backfill"
+
+// SlugBackfillJob generates slugs for IPOs stored without one
+type SlugBackfillJob struct {
+	IPOService *services.IPOService
+}
+
+func NewSlugBackfillJob(ipoService *services.IPOService) *SlugBackfillJob {
+	return &SlugBackfillJob{IPOService: ipoService}
+}
+
+func (j *SlugBackfillJob) Run() {
+	logrus.Info("Starting Slug Backfill Job")
+	ctx, done := DefaultJobRuns.Start(context.Background(), SlugBackfillJobName, 5*time.Minute)
+	defer done()
+
+	filled, err := j.IPOService.BackfillSlugs(ctx)
+	if err != nil {
+		logrus.Errorf("Slug Backfill Job failed: %v", err)
+		return
+	}
+
+	logrus.Infof("Slug Backfill Job completed: filled %d IPOs", filled)
+}
@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultStartupPhaseTimeout bounds a startup phase that sets no Timeout
+const DefaultStartupPhaseTimeout = time.Minute
+
+// Startup phase states, as reported by StartupOrchestrator.Report
+const (
+	StartupPhasePending = "pending"
+	StartupPhaseRunning = "running"
+	StartupPhaseOK      = "ok"
+	StartupPhaseFailed  = "failed"
+)
+
+// StartupPhase is one step that must finish before background jobs start
+type StartupPhase struct {
+	Name string
+	Run  func(ctx context.Context) error
+	// Timeout bounds the phase, retries included; DefaultStartupPhaseTimeout when unset
+	Timeout time.Duration
+	// RetryEvery, when set, reruns a failing phase after this pause until its timeout
+	RetryEvery time.Duration
+	// Required phases that fail keep the jobs from starting; others are only logged
+	Required bool
+}
+
+// StartupPhaseReport is the state of a startup phase
+type StartupPhaseReport struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Required   bool   `json:"required"`
+	Attempts   int    `json:"attempts,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// StartupOrchestrator runs the startup phases (database readiness, schema validation,
+// cache warmup) in order, each under its own timeout, and reports whether background
+// jobs may start. It stops at the first required phase that fails.
+type StartupOrchestrator struct {
+	Phases []StartupPhase
+
+	mutex   sync.RWMutex
+	reports []StartupPhaseReport
+	ready   bool
+	logger  *logrus.Entry
+}
+
+func NewStartupOrchestrator(phases ...StartupPhase) *StartupOrchestrator {
+	reports := make([]StartupPhaseReport, len(phases))
+	for i, phase := range phases {
+		reports[i] = StartupPhaseReport{Name: phase.Name, Status: StartupPhasePending, Required: phase.Required}
+	}
+	return &StartupOrchestrator{
+		Phases:  phases,
+		reports: reports,
+		logger:  logrus.WithField("component", "startup"),
+	}
+}
+
+// Run runs the phases in order. Returns the error of the required phase that failed,
+// or nil once every required phase succeeded and the jobs may start.
+func (o *StartupOrchestrator) Run(ctx context.Context) error {
+	started := time.Now()
+	for i, phase := range o.Phases {
+		o.update(i, func(report *StartupPhaseReport) { report.Status = StartupPhaseRunning })
+		o.logger.WithField("phase", phase.Name).Info("Startup phase started")
+
+		phaseStarted := time.Now()
+		attempts, err := o.runPhase(ctx, phase)
+		duration := time.Since(phaseStarted)
+		o.update(i, func(report *StartupPhaseReport) {
+			report.Attempts = attempts
+			report.DurationMs = duration.Milliseconds()
+			report.Status = StartupPhaseOK
+			if err != nil {
+				report.Status = StartupPhaseFailed
+				report.Error = err.Error()
+			}
+		})
+
+		entry := o.logger.WithFields(logrus.Fields{"phase": phase.Name, "attempts": attempts, "duration": duration})
+		if err == nil {
+			entry.Info("Startup phase completed")
+			continue
+		}
+		if !phase.Required {
+			entry.WithError(err).Warn("Optional startup phase failed, continuing")
+			continue
+		}
+		entry.WithError(err).Error("Required startup phase failed, background jobs will not start")
+		return fmt.Errorf("startup phase %s failed: %w", phase.Name, err)
+	}
+
+	o.mutex.Lock()
+	o.ready = true
+	o.mutex.Unlock()
+	o.logger.WithField("duration", time.Since(started)).Info("Startup completed, background jobs may start")
+	return nil
+}
+
+// runPhase runs a phase until it succeeds, fails without retries or times out. A phase
+// that ignores its context is abandoned at the timeout. Returns the attempts made.
+func (o *StartupOrchestrator) runPhase(ctx context.Context, phase StartupPhase) (int, error) {
+	timeout := phase.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStartupPhaseTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		done := make(chan error, 1)
+		go func() { done <- phase.Run(ctx) }()
+
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("timed out after %s", timeout)
+		}
+		if err == nil || phase.RetryEvery <= 0 {
+			return attempt, err
+		}
+
+		o.logger.WithError(err).WithFields(logrus.Fields{"phase": phase.Name, "attempt": attempt}).Warn("Startup phase attempt failed, retrying")
+		select {
+		case <-time.After(phase.RetryEvery):
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+	}
+}
+
+func (o *StartupOrchestrator) update(i int, change func(report *StartupPhaseReport)) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	change(&o.reports[i])
+}
+
+// Ready reports whether every required phase succeeded
+func (o *StartupOrchestrator) Ready() bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.ready
+}
+
+// Report returns the state of every phase, in run order
+func (o *StartupOrchestrator) Report() []StartupPhaseReport {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return append([]StartupPhaseReport(nil), o.reports...)
+}
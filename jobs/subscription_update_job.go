@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// SubscriptionUpdateJobName is the lock name of the hourly subscription snapshot job
+const SubscriptionUpdateJobName = "subscription_update"
+
+// SubscriptionUpdateJob records category-wise subscription multiples of live IPOs, building
+// the series served by GET /ipos/:id/subscription/history
+type SubscriptionUpdateJob struct {
+	IPOService    *services.IPOService
+	Subscriptions *services.SubscriptionService
+}
+
+func NewSubscriptionUpdateJob(ipoService *services.IPOService, subscriptions *services.SubscriptionService) *SubscriptionUpdateJob {
+	return &SubscriptionUpdateJob{IPOService: ipoService, Subscriptions: subscriptions}
+}
+
+func (j *SubscriptionUpdateJob) Run() {
+	logrus.Info("Starting Subscription Update Job")
+	ctx, done := DefaultJobRuns.Start(context.Background(), SubscriptionUpdateJobName, 10*time.Minute)
+	defer done()
+
+	if _, err := j.Refresh(ctx); err != nil {
+		logrus.Errorf("Subscription Update Job failed: %v", err)
+	}
+}
+
+// Refresh records a subscription snapshot of every live IPO and returns how many were
+// recorded. It fails only when IPOs cannot be loaded or every live IPO failed.
+func (j *SubscriptionUpdateJob) Refresh(ctx context.Context) (int, error) {
+	ipos, err := j.IPOService.GetIPOs(ctx, "all")
+	if err != nil {
+		return 0, fmt.Errorf("failed to load IPOs: %w", err)
+	}
+
+	recorded, failed := 0, 0
+	now := time.Now()
+	var lastErr error
+	for i := range ipos {
+		ipo := &ipos[i]
+		if ipo.Status != "ACTIVE" {
+			continue
+		}
+
+		snapshot, err := j.Subscriptions.FetchSubscription(ctx, ipo)
+		if err != nil {
+			logrus.WithError(err).WithField("ipo", ipo.Name).Warn("Failed to fetch subscription data")
+			failed++
+			lastErr = err
+			continue
+		}
+		if err := j.Subscriptions.RecordSnapshot(ctx, ipo.ID, snapshot, now); err != nil {
+			logrus.WithError(err).WithField("ipo", ipo.Name).Warn("Failed to record subscription snapshot")
+			failed++
+			lastErr = err
+			continue
+		}
+		recorded++
+	}
+
+	logrus.Infof("Subscription Update Job completed: recorded %d IPOs, %d failed", recorded, failed)
+	if recorded == 0 && failed > 0 {
+		return 0, fmt.Errorf("all %d subscription updates failed: %w", failed, lastErr)
+	}
+	return recorded, nil
+}
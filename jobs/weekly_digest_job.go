@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/sirupsen/logrus"
+)
+
+// WeeklyDigestJobName is the lock name of the weekly digest email job
+const WeeklyDigestJobName = "weekly_digest"
+
+// WeeklyDigestJob emails the upcoming week's IPO digest to subscribed accounts on Sunday
+// evenings, checking every Interval whether it is due
+type WeeklyDigestJob struct {
+	Digests  *services.WeeklyDigestService
+	Locker   *JobLocker
+	Interval time.Duration
+}
+
+func NewWeeklyDigestJob(digests *services.WeeklyDigestService, locker *JobLocker) *WeeklyDigestJob {
+	return &WeeklyDigestJob{Digests: digests, Locker: locker, Interval: 15 * time.Minute}
+}
+
+// Start runs the job now and then every Interval in the background
+func (j *WeeklyDigestJob) Start() {
+	logrus.Infof("Starting Weekly Digest Job (runs every %v)...", j.Interval)
+	ticker := time.NewTicker(j.Interval)
+
+	go func() {
+		j.Locker.RunExclusive(WeeklyDigestJobName, j.Run)
+		for range ticker.C {
+			j.Locker.RunExclusive(WeeklyDigestJobName, j.Run)
+		}
+	}()
+}
+
+func (j *WeeklyDigestJob) Run() {
+	ctx, done := DefaultJobRuns.Start(context.Background(), WeeklyDigestJobName, j.Interval)
+	defer done()
+
+	sent, err := j.Digests.SendDue(ctx)
+	if err != nil {
+		logrus.Errorf("Weekly Digest Job failed: %v", err)
+		return
+	}
+	if sent > 0 {
+		logrus.Infof("Weekly Digest Job completed: sent %d digests", sent)
+	}
+}
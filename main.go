@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -9,16 +10,23 @@ import (
 	"github.com/fenilmodi00/ipo-backend/database"
 	"github.com/fenilmodi00/ipo-backend/handlers"
 	"github.com/fenilmodi00/ipo-backend/jobs"
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
 	// Load config
 	cfg := config.LoadConfig()
 
+	// Mask PANs, application numbers and demat IDs in everything logged through logrus
+	shared.ScrubLoggerPII(logrus.StandardLogger())
+
 	// Connect to database
 	if err := database.Connect(cfg.DatabaseURL); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -30,6 +38,16 @@ func main() {
 		log.Printf("Migration warning: %v", err)
 	}
 
+	// Generate (and optionally apply) migrations for columns and constraints the schema validator finds missing
+	if mode := cfg.GetSchemaRemediationMode(); mode != database.RemediationOff {
+		path, err := database.RemediateSchema("database/schema.sql", cfg.SchemaMigrationsDir, mode)
+		if err != nil {
+			log.Printf("Schema remediation warning: %v", err)
+		} else if path != "" {
+			log.Printf("Schema remediation migration written to %s", path)
+		}
+	}
+
 	// Initialize simplified service configurations
 	cacheConfig := config.DefaultCacheConfig()
 
@@ -38,16 +56,38 @@ func main() {
 		cacheConfig.DefaultTTL = cfg.GetCacheTTL()
 	}
 
+	// Apply per-host concurrent request budgets for outbound scraping
+	for host, budget := range cfg.GetHTTPHostBudgets() {
+		shared.DefaultHTTPHostMetrics.SetHostBudget(host, budget)
+	}
+	// Keep failed scraper and registrar requests for /admin/debug/http when enabled
+	shared.DefaultHTTPRecorder.Configure(cfg.GetHTTPRecordingSize())
+
 	// Initialize consolidated services with simplified configuration
 	utilityService := services.NewUtilityService()
-	scrapingService := services.NewChittorgarhIPOScrapingService(nil) // Use simplified scraper with default config
-	allotmentChecker := services.NewAllotmentChecker()                // Separate service for allotment checking
+	retryPolicies := cfg.GetRetryPolicies()
+	scraperConfig := services.NewDefaultIPOScraperConfiguration()
+	scraperConfig.RetryPolicy = &retryPolicies.HTTP
+	scraperConfig.IPOListMirrorURLs = cfg.GetIPOListMirrorURLs()
+	scraperConfig.HTTPCacheDir = cfg.GetScraperHTTPCacheDir()
+	scraperConfig.HTTPCacheMaxAge = cfg.GetScraperCacheMaxAge()
+	scraperConfig.ShadowExtraction = cfg.GetScraperShadowExtraction()
+	scrapingService := services.NewChittorgarhIPOScrapingService(scraperConfig)
+	allotmentChecker := services.NewAllotmentChecker() // Separate service for allotment checking
 
-	// Use Enhanced GMP Service with default configuration
-	// gmpConfig := shared.NewGMPServiceConfig()
-	// gmpService := services.NewEnhancedGMPService(&gmpConfig, database.DB)
+	// GMP scraper implementation behind the GMP job, switched with SCRAPER_IMPL
+	gmpScraper, err := services.NewGMPScraper(cfg.GetScraperImpl(), database.DB)
+	if err != nil {
+		log.Fatalf("Invalid SCRAPER_IMPL: %v", err)
+	}
 
 	ipoService := services.NewIPOService(database.DB)
+	scrapingService.ExtractionOverrides = ipoService
+	ipoService.SetDatabaseRetryPolicy(retryPolicies.Database)
+	ipoService.TextLimits = services.TextPreviewLimits{
+		Description: cfg.GetDescriptionPreviewLength(),
+		About:       cfg.GetAboutPreviewLength(),
+	}
 
 	// Initialize caching layer with simplified configuration
 	cacheService := services.NewCacheServiceWithConfig(
@@ -55,8 +95,28 @@ func main() {
 		cacheConfig.DefaultTTL,
 		cacheConfig.MaxSize,
 	)
+	// Validate Redis before moving the data cache to it: writes go to both, reads are
+	// still served from memory and compared with Redis
+	if cfg.GetCacheShadowMode() == services.CacheShadowWrite {
+		if redisClient, err := shared.NewRedisClient(cfg.RedisURL); err != nil {
+			log.Printf("Cache shadow disabled: %v", err)
+		} else {
+			pingCtx, cancelPing := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := redisClient.Ping(pingCtx); err != nil {
+				log.Printf("Redis not reachable yet, cache shadow errors are counted until it is: %v", err)
+			}
+			cancelPing()
+			cacheService.Shadow = services.NewCacheShadow("redis", redisClient, "ipo-backend:data:")
+		}
+	}
+	fingerprintKey := cfg.GetClientFingerprintKey()
+	cacheService.ReferenceKey = fingerprintKey
 	cachedIPOService := services.NewCachedIPOService(ipoService, cacheService)
 
+	// Per-PAN daily quota on registrar lookups
+	checkDailyLimit := cfg.GetCheckDailyLimit()
+	checkQuotaService := services.NewCheckQuotaService(database.DB, checkDailyLimit)
+
 	// Configure scraping service with simplified rate limiting
 	// Note: Rate limiting is now handled internally by the simplified scraper
 
@@ -65,45 +125,317 @@ func main() {
 	log.Println("Simplified IPO backend services initialized:")
 	log.Printf("  - Simplified IPO scraper (rate limit: %v, timeout: %v)",
 		defaultConfig.RequestRateLimit, defaultConfig.HTTPRequestTimeout)
+	log.Printf("  - GMP scraper (%s)", gmpScraper.Name())
 	log.Printf("  - Allotment checker (rate limit: %v)", 2*time.Second)
 	log.Printf("  - Unified cache service (TTL: %v, max size: %d)",
 		cacheConfig.DefaultTTL, cacheConfig.MaxSize)
 	log.Println("  - Utility service (text processing and normalization)")
 	log.Println("  - Simplified IPO service (lifecycle analyzer removed)")
 
+	// Short-lived HTTP response cache for read-only public endpoints
+	responseCache := middleware.NewResponseCache(cfg.GetResponseCacheTTL())
+
+	// Generation time, cache status, staleness and count on IPO and GMP list responses
+	responseEnvelope := middleware.NewResponseEnvelope(cfg.GetResponseMetaEnabled())
+
+	// Size and content-type limits for JSON write endpoints on admin and check routes
+	bodyValidation := middleware.NewBodyValidation(cfg.GetMaxRequestBodyBytes())
+
 	// Initialize Jobs with consolidated services first
 	dailyJob := jobs.NewDailyIPOUpdateJob(scrapingService, ipoService, utilityService)
 	resultJob := jobs.NewResultReleaseCheckJob(ipoService)
 	cleanupJob := jobs.NewCacheCleanupJob(cacheService)
+	cleanupJob.QuotaService = checkQuotaService
 	gmpJob := jobs.NewGMPUpdateJob(database.DB)
+	hotnessService := services.NewHotnessService(database.DB, utilityService)
+	hotnessJob := jobs.NewHotnessScoreJob(hotnessService)
+	issueSizeBackfillJob := jobs.NewIssueSizeBackfillJob(ipoService)
+	slugBackfillJob := jobs.NewSlugBackfillJob(ipoService)
+	subscriptionService := services.NewSubscriptionService(database.DB)
+	if scraperConfig.HTTPCacheDir != "" {
+		subscriptionService.Client = shared.NewDiskCachedClient(subscriptionService.Client, scraperConfig.HTTPCacheDir, scraperConfig.HTTPCacheMaxAge)
+	}
+	subscriptionJob := jobs.NewSubscriptionUpdateJob(ipoService, subscriptionService)
+
+	// Advisory-lock based locking so each scheduled job runs on one replica only
+	jobLocker := jobs.NewJobLocker(database.DB)
+	// Ticker-driven jobs run once per period across replicas, matching their tickers below
+	jobLocker.Periods = map[string]time.Duration{
+		jobs.DailyIPOUpdateJobName:     8 * time.Hour,
+		jobs.ResultReleaseCheckJobName: 1 * time.Hour,
+		jobs.HotnessScoreJobName:       1 * time.Hour,
+		jobs.CacheCleanupJobName:       12 * time.Hour,
+	}
+	// Every job run ends at its deadline, so a hung call cannot hold a job forever
+	jobs.DefaultJobRuns.Deadlines = cfg.GetJobDeadlines()
+	jobs.DefaultJobRuns.DefaultDeadline = cfg.GetJobDefaultDeadline()
+	gmpJob.Locker = jobLocker
+	gmpJob.Scraper = gmpScraper
+	gmpJob.ResponseCache = responseCache
+	gmpJob.SimpleGMPService.OutlierThreshold = cfg.GetGMPOutlierThreshold()
+	dailyJob.ResponseCache = responseCache
+	dailyJob.ScrapeRuns = services.NewScrapeRunService(database.DB)
+	// Scraping jobs go easy on the source sites during their peak hours
+	scrapePoliteness := cfg.GetScrapePoliteness()
+	dailyJob.Politeness = scrapePoliteness
+	gmpJob.Politeness = scrapePoliteness
+	preOpenService := services.NewPreOpenService(database.DB, services.NewNSEPreOpenProvider())
+	preOpenJob := jobs.NewPreOpenPriceJob(preOpenService, jobLocker)
+	preOpenJob.ResponseCache = responseCache
+	marketIndexService := services.NewMarketIndexService(map[string]services.MarketIndexProvider{
+		"nse":   services.NewNSEIndexProvider(),
+		"yahoo": services.NewYahooQuoteProvider(),
+	})
+	marketIndexJob := jobs.NewMarketIndexJob(marketIndexService)
+	// After downtime, startup catch-up refreshes only the series whose last observation is
+	// older than the refresh interval
+	dataFreshness := services.NewDataFreshnessService(database.DB)
+	// List responses are stale after the daily scrape (every 8h) or the hourly GMP refresh
+	// has missed a few runs
+	ipoListData := &middleware.DataSource{Name: "ipo_list", StaleAfter: 24 * time.Hour, LastUpdated: dataFreshness.IPOListUpdatedAt}
+	gmpData := &middleware.DataSource{Name: "gmp", StaleAfter: 3 * time.Hour, LastUpdated: dataFreshness.GMPUpdatedAt}
+	refreshOrchestrator := jobs.NewRefreshOrchestrator(jobLocker, dailyJob.ScrapeRuns,
+		jobs.RefreshTask{Name: "gmp", LockName: jobs.GMPUpdateJobName, RunCounted: gmpJob.RefreshDue,
+			LastObserved: dataFreshness.GMPUpdatedAt},
+		jobs.RefreshTask{Name: "subscription", LockName: jobs.SubscriptionUpdateJobName, Run: subscriptionJob.Refresh,
+			LastObserved: dataFreshness.SubscriptionUpdatedAt},
+		jobs.RefreshTask{Name: "market_indices", LockName: jobs.MarketIndexRefreshName, Run: marketIndexService.Poll},
+	)
+	refreshOrchestrator.Politeness = scrapePoliteness
 
 	// Initialize handlers with consolidated services
 	ipoHandler := handlers.NewIPOHandler(ipoService)
+	ipoHandler.Cache = cachedIPOService
+	ipoHandler.FormMetadata = services.NewRegistrarFormService(2 * time.Minute)
 	cacheHandler := handlers.NewCacheHandler(cacheService)
+	cacheHandler.FingerprintKey = fingerprintKey
 	adminHandler := handlers.NewAdminHandler(ipoService, gmpJob)
-	checkHandler := handlers.NewCheckHandler(ipoService, allotmentChecker, cacheService)
-	marketHandler := handlers.NewMarketHandler()
+	checkQueueConfig := services.DefaultCheckQueueConfig()
+	checkQueueConfig.RegistrarLimits = cfg.GetCheckRegistrarLimits()
+	checkQueue := services.NewAllotmentCheckQueue(allotmentChecker, cacheService, checkQueueConfig)
+	checkHandler := handlers.NewCheckHandler(ipoService, allotmentChecker, cacheService, checkQueue, checkQuotaService)
+	checkFeedbackService := services.NewCheckFeedbackService(database.DB)
+	checkHandler.Feedback = checkFeedbackService
+	checkHandler.IPOCache = cachedIPOService
+	checkHandler.FingerprintKey = fingerprintKey
+	// Synthetic check results for QA and frontend tests; leave CHECK_SANDBOX_KEYS empty in production
+	checkHandler.Sandbox = services.NewCheckSandbox(cfg.GetCheckSandboxKeys())
+	if checkHandler.Sandbox.Enabled() {
+		log.Println("WARNING: check sandbox mode is enabled; POST /check with X-Sandbox-Key returns synthetic results")
+	}
+	marketHandler := handlers.NewMarketHandler(marketIndexService)
 	gmpHandler := handlers.NewGMPHandler(database.DB)
+	gmpHandler.PreOpen = preOpenService
 	performanceHandler := handlers.NewPerformanceHandler(database.DB, ipoService, cachedIPOService)
-
-	// Warmup cache on startup
-	go func() {
-		time.Sleep(2 * time.Second) // Wait for database to be ready
-		if err := cachedIPOService.WarmupCache(context.Background()); err != nil {
-			log.Printf("Cache warmup failed: %v", err)
+	performanceHandler.ResponseCache = responseCache
+	// Cache invalidations are broadcast to every replica over Postgres LISTEN/NOTIFY
+	cacheInvalidation := services.NewCacheInvalidationBus(database.DB, cfg.DatabaseURL, cachedIPOService, responseCache)
+	if err := cacheInvalidation.Start(context.Background()); err != nil {
+		log.Printf("Cache invalidation listener disabled, other replicas' edits expire by TTL: %v", err)
+	}
+	performanceHandler.Invalidation = cacheInvalidation
+	adminHandler.Invalidation = cacheInvalidation
+	// The result check only runs on fresh daily data; GMP and the other refreshes are independent
+	jobScheduler := jobs.NewJobScheduler(jobLocker, dailyJob.ScrapeRuns.LastSucceeded, jobs.JobDependency{
+		Job:       jobs.ResultReleaseCheckJobName,
+		DependsOn: jobs.DailyIPOUpdateJobName,
+		MaxAge:    cfg.GetResultCheckMaxAge(),
+		OnUnmet:   jobs.DependencyQueue,
+	})
+	jobScheduler.Politeness = scrapePoliteness
+	jobScheduler.ScrapeJobs = []string{jobs.DailyIPOUpdateJobName}
+	jobsHandler := handlers.NewJobsHandler(jobLocker)
+	jobsHandler.Scheduler = jobScheduler
+	hotnessHandler := handlers.NewHotnessHandler(hotnessService)
+	scraperPatternHandler := handlers.NewScraperPatternHandler(services.DefaultTextPatterns)
+	scrapeRunHandler := handlers.NewScrapeRunHandler(dailyJob.ScrapeRuns)
+	scrapeRunHandler.Scraper = scrapingService
+	analyticsHandler := handlers.NewAnalyticsHandler(ipoService)
+	analyticsHandler.Leaderboard = services.NewLeaderboardService(ipoService)
+	feedHandler := handlers.NewFeedHandler(ipoService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService)
+	allotmentRatioHandler := handlers.NewAllotmentRatioHandler(services.NewAllotmentRatioService(database.DB, subscriptionService))
+	exportHandler := handlers.NewExportHandler(services.NewExportService(database.DB))
+	adminAllowlist, err := middleware.NewIPAllowlist(cfg.GetAdminIPAllowlist())
+	if err != nil {
+		log.Fatalf("Invalid ADMIN_IP_ALLOWLIST: %v", err)
+	}
+	adminAllowlist.ClientIPHeader = cfg.AdminClientIPHeader
+	if err := adminAllowlist.SetTrustedProxies(cfg.GetAdminTrustedProxies()); err != nil {
+		log.Fatalf("Invalid ADMIN_TRUSTED_PROXIES: %v", err)
+	}
+	if cfg.AdminClientIPHeader != "" && len(cfg.GetAdminTrustedProxies()) == 0 {
+		log.Println("WARNING: ADMIN_CLIENT_IP_HEADER is set without ADMIN_TRUSTED_PROXIES; the header is ignored")
+	}
+	exportRateLimit := cfg.GetExportRateLimit()
+	exportAuth := middleware.NewAPIKeyAuth(cfg.GetExportAPIKeys(), exportRateLimit)
+	signedURLs := middleware.NewSignedURLs(cfg.SignedURLSecret, services.NewSignedURLRedemptionStore(database.DB))
+	signedURLHandler := handlers.NewSignedURLHandler(signedURLs, cfg.GetSignedURLTTL())
+	cacheStoreSignatures := middleware.NewRequestSignatures(cfg.GetCacheStoreClientKeys(), services.NewSignedURLRedemptionStore(database.DB))
+	var panCipher *services.PANCipher
+	if key := cfg.GetPANVaultKey(); key != nil {
+		cipher, err := services.NewPANCipher(key)
+		if err != nil {
+			log.Printf("PAN vault disabled: %v", err)
+		}
+		panCipher = cipher
+	}
+	// Checks answered with "results not declared" are re-run by the result release check;
+	// the PAN is held encrypted with the vault key until then
+	recheckService := services.NewAllotmentRecheckService(database.DB, allotmentChecker, cacheService, ipoService, panCipher)
+	checkQueue.Rechecks = recheckService
+	resultJob.Rechecks = recheckService
+	// Watch IPOs past their result date closely so released results show within minutes
+	resultWatcher := services.NewResultReleaseWatcher(database.DB, allotmentChecker, ipoService)
+	resultWatcher.Interval = cfg.GetResultWatchInterval()
+	resultWatcher.Window = cfg.GetResultWatchWindow()
+	resultJob.Watcher = resultWatcher
+	resultJob.Locker = jobLocker
+	// UPI mandate deadline reminders for IPOs closing today
+	mandateReminders := services.NewMandateReminderService(database.DB)
+	mandateReminders.Lead = cfg.GetMandateReminderLead()
+	mandateReminderJob := jobs.NewMandateReminderJob(mandateReminders, jobLocker)
+	// Passwordless end-user accounts; each login channel is enabled by configuring its sender
+	accountService := services.NewAccountService(database.DB, cfg.AuthMagicLinkURL, cfg.GetAuthSessionTTL())
+	// Weekly digest of the coming week's IPOs, emailed on Sunday evenings to subscribed
+	// accounts through the login email server
+	weeklyDigests := services.NewWeeklyDigestService(database.DB)
+	if cfg.AuthSMTPAddr != "" {
+		emailSender := &services.SMTPLoginSender{
+			Addr:     cfg.AuthSMTPAddr,
+			Username: cfg.AuthSMTPUsername,
+			Password: cfg.AuthSMTPPassword,
+			From:     cfg.AuthEmailFrom,
+		}
+		accountService.SetSender(models.LoginChannelEmail, emailSender)
+		weeklyDigests.Mailer = emailSender
+	}
+	if cfg.AuthSMSWebhookURL != "" {
+		accountService.SetSender(models.LoginChannelSMS, services.NewSMSWebhookSender(cfg.AuthSMSWebhookURL))
+	}
+	cleanupJob.Accounts = accountService
+	accountAuth := middleware.AttachAccount(accountService)
+	authHandler := handlers.NewAuthHandler(accountService)
+	weeklyDigestJob := jobs.NewWeeklyDigestJob(weeklyDigests, jobLocker)
+	digestHandler := handlers.NewDigestHandler(weeklyDigests)
+	vaultHandler := handlers.NewVaultHandler(services.NewPANVaultService(database.DB, panCipher), checkHandler)
+	// Telegram bot sharing the check cache, quota and queue with POST /check
+	var telegramHandler *handlers.TelegramHandler
+	if cfg.TelegramBotToken != "" {
+		telegramBot := services.NewTelegramBot(cfg.TelegramBotToken, ipoService, cacheService,
+			checkQuotaService, checkQueue, cfg.GetTelegramChatLimit())
+		recheckService.Telegram = telegramBot
+		telegramBot.Reminders = mandateReminders
+		telegramBot.FingerprintKey = fingerprintKey
+		mandateReminders.Telegram = telegramBot
+		if cfg.TelegramHookSecret != "" {
+			telegramHandler = handlers.NewTelegramHandler(telegramBot, cfg.TelegramHookSecret)
 		} else {
-			log.Println("Cache warmed up successfully")
+			telegramBot.Start(context.Background())
 		}
-	}()
+	}
+	quoteHandler := handlers.NewQuoteHandler(ipoService,
+		services.NewQuoteService(services.NewYahooQuoteProvider(), cfg.GetQuoteCacheTTL()))
+
+	// Background jobs start only once the database answers, the schema has every column
+	// they write and the cache is warm (or its warmup gave up)
+	startupTimeout := cfg.GetStartupPhaseTimeout()
+	startup := jobs.NewStartupOrchestrator(
+		jobs.StartupPhase{
+			Name:       "database",
+			Run:        func(ctx context.Context) error { return database.DB.PingContext(ctx) },
+			Timeout:    startupTimeout,
+			RetryEvery: 2 * time.Second,
+			Required:   true,
+		},
+		jobs.StartupPhase{
+			Name: "schema",
+			Run: func(ctx context.Context) error {
+				report, err := database.NewSchemaValidator(database.DB).ValidateSchemaCompatibility()
+				if err != nil {
+					return err
+				}
+				if report.CriticalIssues > 0 {
+					return fmt.Errorf("%d critical schema issues, see the schema validation log or set SCHEMA_REMEDIATION", report.CriticalIssues)
+				}
+				return nil
+			},
+			Timeout:  startupTimeout,
+			Required: true,
+		},
+		jobs.StartupPhase{
+			Name:       "cache_warmup",
+			Run:        cachedIPOService.WarmupCache,
+			Timeout:    startupTimeout,
+			RetryEvery: 5 * time.Second,
+		},
+	)
+
+	// Deliver outbox events (IPO/GMP changes) to configured webhooks
+	outboxDispatcher := services.NewOutboxDispatcher(database.DB, services.ParseWebhookNotifiers(cfg.WebhookURLs))
+	outboxDispatcher.Start(context.Background())
+	dashboardHandler := handlers.NewDashboardHandler(ipoService, dailyJob.ScrapeRuns, outboxDispatcher, checkQueue, jobLocker, responseCache)
+	dashboardHandler.Feedback = checkFeedbackService
+	debugHandler := handlers.NewDebugHandler(shared.DefaultHTTPRecorder)
+	debugHandler.IPOs = ipoService
+	statusHandler := handlers.NewStatusHandler(dailyJob.ScrapeRuns, dataFreshness)
+	statusHandler.IPOListSources = scrapingService.IPOListSourceHealth
+
+	// Load scraper text patterns from the database; other replicas' edits are picked up on reload
+	services.DefaultTextPatterns.SetDB(database.DB)
+	services.DefaultTextPatterns.Start(context.Background(), 1*time.Minute)
+
+	// Alert operators when scrape, GMP parse or registrar check success rates drop
+	alertConfig := services.DefaultAlertConfig()
+	for metric, threshold := range cfg.GetAlertThresholds() {
+		alertConfig.Thresholds[metric] = threshold
+	}
+	alertConfig.Cooldown = cfg.GetAlertCooldown()
+	var alertNotifiers []services.AlertNotifier
+	if cfg.AlertSlackWebhookURL != "" {
+		alertNotifiers = append(alertNotifiers, services.NewSlackAlertNotifier(cfg.AlertSlackWebhookURL))
+	}
+	if cfg.AlertSMTPAddr != "" && cfg.AlertEmailTo != "" {
+		alertNotifiers = append(alertNotifiers, services.NewEmailAlertNotifier(cfg.AlertSMTPAddr,
+			cfg.AlertSMTPUsername, cfg.AlertSMTPPassword, cfg.AlertEmailFrom, cfg.AlertEmailTo))
+	}
+	services.DefaultAlerter.Configure(alertConfig, alertNotifiers)
 
 	// Start Background Jobs with simplified scheduling
 	go func() {
+		if err := startup.Run(context.Background()); err != nil {
+			logrus.WithError(err).Error("Background jobs not started")
+			return
+		}
+
 		// Run immediately on startup
-		go dailyJob.Run()
+		go jobScheduler.Run(jobs.DailyIPOUpdateJobName, dailyJob.Run)
+		go jobScheduler.Run(jobs.HotnessScoreJobName, hotnessJob.Run)
+		go jobScheduler.Run(jobs.IssueSizeBackfillJobName, issueSizeBackfillJob.Run)
+		go jobScheduler.Run(jobs.SlugBackfillJobName, slugBackfillJob.Run)
+
+		// Catch up stale GMP and subscription series, then refresh due GMP, subscription and
+		// market index data together every hour
+		refreshOrchestrator.Start()
 
-		// Start GMP job with its own internal ticker (runs every 1 hour)
+		// Refresh each IPO's GMP on its own cadence, every 10 minutes near listing
 		gmpJob.Start()
 
+		// Poll listing-day pre-open prices every minute during the pre-open window
+		preOpenJob.Start()
+
+		// Poll market indices every 5 minutes during trading hours for the sparklines
+		marketIndexJob.Start()
+
+		// Send UPI mandate reminders ahead of the 5 PM deadline on close dates
+		mandateReminderJob.Start()
+
+		// Email the coming week's IPO digest to subscribers on Sunday evenings
+		weeklyDigestJob.Start()
+
+		// Probe registrars every 10 minutes after result dates until results are out
+		resultJob.Start()
+
 		// Schedule other jobs with simplified timing
 		dailyTicker := time.NewTicker(8 * time.Hour)
 		hourlyTicker := time.NewTicker(1 * time.Hour)
@@ -112,11 +444,12 @@ func main() {
 		for {
 			select {
 			case <-dailyTicker.C:
-				dailyJob.Run()
+				jobScheduler.Run(jobs.DailyIPOUpdateJobName, dailyJob.Run)
 			case <-hourlyTicker.C:
-				resultJob.Run()
+				jobScheduler.Run(jobs.ResultReleaseCheckJobName, resultJob.Run)
+				jobScheduler.Run(jobs.HotnessScoreJobName, hotnessJob.Run)
 			case <-cleanupTicker.C:
-				cleanupJob.Run()
+				jobScheduler.Run(jobs.CacheCleanupJobName, cleanupJob.Run)
 			}
 		}
 	}()
@@ -136,34 +469,168 @@ func main() {
 		})
 	})
 
-	// Routes
+	// Readiness: 503 until the startup phases have passed and background jobs started
+	app.Get("/ready", func(c *fiber.Ctx) error {
+		status := fiber.StatusOK
+		if !startup.Ready() {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"ready":  startup.Ready(),
+			"phases": startup.Report(),
+		})
+	})
+
+	// Routes. Each route's auth, rate limit and cache policy is described in routeCatalog
+	// for GET /api/v1/routes; routes without a description are open and uncached.
 	api := app.Group("/api/v1")
+	routeCatalog := middleware.NewRouteCatalog()
+	responseCachePolicy := middleware.RoutePolicy{Cache: fmt.Sprintf("response_cache %s", responseCache.TTL)}
+	checkQuotaPolicy := middleware.RoutePolicy{RateLimit: fmt.Sprintf("%d registrar lookups per PAN and IPO per day", checkDailyLimit)}
+	for _, path := range []string{
+		"/status", "/ipos", "/ipos/active", "/ipos/:id/subscription/history", "/ipos/:id/faq", "/gmp",
+		"/market/indices", "/digest/weekly", "/analytics/lockin-calendar", "/analytics/screener", "/feeds/ipos.rss", "/feeds/ipo-calendar.ics",
+	} {
+		routeCatalog.Describe(fiber.MethodGet, "/api/v1"+path, responseCachePolicy)
+	}
+	routeCatalog.Describe(fiber.MethodGet, "/api/v1/analytics/leaderboard", middleware.RoutePolicy{Cache: "leaderboard until IST midnight"})
+	for _, path := range []string{"/ipos/:id", "/ipos/slug/:slug"} {
+		routeCatalog.Describe(fiber.MethodGet, "/api/v1"+path, middleware.RoutePolicy{Cache: "ipo_cache 15m0s"})
+	}
+
+	// Public status page: data freshness and source health
+	api.Get("/status", responseCache.Handler(), statusHandler.GetStatus)
 
 	// IPO Routes
-	api.Get("/ipos", ipoHandler.GetIPOs)
-	api.Get("/ipos/active", ipoHandler.GetActiveIPOs)
-	api.Get("/ipos/active-with-gmp", ipoHandler.GetActiveIPOsWithGMP) // New: Returns active IPOs with GMP data joined
+	api.Get("/ipos", responseEnvelope.Handler(ipoListData), responseCache.Handler(), ipoHandler.GetIPOs)
+	api.Get("/ipos/active", responseEnvelope.Handler(ipoListData), responseCache.Handler(), ipoHandler.GetActiveIPOs)
+	api.Get("/ipos/active-with-gmp", responseEnvelope.Handler(ipoListData, gmpData), ipoHandler.GetActiveIPOsWithGMP) // New: Returns active IPOs with GMP data joined
+	api.Get("/ipos/trending", responseEnvelope.Handler(ipoListData), hotnessHandler.GetTrendingIPOs)
+	api.Get("/ipos/batch", ipoHandler.GetIPOsBatch)
+	api.Get("/ipos/status-labels", ipoHandler.GetStatusLabels)
 	api.Get("/ipos/:ipo_id/form-config", ipoHandler.GetIPOFormConfig)
+	api.Get("/ipos/slug/:slug", ipoHandler.GetIPOBySlug)
 	api.Get("/ipos/:id/gmp", gmpHandler.GetGMPByIPO)
+	api.Get("/ipos/:id/quote", quoteHandler.GetIPOQuote)
+	api.Get("/ipos/:id/subscription/history", responseCache.Handler(), subscriptionHandler.GetSubscriptionHistory)
+	api.Get("/ipos/:id/faq", responseCache.Handler(), ipoHandler.GetIPOFAQ)
+	api.Get("/ipos/:id/allotment-probability", allotmentRatioHandler.GetAllotmentProbability)
 	api.Get("/ipos/:id/with-gmp", ipoHandler.GetIPOByIDWithGMP) // New: Returns single IPO with GMP data joined
 	api.Get("/ipos/:id", ipoHandler.GetIPOByID)
 
+	// GMP Routes
+	api.Get("/gmp", responseEnvelope.Handler(gmpData), responseCache.Handler(), gmpHandler.GetGMPByIPOs)
+
 	// Market Routes
-	api.Get("/market/indices", marketHandler.GetMarketIndices)
+	api.Get("/market/indices", responseCache.Handler(), marketHandler.GetMarketIndices)
+
+	// Analytics Routes
+	api.Get("/analytics/lockin-calendar", responseCache.Handler(), analyticsHandler.GetLockInCalendar)
+	api.Get("/analytics/screener", responseEnvelope.Handler(ipoListData), responseCache.Handler(), analyticsHandler.GetScreener)
+	api.Get("/analytics/leaderboard", analyticsHandler.GetLeaderboard)
+
+	// Feed Routes (RSS and iCalendar subscriptions)
+	api.Get("/feeds/ipos.rss", responseCache.Handler(), feedHandler.GetIPOsRSS)
+	api.Get("/feeds/ipo-calendar.ics", responseCache.Handler(), feedHandler.GetIPOCalendar)
+
+	// Export Routes (API-key gated research dumps, streamed as NDJSON)
+	api.Get("/export/ipos", exportAuth.Handler(), exportHandler.ExportIPOs)
+	routeCatalog.Describe(fiber.MethodGet, "/api/v1/export/ipos", middleware.RoutePolicy{
+		Auth:      "api_key",
+		RateLimit: fmt.Sprintf("%d requests per key per minute", exportRateLimit),
+	})
 
 	// Cache Routes
-	api.Post("/cache/store", cacheHandler.StoreResult)
-	api.Get("/cache/:ipo_id/:pan_hash", cacheHandler.GetCachedResult)
+	api.Post("/cache/store", bodyValidation.Handler(), cacheStoreSignatures.Handler(), accountAuth, cacheHandler.StoreResult)
+	routeCatalog.Describe(fiber.MethodPost, "/api/v1/cache/store", middleware.RoutePolicy{Auth: "signed_request"})
+	api.Post("/cache/device-token", cacheHandler.IssueDeviceToken)
+	api.Get("/cache/:ipo_id/:pan_hash", accountAuth, cacheHandler.GetCachedResult)
+	routeCatalog.Describe(fiber.MethodGet, "/api/v1/cache/:ipo_id/:pan_hash", middleware.RoutePolicy{Auth: "account_or_device_token_optional"})
+
+	// Check Route; results of signed-in or device token checks are bound to the owner
+	api.Post("/check", bodyValidation.Handler(), accountAuth, checkHandler.CheckAllotment)
+	api.Get("/check/:check_id", checkHandler.GetCheckStatus)
+	api.Post("/check/:check_id/feedback", bodyValidation.Handler(), checkHandler.SubmitCheckFeedback)
+	routeCatalog.Describe(fiber.MethodPost, "/api/v1/check", checkQuotaPolicy)
+
+	// Telegram Bot Route (webhook mode only)
+	if telegramHandler != nil {
+		api.Post("/telegram/webhook", telegramHandler.Webhook)
+		routeCatalog.Describe(fiber.MethodPost, "/api/v1/telegram/webhook", middleware.RoutePolicy{Auth: "telegram_secret"})
+	}
+
+	// Account Routes (end-user identity, separate from admin access)
+	auth := api.Group("/auth", bodyValidation.Handler(), accountAuth)
+	auth.Post("/login", authHandler.StartLogin)
+	auth.Post("/verify", authHandler.VerifyLogin)
+	auth.Get("/me", middleware.RequireAccount(), authHandler.GetMe)
+	auth.Post("/logout", middleware.RequireAccount(), authHandler.Logout)
+	routeCatalog.DescribePrefix("/api/v1/auth", middleware.RoutePolicy{Auth: "account_optional"})
+	routeCatalog.Describe(fiber.MethodPost, "/api/v1/auth/login", middleware.RoutePolicy{RateLimit: "5 challenges per destination per 15m"})
+	routeCatalog.Describe(fiber.MethodGet, "/api/v1/auth/me", middleware.RoutePolicy{Auth: "account"})
+	routeCatalog.Describe(fiber.MethodPost, "/api/v1/auth/logout", middleware.RoutePolicy{Auth: "account"})
 
-	// Check Route
-	api.Post("/check", checkHandler.CheckAllotment)
+	// Weekly Digest Routes (the email subscription belongs to the signed-in account)
+	api.Get("/digest/weekly", responseCache.Handler(), digestHandler.GetWeeklyDigest)
+	digest := api.Group("/digest/subscription", accountAuth, middleware.RequireAccount())
+	digest.Get("", digestHandler.GetSubscription)
+	digest.Put("", digestHandler.Subscribe)
+	digest.Delete("", digestHandler.Unsubscribe)
+	routeCatalog.DescribePrefix("/api/v1/digest/subscription", middleware.RoutePolicy{Auth: "account"})
+
+	// Saved PAN Vault Routes (opt-in, keyed by the signed-in account or the X-Device-ID header)
+	vault := api.Group("/vault", bodyValidation.Handler(), accountAuth)
+	vault.Get("/pans", vaultHandler.GetSavedPANs)
+	vault.Post("/pans", vaultHandler.AddSavedPAN)
+	vault.Post("/pans/check", vaultHandler.CheckSavedPANs)
+	vault.Delete("/pans/:id", vaultHandler.RemoveSavedPAN)
+	routeCatalog.DescribePrefix("/api/v1/vault", middleware.RoutePolicy{Auth: "account_or_device_id"})
+	routeCatalog.Describe(fiber.MethodPost, "/api/v1/vault/pans/check", checkQuotaPolicy)
 
 	// Admin Routes
-	admin := api.Group("/admin")
+	admin := api.Group("/admin", adminAllowlist.Handler(), bodyValidation.Handler())
+	routeCatalog.DescribePrefix("/api/v1/admin", middleware.RoutePolicy{Auth: "admin_ip_allowlist"})
 	// TODO: Add auth middleware
+	admin.Get("/dashboard", dashboardHandler.GetDashboard)
 	admin.Post("/ipos", adminHandler.CreateIPO)
+	admin.Get("/ipos/completeness", adminHandler.GetIPOCompleteness)
+	admin.Post("/ipos/merge", adminHandler.MergeIPOs)
+	admin.Get("/ipos/:id", adminHandler.GetIPO)
+	admin.Get("/ipos/:id/as-of", adminHandler.GetIPOAsOf)
+	admin.Put("/ipos/:id/gmp", adminHandler.SetGMPOverride)
+	admin.Put("/ipos/:id/manual-check", adminHandler.SetManualCheck)
+	admin.Delete("/ipos/:id/manual-check", adminHandler.ClearManualCheck)
+	admin.Put("/ipos/:id/close-cutoffs", adminHandler.SetCloseCutoffs)
+	admin.Get("/ipos/:id/extraction-override", adminHandler.GetExtractionOverride)
+	admin.Put("/ipos/:id/extraction-override", adminHandler.SetExtractionOverride)
+	admin.Delete("/ipos/:id/extraction-override", adminHandler.ClearExtractionOverride)
+	admin.Post("/ipos/:id/basis-of-allotment", allotmentRatioHandler.IngestBasisOfAllotment)
 	admin.Post("/gmp/update", adminHandler.TriggerGMPUpdate)
 	admin.Get("/gmp/data", adminHandler.GetGMPData)
+	admin.Patch("/gmp/:company_code", adminHandler.PatchGMP)
+	admin.Delete("/cache", performanceHandler.InvalidateCache)
+	admin.Get("/jobs/locks", jobsHandler.GetJobLocks)
+	admin.Get("/jobs/dependencies", jobsHandler.GetJobDependencies)
+	admin.Get("/checks/recent", checkHandler.GetRecentChecks)
+	admin.Get("/scraper/patterns", scraperPatternHandler.GetPatterns)
+	admin.Post("/scraper/patterns", scraperPatternHandler.AddPattern)
+	admin.Post("/scraper/patterns/test", scraperPatternHandler.TestPattern)
+	admin.Delete("/scraper/patterns/:id", scraperPatternHandler.RemovePattern)
+	admin.Get("/scraper/list-sources", scrapeRunHandler.GetIPOListSources)
+	admin.Get("/scraper/shadow", scrapeRunHandler.GetShadowExtraction)
+	admin.Delete("/scraper/shadow", scrapeRunHandler.ResetShadowExtraction)
+	admin.Get("/scrape-runs", scrapeRunHandler.GetScrapeRuns)
+	admin.Get("/scrape-runs/:id", scrapeRunHandler.GetScrapeRun)
+	admin.Post("/signed-urls", signedURLHandler.CreateSignedURL)
+	admin.Get("/debug/http", debugHandler.GetHTTPRecordings)
+	admin.Delete("/debug/http", debugHandler.ClearHTTPRecordings)
+	admin.Get("/debug/status-at", debugHandler.SimulateStatuses)
+
+	// Signed URL Routes (single-use links issued by /admin/signed-urls, no other auth)
+	signed := api.Group("/signed", signedURLs.Handler())
+	routeCatalog.DescribePrefix("/api/v1/signed", middleware.RoutePolicy{Auth: "signed_url"})
+	signed.Get("/export/ipos", exportHandler.ExportIPOs)
+	signed.Get("/scrape-runs/:id", scrapeRunHandler.GetScrapeRun)
 
 	// Performance Routes
 	perf := api.Group("/performance")
@@ -172,6 +639,17 @@ func main() {
 	perf.Delete("/cache", performanceHandler.ClearCache)
 	perf.Post("/cache/warmup", performanceHandler.WarmupCache)
 
+	// Route introspection for ops and partners
+	routesHandler := handlers.NewRoutesHandler(app, routeCatalog)
+	api.Get("/routes", adminAllowlist.Handler(), routesHandler.GetRoutes)
+	routeCatalog.Describe(fiber.MethodGet, "/api/v1/routes", middleware.RoutePolicy{Auth: "admin_ip_allowlist"})
+
+	// Admin UI (static pages built into the binary that call the admin routes above)
+	adminUIHandler := handlers.NewAdminUIHandler()
+	app.Get("/admin/ui", adminAllowlist.Handler(), adminUIHandler.GetAsset)
+	app.Get("/admin/ui/*", adminAllowlist.Handler(), adminUIHandler.GetAsset)
+	routeCatalog.DescribePrefix("/admin/ui", middleware.RoutePolicy{Auth: "admin_ip_allowlist"})
+
 	// Start server
 	log.Printf("Server starting on port %s", cfg.ServerPort)
 	if err := app.Listen(":" + cfg.ServerPort); err != nil {
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// accountLocalsKey is the fiber.Ctx locals key holding the signed-in account
+const accountLocalsKey = "account"
+
+// AccountResolver looks up the account behind a session token; nil means no valid session
+type AccountResolver interface {
+	ResolveSession(ctx context.Context, token string) (*models.Account, error)
+}
+
+// AttachAccount resolves an "Authorization: Bearer <token>" session and attaches the
+// account to the request. Requests without the header continue anonymously; an invalid
+// or expired token is rejected with 401 so clients know to sign in again. This is end-user
+// identity only and grants no admin access.
+func AttachAccount(resolver AccountResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return c.Next()
+		}
+
+		token, ok := bearerToken(header)
+		if !ok {
+			return unauthorized(c, "Authorization header must be a bearer token")
+		}
+
+		account, err := resolver.ResolveSession(c.Context(), token)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to resolve account session")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to resolve session",
+			})
+		}
+		if account == nil {
+			return unauthorized(c, "Invalid or expired session")
+		}
+
+		c.Locals(accountLocalsKey, account)
+		return c.Next()
+	}
+}
+
+// RequireAccount rejects requests that AttachAccount did not sign in
+func RequireAccount() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if AccountFromContext(c) == nil {
+			return unauthorized(c, "Sign-in required")
+		}
+		return c.Next()
+	}
+}
+
+// AccountFromContext returns the signed-in account, or nil for anonymous requests
+func AccountFromContext(c *fiber.Ctx) *models.Account {
+	account, _ := c.Locals(accountLocalsKey).(*models.Account)
+	return account
+}
+
+// BearerTokenFromContext returns the request's bearer token, if any
+func BearerTokenFromContext(c *fiber.Ctx) string {
+	token, _ := bearerToken(c.Get(fiber.HeaderAuthorization))
+	return token
+}
+
+// bearerToken extracts the token from an Authorization header value
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// unauthorized writes a 401 response
+func unauthorized(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader carries the key for API-key gated routes
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth gates routes behind a fixed set of API keys, with a per-key request rate limit
+type APIKeyAuth struct {
+	keyHashes []string
+	interval  time.Duration
+	limiter   *shared.TokenBucketLimiter
+}
+
+// NewAPIKeyAuth accepts the given keys, each allowed requestsPerMinute requests. With no
+// keys the gated routes answer 503.
+func NewAPIKeyAuth(keys []string, requestsPerMinute int) *APIKeyAuth {
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = 5
+	}
+
+	auth := &APIKeyAuth{interval: time.Minute / time.Duration(requestsPerMinute)}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			auth.keyHashes = append(auth.keyHashes, hashAPIKey(key))
+		}
+	}
+	auth.limiter = shared.NewTokenBucketLimiter(auth.interval, requestsPerMinute)
+	return auth
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Handler rejects requests without a known X-API-Key (401) or over the key's rate (429)
+func (a *APIKeyAuth) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(a.keyHashes) == 0 {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error":   "API key access is not enabled",
+			})
+		}
+
+		key := c.Get(APIKeyHeader)
+		if key == "" {
+			return unauthorized(c, "X-API-Key header required")
+		}
+		hash := hashAPIKey(key)
+		known := false
+		for _, candidate := range a.keyHashes {
+			if subtle.ConstantTimeCompare([]byte(hash), []byte(candidate)) == 1 {
+				known = true
+			}
+		}
+		if !known {
+			return unauthorized(c, "Invalid API key")
+		}
+
+		if !a.limiter.Allow(hash) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(a.interval.Seconds())+1))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error":   "API key rate limit exceeded",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyValidation hardens write endpoints that accept JSON. Requests with a body must
+// declare an application/json content type and stay under MaxBytes; empty bodies pass
+// through so trigger-style endpoints (e.g. POST /admin/gmp/update) keep working.
+type BodyValidation struct {
+	MaxBytes int
+}
+
+// NewBodyValidation creates body validation middleware accepting bodies up to maxBytes
+func NewBodyValidation(maxBytes int) *BodyValidation {
+	if maxBytes <= 0 {
+		maxBytes = 64 * 1024
+	}
+	return &BodyValidation{MaxBytes: maxBytes}
+}
+
+// Handler returns Fiber middleware enforcing the size and content-type rules on
+// POST, PUT and PATCH requests
+func (bv *BodyValidation) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		size := len(c.Body())
+		if declared := c.Request().Header.ContentLength(); declared > size {
+			size = declared
+		}
+		if size == 0 {
+			return c.Next()
+		}
+
+		if size > bv.MaxBytes {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"success": false,
+				"error":   fmt.Sprintf("request body exceeds %d bytes", bv.MaxBytes),
+			})
+		}
+
+		if !isJSONContentType(c.Get(fiber.HeaderContentType)) {
+			return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+				"success": false,
+				"error":   "Content-Type must be application/json",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// isJSONContentType reports whether a Content-Type header names JSON, ignoring parameters
+// such as charset
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.ToLower(strings.SplitN(contentType, ";", 2)[0]))
+	return mediaType == fiber.MIMEApplicationJSON
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// IPAllowlist admits only clients whose address is inside one of its networks. With no
+// networks every client is admitted, so the allowlist is opt-in.
+type IPAllowlist struct {
+	networks []*net.IPNet
+	// ClientIPHeader, when set, names the header a trusted reverse proxy writes the client
+	// address to (e.g. X-Forwarded-For). The last address in it is used, since that is
+	// the one the proxy appended; earlier ones are client-supplied. The header is only
+	// read on connections from trustedProxies.
+	ClientIPHeader string
+	trustedProxies []*net.IPNet
+	logger         *logrus.Entry
+}
+
+// NewIPAllowlist parses entries as CIDR networks or single IP addresses
+func NewIPAllowlist(entries []string) (*IPAllowlist, error) {
+	networks, err := parseNetworks(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &IPAllowlist{networks: networks, logger: logrus.WithField("component", "admin_ip_allowlist")}, nil
+}
+
+// SetTrustedProxies parses entries, CIDR networks or single IP addresses, as the reverse
+// proxies whose ClientIPHeader is believed. Without any the header is never read.
+func (a *IPAllowlist) SetTrustedProxies(entries []string) error {
+	networks, err := parseNetworks(entries)
+	if err != nil {
+		return err
+	}
+	a.trustedProxies = networks
+	return nil
+}
+
+// parseNetworks parses entries as CIDR networks or single IP addresses, skipping blanks
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Enabled reports whether the allowlist restricts anyone
+func (a *IPAllowlist) Enabled() bool {
+	return len(a.networks) > 0
+}
+
+// Allows reports whether ip is inside one of the networks
+func (a *IPAllowlist) Allows(ip net.IP) bool {
+	return networksContain(a.networks, ip)
+}
+
+// networksContain reports whether ip is inside one of networks
+func networksContain(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address the allowlist checks for a request: the one in
+// ClientIPHeader when the connection comes from a trusted proxy, the connection's own
+// address otherwise
+func (a *IPAllowlist) clientIP(c *fiber.Ctx) string {
+	if a.ClientIPHeader != "" && networksContain(a.trustedProxies, net.ParseIP(c.IP())) {
+		if value := c.Get(a.ClientIPHeader); value != "" {
+			addresses := strings.Split(value, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+	return c.IP()
+}
+
+// Handler rejects clients outside the allowlist with 403, echoing the address that was
+// checked, and logs each denied attempt for auditing
+func (a *IPAllowlist) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !a.Enabled() {
+			return c.Next()
+		}
+
+		clientIP := a.clientIP(c)
+		if a.Allows(net.ParseIP(clientIP)) {
+			return c.Next()
+		}
+
+		a.logger.WithFields(logrus.Fields{
+			"event":      "admin_access_denied",
+			"client_ip":  clientIP,
+			"remote_ip":  c.IP(),
+			"method":     c.Method(),
+			"path":       c.Path(),
+			"user_agent": c.Get(fiber.HeaderUserAgent),
+		}).Warn("Denied admin request from address outside the allowlist")
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success":   false,
+			"error":     "Access from this address is not allowed",
+			"code":      "ip_not_allowed",
+			"client_ip": clientIP,
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// ResponseCache caches successful GET responses in memory for a short TTL.
+// Entries are keyed by path and normalized query string, so requests that differ
+// only in parameter order share an entry while different "fields" selections do not.
+type ResponseCache struct {
+	TTL        time.Duration
+	MaxEntries int
+
+	mutex    sync.RWMutex
+	entries  map[string]*cachedResponse
+	hits     int64
+	misses   int64
+	counters shared.CacheCounters
+}
+
+// responseCacheHeaders are the response headers that describe the content and are
+// replayed with it on a hit. Other headers, such as request IDs or rate limits, are set
+// per request by other middleware and are left alone.
+var responseCacheHeaders = []string{
+	fiber.HeaderContentDisposition,
+	fiber.HeaderContentLanguage,
+	fiber.HeaderCacheControl,
+}
+
+// cachedResponse is a stored copy of a handler response
+type cachedResponse struct {
+	status      int
+	contentType string
+	headers     map[string]string // responseCacheHeaders the handler set
+	body        []byte
+	storedAt    time.Time
+	expiresAt   time.Time
+	hits        atomic.Int64 // Times served since stored
+}
+
+// NewResponseCache creates a response cache with the given TTL
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &ResponseCache{
+		TTL:        ttl,
+		MaxEntries: 1000,
+		entries:    make(map[string]*cachedResponse),
+	}
+}
+
+// Handler returns Fiber middleware that serves cached responses for GET requests
+func (rc *ResponseCache) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+
+		key := responseCacheKey(c)
+		now := time.Now()
+
+		rc.mutex.RLock()
+		entry, exists := rc.entries[key]
+		rc.mutex.RUnlock()
+
+		if exists && now.Before(entry.expiresAt) {
+			rc.recordLookup(true)
+			rc.counters.Hit(responseCacheFamily(key))
+			entry.hits.Add(1)
+			c.Set("X-Cache", "HIT")
+			c.Set(fiber.HeaderAge, strconv.Itoa(int(now.Sub(entry.storedAt).Seconds())))
+			c.Locals(cacheGeneratedAtKey, entry.storedAt)
+			c.Set(fiber.HeaderContentType, entry.contentType)
+			for name, value := range entry.headers {
+				c.Set(name, value)
+			}
+			return c.Status(entry.status).Send(entry.body)
+		}
+		rc.recordLookup(false)
+		if exists {
+			rc.counters.Expired(responseCacheFamily(key))
+		} else {
+			rc.counters.Miss(responseCacheFamily(key))
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		c.Set("X-Cache", "MISS")
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+
+		// The response body buffer is reused by Fiber, so store a copy
+		body := append([]byte(nil), c.Response().Body()...)
+		headers := make(map[string]string)
+		for _, name := range responseCacheHeaders {
+			// Fiber reuses the header buffer, so keep a copy
+			if value := c.GetRespHeader(name); value != "" {
+				headers[name] = strings.Clone(value)
+			}
+		}
+		rc.store(key, &cachedResponse{
+			status:      fiber.StatusOK,
+			contentType: string(c.Response().Header.ContentType()),
+			headers:     headers,
+			body:        body,
+			storedAt:    now,
+			expiresAt:   now.Add(rc.TTL),
+		})
+		return nil
+	}
+}
+
+// Invalidate drops every cached response; called after jobs write new data
+func (rc *ResponseCache) Invalidate() {
+	if rc == nil {
+		return
+	}
+
+	rc.mutex.Lock()
+	count := len(rc.entries)
+	rc.entries = make(map[string]*cachedResponse)
+	rc.mutex.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"component": "ResponseCache",
+		"entries":   count,
+	}).Debug("Invalidated response cache")
+}
+
+// InvalidatePrefix drops cached responses whose key (request path and query) starts
+// with prefix and returns how many were dropped
+func (rc *ResponseCache) InvalidatePrefix(prefix string) int {
+	if rc == nil {
+		return 0
+	}
+
+	rc.mutex.Lock()
+	count := 0
+	for key := range rc.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(rc.entries, key)
+			count++
+		}
+	}
+	rc.mutex.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"component": "ResponseCache",
+		"prefix":    prefix,
+		"entries":   count,
+	}).Debug("Invalidated response cache prefix")
+	return count
+}
+
+// GetStats returns entry count and hit/miss counters
+func (rc *ResponseCache) GetStats() map[string]interface{} {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	hitRate := 0.0
+	if total := rc.hits + rc.misses; total > 0 {
+		hitRate = float64(rc.hits) / float64(total)
+	}
+
+	return map[string]interface{}{
+		"entries":     len(rc.entries),
+		"hits":        rc.hits,
+		"misses":      rc.misses,
+		"hit_rate":    hitRate,
+		"ttl_seconds": rc.TTL.Seconds(),
+	}
+}
+
+// Stats reports the cached responses, their memory and lookups per family, with the top
+// most served keys. A family is the first three segments of the path, such as
+// "/api/v1/ipos".
+func (rc *ResponseCache) Stats(top int) shared.CacheLayerStats {
+	rc.mutex.RLock()
+	entries := make([]shared.CacheEntryInfo, 0, len(rc.entries))
+	for key, entry := range rc.entries {
+		entries = append(entries, shared.CacheEntryInfo{
+			Key:       key,
+			Family:    responseCacheFamily(key),
+			Bytes:     int64(len(key) + len(entry.contentType) + len(entry.body) + shared.CacheEntryOverheadBytes),
+			Hits:      entry.hits.Load(),
+			ExpiresAt: entry.expiresAt,
+		})
+	}
+	rc.mutex.RUnlock()
+
+	return rc.counters.Snapshot("response", rc.MaxEntries, entries, top, time.Now())
+}
+
+// responseCacheFamily is the first three segments of a response cache key's path
+func responseCacheFamily(key string) string {
+	path, _, _ := strings.Cut(key, "?")
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 4)
+	if len(segments) > 3 {
+		segments = segments[:3]
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// store saves an entry, evicting expired entries first when the cache is full
+func (rc *ResponseCache) store(key string, entry *cachedResponse) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if len(rc.entries) >= rc.MaxEntries {
+		now := time.Now()
+		for existingKey, existing := range rc.entries {
+			if now.After(existing.expiresAt) {
+				delete(rc.entries, existingKey)
+				rc.counters.Evict(responseCacheFamily(existingKey))
+			}
+		}
+		if len(rc.entries) >= rc.MaxEntries {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// recordLookup updates the hit/miss counters
+func (rc *ResponseCache) recordLookup(hit bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	if hit {
+		rc.hits++
+	} else {
+		rc.misses++
+	}
+}
+
+// responseCacheKey builds a cache key from the path and sorted query parameters
+func responseCacheKey(c *fiber.Ctx) string {
+	var params []string
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		params = append(params, string(key)+"="+string(value))
+	})
+	sort.Strings(params)
+
+	return c.Path() + "?" + strings.Join(params, "&")
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// cacheGeneratedAtKey is the Fiber local holding when a response served from the response
+// cache was generated
+const cacheGeneratedAtKey = "response_cache_generated_at"
+
+// freshnessCheckInterval is how long a data source's last update time is reused before it
+// is read again, so busy list endpoints do not query it on every request
+const freshnessCheckInterval = 30 * time.Second
+
+// ResponseMeta is the metadata block added to list responses
+type ResponseMeta struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Cache       string    `json:"cache"` // hit when served from the response cache, otherwise miss
+	Stale       bool      `json:"stale"`
+	Count       int       `json:"count"`
+}
+
+// DataSource is the data behind a group of endpoints. Responses built from it are stale
+// when it was last updated more than StaleAfter ago.
+type DataSource struct {
+	Name        string
+	StaleAfter  time.Duration
+	LastUpdated func(ctx context.Context) (*time.Time, error)
+
+	mutex     sync.Mutex
+	updatedAt *time.Time
+	checkedAt time.Time
+}
+
+// stale reports whether the source is older than StaleAfter. A source that has never been
+// updated is stale; one whose update time cannot be read is not, so a database hiccup
+// does not mark every response stale.
+func (s *DataSource) stale(ctx context.Context, now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.checkedAt) >= freshnessCheckInterval {
+		updatedAt, err := s.LastUpdated(ctx)
+		if err != nil {
+			logrus.WithError(err).WithField("source", s.Name).Warn("Failed to read data freshness for response meta")
+			return false
+		}
+		s.updatedAt, s.checkedAt = updatedAt, now
+	}
+	return s.updatedAt == nil || now.Sub(*s.updatedAt) > s.StaleAfter
+}
+
+// ResponseEnvelope adds a "meta" block to successful JSON list responses, next to their
+// "data": when the response was generated, whether it came from the response cache,
+// whether its data is stale and how many items it holds. Existing fields are left as
+// they are, so clients that ignore meta see the same payload.
+type ResponseEnvelope struct {
+	// Enabled turns the meta block on; when off the handler passes responses through
+	Enabled bool
+}
+
+func NewResponseEnvelope(enabled bool) *ResponseEnvelope {
+	return &ResponseEnvelope{Enabled: enabled}
+}
+
+// Handler returns middleware adding meta to the responses of the routes it wraps. It
+// must run before the response cache so that cached responses get their cache status
+// added on every request. The response is stale when any of sources is.
+func (e *ResponseEnvelope) Handler(sources ...*DataSource) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !e.Enabled {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK ||
+			!bytes.HasPrefix(c.Response().Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+			return nil
+		}
+
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+			return nil
+		}
+		data, ok := body["data"]
+		if !ok {
+			return nil
+		}
+
+		now := time.Now()
+		meta := ResponseMeta{GeneratedAt: now, Cache: "miss", Count: responseCount(body["count"], data)}
+		if generatedAt, ok := c.Locals(cacheGeneratedAtKey).(time.Time); ok {
+			meta.GeneratedAt, meta.Cache = generatedAt, "hit"
+		}
+		for _, source := range sources {
+			if source.stale(c.Context(), now) {
+				meta.Stale = true
+				break
+			}
+		}
+
+		encodedMeta, err := json.Marshal(meta)
+		if err != nil {
+			return nil
+		}
+		body["meta"] = encodedMeta
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil
+		}
+		c.Response().SetBodyRaw(encoded)
+		return nil
+	}
+}
+
+// responseCount is the response's own count when it has one, otherwise the length of data
+// when it is a list or keyed object, and 1 for a single value
+func responseCount(count, data json.RawMessage) int {
+	if count != nil {
+		if parsed, err := strconv.Atoi(string(count)); err == nil {
+			return parsed
+		}
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(data, &list) == nil {
+		return len(list)
+	}
+	var keyed map[string]json.RawMessage
+	if json.Unmarshal(data, &keyed) == nil {
+		return len(keyed)
+	}
+	if string(data) == "null" {
+		return 0
+	}
+	return 1
+}
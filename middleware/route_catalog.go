@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutePolicy describes how requests to a route are authenticated, rate limited and
+// cached. An empty field means the route has no such policy.
+type RoutePolicy struct {
+	Auth      string `json:"auth,omitempty"`
+	RateLimit string `json:"rate_limit,omitempty"`
+	Cache     string `json:"cache,omitempty"`
+}
+
+// RouteInfo is a registered route with its policy
+type RouteInfo struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Auth      string `json:"auth"` // "none" when the route is open
+	RateLimit string `json:"rate_limit,omitempty"`
+	Cache     string `json:"cache,omitempty"`
+}
+
+// describedPolicy is a policy described for one route, or for every route under a path
+// prefix when method is empty
+type describedPolicy struct {
+	method string
+	path   string
+	policy RoutePolicy
+}
+
+// RouteCatalog keeps the policies described for routes at setup time and lists the
+// app's routes with them, so the API surface can be inspected at runtime. It is safe for
+// concurrent use.
+type RouteCatalog struct {
+	mutex    sync.RWMutex
+	policies []describedPolicy
+}
+
+func NewRouteCatalog() *RouteCatalog {
+	return &RouteCatalog{}
+}
+
+// DescribePrefix sets the policy of every route at or under prefix, e.g. a route group.
+// Fields left empty are inherited from shorter prefixes.
+func (rc *RouteCatalog) DescribePrefix(prefix string, policy RoutePolicy) {
+	rc.describe("", prefix, policy)
+}
+
+// Describe sets the policy of one route. Fields left empty are inherited from the
+// prefixes it is under.
+func (rc *RouteCatalog) Describe(method, path string, policy RoutePolicy) {
+	rc.describe(strings.ToUpper(method), path, policy)
+}
+
+func (rc *RouteCatalog) describe(method, path string, policy RoutePolicy) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	rc.policies = append(rc.policies, describedPolicy{method: method, path: strings.TrimSuffix(path, "/"), policy: policy})
+}
+
+// Routes lists the app's routes sorted by path and method, each with its policy. HEAD
+// routes Fiber adds for every GET are left out.
+func (rc *RouteCatalog) Routes(app *fiber.App) []RouteInfo {
+	seen := make(map[string]bool)
+	routes := []RouteInfo{}
+	for _, route := range app.GetRoutes(true) {
+		key := route.Method + " " + route.Path
+		if route.Method == fiber.MethodHead || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		policy := rc.policyOf(route.Method, route.Path)
+		if policy.Auth == "" {
+			policy.Auth = "none"
+		}
+		routes = append(routes, RouteInfo{
+			Method:    route.Method,
+			Path:      route.Path,
+			Auth:      policy.Auth,
+			RateLimit: policy.RateLimit,
+			Cache:     policy.Cache,
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// policyOf merges the policies that apply to a route: prefixes from shortest to longest,
+// then the route's own, each overriding the fields it sets
+func (rc *RouteCatalog) policyOf(method, path string) RoutePolicy {
+	rc.mutex.RLock()
+	var prefixes []describedPolicy
+	var own []describedPolicy
+	for _, described := range rc.policies {
+		switch {
+		case described.method == "" && underPrefix(path, described.path):
+			prefixes = append(prefixes, described)
+		case described.method == method && described.path == path:
+			own = append(own, described)
+		}
+	}
+	rc.mutex.RUnlock()
+
+	sort.SliceStable(prefixes, func(i, j int) bool { return len(prefixes[i].path) < len(prefixes[j].path) })
+	var merged RoutePolicy
+	for _, described := range append(prefixes, own...) {
+		if described.policy.Auth != "" {
+			merged.Auth = described.policy.Auth
+		}
+		if described.policy.RateLimit != "" {
+			merged.RateLimit = described.policy.RateLimit
+		}
+		if described.policy.Cache != "" {
+			merged.Cache = described.policy.Cache
+		}
+	}
+	return merged
+}
+
+// underPrefix reports whether path is prefix or below it, matching whole path segments
+func underPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Login channels; an account is identified by the email or phone it signed in with
+const (
+	LoginChannelEmail = "email" // Magic link sent by email
+	LoginChannelSMS   = "sms"   // One-time code sent by SMS
+)
+
+// Account is an end-user account, separate from admin access
+type Account struct {
+	ID          uuid.UUID  `json:"id"`
+	Email       *string    `json:"email,omitempty"`
+	Phone       *string    `json:"phone,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// LoginChallenge is a pending magic link or OTP login, as returned to the client
+type LoginChallenge struct {
+	ID          uuid.UUID `json:"challenge_id"`
+	Channel     string    `json:"channel"`
+	Destination string    `json:"destination"` // Masked email or phone
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AccountSession is a bearer session issued after a successful login. Token is only
+// populated in the login response; the database stores its hash.
+type AccountSession struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Account   *Account  `json:"account"`
+}
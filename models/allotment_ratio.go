@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Allotment probability sources: the registrar's published basis of allotment, or an
+// estimate from the latest subscription multiples before it is out
+const (
+	AllotmentSourceBasisOfAllotment = "basis_of_allotment"
+	AllotmentSourceSubscription     = "subscription_estimate"
+)
+
+// AllotmentRatio is the official allotment ratio of one investor category, parsed from
+// the basis of allotment. Ratio is the lottery for a minimum application, e.g. "7:169"
+// means 7 of every 169 applicants got one lot.
+type AllotmentRatio struct {
+	Category          string    `json:"category"`
+	SubscriptionTimes *float64  `json:"subscription_times,omitempty"`
+	Ratio             string    `json:"ratio"`
+	Allottees         int       `json:"allottees"`
+	Applicants        int       `json:"applicants"`
+	SourceURL         string    `json:"source_url,omitempty"`
+	IngestedAt        time.Time `json:"ingested_at"`
+}
+
+// Bases of an estimated category probability
+const (
+	AllotmentBasisApplications = "applications_per_lot"
+	AllotmentBasisSubscription = "subscription_multiple"
+)
+
+// CategoryAllotmentProbability is the chance that a minimum application in a category
+// gets one lot
+type CategoryAllotmentProbability struct {
+	Category          string   `json:"category"`
+	Probability       float64  `json:"probability"` // 0 to 1
+	Ratio             string   `json:"ratio,omitempty"`
+	SubscriptionTimes *float64 `json:"subscription_times,omitempty"`
+	// Basis says what an estimate was computed from; empty for official ratios
+	Basis        string `json:"basis,omitempty"`
+	Applications *int64 `json:"applications,omitempty"`
+	// ApplicationsPerLot is applications per minimum lot offered in the category; above 1
+	// the category's lots go by lottery
+	ApplicationsPerLot *float64 `json:"applications_per_lot,omitempty"`
+}
+
+// AllotmentProbability is an IPO's per-category allotment chances. Official is true once
+// the basis of allotment was ingested; before that the chances are estimates.
+type AllotmentProbability struct {
+	IPOID      uuid.UUID                      `json:"ipo_id"`
+	Official   bool                           `json:"official"`
+	Source     string                         `json:"source"`
+	SourceURL  string                         `json:"source_url,omitempty"`
+	AsOf       *time.Time                     `json:"as_of,omitempty"`
+	Categories []CategoryAllotmentProbability `json:"categories"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Anchor lock-in tranches: half the anchor shares unlock after 30 days, the rest after 90
+const (
+	LockInTranche30Day = "30_day"
+	LockInTranche90Day = "90_day"
+)
+
+// AnchorAllocation is the anchor investor portion of an IPO and its lock-in expiries
+type AnchorAllocation struct {
+	IPOID           uuid.UUID  `json:"ipo_id"`
+	BidDate         *time.Time `json:"bid_date,omitempty"`
+	SharesOffered   *int       `json:"shares_offered,omitempty"`
+	AmountCrore     *float64   `json:"amount_crore,omitempty"`
+	LockIn30Date    *time.Time `json:"lockin_30_date,omitempty"`
+	LockIn90Date    *time.Time `json:"lockin_90_date,omitempty"`
+	LockInEstimated bool       `json:"lockin_estimated"` // Dates computed from the listing date, not scraped
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// LockInExpiry is one anchor lock-in tranche expiring on a given date
+type LockInExpiry struct {
+	IPOID           uuid.UUID `json:"ipo_id"`
+	Name            string    `json:"name"`
+	CompanyCode     string    `json:"company_code"`
+	Symbol          *string   `json:"symbol,omitempty"`
+	Tranche         string    `json:"tranche"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	DaysUntil       int       `json:"days_until"`
+	SharesUnlocking *int      `json:"shares_unlocking,omitempty"` // Half of the anchor shares
+	AmountCrore     *float64  `json:"amount_crore,omitempty"`     // Half of the anchor investment
+	Estimated       bool      `json:"estimated"`
+}
@@ -7,10 +7,12 @@ import (
 )
 
 type IPOResultCache struct {
-	ID                uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid()"`
-	PanHash           string    `json:"pan_hash"`
-	IPOID             uuid.UUID `json:"ipo_id"`
-	Status            string    `json:"status"`
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid()"`
+	PanHash string    `json:"pan_hash"`
+	IPOID   uuid.UUID `json:"ipo_id"`
+	Status  string    `json:"status"`
+	// DisplayStatus is Status as shown to users; set on API responses only
+	DisplayStatus     string    `json:"display_status,omitempty"`
 	SharesAllotted    int       `json:"shares_allotted"`
 	ApplicationNumber string    `json:"application_number"`
 	RefundStatus      string    `json:"refund_status"`
@@ -20,4 +22,43 @@ type IPOResultCache struct {
 	ExpiresAt         time.Time `json:"expires_at"`
 	ConfidenceScore   int       `json:"confidence_score"`
 	DuplicateCount    int       `json:"duplicate_count"`
+	SourceChannel     string    `json:"source_channel"`
+	ClientFingerprint string    `json:"client_fingerprint,omitempty"`
+	// Sources that returned this result, as "channel:source[:fingerprint]"; admin only
+	Sources []string `json:"-"`
+	// OwnerHash is the signed-in account or device token holder the result is bound to,
+	// set when the result is first cached; empty leaves it unbound
+	OwnerHash string `json:"-"`
+}
+
+// ReadableBy reports whether owner may fetch the result by its PAN hash: any requester
+// may read an unbound result, a bound one only its owner
+func (r *IPOResultCache) ReadableBy(owner string) bool {
+	return r.OwnerHash == "" || r.OwnerHash == owner
+}
+
+// Source channels recorded on result cache writes
+const (
+	SourceChannelAPI      = "api"
+	SourceChannelBulk     = "bulk"
+	SourceChannelAdmin    = "admin"
+	SourceChannelTelegram = "telegram"
+)
+
+// CheckAuditEntry is an allotment check as exposed to admins, with the PAN hash
+// replaced by a keyed reference so PANs cannot be recovered from the response
+type CheckAuditEntry struct {
+	ID                uuid.UUID `json:"id"`
+	IPOID             uuid.UUID `json:"ipo_id"`
+	IPOName           string    `json:"ipo_name"`
+	PanRef            string    `json:"pan_ref"`
+	Status            string    `json:"status"`
+	Source            string    `json:"source"`
+	SourceChannel     string    `json:"source_channel"`
+	ClientFingerprint string    `json:"client_fingerprint"`
+	UserAgent         string    `json:"user_agent"`
+	DuplicateCount    int       `json:"duplicate_count"`
+	ConfidenceScore   int       `json:"confidence_score"`
+	Sources           []string  `json:"sources"`
+	Timestamp         time.Time `json:"timestamp"`
 }
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Allotment check statuses for async checks
+const (
+	CheckStatusPending    = "pending"
+	CheckStatusProcessing = "processing"
+	CheckStatusComplete   = "complete"
+	CheckStatusFailed     = "failed"
+)
+
+// AllotmentStatusNotDeclared is the result status when the registrar answers that
+// allotment results are not published yet
+const AllotmentStatusNotDeclared = "RESULT_NOT_DECLARED"
+
+// Allotment re-check statuses
+const (
+	RecheckStatusPending   = "PENDING"
+	RecheckStatusCompleted = "COMPLETED"
+	RecheckStatusExpired   = "EXPIRED"
+)
+
+// AllotmentCheck tracks an allotment check submitted to the check queue
+type AllotmentCheck struct {
+	CheckID   string          `json:"check_id"`
+	IPOID     uuid.UUID       `json:"ipo_id"`
+	Registrar string          `json:"registrar"`
+	Status    string          `json:"status"`
+	Result    *IPOResultCache `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	// ErrorCategory classifies failures (network, parse, rate_limited, ...)
+	ErrorCategory string `json:"error_category,omitempty"`
+	// RecheckScheduled is set when results were not declared yet and the check will be
+	// re-run, with the user notified, once they are
+	RecheckScheduled bool       `json:"recheck_scheduled,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+}
+
+// AllotmentRecheck is a check the registrar answered before results were declared. It is
+// re-run once the result release check finds the results published.
+type AllotmentRecheck struct {
+	ID             uuid.UUID  `json:"id"`
+	IPOID          uuid.UUID  `json:"ipo_id"`
+	PanHash        string     `json:"pan_hash"`
+	SourceChannel  string     `json:"source_channel"`
+	TelegramChatID *int64     `json:"-"`
+	Status         string     `json:"status"`
+	ResultStatus   *string    `json:"result_status,omitempty"`
+	Attempts       int        `json:"attempts"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a user can give for flagging an allotment check result
+const (
+	CheckFeedbackWrong = "wrong" // The registrar result shown did not match the user's allotment
+	CheckFeedbackStale = "stale" // The result was outdated, e.g. "not declared" after the registrar published
+)
+
+// CheckFeedback is a user's report that an allotment check result was inaccurate
+type CheckFeedback struct {
+	ID             uuid.UUID `json:"id"`
+	CheckID        string    `json:"check_id"`
+	IPOID          uuid.UUID `json:"ipo_id"`
+	Registrar      string    `json:"registrar"`
+	Reason         string    `json:"reason"`
+	ReportedStatus string    `json:"reported_status"` // The result status the user was shown
+	Comment        *string   `json:"comment,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CheckFeedbackSummary counts feedback reports for one registrar and IPO
+type CheckFeedbackSummary struct {
+	Registrar      string    `json:"registrar"`
+	IPOID          uuid.UUID `json:"ipo_id"`
+	IPOName        string    `json:"ipo_name"`
+	Wrong          int       `json:"wrong"`
+	Stale          int       `json:"stale"`
+	Total          int       `json:"total"`
+	LastReportedAt time.Time `json:"last_reported_at"`
+}
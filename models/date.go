@@ -0,0 +1,125 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout is the wire and storage format of calendar dates
+const DateLayout = "2006-01-02"
+
+// dateLocation is the zone IPO calendar dates refer to
+var dateLocation = func() *time.Location {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return location
+}()
+
+// Date is a calendar date in IST, such as an IPO's open or listing day. It is stored in
+// DATE columns and serialized as "YYYY-MM-DD", so clients in other time zones do not
+// see the day shift. The embedded time is midnight IST of the date.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the IST calendar date of t
+func NewDate(t time.Time) Date {
+	local := t.In(dateLocation)
+	return Date{time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, dateLocation)}
+}
+
+// DateOf returns the calendar date of t, or nil when t is nil
+func DateOf(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	date := NewDate(*t)
+	return &date
+}
+
+// ParseDate parses a "YYYY-MM-DD" date, also accepting RFC3339 timestamps, which are
+// reduced to their IST date
+func ParseDate(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(DateLayout, value, dateLocation); err == nil {
+		return Date{t}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return NewDate(t), nil
+}
+
+// TimePtr returns the date as midnight IST, or nil when d is nil
+func (d *Date) TimePtr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+// String formats the date as YYYY-MM-DD
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON writes the date as a "YYYY-MM-DD" string
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a "YYYY-MM-DD" string or an RFC3339 timestamp
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText writes the date as YYYY-MM-DD
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads a "YYYY-MM-DD" date or an RFC3339 timestamp
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores the date as YYYY-MM-DD
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a DATE column. The driver returns dates as midnight UTC, so the calendar
+// fields are taken as they are rather than converted to IST.
+func (d *Date) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case time.Time:
+		d.Time = time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, dateLocation)
+		return nil
+	case []byte:
+		return d.UnmarshalText(value)
+	case string:
+		return d.UnmarshalText([]byte(value))
+	}
+	return fmt.Errorf("cannot scan %T into Date", src)
+}
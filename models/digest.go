@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestIPO is an IPO in the weekly digest with its dates, issue size and latest GMP
+type DigestIPO struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Symbol          *string   `json:"symbol"`
+	Slug            *string   `json:"slug"`
+	OpenDate        *Date     `json:"open_date"`
+	CloseDate       *Date     `json:"close_date"`
+	ListingDate     *Date     `json:"listing_date"`
+	PriceBandLow    *float64  `json:"price_band_low"`
+	PriceBandHigh   *float64  `json:"price_band_high"`
+	IssueSize       *string   `json:"issue_size"`
+	IssueSizeAmount *float64  `json:"issue_size_amount"`
+	GMPValue        *float64  `json:"gmp_value"`
+	GainPercent     *float64  `json:"gain_percent"`
+}
+
+// WeeklyDigest summarizes the IPOs opening, closing and listing in a Monday-to-Sunday
+// week. An IPO opening and closing in the same week is listed under both.
+type WeeklyDigest struct {
+	WeekStart   Date        `json:"week_start"`
+	WeekEnd     Date        `json:"week_end"`
+	Opening     []DigestIPO `json:"opening"`
+	Closing     []DigestIPO `json:"closing"`
+	Listing     []DigestIPO `json:"listing"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// Empty reports whether no IPO opens, closes or lists in the week
+func (d *WeeklyDigest) Empty() bool {
+	return len(d.Opening) == 0 && len(d.Closing) == 0 && len(d.Listing) == 0
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IPOExportRecord is one line of the research export: an IPO with its final GMP and
+// listing performance
+type IPOExportRecord struct {
+	ID                 uuid.UUID `json:"id"`
+	StockID            string    `json:"stock_id"`
+	Name               string    `json:"name"`
+	CompanyCode        string    `json:"company_code"`
+	Symbol             *string   `json:"symbol"`
+	Registrar          string    `json:"registrar"`
+	Status             string    `json:"status"`
+	OpenDate           *Date     `json:"open_date"`
+	CloseDate          *Date     `json:"close_date"`
+	ResultDate         *Date     `json:"result_date"`
+	ListingDate        *Date     `json:"listing_date"`
+	PriceBandLow       *float64  `json:"price_band_low"`
+	PriceBandHigh      *float64  `json:"price_band_high"`
+	IssueSize          *string   `json:"issue_size"`
+	MinQty             *int      `json:"min_qty"`
+	MinAmount          *int      `json:"min_amount"`
+	SubscriptionStatus *string   `json:"subscription_status"`
+	ListingGain        *string   `json:"listing_gain"`
+
+	// Last GMP observed for the IPO; nil when no GMP was ever scraped
+	FinalGMP              *float64   `json:"final_gmp"`
+	FinalGMPGainPercent   *float64   `json:"final_gmp_gain_percent"`
+	FinalEstimatedListing *float64   `json:"final_estimated_listing"`
+	GMPLastUpdated        *time.Time `json:"gmp_last_updated"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ExtractionOverride fixes the scrape of one IPO whose detail page the generic extractor
+// gets wrong, such as an unusual layout. It is stored under "extraction" in the IPO's
+// parser_config and applied over the extracted values on every scrape. parser_config is
+// part of the IPO responses, so the override holds nothing private.
+type ExtractionOverride struct {
+	// Selectors maps IPO fields, as in the API, to a CSS selector on the detail page whose
+	// text is parsed into the field
+	Selectors map[string]string `json:"selectors,omitempty"`
+	// Fields are values entered by hand; they win over both the extractor and Selectors
+	Fields    *ExtractionOverrideFields `json:"fields,omitempty"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// ExtractionOverrideFields is the manual payload of an extraction override; nil fields
+// keep the extracted value
+type ExtractionOverrideFields struct {
+	Name               *string  `json:"name,omitempty"`
+	Registrar          *string  `json:"registrar,omitempty"`
+	OpenDate           *Date    `json:"open_date,omitempty"`
+	CloseDate          *Date    `json:"close_date,omitempty"`
+	ResultDate         *Date    `json:"result_date,omitempty"`
+	ListingDate        *Date    `json:"listing_date,omitempty"`
+	PriceBandLow       *float64 `json:"price_band_low,omitempty"`
+	PriceBandHigh      *float64 `json:"price_band_high,omitempty"`
+	IssueSize          *string  `json:"issue_size,omitempty"`
+	MinQty             *int     `json:"min_qty,omitempty"`
+	MinAmount          *int     `json:"min_amount,omitempty"`
+	SubscriptionStatus *string  `json:"subscription_status,omitempty"`
+	ListingGain        *string  `json:"listing_gain,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RegistrarFormMetadata is the live state of an IPO's registrar allotment form, read from
+// the form page so clients can prefill values the static form config cannot hold. Hidden
+// field values and CSRF tokens belong to the server's own registrar session, so only the
+// field names are kept; clients read the values from their own fetch of the form.
+type RegistrarFormMetadata struct {
+	FormURL string `json:"form_url"`
+	// CompanyField and CompanyValue are the company dropdown and the option selecting this IPO
+	CompanyField    string    `json:"company_field,omitempty"`
+	CompanyValue    string    `json:"company_value,omitempty"`
+	CompanyLabel    string    `json:"company_label,omitempty"`
+	HiddenFields    []string  `json:"hidden_fields"`
+	CSRFField       string    `json:"csrf_field,omitempty"`
+	CaptchaRequired bool      `json:"captcha_required"`
+	CaptchaType     string    `json:"captcha_type,omitempty"` // image, recaptcha or hcaptcha
+	FetchedAt       time.Time `json:"fetched_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
@@ -40,6 +40,76 @@ type EnhancedGMPData struct {
 	IPOStatus          *string             `json:"ipo_status"`          // Upcoming, Open, Listed
 	DataSource         string              `json:"data_source"`         // "investorgain.com"
 	ExtractionMetadata *ExtractionMetadata `json:"extraction_metadata,omitempty"`
+
+	// Derived trend signals computed from the GMP history series
+	TrendSignals *GMPTrendSignals `json:"trend_signals,omitempty"`
+
+	// Pre-open auction price on listing morning, once the exchange publishes one
+	IndicativeListingPrice *IndicativeListingPrice `json:"indicative_listing_price,omitempty"`
+
+	// Raw and EMA-smoothed values of the last scrape
+	Smoothing *GMPSmoothing `json:"smoothing,omitempty"`
+
+	// Where the published GMP came from and when it was scraped
+	Attribution *GMPAttribution `json:"attribution,omitempty"`
+}
+
+// GMPAttribution says which providers produced the published GMP and when. GMP is an
+// unofficial grey market figure reported by third-party sites, not exchange data, so
+// Unofficial is always set.
+type GMPAttribution struct {
+	Source            string    `json:"source"`
+	Providers         []string  `json:"providers"`
+	ProvidersAgreeing int       `json:"providers_agreeing"`
+	ScrapedAt         time.Time `json:"scraped_at"`
+	ManualOverride    bool      `json:"manual_override"`
+	Unofficial        bool      `json:"unofficial"`
+}
+
+// NewGMPAttribution attributes a GMP from source to the providers agreeing on it. Rows
+// saved before providers were recorded list only their source, and a manual override
+// has no agreeing providers.
+func NewGMPAttribution(source string, providers []string, scrapedAt time.Time, manualOverride bool) *GMPAttribution {
+	if len(providers) == 0 && source != "" && !manualOverride {
+		providers = []string{source}
+	}
+	if manualOverride || providers == nil {
+		providers = []string{}
+	}
+	return &GMPAttribution{
+		Source:            source,
+		Providers:         providers,
+		ProvidersAgreeing: len(providers),
+		ScrapedAt:         scrapedAt,
+		ManualOverride:    manualOverride,
+		Unofficial:        true,
+	}
+}
+
+// GMPSmoothing records the last scraped GMP next to its exponential moving average.
+// When OutlierRejected is set the scraped value was not published and GMPValue keeps
+// the previous value.
+type GMPSmoothing struct {
+	RawGMPValue      float64 `json:"raw_gmp_value"`
+	SmoothedGMPValue float64 `json:"smoothed_gmp_value"`
+	OutlierRejected  bool    `json:"outlier_rejected"`
+}
+
+// GMPHistoryPoint is a single GMP observation from the history series
+type GMPHistoryPoint struct {
+	GMPValue    float64   `json:"gmp_value"`
+	GainPercent float64   `json:"gain_percent"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// GMPTrendSignals are per-IPO signals derived from GMP history
+type GMPTrendSignals struct {
+	Change24h     *float64   `json:"change_24h"`      // GMP change vs. the last observation at least 24h old
+	Momentum3d    *float64   `json:"momentum_3d"`     // Least-squares GMP slope over the last 3 days, in rupees/day
+	Volatility    *float64   `json:"volatility"`      // Standard deviation of GMP over the last 3 days
+	DaysToListing *int       `json:"days_to_listing"` // Whole days until listing, 0 on listing day
+	ListingDecay  *float64   `json:"listing_decay"`   // exp(-days_to_listing/7); approaches 1 as listing nears
+	ComputedAt    *time.Time `json:"computed_at"`
 }
 
 // ExtractionMetadata tracks parsing success and metadata for GMP extraction
@@ -49,6 +119,72 @@ type ExtractionMetadata struct {
 	ParsingConfidence float64   `json:"parsing_confidence"`
 	TableStructure    string    `json:"table_structure"`
 	LastSuccessfulRun time.Time `json:"last_successful_run"`
+
+	// Set when the GMP row was entered manually by an admin
+	Override *GMPOverrideProvenance `json:"override,omitempty"`
+}
+
+// GMPOverride is an admin-entered GMP value that the GMP job leaves alone until it expires
+type GMPOverride struct {
+	GMPValue           float64   `json:"gmp_value"`
+	IPOPrice           *float64  `json:"ipo_price,omitempty"`
+	SubscriptionStatus *string   `json:"subscription_status,omitempty"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	SetBy              string    `json:"set_by"`
+	Reason             string    `json:"reason"`
+}
+
+// GMPOverrideProvenance records who overrode a GMP value, why, and what it replaced
+type GMPOverrideProvenance struct {
+	SetBy              string    `json:"set_by"`
+	Reason             string    `json:"reason"`
+	SetAt              time.Time `json:"set_at"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	PreviousGMPValue   *float64  `json:"previous_gmp_value,omitempty"`
+	PreviousDataSource string    `json:"previous_data_source,omitempty"`
+}
+
+// GMPPatch corrects individual fields of a GMP row that the scraper does not capture;
+// nil fields are left unchanged. Patched fields stay admin-owned, so the GMP job keeps
+// them, until they are listed in Unlock.
+type GMPPatch struct {
+	Sub2               *float64 `json:"sub2"`
+	Kostak             *float64 `json:"kostak"`
+	SubscriptionStatus *string  `json:"subscription_status"`
+	ListingGain        *string  `json:"listing_gain"`
+	Unlock             []string `json:"unlock"`
+	EditedBy           string   `json:"edited_by"`
+	Reason             string   `json:"reason"`
+}
+
+// GMPFieldEdit is one audited change made by a GMPPatch
+type GMPFieldEdit struct {
+	Field    string  `json:"field"`
+	Action   string  `json:"action"` // "set" or "unlock"
+	OldValue *string `json:"old_value"`
+	NewValue *string `json:"new_value"`
+}
+
+// GMPPatchResult is a GMP row after an admin patch
+type GMPPatchResult struct {
+	ID                 string               `json:"id"`
+	IPOName            string               `json:"ipo_name"`
+	CompanyCode        string               `json:"company_code"`
+	Sub2               float64              `json:"sub2"`
+	Kostak             float64              `json:"kostak"`
+	SubscriptionStatus *string              `json:"subscription_status"`
+	ListingGain        *string              `json:"listing_gain"`
+	AdminFields        map[string]time.Time `json:"admin_fields"` // admin-owned field -> when it was last set
+	Edits              []GMPFieldEdit       `json:"edits"`
+}
+
+// GMPAdminRow is one GMP row in the admin GMP data listing
+type GMPAdminRow struct {
+	GMPData
+	StockID            *string `json:"stock_id"`
+	IPOStatus          *string `json:"ipo_status"`
+	SubscriptionStatus *string `json:"subscription_status"`
+	IsManualOverride   bool    `json:"is_manual_override"`
 }
 
 // StockIDCache represents cached stock ID resolution results
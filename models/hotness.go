@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HotnessComponent is one weighted input to the hotness score
+type HotnessComponent struct {
+	RawValue   float64 `json:"raw_value"`
+	Normalized float64 `json:"normalized"` // 0-1 after scaling
+	Weight     float64 `json:"weight"`
+	Points     float64 `json:"points"` // Contribution to the 0-100 score
+}
+
+// HotnessBreakdown lists the components behind a hotness score
+type HotnessBreakdown struct {
+	GMPPercent   HotnessComponent `json:"gmp_percent"`
+	Subscription HotnessComponent `json:"subscription"`
+	CheckVolume  HotnessComponent `json:"check_volume"`
+}
+
+// IPOHotness is a scored IPO as returned by the trending endpoint
+type IPOHotness struct {
+	IPOID       uuid.UUID        `json:"ipo_id"`
+	Name        string           `json:"name"`
+	CompanyCode string           `json:"company_code"`
+	Slug        *string          `json:"slug,omitempty"`
+	Status      string           `json:"status"`
+	Score       float64          `json:"score"`
+	Breakdown   HotnessBreakdown `json:"breakdown"`
+	ComputedAt  time.Time        `json:"computed_at"`
+}
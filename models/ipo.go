@@ -7,6 +7,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// IPOStatusResultOut is the stored status of a closed IPO whose allotment results the
+// registrar has published. It shows until the IPO lists.
+const IPOStatusResultOut = "RESULT_OUT"
+
 type IPO struct {
 	// Primary identification
 	ID      uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
@@ -19,10 +23,22 @@ type IPO struct {
 	Registrar   string  `json:"registrar" gorm:"type:varchar(255);not null"`
 
 	// Date Information (from IPODateInformation)
-	OpenDate    *time.Time `json:"open_date"`
-	CloseDate   *time.Time `json:"close_date"`
-	ResultDate  *time.Time `json:"result_date"`
-	ListingDate *time.Time `json:"listing_date"`
+	OpenDate    *Date `json:"open_date"`
+	CloseDate   *Date `json:"close_date"`
+	ResultDate  *Date `json:"result_date"`
+	ListingDate *Date `json:"listing_date"`
+	// MandateDeadline is when the UPI mandate must be accepted: 17:00 IST on the close
+	// date. Computed with the status, not stored.
+	MandateDeadline *time.Time `json:"mandate_deadline,omitempty" gorm:"-"`
+	// Bidding cutoffs on the close date as IST times of day ("16:00"), set by admins when
+	// the IPO's differ from the defaults; nil uses the default of the category
+	RetailCutoff *string `json:"-" gorm:"type:time"`
+	HNICutoff    *string `json:"-" gorm:"type:time"`
+	// CloseTimes is when bidding closes for each investor category, and SecondsRemaining
+	// the seconds until the last cutoff while the IPO is active. Computed with the
+	// status, not stored.
+	CloseTimes       *CloseTimes `json:"close_times,omitempty" gorm:"-"`
+	SecondsRemaining *int64      `json:"seconds_remaining,omitempty" gorm:"-"`
 
 	// Pricing Information (from IPOPricingInformation)
 	PriceBandLow  *float64 `json:"price_band_low" gorm:"type:decimal(10,2)"`
@@ -30,9 +46,13 @@ type IPO struct {
 	IssueSize     *string  `json:"issue_size" gorm:"type:varchar(100)"`
 	MinQty        *int     `json:"min_qty"`
 	MinAmount     *int     `json:"min_amount"`
+	// IssueSizeAmount is IssueSize parsed into rupees, for sorting and filtering by size
+	IssueSizeAmount *float64 `json:"issue_size_amount" gorm:"type:decimal(18,2)"`
 
 	// Status Information (from IPOStatusInformation)
-	Status             string  `json:"status" gorm:"type:varchar(50);not null;default:'Unknown'"`
+	Status string `json:"status" gorm:"type:varchar(50);not null;default:'Unknown'"`
+	// DisplayStatus is Status as shown to users, in the language asked for with ?lang=
+	DisplayStatus      string  `json:"display_status" gorm:"-"`
 	SubscriptionStatus *string `json:"subscription_status" gorm:"type:varchar(100)"`
 	ListingGain        *string `json:"listing_gain" gorm:"type:varchar(50)"`
 
@@ -42,6 +62,11 @@ type IPO struct {
 	About       *string `json:"about" gorm:"type:text"`
 	Slug        *string `json:"slug" gorm:"type:varchar(255)"`
 
+	// Set when Description or About was cut to its preview length for the response, so
+	// clients can offer "read more"; GET /ipos/:id?full_text=true returns the full text
+	DescriptionTruncated bool `json:"description_truncated" gorm:"-"`
+	AboutTruncated       bool `json:"about_truncated" gorm:"-"`
+
 	// Legacy form fields (kept for API compatibility)
 	FormURL      *string         `json:"form_url" gorm:"type:varchar(500)"`
 	FormFields   json.RawMessage `json:"form_fields" gorm:"type:jsonb;default:'{}'"`
@@ -52,8 +77,119 @@ type IPO struct {
 	Strengths json.RawMessage `json:"strengths" gorm:"type:jsonb;default:'[]'"`
 	Risks     json.RawMessage `json:"risks" gorm:"type:jsonb;default:'[]'"`
 
+	// Anchor investor allocation, set by the scraper and saved to ipo_anchor_allocations
+	AnchorAllocation *AnchorAllocation `json:"anchor_allocation,omitempty" gorm:"-"`
+
+	// Structured page content, set by the scraper and stored as JSONB. The timetable is
+	// returned on detail responses only; the FAQ is served by GET /ipos/:id/faq.
+	FAQ       []IPOFAQItem     `json:"-" gorm:"-"`
+	Timetable []TimetableEntry `json:"timetable,omitempty" gorm:"-"`
+
+	// Fresh issue and offer for sale breakdown, set by the scraper and stored in dedicated
+	// ipo_list columns; returned on detail responses only
+	IssueStructure *IssueStructure `json:"issue_structure,omitempty" gorm:"-"`
+
+	// Promoter holding before and after the issue, set by the scraper and stored in
+	// dedicated ipo_list columns; returned on detail responses only
+	PromoterHolding *PromoterHolding `json:"promoter_holding,omitempty" gorm:"-"`
+
+	// Price band history from ipo_price_revisions, oldest first; set on detail responses only
+	PriceRevisions []PriceRevision `json:"price_revisions,omitempty" gorm:"-"`
+
+	// Slugs the IPO had before a rename, most recent first; set on detail responses only.
+	// Requests for them are redirected to Slug.
+	PreviousSlugs []string `json:"previous_slugs,omitempty" gorm:"-"`
+
+	// How to check allotment on the registrar's site, set by admins for registrars the
+	// checker does not support; set on detail responses only
+	ManualCheck *ManualCheck `json:"manual_check,omitempty" gorm:"-"`
+
+	// Where each field's value came from, stored in ipo_list.field_lineage and returned
+	// by the admin detail endpoint only
+	Lineage FieldLineageMap `json:"-" gorm:"-"`
+
 	// Audit fields
 	CreatedAt time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
 	CreatedBy *string   `json:"created_by" gorm:"type:varchar(100)"`
 }
+
+// PriceRevision is one change of an IPO's price band. The first revision records the
+// band as first seen, with no previous band.
+type PriceRevision struct {
+	PriceBandLow          *float64  `json:"price_band_low"`
+	PriceBandHigh         *float64  `json:"price_band_high"`
+	PreviousPriceBandLow  *float64  `json:"previous_price_band_low"`
+	PreviousPriceBandHigh *float64  `json:"previous_price_band_high"`
+	Source                string    `json:"source"`
+	RevisedAt             time.Time `json:"revised_at"`
+}
+
+// CloseTimes is when bidding closes on an IPO's close date for each investor category
+type CloseTimes struct {
+	Retail CategoryClose `json:"retail"`
+	HNI    CategoryClose `json:"hni"`
+}
+
+// CategoryClose is when bidding closes for one investor category. SecondsRemaining is set
+// while the IPO is active, and is 0 once the category's cutoff has passed.
+type CategoryClose struct {
+	ClosesAt         time.Time `json:"closes_at"`
+	SecondsRemaining *int64    `json:"seconds_remaining,omitempty"`
+}
+
+// ManualCheck tells users how to check allotment themselves, for IPOs whose registrar the
+// allotment checker does not support. Stored in ipo_list's manual_check_* columns.
+type ManualCheck struct {
+	URL          string `json:"manual_check_url,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// SlugRedirect points a replaced slug at the IPO's current, canonical slug
+type SlugRedirect struct {
+	IPOID         uuid.UUID `json:"ipo_id"`
+	Slug          string    `json:"slug"`
+	CanonicalSlug string    `json:"canonical_slug"`
+}
+
+// IPOCompleteness reports which key fields of an IPO are missing
+type IPOCompleteness struct {
+	IPOID           uuid.UUID `json:"ipo_id"`
+	Name            string    `json:"name"`
+	StockID         string    `json:"stock_id"`
+	Status          string    `json:"status"`
+	Score           *float64  `json:"completeness_score"` // Nil until the IPO is next upserted
+	PopulatedFields int       `json:"populated_fields"`
+	TotalFields     int       `json:"total_fields"`
+	MissingFields   []string  `json:"missing_fields"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// IPOMergeRequest asks to fold the source IPO, a duplicate record, into the target
+type IPOMergeRequest struct {
+	SourceID uuid.UUID `json:"source_id"`
+	TargetID uuid.UUID `json:"target_id"`
+	MergedBy string    `json:"merged_by"`
+	Reason   string    `json:"reason"`
+}
+
+// IPOMergeResult reports what a merge moved from the source IPO to the target. The
+// source is soft-deleted and points at the target from then on.
+type IPOMergeResult struct {
+	SourceID      uuid.UUID        `json:"source_id"`
+	TargetID      uuid.UUID        `json:"target_id"`
+	SourceStockID string           `json:"source_stock_id"`
+	TargetStockID string           `json:"target_stock_id"`
+	MergedBy      string           `json:"merged_by"`
+	Reason        string           `json:"reason"`
+	MovedRows     map[string]int64 `json:"moved_rows"` // Rows re-pointed at the target, by table
+	GMP           string           `json:"gmp"`        // none, moved or history_merged
+	MergedAt      time.Time        `json:"merged_at"`
+}
+
+// What an IPO merge did with the source's GMP data
+const (
+	IPOMergeGMPNone          = "none"           // The source had no GMP row
+	IPOMergeGMPMoved         = "moved"          // The source's GMP row now belongs to the target
+	IPOMergeGMPHistoryMerged = "history_merged" // Both had rows; the source's history joined the target's
+)
@@ -0,0 +1,36 @@
+package models
+
+// IPOFAQItem is one question and answer from an IPO page's FAQ section
+type IPOFAQItem struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// TimetableEntry is one row of an IPO's published timetable, such as "Initiation of
+// Refunds". Date is nil when the row is not a plain date, e.g. the UPI mandate cut-off
+// "5 PM on Jan 16, 2024"; Text always holds the value as published.
+type TimetableEntry struct {
+	Event string `json:"event"`
+	Date  *Date  `json:"date"`
+	Text  string `json:"text"`
+}
+
+// IssueStructure splits an issue into its fresh issue, new shares whose proceeds go to
+// the company, and its offer for sale (OFS) by existing shareholders. Amounts are in
+// rupees. OFSPercent is the OFS share of the issue by amount, or by shares when the
+// amounts are missing; a page listing only one component counts the other as zero.
+type IssueStructure struct {
+	FreshIssueShares *int64   `json:"fresh_issue_shares"`
+	FreshIssueAmount *float64 `json:"fresh_issue_amount"`
+	OFSShares        *int64   `json:"ofs_shares"`
+	OFSAmount        *float64 `json:"ofs_amount"`
+	OFSPercent       *float64 `json:"ofs_percent"`
+}
+
+// PromoterHolding is the promoters' shareholding before and after the issue, in
+// percent. DilutionPoints is how many percentage points the issue takes off it.
+type PromoterHolding struct {
+	PreIssuePercent  *float64 `json:"pre_issue_percent"`
+	PostIssuePercent *float64 `json:"post_issue_percent"`
+	DilutionPoints   *float64 `json:"dilution_points"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of a leaderboard entry's listing gain
+const (
+	ListingGainSourceScraped = "listing_gain"
+	ListingGainSourcePreOpen = "pre_open"
+)
+
+// LeaderboardEntry is one listed IPO ranked by its listing gain, with its issue details
+type LeaderboardEntry struct {
+	Rank               int       `json:"rank"`
+	IPOID              uuid.UUID `json:"ipo_id"`
+	Name               string    `json:"name"`
+	CompanyCode        string    `json:"company_code"`
+	Symbol             *string   `json:"symbol"`
+	Slug               *string   `json:"slug"`
+	ListingDate        *Date     `json:"listing_date"`
+	PriceBandLow       *float64  `json:"price_band_low"`
+	PriceBandHigh      *float64  `json:"price_band_high"`
+	IssueSize          *string   `json:"issue_size"`
+	IssueSizeAmount    *float64  `json:"issue_size_amount"`
+	SubscriptionStatus *string   `json:"subscription_status"`
+	ListingGainPercent float64   `json:"listing_gain_percent"`
+	ListingGainSource  string    `json:"listing_gain_source"`
+}
+
+// Leaderboard lists the best and worst IPOs of a year by a metric. Ranks count from the
+// best IPO, so the worst IPO's rank is Count.
+type Leaderboard struct {
+	Year       int                `json:"year"`
+	Metric     string             `json:"metric"`
+	Count      int                `json:"count"`
+	Top        []LeaderboardEntry `json:"top"`
+	Bottom     []LeaderboardEntry `json:"bottom"`
+	ComputedAt time.Time          `json:"computed_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Field lineage sources
+const (
+	LineageSourceJSON     = "json"     // the Chittorgarh page's embedded JSON payload
+	LineageSourceHTML     = "html"     // the Chittorgarh page's HTML, as fallback or for HTML-only sections
+	LineageSourceAdmin    = "admin"    // entered through the admin API
+	LineageSourceGMP      = "gmp"      // a GMP provider; the provider name follows after a colon
+	LineageSourceOverride = "override" // the IPO's extraction override; "selector" or "manual" follows after a colon
+)
+
+// FieldLineage records where a field's current value came from and when it was fetched
+type FieldLineage struct {
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// FieldLineageMap maps IPO field names, as in the API, to their lineage. It is stored
+// per IPO in ipo_list.field_lineage.
+type FieldLineageMap map[string]FieldLineage
+
+// Set records source as the origin of each of fields
+func (m FieldLineageMap) Set(source string, fetchedAt time.Time, fields ...string) {
+	for _, field := range fields {
+		m[field] = FieldLineage{Source: source, FetchedAt: fetchedAt}
+	}
+}
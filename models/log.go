@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,3 +16,23 @@ type IPOUpdateLog struct {
 	Source    string    `json:"source"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// IPOFieldChange is one logged change of an IPO field, with its values as JSON
+type IPOFieldChange struct {
+	Field     string          `json:"field"`
+	OldValue  json.RawMessage `json:"old_value"`
+	NewValue  json.RawMessage `json:"new_value"`
+	Source    string          `json:"source"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// IPOSnapshot is an IPO reconstructed as it was at AsOf from its update log
+type IPOSnapshot struct {
+	IPO  *IPO      `json:"ipo"`
+	AsOf time.Time `json:"as_of"`
+	// Reverted are the changes made after AsOf that were rolled back, newest first
+	Reverted []IPOFieldChange `json:"reverted"`
+	// Skipped are changes after AsOf that could not be rolled back because the field's
+	// value at the time is not the one they wrote
+	Skipped []IPOFieldChange `json:"skipped"`
+}
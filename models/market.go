@@ -1,11 +1,17 @@
 package models
 
+import "time"
+
 // MarketIndex represents a stock market index with current value and change information
 type MarketIndex struct {
-	ID            string  `json:"id"`
-	Name          string  `json:"name"`
-	Value         float64 `json:"value"`
-	Change        float64 `json:"change"`
-	ChangePercent float64 `json:"change_percent"`
-	IsPositive    bool    `json:"is_positive"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Exchange      string    `json:"exchange"`
+	Category      string    `json:"category"` // mainboard or sme
+	Value         float64   `json:"value"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"change_percent"`
+	IsPositive    bool      `json:"is_positive"`
+	Sparkline     []float64 `json:"sparkline"` // recent intraday values, oldest first
+	UpdatedAt     time.Time `json:"updated_at"`
 }
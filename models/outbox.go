@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox event types
+const (
+	EventIPOCreated = "ipo.created"
+	EventIPOUpdated = "ipo.updated"
+	EventGMPUpdated = "gmp.updated"
+	// EventAllotmentRechecked carries the result of a re-check scheduled because results
+	// were not declared, for delivering it to the user who asked
+	EventAllotmentRechecked = "allotment.rechecked"
+	// EventMandateReminder is emitted once per IPO a few hours before its UPI mandate
+	// deadline, for pushing a reminder to users watching the IPO
+	EventMandateReminder = "ipo.mandate_reminder"
+	// EventResultsReleased is emitted once when an IPO's allotment results are detected
+	// on the registrar, for telling users they can check
+	EventResultsReleased = "ipo.results_released"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "PENDING"
+	OutboxStatusDelivered = "DELIVERED"
+	OutboxStatusFailed    = "FAILED"
+)
+
+// OutboxEvent is an event written in the same transaction as the data change
+// it describes, and delivered to notifiers by the outbox dispatcher
+type OutboxEvent struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;default:gen_random_uuid()"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     *string         `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	// DeliveredTo names the notifiers that have received the event, so a retry after a
+	// partial failure skips them
+	DeliveredTo []string `json:"-"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IndicativeListingPrice is the equilibrium price discovered in the exchange's pre-open
+// call auction on an IPO's listing morning
+type IndicativeListingPrice struct {
+	IPOID             uuid.UUID `json:"ipo_id"`
+	Symbol            string    `json:"symbol"`
+	Exchange          string    `json:"exchange"`
+	Price             float64   `json:"price"`
+	IssuePrice        *float64  `json:"issue_price,omitempty"`
+	GainPercent       *float64  `json:"gain_percent,omitempty"`
+	TotalBuyQuantity  int64     `json:"total_buy_quantity"`
+	TotalSellQuantity int64     `json:"total_sell_quantity"`
+	MarketTime        time.Time `json:"market_time"`
+	Source            string    `json:"source"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockQuote is a market price snapshot returned by a quote provider
+type StockQuote struct {
+	Symbol        string    `json:"symbol"`
+	Exchange      string    `json:"exchange"`
+	Currency      string    `json:"currency"`
+	Price         float64   `json:"price"`
+	PreviousClose float64   `json:"previous_close"`
+	MarketTime    time.Time `json:"market_time"`
+	Source        string    `json:"source"`
+}
+
+// IPOQuote is the post-listing performance of a listed IPO
+type IPOQuote struct {
+	StockQuote
+	IPOID              uuid.UUID `json:"ipo_id"`
+	Name               string    `json:"name"`
+	DayChange          float64   `json:"day_change"`
+	DayChangePercent   float64   `json:"day_change_percent"`
+	IssuePrice         *float64  `json:"issue_price,omitempty"`
+	GainVsIssue        *float64  `json:"gain_vs_issue,omitempty"`
+	GainVsIssuePercent *float64  `json:"gain_vs_issue_percent,omitempty"`
+	ListingDate        *Date     `json:"listing_date,omitempty"`
+	FetchedAt          time.Time `json:"fetched_at"`
+	Cached             bool      `json:"cached"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedPAN is a PAN a user opted to keep in the vault. The PAN itself is stored
+// encrypted and only its masked form is ever returned by the API.
+type SavedPAN struct {
+	ID            uuid.UUID  `json:"id"`
+	MaskedPAN     string     `json:"masked_pan"`
+	Label         string     `json:"label,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+}
+
+// Outcomes of checking one saved PAN against an IPO
+const (
+	VaultCheckCached      = "cached"       // A cached result was returned without a registrar lookup
+	VaultCheckQueued      = "queued"       // A registrar check was queued; poll check_id
+	VaultCheckRateLimited = "rate_limited" // The PAN's daily quota for the IPO is used up
+	VaultCheckFailed      = "failed"       // The check could not be queued
+)
+
+// VaultCheckEntry is the outcome for one saved PAN in a "check all my PANs" request
+type VaultCheckEntry struct {
+	SavedPANID uuid.UUID       `json:"saved_pan_id"`
+	MaskedPAN  string          `json:"masked_pan"`
+	Label      string          `json:"label,omitempty"`
+	Outcome    string          `json:"outcome"`
+	CheckID    string          `json:"check_id,omitempty"`
+	Result     *IPOResultCache `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scrape run statuses
+const (
+	ScrapeRunSucceeded = "SUCCEEDED" // Every item was saved
+	ScrapeRunPartial   = "PARTIAL"   // Some items failed to scrape or save
+	ScrapeRunFailed    = "FAILED"    // The listing could not be fetched or every item failed
+)
+
+// Upsert outcomes used for a run's data diff counts
+const (
+	UpsertCreated   = "created"
+	UpsertUpdated   = "updated"
+	UpsertUnchanged = "unchanged"
+)
+
+// ScrapeFieldStat is the extraction success rate of one IPO field across a run
+type ScrapeFieldStat struct {
+	Populated   int     `json:"populated"`
+	Attempts    int     `json:"attempts"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// ScrapeRun is the persisted report of one scraper job run
+type ScrapeRun struct {
+	ID                uuid.UUID                  `json:"id"`
+	JobName           string                     `json:"job_name"`
+	Status            string                     `json:"status"`
+	StartedAt         time.Time                  `json:"started_at"`
+	FinishedAt        time.Time                  `json:"finished_at"`
+	DurationMs        int64                      `json:"duration_ms"`
+	TotalItems        int                        `json:"total_items"`
+	SuccessCount      int                        `json:"success_count"`
+	PartialCount      int                        `json:"partial_count"`
+	FailureCount      int                        `json:"failure_count"`
+	CreatedCount      int                        `json:"created_count"`
+	UpdatedCount      int                        `json:"updated_count"`
+	UnchangedCount    int                        `json:"unchanged_count"`
+	FieldStats        map[string]ScrapeFieldStat `json:"field_stats"`
+	ExtractionMetrics map[string]interface{}     `json:"extraction_metrics"`
+	ErrorSamples      []string                   `json:"error_samples"`
+}
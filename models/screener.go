@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+// IPOScreenerEntry is one IPO in the analytics screener: its schedule, size and issue
+// structure without the page content of full IPO responses
+type IPOScreenerEntry struct {
+	IPOID           uuid.UUID `json:"ipo_id"`
+	Name            string    `json:"name"`
+	CompanyCode     string    `json:"company_code"`
+	Symbol          *string   `json:"symbol"`
+	Slug            *string   `json:"slug"`
+	Status          string    `json:"status"`
+	OpenDate        *Date     `json:"open_date"`
+	CloseDate       *Date     `json:"close_date"`
+	ListingDate     *Date     `json:"listing_date"`
+	IssueSize       *string   `json:"issue_size"`
+	IssueSizeAmount *float64  `json:"issue_size_amount"`
+	ListingGain     *string   `json:"listing_gain"`
+	IssueStructure
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription investor categories, as normalized from the registrar's subscription table
+const (
+	SubscriptionQIB         = "qib"
+	SubscriptionNII         = "nii"
+	SubscriptionBNII        = "bnii"
+	SubscriptionSNII        = "snii"
+	SubscriptionRetail      = "retail"
+	SubscriptionEmployee    = "employee"
+	SubscriptionShareholder = "shareholder"
+	SubscriptionTotal       = "total"
+)
+
+// SubscriptionSnapshot is one scrape of an IPO's subscription table: each category's
+// multiple and, where the exchange data has them, its application count and shares offered
+type SubscriptionSnapshot struct {
+	Multiples     map[string]float64
+	Applications  map[string]int64
+	SharesOffered map[string]int64
+}
+
+// SubscriptionHistoryPoint holds the subscription multiple of each category at one hour,
+// with application counts and shares offered for the categories that had them
+type SubscriptionHistoryPoint struct {
+	RecordedAt    time.Time          `json:"recorded_at"`
+	Multiples     map[string]float64 `json:"multiples"`
+	Applications  map[string]int64   `json:"applications,omitempty"`
+	SharesOffered map[string]int64   `json:"shares_offered,omitempty"`
+}
+
+// SubscriptionHistory is the hourly subscription build-up of an IPO, oldest first
+type SubscriptionHistory struct {
+	IPOID      uuid.UUID                  `json:"ipo_id"`
+	Categories []string                   `json:"categories"`
+	Points     []SubscriptionHistoryPoint `json:"points"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Text pattern kinds used by the description/about cleaners
+const (
+	TextPatternNavigation  = "navigation"  // Matches are replaced with a space
+	TextPatternBoilerplate = "boilerplate" // Matches are removed
+)
+
+// TextPattern is a regular expression the scrapers strip from extracted company text
+type TextPattern struct {
+	ID          uuid.UUID `json:"id"`
+	Kind        string    `json:"kind"`
+	Pattern     string    `json:"pattern"`
+	Description *string   `json:"description,omitempty"`
+	Position    int       `json:"position"` // Patterns of a kind are applied in ascending position
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TextPatternTestResult is the outcome of applying patterns to sample text
+type TextPatternTestResult struct {
+	Kind        string   `json:"kind"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Matches     []string `json:"matches"`
+	CleanedText string   `json:"cleaned_text"`
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LoginSender delivers a login message (magic link or one-time code) to an email or phone
+type LoginSender interface {
+	SendLoginMessage(ctx context.Context, destination, subject, body string) error
+}
+
+// SMTPLoginSender emails magic links through an SMTP server
+type SMTPLoginSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// SendLoginMessage emails body to destination
+func (s *SMTPLoginSender) SendLoginMessage(ctx context.Context, destination, subject, body string) error {
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", s.From, destination, subject, body)
+	if err := sendSMTPMail(ctx, s.Addr, s.Username, s.Password, s.From, []string{destination}, message); err != nil {
+		return shared.NetworkErrorf("failed to send login email: %w", err)
+	}
+	return nil
+}
+
+// SMSWebhookSender sends OTPs by posting {"to", "message"} to an SMS gateway webhook
+type SMSWebhookSender struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewSMSWebhookSender creates an SMS sender for a gateway webhook URL
+func NewSMSWebhookSender(webhookURL string) *SMSWebhookSender {
+	return &SMSWebhookSender{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendLoginMessage posts the SMS body; the subject is unused
+func (s *SMSWebhookSender) SendLoginMessage(ctx context.Context, destination, subject, body string) error {
+	payload, err := json.Marshal(map[string]string{"to": destination, "message": body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal SMS request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SMS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return shared.NetworkErrorf("SMS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return shared.NetworkErrorf("SMS gateway returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// phonePattern matches an E.164 phone number after normalization
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// AccountService handles passwordless login (email magic link or SMS OTP) and bearer
+// sessions for end users. It is deliberately separate from admin access.
+type AccountService struct {
+	DB           *sql.DB
+	MagicLinkURL string
+	SessionTTL   time.Duration
+	ChallengeTTL time.Duration
+	// MaxAttempts is how many wrong codes a challenge accepts before it is burned
+	MaxAttempts int
+	// MaxChallenges caps login requests per destination within ChallengeTTL
+	MaxChallenges int
+
+	senders map[string]LoginSender
+	logger  *logrus.Entry
+}
+
+// NewAccountService creates an account service; magic links point at magicLinkURL with
+// a token query parameter
+func NewAccountService(db *sql.DB, magicLinkURL string, sessionTTL time.Duration) *AccountService {
+	if sessionTTL <= 0 {
+		sessionTTL = 30 * 24 * time.Hour
+	}
+	return &AccountService{
+		DB:            db,
+		MagicLinkURL:  magicLinkURL,
+		SessionTTL:    sessionTTL,
+		ChallengeTTL:  15 * time.Minute,
+		MaxAttempts:   5,
+		MaxChallenges: 5,
+		senders:       make(map[string]LoginSender),
+		logger:        logrus.WithField("component", "account_service"),
+	}
+}
+
+// SetSender enables a login channel
+func (s *AccountService) SetSender(channel string, sender LoginSender) {
+	s.senders[channel] = sender
+}
+
+// normalizeDestination validates and canonicalizes an email or phone for a channel.
+// Ten-digit phone numbers are taken as Indian mobiles.
+func normalizeDestination(channel, destination string) (string, error) {
+	destination = strings.TrimSpace(destination)
+	switch channel {
+	case models.LoginChannelEmail:
+		address, err := mail.ParseAddress(destination)
+		if err != nil || address.Address != destination {
+			return "", shared.ValidationErrorf("invalid email address")
+		}
+		return strings.ToLower(address.Address), nil
+	case models.LoginChannelSMS:
+		phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(destination)
+		if len(phone) == 10 && !strings.HasPrefix(phone, "+") {
+			phone = "+91" + phone
+		}
+		if !phonePattern.MatchString(phone) {
+			return "", shared.ValidationErrorf("invalid phone number")
+		}
+		return phone, nil
+	default:
+		return "", shared.ValidationErrorf("unsupported login channel %q", channel)
+	}
+}
+
+// maskDestination hides most of an email local part or phone number
+func maskDestination(channel, destination string) string {
+	if channel == models.LoginChannelEmail {
+		at := strings.Index(destination, "@")
+		if at < 0 {
+			return strings.Repeat("*", len(destination))
+		}
+		if at <= 1 {
+			return "*" + destination[at:]
+		}
+		return destination[:1] + strings.Repeat("*", at-1) + destination[at:]
+	}
+	if len(destination) <= 4 {
+		return destination
+	}
+	return strings.Repeat("*", len(destination)-4) + destination[len(destination)-4:]
+}
+
+// hashSecret hashes a login code, magic link token or session token for storage
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns a URL-safe random token of n bytes
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// randomOTP returns a six-digit numeric code
+func randomOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// StartLogin creates a login challenge and sends the magic link or code. Logging in with
+// an email or phone that has no account registers one on verification.
+func (s *AccountService) StartLogin(ctx context.Context, channel, destination string) (*models.LoginChallenge, error) {
+	destination, err := normalizeDestination(channel, destination)
+	if err != nil {
+		return nil, err
+	}
+	sender, ok := s.senders[channel]
+	if !ok {
+		return nil, shared.ValidationErrorf("%s login is not enabled", channel)
+	}
+
+	var recent int
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM account_login_challenges
+		WHERE destination = $1 AND created_at > $2
+	`, destination, time.Now().Add(-s.ChallengeTTL)).Scan(&recent); err != nil {
+		return nil, fmt.Errorf("failed to count login challenges: %w", err)
+	}
+	if recent >= s.MaxChallenges {
+		return nil, shared.RateLimitedErrorf("too many login requests, try again later")
+	}
+
+	var secret, subject, body string
+	if channel == models.LoginChannelEmail {
+		if secret, err = randomToken(32); err != nil {
+			return nil, err
+		}
+		link := s.MagicLinkURL + "?token=" + url.QueryEscape(secret)
+		subject = "Your sign-in link"
+		body = fmt.Sprintf("Sign in by opening this link within %d minutes:\r\n\r\n%s\r\n\r\nIf you didn't request this, ignore this email.",
+			int(s.ChallengeTTL.Minutes()), link)
+	} else {
+		if secret, err = randomOTP(); err != nil {
+			return nil, err
+		}
+		body = fmt.Sprintf("%s is your sign-in code. It expires in %d minutes.", secret, int(s.ChallengeTTL.Minutes()))
+	}
+
+	challenge := &models.LoginChallenge{
+		Channel:     channel,
+		Destination: maskDestination(channel, destination),
+		ExpiresAt:   time.Now().Add(s.ChallengeTTL),
+	}
+	if err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO account_login_challenges (channel, destination, secret_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, channel, destination, hashSecret(secret), challenge.ExpiresAt).Scan(&challenge.ID); err != nil {
+		return nil, fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	if err := sender.SendLoginMessage(ctx, destination, subject, body); err != nil {
+		s.logger.WithError(err).WithField("channel", channel).Warn("Failed to send login message")
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// VerifyLogin completes a login with either a magic link token or a challenge ID and OTP,
+// creating the account on first login, and returns a new session
+func (s *AccountService) VerifyLogin(ctx context.Context, challengeID, code, token string) (*models.AccountSession, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin login transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		id          uuid.UUID
+		channel     string
+		destination string
+		secretHash  string
+		attempts    int
+		expiresAt   time.Time
+		consumedAt  sql.NullTime
+		secret      string
+	)
+	query := `
+		SELECT id, channel, destination, secret_hash, attempts, expires_at, consumed_at
+		FROM account_login_challenges
+	`
+	var row *sql.Row
+	switch {
+	case token != "":
+		row = tx.QueryRowContext(ctx, query+` WHERE secret_hash = $1 AND channel = $2 FOR UPDATE`,
+			hashSecret(token), models.LoginChannelEmail)
+		secret = token
+	case challengeID != "" && code != "":
+		parsed, err := uuid.Parse(challengeID)
+		if err != nil {
+			return nil, shared.ValidationErrorf("invalid challenge ID")
+		}
+		row = tx.QueryRowContext(ctx, query+` WHERE id = $1 FOR UPDATE`, parsed)
+		secret = strings.TrimSpace(code)
+	default:
+		return nil, shared.ValidationErrorf("token, or challenge_id and code, are required")
+	}
+
+	err = row.Scan(&id, &channel, &destination, &secretHash, &attempts, &expiresAt, &consumedAt)
+	if err == sql.ErrNoRows {
+		return nil, shared.ValidationErrorf("invalid or expired login")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load login challenge: %w", err)
+	}
+	if consumedAt.Valid || time.Now().After(expiresAt) || attempts >= s.MaxAttempts {
+		return nil, shared.ValidationErrorf("invalid or expired login")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(secretHash)) != 1 {
+		if _, err := tx.ExecContext(ctx, `UPDATE account_login_challenges SET attempts = attempts + 1 WHERE id = $1`, id); err != nil {
+			return nil, fmt.Errorf("failed to record login attempt: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to record login attempt: %w", err)
+		}
+		return nil, shared.ValidationErrorf("invalid or expired login")
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE account_login_challenges SET consumed_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to consume login challenge: %w", err)
+	}
+
+	column := "email"
+	if channel == models.LoginChannelSMS {
+		column = "phone"
+	}
+	account := &models.Account{}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO accounts (`+column+`, last_login_at) VALUES ($1, CURRENT_TIMESTAMP)
+		ON CONFLICT (`+column+`) DO UPDATE SET last_login_at = CURRENT_TIMESTAMP
+		RETURNING id, email, phone, created_at, last_login_at
+	`, destination).Scan(&account.ID, &account.Email, &account.Phone, &account.CreatedAt, &account.LastLoginAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert account: %w", err)
+	}
+
+	sessionToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	session := &models.AccountSession{
+		Token:     sessionToken,
+		ExpiresAt: time.Now().Add(s.SessionTTL),
+		Account:   account,
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO account_sessions (account_id, token_hash, expires_at) VALUES ($1, $2, $3)
+	`, account.ID, hashSecret(sessionToken), session.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit login: %w", err)
+	}
+	return session, nil
+}
+
+// ResolveSession returns the account for a bearer token, or nil when the token is
+// unknown, expired or revoked
+func (s *AccountService) ResolveSession(ctx context.Context, token string) (*models.Account, error) {
+	account := &models.Account{}
+	err := s.DB.QueryRowContext(ctx, `
+		UPDATE account_sessions s SET last_seen_at = CURRENT_TIMESTAMP
+		FROM accounts a
+		WHERE s.token_hash = $1 AND s.account_id = a.id
+		  AND s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP
+		RETURNING a.id, a.email, a.phone, a.created_at, a.last_login_at
+	`, hashSecret(token)).Scan(&account.ID, &account.Email, &account.Phone, &account.CreatedAt, &account.LastLoginAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve session: %w", err)
+	}
+	return account, nil
+}
+
+// Logout revokes a session token
+func (s *AccountService) Logout(ctx context.Context, token string) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE account_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`, hashSecret(token))
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpired deletes expired sessions and login challenges
+func (s *AccountService) CleanupExpired(ctx context.Context) (int64, error) {
+	var removed int64
+	for _, query := range []string{
+		`DELETE FROM account_sessions WHERE expires_at < CURRENT_TIMESTAMP OR revoked_at IS NOT NULL`,
+		`DELETE FROM account_login_challenges WHERE expires_at < CURRENT_TIMESTAMP - INTERVAL '1 day'`,
+	} {
+		result, err := s.DB.ExecContext(ctx, query)
+		if err != nil {
+			return removed, fmt.Errorf("failed to clean up account data: %w", err)
+		}
+		affected, _ := result.RowsAffected()
+		removed += affected
+	}
+	return removed, nil
+}
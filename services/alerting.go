@@ -0,0 +1,453 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// Alert metrics; each has a success-rate threshold in AlertConfig.Thresholds
+const (
+	AlertFieldExtraction = "field_extraction" // Daily IPO scrape field extraction success rate
+	AlertGMPParse        = "gmp_parse"        // GMP rows whose value could be parsed
+	AlertRegistrarCheck  = "registrar_check"  // Recent allotment checks that reached the registrar
+)
+
+// Alert is a success rate that dropped below its threshold
+type Alert struct {
+	Key         string                 `json:"key"`
+	Metric      string                 `json:"metric"`
+	Scope       string                 `json:"scope,omitempty"`
+	Rate        float64                `json:"rate"`
+	Threshold   float64                `json:"threshold"`
+	Message     string                 `json:"message"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	TriggeredAt time.Time              `json:"triggered_at"`
+}
+
+// AlertNotifier delivers alerts to an operator channel
+type AlertNotifier interface {
+	Name() string
+	SendAlert(ctx context.Context, alert Alert) error
+}
+
+// SlackAlertNotifier posts alerts to a Slack incoming webhook
+type SlackAlertNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewSlackAlertNotifier creates a Slack notifier for an incoming webhook URL
+func NewSlackAlertNotifier(webhookURL string) *SlackAlertNotifier {
+	return &SlackAlertNotifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the notifier name used in logs
+func (n *SlackAlertNotifier) Name() string {
+	return "slack"
+}
+
+// SendAlert posts the alert message and treats any non-2xx response as a failure
+func (n *SlackAlertNotifier) SendAlert(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{"text": ":rotating_light: " + alert.Message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailAlertNotifier sends alerts through an SMTP server
+type EmailAlertNotifier struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NewEmailAlertNotifier creates an SMTP notifier; recipients are comma-separated
+func NewEmailAlertNotifier(addr, username, password, from, to string) *EmailAlertNotifier {
+	var recipients []string
+	for _, recipient := range strings.Split(to, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return &EmailAlertNotifier{Addr: addr, Username: username, Password: password, From: from, To: recipients}
+}
+
+// Name returns the notifier name used in logs
+func (n *EmailAlertNotifier) Name() string {
+	return "email"
+}
+
+// SendAlert sends the alert as a plain-text email
+func (n *EmailAlertNotifier) SendAlert(ctx context.Context, alert Alert) error {
+	if len(n.To) == 0 {
+		return fmt.Errorf("no email recipients configured")
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\r\n\r\n", alert.Message)
+	keys := make([]string, 0, len(alert.Details))
+	for key := range alert.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&body, "%s: %v\r\n", key, alert.Details[key])
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: [IPO backend] %s alert\r\n\r\n%s",
+		n.From, strings.Join(n.To, ", "), alert.Key, body.String())
+
+	if err := sendSMTPMail(ctx, n.Addr, n.Username, n.Password, n.From, n.To, message); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+	return nil
+}
+
+// sendSMTPMail sends a pre-formatted message, authenticating when a username is set
+func sendSMTPMail(ctx context.Context, addr, username, password, from string, to []string, message string) error {
+	var auth smtp.Auth
+	if username != "" {
+		host := addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	// smtp.SendMail has no context support; run it so the caller's deadline still applies
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from, to, []byte(message))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AlertConfig holds alert thresholds and anti-spam settings
+type AlertConfig struct {
+	Thresholds      map[string]float64 // Success-rate percentage per metric; 0 disables
+	Cooldown        time.Duration      // Minimum time between alerts for the same key
+	CheckWindow     int                // Registrar check outcomes kept per registrar
+	CheckMinSamples int                // Outcomes required before a registrar is evaluated
+	NotifyTimeout   time.Duration
+}
+
+// DefaultAlertConfig returns the default thresholds: 60% field extraction, 70% GMP
+// parsing and 50% registrar checks, with a one hour cool-down
+func DefaultAlertConfig() AlertConfig {
+	return AlertConfig{
+		Thresholds: map[string]float64{
+			AlertFieldExtraction: 60,
+			AlertGMPParse:        70,
+			AlertRegistrarCheck:  50,
+		},
+		Cooldown:        time.Hour,
+		CheckWindow:     50,
+		CheckMinSamples: 20,
+		NotifyTimeout:   15 * time.Second,
+	}
+}
+
+// alertState tracks one alert key for deduplication
+type alertState struct {
+	firing     bool
+	lastSent   time.Time
+	suppressed int64
+	lastRate   float64
+}
+
+// checkOutcomeWindow is a ring buffer of recent registrar check outcomes
+type checkOutcomeWindow struct {
+	outcomes []bool
+	next     int
+	filled   bool
+}
+
+// record adds an outcome and returns the success rate and sample count
+func (w *checkOutcomeWindow) record(success bool) (float64, int) {
+	w.outcomes[w.next] = success
+	w.next = (w.next + 1) % len(w.outcomes)
+	if w.next == 0 {
+		w.filled = true
+	}
+
+	samples := w.next
+	if w.filled {
+		samples = len(w.outcomes)
+	}
+	successes := 0
+	for _, outcome := range w.outcomes[:samples] {
+		if outcome {
+			successes++
+		}
+	}
+	return float64(successes) / float64(samples) * 100, samples
+}
+
+// Alerter raises alerts when success rates fall below their thresholds. An alert key
+// (metric plus optional scope, such as a registrar) fires once when the rate drops;
+// repeats are suppressed until the cool-down elapses, even across a brief recovery,
+// so a flapping rate cannot spam the channel.
+type Alerter struct {
+	mutex     sync.Mutex
+	config    AlertConfig
+	notifiers []AlertNotifier
+	states    map[string]*alertState
+	windows   map[string]*checkOutcomeWindow
+	logger    *logrus.Entry
+}
+
+// NewAlerter creates an alerter; with no notifiers alerts are only logged
+func NewAlerter(config AlertConfig, notifiers []AlertNotifier) *Alerter {
+	a := &Alerter{
+		states:  make(map[string]*alertState),
+		windows: make(map[string]*checkOutcomeWindow),
+		logger:  logrus.WithField("component", "alerting"),
+	}
+	a.Configure(config, notifiers)
+	return a
+}
+
+// DefaultAlerter is used by the scrape jobs and the allotment check queue
+var DefaultAlerter = NewAlerter(DefaultAlertConfig(), nil)
+
+// Configure replaces the thresholds and notifiers, filling unset values with defaults
+func (a *Alerter) Configure(config AlertConfig, notifiers []AlertNotifier) {
+	defaults := DefaultAlertConfig()
+	if config.Thresholds == nil {
+		config.Thresholds = defaults.Thresholds
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	if config.CheckWindow <= 0 {
+		config.CheckWindow = defaults.CheckWindow
+	}
+	if config.CheckMinSamples <= 0 {
+		config.CheckMinSamples = defaults.CheckMinSamples
+	}
+	if config.NotifyTimeout <= 0 {
+		config.NotifyTimeout = defaults.NotifyTimeout
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config = config
+	a.notifiers = notifiers
+	a.windows = make(map[string]*checkOutcomeWindow)
+}
+
+// Evaluate compares a success rate with the metric's threshold and sends an alert
+// when it is below and the key is not in its cool-down. Delivery is asynchronous.
+func (a *Alerter) Evaluate(metric, scope string, rate float64, details map[string]interface{}) {
+	key := metric
+	if scope != "" {
+		key = metric + ":" + scope
+	}
+
+	a.mutex.Lock()
+	threshold := a.config.Thresholds[metric]
+	if threshold <= 0 {
+		a.mutex.Unlock()
+		return
+	}
+
+	state, exists := a.states[key]
+	if !exists {
+		state = &alertState{}
+		a.states[key] = state
+	}
+	state.lastRate = rate
+
+	if rate >= threshold {
+		recovered := state.firing
+		state.firing = false
+		a.mutex.Unlock()
+		if recovered {
+			a.logger.WithFields(logrus.Fields{"key": key, "rate": rate, "threshold": threshold}).
+				Info("Success rate recovered above alert threshold")
+		}
+		return
+	}
+
+	now := time.Now()
+	if !state.lastSent.IsZero() && now.Sub(state.lastSent) < a.config.Cooldown {
+		state.firing = true
+		state.suppressed++
+		a.mutex.Unlock()
+		return
+	}
+	state.firing = true
+	state.lastSent = now
+	notifiers := a.notifiers
+	timeout := a.config.NotifyTimeout
+	a.mutex.Unlock()
+
+	alert := Alert{
+		Key:         key,
+		Metric:      metric,
+		Scope:       scope,
+		Rate:        roundTo(rate, 1),
+		Threshold:   threshold,
+		Details:     details,
+		TriggeredAt: now,
+	}
+	alert.Message = fmt.Sprintf("%s success rate is %.1f%%, below the %.0f%% threshold", key, alert.Rate, threshold)
+
+	a.logger.WithFields(logrus.Fields{
+		"key":       key,
+		"rate":      alert.Rate,
+		"threshold": threshold,
+		"details":   details,
+	}).Warn("Alert triggered")
+
+	for _, notifier := range notifiers {
+		go func(notifier AlertNotifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := notifier.SendAlert(ctx, alert); err != nil {
+				a.logger.WithError(err).WithFields(logrus.Fields{
+					"key":      key,
+					"notifier": notifier.Name(),
+				}).Error("Failed to deliver alert")
+			}
+		}(notifier)
+	}
+}
+
+// RecordCheckOutcome adds a registrar check result to the registrar's rolling window
+// and evaluates it once enough samples exist. Validation errors (bad input) are not
+// the registrar's fault and are ignored.
+func (a *Alerter) RecordCheckOutcome(registrar string, err error) {
+	if shared.ErrorCategoryOf(err) == shared.ErrorCategoryValidation {
+		return
+	}
+	registrar = strings.ToLower(strings.TrimSpace(registrar))
+
+	a.mutex.Lock()
+	window, exists := a.windows[registrar]
+	if !exists {
+		window = &checkOutcomeWindow{outcomes: make([]bool, a.config.CheckWindow)}
+		a.windows[registrar] = window
+	}
+	rate, samples := window.record(err == nil)
+	minSamples := a.config.CheckMinSamples
+	a.mutex.Unlock()
+
+	if samples < minSamples {
+		return
+	}
+	details := map[string]interface{}{"samples": samples}
+	if err != nil {
+		details["last_error"] = err.Error()
+	}
+	a.Evaluate(AlertRegistrarCheck, registrar, rate, details)
+}
+
+// AlertStatus is the current state of one alert key, for the metrics endpoint
+type AlertStatus struct {
+	Key        string     `json:"key"`
+	Firing     bool       `json:"firing"`
+	LastRate   float64    `json:"last_rate"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	Suppressed int64      `json:"suppressed"`
+}
+
+// Snapshot returns the state of every evaluated alert key, sorted by key
+func (a *Alerter) Snapshot() []AlertStatus {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	statuses := make([]AlertStatus, 0, len(a.states))
+	for key, state := range a.states {
+		status := AlertStatus{
+			Key:        key,
+			Firing:     state.firing,
+			LastRate:   roundTo(state.lastRate, 1),
+			Suppressed: state.suppressed,
+		}
+		if !state.lastSent.IsZero() {
+			lastSent := state.lastSent
+			status.LastSentAt = &lastSent
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Key < statuses[j].Key })
+	return statuses
+}
+
+// FieldExtractionRate returns the share of tracked fields populated across a run,
+// and false when the run attempted no fields
+func FieldExtractionRate(run *models.ScrapeRun) (float64, bool) {
+	populated, attempts := 0, 0
+	for _, stat := range run.FieldStats {
+		populated += stat.Populated
+		attempts += stat.Attempts
+	}
+	if attempts == 0 {
+		return 0, false
+	}
+	return float64(populated) / float64(attempts) * 100, true
+}
+
+// GMPParseSuccessRate returns the share of scraped GMP rows whose GMP value was parsed
+func GMPParseSuccessRate(gmpList []models.EnhancedGMPData) float64 {
+	if len(gmpList) == 0 {
+		return 0
+	}
+	parsed := 0
+	for _, gmp := range gmpList {
+		if gmp.ExtractionMetadata == nil || !containsString(gmp.ExtractionMetadata.FailedFields, "gmp_value") {
+			parsed++
+		}
+	}
+	return float64(parsed) / float64(len(gmpList)) * 100
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -26,10 +27,28 @@ func NewAllotmentChecker() *AllotmentChecker {
 	}
 }
 
-// CheckAllotmentStatus checks the allotment status for a given IPO and PAN
+// CheckAllotmentStatus checks the allotment status for a given IPO and PAN. Returned
+// errors have PANs, application numbers and demat IDs masked, since they quote registrar
+// responses and reach logs and API clients.
 func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models.IPO, pan string) (string, int, error) {
-	// Apply rate limiting for politeness
-	a.RateLimiter.EnforceRateLimit()
+	return a.CheckAllotmentStatusTimed(ctx, ipo, pan, nil)
+}
+
+// CheckAllotmentStatusTimed checks allotment like CheckAllotmentStatus and records the
+// rate limit wait, registrar request and response parsing stages in stages
+func (a *AllotmentChecker) CheckAllotmentStatusTimed(ctx context.Context, ipo *models.IPO, pan string, stages *shared.StageTimer) (string, int, error) {
+	status, shares, err := a.checkAllotmentStatus(ctx, ipo, pan, stages)
+	return status, shares, shared.ScrubError(err)
+}
+
+func (a *AllotmentChecker) checkAllotmentStatus(ctx context.Context, ipo *models.IPO, pan string, stages *shared.StageTimer) (string, int, error) {
+	// Apply rate limiting for politeness; a cancelled check stops waiting
+	endWait := stages.Start(CheckStageRateLimitWait)
+	err := a.RateLimiter.Wait(ctx)
+	endWait()
+	if err != nil {
+		return "", 0, fmt.Errorf("rate limit wait cancelled: %w", err)
+	}
 
 	// 1. Parse Configs
 	var formFields map[string]string
@@ -47,6 +66,7 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 		StatusSelectors struct {
 			Allotted    []string `json:"allotted"`
 			NotAllotted []string `json:"not_allotted"`
+			NotDeclared []string `json:"not_declared"`
 		} `json:"status_selectors"`
 	}
 	var parserConfig ParserConfig
@@ -97,8 +117,11 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 			}
 		})
 		if ipo.FormURL != nil {
-			if err := c.Visit(*ipo.FormURL); err != nil {
-				return "", 0, fmt.Errorf("failed to scrape form page: %w", err)
+			endVisit := stages.Start(CheckStageRegistrarRequest)
+			err := c.Visit(*ipo.FormURL)
+			endVisit()
+			if err != nil {
+				return "", 0, shared.NetworkErrorf("failed to scrape form page: %w", err)
 			}
 		} else {
 			return "", 0, fmt.Errorf("IPO FormURL is nil, cannot scrape form page")
@@ -141,20 +164,28 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to marshal payload: %w", err)
 	}
-	logrus.Infof("Final JSON Payload: %s", string(jsonPayload))
+	logrus.Infof("Final JSON Payload: %s", shared.ScrubPII(string(jsonPayload)))
 
 	var status string = "NOT_FOUND"
 	var shares int = 0
 
 	var errorBody string
+	var errorStatus int
 	// Log Error Response
+	var requestStart time.Time
+	// Time spent in the response callbacks below, split from the registrar request time
+	var parsing time.Duration
 	c.OnError(func(r *colly.Response, err error) {
 		errorBody = string(r.Body)
-		logrus.Errorf("Scraper Error: %v, Body: %s", err, errorBody)
+		errorStatus = r.StatusCode
+		logrus.Errorf("Scraper Error: %v, Body: %s", err, shared.ScrubPII(errorBody))
+		recordRegistrarFailure(r, jsonPayload, time.Since(requestStart), err)
 	})
 
 	// Parse Response (Handle JSON response if Content-Type is JSON)
 	c.OnResponse(func(r *colly.Response) {
+		parseStart := time.Now()
+		defer func() { parsing += time.Since(parseStart) }()
 		if len(r.Body) > 0 && (r.Headers.Get("Content-Type") == "application/json" || r.Headers.Get("content-type") == "application/json; charset=utf-8") {
 			// Try to parse JSON response
 			var resp map[string]interface{}
@@ -186,9 +217,13 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 						}
 					}
 
+					if status == "NOT_FOUND" && resultNotDeclared(doc.Selection, parserConfig.StatusSelectors.NotDeclared) {
+						status = models.AllotmentStatusNotDeclared
+					}
+
 					// If still not found, log the HTML for debugging
 					if status == "NOT_FOUND" {
-						logrus.Warnf("Status not found in response HTML: %s", d)
+						logrus.Warnf("Status not found in response HTML: %s", shared.ScrubPII(d))
 					}
 				}
 			}
@@ -197,6 +232,8 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 
 	// Fallback HTML parsing
 	c.OnHTML("html", func(e *colly.HTMLElement) {
+		parseStart := time.Now()
+		defer func() { parsing += time.Since(parseStart) }()
 		// Check Allotted
 		for _, selector := range parserConfig.StatusSelectors.Allotted {
 			if e.DOM.Find(selector).Length() > 0 {
@@ -211,24 +248,73 @@ func (a *AllotmentChecker) CheckAllotmentStatus(ctx context.Context, ipo *models
 				return
 			}
 		}
+		if status == "NOT_FOUND" && resultNotDeclared(e.DOM, parserConfig.StatusSelectors.NotDeclared) {
+			status = models.AllotmentStatusNotDeclared
+		}
 	})
 
 	if targetURL == nil {
 		return "", 0, fmt.Errorf("target URL is nil, cannot make request")
 	}
 
+	requestStart = time.Now()
 	err = c.PostRaw(*targetURL, jsonPayload)
+	stages.Add(CheckStageRegistrarRequest, time.Since(requestStart)-parsing)
+	stages.Add(CheckStageParsing, parsing)
 	if err != nil {
 		// The error might be from OnError, so we check if we got a status
 		if status != "NOT_FOUND" {
 			return status, shares, nil
 		}
-		return "", 0, fmt.Errorf("failed to post to registrar: %w, Body: %s", err, errorBody)
+		if errorStatus > 0 {
+			return "", 0, fmt.Errorf("failed to post to registrar: %w, Body: %s", &shared.HTTPStatusError{StatusCode: errorStatus}, errorBody)
+		}
+		return "", 0, shared.NetworkErrorf("failed to post to registrar: %w, Body: %s", err, errorBody)
 	}
 
 	return status, shares, nil
 }
 
+// resultNotDeclaredPattern matches the wording registrars use before allotment is
+// finalised, e.g. "Allotment not yet finalised" or "Results will be available shortly"
+var resultNotDeclaredPattern = regexp.MustCompile(`(?i)(not\s+(yet\s+)?(been\s+)?(declared|finali[sz]ed|published|announced|released)|yet\s+to\s+be\s+(declared|finali[sz]ed|published|announced|released)|(results?|allotment|basis\s+of\s+allotment)\s+(will\s+be|is\s+not)\s+(available|declared|published|updated))`)
+
+// resultNotDeclared reports whether a registrar response says allotment results are not
+// published yet, by the IPO's not_declared selectors or by the usual wording
+func resultNotDeclared(document *goquery.Selection, selectors []string) bool {
+	for _, selector := range selectors {
+		if document.Find(selector).Length() > 0 {
+			return true
+		}
+	}
+	return resultNotDeclaredPattern.MatchString(document.Text())
+}
+
+// recordRegistrarFailure keeps a failed registrar exchange in the HTTP recorder; the
+// recorder masks the PAN in the payload
+func recordRegistrarFailure(response *colly.Response, payload []byte, duration time.Duration, err error) {
+	if !shared.DefaultHTTPRecorder.Enabled() || response.Request == nil {
+		return
+	}
+	recording := shared.HTTPRecording{
+		Source:       "registrar",
+		Method:       response.Request.Method,
+		URL:          response.Request.URL.String(),
+		RequestBody:  string(payload),
+		StatusCode:   response.StatusCode,
+		ResponseBody: string(response.Body),
+		DurationMs:   duration.Milliseconds(),
+		Error:        err.Error(),
+	}
+	if response.Request.Headers != nil {
+		recording.RequestHeaders = *response.Request.Headers
+	}
+	if response.Headers != nil {
+		recording.ResponseHeaders = *response.Headers
+	}
+	shared.DefaultHTTPRecorder.Record(recording)
+}
+
 // reflectKeys returns the keys of a map
 func (a *AllotmentChecker) reflectKeys(data map[string]interface{}) []string {
 	keys := make([]string, 0, len(data))
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/ledongthuc/pdf"
+)
+
+// MaxBasisOfAllotmentBytes bounds the basis of allotment PDF downloaded for one IPO
+const MaxBasisOfAllotmentBytes = 20 << 20
+
+var (
+	allotmentRatioPattern        = regexp.MustCompile(`\b(\d{1,7})\s*:\s*(\d{1,7})\b`)
+	allotmentSubscriptionPattern = regexp.MustCompile(`(?i)subscribed(?:\s+to\s+the\s+extent\s+of)?\s+(?:about\s+)?([\d,]+(?:\.\d+)?)\s*times`)
+)
+
+// AllotmentRatioService ingests the category-wise allotment ratios registrars publish in
+// basis of allotment PDFs, and reports allotment chances from them or, until they are
+// out, from the latest subscription multiples
+type AllotmentRatioService struct {
+	DB            *sql.DB
+	Subscriptions *SubscriptionService
+	Client        *http.Client
+	Policy        shared.RetryPolicy
+}
+
+func NewAllotmentRatioService(db *sql.DB, subscriptions *SubscriptionService) *AllotmentRatioService {
+	return &AllotmentRatioService{
+		DB:            db,
+		Subscriptions: subscriptions,
+		Client:        shared.NewHTTPClientFactory(60 * time.Second).CreateOptimizedHTTPClient(60 * time.Second),
+		Policy:        shared.DefaultHTTPRetryPolicy().WithMaxRetries(2),
+	}
+}
+
+// IngestBasisOfAllotment downloads the basis of allotment PDF at pdfURL, parses its
+// category-wise ratios and replaces the IPO's stored ratios with them
+func (s *AllotmentRatioService) IngestBasisOfAllotment(ctx context.Context, ipoID uuid.UUID, pdfURL string) ([]models.AllotmentRatio, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+		return nil, shared.ValidationErrorf("pdf_url must be an http(s) URL")
+	}
+	shared.SetBrowserLikeHeaders(req, "application/pdf")
+
+	var exists bool
+	if err := s.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ipo_list WHERE id = $1)`, ipoID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up IPO: %w", err)
+	}
+	if !exists {
+		return nil, shared.NotFoundErrorf("IPO %s not found", ipoID)
+	}
+
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(s.Client, req, s.Policy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBasisOfAllotmentBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download basis of allotment: %w", err)
+	}
+	if len(data) > MaxBasisOfAllotmentBytes {
+		return nil, shared.ValidationErrorf("basis of allotment PDF exceeds %d MB", MaxBasisOfAllotmentBytes>>20)
+	}
+
+	lines, err := extractPDFLines(data)
+	if err != nil {
+		return nil, shared.ParseErrorf("failed to read basis of allotment PDF: %v", err)
+	}
+	ratios := ParseBasisOfAllotment(lines)
+	if len(ratios) == 0 {
+		return nil, shared.ParseErrorf("no allotment ratios found in basis of allotment PDF")
+	}
+
+	ingestedAt := time.Now()
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin allotment ratio update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ipo_allotment_ratios WHERE ipo_id = $1`, ipoID); err != nil {
+		return nil, fmt.Errorf("failed to replace allotment ratios: %w", err)
+	}
+	for i := range ratios {
+		ratios[i].SourceURL = pdfURL
+		ratios[i].IngestedAt = ingestedAt
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_allotment_ratios (ipo_id, category, subscription_times, allottees, applicants, source_url, ingested_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, ipoID, ratios[i].Category, ratios[i].SubscriptionTimes, ratios[i].Allottees, ratios[i].Applicants,
+			pdfURL, ingestedAt); err != nil {
+			return nil, fmt.Errorf("failed to save allotment ratio: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit allotment ratios: %w", err)
+	}
+	return ratios, nil
+}
+
+// extractPDFLines returns the text rows of every page, top to bottom, with the pieces of
+// each row joined by spaces
+func extractPDFLines(data []byte) ([]string, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for number := 1; number <= reader.NumPage(); number++ {
+		page := reader.Page(number)
+		if page.V.IsNull() {
+			continue
+		}
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", number, err)
+		}
+		// Rows come sorted top to bottom, each row's pieces left to right
+		for _, row := range rows {
+			parts := make([]string, 0, len(row.Content))
+			for _, text := range row.Content {
+				parts = append(parts, text.S)
+			}
+			if line := strings.Join(strings.Fields(strings.Join(parts, " ")), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, nil
+}
+
+// ParseBasisOfAllotment reads category-wise ratios from the text lines of a basis of
+// allotment. Each "Allotment to ..." heading starts a category; its first ratio is the
+// minimum application's lottery and "subscribed N times" its subscription. Anchor
+// investors are skipped, as their allocation is not a lottery.
+func ParseBasisOfAllotment(lines []string) []models.AllotmentRatio {
+	var ratios []models.AllotmentRatio
+	byCategory := make(map[string]int)
+	current := ""
+
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "allotment to") {
+			current = basisOfAllotmentCategory(lower)
+			if _, seen := byCategory[current]; current != "" && !seen {
+				byCategory[current] = len(ratios)
+				ratios = append(ratios, models.AllotmentRatio{Category: current})
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		ratio := &ratios[byCategory[current]]
+
+		if match := allotmentSubscriptionPattern.FindStringSubmatch(line); match != nil && ratio.SubscriptionTimes == nil {
+			if times, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64); err == nil {
+				ratio.SubscriptionTimes = &times
+			}
+		}
+		if match := allotmentRatioPattern.FindStringSubmatch(line); match != nil && ratio.Applicants == 0 {
+			allottees, _ := strconv.Atoi(match[1])
+			applicants, _ := strconv.Atoi(match[2])
+			if allottees > 0 && applicants > 0 && allottees <= applicants {
+				ratio.Allottees, ratio.Applicants = allottees, applicants
+				ratio.Ratio = fmt.Sprintf("%d:%d", allottees, applicants)
+			}
+		}
+	}
+
+	parsed := ratios[:0]
+	for _, ratio := range ratios {
+		if ratio.Applicants > 0 {
+			parsed = append(parsed, ratio)
+		}
+	}
+	return parsed
+}
+
+// basisOfAllotmentCategory maps a lower-cased "Allotment to ..." heading to a
+// subscription category; non-institutional headings are split by application size
+func basisOfAllotmentCategory(heading string) string {
+	if strings.Contains(heading, "anchor") {
+		return ""
+	}
+	if strings.Contains(heading, "non-institutional") || strings.Contains(heading, "non institutional") {
+		switch {
+		case strings.Contains(heading, "up to") || strings.Contains(heading, "upto"):
+			return models.SubscriptionSNII
+		case strings.Contains(heading, "10 lakh"):
+			return models.SubscriptionBNII
+		}
+		return models.SubscriptionNII
+	}
+	category := subscriptionCategory(heading)
+	if category == models.SubscriptionTotal {
+		return ""
+	}
+	return category
+}
+
+// GetAllotmentRatios returns the ratios ingested for an IPO, in category order
+func (s *AllotmentRatioService) GetAllotmentRatios(ctx context.Context, ipoID uuid.UUID) ([]models.AllotmentRatio, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT category, subscription_times, allottees, applicants, source_url, ingested_at
+		FROM ipo_allotment_ratios
+		WHERE ipo_id = $1
+	`, ipoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query allotment ratios: %w", err)
+	}
+	defer rows.Close()
+
+	ratios := []models.AllotmentRatio{}
+	for rows.Next() {
+		var ratio models.AllotmentRatio
+		if err := rows.Scan(&ratio.Category, &ratio.SubscriptionTimes, &ratio.Allottees, &ratio.Applicants,
+			&ratio.SourceURL, &ratio.IngestedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan allotment ratio: %w", err)
+		}
+		ratio.Ratio = fmt.Sprintf("%d:%d", ratio.Allottees, ratio.Applicants)
+		ratios = append(ratios, ratio)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read allotment ratios: %w", err)
+	}
+	sort.SliceStable(ratios, func(i, j int) bool {
+		return subscriptionCategoryRank(ratios[i].Category) < subscriptionCategoryRank(ratios[j].Category)
+	})
+	return ratios, nil
+}
+
+// lotteryCategories are the categories whose minimum application is one lot, so their
+// lots go to applicants by lottery when applications outnumber them
+var lotteryCategories = map[string]bool{
+	models.SubscriptionRetail:      true,
+	models.SubscriptionEmployee:    true,
+	models.SubscriptionShareholder: true,
+}
+
+// GetAllotmentProbability returns the official per-category chances once the basis of
+// allotment was ingested. Before that each category is estimated from its latest
+// snapshot: lottery categories with application counts, shares offered and a known lot
+// size as one over applications per lot, the rest as one over the subscription
+// multiple, both capped at 1. The IPO is not_found when it has neither.
+func (s *AllotmentRatioService) GetAllotmentProbability(ctx context.Context, ipoID uuid.UUID) (*models.AllotmentProbability, error) {
+	ratios, err := s.GetAllotmentRatios(ctx, ipoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ratios) > 0 {
+		result := &models.AllotmentProbability{
+			IPOID:      ipoID,
+			Official:   true,
+			Source:     models.AllotmentSourceBasisOfAllotment,
+			SourceURL:  ratios[0].SourceURL,
+			AsOf:       &ratios[0].IngestedAt,
+			Categories: make([]models.CategoryAllotmentProbability, 0, len(ratios)),
+		}
+		for _, ratio := range ratios {
+			result.Categories = append(result.Categories, models.CategoryAllotmentProbability{
+				Category:          ratio.Category,
+				Probability:       roundTo(float64(ratio.Allottees)/float64(ratio.Applicants), 4),
+				Ratio:             ratio.Ratio,
+				SubscriptionTimes: ratio.SubscriptionTimes,
+			})
+		}
+		return result, nil
+	}
+
+	history, err := s.Subscriptions.GetHistory(ctx, ipoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Points) == 0 {
+		return nil, shared.NotFoundErrorf("no allotment ratios or subscription data for IPO %s", ipoID)
+	}
+	var minQty sql.NullInt64
+	if err := s.DB.QueryRowContext(ctx, `SELECT min_qty FROM ipo_list WHERE id = $1`, ipoID).Scan(&minQty); err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to look up IPO lot size: %w", err)
+	}
+
+	latest := history.Points[len(history.Points)-1]
+	result := &models.AllotmentProbability{
+		IPOID:      ipoID,
+		Source:     models.AllotmentSourceSubscription,
+		AsOf:       &latest.RecordedAt,
+		Categories: []models.CategoryAllotmentProbability{},
+	}
+	for _, category := range history.Categories {
+		multiple, ok := latest.Multiples[category]
+		if !ok || category == models.SubscriptionTotal || multiple <= 0 {
+			continue
+		}
+		times := multiple
+		estimate := models.CategoryAllotmentProbability{
+			Category:          category,
+			Probability:       roundTo(math.Min(1, 1/multiple), 4),
+			SubscriptionTimes: &times,
+			Basis:             models.AllotmentBasisSubscription,
+		}
+		if applications, ok := latest.Applications[category]; ok && applications > 0 {
+			estimate.Applications = &applications
+			if perLot, ok := applicationsPerLot(applications, latest.SharesOffered[category], minQty.Int64); ok && lotteryCategories[category] {
+				estimate.ApplicationsPerLot = &perLot
+				estimate.Probability = roundTo(math.Min(1, 1/perLot), 4)
+				estimate.Basis = models.AllotmentBasisApplications
+			}
+		}
+		result.Categories = append(result.Categories, estimate)
+	}
+	return result, nil
+}
+
+// applicationsPerLot divides a category's applications by the minimum lots its shares
+// offered make up; false when the shares offered or lot size are unknown or too few
+// for one lot
+func applicationsPerLot(applications, sharesOffered, lotSize int64) (float64, bool) {
+	if applications <= 0 || lotSize <= 0 {
+		return 0, false
+	}
+	lots := sharesOffered / lotSize
+	if lots <= 0 {
+		return 0, false
+	}
+	return roundTo(float64(applications)/float64(lots), 2), true
+}
+
+// subscriptionCategoryRank orders categories as in subscription history responses
+func subscriptionCategoryRank(category string) int {
+	for i, candidate := range subscriptionCategoryOrder {
+		if candidate == category {
+			return i
+		}
+	}
+	return len(subscriptionCategoryOrder)
+}
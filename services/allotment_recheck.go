@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// RecheckMaxAge is how long a scheduled re-check waits for results before it expires
+const RecheckMaxAge = 14 * 24 * time.Hour
+
+// MaxRechecksPerRun caps the registrar calls one run of the due re-checks makes; the
+// rest wait for the next run
+const MaxRechecksPerRun = 200
+
+// AllotmentRecheckService re-runs allotment checks that the registrar answered with
+// "results not declared". The PAN is kept encrypted with the PAN vault key until the
+// re-check completes or expires, so scheduling needs PAN_VAULT_KEY.
+type AllotmentRecheckService struct {
+	DB      *sql.DB
+	Checker *AllotmentChecker
+	Cache   *CacheService
+	IPOs    *IPOService
+	// Telegram, when set, sends re-check results to the chat that ran the check
+	Telegram *TelegramBot
+	// CachedResultTTL is how long re-check results stay in the result cache
+	CachedResultTTL time.Duration
+
+	cipher *PANCipher
+	logger *logrus.Entry
+}
+
+// NewAllotmentRecheckService creates a re-check service; a nil cipher leaves it disabled
+func NewAllotmentRecheckService(db *sql.DB, checker *AllotmentChecker, cache *CacheService, ipos *IPOService, panCipher *PANCipher) *AllotmentRecheckService {
+	return &AllotmentRecheckService{
+		DB:              db,
+		Checker:         checker,
+		Cache:           cache,
+		IPOs:            ipos,
+		CachedResultTTL: DefaultCheckQueueConfig().CachedResultTTL,
+		cipher:          panCipher,
+		logger:          logrus.WithField("component", "allotment_recheck"),
+	}
+}
+
+// Enabled reports whether re-checks can be scheduled
+func (s *AllotmentRecheckService) Enabled() bool {
+	return s != nil && s.DB != nil && s.cipher != nil
+}
+
+// Schedule stores a pending re-check of pan for the IPO. Scheduling the same PAN and
+// IPO again replaces the earlier re-check, keeping its Telegram chat when the new
+// request has none.
+func (s *AllotmentRecheckService) Schedule(ctx context.Context, ipoID uuid.UUID, pan string, meta CheckRequestMeta) error {
+	if !s.Enabled() {
+		return fmt.Errorf("allotment re-checks are not enabled")
+	}
+
+	panHash := shared.HashPAN(pan)
+	encrypted, err := s.cipher.Encrypt(shared.NormalizePAN(pan), panHash)
+	if err != nil {
+		return err
+	}
+	var chatID *int64
+	if meta.TelegramChatID != 0 {
+		chatID = &meta.TelegramChatID
+	}
+
+	if _, err := s.DB.ExecContext(ctx, `
+		INSERT INTO allotment_rechecks (ipo_id, pan_hash, pan_encrypted, source_channel, telegram_chat_id, status, owner_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (ipo_id, pan_hash) DO UPDATE SET
+			pan_encrypted = EXCLUDED.pan_encrypted,
+			source_channel = EXCLUDED.source_channel,
+			telegram_chat_id = COALESCE(EXCLUDED.telegram_chat_id, allotment_rechecks.telegram_chat_id),
+			owner_hash = COALESCE(allotment_rechecks.owner_hash, EXCLUDED.owner_hash),
+			status = EXCLUDED.status,
+			result_status = NULL,
+			attempts = 0,
+			last_attempt_at = NULL,
+			created_at = CURRENT_TIMESTAMP,
+			completed_at = NULL
+	`, ipoID, panHash, encrypted, meta.SourceChannel, chatID, models.RecheckStatusPending,
+		sql.NullString{String: meta.OwnerHash, Valid: meta.OwnerHash != ""}); err != nil {
+		return fmt.Errorf("failed to schedule allotment re-check: %w", err)
+	}
+	return nil
+}
+
+// RunDue expires stale re-checks and probes each IPO with pending re-checks whose result
+// date has arrived. The oldest pending re-check of an IPO goes first; while the registrar
+// still answers "not declared" the IPO's other re-checks wait for the next run. Once it
+// answers, the rest are run and every result is stored and sent to its user. Returns how
+// many re-checks completed.
+func (s *AllotmentRecheckService) RunDue(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	expired, err := s.DB.ExecContext(ctx, `
+		UPDATE allotment_rechecks SET status = $1, pan_encrypted = NULL
+		WHERE status = $2 AND created_at < $3
+	`, models.RecheckStatusExpired, models.RecheckStatusPending, time.Now().Add(-RecheckMaxAge))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire allotment re-checks: %w", err)
+	}
+	if count, _ := expired.RowsAffected(); count > 0 {
+		s.logger.WithField("count", count).Info("Expired allotment re-checks that never saw results")
+	}
+
+	ipoIDs, err := s.pendingIPOs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	completed, budget := 0, MaxRechecksPerRun
+	for _, ipoID := range ipoIDs {
+		if budget <= 0 || ctx.Err() != nil {
+			break
+		}
+		ipo, err := s.IPOs.GetIPOByID(ctx, ipoID.String())
+		if err != nil || ipo == nil {
+			s.logger.WithError(err).WithField("ipo_id", ipoID).Warn("Skipping re-checks of an IPO that could not be loaded")
+			continue
+		}
+		if ipo.ResultDate != nil && ipo.ResultDate.After(time.Now()) {
+			continue
+		}
+
+		done, attempted, err := s.runIPO(ctx, ipo, budget)
+		completed += done
+		budget -= attempted
+		if err != nil {
+			s.logger.WithError(err).WithField("ipo_id", ipoID).Warn("Allotment re-checks of an IPO failed")
+		}
+	}
+	return completed, nil
+}
+
+func (s *AllotmentRecheckService) pendingIPOs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT ipo_id FROM allotment_rechecks WHERE status = $1
+		GROUP BY ipo_id ORDER BY MIN(created_at)
+	`, models.RecheckStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending allotment re-checks: %w", err)
+	}
+	defer rows.Close()
+
+	var ipoIDs []uuid.UUID
+	for rows.Next() {
+		var ipoID uuid.UUID
+		if err := rows.Scan(&ipoID); err != nil {
+			return nil, fmt.Errorf("failed to scan pending allotment re-check: %w", err)
+		}
+		ipoIDs = append(ipoIDs, ipoID)
+	}
+	return ipoIDs, rows.Err()
+}
+
+// pendingRecheck is a pending re-check with its encrypted PAN
+type pendingRecheck struct {
+	models.AllotmentRecheck
+	panEncrypted []byte
+	ownerHash    sql.NullString
+}
+
+// runIPO runs up to limit pending re-checks of one IPO, stopping at the first that is
+// still not declared or fails. Returns how many completed and how many were attempted.
+func (s *AllotmentRecheckService) runIPO(ctx context.Context, ipo *models.IPO, limit int) (int, int, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, ipo_id, pan_hash, pan_encrypted, source_channel, telegram_chat_id, status, attempts, created_at,
+		       owner_hash
+		FROM allotment_rechecks
+		WHERE ipo_id = $1 AND status = $2
+		ORDER BY created_at
+		LIMIT $3
+	`, ipo.ID, models.RecheckStatusPending, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query allotment re-checks: %w", err)
+	}
+	var rechecks []pendingRecheck
+	for rows.Next() {
+		var recheck pendingRecheck
+		if err := rows.Scan(&recheck.ID, &recheck.IPOID, &recheck.PanHash, &recheck.panEncrypted,
+			&recheck.SourceChannel, &recheck.TelegramChatID, &recheck.Status, &recheck.Attempts, &recheck.CreatedAt,
+			&recheck.ownerHash); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan allotment re-check: %w", err)
+		}
+		rechecks = append(rechecks, recheck)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read allotment re-checks: %w", err)
+	}
+
+	completed := 0
+	for i, recheck := range rechecks {
+		if ctx.Err() != nil {
+			return completed, i, ctx.Err()
+		}
+
+		pan, err := s.cipher.Decrypt(recheck.panEncrypted, recheck.PanHash)
+		if err != nil {
+			return completed, i + 1, err
+		}
+		status, shares, err := s.Checker.CheckAllotmentStatus(ctx, ipo, pan)
+		if err == nil && status == models.AllotmentStatusNotDeclared && s.Cache != nil {
+			s.Cache.MarkResultsNotDeclared(ipo.ID, DefaultCheckQueueConfig().NotDeclaredTTL)
+		}
+		if err != nil || status == models.AllotmentStatusNotDeclared {
+			if _, updateErr := s.DB.ExecContext(ctx, `
+				UPDATE allotment_rechecks SET attempts = attempts + 1, last_attempt_at = CURRENT_TIMESTAMP WHERE id = $1
+			`, recheck.ID); updateErr != nil {
+				s.logger.WithError(updateErr).Warn("Failed to record allotment re-check attempt")
+			}
+			return completed, i + 1, err
+		}
+
+		result := &models.IPOResultCache{
+			PanHash:        recheck.PanHash,
+			IPOID:          ipo.ID,
+			Status:         status,
+			SharesAllotted: shares,
+			Source:         "recheck",
+			SourceChannel:  recheck.SourceChannel,
+			OwnerHash:      recheck.ownerHash.String,
+			Timestamp:      time.Now(),
+		}
+		if err := s.complete(ctx, ipo, &recheck.AllotmentRecheck, result); err != nil {
+			return completed, i + 1, err
+		}
+		completed++
+	}
+	return completed, len(rechecks), nil
+}
+
+// complete stores a re-check result, marks the re-check done with its PAN dropped and
+// notifies the user: an outbox event for webhook consumers, and a Telegram message when
+// the check came from a chat
+func (s *AllotmentRecheckService) complete(ctx context.Context, ipo *models.IPO, recheck *models.AllotmentRecheck, result *models.IPOResultCache) error {
+	// NOT_FOUND may still change, so only definitive results go to the result cache
+	if s.Cache != nil && (result.Status == "ALLOTTED" || result.Status == "NOT_ALLOTTED") {
+		toStore := *result
+		toStore.ExpiresAt = toStore.Timestamp.Add(s.CachedResultTTL)
+		if err := s.Cache.StoreResult(ctx, &toStore); err != nil {
+			s.logger.WithError(err).Warn("Failed to cache allotment re-check result")
+		}
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE allotment_rechecks
+		SET status = $2, result_status = $3, attempts = attempts + 1, last_attempt_at = CURRENT_TIMESTAMP,
+			completed_at = CURRENT_TIMESTAMP, pan_encrypted = NULL
+		WHERE id = $1
+	`, recheck.ID, models.RecheckStatusCompleted, result.Status); err != nil {
+		return fmt.Errorf("failed to complete allotment re-check: %w", err)
+	}
+	if err := EnqueueOutboxEvent(ctx, tx, models.EventAllotmentRechecked, "ipo", ipo.ID.String(), map[string]interface{}{
+		"recheck_id":      recheck.ID,
+		"ipo_id":          ipo.ID,
+		"ipo_name":        ipo.Name,
+		"pan_hash":        recheck.PanHash,
+		"status":          result.Status,
+		"shares_allotted": result.SharesAllotted,
+		"source_channel":  recheck.SourceChannel,
+		"checked_at":      result.Timestamp,
+	}); err != nil {
+		return err
+	}
+	// Results are out, so every replica stops answering checks of the IPO as not declared
+	if err := NotifyCacheInvalidation(ctx, tx, CacheInvalidation{Prefix: NotDeclaredKey(ipo.ID)}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit allotment re-check: %w", err)
+	}
+
+	if s.Telegram != nil && recheck.TelegramChatID != nil {
+		s.Telegram.SendRecheckResult(ctx, *recheck.TelegramChatID, ipo.Name, result)
+	}
+	return nil
+}
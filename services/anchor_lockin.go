@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+)
+
+// Anchor lock-in periods, in days after listing, used when the scraped page has no dates
+const (
+	AnchorLockInShortDays = 30
+	AnchorLockInLongDays  = 90
+)
+
+// completeAnchorLockIns fills lock-in dates the page did not publish from the listing date
+func completeAnchorLockIns(anchor *models.AnchorAllocation, listingDate *time.Time) {
+	if listingDate == nil {
+		return
+	}
+	if anchor.LockIn30Date == nil {
+		date := listingDate.AddDate(0, 0, AnchorLockInShortDays)
+		anchor.LockIn30Date = &date
+		anchor.LockInEstimated = true
+	}
+	if anchor.LockIn90Date == nil {
+		date := listingDate.AddDate(0, 0, AnchorLockInLongDays)
+		anchor.LockIn90Date = &date
+		anchor.LockInEstimated = true
+	}
+}
+
+// saveAnchorAllocation upserts an IPO's anchor allocation inside the caller's transaction
+// and reports whether the stored allocation changed
+func saveAnchorAllocation(ctx context.Context, tx *sql.Tx, ipoID uuid.UUID, anchor *models.AnchorAllocation, listingDate *time.Time) (bool, error) {
+	completeAnchorLockIns(anchor, listingDate)
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO ipo_anchor_allocations (
+			ipo_id, bid_date, shares_offered, amount_crore,
+			lockin_30_date, lockin_90_date, lockin_estimated, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+		ON CONFLICT (ipo_id) DO UPDATE SET
+			bid_date = EXCLUDED.bid_date,
+			shares_offered = EXCLUDED.shares_offered,
+			amount_crore = EXCLUDED.amount_crore,
+			lockin_30_date = EXCLUDED.lockin_30_date,
+			lockin_90_date = EXCLUDED.lockin_90_date,
+			lockin_estimated = EXCLUDED.lockin_estimated,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE (ipo_anchor_allocations.bid_date, ipo_anchor_allocations.shares_offered, ipo_anchor_allocations.amount_crore,
+			ipo_anchor_allocations.lockin_30_date, ipo_anchor_allocations.lockin_90_date, ipo_anchor_allocations.lockin_estimated)
+			IS DISTINCT FROM (EXCLUDED.bid_date, EXCLUDED.shares_offered, EXCLUDED.amount_crore,
+			EXCLUDED.lockin_30_date, EXCLUDED.lockin_90_date, EXCLUDED.lockin_estimated)
+	`, ipoID, anchor.BidDate, anchor.SharesOffered, anchor.AmountCrore,
+		anchor.LockIn30Date, anchor.LockIn90Date, anchor.LockInEstimated)
+	if err != nil {
+		return false, fmt.Errorf("failed to save anchor allocation: %w", err)
+	}
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save anchor allocation: %w", err)
+	}
+	return saved > 0, nil
+}
+
+// GetLockInCalendar returns anchor lock-in tranches expiring within the next days,
+// soonest first. Each IPO contributes up to two entries (30-day and 90-day).
+func (s *IPOService) GetLockInCalendar(ctx context.Context, days int) ([]models.LockInExpiry, error) {
+	query := `
+		SELECT ipo_id, name, company_code, symbol, tranche, expiry_date,
+		       shares_offered, amount_crore, lockin_estimated
+		FROM (
+			SELECT a.ipo_id, i.name, i.company_code, i.symbol, $2::text AS tranche,
+			       a.lockin_30_date AS expiry_date, a.shares_offered, a.amount_crore, a.lockin_estimated
+			FROM ipo_anchor_allocations a
+			JOIN ipo_list i ON i.id = a.ipo_id
+			WHERE a.lockin_30_date IS NOT NULL
+			UNION ALL
+			SELECT a.ipo_id, i.name, i.company_code, i.symbol, $3::text AS tranche,
+			       a.lockin_90_date AS expiry_date, a.shares_offered, a.amount_crore, a.lockin_estimated
+			FROM ipo_anchor_allocations a
+			JOIN ipo_list i ON i.id = a.ipo_id
+			WHERE a.lockin_90_date IS NOT NULL
+		) tranches
+		WHERE expiry_date >= CURRENT_DATE AND expiry_date <= CURRENT_DATE + $1::int
+		ORDER BY expiry_date, name
+	`
+
+	rows, err := s.DB.QueryContext(ctx, query, days, models.LockInTranche30Day, models.LockInTranche90Day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lock-in calendar: %w", err)
+	}
+	defer rows.Close()
+
+	today := time.Now().Truncate(24 * time.Hour)
+	expiries := []models.LockInExpiry{}
+	for rows.Next() {
+		var expiry models.LockInExpiry
+		var shares sql.NullInt64
+		var amount sql.NullFloat64
+		if err := rows.Scan(&expiry.IPOID, &expiry.Name, &expiry.CompanyCode, &expiry.Symbol,
+			&expiry.Tranche, &expiry.ExpiryDate, &shares, &amount, &expiry.Estimated); err != nil {
+			return nil, fmt.Errorf("failed to scan lock-in expiry: %w", err)
+		}
+
+		// Each tranche is half of the anchor portion
+		if shares.Valid {
+			half := int(shares.Int64 / 2)
+			expiry.SharesUnlocking = &half
+		}
+		if amount.Valid {
+			half := roundTo(amount.Float64/2, 2)
+			expiry.AmountCrore = &half
+		}
+		expiry.DaysUntil = int(expiry.ExpiryDate.Truncate(24*time.Hour).Sub(today).Hours() / 24)
+		expiries = append(expiries, expiry)
+	}
+
+	return expiries, rows.Err()
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// CacheInvalidationChannel is the Postgres NOTIFY channel replicas listen on for cache
+// invalidations. Invalidations go over Postgres rather than Redis pub/sub: every replica
+// already shares the database while Redis is optional, and a NOTIFY sent inside the
+// writing transaction is delivered only if that write commits.
+const CacheInvalidationChannel = "cache_invalidation"
+
+// CacheInvalidation describes which cached data to drop. IPOID clears one IPO's entries
+// and the IPO list responses, Prefix clears keys starting with it, and All clears everything.
+type CacheInvalidation struct {
+	All    bool   `json:"all,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	IPOID  string `json:"ipo_id,omitempty"`
+	// Origin is the publishing replica, which has already applied the invalidation
+	Origin string `json:"origin,omitempty"`
+}
+
+// ResponseInvalidator is the part of the HTTP response cache the bus clears
+type ResponseInvalidator interface {
+	Invalidate()
+	InvalidatePrefix(prefix string) int
+}
+
+// NotifyCacheInvalidation queues an invalidation inside the caller's transaction; Postgres
+// delivers it to every replica (including this one) only if the transaction commits
+func NotifyCacheInvalidation(ctx context.Context, tx *sql.Tx, invalidation CacheInvalidation) error {
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache invalidation: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", CacheInvalidationChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to notify cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// CacheInvalidationBus applies cache invalidations locally and broadcasts them to the
+// other replicas over Postgres LISTEN/NOTIFY
+type CacheInvalidationBus struct {
+	DB        *sql.DB
+	DSN       string
+	IPOCache  *CachedIPOService
+	Responses ResponseInvalidator
+	// IPOResponsePrefixes are the response cache paths that may embed a single IPO
+	IPOResponsePrefixes []string
+
+	instanceID string
+	logger     *logrus.Entry
+}
+
+// NewCacheInvalidationBus creates a bus over the given caches; dsn is used for the
+// dedicated listener connection
+func NewCacheInvalidationBus(db *sql.DB, dsn string, ipoCache *CachedIPOService, responses ResponseInvalidator) *CacheInvalidationBus {
+	return &CacheInvalidationBus{
+		DB:                  db,
+		DSN:                 dsn,
+		IPOCache:            ipoCache,
+		Responses:           responses,
+		IPOResponsePrefixes: []string{"/api/v1/ipos", "/api/v1/feeds", "/api/v1/analytics"},
+		instanceID:          uuid.NewString(),
+		logger:              logrus.WithField("component", "cache_invalidation"),
+	}
+}
+
+// Apply drops the matching local cache entries and returns how many keyed entries were
+// dropped (full clears are not counted)
+func (b *CacheInvalidationBus) Apply(invalidation CacheInvalidation) int {
+	removed := 0
+	switch {
+	case invalidation.All:
+		if b.IPOCache != nil {
+			b.IPOCache.InvalidateAllIPOCache()
+		}
+		if b.Responses != nil {
+			b.Responses.Invalidate()
+		}
+	case invalidation.IPOID != "":
+		if b.IPOCache != nil {
+			b.IPOCache.InvalidateIPOCache(invalidation.IPOID)
+		}
+		if b.Responses != nil {
+			for _, prefix := range b.IPOResponsePrefixes {
+				removed += b.Responses.InvalidatePrefix(prefix)
+			}
+		}
+	case invalidation.Prefix != "":
+		if b.IPOCache != nil {
+			removed += b.IPOCache.InvalidatePrefix(invalidation.Prefix)
+		}
+		if b.Responses != nil {
+			removed += b.Responses.InvalidatePrefix(invalidation.Prefix)
+		}
+	}
+
+	b.logger.WithFields(logrus.Fields{
+		"all":     invalidation.All,
+		"prefix":  invalidation.Prefix,
+		"ipo_id":  invalidation.IPOID,
+		"origin":  invalidation.Origin,
+		"removed": removed,
+	}).Debug("Applied cache invalidation")
+	return removed
+}
+
+// Publish applies the invalidation on this replica and broadcasts it to the others
+func (b *CacheInvalidationBus) Publish(ctx context.Context, invalidation CacheInvalidation) (int, error) {
+	invalidation.Origin = b.instanceID
+	removed := b.Apply(invalidation)
+
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return removed, fmt.Errorf("failed to marshal cache invalidation: %w", err)
+	}
+	if _, err := b.DB.ExecContext(ctx, "SELECT pg_notify($1, $2)", CacheInvalidationChannel, string(payload)); err != nil {
+		return removed, fmt.Errorf("failed to broadcast cache invalidation: %w", err)
+	}
+	return removed, nil
+}
+
+// Start listens for invalidations from other replicas until ctx is cancelled
+func (b *CacheInvalidationBus) Start(ctx context.Context) error {
+	listener := pq.NewListener(b.DSN, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			b.logger.WithError(err).Warn("Cache invalidation listener connection problem")
+		}
+	})
+	if err := listener.Listen(CacheInvalidationChannel); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for cache invalidations: %w", err)
+	}
+
+	b.logger.WithField("instance_id", b.instanceID).Info("Listening for cache invalidations")
+	go func() {
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification := <-listener.Notify:
+				if notification == nil {
+					// The connection was re-established and notifications may have been
+					// missed, so nothing cached before now can be trusted
+					b.Apply(CacheInvalidation{All: true})
+					continue
+				}
+				b.handleNotification(notification.Extra)
+			case <-time.After(90 * time.Second):
+				go listener.Ping()
+			}
+		}
+	}()
+	return nil
+}
+
+// handleNotification applies an invalidation received from the channel
+func (b *CacheInvalidationBus) handleNotification(payload string) {
+	var invalidation CacheInvalidation
+	if err := json.Unmarshal([]byte(payload), &invalidation); err != nil {
+		b.logger.WithError(err).Warn("Ignoring malformed cache invalidation")
+		return
+	}
+	if invalidation.Origin == b.instanceID {
+		return
+	}
+	b.Apply(invalidation)
+}
@@ -3,22 +3,35 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // CacheEntry represents a cached item with expiration
 type CacheEntry struct {
 	Data      interface{}
 	ExpiresAt time.Time
+
+	hits atomic.Int64 // Reads since the entry was stored
+	size int64        // Estimated bytes, see estimateCacheEntryBytes
 }
 
 // IsExpired checks if the cache entry has expired
 func (ce *CacheEntry) IsExpired() bool {
-	return time.Now().After(ce.ExpiresAt)
+	return ce.isExpiredAt(time.Now())
+}
+
+func (ce *CacheEntry) isExpiredAt(now time.Time) bool {
+	return now.After(ce.ExpiresAt)
 }
 
 // CacheService provides unified caching solution with both in-memory and database persistence.
@@ -33,7 +46,15 @@ type CacheService struct {
 	mutex      sync.RWMutex
 	defaultTTL time.Duration
 	maxSize    int
-	DB         *sql.DB // Database for persistent caching
+	DB         *sql.DB      // Database for persistent caching
+	Clock      shared.Clock // Time in-memory entries expire against
+	// Shadow, when set, mirrors writes to a second backend and compares every read with
+	// it, to validate that backend before reads move to it
+	Shadow *CacheShadow
+	// ReferenceKey keys the PAN references GetRecentChecks returns in place of PAN hashes
+	ReferenceKey []byte
+
+	counters shared.CacheCounters
 }
 
 // NewCacheService creates a new consolidated cache service with default TTL.
@@ -44,6 +65,7 @@ func NewCacheService(db *sql.DB) *CacheService {
 		defaultTTL: 5 * time.Minute, // Default 5 minute TTL
 		maxSize:    1000,            // Default max size
 		DB:         db,
+		Clock:      shared.DefaultClock,
 	}
 
 	// Start cleanup goroutine
@@ -59,6 +81,7 @@ func NewCacheServiceWithConfig(db *sql.DB, defaultTTL time.Duration, maxSize int
 		defaultTTL: defaultTTL,
 		maxSize:    maxSize,
 		DB:         db,
+		Clock:      shared.DefaultClock,
 	}
 
 	// Start cleanup goroutine
@@ -73,10 +96,20 @@ func (cs *CacheService) Get(key string) (interface{}, bool) {
 	defer cs.mutex.RUnlock()
 
 	entry, exists := cs.cache[key]
-	if !exists || entry.IsExpired() {
+	if !exists {
+		cs.counters.Miss(CacheKeyFamily(key))
+		cs.Shadow.CompareMiss(key)
+		return nil, false
+	}
+	// Not compared: the shadow's copy expires a moment later and would count as extra
+	if entry.isExpiredAt(cs.now()) {
+		cs.counters.Expired(CacheKeyFamily(key))
 		return nil, false
 	}
 
+	cs.counters.Hit(CacheKeyFamily(key))
+	entry.hits.Add(1)
+	cs.Shadow.CompareHit(key, entry.Data)
 	return entry.Data, true
 }
 
@@ -97,8 +130,55 @@ func (cs *CacheService) SetWithTTL(key string, value interface{}, ttl time.Durat
 
 	cs.cache[key] = &CacheEntry{
 		Data:      value,
-		ExpiresAt: time.Now().Add(ttl),
+		ExpiresAt: cs.now().Add(ttl),
+		size:      estimateCacheEntryBytes(key, value),
+	}
+	cs.Shadow.Set(key, value, ttl)
+}
+
+// CacheKeyFamily is the family a data cache key is reported under: the part before its
+// first ":" ("ipo_detail:<id>" is "ipo_detail"), or the whole key
+func CacheKeyFamily(key string) string {
+	family, _, _ := strings.Cut(key, ":")
+	return family
+}
+
+// estimateCacheEntryBytes sizes an entry by its key and the JSON encoding of its value,
+// plus shared.CacheEntryOverheadBytes. Values are sized once, when they are stored.
+func estimateCacheEntryBytes(key string, value interface{}) int64 {
+	size := int64(len(key) + shared.CacheEntryOverheadBytes)
+	if encoded, err := json.Marshal(value); err == nil {
+		size += int64(len(encoded))
+	}
+	return size
+}
+
+// Stats reports the cache's entries, estimated memory and lookups per key family, with
+// its top most read keys
+func (cs *CacheService) Stats(top int) shared.CacheLayerStats {
+	cs.mutex.RLock()
+	entries := make([]shared.CacheEntryInfo, 0, len(cs.cache))
+	for key, entry := range cs.cache {
+		entries = append(entries, shared.CacheEntryInfo{
+			Key:       key,
+			Family:    CacheKeyFamily(key),
+			Bytes:     entry.size,
+			Hits:      entry.hits.Load(),
+			ExpiresAt: entry.ExpiresAt,
+		})
 	}
+	cs.mutex.RUnlock()
+
+	stats := cs.counters.Snapshot("data", cs.maxSize, entries, top, cs.now())
+	if cs.Shadow != nil {
+		shadow := cs.Shadow.Stats()
+		stats.Shadow = &shadow
+	}
+	return stats
+}
+
+func (cs *CacheService) now() time.Time {
+	return shared.ClockOrDefault(cs.Clock).Now()
 }
 
 // evictOldest removes the oldest entry from cache (simple FIFO eviction)
@@ -115,6 +195,8 @@ func (cs *CacheService) evictOldest() {
 
 	if oldestKey != "" {
 		delete(cs.cache, oldestKey)
+		cs.counters.Evict(CacheKeyFamily(oldestKey))
+		cs.Shadow.Delete(oldestKey)
 	}
 }
 
@@ -124,6 +206,23 @@ func (cs *CacheService) Delete(key string) {
 	defer cs.mutex.Unlock()
 
 	delete(cs.cache, key)
+	cs.Shadow.Delete(key)
+}
+
+// DeletePrefix removes every key starting with prefix and returns how many were removed
+func (cs *CacheService) DeletePrefix(prefix string) int {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	count := 0
+	for key := range cs.cache {
+		if strings.HasPrefix(key, prefix) {
+			delete(cs.cache, key)
+			count++
+		}
+	}
+	cs.Shadow.DeletePrefix(prefix)
+	return count
 }
 
 // Clear removes all values from cache
@@ -132,6 +231,7 @@ func (cs *CacheService) Clear() {
 	defer cs.mutex.Unlock()
 
 	cs.cache = make(map[string]*CacheEntry)
+	cs.Shadow.Clear()
 }
 
 // Size returns the number of items in cache
@@ -149,8 +249,9 @@ func (cs *CacheService) cleanupExpired() {
 
 	for range ticker.C {
 		cs.mutex.Lock()
+		now := cs.now()
 		for key, entry := range cs.cache {
-			if entry.IsExpired() {
+			if entry.isExpiredAt(now) {
 				delete(cs.cache, key)
 			}
 		}
@@ -162,6 +263,9 @@ func (cs *CacheService) cleanupExpired() {
 type CachedIPOService struct {
 	ipoService *IPOService
 	cache      *CacheService
+
+	warmupMutex sync.Mutex
+	warmup      shared.CacheWarmupStatus
 }
 
 // NewCachedIPOService creates a new cached IPO service
@@ -169,6 +273,7 @@ func NewCachedIPOService(ipoService *IPOService, cache *CacheService) *CachedIPO
 	return &CachedIPOService{
 		ipoService: ipoService,
 		cache:      cache,
+		warmup:     shared.CacheWarmupStatus{Status: shared.CacheWarmupNever},
 	}
 }
 
@@ -205,6 +310,9 @@ func (cis *CachedIPOService) GetIPOByIDWithGMP(ctx context.Context, id string) (
 			return ipo, nil
 		}
 	}
+	if cis.knownMissing(notFoundIDKey(id)) {
+		return nil, nil
+	}
 
 	// Cache miss - fetch from database
 	ipo, err := cis.ipoService.GetIPOByIDWithGMP(ctx, id)
@@ -215,6 +323,8 @@ func (cis *CachedIPOService) GetIPOByIDWithGMP(ctx context.Context, id string) (
 	if ipo != nil {
 		// Cache the result for 10 minutes (individual IPOs are accessed frequently)
 		cis.cache.SetWithTTL(cacheKey, ipo, 10*time.Minute)
+	} else {
+		cis.rememberMissing(notFoundIDKey(id))
 	}
 
 	return ipo, nil
@@ -276,6 +386,9 @@ func (cis *CachedIPOService) GetIPOByID(ctx context.Context, id string) (*models
 			return ipo, nil
 		}
 	}
+	if cis.knownMissing(notFoundIDKey(id)) {
+		return nil, nil
+	}
 
 	// Cache miss - fetch from database
 	ipo, err := cis.ipoService.GetIPOByID(ctx, id)
@@ -286,16 +399,78 @@ func (cis *CachedIPOService) GetIPOByID(ctx context.Context, id string) (*models
 	if ipo != nil {
 		// Cache the result for 15 minutes (individual IPO details are relatively static)
 		cis.cache.SetWithTTL(cacheKey, ipo, 15*time.Minute)
+	} else {
+		cis.rememberMissing(notFoundIDKey(id))
 	}
 
 	return ipo, nil
 }
 
+// GetIPODetail returns an IPO by ID or slug with its detail fields, reading through the
+// cache. Slugs are cached as pointers to the ID's entry, so invalidating an IPO by ID
+// also drops it for its slug; a slug entry whose IPO has since been renamed is ignored.
+// Lookups that find no IPO are cached for NotFoundCacheTTL.
+func (cis *CachedIPOService) GetIPODetail(ctx context.Context, idOrSlug string) (*models.IPO, error) {
+	id := idOrSlug
+	slug := ""
+	missingKey := notFoundIDKey(idOrSlug)
+	if _, err := uuid.Parse(idOrSlug); err != nil {
+		slug = idOrSlug
+		id = ""
+		missingKey = notFoundSlugPrefix + slug
+		if cached, found := cis.cache.Get(fmt.Sprintf("ipo_slug:%s", slug)); found {
+			id, _ = cached.(string)
+		}
+	}
+
+	if id != "" {
+		if cached, found := cis.cache.Get(fmt.Sprintf("ipo_detail:%s", id)); found {
+			if ipo, ok := cached.(*models.IPO); ok && (slug == "" || (ipo.Slug != nil && *ipo.Slug == slug)) {
+				return cis.detailCopy(ipo), nil
+			}
+		}
+	}
+
+	if cis.knownMissing(missingKey) {
+		return nil, nil
+	}
+
+	// Cache miss - fetch from database
+	ipo, err := cis.ipoService.GetIPODetail(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	if ipo == nil {
+		cis.rememberMissing(missingKey)
+		return nil, nil
+	}
+
+	// Cache the result for 15 minutes; upserts invalidate it sooner
+	cis.cache.SetWithTTL(fmt.Sprintf("ipo_detail:%s", ipo.ID), ipo, 15*time.Minute)
+	if ipo.Slug != nil && *ipo.Slug != "" {
+		cis.cache.SetWithTTL(fmt.Sprintf("ipo_slug:%s", *ipo.Slug), ipo.ID.String(), 15*time.Minute)
+	}
+	return cis.detailCopy(ipo), nil
+}
+
+// detailCopy returns a copy of a cached IPO with its status recalculated for now, so
+// callers never share or mutate the cached value
+func (cis *CachedIPOService) detailCopy(ipo *models.IPO) *models.IPO {
+	copied := *ipo
+	cis.ipoService.recalculateStatus(&copied)
+	return &copied
+}
+
 // InvalidateIPOCache removes IPO-related cache entries
 func (cis *CachedIPOService) InvalidateIPOCache(ipoID string) {
 	// Remove specific IPO caches
 	cis.cache.Delete(fmt.Sprintf("ipo:%s", ipoID))
 	cis.cache.Delete(fmt.Sprintf("ipo_with_gmp:%s", ipoID))
+	cis.cache.Delete(fmt.Sprintf("ipo_detail:%s", ipoID))
+
+	// Drop misses that could hide the IPO, which may have just been created
+	cis.cache.Delete(notFoundIDKey(ipoID))
+	cis.cache.DeletePrefix(notFoundSlugPrefix)
 
 	// Remove list caches (they may contain the updated IPO)
 	cis.cache.Delete("active_ipos")
@@ -306,82 +481,232 @@ func (cis *CachedIPOService) InvalidateIPOCache(ipoID string) {
 	cis.cache.Delete("ipos:closed")
 }
 
+// InvalidatePrefix removes cache entries whose key starts with prefix, e.g. "ipo:" or "active_ipos"
+func (cis *CachedIPOService) InvalidatePrefix(prefix string) int {
+	return cis.cache.DeletePrefix(prefix)
+}
+
 // InvalidateAllIPOCache removes all IPO-related cache entries
 func (cis *CachedIPOService) InvalidateAllIPOCache() {
 	// This is a simple approach - in production, you might want to use cache tags
 	cis.cache.Clear()
 }
 
-// GetCacheStats returns cache statistics
-func (cis *CachedIPOService) GetCacheStats() map[string]interface{} {
-	return map[string]interface{}{
-		"size": cis.cache.Size(),
-		"type": "in-memory",
-	}
+// GetCacheStats returns the data cache's per-family lookups, estimated memory, hottest
+// keys and last warmup
+func (cis *CachedIPOService) GetCacheStats() shared.CacheLayerStats {
+	stats := cis.cache.Stats(shared.DefaultHottestCacheKeys)
+	cis.warmupMutex.Lock()
+	warmup := cis.warmup
+	cis.warmupMutex.Unlock()
+	stats.Warmup = &warmup
+	return stats
 }
 
 // WarmupCache pre-loads frequently accessed data into cache
 func (cis *CachedIPOService) WarmupCache(ctx context.Context) error {
+	started := time.Now()
+	cis.recordWarmup(shared.CacheWarmupStatus{Status: shared.CacheWarmupRunning, LastRunAt: &started})
+
+	keys, err := cis.warmupKeys(ctx)
+	status := shared.CacheWarmupStatus{
+		Status:     shared.CacheWarmupOK,
+		LastRunAt:  &started,
+		DurationMs: time.Since(started).Milliseconds(),
+		Keys:       keys,
+	}
+	if err != nil {
+		status.Status = shared.CacheWarmupFailed
+		status.Error = err.Error()
+	}
+	cis.recordWarmup(status)
+	return err
+}
+
+// warmupKeys loads the warmed entries and returns how many were loaded
+func (cis *CachedIPOService) warmupKeys(ctx context.Context) (int, error) {
 	// Pre-load active IPOs
 	_, err := cis.GetActiveIPOs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to warmup active IPOs cache: %w", err)
+		return 0, fmt.Errorf("failed to warmup active IPOs cache: %w", err)
 	}
 
 	// Pre-load active IPOs with GMP
 	_, err = cis.GetActiveIPOsWithGMP(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to warmup active IPOs with GMP cache: %w", err)
+		return 1, fmt.Errorf("failed to warmup active IPOs with GMP cache: %w", err)
 	}
 
-	return nil
+	return 2, nil
+}
+
+func (cis *CachedIPOService) recordWarmup(status shared.CacheWarmupStatus) {
+	cis.warmupMutex.Lock()
+	defer cis.warmupMutex.Unlock()
+	cis.warmup = status
 }
 
 // Database cache methods for IPO results
 
-// StoreResult stores an IPO result in the database cache
+// StoreResult stores an IPO result in the database cache. A result agreeing with the
+// cached one (same status and shares) from a source not seen yet is recorded in sources,
+// bumping duplicate_count and confidence_score, instead of replacing it; a different
+// result replaces the row and resets both. The row is bound to the result's owner hash, if
+// any, when it is first stored; later stores never change who it is bound to.
 func (cs *CacheService) StoreResult(ctx context.Context, result *models.IPOResultCache) error {
+	_, err := cs.storeResult(ctx, result, false)
+	return err
+}
+
+// StoreSubmittedResult stores a result a client submitted through /cache/store like
+// StoreResult, except that a row bound to another owner is left unchanged. It reports
+// whether the result was stored.
+func (cs *CacheService) StoreSubmittedResult(ctx context.Context, result *models.IPOResultCache) (bool, error) {
+	return cs.storeResult(ctx, result, true)
+}
+
+// storeResult upserts result; with ownersOnly, an existing row bound to another owner is
+// not updated
+func (cs *CacheService) storeResult(ctx context.Context, result *models.IPOResultCache, ownersOnly bool) (bool, error) {
 	query := `
 		INSERT INTO ipo_result_cache (
 			pan_hash, ipo_id, status, shares_allotted, application_number,
 			refund_status, source, user_agent, timestamp, expires_at,
-			confidence_score, duplicate_count
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			confidence_score, duplicate_count, source_channel, client_fingerprint, sources,
+			owner_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, ARRAY[$15::text], $17)
 		ON CONFLICT (pan_hash, ipo_id) DO UPDATE SET
 			status = EXCLUDED.status,
 			shares_allotted = EXCLUDED.shares_allotted,
 			application_number = EXCLUDED.application_number,
 			refund_status = EXCLUDED.refund_status,
 			timestamp = EXCLUDED.timestamp,
-			duplicate_count = ipo_result_cache.duplicate_count + 1
+			user_agent = EXCLUDED.user_agent,
+			source_channel = EXCLUDED.source_channel,
+			client_fingerprint = EXCLUDED.client_fingerprint,
+			duplicate_count = CASE
+				WHEN ipo_result_cache.status <> EXCLUDED.status
+				  OR ipo_result_cache.shares_allotted IS DISTINCT FROM EXCLUDED.shares_allotted THEN 0
+				WHEN $15 = ANY(ipo_result_cache.sources) THEN ipo_result_cache.duplicate_count
+				ELSE ipo_result_cache.duplicate_count + 1
+			END,
+			sources = CASE
+				WHEN ipo_result_cache.status <> EXCLUDED.status
+				  OR ipo_result_cache.shares_allotted IS DISTINCT FROM EXCLUDED.shares_allotted THEN EXCLUDED.sources
+				WHEN $15 = ANY(ipo_result_cache.sources) THEN ipo_result_cache.sources
+				ELSE array_append(ipo_result_cache.sources, $15)
+			END,
+			confidence_score = CASE
+				WHEN ipo_result_cache.status <> EXCLUDED.status
+				  OR ipo_result_cache.shares_allotted IS DISTINCT FROM EXCLUDED.shares_allotted THEN EXCLUDED.confidence_score
+				WHEN $15 = ANY(ipo_result_cache.sources) THEN GREATEST(ipo_result_cache.confidence_score, EXCLUDED.confidence_score)
+				ELSE LEAST(100, GREATEST(ipo_result_cache.confidence_score, EXCLUDED.confidence_score) + $16)
+			END
+		WHERE NOT $18 OR ipo_result_cache.owner_hash IS NULL OR ipo_result_cache.owner_hash = $17
 	`
 
-	_, err := cs.DB.ExecContext(ctx, query,
+	sourceChannel := result.SourceChannel
+	if sourceChannel == "" {
+		sourceChannel = models.SourceChannelAPI
+	}
+	confidence := result.ConfidenceScore
+	if confidence == 0 {
+		confidence = BaseResultConfidence
+	}
+
+	stored, err := cs.DB.ExecContext(ctx, query,
 		result.PanHash, result.IPOID, result.Status, result.SharesAllotted,
 		result.ApplicationNumber, result.RefundStatus, result.Source,
 		result.UserAgent, result.Timestamp, result.ExpiresAt,
-		result.ConfidenceScore, result.DuplicateCount,
+		confidence, result.DuplicateCount,
+		sourceChannel, sql.NullString{String: result.ClientFingerprint, Valid: result.ClientFingerprint != ""},
+		resultSourceKey(result), AgreeingSourceConfidence,
+		sql.NullString{String: result.OwnerHash, Valid: result.OwnerHash != ""}, ownersOnly,
 	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := stored.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
 
-	return err
+// MaxSubmittedResultAge is how old a result submitted to /cache/store may be
+const MaxSubmittedResultAge = 7 * 24 * time.Hour
+
+// ValidateSubmittedResult checks a result submitted by a client before it is stored:
+// the IPO must exist, the PAN hash must be a HashPAN result and only definitive results
+// are accepted. Fields the server owns are reset, and the result expires like a live
+// check's.
+func (cs *CacheService) ValidateSubmittedResult(ctx context.Context, result *models.IPOResultCache) error {
+	if result.IPOID == uuid.Nil {
+		return shared.ValidationErrorf("ipo_id is required")
+	}
+	result.PanHash = strings.TrimSpace(result.PanHash)
+	if !shared.IsValidPANHash(result.PanHash) {
+		return shared.ValidationErrorf("pan_hash must be the lowercase hex SHA-256 of the PAN")
+	}
+	switch result.Status {
+	case "ALLOTTED":
+		if result.SharesAllotted <= 0 {
+			return shared.ValidationErrorf("shares_allotted must be positive for ALLOTTED results")
+		}
+	case "NOT_ALLOTTED":
+		if result.SharesAllotted != 0 {
+			return shared.ValidationErrorf("shares_allotted must be 0 for NOT_ALLOTTED results")
+		}
+	default:
+		return shared.ValidationErrorf("status must be ALLOTTED or NOT_ALLOTTED")
+	}
+	if result.ConfidenceScore < 0 || result.ConfidenceScore > 100 {
+		return shared.ValidationErrorf("confidence_score must be between 0 and 100")
+	}
+	if len(result.ApplicationNumber) > 100 || len(result.RefundStatus) > 100 || len(result.Source) > 100 {
+		return shared.ValidationErrorf("application_number, refund_status and source must be at most 100 characters")
+	}
+
+	now := time.Now()
+	if result.Timestamp.IsZero() {
+		result.Timestamp = now
+	}
+	if result.Timestamp.After(now.Add(time.Minute)) || now.Sub(result.Timestamp) > MaxSubmittedResultAge {
+		return shared.ValidationErrorf("timestamp must be within the last 7 days")
+	}
+	result.ExpiresAt = result.Timestamp.Add(MaxSubmittedResultAge)
+	result.DuplicateCount = 0
+
+	var exists bool
+	if err := cs.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ipo_list WHERE id = $1)`, result.IPOID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up IPO: %w", err)
+	}
+	if !exists {
+		return shared.NotFoundErrorf("IPO %s not found", result.IPOID)
+	}
+	return nil
 }
 
-// GetCachedResult retrieves a cached IPO result from database
+// GetCachedResult retrieves a cached IPO result from database, whoever it is bound to
 func (cs *CacheService) GetCachedResult(ctx context.Context, ipoID, panHash string) (*models.IPOResultCache, error) {
 	query := `
 		SELECT id, pan_hash, ipo_id, status, shares_allotted, application_number,
 		       refund_status, source, user_agent, timestamp, expires_at,
-		       confidence_score, duplicate_count
+		       confidence_score, duplicate_count,
+		       COALESCE(source_channel, 'api'), COALESCE(client_fingerprint, ''), sources,
+		       COALESCE(owner_hash, '')
 		FROM ipo_result_cache
-		WHERE ipo_id = $1 AND pan_hash = $2 AND expires_at > NOW()
+		WHERE ipo_id = $1 AND pan_hash = $2 AND expires_at > $3
 	`
 
 	var result models.IPOResultCache
-	err := cs.DB.QueryRowContext(ctx, query, ipoID, panHash).Scan(
+	err := cs.DB.QueryRowContext(ctx, query, ipoID, panHash, cs.now()).Scan(
 		&result.ID, &result.PanHash, &result.IPOID, &result.Status,
 		&result.SharesAllotted, &result.ApplicationNumber, &result.RefundStatus,
 		&result.Source, &result.UserAgent, &result.Timestamp, &result.ExpiresAt,
 		&result.ConfidenceScore, &result.DuplicateCount,
+		&result.SourceChannel, &result.ClientFingerprint, pq.Array(&result.Sources),
+		&result.OwnerHash,
 	)
 
 	if err != nil {
@@ -394,11 +719,98 @@ func (cs *CacheService) GetCachedResult(ctx context.Context, ipoID, panHash stri
 	return &result, nil
 }
 
+// GetCachedResultFor retrieves a cached result for a client fetching it by PAN hash.
+// A result bound to another owner is reported as missing, like one that does not exist,
+// so a client can't learn which PAN hashes have bound results.
+func (cs *CacheService) GetCachedResultFor(ctx context.Context, ipoID, panHash, owner string) (*models.IPOResultCache, error) {
+	result, err := cs.GetCachedResult(ctx, ipoID, panHash)
+	if err != nil || result == nil || !result.ReadableBy(owner) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CheckAuditFilter narrows the recent checks returned to admins
+type CheckAuditFilter struct {
+	IPOID             string
+	SourceChannel     string
+	ClientFingerprint string
+	Status            string
+	Since             *time.Time
+	Limit             int
+}
+
+// GetRecentChecks returns recent result cache writes for abuse investigation. PAN
+// hashes are replaced with a keyed reference (shared.PANReference) and never returned.
+func (cs *CacheService) GetRecentChecks(ctx context.Context, filter CheckAuditFilter) ([]models.CheckAuditEntry, error) {
+	query := `
+		SELECT r.id, r.ipo_id, COALESCE(i.name, ''), r.pan_hash, r.status,
+		       COALESCE(r.source, ''), COALESCE(r.source_channel, 'api'),
+		       COALESCE(r.client_fingerprint, ''), COALESCE(r.user_agent, ''),
+		       r.duplicate_count, r.confidence_score, r.sources, r.timestamp
+		FROM ipo_result_cache r
+		LEFT JOIN ipo_list i ON i.id = r.ipo_id
+		WHERE 1=1
+	`
+
+	var args []interface{}
+	addFilter := func(clause string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND "+clause, len(args))
+	}
+
+	if filter.IPOID != "" {
+		addFilter("r.ipo_id = $%d", filter.IPOID)
+	}
+	if filter.SourceChannel != "" {
+		addFilter("r.source_channel = $%d", filter.SourceChannel)
+	}
+	if filter.ClientFingerprint != "" {
+		addFilter("r.client_fingerprint = $%d", filter.ClientFingerprint)
+	}
+	if filter.Status != "" {
+		addFilter("r.status = $%d", filter.Status)
+	}
+	if filter.Since != nil {
+		addFilter("r.timestamp >= $%d", *filter.Since)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY r.timestamp DESC LIMIT $%d", len(args))
+
+	rows, err := cs.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent checks: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.CheckAuditEntry{}
+	for rows.Next() {
+		var entry models.CheckAuditEntry
+		var panHash string
+		if err := rows.Scan(
+			&entry.ID, &entry.IPOID, &entry.IPOName, &panHash, &entry.Status,
+			&entry.Source, &entry.SourceChannel, &entry.ClientFingerprint, &entry.UserAgent,
+			&entry.DuplicateCount, &entry.ConfidenceScore, pq.Array(&entry.Sources), &entry.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan recent check: %w", err)
+		}
+		entry.PanRef = shared.PANReference(cs.ReferenceKey, panHash)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
 // CleanupExpiredDB removes expired cache entries from database
 func (cs *CacheService) CleanupExpiredDB(ctx context.Context) error {
-	query := `DELETE FROM ipo_result_cache WHERE expires_at < NOW()`
+	query := `DELETE FROM ipo_result_cache WHERE expires_at < $1`
 
-	result, err := cs.DB.ExecContext(ctx, query)
+	result, err := cs.DB.ExecContext(ctx, query, cs.now())
 	if err != nil {
 		return err
 	}
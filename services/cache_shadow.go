@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// Cache shadow modes
+const (
+	CacheShadowOff   = "off"    // in-memory cache only
+	CacheShadowWrite = "shadow" // also write Redis and compare its reads, still serving memory
+)
+
+const (
+	cacheShadowQueue  = 1000 // Operations waiting for the shadow backend
+	cacheShadowRecent = 20   // Divergences kept for the stats
+)
+
+// Kinds of shadow read divergence
+const (
+	CacheDivergenceMismatch = "mismatch" // both had the key with different values
+	CacheDivergenceMissing  = "missing"  // the shadow lacked a key the cache had
+	CacheDivergenceExtra    = "extra"    // the shadow had a key the cache lacked
+)
+
+// CacheShadowBackend is the cache backend a CacheShadow writes to and reads back from
+type CacheShadowBackend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) (int, error)
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// CacheShadow mirrors a cache layer's writes to a second backend, such as Redis before
+// reads are cut over to it, and compares every read with it. Values are stored as their
+// JSON encoding, so a read matches when both encodings are equal. Values are encoded
+// before the call returns, since callers may change them once they are cached. Operations run in
+// order on one background worker and never slow down the cache; when the queue is full
+// they are dropped and counted. A nil shadow does nothing. Safe for concurrent use.
+type CacheShadow struct {
+	Backend CacheShadowBackend
+	// Name identifies the backend in the stats, e.g. "redis"
+	Name string
+	// Prefix namespaces the layer's keys in the backend
+	Prefix string
+	// Timeout bounds each backend operation
+	Timeout time.Duration
+
+	ops    chan func(ctx context.Context)
+	mutex  sync.Mutex
+	stats  shared.CacheShadowStats
+	logger *logrus.Entry
+}
+
+// NewCacheShadow creates a shadow writing to backend under prefix and starts its worker
+func NewCacheShadow(name string, backend CacheShadowBackend, prefix string) *CacheShadow {
+	shadow := &CacheShadow{
+		Backend: backend,
+		Name:    name,
+		Prefix:  prefix,
+		Timeout: 2 * time.Second,
+		ops:     make(chan func(ctx context.Context), cacheShadowQueue),
+		logger:  logrus.WithFields(logrus.Fields{"component": "cache_shadow", "backend": name}),
+	}
+	go shadow.run()
+	return shadow
+}
+
+func (s *CacheShadow) run() {
+	for op := range s.ops {
+		ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+		op(ctx)
+		cancel()
+	}
+}
+
+// enqueue queues op for the worker, dropping it when the queue is full
+func (s *CacheShadow) enqueue(op func(ctx context.Context)) {
+	select {
+	case s.ops <- op:
+	default:
+		s.count(func(stats *shared.CacheShadowStats) { stats.Dropped++ })
+	}
+}
+
+// Flush waits until every operation queued so far has run
+func (s *CacheShadow) Flush() {
+	if s == nil {
+		return
+	}
+	done := make(chan struct{})
+	s.ops <- func(context.Context) { close(done) }
+	<-done
+}
+
+// Set mirrors a write of value under key
+func (s *CacheShadow) Set(key string, value interface{}, ttl time.Duration) {
+	if s == nil {
+		return
+	}
+	encoded, encodeErr := json.Marshal(value)
+	s.enqueue(func(ctx context.Context) {
+		err := encodeErr
+		if err == nil {
+			err = s.Backend.Set(ctx, s.Prefix+key, encoded, ttl)
+		}
+		s.count(func(stats *shared.CacheShadowStats) {
+			stats.Writes++
+			if err != nil {
+				stats.WriteErrors++
+			}
+		})
+		if err != nil {
+			s.logger.WithError(err).WithField("key", key).Debug("Cache shadow write failed")
+		}
+	})
+}
+
+// Delete mirrors the removal of key
+func (s *CacheShadow) Delete(key string) {
+	if s == nil {
+		return
+	}
+	s.enqueue(func(ctx context.Context) {
+		if _, err := s.Backend.Del(ctx, s.Prefix+key); err != nil {
+			s.writeFailed(err, key)
+		}
+	})
+}
+
+// DeletePrefix mirrors the removal of every key starting with prefix
+func (s *CacheShadow) DeletePrefix(prefix string) {
+	if s == nil {
+		return
+	}
+	s.enqueue(func(ctx context.Context) {
+		if _, err := s.Backend.DeletePrefix(ctx, s.Prefix+prefix); err != nil {
+			s.writeFailed(err, prefix+"*")
+		}
+	})
+}
+
+// Clear mirrors the removal of every key
+func (s *CacheShadow) Clear() {
+	s.DeletePrefix("")
+}
+
+// CompareHit reads key back from the shadow and compares it with value, the cache's own
+func (s *CacheShadow) CompareHit(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	expected, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	s.enqueue(func(ctx context.Context) {
+		shadowed, found, err := s.Backend.Get(ctx, s.Prefix+key)
+		switch {
+		case err != nil:
+			s.readFailed(err, key)
+		case !found:
+			s.diverged(key, CacheDivergenceMissing)
+		case !bytes.Equal(expected, shadowed):
+			s.diverged(key, CacheDivergenceMismatch)
+		default:
+			s.count(func(stats *shared.CacheShadowStats) {
+				stats.Compares++
+				stats.Matches++
+			})
+		}
+	})
+}
+
+// CompareMiss checks that the shadow lacks key too, after the cache missed it
+func (s *CacheShadow) CompareMiss(key string) {
+	if s == nil {
+		return
+	}
+	s.enqueue(func(ctx context.Context) {
+		_, found, err := s.Backend.Get(ctx, s.Prefix+key)
+		switch {
+		case err != nil:
+			s.readFailed(err, key)
+		case found:
+			s.diverged(key, CacheDivergenceExtra)
+		default:
+			s.count(func(stats *shared.CacheShadowStats) {
+				stats.Compares++
+				stats.Matches++
+			})
+		}
+	})
+}
+
+// Stats returns the shadow's counters and latest divergences
+func (s *CacheShadow) Stats() shared.CacheShadowStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.Backend = s.Name
+	if stats.Compares > 0 {
+		stats.MatchRate = float64(stats.Matches) / float64(stats.Compares)
+	}
+	stats.RecentDivergences = append([]shared.CacheDivergence{}, s.stats.RecentDivergences...)
+	return stats
+}
+
+func (s *CacheShadow) count(change func(stats *shared.CacheShadowStats)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	change(&s.stats)
+}
+
+// diverged counts and logs a read whose shadow value differed, keeping the latest few
+func (s *CacheShadow) diverged(key, kind string) {
+	s.count(func(stats *shared.CacheShadowStats) {
+		stats.Compares++
+		switch kind {
+		case CacheDivergenceMismatch:
+			stats.Mismatches++
+		case CacheDivergenceMissing:
+			stats.Missing++
+		case CacheDivergenceExtra:
+			stats.Extra++
+		}
+		stats.RecentDivergences = append(stats.RecentDivergences, shared.CacheDivergence{Key: key, Kind: kind, At: time.Now()})
+		if len(stats.RecentDivergences) > cacheShadowRecent {
+			stats.RecentDivergences = stats.RecentDivergences[1:]
+		}
+	})
+	s.logger.WithFields(logrus.Fields{"key": key, "kind": kind}).Warn("Cache shadow read diverged")
+}
+
+func (s *CacheShadow) writeFailed(err error, key string) {
+	s.count(func(stats *shared.CacheShadowStats) { stats.WriteErrors++ })
+	s.logger.WithError(err).WithField("key", key).Debug("Cache shadow delete failed")
+}
+
+func (s *CacheShadow) readFailed(err error, key string) {
+	s.count(func(stats *shared.CacheShadowStats) { stats.ReadErrors++ })
+	s.logger.WithError(err).WithField("key", key).Debug("Cache shadow read failed")
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// MaxCheckFeedbackComment bounds the free-text comment stored with a feedback report
+const MaxCheckFeedbackComment = 500
+
+// CheckFeedbackService stores user reports of wrong or stale allotment results so parser
+// regressions at a registrar show up as a spike of reports for its IPOs
+type CheckFeedbackService struct {
+	DB *sql.DB
+}
+
+// NewCheckFeedbackService creates a check feedback service
+func NewCheckFeedbackService(db *sql.DB) *CheckFeedbackService {
+	return &CheckFeedbackService{DB: db}
+}
+
+// Record stores feedback on a completed check. A check has at most one report; sending
+// feedback again replaces it.
+func (s *CheckFeedbackService) Record(ctx context.Context, check *models.AllotmentCheck, reason, comment string) (*models.CheckFeedback, error) {
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if reason != models.CheckFeedbackWrong && reason != models.CheckFeedbackStale {
+		return nil, shared.ValidationErrorf("reason must be one of %s, %s", models.CheckFeedbackWrong, models.CheckFeedbackStale)
+	}
+	if check.Status != models.CheckStatusComplete || check.Result == nil {
+		return nil, shared.ValidationErrorf("feedback can only be given on a completed check")
+	}
+
+	feedback := &models.CheckFeedback{
+		ID:             uuid.New(),
+		CheckID:        check.CheckID,
+		IPOID:          check.IPOID,
+		Registrar:      check.Registrar,
+		Reason:         reason,
+		ReportedStatus: check.Result.Status,
+	}
+	if comment = strings.TrimSpace(comment); comment != "" {
+		if len(comment) > MaxCheckFeedbackComment {
+			return nil, shared.ValidationErrorf("comment must be at most %d characters", MaxCheckFeedbackComment)
+		}
+		feedback.Comment = &comment
+	}
+
+	err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO check_feedback (id, check_id, ipo_id, registrar, reason, reported_status, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (check_id) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			comment = EXCLUDED.comment,
+			created_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at
+	`, feedback.ID, feedback.CheckID, feedback.IPOID, feedback.Registrar, feedback.Reason,
+		feedback.ReportedStatus, feedback.Comment).Scan(&feedback.ID, &feedback.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save check feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// Summary counts feedback reported since the given time per registrar and IPO, most
+// reported first
+func (s *CheckFeedbackService) Summary(ctx context.Context, since time.Time) ([]models.CheckFeedbackSummary, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT f.registrar, f.ipo_id, i.name,
+		       COUNT(*) FILTER (WHERE f.reason = $2),
+		       COUNT(*) FILTER (WHERE f.reason = $3),
+		       COUNT(*), MAX(f.created_at)
+		FROM check_feedback f
+		JOIN ipo_list i ON i.id = f.ipo_id
+		WHERE f.created_at >= $1
+		GROUP BY f.registrar, f.ipo_id, i.name
+		ORDER BY COUNT(*) DESC, MAX(f.created_at) DESC
+	`, since, models.CheckFeedbackWrong, models.CheckFeedbackStale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check feedback: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.CheckFeedbackSummary{}
+	for rows.Next() {
+		var summary models.CheckFeedbackSummary
+		if err := rows.Scan(&summary.Registrar, &summary.IPOID, &summary.IPOName,
+			&summary.Wrong, &summary.Stale, &summary.Total, &summary.LastReportedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan check feedback: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
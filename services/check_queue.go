@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCheckQueueFull is returned when the check queue cannot accept more work
+var ErrCheckQueueFull = errors.New("allotment check queue is full")
+
+// Stages of an allotment check, timed in the /check debug meta and the check stage metrics
+const (
+	CheckStageValidation       = "validation"
+	CheckStageIPOLookup        = "ipo_lookup"
+	CheckStageCacheLookup      = "cache_lookup"
+	CheckStageQuota            = "quota"
+	CheckStageQueueWait        = "queue_wait"
+	CheckStageRateLimitWait    = "rate_limit_wait"
+	CheckStageRegistrarRequest = "registrar_request"
+	CheckStageParsing          = "parsing"
+	CheckStageCacheStore       = "cache_store"
+	// CheckStageTotal is the whole /check request, up to its response
+	CheckStageTotal = "total"
+)
+
+// DefaultCheckStageMetrics collects the stage timings of every allotment check
+var DefaultCheckStageMetrics = shared.NewStageLatencyMetrics()
+
+// CheckQueueConfig holds queue and per-registrar concurrency settings
+type CheckQueueConfig struct {
+	QueueSize             int
+	DefaultRegistrarLimit int
+	RegistrarLimits       map[string]int
+	CheckTimeout          time.Duration
+	ResultTTL             time.Duration
+	CachedResultTTL       time.Duration
+	// NotDeclaredTTL is how long a "results not declared" answer is reused for other
+	// checks of the same IPO before the registrar is asked again
+	NotDeclaredTTL time.Duration
+}
+
+// DefaultCheckQueueConfig returns the default check queue configuration
+func DefaultCheckQueueConfig() CheckQueueConfig {
+	return CheckQueueConfig{
+		QueueSize:             500,
+		DefaultRegistrarLimit: 2,
+		RegistrarLimits:       map[string]int{},
+		CheckTimeout:          60 * time.Second,
+		ResultTTL:             30 * time.Minute,
+		CachedResultTTL:       7 * 24 * time.Hour,
+		NotDeclaredTTL:        5 * time.Minute,
+	}
+}
+
+// CheckRequestMeta identifies where a check came from, recorded for auditing
+type CheckRequestMeta struct {
+	SourceChannel     string
+	ClientFingerprint string
+	UserAgent         string
+	// TelegramChatID is the chat a Telegram check came from, so a scheduled re-check
+	// can reply there
+	TelegramChatID int64
+	// OwnerHash is the signed-in account or device token that asked, which the cached
+	// result is bound to
+	OwnerHash string
+}
+
+// queuedCheck holds a check and the inputs needed to process it
+type queuedCheck struct {
+	check *models.AllotmentCheck
+	ipo   *models.IPO
+	pan   string
+	meta  CheckRequestMeta
+	done  chan struct{}
+	// stages times the queue wait and the processing stages
+	stages *shared.StageTimer
+}
+
+// AllotmentCheckQueue processes allotment checks on per-registrar worker lanes.
+// Each registrar gets its own queue and a bounded number of workers, so a slow
+// registrar neither blocks the API nor starves checks for other registrars.
+type AllotmentCheckQueue struct {
+	checker *AllotmentChecker
+	cache   *CacheService
+	config  CheckQueueConfig
+	// Rechecks, when enabled, schedules a re-check for results that were not declared yet
+	Rechecks *AllotmentRecheckService
+
+	mutex      sync.RWMutex
+	checks     map[string]*queuedCheck
+	registrars map[string]chan *queuedCheck
+
+	logger *logrus.Entry
+}
+
+// NewAllotmentCheckQueue creates a check queue; registrar workers start on first use.
+// Definitive results are written to the database result cache when cache is non-nil.
+func NewAllotmentCheckQueue(checker *AllotmentChecker, cache *CacheService, config CheckQueueConfig) *AllotmentCheckQueue {
+	defaults := DefaultCheckQueueConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.DefaultRegistrarLimit <= 0 {
+		config.DefaultRegistrarLimit = defaults.DefaultRegistrarLimit
+	}
+	if config.CheckTimeout <= 0 {
+		config.CheckTimeout = defaults.CheckTimeout
+	}
+	if config.ResultTTL <= 0 {
+		config.ResultTTL = defaults.ResultTTL
+	}
+	if config.CachedResultTTL <= 0 {
+		config.CachedResultTTL = defaults.CachedResultTTL
+	}
+	if config.NotDeclaredTTL <= 0 {
+		config.NotDeclaredTTL = defaults.NotDeclaredTTL
+	}
+
+	q := &AllotmentCheckQueue{
+		checker:    checker,
+		cache:      cache,
+		config:     config,
+		checks:     make(map[string]*queuedCheck),
+		registrars: make(map[string]chan *queuedCheck),
+		logger:     logrus.WithField("component", "check_queue"),
+	}
+
+	go q.cleanupExpired()
+
+	return q
+}
+
+// Submit enqueues a check and returns it along with a channel closed on completion
+func (q *AllotmentCheckQueue) Submit(ipo *models.IPO, pan string, meta CheckRequestMeta) (*models.AllotmentCheck, <-chan struct{}, error) {
+	check := &models.AllotmentCheck{
+		CheckID:   uuid.New().String(),
+		IPOID:     ipo.ID,
+		Registrar: ipo.Registrar,
+		Status:    models.CheckStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	item := &queuedCheck{
+		check:  check,
+		ipo:    ipo,
+		pan:    pan,
+		meta:   meta,
+		done:   make(chan struct{}),
+		stages: shared.NewStageTimer(),
+	}
+	// Copy the check before a worker can pick it up and start updating it
+	snapshot := *check
+
+	q.mutex.Lock()
+	q.checks[check.CheckID] = item
+	q.mutex.Unlock()
+
+	select {
+	case q.registrarQueue(ipo.Registrar) <- item:
+	default:
+		q.mutex.Lock()
+		delete(q.checks, check.CheckID)
+		q.mutex.Unlock()
+		return nil, nil, ErrCheckQueueFull
+	}
+
+	return &snapshot, item.done, nil
+}
+
+// Get returns a snapshot of the check with the given ID, or nil if unknown or expired
+func (q *AllotmentCheckQueue) Get(checkID string) *models.AllotmentCheck {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	item, exists := q.checks[checkID]
+	if !exists {
+		return nil
+	}
+	snapshot := *item.check
+	return &snapshot
+}
+
+// Stages returns the timed stages of the check with the given ID so far, or nil if
+// unknown or expired
+func (q *AllotmentCheckQueue) Stages(checkID string) []shared.StageTiming {
+	q.mutex.RLock()
+	item, exists := q.checks[checkID]
+	q.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+	return item.stages.Stages()
+}
+
+// Stats returns queue depth and tracked check counts by status
+func (q *AllotmentCheckQueue) Stats() map[string]interface{} {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	byStatus := make(map[string]int)
+	for _, item := range q.checks {
+		byStatus[item.check.Status]++
+	}
+
+	queued := make(map[string]int)
+	for registrar, queue := range q.registrars {
+		queued[registrar] = len(queue)
+	}
+
+	return map[string]interface{}{
+		"queued_by_registrar": queued,
+		"by_status":           byStatus,
+	}
+}
+
+// worker processes checks from one registrar queue until the process exits
+func (q *AllotmentCheckQueue) worker(queue chan *queuedCheck) {
+	for item := range queue {
+		q.process(item)
+	}
+}
+
+// process runs a single check against the registrar. While the IPO's results are known
+// to be not declared the registrar is skipped and the check answers not declared.
+func (q *AllotmentCheckQueue) process(item *queuedCheck) {
+	startedAt := time.Now()
+	q.mutex.Lock()
+	item.check.Status = models.CheckStatusProcessing
+	item.check.StartedAt = &startedAt
+	q.mutex.Unlock()
+	item.stages.Add(CheckStageQueueWait, startedAt.Sub(item.check.CreatedAt))
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.config.CheckTimeout)
+	defer cancel()
+
+	source := "live_check"
+	var status string
+	var shares int
+	var err error
+	if q.cache != nil && q.cache.ResultsKnownNotDeclared(item.ipo.ID) {
+		source, status = "not_declared_cache", models.AllotmentStatusNotDeclared
+	} else {
+		status, shares, err = q.checker.CheckAllotmentStatusTimed(ctx, item.ipo, item.pan, item.stages)
+		q.recordDeclaration(item.ipo.ID, status, err)
+	}
+	completedAt := time.Now()
+	recheckScheduled := err == nil && status == models.AllotmentStatusNotDeclared && q.scheduleRecheck(item)
+
+	q.mutex.Lock()
+	item.check.CompletedAt = &completedAt
+	if err != nil {
+		item.check.Status = models.CheckStatusFailed
+		item.check.Error = "Failed to check status: " + err.Error()
+		item.check.ErrorCategory = string(shared.ErrorCategoryOf(err))
+		shared.DefaultErrorCounter.Record("allotment_check", err)
+	} else {
+		item.check.Status = models.CheckStatusComplete
+		item.check.Result = &models.IPOResultCache{
+			PanHash:           shared.HashPAN(item.pan),
+			IPOID:             item.ipo.ID,
+			Status:            status,
+			SharesAllotted:    shares,
+			Source:            source,
+			UserAgent:         item.meta.UserAgent,
+			SourceChannel:     item.meta.SourceChannel,
+			ClientFingerprint: item.meta.ClientFingerprint,
+			OwnerHash:         item.meta.OwnerHash,
+			Timestamp:         completedAt,
+		}
+		item.check.RecheckScheduled = recheckScheduled
+	}
+	// Drop the raw PAN as soon as it is no longer needed
+	item.pan = ""
+	result := item.check.Result
+	q.mutex.Unlock()
+
+	q.storeResult(result, item.stages)
+	DefaultCheckStageMetrics.Record(item.stages.Stages())
+	close(item.done)
+
+	if source == "live_check" {
+		DefaultAlerter.RecordCheckOutcome(item.ipo.Registrar, err)
+	}
+
+	q.logger.WithFields(logrus.Fields{
+		"check_id":  item.check.CheckID,
+		"registrar": item.ipo.Registrar,
+		"duration":  completedAt.Sub(startedAt),
+		"success":   err == nil,
+	}).Info("Allotment check processed")
+}
+
+// recordDeclaration marks the IPO's results not declared after the registrar said so,
+// and clears the mark once it answers with a result
+func (q *AllotmentCheckQueue) recordDeclaration(ipoID uuid.UUID, status string, err error) {
+	if q.cache == nil || err != nil {
+		return
+	}
+	if status == models.AllotmentStatusNotDeclared {
+		q.cache.MarkResultsNotDeclared(ipoID, q.config.NotDeclaredTTL)
+	} else {
+		q.cache.ClearResultsNotDeclared(ipoID)
+	}
+}
+
+// scheduleRecheck stores a re-check of a check whose results were not declared yet,
+// reporting whether one was scheduled
+func (q *AllotmentCheckQueue) scheduleRecheck(item *queuedCheck) bool {
+	if !q.Rechecks.Enabled() {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Rechecks.Schedule(ctx, item.ipo.ID, item.pan, item.meta); err != nil {
+		q.logger.WithError(err).Warn("Failed to schedule allotment re-check")
+		return false
+	}
+	return true
+}
+
+// storeResult caches definitive results so repeat checks skip the registrar
+func (q *AllotmentCheckQueue) storeResult(result *models.IPOResultCache, stages *shared.StageTimer) {
+	if q.cache == nil || q.cache.DB == nil || result == nil {
+		return
+	}
+	// NOT_FOUND may change once the registrar publishes results, so it is not cached
+	if result.Status != "ALLOTTED" && result.Status != "NOT_ALLOTTED" {
+		return
+	}
+
+	toStore := *result
+	toStore.ExpiresAt = toStore.Timestamp.Add(q.config.CachedResultTTL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	defer stages.Start(CheckStageCacheStore)()
+	if err := q.cache.StoreResult(ctx, &toStore); err != nil {
+		q.logger.WithError(err).Warn("Failed to cache allotment check result")
+	}
+}
+
+// registrarQueue returns the queue for a registrar, starting its workers on first use;
+// the number of workers is the registrar's concurrency limit
+func (q *AllotmentCheckQueue) registrarQueue(registrar string) chan *queuedCheck {
+	key := strings.ToLower(strings.TrimSpace(registrar))
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	queue, exists := q.registrars[key]
+	if !exists {
+		limit := q.config.DefaultRegistrarLimit
+		if override, ok := q.config.RegistrarLimits[key]; ok && override > 0 {
+			limit = override
+		}
+		queue = make(chan *queuedCheck, q.config.QueueSize)
+		q.registrars[key] = queue
+		for i := 0; i < limit; i++ {
+			go q.worker(queue)
+		}
+	}
+	return queue
+}
+
+// cleanupExpired drops finished checks once they are older than ResultTTL
+func (q *AllotmentCheckQueue) cleanupExpired() {
+	ticker := time.NewTicker(q.config.ResultTTL / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-q.config.ResultTTL)
+		q.mutex.Lock()
+		for id, item := range q.checks {
+			if item.check.CompletedAt != nil && item.check.CompletedAt.Before(cutoff) {
+				delete(q.checks, id)
+			}
+		}
+		q.mutex.Unlock()
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CheckQuotaResult describes the outcome of consuming a registrar lookup from a PAN's quota
+type CheckQuotaResult struct {
+	Allowed   bool      `json:"allowed"`
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// CheckQuotaService enforces a daily limit of registrar lookups per PAN hash and IPO.
+// Counters live in the database so the limit holds across replicas.
+type CheckQuotaService struct {
+	DB         *sql.DB
+	DailyLimit int
+	location   *time.Location
+}
+
+// NewCheckQuotaService creates a quota service; days roll over at midnight IST
+func NewCheckQuotaService(db *sql.DB, dailyLimit int) *CheckQuotaService {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		location = time.FixedZone("IST", 5*60*60+30*60)
+	}
+
+	return &CheckQuotaService{
+		DB:         db,
+		DailyLimit: dailyLimit,
+		location:   location,
+	}
+}
+
+// Consume records one registrar lookup for the PAN hash and IPO and reports whether it is allowed
+func (s *CheckQuotaService) Consume(ctx context.Context, panHash, ipoID string) (*CheckQuotaResult, error) {
+	now := time.Now().In(s.location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location)
+
+	result := &CheckQuotaResult{
+		Limit:   s.DailyLimit,
+		ResetAt: today.AddDate(0, 0, 1),
+	}
+
+	if s.DailyLimit <= 0 {
+		result.Allowed = true
+		return result, nil
+	}
+
+	// Only increment while under the limit so rejected requests don't inflate the counter
+	query := `
+		INSERT INTO check_quota_usage (pan_hash, ipo_id, usage_date, lookup_count)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (pan_hash, ipo_id, usage_date) DO UPDATE SET
+			lookup_count = check_quota_usage.lookup_count + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE check_quota_usage.lookup_count < $4
+		RETURNING lookup_count
+	`
+
+	var used int
+	err := s.DB.QueryRowContext(ctx, query, panHash, ipoID, today.Format("2006-01-02"), s.DailyLimit).Scan(&used)
+	if err == sql.ErrNoRows {
+		result.Used = s.DailyLimit
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume check quota: %w", err)
+	}
+
+	result.Allowed = true
+	result.Used = used
+	result.Remaining = s.DailyLimit - used
+	return result, nil
+}
+
+// Refund gives back a lookup that Consume allowed but that never reached the registrar,
+// such as a check the queue turned away. It is a no-op for nil or rejected results.
+func (s *CheckQuotaService) Refund(ctx context.Context, panHash, ipoID string, quota *CheckQuotaResult) error {
+	if quota == nil || !quota.Allowed || s.DailyLimit <= 0 {
+		return nil
+	}
+	// The lookup counted against the day before its reset
+	usageDate := quota.ResetAt.AddDate(0, 0, -1).Format("2006-01-02")
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE check_quota_usage
+		SET lookup_count = lookup_count - 1, updated_at = CURRENT_TIMESTAMP
+		WHERE pan_hash = $1 AND ipo_id = $2 AND usage_date = $3 AND lookup_count > 0
+	`, panHash, ipoID, usageDate)
+	if err != nil {
+		return fmt.Errorf("failed to refund check quota: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpired removes quota counters from previous days
+func (s *CheckQuotaService) CleanupExpired(ctx context.Context) (int64, error) {
+	now := time.Now().In(s.location)
+	result, err := s.DB.ExecContext(ctx, `DELETE FROM check_quota_usage WHERE usage_date < $1`, now.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
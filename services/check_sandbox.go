@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// SandboxKeyHeader carries a sandbox key. POST /check with a valid key returns a synthetic
+// result and never calls the registrar.
+const SandboxKeyHeader = "X-Sandbox-Key"
+
+// SandboxSource is the result source of sandbox checks
+const SandboxSource = "sandbox"
+
+// CheckSandbox answers allotment checks with deterministic synthetic results, so clients
+// and end-to-end tests can exercise the check flow without registrars. The last letter of
+// the PAN picks the outcome:
+//
+//	A  ALLOTTED, one lot
+//	N  NOT_ALLOTTED
+//	D  RESULT_NOT_DECLARED
+//	F  NOT_FOUND
+//	E  registrar unreachable (network error)
+//	T  registrar timeout
+//	R  registrar rate limit
+//
+// Any other PAN is ALLOTTED or NOT_ALLOTTED by a hash of the PAN and IPO. Sandbox results
+// are never cached, stored or counted against the check quota.
+type CheckSandbox struct {
+	keyHashes []string
+}
+
+// NewCheckSandbox accepts the given sandbox keys; with none the sandbox is disabled. Leave
+// it disabled in production.
+func NewCheckSandbox(keys []string) *CheckSandbox {
+	sandbox := &CheckSandbox{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			sandbox.keyHashes = append(sandbox.keyHashes, hashSandboxKey(key))
+		}
+	}
+	return sandbox
+}
+
+func hashSandboxKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Enabled reports whether any sandbox key is configured
+func (s *CheckSandbox) Enabled() bool {
+	return s != nil && len(s.keyHashes) > 0
+}
+
+// Allows reports whether key is a configured sandbox key
+func (s *CheckSandbox) Allows(key string) bool {
+	if !s.Enabled() || key == "" {
+		return false
+	}
+	hash := hashSandboxKey(key)
+	known := false
+	for _, candidate := range s.keyHashes {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(candidate)) == 1 {
+			known = true
+		}
+	}
+	return known
+}
+
+// Result returns the synthetic result of checking pan for the IPO, or the synthetic
+// registrar error for the failure PANs
+func (s *CheckSandbox) Result(ipo *models.IPO, pan string, now time.Time) (*models.IPOResultCache, error) {
+	pan = shared.NormalizePAN(pan)
+	lot := 1
+	if ipo.MinQty != nil && *ipo.MinQty > 0 {
+		lot = *ipo.MinQty
+	}
+
+	var status string
+	shares := 0
+	switch pan[len(pan)-1] {
+	case 'A':
+		status, shares = "ALLOTTED", lot
+	case 'N':
+		status = "NOT_ALLOTTED"
+	case 'D':
+		status = models.AllotmentStatusNotDeclared
+	case 'F':
+		status = "NOT_FOUND"
+	case 'E':
+		return nil, shared.NetworkErrorf("sandbox: registrar %s unreachable", ipo.Registrar)
+	case 'T':
+		return nil, shared.CategorizeError(shared.ErrorCategoryTimeout,
+			fmt.Errorf("sandbox: registrar %s timed out: %w", ipo.Registrar, context.DeadlineExceeded))
+	case 'R':
+		return nil, shared.RateLimitedErrorf("sandbox: registrar %s rate limit reached", ipo.Registrar)
+	default:
+		sum := sha256.Sum256([]byte(pan + ":" + ipo.ID.String()))
+		if sum[0]%2 == 0 {
+			status, shares = "ALLOTTED", lot
+		} else {
+			status = "NOT_ALLOTTED"
+		}
+	}
+
+	return &models.IPOResultCache{
+		PanHash:        shared.HashPAN(pan),
+		IPOID:          ipo.ID,
+		Status:         status,
+		SharesAllotted: shares,
+		Source:         SandboxSource,
+		Timestamp:      now,
+	}, nil
+}
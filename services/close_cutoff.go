@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// Default bidding cutoffs on an IPO's close date, as times of day in IST. HNI bids close
+// an hour before retail bids; an IPO's status turns CLOSED at the later of its cutoffs.
+const (
+	DefaultRetailCutoff = 17 * time.Hour
+	DefaultHNICutoff    = 16 * time.Hour
+)
+
+// cutoffLayout is how cutoffs are written: an IST time of day, to the minute
+const cutoffLayout = "15:04"
+
+// ParseCutoff parses an "HH:MM" cutoff into its offset from midnight
+func ParseCutoff(value string) (time.Duration, error) {
+	parsed, err := time.Parse(cutoffLayout, strings.TrimSpace(value))
+	if err != nil {
+		return 0, shared.ValidationErrorf("cutoff %q must be an HH:MM time of day", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// cutoffOrDefault returns the stored cutoff, or fallback when none is stored or it does
+// not parse
+func cutoffOrDefault(cutoff *string, fallback time.Duration) time.Duration {
+	if cutoff == nil {
+		return fallback
+	}
+	parsed, err := ParseCutoff(*cutoff)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// CloseTimes returns when bidding closes on closeDate for each investor category, using
+// the IPO's cutoffs where set and the defaults otherwise, or nil without a close date.
+// Close dates are midnight IST, so the cutoffs are IST times.
+func CloseTimes(closeDate *models.Date, retailCutoff, hniCutoff *string) *models.CloseTimes {
+	if closeDate == nil || closeDate.IsZero() {
+		return nil
+	}
+	return &models.CloseTimes{
+		Retail: models.CategoryClose{ClosesAt: closeDate.Add(cutoffOrDefault(retailCutoff, DefaultRetailCutoff))},
+		HNI:    models.CategoryClose{ClosesAt: closeDate.Add(cutoffOrDefault(hniCutoff, DefaultHNICutoff))},
+	}
+}
+
+// biddingClosesAt returns when bidding closes for every category, or nil without close
+// times
+func biddingClosesAt(closeTimes *models.CloseTimes) *time.Time {
+	if closeTimes == nil {
+		return nil
+	}
+	last := closeTimes.Retail.ClosesAt
+	if closeTimes.HNI.ClosesAt.After(last) {
+		last = closeTimes.HNI.ClosesAt
+	}
+	return &last
+}
+
+// secondsUntil returns the whole seconds from now until deadline, and 0 once it has passed
+func secondsUntil(deadline, now time.Time) *int64 {
+	seconds := int64(deadline.Sub(now) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return &seconds
+}
+
+// applyCloseTimes sets an IPO's status at now from its dates and bidding cutoffs, with
+// the close times and, while it is active, the countdown to each cutoff
+func (s *IPOService) applyCloseTimes(ipo *models.IPO, now time.Time) {
+	closeTimes := CloseTimes(ipo.CloseDate, ipo.RetailCutoff, ipo.HNICutoff)
+	computed := s.UtilityService.CalculateIPOStatusClosingAt(ipo.OpenDate, biddingClosesAt(closeTimes), ipo.ListingDate, now)
+	ipo.Status = statusWithResults(computed, ipo.Status)
+	ipo.DisplayStatus = StatusLabel(ipo.Status, DefaultLabelLanguage)
+	ipo.MandateDeadline = MandateDeadline(ipo.CloseDate)
+	ipo.CloseTimes = closeTimes
+	ipo.SecondsRemaining = nil
+	if closeTimes != nil && computed == "ACTIVE" {
+		closeTimes.Retail.SecondsRemaining = secondsUntil(closeTimes.Retail.ClosesAt, now)
+		closeTimes.HNI.SecondsRemaining = secondsUntil(closeTimes.HNI.ClosesAt, now)
+		ipo.SecondsRemaining = secondsUntil(*biddingClosesAt(closeTimes), now)
+	}
+}
+
+// SetCloseCutoffs stores the IPO's bidding cutoffs, "HH:MM" IST times of day; an empty
+// cutoff restores the category's default. Returns false when there is no such IPO.
+func (s *IPOService) SetCloseCutoffs(ctx context.Context, ipoID uuid.UUID, retailCutoff, hniCutoff string) (bool, error) {
+	var values [2]*string
+	for i, cutoff := range []string{retailCutoff, hniCutoff} {
+		if strings.TrimSpace(cutoff) == "" {
+			continue
+		}
+		parsed, err := ParseCutoff(cutoff)
+		if err != nil {
+			return false, err
+		}
+		formatted := fmt.Sprintf("%02d:%02d", int(parsed.Hours()), int(parsed.Minutes())%60)
+		values[i] = &formatted
+	}
+
+	result, err := s.DB.ExecContext(ctx, `
+		UPDATE ipo_list SET retail_cutoff = $2::time, hni_cutoff = $3::time, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, ipoID, values[0], values[1])
+	if err != nil {
+		return false, fmt.Errorf("failed to save close cutoffs: %w", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
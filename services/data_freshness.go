@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DataFreshness is when each kind of scraped data was last written
+type DataFreshness struct {
+	IPOListUpdatedAt      *time.Time
+	GMPUpdatedAt          *time.Time
+	SubscriptionUpdatedAt *time.Time
+}
+
+// DataFreshnessService reads the newest write times of the scraped tables, so clients
+// can tell users how old the numbers they see are
+type DataFreshnessService struct {
+	DB *sql.DB
+}
+
+func NewDataFreshnessService(db *sql.DB) *DataFreshnessService {
+	return &DataFreshnessService{DB: db}
+}
+
+// Freshness returns the last update time of the IPO list, GMP and subscription data;
+// a time is nil when the table has no rows yet
+func (s *DataFreshnessService) Freshness(ctx context.Context) (*DataFreshness, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	var ipoList, gmp, subscription sql.NullTime
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT
+			(SELECT MAX(updated_at) FROM ipo_list),
+			(SELECT MAX(GREATEST(last_updated, checked_at)) FROM ipo_gmp),
+			(SELECT MAX(recorded_at) FROM ipo_subscription_history)
+	`).Scan(&ipoList, &gmp, &subscription); err != nil {
+		return nil, fmt.Errorf("failed to query data freshness: %w", err)
+	}
+
+	return &DataFreshness{
+		IPOListUpdatedAt:      nullTimePtr(ipoList),
+		GMPUpdatedAt:          nullTimePtr(gmp),
+		SubscriptionUpdatedAt: nullTimePtr(subscription),
+	}, nil
+}
+
+// IPOListUpdatedAt returns when an IPO was last written, or nil if never
+func (s *DataFreshnessService) IPOListUpdatedAt(ctx context.Context) (*time.Time, error) {
+	return s.latest(ctx, `SELECT MAX(updated_at) FROM ipo_list`)
+}
+
+// GMPUpdatedAt returns when GMP data was last written or confirmed unchanged by the
+// source, or nil if never
+func (s *DataFreshnessService) GMPUpdatedAt(ctx context.Context) (*time.Time, error) {
+	return s.latest(ctx, `SELECT MAX(GREATEST(last_updated, checked_at)) FROM ipo_gmp`)
+}
+
+// SubscriptionUpdatedAt returns when the last subscription snapshot was recorded, or nil
+// if never
+func (s *DataFreshnessService) SubscriptionUpdatedAt(ctx context.Context) (*time.Time, error) {
+	return s.latest(ctx, `SELECT MAX(recorded_at) FROM ipo_subscription_history`)
+}
+
+func (s *DataFreshnessService) latest(ctx context.Context, query string) (*time.Time, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	var latest sql.NullTime
+	if err := s.DB.QueryRowContext(ctx, query).Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to query data freshness: %w", err)
+	}
+	return nullTimePtr(latest), nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// deviceTokenIDBytes is the length of the random device ID inside a device token
+const deviceTokenIDBytes = 16
+
+// NewDeviceToken issues an anonymous device token, "<device ID>.<signature>", with a
+// random device ID signed by HMAC-SHA256 under key. Results can only be bound to device
+// IDs the server handed out, so a client cannot claim another device's results by
+// choosing its ID.
+func NewDeviceToken(key []byte) (string, error) {
+	id := make([]byte, deviceTokenIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	deviceID := hex.EncodeToString(id)
+	return deviceID + "." + deviceTokenSignature(key, deviceID), nil
+}
+
+// DeviceTokenOwnerHash verifies a token issued by NewDeviceToken and returns the owner
+// hash results are bound to for its device
+func DeviceTokenOwnerHash(key []byte, token string) (string, error) {
+	deviceID, signature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || len(deviceID) != 2*deviceTokenIDBytes ||
+		!hmac.Equal([]byte(signature), []byte(deviceTokenSignature(key, deviceID))) {
+		return "", shared.ValidationErrorf("invalid device token")
+	}
+	sum := sha256.Sum256([]byte("device:" + deviceID))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// deviceTokenSignature signs a device ID; the prefix keeps it distinct from other
+// HMACs under the same key
+func deviceTokenSignature(key []byte, deviceID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("device-token:" + deviceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// MaxExportRange bounds the open-date window of one export request
+const MaxExportRange = 5 * 366 * 24 * time.Hour
+
+// ExportService streams IPO data for bulk research exports
+type ExportService struct {
+	DB      *sql.DB
+	utility *UtilityService
+}
+
+func NewExportService(db *sql.DB) *ExportService {
+	return &ExportService{DB: db, utility: NewUtilityService()}
+}
+
+// StreamIPOs calls fn for each IPO opening in [from, to), oldest first, joined with its
+// latest GMP row. Rows are read one at a time so the export never holds the whole
+// dataset in memory; an error from fn stops the stream. It returns the rows written.
+func (s *ExportService) StreamIPOs(ctx context.Context, from, to time.Time, fn func(*models.IPOExportRecord) error) (int, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT i.id, i.stock_id, i.name, i.company_code, i.symbol, i.registrar,
+		       i.open_date, i.close_date, i.result_date, i.listing_date,
+		       i.price_band_low, i.price_band_high, i.issue_size, i.min_qty, i.min_amount,
+		       i.subscription_status, i.listing_gain,
+		       g.gmp_value, g.gain_percent, g.estimated_listing, g.last_updated
+		FROM ipo_list i
+		LEFT JOIN LATERAL (
+			SELECT gmp_value, gain_percent, estimated_listing, last_updated
+			FROM ipo_gmp
+			WHERE ipo_gmp.stock_id = i.stock_id OR ipo_gmp.company_code = i.company_code
+			ORDER BY CASE WHEN i.stock_id = ipo_gmp.stock_id THEN 1 ELSE 2 END, last_updated DESC
+			LIMIT 1
+		) g ON TRUE
+		WHERE i.open_date >= $1 AND i.open_date < $2 AND i.deleted_at IS NULL
+		ORDER BY i.open_date, i.id
+	`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query IPO export: %w", err)
+	}
+	defer rows.Close()
+
+	written := 0
+	for rows.Next() {
+		var record models.IPOExportRecord
+		if err := rows.Scan(
+			&record.ID, &record.StockID, &record.Name, &record.CompanyCode, &record.Symbol, &record.Registrar,
+			&record.OpenDate, &record.CloseDate, &record.ResultDate, &record.ListingDate,
+			&record.PriceBandLow, &record.PriceBandHigh, &record.IssueSize, &record.MinQty, &record.MinAmount,
+			&record.SubscriptionStatus, &record.ListingGain,
+			&record.FinalGMP, &record.FinalGMPGainPercent, &record.FinalEstimatedListing, &record.GMPLastUpdated,
+		); err != nil {
+			return written, fmt.Errorf("failed to scan IPO export row: %w", err)
+		}
+		record.Status = s.utility.CalculateIPOStatus(record.OpenDate, record.CloseDate, record.ListingDate)
+
+		if err := fn(&record); err != nil {
+			return written, err
+		}
+		written++
+	}
+	if err := rows.Err(); err != nil {
+		return written, fmt.Errorf("failed to read IPO export rows: %w", err)
+	}
+	return written, nil
+}
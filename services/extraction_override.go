@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Lineage sources of fields set by an extraction override
+const (
+	extractionOverrideSelectorSource = models.LineageSourceOverride + ":selector"
+	extractionOverrideManualSource   = models.LineageSourceOverride + ":manual"
+)
+
+// maxExtractionSelectorLength caps each selector of an extraction override
+const maxExtractionSelectorLength = 500
+
+// ExtractionOverrideSource looks up the extraction override of a scraped IPO
+type ExtractionOverrideSource interface {
+	// ExtractionOverrideForStock returns the override of the IPO with stockID, or nil
+	ExtractionOverrideForStock(ctx context.Context, stockID string) (*models.ExtractionOverride, error)
+}
+
+// extractionSelectorSetters parse a selector's text into an IPO field, keyed by the
+// field's API name, and return the fields they set, or nil when the text does not parse
+var extractionSelectorSetters = map[string]func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string{
+	"name": func(_ *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		ipo.Name = text
+		return []string{"name"}
+	},
+	"registrar": func(_ *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		ipo.Registrar = text
+		return []string{"registrar"}
+	},
+	"issue_size":          textSelectorSetter("issue_size", func(ipo *models.IPO) **string { return &ipo.IssueSize }),
+	"subscription_status": textSelectorSetter("subscription_status", func(ipo *models.IPO) **string { return &ipo.SubscriptionStatus }),
+	"listing_gain":        textSelectorSetter("listing_gain", func(ipo *models.IPO) **string { return &ipo.ListingGain }),
+	"open_date":           dateSelectorSetter("open_date", func(ipo *models.IPO) **models.Date { return &ipo.OpenDate }),
+	"close_date":          dateSelectorSetter("close_date", func(ipo *models.IPO) **models.Date { return &ipo.CloseDate }),
+	"result_date":         dateSelectorSetter("result_date", func(ipo *models.IPO) **models.Date { return &ipo.ResultDate }),
+	"listing_date":        dateSelectorSetter("listing_date", func(ipo *models.IPO) **models.Date { return &ipo.ListingDate }),
+	"price_band": func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		prices := extractor.parsePriceBand(text)
+		if len(prices) == 0 {
+			return nil
+		}
+		low, high := prices[0], prices[len(prices)-1]
+		ipo.PriceBandLow, ipo.PriceBandHigh = &low, &high
+		return []string{"price_band_low", "price_band_high"}
+	},
+	"price_band_low": func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		if ipo.PriceBandLow = extractor.parseNumericValueAsFloat(text); ipo.PriceBandLow == nil {
+			return nil
+		}
+		return []string{"price_band_low"}
+	},
+	"price_band_high": func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		if ipo.PriceBandHigh = extractor.parseNumericValueAsFloat(text); ipo.PriceBandHigh == nil {
+			return nil
+		}
+		return []string{"price_band_high"}
+	},
+	"min_qty": func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		if ipo.MinQty = extractor.parseNumericValueAsInteger(text); ipo.MinQty == nil {
+			return nil
+		}
+		return []string{"min_qty"}
+	},
+	"min_amount": func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		if ipo.MinAmount = extractor.parseNumericValueAsInteger(text); ipo.MinAmount == nil {
+			return nil
+		}
+		return []string{"min_amount"}
+	},
+}
+
+func textSelectorSetter(field string, target func(ipo *models.IPO) **string) func(*HTMLDataExtractor, *models.IPO, string) []string {
+	return func(_ *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		*target(ipo) = &text
+		return []string{field}
+	}
+}
+
+func dateSelectorSetter(field string, target func(ipo *models.IPO) **models.Date) func(*HTMLDataExtractor, *models.IPO, string) []string {
+	return func(extractor *HTMLDataExtractor, ipo *models.IPO, text string) []string {
+		date := models.DateOf(extractor.parseStandardDateFormats(text))
+		if date == nil {
+			return nil
+		}
+		*target(ipo) = date
+		return []string{field}
+	}
+}
+
+// ExtractionSelectorFields returns the fields an extraction override can set with a
+// selector, sorted
+func ExtractionSelectorFields() []string {
+	fields := make([]string, 0, len(extractionSelectorSetters))
+	for field := range extractionSelectorSetters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ValidateExtractionOverride trims an extraction override's selectors and checks that it
+// has a selector or manual field, that every selector targets a known field and compiles,
+// and that the manual values are in range
+func ValidateExtractionOverride(override *models.ExtractionOverride) error {
+	for field, selector := range override.Selectors {
+		if _, ok := extractionSelectorSetters[field]; !ok {
+			return shared.ValidationErrorf("unknown selector field %q, expected one of %s", field, strings.Join(ExtractionSelectorFields(), ", "))
+		}
+		selector = strings.TrimSpace(selector)
+		if selector == "" || len(selector) > maxExtractionSelectorLength {
+			return shared.ValidationErrorf("selector of %s must be 1-%d characters", field, maxExtractionSelectorLength)
+		}
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return shared.ValidationErrorf("invalid selector of %s: %v", field, err)
+		}
+		override.Selectors[field] = selector
+	}
+
+	fields := override.Fields
+	if fields != nil {
+		for name, value := range map[string]*string{"name": fields.Name, "registrar": fields.Registrar, "issue_size": fields.IssueSize} {
+			if value != nil && strings.TrimSpace(*value) == "" {
+				return shared.ValidationErrorf("fields.%s must not be empty", name)
+			}
+		}
+		for name, value := range map[string]*float64{"price_band_low": fields.PriceBandLow, "price_band_high": fields.PriceBandHigh} {
+			if value != nil && *value <= 0 {
+				return shared.ValidationErrorf("fields.%s must be positive", name)
+			}
+		}
+		if fields.PriceBandLow != nil && fields.PriceBandHigh != nil && *fields.PriceBandLow > *fields.PriceBandHigh {
+			return shared.ValidationErrorf("fields.price_band_low must not exceed fields.price_band_high")
+		}
+		for name, value := range map[string]*int{"min_qty": fields.MinQty, "min_amount": fields.MinAmount} {
+			if value != nil && *value <= 0 {
+				return shared.ValidationErrorf("fields.%s must be positive", name)
+			}
+		}
+		if fields.OpenDate != nil && fields.CloseDate != nil && fields.CloseDate.Before(fields.OpenDate.Time) {
+			return shared.ValidationErrorf("fields.close_date must not be before fields.open_date")
+		}
+		if *fields == (models.ExtractionOverrideFields{}) {
+			override.Fields = nil
+		}
+	}
+
+	if len(override.Selectors) == 0 && override.Fields == nil {
+		return shared.ValidationErrorf("selectors or fields is required")
+	}
+	return nil
+}
+
+// ApplyExtractionOverride sets the IPO's fields from an extraction override: first from
+// the text of each selector on the detail page, then from the manual fields, which win.
+// The lineage of each field set records the override. Returns the fields set and the
+// selectors that matched nothing or did not parse, which keep the extracted value.
+func (extractor *HTMLDataExtractor) ApplyExtractionOverride(ipo *models.IPO, override *models.ExtractionOverride, document *goquery.Document, fetchedAt time.Time) (applied, failed []string) {
+	if override == nil {
+		return nil, nil
+	}
+
+	selectorFields := make([]string, 0, len(override.Selectors))
+	for field := range override.Selectors {
+		selectorFields = append(selectorFields, field)
+	}
+	sort.Strings(selectorFields)
+	for _, field := range selectorFields {
+		setter, ok := extractionSelectorSetters[field]
+		if !ok || document == nil {
+			failed = append(failed, field)
+			continue
+		}
+		text := extractor.normalizeTextContent(document.Find(override.Selectors[field]).First().Text())
+		var set []string
+		if text != "" {
+			// Parse into a copy, so a selector that does not parse leaves the field alone
+			parsed := *ipo
+			if set = setter(extractor, &parsed, text); len(set) > 0 {
+				*ipo = parsed
+			}
+		}
+		if len(set) == 0 {
+			failed = append(failed, field)
+			continue
+		}
+		setLineage(ipo, extractionOverrideSelectorSource, fetchedAt, set...)
+		applied = append(applied, set...)
+	}
+
+	manual := applyExtractionOverrideFields(ipo, override.Fields)
+	setLineage(ipo, extractionOverrideManualSource, fetchedAt, manual...)
+	for _, field := range manual {
+		if !containsString(applied, field) {
+			applied = append(applied, field)
+		}
+	}
+	return applied, failed
+}
+
+// applyExtractionOverrideFields copies the set manual fields onto the IPO and returns
+// their names
+func applyExtractionOverrideFields(ipo *models.IPO, fields *models.ExtractionOverrideFields) []string {
+	if fields == nil {
+		return nil
+	}
+	var set []string
+	if fields.Name != nil {
+		ipo.Name = *fields.Name
+		set = append(set, "name")
+	}
+	if fields.Registrar != nil {
+		ipo.Registrar = *fields.Registrar
+		set = append(set, "registrar")
+	}
+	for _, date := range []struct {
+		name   string
+		value  *models.Date
+		target **models.Date
+	}{
+		{"open_date", fields.OpenDate, &ipo.OpenDate},
+		{"close_date", fields.CloseDate, &ipo.CloseDate},
+		{"result_date", fields.ResultDate, &ipo.ResultDate},
+		{"listing_date", fields.ListingDate, &ipo.ListingDate},
+	} {
+		if date.value != nil {
+			value := *date.value
+			*date.target = &value
+			set = append(set, date.name)
+		}
+	}
+	if fields.PriceBandLow != nil {
+		value := *fields.PriceBandLow
+		ipo.PriceBandLow = &value
+		set = append(set, "price_band_low")
+	}
+	if fields.PriceBandHigh != nil {
+		value := *fields.PriceBandHigh
+		ipo.PriceBandHigh = &value
+		set = append(set, "price_band_high")
+	}
+	if fields.IssueSize != nil {
+		value := *fields.IssueSize
+		ipo.IssueSize = &value
+		set = append(set, "issue_size")
+	}
+	if fields.MinQty != nil {
+		value := *fields.MinQty
+		ipo.MinQty = &value
+		set = append(set, "min_qty")
+	}
+	if fields.MinAmount != nil {
+		value := *fields.MinAmount
+		ipo.MinAmount = &value
+		set = append(set, "min_amount")
+	}
+	if fields.SubscriptionStatus != nil {
+		value := *fields.SubscriptionStatus
+		ipo.SubscriptionStatus = &value
+		set = append(set, "subscription_status")
+	}
+	if fields.ListingGain != nil {
+		value := *fields.ListingGain
+		ipo.ListingGain = &value
+		set = append(set, "listing_gain")
+	}
+	return set
+}
+
+// applyExtractionOverride applies the scraped IPO's extraction override, if it has one.
+// A failed lookup only logs, so the IPO is still saved as extracted.
+func (service *ChittorgarhIPOScrapingService) applyExtractionOverride(ctx context.Context, ipoData *models.IPO, htmlDocument *goquery.Document, fetchedAt time.Time, logger *logrus.Entry) {
+	if service.ExtractionOverrides == nil {
+		return
+	}
+	override, err := service.ExtractionOverrides.ExtractionOverrideForStock(ctx, ipoData.StockID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load extraction override, keeping extracted values")
+		return
+	}
+	if override == nil {
+		return
+	}
+
+	applied, failed := service.htmlDataExtractor.ApplyExtractionOverride(ipoData, override, htmlDocument, fetchedAt)
+	entry := logger.WithFields(logrus.Fields{"applied_fields": applied, "failed_selectors": failed})
+	if len(failed) > 0 {
+		entry.Warn("Applied extraction override; some selectors matched nothing")
+		return
+	}
+	entry.Info("Applied extraction override")
+}
+
+// GetExtractionOverride returns the IPO's extraction override, or nil when it has none.
+// found is false when there is no such IPO.
+func (s *IPOService) GetExtractionOverride(ctx context.Context, ipoID uuid.UUID) (override *models.ExtractionOverride, found bool, err error) {
+	var raw sql.NullString
+	err = s.DB.QueryRowContext(ctx, `
+		SELECT parser_config->'extraction' FROM ipo_list WHERE id = $1 AND deleted_at IS NULL
+	`, ipoID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load extraction override: %w", err)
+	}
+	override, err = decodeExtractionOverride(raw)
+	return override, true, err
+}
+
+// ExtractionOverrideForStock returns the extraction override of the IPO with stockID,
+// or nil when the IPO is unknown or has none
+func (s *IPOService) ExtractionOverrideForStock(ctx context.Context, stockID string) (*models.ExtractionOverride, error) {
+	var raw sql.NullString
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT parser_config->'extraction' FROM ipo_list WHERE stock_id = $1 AND deleted_at IS NULL
+	`, stockID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load extraction override of IPO %s: %w", stockID, err)
+	}
+	return decodeExtractionOverride(raw)
+}
+
+// SetExtractionOverride validates and stores the IPO's extraction override in its
+// parser_config, keeping the rest of the config; nil clears it. Returns false when there
+// is no such IPO.
+func (s *IPOService) SetExtractionOverride(ctx context.Context, ipoID uuid.UUID, override *models.ExtractionOverride) (bool, error) {
+	var result sql.Result
+	var err error
+	if override == nil {
+		result, err = s.DB.ExecContext(ctx, `
+			UPDATE ipo_list SET parser_config = COALESCE(parser_config, '{}'::jsonb) - 'extraction', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+		`, ipoID)
+	} else {
+		if err := ValidateExtractionOverride(override); err != nil {
+			return false, err
+		}
+		override.UpdatedAt = time.Now()
+		encoded, encodeErr := json.Marshal(override)
+		if encodeErr != nil {
+			return false, fmt.Errorf("failed to encode extraction override: %w", encodeErr)
+		}
+		result, err = s.DB.ExecContext(ctx, `
+			UPDATE ipo_list SET
+				parser_config = COALESCE(parser_config, '{}'::jsonb) || jsonb_build_object('extraction', $2::jsonb),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+		`, ipoID, string(encoded))
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save extraction override: %w", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+func decodeExtractionOverride(raw sql.NullString) (*models.ExtractionOverride, error) {
+	if !raw.Valid || raw.String == "" || raw.String == "null" {
+		return nil, nil
+	}
+	var override models.ExtractionOverride
+	if err := json.Unmarshal([]byte(raw.String), &override); err != nil {
+		return nil, fmt.Errorf("invalid extraction override: %w", err)
+	}
+	return &override, nil
+}
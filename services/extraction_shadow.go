@@ -0,0 +1,265 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// IPO detail extraction strategies
+const (
+	ExtractionStrategyJSON = "json" // ipoData from the page's Next.js payload
+	ExtractionStrategyHTML = "html" // the rendered HTML tables
+)
+
+// MaxShadowMismatchExamples is how many recent mismatches are kept per field
+const MaxShadowMismatchExamples = 5
+
+// maxShadowValueLength truncates long values (description, about) in mismatch examples
+const maxShadowValueLength = 200
+
+// ShadowMismatch is one IPO whose primary and shadow extractions disagree on a field
+type ShadowMismatch struct {
+	StockID string    `json:"stock_id"`
+	IPOName string    `json:"ipo_name"`
+	Primary string    `json:"primary"`
+	Shadow  string    `json:"shadow"`
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+// ShadowFieldStat is the agreement between the primary and shadow extraction on one field.
+// A field is only compared when at least one side has a value.
+type ShadowFieldStat struct {
+	Compared      int              `json:"compared"`
+	Agreed        int              `json:"agreed"`
+	AgreementRate float64          `json:"agreement_rate"`
+	Examples      []ShadowMismatch `json:"examples"`
+}
+
+// ShadowExtractionReport summarizes shadow comparisons since the tracker was created or
+// last reset
+type ShadowExtractionReport struct {
+	Strategy      string                     `json:"strategy"`
+	Since         time.Time                  `json:"since"`
+	Comparisons   int                        `json:"comparisons"`
+	ShadowErrors  int                        `json:"shadow_errors"`
+	ErrorSamples  []string                   `json:"error_samples"`
+	AgreementRate float64                    `json:"agreement_rate"`
+	Fields        map[string]ShadowFieldStat `json:"fields"`
+}
+
+// shadowField reads one compared field of an IPO as text, "" when it is not set
+type shadowField struct {
+	name  string
+	value func(ipo *models.IPO) string
+}
+
+// shadowFields are the fields both extraction strategies produce. Text is compared with
+// whitespace collapsed and numbers with two decimals, so formatting alone never counts as
+// a mismatch.
+var shadowFields = []shadowField{
+	{"name", func(ipo *models.IPO) string { return ipo.Name }},
+	{"registrar", func(ipo *models.IPO) string { return ipo.Registrar }},
+	{"symbol", func(ipo *models.IPO) string { return shadowString(ipo.Symbol) }},
+	{"open_date", func(ipo *models.IPO) string { return shadowDate(ipo.OpenDate) }},
+	{"close_date", func(ipo *models.IPO) string { return shadowDate(ipo.CloseDate) }},
+	{"result_date", func(ipo *models.IPO) string { return shadowDate(ipo.ResultDate) }},
+	{"listing_date", func(ipo *models.IPO) string { return shadowDate(ipo.ListingDate) }},
+	{"price_band_low", func(ipo *models.IPO) string { return shadowFloat(ipo.PriceBandLow) }},
+	{"price_band_high", func(ipo *models.IPO) string { return shadowFloat(ipo.PriceBandHigh) }},
+	{"min_qty", func(ipo *models.IPO) string { return shadowInt(ipo.MinQty) }},
+	{"min_amount", func(ipo *models.IPO) string { return shadowInt(ipo.MinAmount) }},
+	{"issue_size", func(ipo *models.IPO) string { return shadowString(ipo.IssueSize) }},
+	{"description", func(ipo *models.IPO) string { return shadowString(ipo.Description) }},
+	{"about", func(ipo *models.IPO) string { return shadowString(ipo.About) }},
+	{"status", func(ipo *models.IPO) string { return ipo.Status }},
+}
+
+// ExtractionShadow compares the IPOs produced by the primary extraction with those of a
+// shadow strategy run on the same page, so a new extraction path can be checked against
+// live pages before it replaces the current one. It is safe for concurrent use.
+type ExtractionShadow struct {
+	// Strategy is the extraction strategy run in shadow
+	Strategy string
+
+	mutex        sync.Mutex
+	since        time.Time
+	comparisons  int
+	shadowErrors int
+	errorSamples []string
+	fields       map[string]*ShadowFieldStat
+	logger       *logrus.Entry
+}
+
+func NewExtractionShadow(strategy string) *ExtractionShadow {
+	return &ExtractionShadow{
+		Strategy: strategy,
+		since:    time.Now(),
+		fields:   make(map[string]*ShadowFieldStat),
+		logger:   logrus.WithFields(logrus.Fields{"component": "extraction_shadow", "strategy": strategy}),
+	}
+}
+
+// Compare records field-by-field agreement between a primary and a shadow extraction of
+// the same IPO and logs the fields that disagree
+func (s *ExtractionShadow) Compare(primary, shadow *models.IPO) {
+	now := time.Now()
+	mismatches := make(map[string]ShadowMismatch)
+
+	s.mutex.Lock()
+	s.comparisons++
+	for _, field := range shadowFields {
+		primaryValue, shadowValue := field.value(primary), field.value(shadow)
+		if primaryValue == "" && shadowValue == "" {
+			continue
+		}
+		stat := s.fields[field.name]
+		if stat == nil {
+			stat = &ShadowFieldStat{}
+			s.fields[field.name] = stat
+		}
+		stat.Compared++
+		if normalizeShadowValue(primaryValue) == normalizeShadowValue(shadowValue) {
+			stat.Agreed++
+			continue
+		}
+		mismatch := ShadowMismatch{
+			StockID: primary.StockID,
+			IPOName: primary.Name,
+			Primary: truncateShadowValue(primaryValue),
+			Shadow:  truncateShadowValue(shadowValue),
+			SeenAt:  now,
+		}
+		stat.Examples = append(stat.Examples, mismatch)
+		if len(stat.Examples) > MaxShadowMismatchExamples {
+			stat.Examples = stat.Examples[len(stat.Examples)-MaxShadowMismatchExamples:]
+		}
+		mismatches[field.name] = mismatch
+	}
+	s.mutex.Unlock()
+
+	if len(mismatches) == 0 {
+		return
+	}
+	fields := make(logrus.Fields, len(mismatches))
+	for name, mismatch := range mismatches {
+		fields[name] = fmt.Sprintf("primary=%q shadow=%q", mismatch.Primary, mismatch.Shadow)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"stock_id":   primary.StockID,
+		"ipo_name":   primary.Name,
+		"mismatches": fields,
+	}).Warn("Shadow extraction disagrees with the primary extraction")
+}
+
+// RecordError records a shadow extraction that failed on a page the primary extracted
+func (s *ExtractionShadow) RecordError(stockID string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.shadowErrors++
+	s.errorSamples = append(s.errorSamples, fmt.Sprintf("%s: %v", stockID, err))
+	if len(s.errorSamples) > MaxShadowMismatchExamples {
+		s.errorSamples = s.errorSamples[len(s.errorSamples)-MaxShadowMismatchExamples:]
+	}
+}
+
+// Report returns the agreement rate of each field and overall, as percentages
+func (s *ExtractionShadow) Report() ShadowExtractionReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	report := ShadowExtractionReport{
+		Strategy:     s.Strategy,
+		Since:        s.since,
+		Comparisons:  s.comparisons,
+		ShadowErrors: s.shadowErrors,
+		ErrorSamples: append([]string{}, s.errorSamples...),
+		Fields:       make(map[string]ShadowFieldStat, len(s.fields)),
+	}
+	compared, agreed := 0, 0
+	for name, stat := range s.fields {
+		copied := *stat
+		copied.Examples = append([]ShadowMismatch{}, stat.Examples...)
+		copied.AgreementRate = successRate(stat.Agreed, stat.Compared)
+		report.Fields[name] = copied
+		compared += stat.Compared
+		agreed += stat.Agreed
+	}
+	report.AgreementRate = successRate(agreed, compared)
+	return report
+}
+
+// Reset drops every comparison so agreement is measured afresh, e.g. after a fix
+func (s *ExtractionShadow) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.since = time.Now()
+	s.comparisons = 0
+	s.shadowErrors = 0
+	s.errorSamples = nil
+	s.fields = make(map[string]*ShadowFieldStat)
+}
+
+// LogSummary logs the overall agreement rate and the fields below 100%
+func (s *ExtractionShadow) LogSummary() {
+	report := s.Report()
+	var disagreeing []string
+	for name, stat := range report.Fields {
+		if stat.Agreed < stat.Compared {
+			disagreeing = append(disagreeing, fmt.Sprintf("%s=%.1f%%", name, stat.AgreementRate))
+		}
+	}
+	sort.Strings(disagreeing)
+	s.logger.WithFields(logrus.Fields{
+		"comparisons":        report.Comparisons,
+		"shadow_errors":      report.ShadowErrors,
+		"agreement_rate":     fmt.Sprintf("%.1f%%", report.AgreementRate),
+		"disagreeing_fields": strings.Join(disagreeing, ", "),
+	}).Info("Shadow extraction summary")
+}
+
+func normalizeShadowValue(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func truncateShadowValue(value string) string {
+	if len(value) <= maxShadowValueLength {
+		return value
+	}
+	return value[:maxShadowValueLength] + "..."
+}
+
+func shadowString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func shadowDate(date *models.Date) string {
+	if date == nil {
+		return ""
+	}
+	return date.String()
+}
+
+func shadowFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", *value)
+}
+
+func shadowInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *value)
+}
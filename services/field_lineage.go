@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+)
+
+// lineageFields are the IPO fields whose lineage is tracked, keyed by their API name,
+// with a check for whether the IPO has a value for them
+var lineageFields = []struct {
+	name string
+	set  func(ipo *models.IPO) bool
+}{
+	{"name", func(ipo *models.IPO) bool { return ipo.Name != "" }},
+	{"registrar", func(ipo *models.IPO) bool { return ipo.Registrar != "" && ipo.Registrar != "Unknown" }},
+	{"symbol", func(ipo *models.IPO) bool { return ipo.Symbol != nil }},
+	{"open_date", func(ipo *models.IPO) bool { return ipo.OpenDate != nil }},
+	{"close_date", func(ipo *models.IPO) bool { return ipo.CloseDate != nil }},
+	{"result_date", func(ipo *models.IPO) bool { return ipo.ResultDate != nil }},
+	{"listing_date", func(ipo *models.IPO) bool { return ipo.ListingDate != nil }},
+	{"price_band_low", func(ipo *models.IPO) bool { return ipo.PriceBandLow != nil }},
+	{"price_band_high", func(ipo *models.IPO) bool { return ipo.PriceBandHigh != nil }},
+	{"issue_size", func(ipo *models.IPO) bool { return ipo.IssueSize != nil }},
+	{"min_qty", func(ipo *models.IPO) bool { return ipo.MinQty != nil }},
+	{"min_amount", func(ipo *models.IPO) bool { return ipo.MinAmount != nil }},
+	{"subscription_status", func(ipo *models.IPO) bool { return ipo.SubscriptionStatus != nil }},
+	{"listing_gain", func(ipo *models.IPO) bool { return ipo.ListingGain != nil }},
+	{"logo_url", func(ipo *models.IPO) bool { return ipo.LogoURL != nil }},
+	{"description", func(ipo *models.IPO) bool { return ipo.Description != nil }},
+	{"about", func(ipo *models.IPO) bool { return ipo.About != nil }},
+	{"strengths", func(ipo *models.IPO) bool { return jsonArrayHasItems(ipo.Strengths) }},
+	{"risks", func(ipo *models.IPO) bool { return jsonArrayHasItems(ipo.Risks) }},
+	{"anchor_allocation", func(ipo *models.IPO) bool { return ipo.AnchorAllocation != nil }},
+	{"timetable", func(ipo *models.IPO) bool { return len(ipo.Timetable) > 0 }},
+	{"issue_structure", func(ipo *models.IPO) bool { return ipo.IssueStructure != nil }},
+	{"promoter_holding", func(ipo *models.IPO) bool { return ipo.PromoterHolding != nil }},
+	{"faq", func(ipo *models.IPO) bool { return len(ipo.FAQ) > 0 }},
+}
+
+func jsonArrayHasItems(value json.RawMessage) bool {
+	var items []json.RawMessage
+	return json.Unmarshal(value, &items) == nil && len(items) > 0
+}
+
+// tagLineage records source as the lineage of every field the IPO has a value for that
+// has no lineage yet, so fields tagged earlier (e.g. an HTML fallback) keep theirs
+func tagLineage(ipo *models.IPO, source string, fetchedAt time.Time) {
+	if ipo.Lineage == nil {
+		ipo.Lineage = models.FieldLineageMap{}
+	}
+	for _, field := range lineageFields {
+		if _, tagged := ipo.Lineage[field.name]; !tagged && field.set(ipo) {
+			ipo.Lineage.Set(source, fetchedAt, field.name)
+		}
+	}
+}
+
+// lineageColumn encodes an IPO's lineage for ipo_list.field_lineage
+func lineageColumn(ipo *models.IPO) ([]byte, error) {
+	if len(ipo.Lineage) == 0 {
+		return []byte("{}"), nil
+	}
+	encoded, err := json.Marshal(ipo.Lineage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode field lineage: %w", err)
+	}
+	return encoded, nil
+}
+
+// GetFieldLineage returns where each of an IPO's fields came from: the stored lineage of
+// the IPO row, plus gmp_value from the GMP row the IPO endpoints would use, tagged with
+// its provider. Returns nil when the IPO does not exist.
+func (s *IPOService) GetFieldLineage(ctx context.Context, ipoID uuid.UUID) (models.FieldLineageMap, error) {
+	var stored []byte
+	var gmpSource sql.NullString
+	var gmpUpdated sql.NullTime
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT l.field_lineage, g.data_source, g.last_updated
+		FROM ipo_list l
+		LEFT JOIN LATERAL (
+			SELECT data_source, last_updated
+			FROM ipo_gmp
+			WHERE (COALESCE(l.stock_id, '') <> '' AND stock_id = l.stock_id)
+			   OR company_code = l.company_code
+			ORDER BY
+				CASE WHEN COALESCE(l.stock_id, '') <> '' AND stock_id = l.stock_id THEN 1 ELSE 2 END,
+				last_updated DESC
+			LIMIT 1
+		) g ON TRUE
+		WHERE l.id = $1
+	`, ipoID).Scan(&stored, &gmpSource, &gmpUpdated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load field lineage: %w", err)
+	}
+
+	lineage := models.FieldLineageMap{}
+	if len(stored) > 0 {
+		if err := json.Unmarshal(stored, &lineage); err != nil {
+			return nil, fmt.Errorf("failed to parse field lineage: %w", err)
+		}
+	}
+	if gmpUpdated.Valid {
+		source := models.LineageSourceGMP
+		if gmpSource.String != "" {
+			source += ":" + gmpSource.String
+		}
+		lineage.Set(source, gmpUpdated.Time, "gmp_value")
+	}
+	return lineage, nil
+}
+
+// setLineage records source as the lineage of fields, replacing any earlier tag
+func setLineage(ipo *models.IPO, source string, fetchedAt time.Time, fields ...string) {
+	if ipo.Lineage == nil {
+		ipo.Lineage = models.FieldLineageMap{}
+	}
+	ipo.Lineage.Set(source, fetchedAt, fields...)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/lib/pq"
+)
+
+// GMPSaveResult counts what SaveGMPData did with the rows it was given
+type GMPSaveResult struct {
+	Written int `json:"written"`
+	// Unchanged rows had the stored content hash; only their checked_at was moved
+	Unchanged int `json:"unchanged"`
+	// Overridden rows are under an unexpired manual override and were left alone
+	Overridden int `json:"overridden"`
+	Failed     int `json:"failed"`
+}
+
+// GMPContentHash returns the hex SHA-256 of the values a GMP save writes, after
+// smoothing. Timestamps, the row ID and extraction metadata are left out, so a scrape
+// that found the same numbers hashes the same. Amounts are rounded to the paise the
+// columns store.
+func GMPContentHash(gmp models.EnhancedGMPData) string {
+	amount := func(value float64) string { return strconv.FormatFloat(value, 'f', 2, 64) }
+	text := func(value *string) string {
+		if value == nil {
+			return "\x00"
+		}
+		return *value
+	}
+
+	fields := []string{
+		gmp.CompanyCode, amount(gmp.IPOPrice), amount(gmp.GMPValue), amount(gmp.EstimatedListing),
+		amount(gmp.GainPercent), amount(gmp.Sub2), amount(gmp.Kostak), gmp.DataSource,
+		text(gmp.StockID), text(gmp.SubscriptionStatus), text(gmp.ListingGain), text(gmp.IPOStatus),
+	}
+	if gmp.Smoothing != nil {
+		fields = append(fields, amount(gmp.Smoothing.RawGMPValue), amount(gmp.Smoothing.SmoothedGMPValue),
+			strconv.FormatBool(gmp.Smoothing.OutlierRejected))
+	}
+	if gmp.Attribution != nil {
+		fields = append(fields, strings.Join(gmp.Attribution.Providers, ","))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadGMPContentHashes returns the content hash of every stored GMP row by IPO name. Rows
+// written before hashing, or whose content an admin changed since, have none.
+func loadGMPContentHashes(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT ipo_name, content_hash FROM ipo_gmp WHERE content_hash IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GMP content hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var name, hash string
+		if err := rows.Scan(&name, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan GMP content hash: %w", err)
+		}
+		hashes[name] = hash
+	}
+	return hashes, rows.Err()
+}
+
+// markGMPChecked records that the source confirmed the unchanged rows at checkedAt, so
+// the GMP schedule and data freshness see them as refreshed. The one narrow update
+// replaces rewriting each row.
+func markGMPChecked(ctx context.Context, tx *sql.Tx, names []string, checkedAt time.Time) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE ipo_gmp SET checked_at = $2 WHERE ipo_name = ANY($1)
+	`, pq.Array(names), checkedAt); err != nil {
+		return fmt.Errorf("failed to mark unchanged GMP rows checked: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/lib/pq"
+)
+
+// GMPDataSortColumns maps the sort keys accepted by ListGMPData to ipo_gmp columns
+var GMPDataSortColumns = map[string]string{
+	"last_updated": "last_updated",
+	"gain_percent": "gain_percent",
+}
+
+// MaxGMPDataPageSize bounds the rows returned by one ListGMPData call
+const MaxGMPDataPageSize = 200
+
+// GMPDataFilter selects, orders and pages the GMP rows listed to admins
+type GMPDataFilter struct {
+	Search    string   // case-insensitive match on ipo_name or company_code
+	Statuses  []string // ipo_status values, case-insensitive; empty means all
+	SortBy    string   // a key of GMPDataSortColumns, default last_updated
+	Ascending bool
+	Limit     int
+	Offset    int
+}
+
+// ListGMPData returns one page of GMP rows matching filter and the total number of
+// matching rows
+func (s *SimpleGMPService) ListGMPData(ctx context.Context, filter GMPDataFilter) ([]models.GMPAdminRow, int, error) {
+	if s.db == nil {
+		return nil, 0, fmt.Errorf("database not available")
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = "last_updated"
+	}
+	column, ok := GMPDataSortColumns[filter.SortBy]
+	if !ok {
+		return nil, 0, shared.ValidationErrorf("sort must be one of last_updated, gain_percent")
+	}
+	if filter.Limit <= 0 || filter.Limit > MaxGMPDataPageSize {
+		return nil, 0, shared.ValidationErrorf("limit must be between 1 and %d", MaxGMPDataPageSize)
+	}
+	if filter.Offset < 0 {
+		return nil, 0, shared.ValidationErrorf("offset must not be negative")
+	}
+
+	where := " WHERE 1=1"
+	var args []interface{}
+	addFilter := func(clause string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+clause, len(args))
+	}
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		addFilter("(ipo_name ILIKE $%[1]d OR company_code ILIKE $%[1]d)", "%"+escapeLikePattern(search)+"%")
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = strings.ToLower(status)
+		}
+		addFilter("LOWER(ipo_status) = ANY($%d)", pq.Array(statuses))
+	}
+
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+	query := `
+		SELECT id, ipo_name, company_code, ipo_price, gmp_value, estimated_listing, gain_percent,
+		       COALESCE(sub2, 0), COALESCE(kostak, 0), listing_date, last_updated,
+		       stock_id, ipo_status, subscription_status, COALESCE(is_manual_override, FALSE),
+		       COUNT(*) OVER ()
+		FROM ipo_gmp` + where + fmt.Sprintf(" ORDER BY %s %s, id LIMIT $%d OFFSET $%d", column, direction, len(args)+1, len(args)+2)
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query GMP data: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0
+	data := []models.GMPAdminRow{}
+	for rows.Next() {
+		var row models.GMPAdminRow
+		if err := rows.Scan(
+			&row.ID, &row.IPOName, &row.CompanyCode, &row.IPOPrice, &row.GMPValue, &row.EstimatedListing, &row.GainPercent,
+			&row.Sub2, &row.Kostak, &row.ListingDate, &row.LastUpdated,
+			&row.StockID, &row.IPOStatus, &row.SubscriptionStatus, &row.IsManualOverride,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan GMP row: %w", err)
+		}
+		data = append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read GMP rows: %w", err)
+	}
+
+	// A page past the end has no rows to carry the window count
+	if len(data) == 0 && filter.Offset > 0 {
+		if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ipo_gmp"+where, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count GMP data: %w", err)
+		}
+	}
+	return data, total, nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards in a user-supplied search term
+func escapeLikePattern(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// GMPManualOverrideSource is the data_source recorded for admin-entered GMP rows
+const GMPManualOverrideSource = "manual_override"
+
+// activeGMPOverrides holds the identifiers of GMP rows with an unexpired manual override
+type activeGMPOverrides struct {
+	names        map[string]bool
+	companyCodes map[string]bool
+	stockIDs     map[string]bool
+}
+
+// covers reports whether a scraped GMP record targets an overridden row
+func (o activeGMPOverrides) covers(gmp models.EnhancedGMPData) bool {
+	if o.names[gmp.IPOName] || o.companyCodes[gmp.CompanyCode] {
+		return true
+	}
+	return gmp.StockID != nil && *gmp.StockID != "" && o.stockIDs[*gmp.StockID]
+}
+
+// loadActiveGMPOverrides returns the GMP rows that scraping must not overwrite
+func loadActiveGMPOverrides(ctx context.Context, tx *sql.Tx) (activeGMPOverrides, error) {
+	overrides := activeGMPOverrides{
+		names:        make(map[string]bool),
+		companyCodes: make(map[string]bool),
+		stockIDs:     make(map[string]bool),
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ipo_name, company_code, COALESCE(stock_id, '')
+		FROM ipo_gmp
+		WHERE is_manual_override = TRUE AND override_expires_at > NOW()
+	`)
+	if err != nil {
+		return overrides, fmt.Errorf("failed to load GMP overrides: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, companyCode, stockID string
+		if err := rows.Scan(&name, &companyCode, &stockID); err != nil {
+			return overrides, fmt.Errorf("failed to scan GMP override: %w", err)
+		}
+		overrides.names[name] = true
+		overrides.companyCodes[companyCode] = true
+		if stockID != "" {
+			overrides.stockIDs[stockID] = true
+		}
+	}
+
+	return overrides, rows.Err()
+}
+
+// SetGMPOverride writes an admin-entered GMP value for an IPO. The existing GMP row
+// (matched by stock_id, then company_code) is updated in place, or a new row is
+// created when the scraper has not produced one yet.
+func (s *SimpleGMPService) SetGMPOverride(ctx context.Context, ipo *models.IPO, override models.GMPOverride) (*models.EnhancedGMPData, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existingID, existingName, existingSource sql.NullString
+	var existingGMP, existingPrice sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, ipo_name, gmp_value, ipo_price, data_source
+		FROM ipo_gmp
+		WHERE (stock_id = $1 OR company_code = $2)
+		ORDER BY
+			CASE WHEN stock_id = $1 THEN 1 ELSE 2 END,
+			last_updated DESC
+		LIMIT 1
+		FOR UPDATE
+	`, ipo.StockID, ipo.CompanyCode).Scan(&existingID, &existingName, &existingGMP, &existingPrice, &existingSource)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to load existing GMP row: %w", err)
+	}
+
+	now := time.Now()
+	gmp := models.EnhancedGMPData{
+		ID:                 uuid.New().String(),
+		IPOName:            ipo.Name,
+		CompanyCode:        ipo.CompanyCode,
+		GMPValue:           override.GMPValue,
+		LastUpdated:        now,
+		SubscriptionStatus: override.SubscriptionStatus,
+		DataSource:         GMPManualOverrideSource,
+	}
+	if ipo.StockID != "" {
+		stockID := ipo.StockID
+		gmp.StockID = &stockID
+	}
+	if existingID.Valid {
+		gmp.ID = existingID.String
+		gmp.IPOName = existingName.String
+	}
+
+	// Price precedence: explicit override, then the scraped GMP row, then the IPO's upper band
+	switch {
+	case override.IPOPrice != nil:
+		gmp.IPOPrice = *override.IPOPrice
+	case existingPrice.Valid:
+		gmp.IPOPrice = existingPrice.Float64
+	case ipo.PriceBandHigh != nil:
+		gmp.IPOPrice = *ipo.PriceBandHigh
+	}
+	gmp.EstimatedListing = gmp.IPOPrice + gmp.GMPValue
+	if gmp.IPOPrice > 0 {
+		gmp.GainPercent = roundTo(gmp.GMPValue/gmp.IPOPrice*100, 2)
+	}
+
+	provenance := &models.GMPOverrideProvenance{
+		SetBy:     strings.TrimSpace(override.SetBy),
+		Reason:    strings.TrimSpace(override.Reason),
+		SetAt:     now,
+		ExpiresAt: override.ExpiresAt,
+	}
+	if existingGMP.Valid {
+		previous := existingGMP.Float64
+		provenance.PreviousGMPValue = &previous
+	}
+	if existingSource.Valid {
+		provenance.PreviousDataSource = existingSource.String
+	}
+	gmp.ExtractionMetadata = &models.ExtractionMetadata{
+		ExtractedFields:   []string{"gmp_value"},
+		FailedFields:      []string{},
+		ParsingConfidence: 100,
+		TableStructure:    GMPManualOverrideSource,
+		LastSuccessfulRun: now,
+		Override:          provenance,
+	}
+	metadataJSON, err := json.Marshal(gmp.ExtractionMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extraction metadata: %w", err)
+	}
+
+	if existingID.Valid {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE ipo_gmp SET
+				gmp_value = $2,
+				ipo_price = $3,
+				estimated_listing = $4,
+				gain_percent = $5,
+				subscription_status = COALESCE($6, subscription_status),
+				data_source = $7,
+				extraction_metadata = $8,
+				is_manual_override = TRUE,
+				override_expires_at = $9,
+				last_updated = $10,
+				gmp_providers = '{}',
+				gmp_scraped_at = $10,
+				content_hash = NULL
+			WHERE id = $1
+		`, gmp.ID, gmp.GMPValue, gmp.IPOPrice, gmp.EstimatedListing, gmp.GainPercent,
+			gmp.SubscriptionStatus, gmp.DataSource, string(metadataJSON), override.ExpiresAt, now)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ipo_gmp (
+				id, ipo_name, company_code, ipo_price, gmp_value,
+				estimated_listing, gain_percent, last_updated, data_source,
+				stock_id, subscription_status, extraction_metadata,
+				is_manual_override, override_expires_at, gmp_scraped_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, TRUE, $13, $8)
+		`, gmp.ID, gmp.IPOName, gmp.CompanyCode, gmp.IPOPrice, gmp.GMPValue,
+			gmp.EstimatedListing, gmp.GainPercent, now, gmp.DataSource,
+			gmp.StockID, gmp.SubscriptionStatus, string(metadataJSON), override.ExpiresAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save GMP override: %w", err)
+	}
+
+	if err := s.recordGMPHistoryAndSignals(ctx, tx, gmp); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"ipo_name":        gmp.IPOName,
+		"company_code":    gmp.CompanyCode,
+		"gmp_value":       gmp.GMPValue,
+		"gain_percent":    gmp.GainPercent,
+		"ipo_price":       gmp.IPOPrice,
+		"last_updated":    gmp.LastUpdated,
+		"manual_override": true,
+	}
+	if existingGMP.Valid {
+		payload["previous_gmp_value"] = existingGMP.Float64
+	}
+	if err := EnqueueOutboxEvent(ctx, tx, models.EventGMPUpdated, "gmp", gmp.IPOName, payload); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"ipo_name":   gmp.IPOName,
+		"gmp_value":  gmp.GMPValue,
+		"set_by":     provenance.SetBy,
+		"expires_at": override.ExpiresAt,
+	}).Info("Saved manual GMP override")
+
+	return &gmp, nil
+}
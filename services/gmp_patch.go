@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// GMPAdminEditableFields are the ipo_gmp columns PatchGMP can correct
+var GMPAdminEditableFields = []string{"sub2", "kostak", "subscription_status", "listing_gain"}
+
+// maxGMPDecimal is the largest value a DECIMAL(10, 2) GMP column holds
+const maxGMPDecimal = 99999999.99
+
+// ValidateGMPPatch checks a patch before it is applied
+func ValidateGMPPatch(patch models.GMPPatch) error {
+	if patch.Sub2 == nil && patch.Kostak == nil && patch.SubscriptionStatus == nil && patch.ListingGain == nil && len(patch.Unlock) == 0 {
+		return shared.ValidationErrorf("patch must set at least one of %s, or unlock fields", strings.Join(GMPAdminEditableFields, ", "))
+	}
+	if strings.TrimSpace(patch.EditedBy) == "" {
+		return shared.ValidationErrorf("edited_by is required")
+	}
+	for name, value := range map[string]*float64{"sub2": patch.Sub2, "kostak": patch.Kostak} {
+		if value != nil && (*value < 0 || *value > maxGMPDecimal) {
+			return shared.ValidationErrorf("%s must be between 0 and %.2f", name, maxGMPDecimal)
+		}
+	}
+	if patch.SubscriptionStatus != nil && len(*patch.SubscriptionStatus) > 100 {
+		return shared.ValidationErrorf("subscription_status must be at most 100 characters")
+	}
+	if patch.ListingGain != nil && len(*patch.ListingGain) > 50 {
+		return shared.ValidationErrorf("listing_gain must be at most 50 characters")
+	}
+	for _, field := range patch.Unlock {
+		if !isGMPAdminEditableField(field) {
+			return shared.ValidationErrorf("cannot unlock %q; editable fields are %s", field, strings.Join(GMPAdminEditableFields, ", "))
+		}
+	}
+	return nil
+}
+
+func isGMPAdminEditableField(field string) bool {
+	for _, editable := range GMPAdminEditableFields {
+		if field == editable {
+			return true
+		}
+	}
+	return false
+}
+
+// PatchGMP applies an admin correction to the latest GMP row of a company. Each changed
+// field is recorded in ipo_gmp_edit_log and marked admin-owned in admin_fields, which
+// the GMP job respects when it merges scraped values into the row.
+func (s *SimpleGMPService) PatchGMP(ctx context.Context, companyCode string, patch models.GMPPatch) (*models.GMPPatchResult, error) {
+	if err := ValidateGMPPatch(patch); err != nil {
+		return nil, err
+	}
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &models.GMPPatchResult{CompanyCode: companyCode, Edits: []models.GMPFieldEdit{}}
+	var adminFieldsJSON []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, ipo_name, COALESCE(sub2, 0), COALESCE(kostak, 0), subscription_status, listing_gain,
+		       COALESCE(admin_fields, '{}')
+		FROM ipo_gmp
+		WHERE company_code = $1
+		ORDER BY last_updated DESC
+		LIMIT 1
+		FOR UPDATE
+	`, companyCode).Scan(&result.ID, &result.IPOName, &result.Sub2, &result.Kostak,
+		&result.SubscriptionStatus, &result.ListingGain, &adminFieldsJSON)
+	if err == sql.ErrNoRows {
+		return nil, shared.NotFoundErrorf("no GMP row for company code %s", companyCode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GMP row: %w", err)
+	}
+
+	result.AdminFields = make(map[string]time.Time)
+	if err := json.Unmarshal(adminFieldsJSON, &result.AdminFields); err != nil {
+		return nil, fmt.Errorf("failed to decode admin_fields: %w", err)
+	}
+
+	now := time.Now()
+	set := func(field string, oldValue, newValue *string) {
+		result.AdminFields[field] = now
+		if optionalStringEqual(oldValue, newValue) {
+			return
+		}
+		result.Edits = append(result.Edits, models.GMPFieldEdit{Field: field, Action: "set", OldValue: oldValue, NewValue: newValue})
+	}
+	if patch.Sub2 != nil {
+		set("sub2", formatGMPDecimal(result.Sub2), formatGMPDecimal(*patch.Sub2))
+		result.Sub2 = *patch.Sub2
+	}
+	if patch.Kostak != nil {
+		set("kostak", formatGMPDecimal(result.Kostak), formatGMPDecimal(*patch.Kostak))
+		result.Kostak = *patch.Kostak
+	}
+	if patch.SubscriptionStatus != nil {
+		value := strings.TrimSpace(*patch.SubscriptionStatus)
+		set("subscription_status", result.SubscriptionStatus, &value)
+		result.SubscriptionStatus = &value
+	}
+	if patch.ListingGain != nil {
+		value := strings.TrimSpace(*patch.ListingGain)
+		set("listing_gain", result.ListingGain, &value)
+		result.ListingGain = &value
+	}
+	for _, field := range patch.Unlock {
+		if _, locked := result.AdminFields[field]; !locked {
+			continue
+		}
+		delete(result.AdminFields, field)
+		result.Edits = append(result.Edits, models.GMPFieldEdit{Field: field, Action: "unlock"})
+	}
+
+	adminFieldsJSON, err = json.Marshal(result.AdminFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode admin_fields: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE ipo_gmp SET
+			sub2 = $2,
+			kostak = $3,
+			subscription_status = $4,
+			listing_gain = $5,
+			admin_fields = $6,
+			content_hash = NULL
+		WHERE id = $1
+	`, result.ID, result.Sub2, result.Kostak, result.SubscriptionStatus, result.ListingGain, string(adminFieldsJSON)); err != nil {
+		return nil, fmt.Errorf("failed to patch GMP row: %w", err)
+	}
+
+	for _, edit := range result.Edits {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_gmp_edit_log (gmp_id, company_code, field_name, action, old_value, new_value, edited_by, reason, edited_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, result.ID, companyCode, edit.Field, edit.Action, edit.OldValue, edit.NewValue,
+			strings.TrimSpace(patch.EditedBy), strings.TrimSpace(patch.Reason), now); err != nil {
+			return nil, fmt.Errorf("failed to record GMP edit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit GMP patch: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"operation":    "PATCH",
+		"entity_type":  "GMP",
+		"company_code": companyCode,
+		"edited_by":    patch.EditedBy,
+		"reason":       patch.Reason,
+		"edits":        result.Edits,
+	}).Info("AUDIT: GMP row patched")
+	return result, nil
+}
+
+// formatGMPDecimal renders a DECIMAL(10, 2) value for the edit log
+func formatGMPDecimal(value float64) *string {
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	return &formatted
+}
+
+// optionalStringEqual reports whether two optional strings hold the same value
+func optionalStringEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
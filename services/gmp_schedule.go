@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// GMP tracking cadence. GMP moves fastest in the last two days before listing, so those
+// IPOs are tracked more often; tracking stops once the IPO lists.
+const (
+	DefaultGMPTrackingInterval    = time.Hour
+	DefaultGMPNearListingInterval = 10 * time.Minute
+	DefaultGMPNearListingWindow   = 48 * time.Hour
+	gmpScheduleSlack              = time.Minute
+	minGMPScheduleWait            = time.Minute
+)
+
+// GMPTracking is the refresh schedule of one stored GMP row
+type GMPTracking struct {
+	IPOName     string
+	ListingAt   *time.Time // trading start (10:00 IST) on the listing date
+	LastUpdated time.Time  // last written, or confirmed unchanged by the source
+	Interval    time.Duration
+	Stopped     bool // the IPO has listed
+}
+
+// NextDue is when the row should next be refreshed; zero for a stopped row
+func (t GMPTracking) NextDue() time.Time {
+	if t.Stopped {
+		return time.Time{}
+	}
+	return t.LastUpdated.Add(t.Interval)
+}
+
+// GMPSchedule decides, per IPO, how often its GMP is refreshed: every Interval normally,
+// every NearListingInterval within NearListingWindow of listing, and never after listing.
+// The GMP source is one page listing every IPO, so a fetch happens whenever any row is
+// due and only the due rows (plus IPOs seen for the first time) are saved.
+type GMPSchedule struct {
+	DB                  *sql.DB
+	Interval            time.Duration
+	NearListingInterval time.Duration
+	NearListingWindow   time.Duration
+	// Clock is the time due and listing checks are made against
+	Clock shared.Clock
+}
+
+func NewGMPSchedule(db *sql.DB) *GMPSchedule {
+	return &GMPSchedule{
+		DB:                  db,
+		Interval:            DefaultGMPTrackingInterval,
+		NearListingInterval: DefaultGMPNearListingInterval,
+		NearListingWindow:   DefaultGMPNearListingWindow,
+		Clock:               shared.DefaultClock,
+	}
+}
+
+func (s *GMPSchedule) now() time.Time {
+	return shared.ClockOrDefault(s.Clock).Now()
+}
+
+// Tracking returns the schedule of every stored GMP row, keyed by IPO name. A row's
+// listing date comes from its IPO, matched by stock_id first and company_code second.
+func (s *GMPSchedule) Tracking(ctx context.Context) (map[string]GMPTracking, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT g.ipo_name, GREATEST(g.last_updated, g.checked_at), l.listing_date
+		FROM ipo_gmp g
+		LEFT JOIN LATERAL (
+			SELECT listing_date FROM ipo_list
+			WHERE ((COALESCE(g.stock_id, '') <> '' AND stock_id = g.stock_id)
+			   OR company_code = g.company_code) AND deleted_at IS NULL
+			ORDER BY
+				CASE WHEN COALESCE(g.stock_id, '') <> '' AND stock_id = g.stock_id THEN 1 ELSE 2 END,
+				updated_at DESC
+			LIMIT 1
+		) l ON TRUE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GMP schedule: %w", err)
+	}
+	defer rows.Close()
+
+	now := s.now()
+	tracking := make(map[string]GMPTracking)
+	for rows.Next() {
+		var row GMPTracking
+		var lastUpdated sql.NullTime
+		var listingDate *models.Date
+		if err := rows.Scan(&row.IPOName, &lastUpdated, &listingDate); err != nil {
+			return nil, fmt.Errorf("failed to scan GMP schedule: %w", err)
+		}
+		// A row never updated stays zero and is always due
+		row.LastUpdated = lastUpdated.Time
+		if listingDate != nil {
+			listingAt := listingDate.Add(PreOpenWindowEnd)
+			row.ListingAt = &listingAt
+		}
+		row.Interval, row.Stopped = s.intervalAt(row.ListingAt, now)
+		tracking[row.IPOName] = row
+	}
+	return tracking, rows.Err()
+}
+
+// intervalAt returns the refresh interval of an IPO listing at listingAt, and whether it
+// has listed by now
+func (s *GMPSchedule) intervalAt(listingAt *time.Time, now time.Time) (time.Duration, bool) {
+	switch {
+	case listingAt == nil:
+		return s.Interval, false
+	case !now.Before(*listingAt):
+		return 0, true
+	case listingAt.Sub(now) <= s.NearListingWindow:
+		return s.NearListingInterval, false
+	default:
+		return s.Interval, false
+	}
+}
+
+// AnyDue reports whether a fetch is needed: some tracked row is due, or no row is
+// tracked yet so new IPOs can only be found by fetching
+func (s *GMPSchedule) AnyDue(tracking map[string]GMPTracking) bool {
+	now := s.now()
+	active := 0
+	for _, row := range tracking {
+		if row.Stopped {
+			continue
+		}
+		active++
+		if s.due(row, now) {
+			return true
+		}
+	}
+	return active == 0
+}
+
+// due reports whether row is due, allowing a little slack so a refresh that lands just
+// before the interval is up does not wait a whole extra interval
+func (s *GMPSchedule) due(row GMPTracking, now time.Time) bool {
+	return !row.Stopped && !now.Before(row.NextDue().Add(-gmpScheduleSlack))
+}
+
+// Select keeps the fetched rows that should be saved: rows that are due and IPOs not
+// stored yet. Listed IPOs are always dropped; with force every other row is kept.
+func (s *GMPSchedule) Select(tracking map[string]GMPTracking, fetched []models.EnhancedGMPData, force bool) []models.EnhancedGMPData {
+	now := s.now()
+	selected := make([]models.EnhancedGMPData, 0, len(fetched))
+	for _, gmp := range fetched {
+		row, known := tracking[gmp.IPOName]
+		switch {
+		case !known:
+			selected = append(selected, gmp)
+		case row.Stopped:
+			continue
+		case force || s.due(row, now):
+			selected = append(selected, gmp)
+		}
+	}
+	return selected
+}
+
+// NextWait is how long to sleep until the earliest tracked row is due, between a minute
+// and Interval
+func (s *GMPSchedule) NextWait(tracking map[string]GMPTracking) time.Duration {
+	now := s.now()
+	wait := s.Interval
+	for _, row := range tracking {
+		if row.Stopped {
+			continue
+		}
+		if untilDue := row.NextDue().Sub(now); untilDue < wait {
+			wait = untilDue
+		}
+	}
+	if wait < minGMPScheduleWait {
+		wait = minGMPScheduleWait
+	}
+	return wait
+}
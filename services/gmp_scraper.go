@@ -0,0 +1,87 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+)
+
+// GMP scraper implementations, selected with SCRAPER_IMPL
+const (
+	ScraperImplSimplified = "simplified"
+	ScraperImplEnhanced   = "enhanced"
+)
+
+// GMPScraper fetches the current GMP of every IPO from the GMP source. The GMP job fetches
+// through it, so the scraper implementation is switched in one place and can be replaced
+// with a fake in tests.
+type GMPScraper interface {
+	// Name is the implementation name, as accepted by NewGMPScraper
+	Name() string
+	FetchGMPData() ([]models.EnhancedGMPData, error)
+}
+
+// NewGMPScraper returns the GMP scraper implementation named impl
+func NewGMPScraper(impl string, db *sql.DB) (GMPScraper, error) {
+	switch impl {
+	case ScraperImplSimplified:
+		return NewSimpleGMPService(db), nil
+	case ScraperImplEnhanced:
+		return &enhancedGMPScraper{service: NewEnhancedGMPService(nil, db)}, nil
+	default:
+		return nil, fmt.Errorf("unknown scraper implementation %q (want %s or %s)", impl, ScraperImplSimplified, ScraperImplEnhanced)
+	}
+}
+
+// Name returns the implementation name
+func (s *SimpleGMPService) Name() string {
+	return ScraperImplSimplified
+}
+
+// enhancedGMPScraper adapts EnhancedGMPService to GMPScraper
+type enhancedGMPScraper struct {
+	service *EnhancedGMPService
+}
+
+func (s *enhancedGMPScraper) Name() string {
+	return ScraperImplEnhanced
+}
+
+// FetchGMPData scrapes with the enhanced service and converts its rows to the model the
+// GMP job saves. The enhanced scrape only reads name, GMP, price and listing date, so the
+// other fields are listed as failed in the extraction metadata.
+func (s *enhancedGMPScraper) FetchGMPData() ([]models.EnhancedGMPData, error) {
+	rows, err := s.service.FetchGMPData()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	gmpList := make([]models.EnhancedGMPData, 0, len(rows))
+	for _, row := range rows {
+		gmpList = append(gmpList, models.EnhancedGMPData{
+			ID:               uuid.New().String(),
+			IPOName:          row.IPOName,
+			CompanyCode:      row.CompanyCode,
+			IPOPrice:         row.IPOPrice,
+			GMPValue:         row.GMPValue,
+			EstimatedListing: row.EstimatedListing,
+			GainPercent:      row.GainPercent,
+			Sub2:             row.Sub2,
+			Kostak:           row.Kostak,
+			ListingDate:      row.ListingDate,
+			LastUpdated:      now,
+			DataSource:       "investorgain.com",
+			ExtractionMetadata: &models.ExtractionMetadata{
+				ExtractedFields:   []string{"ipo_name", "gmp_value", "gain_percent", "ipo_price", "estimated_listing", "listing_date"},
+				FailedFields:      []string{"subscription_status", "listing_gain", "rating", "ipo_status"},
+				TableStructure:    "investorgain_enhanced",
+				LastSuccessfulRun: now,
+			},
+		})
+	}
+	return gmpList, nil
+}
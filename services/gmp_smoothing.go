@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// GMP smoothing parameters. Deviations are measured against the EMA, or against 5% of
+// the issue price when the EMA is near zero, so a GMP moving off ₹0 is not an infinite jump.
+const (
+	GMPSmoothingAlpha          = 0.3
+	DefaultGMPOutlierThreshold = 50.0 // percent deviation from the EMA
+	gmpDeviationFloorRatio     = 0.05
+)
+
+// GMPCorroborator is a second GMP source consulted when a scraped value looks like an
+// outlier. It reports the GMP it currently has for the IPO, or false when it has none.
+type GMPCorroborator interface {
+	Name() string
+	LookupGMP(ctx context.Context, gmp models.EnhancedGMPData) (float64, bool, error)
+}
+
+// GMPSmoothingState is the stored smoothing state of one IPO's GMP
+type GMPSmoothingState struct {
+	Smoothed     float64
+	HasSmoothed  bool
+	LastRaw      float64
+	LastRejected bool
+}
+
+// GMPSmoothingDecision is the outcome of feeding one raw observation into the EMA
+type GMPSmoothingDecision struct {
+	Smoothed  float64
+	Deviation float64 // percent deviation of the raw value from the previous EMA
+	Outlier   bool
+	Rejected  bool
+}
+
+// gmpDeviationPercent returns how far value is from reference, in percent of reference
+// with a floor derived from the issue price
+func gmpDeviationPercent(value, reference, ipoPrice float64) float64 {
+	base := math.Max(math.Abs(reference), math.Max(ipoPrice*gmpDeviationFloorRatio, 1))
+	return math.Abs(value-reference) / base * 100
+}
+
+// SmoothGMP feeds raw into state's EMA. An observation deviating more than threshold
+// percent from the EMA is rejected unless a second provider corroborated it or it repeats
+// the previous rejected observation; two consecutive scrapes agreeing on a new level are
+// a real move rather than a parsing glitch. An accepted outlier restarts the EMA at raw.
+func SmoothGMP(state GMPSmoothingState, raw, ipoPrice, threshold float64, corroborated bool) GMPSmoothingDecision {
+	if !state.HasSmoothed {
+		return GMPSmoothingDecision{Smoothed: raw}
+	}
+
+	decision := GMPSmoothingDecision{Deviation: roundTo(gmpDeviationPercent(raw, state.Smoothed, ipoPrice), 2)}
+	decision.Outlier = decision.Deviation > threshold
+	if !decision.Outlier {
+		decision.Smoothed = roundTo(GMPSmoothingAlpha*raw+(1-GMPSmoothingAlpha)*state.Smoothed, 2)
+		return decision
+	}
+
+	repeated := state.LastRejected && gmpDeviationPercent(raw, state.LastRaw, ipoPrice) <= threshold
+	if corroborated || repeated {
+		decision.Smoothed = raw
+		return decision
+	}
+	decision.Rejected = true
+	decision.Smoothed = state.Smoothed
+	return decision
+}
+
+// smoothGMP applies outlier rejection and EMA smoothing to a scraped GMP before it is
+// saved. A rejected observation keeps the previously stored GMP, gain and estimate;
+// it reports whether the observation was rejected. The GMP is attributed to its source
+// and to the second provider that corroborated it, if any.
+func (s *SimpleGMPService) smoothGMP(ctx context.Context, tx *sql.Tx, gmp *models.EnhancedGMPData) (bool, error) {
+	var state GMPSmoothingState
+	var current models.EnhancedGMPData
+	var smoothed, lastRaw sql.NullFloat64
+	var lastRejected sql.NullBool
+	err := tx.QueryRowContext(ctx, `
+		SELECT gmp_value, gain_percent, estimated_listing, smoothed_gmp_value, raw_gmp_value, gmp_outlier_rejected
+		FROM ipo_gmp WHERE ipo_name = $1
+	`, gmp.IPOName).Scan(&current.GMPValue, &current.GainPercent, &current.EstimatedListing, &smoothed, &lastRaw, &lastRejected)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to load GMP smoothing state: %w", err)
+	}
+	if err == nil {
+		// Rows saved before smoothing existed start their EMA at the stored GMP
+		state.HasSmoothed = true
+		state.Smoothed = current.GMPValue
+		if smoothed.Valid {
+			state.Smoothed = smoothed.Float64
+		}
+		state.LastRaw = lastRaw.Float64
+		state.LastRejected = lastRejected.Bool && lastRaw.Valid
+	}
+
+	threshold := s.OutlierThreshold
+	if threshold <= 0 {
+		threshold = DefaultGMPOutlierThreshold
+	}
+	providers := []string{gmp.DataSource}
+	decision := SmoothGMP(state, gmp.GMPValue, gmp.IPOPrice, threshold, false)
+	if decision.Rejected {
+		if source, ok := s.corroborate(ctx, *gmp, threshold); ok {
+			providers = append(providers, source)
+			decision = SmoothGMP(state, gmp.GMPValue, gmp.IPOPrice, threshold, true)
+			s.logger.WithFields(logrus.Fields{
+				"company":      gmp.IPOName,
+				"gmp_value":    gmp.GMPValue,
+				"corroborator": source,
+			}).Info("GMP jump corroborated by second provider")
+		}
+	}
+
+	raw := gmp.GMPValue
+	gmp.Smoothing = &models.GMPSmoothing{
+		RawGMPValue:      raw,
+		SmoothedGMPValue: decision.Smoothed,
+		OutlierRejected:  decision.Rejected,
+	}
+	gmp.Attribution = models.NewGMPAttribution(gmp.DataSource, providers, gmp.LastUpdated, false)
+	if decision.Rejected {
+		s.logger.WithFields(logrus.Fields{
+			"company":   gmp.IPOName,
+			"raw_gmp":   raw,
+			"ema":       state.Smoothed,
+			"deviation": decision.Deviation,
+		}).Warn("Rejected GMP outlier")
+		gmp.GMPValue = current.GMPValue
+		gmp.GainPercent = current.GainPercent
+		gmp.EstimatedListing = current.EstimatedListing
+	}
+	return decision.Rejected, nil
+}
+
+// corroborate asks the configured second providers for the IPO's GMP and returns the
+// name of the first one agreeing with the scraped value within threshold
+func (s *SimpleGMPService) corroborate(ctx context.Context, gmp models.EnhancedGMPData, threshold float64) (string, bool) {
+	for _, corroborator := range s.Corroborators {
+		value, ok, err := corroborator.LookupGMP(ctx, gmp)
+		if err != nil {
+			s.logger.WithError(err).WithField("corroborator", corroborator.Name()).Warn("GMP corroboration lookup failed")
+			continue
+		}
+		if ok && gmpDeviationPercent(value, gmp.GMPValue, gmp.IPOPrice) <= threshold {
+			return corroborator.Name(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+const (
+	// gmpTrendWindow is the history window used for momentum and volatility
+	gmpTrendWindow = 72 * time.Hour
+	// gmpListingDecayDays is the time constant for the days-to-listing decay
+	gmpListingDecayDays = 7.0
+)
+
+// CalculateGMPTrendSignals derives trend signals from a GMP history series ordered
+// oldest first. The last point is treated as the current GMP, observed at now; days to
+// listing are counted from today, the current IST date.
+func CalculateGMPTrendSignals(points []models.GMPHistoryPoint, now time.Time, today models.Date, listingDate *time.Time) models.GMPTrendSignals {
+	signals := models.GMPTrendSignals{ComputedAt: &now}
+
+	if listingDate != nil {
+		listingDay := models.NewDate(*listingDate)
+		days := int(math.Round(listingDay.Sub(today.Time).Hours() / 24))
+		if days >= 0 {
+			decay := roundTo(math.Exp(-float64(days)/gmpListingDecayDays), 4)
+			signals.DaysToListing = &days
+			signals.ListingDecay = &decay
+		}
+	}
+
+	if len(points) == 0 {
+		return signals
+	}
+
+	latest := points[len(points)-1]
+
+	// 24h change against the most recent observation at least a day old
+	dayAgo := now.Add(-24 * time.Hour)
+	for i := len(points) - 1; i >= 0; i-- {
+		if !points[i].RecordedAt.After(dayAgo) {
+			change := roundTo(latest.GMPValue-points[i].GMPValue, 2)
+			signals.Change24h = &change
+			break
+		}
+	}
+
+	// Momentum and volatility over the trend window
+	windowStart := now.Add(-gmpTrendWindow)
+	var window []models.GMPHistoryPoint
+	for _, point := range points {
+		if !point.RecordedAt.Before(windowStart) {
+			window = append(window, point)
+		}
+	}
+
+	if len(window) >= 2 && window[len(window)-1].RecordedAt.Sub(window[0].RecordedAt) >= 6*time.Hour {
+		slope := roundTo(gmpSlopePerDay(window), 2)
+		signals.Momentum3d = &slope
+
+		volatility := roundTo(gmpStdDev(window), 4)
+		signals.Volatility = &volatility
+	}
+
+	return signals
+}
+
+// gmpSlopePerDay fits a least-squares line through the points and returns its slope in GMP/day
+func gmpSlopePerDay(points []models.GMPHistoryPoint) float64 {
+	origin := points[0].RecordedAt
+	n := float64(len(points))
+
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := point.RecordedAt.Sub(origin).Hours() / 24
+		y := point.GMPValue
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// gmpStdDev returns the population standard deviation of the GMP values
+func gmpStdDev(points []models.GMPHistoryPoint) float64 {
+	var sum float64
+	for _, point := range points {
+		sum += point.GMPValue
+	}
+	mean := sum / float64(len(points))
+
+	var variance float64
+	for _, point := range points {
+		variance += (point.GMPValue - mean) * (point.GMPValue - mean)
+	}
+	return math.Sqrt(variance / float64(len(points)))
+}
+
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
+
+// recordGMPHistoryAndSignals appends the observation to the GMP history and refreshes
+// the derived trend signals stored on the latest ipo_gmp row, inside the caller's transaction
+func (s *SimpleGMPService) recordGMPHistoryAndSignals(ctx context.Context, tx *sql.Tx, gmp models.EnhancedGMPData) error {
+	recordedAt := gmp.LastUpdated
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO ipo_gmp_history (ipo_name, company_code, gmp_value, gain_percent, recorded_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, gmp.IPOName, gmp.CompanyCode, gmp.GMPValue, gmp.GainPercent, recordedAt); err != nil {
+		return fmt.Errorf("failed to record GMP history: %w", err)
+	}
+
+	// Load slightly more than the trend window so the 24h change always has a baseline
+	rows, err := tx.QueryContext(ctx, `
+		SELECT gmp_value, gain_percent, recorded_at
+		FROM ipo_gmp_history
+		WHERE ipo_name = $1 AND recorded_at >= $2
+		ORDER BY recorded_at ASC
+	`, gmp.IPOName, recordedAt.Add(-gmpTrendWindow-24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to load GMP history: %w", err)
+	}
+
+	var points []models.GMPHistoryPoint
+	for rows.Next() {
+		var point models.GMPHistoryPoint
+		if err := rows.Scan(&point.GMPValue, &point.GainPercent, &point.RecordedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan GMP history: %w", err)
+		}
+		points = append(points, point)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate GMP history: %w", err)
+	}
+
+	var listingDate *time.Time
+	stockID := ""
+	if gmp.StockID != nil {
+		stockID = *gmp.StockID
+	}
+	err = tx.QueryRowContext(ctx, `
+		SELECT listing_date FROM ipo_list
+		WHERE ((stock_id = $1 AND $1 != '') OR company_code = $2) AND deleted_at IS NULL
+		ORDER BY CASE WHEN stock_id = $1 THEN 1 ELSE 2 END
+		LIMIT 1
+	`, stockID, gmp.CompanyCode).Scan(&listingDate)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to load listing date: %w", err)
+	}
+
+	today := models.NewDate(shared.ClockOrDefault(s.Clock).Now())
+	signals := CalculateGMPTrendSignals(points, recordedAt, today, listingDate)
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE ipo_gmp SET
+			gmp_change_24h = $2,
+			gmp_momentum_3d = $3,
+			gmp_volatility = $4,
+			days_to_listing = $5,
+			listing_decay = $6,
+			signals_updated_at = $7
+		WHERE ipo_name = $1
+	`, gmp.IPOName, signals.Change24h, signals.Momentum3d, signals.Volatility,
+		signals.DaysToListing, signals.ListingDecay, signals.ComputedAt); err != nil {
+		return fmt.Errorf("failed to store GMP trend signals: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Hotness component weights; they sum to 1 so scores range 0-100. Watchlists live in the
+// apps and never reach the backend, so they carry no weight.
+const (
+	hotnessWeightGMP          = 0.45
+	hotnessWeightSubscription = 0.3
+	hotnessWeightChecks       = 0.25
+)
+
+// Saturation points for the log-scaled components
+const (
+	hotnessGMPPercentCap   = 100.0
+	hotnessSubscriptionCap = 200.0
+	hotnessCheckVolumeCap  = 10000.0
+)
+
+var subscriptionMultiplePattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*x`)
+
+// HotnessService scores active IPOs by GMP, subscription and check volume
+type HotnessService struct {
+	DB             *sql.DB
+	UtilityService *UtilityService
+	logger         *logrus.Entry
+}
+
+// NewHotnessService creates a new hotness service
+func NewHotnessService(db *sql.DB, utilityService *UtilityService) *HotnessService {
+	return &HotnessService{
+		DB:             db,
+		UtilityService: utilityService,
+		logger:         logrus.WithField("component", "hotness_service"),
+	}
+}
+
+// hotnessInputs holds the raw values gathered for one IPO
+type hotnessInputs struct {
+	gmpPercent   float64
+	subscription float64
+	checkVolume  float64
+}
+
+// ComputeScores recalculates hotness for all IPOs that have not listed yet and stores them
+func (s *HotnessService) ComputeScores(ctx context.Context) (int, error) {
+	// Check volume counts each cached result plus its repeat checks over the last 7 days
+	query := `
+		SELECT i.id, i.open_date, i.close_date, i.listing_date,
+		       COALESCE(g.gain_percent, 0),
+		       COALESCE(NULLIF(g.subscription_status, ''), i.subscription_status, ''),
+		       COALESCE(c.check_volume, 0)
+		FROM ipo_list i
+		LEFT JOIN LATERAL (
+			SELECT gain_percent, subscription_status
+			FROM ipo_gmp
+			WHERE (i.stock_id IS NOT NULL AND stock_id = i.stock_id) OR company_code = i.company_code
+			ORDER BY last_updated DESC
+			LIMIT 1
+		) g ON true
+		LEFT JOIN (
+			SELECT ipo_id, SUM(1 + duplicate_count) AS check_volume
+			FROM ipo_result_cache
+			WHERE timestamp >= NOW() - INTERVAL '7 days'
+			GROUP BY ipo_id
+		) c ON c.ipo_id = i.id
+		WHERE (i.listing_date IS NULL OR i.listing_date >= CURRENT_DATE) AND i.deleted_at IS NULL
+	`
+
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load hotness inputs: %w", err)
+	}
+
+	type scoredIPO struct {
+		id        string
+		score     float64
+		breakdown models.HotnessBreakdown
+	}
+
+	var scored []scoredIPO
+	for rows.Next() {
+		var id string
+		var openDate, closeDate, listingDate *models.Date
+		var inputs hotnessInputs
+		var subscriptionText string
+		if err := rows.Scan(&id, &openDate, &closeDate, &listingDate,
+			&inputs.gmpPercent, &subscriptionText, &inputs.checkVolume); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan hotness inputs: %w", err)
+		}
+
+		if status := s.UtilityService.CalculateIPOStatus(openDate, closeDate, listingDate); status == "LISTED" {
+			continue
+		}
+
+		inputs.subscription = ParseSubscriptionMultiple(subscriptionText)
+		score, breakdown := CalculateHotnessScore(inputs.gmpPercent, inputs.subscription, inputs.checkVolume)
+		scored = append(scored, scoredIPO{id: id, score: score, breakdown: breakdown})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate hotness inputs: %w", err)
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin hotness transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Replace the whole table so listed IPOs drop out of trending
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ipo_hotness`); err != nil {
+		return 0, fmt.Errorf("failed to clear hotness scores: %w", err)
+	}
+
+	for _, item := range scored {
+		breakdownJSON, err := json.Marshal(item.breakdown)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal hotness breakdown: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_hotness (ipo_id, score, breakdown, computed_at)
+			VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		`, item.id, item.score, breakdownJSON); err != nil {
+			return 0, fmt.Errorf("failed to store hotness score: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit hotness scores: %w", err)
+	}
+
+	s.logger.WithField("scored_ipos", len(scored)).Info("Hotness scores updated")
+	return len(scored), nil
+}
+
+// GetTrending returns the top-N scored IPOs, highest score first
+func (s *HotnessService) GetTrending(ctx context.Context, limit int) ([]models.IPOHotness, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT i.id, i.name, i.company_code, i.slug, i.open_date, i.close_date, i.listing_date,
+		       h.score, h.breakdown, h.computed_at
+		FROM ipo_hotness h
+		JOIN ipo_list i ON i.id = h.ipo_id
+		ORDER BY h.score DESC, i.open_date DESC NULLS LAST
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending IPOs: %w", err)
+	}
+	defer rows.Close()
+
+	trending := []models.IPOHotness{}
+	for rows.Next() {
+		var item models.IPOHotness
+		var openDate, closeDate, listingDate *models.Date
+		var breakdown []byte
+		if err := rows.Scan(&item.IPOID, &item.Name, &item.CompanyCode, &item.Slug,
+			&openDate, &closeDate, &listingDate, &item.Score, &breakdown, &item.ComputedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trending IPO: %w", err)
+		}
+		if err := json.Unmarshal(breakdown, &item.Breakdown); err != nil {
+			return nil, fmt.Errorf("failed to parse hotness breakdown: %w", err)
+		}
+		item.Status = s.UtilityService.CalculateIPOStatus(openDate, closeDate, listingDate)
+		trending = append(trending, item)
+	}
+
+	return trending, rows.Err()
+}
+
+// CalculateHotnessScore combines the raw inputs into a 0-100 score with its breakdown.
+// GMP percent scales linearly up to 100%; the other inputs are log-scaled so a
+// few very popular IPOs don't flatten everyone else.
+func CalculateHotnessScore(gmpPercent, subscription, checkVolume float64) (float64, models.HotnessBreakdown) {
+	breakdown := models.HotnessBreakdown{
+		GMPPercent:   hotnessComponent(gmpPercent, clamp01(gmpPercent/hotnessGMPPercentCap), hotnessWeightGMP),
+		Subscription: hotnessComponent(subscription, logScale(subscription, hotnessSubscriptionCap), hotnessWeightSubscription),
+		CheckVolume:  hotnessComponent(checkVolume, logScale(checkVolume, hotnessCheckVolumeCap), hotnessWeightChecks),
+	}
+
+	score := breakdown.GMPPercent.Points + breakdown.Subscription.Points + breakdown.CheckVolume.Points
+
+	return roundTo(score, 2), breakdown
+}
+
+// ParseSubscriptionMultiple extracts the multiple from text such as "526.56x" or "10.5x subscribed"
+func ParseSubscriptionMultiple(text string) float64 {
+	match := subscriptionMultiplePattern.FindStringSubmatch(text)
+	if len(match) < 2 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func hotnessComponent(raw, normalized, weight float64) models.HotnessComponent {
+	return models.HotnessComponent{
+		RawValue:   raw,
+		Normalized: roundTo(normalized, 4),
+		Weight:     weight,
+		Points:     roundTo(normalized*weight*100, 2),
+	}
+}
+
+func logScale(value, cap float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return clamp01(math.Log10(1+value) / math.Log10(1+cap))
+}
+
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
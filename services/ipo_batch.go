@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MaxIPOBatchSize is how many IPOs one batch detail request may ask for
+const MaxIPOBatchSize = 25
+
+// GetIPODetailsByIDs returns the detail of each of the IPOs, as the IPO detail endpoint
+// does, in one query, for screens that show several IPOs at once. With withGMP each IPO
+// also gets the GMP row the single-IPO GMP endpoint would join. IPOs come back in the
+// order of ids; unknown IDs are left out.
+func (s *IPOService) GetIPODetailsByIDs(ctx context.Context, ids []uuid.UUID, withGMP bool) ([]models.IPOWithGMP, error) {
+	if len(ids) == 0 {
+		return []models.IPOWithGMP{}, nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT
+			i.id, i.name, i.company_code, COALESCE(i.description_full, i.description), i.price_band_low, i.price_band_high,
+			i.issue_size, i.issue_size_amount, i.open_date, i.close_date, i.result_date, i.registrar, i.stock_id,
+			i.form_url, i.form_fields, i.form_headers, i.parser_config, i.status, i.subscription_status,
+			i.symbol, i.slug, i.listing_date, i.listing_gain, i.min_qty, i.min_amount,
+			i.logo_url, COALESCE(i.about_full, i.about), i.strengths, i.risks, i.created_at, i.updated_at, i.created_by,
+			to_char(i.retail_cutoff, 'HH24:MI'), to_char(i.hni_cutoff, 'HH24:MI'),
+			i.timetable, i.fresh_issue_shares, i.fresh_issue_amount, i.ofs_shares, i.ofs_amount, i.ofs_percent,
+			i.promoter_holding_pre, i.promoter_holding_post,
+			(
+				SELECT json_agg(json_build_object(
+					'price_band_low', r.price_band_low,
+					'price_band_high', r.price_band_high,
+					'previous_price_band_low', r.previous_price_band_low,
+					'previous_price_band_high', r.previous_price_band_high,
+					'source', r.source,
+					'revised_at', to_char(r.revised_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+				) ORDER BY r.revised_at)
+				FROM ipo_price_revisions r
+				WHERE r.ipo_id = i.id
+			) AS price_revisions,
+			g.gmp_value, g.gain_percent, g.estimated_listing, g.last_updated,
+			g.stock_id, g.subscription_status, g.listing_gain, g.ipo_status,
+			g.data_source, g.extraction_metadata
+		FROM ipo_list i
+		LEFT JOIN LATERAL (
+			SELECT * FROM ipo_gmp gmp
+			WHERE $2
+			  AND ((i.stock_id IS NOT NULL AND gmp.stock_id IS NOT NULL AND i.stock_id = gmp.stock_id)
+			       OR i.company_code = gmp.company_code)
+			ORDER BY
+				CASE WHEN i.stock_id IS NOT NULL AND gmp.stock_id IS NOT NULL AND i.stock_id = gmp.stock_id THEN 1 ELSE 2 END,
+				gmp.last_updated DESC
+			LIMIT 1
+		) g ON TRUE
+		WHERE i.id = ANY($1::uuid[]) AND i.deleted_at IS NULL
+	`, pq.Array(idStrings), withGMP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO details: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]models.IPOWithGMP, len(ids))
+	for rows.Next() {
+		var ipo models.IPOWithGMP
+		var formFields, formHeaders, parserConfig, strengths, risks, timetable, revisions []byte
+		var structure models.IssueStructure
+		var holding models.PromoterHolding
+		var extractionMetadataBytes sql.NullString
+
+		if err := rows.Scan(
+			&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
+			&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+			&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
+			&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
+			&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+			&ipo.RetailCutoff, &ipo.HNICutoff,
+			&timetable, &structure.FreshIssueShares, &structure.FreshIssueAmount,
+			&structure.OFSShares, &structure.OFSAmount, &structure.OFSPercent,
+			&holding.PreIssuePercent, &holding.PostIssuePercent,
+			&revisions,
+			&ipo.GMPValue, &ipo.GainPercent, &ipo.EstimatedListing, &ipo.GMPLastUpdated,
+			&ipo.GMPStockID, &ipo.GMPSubscriptionStatus, &ipo.GMPListingGain, &ipo.GMPIPOStatus,
+			&ipo.GMPDataSource, &extractionMetadataBytes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO detail: %w", err)
+		}
+
+		ipo.FormFields = json.RawMessage(formFields)
+		ipo.FormHeaders = json.RawMessage(formHeaders)
+		ipo.ParserConfig = json.RawMessage(parserConfig)
+		ipo.Strengths = json.RawMessage(strengths)
+		ipo.Risks = json.RawMessage(risks)
+
+		// Same detail fields as LoadIPODetail
+		if structure.OFSPercent != nil {
+			ipo.IssueStructure = &structure
+		}
+		if holding.PreIssuePercent != nil || holding.PostIssuePercent != nil {
+			holding.DilutionPoints = promoterDilutionOf(&holding)
+			ipo.PromoterHolding = &holding
+		}
+		if len(timetable) > 0 {
+			if err := json.Unmarshal(timetable, &ipo.Timetable); err != nil {
+				return nil, fmt.Errorf("failed to parse IPO timetable: %w", err)
+			}
+		}
+		ipo.PriceRevisions = []models.PriceRevision{}
+		if len(revisions) > 0 {
+			if err := json.Unmarshal(revisions, &ipo.PriceRevisions); err != nil {
+				return nil, fmt.Errorf("failed to parse price revisions: %w", err)
+			}
+		}
+
+		if extractionMetadataBytes.Valid && extractionMetadataBytes.String != "" {
+			var metadata models.ExtractionMetadata
+			if err := json.Unmarshal([]byte(extractionMetadataBytes.String), &metadata); err == nil {
+				ipo.GMPExtractionMetadata = &metadata
+			}
+		}
+
+		s.recalculateStatusWithGMP(&ipo)
+		byID[ipo.ID] = ipo
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating IPO detail rows: %w", err)
+	}
+
+	ipos := make([]models.IPOWithGMP, 0, len(byID))
+	for _, id := range ids {
+		if ipo, ok := byID[id]; ok {
+			ipos = append(ipos, ipo)
+		}
+	}
+	return ipos, nil
+}
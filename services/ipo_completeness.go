@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// DefaultCompletenessThreshold is the score below which an IPO is reported as incomplete
+const DefaultCompletenessThreshold = 80.0
+
+// completenessField is one key IPO field checked by the completeness score
+type completenessField struct {
+	name      string
+	populated func(ipo *models.IPO) bool
+}
+
+// ipoCompletenessFields lists the key fields that make up the completeness score
+var ipoCompletenessFields = []completenessField{
+	{"name", func(ipo *models.IPO) bool { return strings.TrimSpace(ipo.Name) != "" }},
+	{"company_code", func(ipo *models.IPO) bool { return strings.TrimSpace(ipo.CompanyCode) != "" }},
+	{"symbol", func(ipo *models.IPO) bool { return hasText(ipo.Symbol) }},
+	{"registrar", func(ipo *models.IPO) bool { return hasText(&ipo.Registrar) && ipo.Registrar != "Unknown" }},
+	{"open_date", func(ipo *models.IPO) bool { return ipo.OpenDate != nil }},
+	{"close_date", func(ipo *models.IPO) bool { return ipo.CloseDate != nil }},
+	{"result_date", func(ipo *models.IPO) bool { return ipo.ResultDate != nil }},
+	{"listing_date", func(ipo *models.IPO) bool { return ipo.ListingDate != nil }},
+	{"price_band_low", func(ipo *models.IPO) bool { return ipo.PriceBandLow != nil && *ipo.PriceBandLow > 0 }},
+	{"price_band_high", func(ipo *models.IPO) bool { return ipo.PriceBandHigh != nil && *ipo.PriceBandHigh > 0 }},
+	{"issue_size", func(ipo *models.IPO) bool { return hasText(ipo.IssueSize) }},
+	{"min_qty", func(ipo *models.IPO) bool { return ipo.MinQty != nil && *ipo.MinQty > 0 }},
+	{"min_amount", func(ipo *models.IPO) bool { return ipo.MinAmount != nil && *ipo.MinAmount > 0 }},
+	{"status", func(ipo *models.IPO) bool { return hasText(&ipo.Status) && ipo.Status != "Unknown" }},
+	{"subscription_status", func(ipo *models.IPO) bool { return hasText(ipo.SubscriptionStatus) }},
+	{"logo_url", func(ipo *models.IPO) bool { return hasText(ipo.LogoURL) }},
+	{"description", func(ipo *models.IPO) bool { return hasText(ipo.Description) }},
+	{"about", func(ipo *models.IPO) bool { return hasText(ipo.About) }},
+	{"strengths", func(ipo *models.IPO) bool { return hasJSONItems(ipo.Strengths) }},
+	{"risks", func(ipo *models.IPO) bool { return hasJSONItems(ipo.Risks) }},
+}
+
+// hasText reports whether a string pointer holds non-blank text
+func hasText(value *string) bool {
+	return value != nil && strings.TrimSpace(*value) != ""
+}
+
+// hasJSONItems reports whether a JSON array or object has at least one entry
+func hasJSONItems(raw json.RawMessage) bool {
+	var items []interface{}
+	if err := json.Unmarshal(raw, &items); err == nil {
+		return len(items) > 0
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return len(object) > 0
+	}
+	return false
+}
+
+// ComputeIPOCompleteness returns the percentage of key fields populated and the names of missing ones
+func ComputeIPOCompleteness(ipo *models.IPO) (float64, []string) {
+	missing := []string{}
+	for _, field := range ipoCompletenessFields {
+		if !field.populated(ipo) {
+			missing = append(missing, field.name)
+		}
+	}
+
+	total := len(ipoCompletenessFields)
+	score := float64(total-len(missing)) / float64(total) * 100
+	return math.Round(score*100) / 100, missing
+}
+
+// GetIncompleteIPOs lists IPOs whose completeness score is below threshold, least complete
+// first. IPOs that have not been scored since the column was added are listed first.
+func (s *IPOService) GetIncompleteIPOs(ctx context.Context, threshold float64, limit int) ([]models.IPOCompleteness, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, name, stock_id, status, completeness_score, missing_fields, updated_at
+		FROM ipo_list
+		WHERE (completeness_score IS NULL OR completeness_score < $1) AND deleted_at IS NULL
+		ORDER BY completeness_score ASC NULLS FIRST, updated_at DESC
+		LIMIT $2
+	`, threshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO completeness: %w", err)
+	}
+	defer rows.Close()
+
+	total := len(ipoCompletenessFields)
+	reports := []models.IPOCompleteness{}
+	for rows.Next() {
+		var report models.IPOCompleteness
+		var missingFields []byte
+		var updatedAt time.Time
+		if err := rows.Scan(&report.IPOID, &report.Name, &report.StockID, &report.Status,
+			&report.Score, &missingFields, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO completeness: %w", err)
+		}
+
+		report.MissingFields = []string{}
+		if len(missingFields) > 0 {
+			if err := json.Unmarshal(missingFields, &report.MissingFields); err != nil {
+				return nil, fmt.Errorf("failed to decode missing fields: %w", err)
+			}
+		}
+		report.TotalFields = total
+		if report.Score != nil {
+			report.PopulatedFields = total - len(report.MissingFields)
+		}
+		report.UpdatedAt = updatedAt
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// completenessColumns computes the completeness_score and missing_fields values stored on upsert
+func completenessColumns(ipo *models.IPO) (float64, string, error) {
+	score, missing := ComputeIPOCompleteness(ipo)
+	missingJSON, err := json.Marshal(missing)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode missing fields: %w", err)
+	}
+	return score, string(missingJSON), nil
+}
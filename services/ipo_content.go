@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// contentColumns encodes an IPO's FAQ and timetable for the faq and timetable JSONB
+// columns. Content the scrape did not find is nil, so the stored copy is kept.
+func contentColumns(ipo *models.IPO) (*string, *string, error) {
+	var faq, timetable *string
+	if len(ipo.FAQ) > 0 {
+		encoded, err := json.Marshal(ipo.FAQ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode FAQ: %w", err)
+		}
+		value := string(encoded)
+		faq = &value
+	}
+	if len(ipo.Timetable) > 0 {
+		encoded, err := json.Marshal(ipo.Timetable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode timetable: %w", err)
+		}
+		value := string(encoded)
+		timetable = &value
+	}
+	return faq, timetable, nil
+}
+
+// issueStructureColumns returns the fresh issue and offer for sale columns of an IPO;
+// all are nil when the scrape found no breakdown
+func issueStructureColumns(ipo *models.IPO) (*int64, *float64, *int64, *float64, *float64) {
+	structure := ipo.IssueStructure
+	if structure == nil {
+		return nil, nil, nil, nil, nil
+	}
+	return structure.FreshIssueShares, structure.FreshIssueAmount, structure.OFSShares, structure.OFSAmount, structure.OFSPercent
+}
+
+// promoterHoldingColumns returns the promoter holding columns of an IPO; each is nil
+// when the scrape did not find it, so the stored value is kept
+func promoterHoldingColumns(ipo *models.IPO) (*float64, *float64) {
+	holding := ipo.PromoterHolding
+	if holding == nil {
+		return nil, nil
+	}
+	return holding.PreIssuePercent, holding.PostIssuePercent
+}
+
+// promoterDilutionOf returns how many percentage points the issue takes off the
+// promoters' holding, or nil unless both holdings are known
+func promoterDilutionOf(holding *models.PromoterHolding) *float64 {
+	if holding == nil || holding.PreIssuePercent == nil || holding.PostIssuePercent == nil {
+		return nil
+	}
+	dilution := roundTo(*holding.PreIssuePercent-*holding.PostIssuePercent, 2)
+	return &dilution
+}
+
+// GetIPOFAQ returns the FAQ extracted from an IPO's page, empty when the page had none
+func (s *IPOService) GetIPOFAQ(ctx context.Context, ipoID uuid.UUID) ([]models.IPOFAQItem, error) {
+	var faq []byte
+	err := s.DB.QueryRowContext(ctx, `SELECT faq FROM ipo_list WHERE id = $1`, ipoID).Scan(&faq)
+	if err == sql.ErrNoRows {
+		return nil, shared.NotFoundErrorf("IPO %s not found", ipoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IPO FAQ: %w", err)
+	}
+
+	items := []models.IPOFAQItem{}
+	if len(faq) > 0 {
+		if err := json.Unmarshal(faq, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse IPO FAQ: %w", err)
+		}
+	}
+	return items, nil
+}
+
+// LoadIPODetail fills the fields returned only on IPO detail responses: the timetable,
+// the issue structure, the promoter holding, the price band history and the previous slugs
+func (s *IPOService) LoadIPODetail(ctx context.Context, ipo *models.IPO) error {
+	var timetable []byte
+	var structure models.IssueStructure
+	var holding models.PromoterHolding
+	var manual models.ManualCheck
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT timetable, fresh_issue_shares, fresh_issue_amount, ofs_shares, ofs_amount, ofs_percent,
+			promoter_holding_pre, promoter_holding_post,
+			COALESCE(manual_check_url, ''), COALESCE(manual_check_instructions, '')
+		FROM ipo_list WHERE id = $1
+	`, ipo.ID).Scan(&timetable, &structure.FreshIssueShares, &structure.FreshIssueAmount,
+		&structure.OFSShares, &structure.OFSAmount, &structure.OFSPercent,
+		&holding.PreIssuePercent, &holding.PostIssuePercent,
+		&manual.URL, &manual.Instructions); err != nil {
+		return fmt.Errorf("failed to load IPO detail: %w", err)
+	}
+	if manual.URL != "" || manual.Instructions != "" {
+		ipo.ManualCheck = &manual
+	}
+	if structure.OFSPercent != nil {
+		ipo.IssueStructure = &structure
+	}
+	if holding.PreIssuePercent != nil || holding.PostIssuePercent != nil {
+		holding.DilutionPoints = promoterDilutionOf(&holding)
+		ipo.PromoterHolding = &holding
+	}
+	if len(timetable) > 0 {
+		if err := json.Unmarshal(timetable, &ipo.Timetable); err != nil {
+			return fmt.Errorf("failed to parse IPO timetable: %w", err)
+		}
+	}
+
+	revisions, err := s.GetPriceRevisions(ctx, ipo.ID)
+	if err != nil {
+		return err
+	}
+	ipo.PriceRevisions = revisions
+
+	previousSlugs, err := s.getPreviousSlugs(ctx, ipo.ID)
+	if err != nil {
+		return err
+	}
+	ipo.PreviousSlugs = previousSlugs
+	return nil
+}
+
+// GetIPODetail returns an IPO by ID or slug with its detail fields loaded, or nil when
+// there is no such IPO
+func (s *IPOService) GetIPODetail(ctx context.Context, idOrSlug string) (*models.IPO, error) {
+	var ipo *models.IPO
+	var err error
+	if _, parseErr := uuid.Parse(idOrSlug); parseErr == nil {
+		ipo, err = s.GetIPOByID(ctx, idOrSlug)
+	} else {
+		ipo, err = s.GetIPOBySlug(ctx, idOrSlug)
+	}
+	if err != nil || ipo == nil {
+		return nil, err
+	}
+	if err := s.LoadIPODetail(ctx, ipo); err != nil {
+		return nil, err
+	}
+	return ipo, nil
+}
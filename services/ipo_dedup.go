@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// dedupNameNoise are name tokens that differ between list entries of the same IPO
+var dedupNameNoise = map[string]bool{
+	"ltd": true, "limited": true, "pvt": true, "private": true, "ipo": true, "the": true,
+}
+
+// IPODuplicate pairs an IPO row with the canonical row it duplicates
+type IPODuplicate struct {
+	Canonical models.IPO
+	Duplicate models.IPO
+}
+
+// ipoMergeColumns are the ipo_list columns the canonical row inherits from a duplicate
+// when it has no value of its own. Dates and price bands are left alone so the
+// canonical row never mixes values from two listings.
+var ipoMergeColumns = []string{
+	"symbol", "issue_size", "issue_size_amount", "min_qty", "min_amount", "subscription_status",
+	"listing_gain", "logo_url", "description", "about", "description_full", "about_full", "slug",
+	"fresh_issue_shares", "fresh_issue_amount", "ofs_shares", "ofs_amount", "ofs_percent",
+	"promoter_holding_pre", "promoter_holding_post", "manual_check_url", "manual_check_instructions",
+}
+
+// ipoChildTable is a table keyed by ipo_id whose rows move to the canonical IPO. conflict
+// lists the other key columns of a unique constraint including ipo_id; rows that would
+// collide with an existing canonical row are dropped instead of moved.
+type ipoChildTable struct {
+	name     string
+	conflict []string
+}
+
+var ipoChildTables = []ipoChildTable{
+	{name: "ipo_result_cache", conflict: []string{"pan_hash"}},
+	{name: "ipo_update_log"},
+	{name: "ipo_hotness", conflict: []string{}},
+	{name: "ipo_anchor_allocations", conflict: []string{}},
+	{name: "check_quota_usage", conflict: []string{"pan_hash", "usage_date"}},
+	{name: "ipo_subscription_history"},
+	{name: "ipo_preopen_prices", conflict: []string{}},
+	{name: "check_feedback"},
+	{name: "ipo_price_revisions"},
+	{name: "ipo_allotment_ratios", conflict: []string{"category"}},
+	{name: "allotment_rechecks", conflict: []string{"pan_hash"}},
+	{name: "mandate_reminder_subscriptions", conflict: []string{"telegram_chat_id"}},
+	{name: "mandate_reminders", conflict: []string{}},
+	{name: "ipo_slug_history"},
+}
+
+// DedupNameKey normalizes an IPO name for duplicate detection: lowercase, punctuation
+// removed and legal-form words such as "Ltd" or "Limited" dropped
+func DedupNameKey(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	kept := fields[:0]
+	for _, field := range fields {
+		if !dedupNameNoise[field] {
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// FindDuplicateIPOs groups IPOs with the same normalized name and overlapping
+// subscription windows. The row with the newer stock ID is canonical; every other row
+// of the group is returned as its duplicate. Rows without an open date are skipped.
+func FindDuplicateIPOs(ipos []models.IPO) []IPODuplicate {
+	groups := make(map[string][]models.IPO)
+	var keys []string
+	for _, ipo := range ipos {
+		if ipo.OpenDate == nil {
+			continue
+		}
+		key := DedupNameKey(ipo.Name)
+		if key == "" {
+			continue
+		}
+		if _, seen := groups[key]; !seen {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], ipo)
+	}
+
+	var duplicates []IPODuplicate
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return newerStockID(group[i], group[j]) })
+		merged := make([]bool, len(group))
+		for i := range group {
+			if merged[i] {
+				continue
+			}
+			for j := i + 1; j < len(group); j++ {
+				if !merged[j] && ipoWindowsOverlap(group[i], group[j]) {
+					merged[j] = true
+					duplicates = append(duplicates, IPODuplicate{Canonical: group[i], Duplicate: group[j]})
+				}
+			}
+		}
+	}
+	return duplicates
+}
+
+// newerStockID reports whether a was listed after b. Numeric stock IDs are compared as
+// numbers; otherwise the later created row wins.
+func newerStockID(a, b models.IPO) bool {
+	aID, aErr := strconv.Atoi(a.StockID)
+	bID, bErr := strconv.Atoi(b.StockID)
+	if aErr == nil && bErr == nil && aID != bID {
+		return aID > bID
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// ipoWindowsOverlap reports whether the open-close windows of two IPOs overlap; a
+// missing close date is treated as a single-day window
+func ipoWindowsOverlap(a, b models.IPO) bool {
+	aClose, bClose := a.OpenDate.Time, b.OpenDate.Time
+	if a.CloseDate != nil {
+		aClose = a.CloseDate.Time
+	}
+	if b.CloseDate != nil {
+		bClose = b.CloseDate.Time
+	}
+	return !a.OpenDate.After(bClose) && !b.OpenDate.After(aClose)
+}
+
+// MergeDuplicateIPO folds a duplicate IPO row into its canonical row the way the admin
+// merge tool does, recording the dedup pass as the merger
+func (s *IPOService) MergeDuplicateIPO(ctx context.Context, duplicate IPODuplicate) error {
+	_, err := s.mergeIPOs(ctx, duplicate.Canonical, duplicate.Duplicate, ipoMerge{
+		source:   "dedup",
+		mergedBy: "dedup",
+		reason:   "same normalized name and overlapping dates",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge IPO %s into %s: %w", duplicate.Duplicate.StockID, duplicate.Canonical.StockID, err)
+	}
+	return nil
+}
+
+// moveIPOChildRows re-points one child table's rows from the duplicate to the canonical
+// IPO and returns how many moved
+func moveIPOChildRows(ctx context.Context, tx *sql.Tx, table ipoChildTable, canonicalID, duplicateID string) (int64, error) {
+	if table.conflict == nil {
+		result, err := tx.ExecContext(ctx, `UPDATE `+table.name+` SET ipo_id = $1 WHERE ipo_id = $2`, canonicalID, duplicateID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move %s rows: %w", table.name, err)
+		}
+		return result.RowsAffected()
+	}
+
+	conditions := []string{"existing.ipo_id = $1"}
+	for _, column := range table.conflict {
+		conditions = append(conditions, fmt.Sprintf("existing.%s = %s.%s", column, table.name, column))
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE `+table.name+` SET ipo_id = $1
+		WHERE ipo_id = $2 AND NOT EXISTS (
+			SELECT 1 FROM `+table.name+` existing WHERE `+strings.Join(conditions, " AND ")+`
+		)
+	`, canonicalID, duplicateID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move %s rows: %w", table.name, err)
+	}
+	// Rows left behind collide with the canonical IPO's own rows, which are kept
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+table.name+` WHERE ipo_id = $1`, duplicateID); err != nil {
+		return 0, fmt.Errorf("failed to drop conflicting %s rows: %w", table.name, err)
+	}
+	return result.RowsAffected()
+}
+
+// DeduplicateIPOs finds duplicate IPO rows and merges each into its canonical row. It
+// returns the merges that succeeded; a failed merge is logged and the pass continues.
+func (s *IPOService) DeduplicateIPOs(ctx context.Context) ([]IPODuplicate, error) {
+	ipos, err := s.GetIPOs(ctx, "all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IPOs for dedup: %w", err)
+	}
+
+	var merged []IPODuplicate
+	for _, duplicate := range FindDuplicateIPOs(ipos) {
+		if err := s.MergeDuplicateIPO(ctx, duplicate); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"canonical_stock_id": duplicate.Canonical.StockID,
+				"duplicate_stock_id": duplicate.Duplicate.StockID,
+			}).Error("Failed to merge duplicate IPO")
+			continue
+		}
+		merged = append(merged, duplicate)
+	}
+	return merged, nil
+}
+
+// MergedStockIDs returns the stock IDs that were merged away, mapped to their canonical
+// stock ID, so the scraper does not recreate them from the list
+func (s *IPOService) MergedStockIDs(ctx context.Context) (map[string]string, error) {
+	rows, err := s.DB.QueryContext(ctx, `SELECT duplicate_stock_id, canonical_stock_id FROM ipo_merges`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO merges: %w", err)
+	}
+	defer rows.Close()
+
+	merged := make(map[string]string)
+	for rows.Next() {
+		var duplicateStockID, canonicalStockID string
+		if err := rows.Scan(&duplicateStockID, &canonicalStockID); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO merge: %w", err)
+		}
+		merged[duplicateStockID] = canonicalStockID
+	}
+	return merged, rows.Err()
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// Feed windows: the RSS feed lists live and upcoming IPOs plus anything announced in
+// the last FeedAnnouncementWindow; the calendar drops events older than FeedCalendarLookback
+const (
+	FeedAnnouncementWindow = 30 * 24 * time.Hour
+	FeedCalendarLookback   = 30 * 24 * time.Hour
+	FeedMaxItems           = 50
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RenderIPORSS renders live, upcoming and recently announced IPOs as an RSS 2.0 feed,
+// newest announcement first. baseURL is the public API root used for item links.
+func RenderIPORSS(ipos []models.IPO, baseURL, selfURL string, now time.Time) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         "IPO Announcements",
+			Link:          baseURL + "/ipos",
+			Description:   "New and upcoming IPOs with dates, price band and lot size",
+			Language:      "en-in",
+			LastBuildDate: now.UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for _, ipo := range ipos {
+		if ipo.Status != "UPCOMING" && ipo.Status != "ACTIVE" && now.Sub(ipo.CreatedAt) > FeedAnnouncementWindow {
+			continue
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       fmt.Sprintf("%s IPO (%s)", ipo.Name, strings.ToLower(ipo.Status)),
+			Link:        fmt.Sprintf("%s/ipos/%s", baseURL, ipo.ID),
+			Description: describeIPOForFeed(&ipo),
+			Category:    ipo.Status,
+			GUID:        rssGUID{Value: "ipo:" + ipo.ID.String()},
+			PubDate:     ipo.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+		if len(feed.Channel.Items) == FeedMaxItems {
+			break
+		}
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render RSS feed: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// describeIPOForFeed summarizes the dates, pricing and registrar of an IPO in one line
+func describeIPOForFeed(ipo *models.IPO) string {
+	var parts []string
+	if ipo.OpenDate != nil && ipo.CloseDate != nil {
+		parts = append(parts, fmt.Sprintf("Opens %s, closes %s",
+			ipo.OpenDate.Format("2 Jan 2006"), ipo.CloseDate.Format("2 Jan 2006")))
+	}
+	if ipo.PriceBandLow != nil && ipo.PriceBandHigh != nil {
+		parts = append(parts, fmt.Sprintf("Price band ₹%.0f-%.0f", *ipo.PriceBandLow, *ipo.PriceBandHigh))
+	}
+	if ipo.MinQty != nil {
+		parts = append(parts, fmt.Sprintf("Lot size %d shares", *ipo.MinQty))
+	}
+	if ipo.IssueSize != nil && *ipo.IssueSize != "" {
+		parts = append(parts, "Issue size "+*ipo.IssueSize)
+	}
+	if ipo.Registrar != "" {
+		parts = append(parts, "Registrar "+ipo.Registrar)
+	}
+	if len(parts) == 0 {
+		return ipo.Name
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+// RenderIPOCalendar renders open, close, allotment and listing dates as all-day iCalendar
+// (RFC 5545) events. Event UIDs are stable per IPO and event kind, so calendar apps update
+// a moved date instead of duplicating it.
+func RenderIPOCalendar(ipos []models.IPO, baseURL string, now time.Time) []byte {
+	var builder strings.Builder
+	writeLine := func(line string) {
+		builder.WriteString(foldICalLine(line))
+		builder.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//ALLOTRA//IPO Calendar//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:IPO Calendar")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	cutoff := now.Add(-FeedCalendarLookback)
+	for _, ipo := range ipos {
+		events := []struct {
+			kind    string
+			date    *models.Date
+			summary string
+		}{
+			{"open", ipo.OpenDate, "IPO opens"},
+			{"close", ipo.CloseDate, "IPO closes"},
+			{"allotment", ipo.ResultDate, "allotment"},
+			{"listing", ipo.ListingDate, "listing"},
+		}
+		for _, event := range events {
+			if event.date == nil || event.date.Before(cutoff) {
+				continue
+			}
+			writeLine("BEGIN:VEVENT")
+			writeLine(fmt.Sprintf("UID:%s-%s@allotra", ipo.ID, event.kind))
+			writeLine("DTSTAMP:" + stamp)
+			writeLine("DTSTART;VALUE=DATE:" + event.date.Format("20060102"))
+			writeLine("DTEND;VALUE=DATE:" + event.date.AddDate(0, 0, 1).Format("20060102"))
+			writeLine("SUMMARY:" + escapeICalText(ipo.Name+" "+event.summary))
+			writeLine("DESCRIPTION:" + escapeICalText(describeIPOForFeed(&ipo)))
+			writeLine(fmt.Sprintf("URL:%s/ipos/%s", baseURL, ipo.ID))
+			writeLine("TRANSP:TRANSPARENT")
+			writeLine("END:VEVENT")
+		}
+	}
+
+	writeLine("END:VCALENDAR")
+	return []byte(builder.String())
+}
+
+// escapeICalText escapes backslashes, separators and newlines in an iCalendar TEXT value
+func escapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// foldICalLine splits a content line into 75-octet chunks joined by CRLF and a space,
+// without breaking a multi-byte character
+func foldICalLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var builder strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			builder.WriteString("\r\n ")
+			width = 1
+		}
+		builder.WriteRune(r)
+		width += size
+	}
+	return builder.String()
+}
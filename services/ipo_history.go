@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// ipoHistoryFields are the IPO fields whose changes are written to ipo_update_log, keyed
+// by their API name, with the column expression holding each. Their old and new values
+// are logged as JSON so GetIPOAsOf can put them back.
+var ipoHistoryFields = []struct {
+	name   string
+	column string
+}{
+	{"name", "name"},
+	{"company_code", "company_code"},
+	{"symbol", "symbol"},
+	{"slug", "slug"},
+	{"registrar", "registrar"},
+	{"status", "status"},
+	{"open_date", "open_date"},
+	{"close_date", "close_date"},
+	{"result_date", "result_date"},
+	{"listing_date", "listing_date"},
+	{"price_band_low", "price_band_low"},
+	{"price_band_high", "price_band_high"},
+	{"issue_size", "issue_size"},
+	{"issue_size_amount", "issue_size_amount"},
+	{"min_qty", "min_qty"},
+	{"min_amount", "min_amount"},
+	{"subscription_status", "subscription_status"},
+	{"listing_gain", "listing_gain"},
+	{"logo_url", "logo_url"},
+	{"description", "COALESCE(description_full, description)"},
+	{"about", "COALESCE(about_full, about)"},
+	{"strengths", "strengths"},
+	{"risks", "risks"},
+}
+
+// ipoHistorySnapshot selects an IPO's history fields as one JSON object
+var ipoHistorySnapshot = func() string {
+	pairs := make([]string, 0, len(ipoHistoryFields))
+	for _, field := range ipoHistoryFields {
+		pairs = append(pairs, fmt.Sprintf("'%s', %s", field.name, field.column))
+	}
+	return "jsonb_build_object(" + strings.Join(pairs, ", ") + ")"
+}()
+
+// ipoUnloggedFields are further columns an IPO upsert rewrites. Their changes are not
+// logged, since GetIPOAsOf does not roll them back, but an upsert changing one of them
+// still changed the IPO.
+var ipoUnloggedFields = []string{
+	"faq", "timetable", "completeness_score", "missing_fields",
+	"fresh_issue_shares", "fresh_issue_amount", "ofs_shares", "ofs_amount", "ofs_percent",
+	"promoter_holding_pre", "promoter_holding_post",
+}
+
+// ipoUpsertSnapshot selects an IPO's history and unlogged fields as one JSON object
+var ipoUpsertSnapshot = func() string {
+	pairs := make([]string, 0, len(ipoUnloggedFields))
+	for _, column := range ipoUnloggedFields {
+		pairs = append(pairs, fmt.Sprintf("'%s', %s", column, column))
+	}
+	return ipoHistorySnapshot + " || jsonb_build_object(" + strings.Join(pairs, ", ") + ")"
+}()
+
+// isIPOHistoryField reports whether name is logged by field changes
+func isIPOHistoryField(name string) bool {
+	for _, field := range ipoHistoryFields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIPOHistoryFields reads the history and unlogged fields of an IPO inside tx,
+// locking its row until tx ends. It returns nil when no IPO has the stock ID.
+func loadIPOHistoryFields(ctx context.Context, tx *sql.Tx, stockID string) (map[string]json.RawMessage, error) {
+	var raw []byte
+	err := tx.QueryRowContext(ctx, `SELECT `+ipoUpsertSnapshot+` FROM ipo_list WHERE stock_id = $1 FOR UPDATE`, stockID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IPO fields for the update log: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode IPO fields for the update log: %w", err)
+	}
+	return fields, nil
+}
+
+// ipoFieldsChanged reports whether any field loaded by loadIPOHistoryFields differs
+// between before and after
+func ipoFieldsChanged(before, after map[string]json.RawMessage) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for name, value := range before {
+		if string(value) != string(after[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// logIPOFieldChanges writes each history field whose value differs between before and
+// after to ipo_update_log inside the caller's transaction
+func logIPOFieldChanges(ctx context.Context, tx *sql.Tx, ipoID uuid.UUID, before, after map[string]json.RawMessage, source string) error {
+	for _, field := range ipoHistoryFields {
+		oldValue, newValue := string(before[field.name]), string(after[field.name])
+		if oldValue == newValue {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_update_log (ipo_id, field_name, old_value, new_value, source, timestamp)
+			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		`, ipoID, field.name, oldValue, newValue, source); err != nil {
+			return fmt.Errorf("failed to log IPO field change: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetIPOAsOf reconstructs an IPO as it was at a point in time: the current record with
+// every logged field change made after that time reverse-applied, newest first. The
+// status is then calculated at that time, as the IPO endpoints would have. Only the
+// fields in the update log are rolled back; changes made before field changes were
+// logged cannot be, and neither can a field whose logged chain is broken (see Skipped).
+// Returns nil when the IPO does not exist, and a not-found error when
+// it was created after at.
+func (s *IPOService) GetIPOAsOf(ctx context.Context, ipoID uuid.UUID, at time.Time) (*models.IPOSnapshot, error) {
+	var raw []byte
+	var stockID string
+	var createdAt time.Time
+	var createdBy, retailCutoff, hniCutoff *string
+	var createdLater bool
+	// TIMESTAMP columns hold the session's local time; casting compares them with at
+	// in the right zone
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT `+ipoHistorySnapshot+`, stock_id, created_at, created_by,
+			to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI'),
+			created_at::timestamptz > $2::timestamptz
+		FROM ipo_list WHERE id = $1 AND deleted_at IS NULL
+	`, ipoID, at).Scan(&raw, &stockID, &createdAt, &createdBy, &retailCutoff, &hniCutoff, &createdLater)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IPO: %w", err)
+	}
+	if createdLater {
+		return nil, shared.NotFoundErrorf("IPO was created at %s, after the requested time", createdAt.Format(time.RFC3339))
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode IPO fields: %w", err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT field_name, old_value, new_value, COALESCE(source, ''), timestamp
+		FROM ipo_update_log
+		WHERE ipo_id = $1 AND timestamp::timestamptz > $2::timestamptz
+		ORDER BY timestamp DESC
+	`, ipoID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IPO update log: %w", err)
+	}
+	defer rows.Close()
+
+	snapshot := &models.IPOSnapshot{AsOf: at, Reverted: []models.IPOFieldChange{}, Skipped: []models.IPOFieldChange{}}
+	for rows.Next() {
+		var change models.IPOFieldChange
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&change.Field, &oldValue, &newValue, &change.Source, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO update log: %w", err)
+		}
+		// Merge markers and other log entries are not field values
+		if !isIPOHistoryField(change.Field) || !json.Valid([]byte(oldValue.String)) || !json.Valid([]byte(newValue.String)) {
+			continue
+		}
+		change.OldValue = json.RawMessage(oldValue.String)
+		change.NewValue = json.RawMessage(newValue.String)
+		// A change whose new value is not the value it is rolled back from did not produce
+		// it: the entry moved over from a merged duplicate, or the field was written later
+		// without being logged
+		if string(fields[change.Field]) != newValue.String {
+			snapshot.Skipped = append(snapshot.Skipped, change)
+			continue
+		}
+		fields[change.Field] = change.OldValue
+		snapshot.Reverted = append(snapshot.Reverted, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IPO update log: %w", err)
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode IPO fields: %w", err)
+	}
+	var ipo models.IPO
+	if err := json.Unmarshal(encoded, &ipo); err != nil {
+		return nil, fmt.Errorf("failed to rebuild IPO: %w", err)
+	}
+	ipo.ID, ipo.StockID, ipo.CreatedAt, ipo.CreatedBy = ipoID, stockID, createdAt, createdBy
+	ipo.RetailCutoff, ipo.HNICutoff = retailCutoff, hniCutoff
+	s.applyCloseTimes(&ipo, at)
+	snapshot.IPO = &ipo
+	return snapshot, nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultIPOListAPIURL is Chittorgarh's internal IPO list API, the primary list source
+const DefaultIPOListAPIURL = "https://webnodejs.chittorgarh.com/cloud/ipo/list-read"
+
+// DefaultIPOListHTMLPath is the public mainboard IPO report page used when the API is down
+const DefaultIPOListHTMLPath = "/report/mainboard-ipo-list-in-india-bse-nse/83/"
+
+// ipoDetailLinkPattern matches IPO detail page links: /ipo/<slug>/<id>/
+var ipoDetailLinkPattern = regexp.MustCompile(`/ipo/([a-z0-9-]+)/(\d+)/?$`)
+
+// IPOListSource is one place the IPO list can be fetched from
+type IPOListSource interface {
+	Name() string
+	FetchIPOList(ctx context.Context) ([]ChittorgarhIPOListItem, error)
+}
+
+// ipoListAPISource reads the JSON list API or a mirror serving the same format
+type ipoListAPISource struct {
+	name    string
+	url     string
+	service *ChittorgarhIPOScrapingService
+}
+
+func (s *ipoListAPISource) Name() string { return s.name }
+
+func (s *ipoListAPISource) FetchIPOList(ctx context.Context) ([]ChittorgarhIPOListItem, error) {
+	return s.service.fetchIPOListFromAPI(ctx, s.url)
+}
+
+// ipoListHTMLSource reads IPO links from a public HTML report page
+type ipoListHTMLSource struct {
+	name    string
+	url     string
+	service *ChittorgarhIPOScrapingService
+}
+
+func (s *ipoListHTMLSource) Name() string { return s.name }
+
+func (s *ipoListHTMLSource) FetchIPOList(ctx context.Context) ([]ChittorgarhIPOListItem, error) {
+	return s.service.fetchIPOListFromHTML(ctx, s.url)
+}
+
+// fetchIPOListFromHTML scrapes IPO detail links from a listing page into list items
+func (service *ChittorgarhIPOScrapingService) fetchIPOListFromHTML(ctx context.Context, pageURL string) ([]ChittorgarhIPOListItem, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if err := service.requestRateLimiter.WaitForHost(ctx, httpRequest.URL.Host); err != nil {
+		return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
+	}
+	service.setBrowserLikeHeaders(httpRequest, "text/html,application/xhtml+xml")
+
+	httpResponse, err := service.executeHTTPRequestWithRetry(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IPO listing page: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	document, err := goquery.NewDocumentFromReader(httpResponse.Body)
+	if err != nil {
+		return nil, shared.ParseErrorf("failed to parse IPO listing page: %v", err)
+	}
+
+	items := ParseIPOListingLinks(document)
+	if len(items) == 0 {
+		return nil, shared.UpstreamChangedErrorf("no IPO links found on listing page %s", pageURL)
+	}
+	return items, nil
+}
+
+// ParseIPOListingLinks collects the IPO detail links of a listing page, one item per IPO ID
+func ParseIPOListingLinks(document *goquery.Document) []ChittorgarhIPOListItem {
+	var items []ChittorgarhIPOListItem
+	seen := make(map[int]bool)
+	document.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		match := ipoDetailLinkPattern.FindStringSubmatch(strings.TrimSpace(href))
+		if match == nil {
+			return
+		}
+		id, err := strconv.Atoi(match[2])
+		if err != nil || seen[id] {
+			return
+		}
+		title := strings.Join(strings.Fields(link.Text()), " ")
+		if title == "" {
+			return
+		}
+		seen[id] = true
+		items = append(items, ChittorgarhIPOListItem{
+			ID:                   id,
+			IPONewsTitle:         title,
+			URLRewriteFolderName: match[1],
+		})
+	})
+	return items
+}
+
+// IPOListSourceHealth is the failover state of one IPO list source
+type IPOListSourceHealth struct {
+	Name                string     `json:"name"`
+	Priority            int        `json:"priority"`
+	Score               float64    `json:"score"`
+	Successes           int64      `json:"successes"`
+	Failures            int64      `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastItems           int        `json:"last_items"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	CooldownUntil       *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// IPOListFailover fetches the IPO list from the first healthy source in priority order.
+// Each source keeps a decaying success score; a source that fails FailureThreshold times
+// in a row, or whose score drops below MinScore, is benched for a cooldown that doubles
+// with each further failure. Once the cooldown passes it is probed first, so a recovered
+// primary takes over again.
+type IPOListFailover struct {
+	Sources          []IPOListSource
+	FailureThreshold int
+	MinScore         float64
+	BaseCooldown     time.Duration
+	MaxCooldown      time.Duration
+
+	mutex  sync.Mutex
+	health map[string]*IPOListSourceHealth
+	now    func() time.Time
+	logger *logrus.Entry
+}
+
+// NewIPOListFailover creates a failover over sources, listed in priority order
+func NewIPOListFailover(sources ...IPOListSource) *IPOListFailover {
+	failover := &IPOListFailover{
+		Sources:          sources,
+		FailureThreshold: 2,
+		MinScore:         0.5,
+		BaseCooldown:     15 * time.Minute,
+		MaxCooldown:      6 * time.Hour,
+		health:           make(map[string]*IPOListSourceHealth),
+		now:              time.Now,
+		logger:           logrus.WithField("component", "ipo_list_failover"),
+	}
+	for priority, source := range sources {
+		failover.health[source.Name()] = &IPOListSourceHealth{Name: source.Name(), Priority: priority, Score: 1}
+	}
+	return failover
+}
+
+// Fetch tries sources in health order and returns the first non-empty list
+func (f *IPOListFailover) Fetch(ctx context.Context) ([]ChittorgarhIPOListItem, error) {
+	var failures []error
+	for _, source := range f.plan() {
+		items, err := source.FetchIPOList(ctx)
+		f.record(source.Name(), len(items), err)
+		if err == nil {
+			return items, nil
+		}
+		failures = append(failures, fmt.Errorf("%s: %w", source.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		f.logger.WithError(err).WithField("source", source.Name()).Warn("IPO list source failed, trying next source")
+	}
+	if len(failures) == 0 {
+		return nil, shared.ValidationErrorf("no IPO list sources configured")
+	}
+	return nil, fmt.Errorf("all IPO list sources failed: %w", errors.Join(failures...))
+}
+
+// plan orders the sources for one fetch: sources due a recovery probe, then healthy
+// sources, then benched sources as a last resort, each group by priority
+func (f *IPOListFailover) plan() []IPOListSource {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	rank := func(h *IPOListSourceHealth) int {
+		switch {
+		case h.CooldownUntil == nil:
+			return 1
+		case !now.Before(*h.CooldownUntil):
+			return 0
+		default:
+			return 2
+		}
+	}
+
+	ordered := append([]IPOListSource(nil), f.Sources...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := f.health[ordered[i].Name()], f.health[ordered[j].Name()]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a.Priority < b.Priority
+	})
+	return ordered
+}
+
+// record updates a source's health after a fetch
+func (f *IPOListFailover) record(name string, items int, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	health := f.health[name]
+	now := f.now()
+	if err == nil {
+		health.Successes++
+		health.ConsecutiveFailures = 0
+		health.Score = 0.7*health.Score + 0.3
+		health.LastItems = items
+		health.LastSuccessAt = &now
+		health.LastError = ""
+		if health.CooldownUntil != nil {
+			f.logger.WithField("source", name).Info("IPO list source recovered")
+		}
+		health.CooldownUntil = nil
+		return
+	}
+
+	health.Failures++
+	health.ConsecutiveFailures++
+	health.Score = 0.7 * health.Score
+	health.LastFailureAt = &now
+	health.LastError = err.Error()
+	if health.ConsecutiveFailures >= f.FailureThreshold || health.Score < f.MinScore {
+		doublings := math.Max(0, float64(health.ConsecutiveFailures-f.FailureThreshold))
+		cooldown := time.Duration(float64(f.BaseCooldown) * math.Pow(2, doublings))
+		if cooldown > f.MaxCooldown || cooldown <= 0 {
+			cooldown = f.MaxCooldown
+		}
+		until := now.Add(cooldown)
+		health.CooldownUntil = &until
+	}
+}
+
+// Health returns a snapshot of every source's health in priority order
+func (f *IPOListFailover) Health() []IPOListSourceHealth {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	snapshot := make([]IPOListSourceHealth, 0, len(f.Sources))
+	for _, source := range f.Sources {
+		snapshot = append(snapshot, *f.health[source.Name()])
+	}
+	return snapshot
+}
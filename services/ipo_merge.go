@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// ipoMerge says who folded one IPO into another and why, for the merge records
+type ipoMerge struct {
+	source   string // ipo_update_log source: "admin" or "dedup"
+	mergedBy string
+	reason   string
+}
+
+// ipoGMPRow identifies the GMP row linked to an IPO
+type ipoGMPRow struct {
+	id      string
+	ipoName string
+}
+
+// MergeIPOs folds the source IPO of an admin merge request into the target: missing
+// metadata is copied over, GMP history, result-cache rows and the other child rows are
+// re-pointed, the source is soft-deleted and the merge is recorded in ipo_merges, both
+// IPOs' update logs and the audit log. Merged IPOs no longer resolve, so a source
+// merged before is not found.
+func (s *IPOService) MergeIPOs(ctx context.Context, request models.IPOMergeRequest) (*models.IPOMergeResult, error) {
+	if request.SourceID == uuid.Nil || request.TargetID == uuid.Nil {
+		return nil, shared.ValidationErrorf("source_id and target_id are required")
+	}
+	if request.SourceID == request.TargetID {
+		return nil, shared.ValidationErrorf("source_id and target_id must differ")
+	}
+	mergedBy := strings.TrimSpace(request.MergedBy)
+	if mergedBy == "" {
+		return nil, shared.ValidationErrorf("merged_by is required")
+	}
+	if len(mergedBy) > 100 {
+		return nil, shared.ValidationErrorf("merged_by must be at most 100 characters")
+	}
+
+	source, err := s.GetIPOByID(ctx, request.SourceID.String())
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, shared.NotFoundErrorf("source IPO %s not found", request.SourceID)
+	}
+	target, err := s.GetIPOByID(ctx, request.TargetID.String())
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, shared.NotFoundErrorf("target IPO %s not found", request.TargetID)
+	}
+
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		reason = "merged by admin"
+	}
+	return s.mergeIPOs(ctx, *target, *source, ipoMerge{source: "admin", mergedBy: mergedBy, reason: reason})
+}
+
+// mergeIPOs folds source into target in one transaction and returns what moved
+func (s *IPOService) mergeIPOs(ctx context.Context, target, source models.IPO, merge ipoMerge) (*models.IPOMergeResult, error) {
+	if target.ID == source.ID {
+		return nil, fmt.Errorf("cannot merge IPO %s into itself", target.ID)
+	}
+
+	result := &models.IPOMergeResult{
+		SourceID:      source.ID,
+		TargetID:      target.ID,
+		SourceStockID: source.StockID,
+		TargetStockID: target.StockID,
+		MergedBy:      merge.mergedBy,
+		Reason:        merge.reason,
+		MovedRows:     make(map[string]int64),
+		GMP:           models.IPOMergeGMPNone,
+		MergedAt:      time.Now(),
+	}
+
+	assignments := make([]string, len(ipoMergeColumns))
+	for i, column := range ipoMergeColumns {
+		assignments[i] = fmt.Sprintf("%s = COALESCE(t.%s, s.%s)", column, column, column)
+	}
+
+	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
+		// Soft-delete the source first: a concurrent merge of the same source finds it
+		// already gone and fails instead of merging it twice
+		deleted, err := tx.ExecContext(ctx, `
+			UPDATE ipo_list SET deleted_at = $3, merged_into_ipo_id = $1, updated_at = $3
+			WHERE id = $2 AND deleted_at IS NULL
+		`, target.ID, source.ID, result.MergedAt)
+		if err != nil {
+			return fmt.Errorf("failed to soft-delete source IPO: %w", err)
+		}
+		if affected, err := deleted.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to soft-delete source IPO: %w", err)
+		} else if affected == 0 {
+			return shared.NotFoundErrorf("source IPO %s not found", source.ID)
+		}
+
+		before, err := loadIPOHistoryFields(ctx, tx, target.StockID)
+		if err != nil {
+			return err
+		}
+		updated, err := tx.ExecContext(ctx, `
+			UPDATE ipo_list t SET `+strings.Join(assignments, ", ")+`,
+				-- Fields copied from the source bring their lineage along
+				field_lineage = s.field_lineage || t.field_lineage,
+				updated_at = CURRENT_TIMESTAMP
+			FROM ipo_list s
+			WHERE t.id = $1 AND s.id = $2 AND t.deleted_at IS NULL
+		`, target.ID, source.ID)
+		if err != nil {
+			return fmt.Errorf("failed to merge IPO fields: %w", err)
+		}
+		if affected, err := updated.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to merge IPO fields: %w", err)
+		} else if affected == 0 {
+			return shared.NotFoundErrorf("target IPO %s not found", target.ID)
+		}
+		after, err := loadIPOHistoryFields(ctx, tx, target.StockID)
+		if err != nil {
+			return err
+		}
+		if err := logIPOFieldChanges(ctx, tx, target.ID, before, after, merge.source); err != nil {
+			return err
+		}
+		if err := recordMergedSlug(ctx, tx, target.ID, source.ID); err != nil {
+			return err
+		}
+
+		for _, table := range ipoChildTables {
+			moved, err := moveIPOChildRows(ctx, tx, table, target.ID.String(), source.ID.String())
+			if err != nil {
+				return err
+			}
+			if moved > 0 {
+				result.MovedRows[table.name] = moved
+			}
+		}
+		if result.GMP, err = mergeIPOGMP(ctx, tx, target, source, result.MovedRows); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_merges (duplicate_stock_id, duplicate_name, canonical_ipo_id, canonical_stock_id, reason, merged_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (duplicate_stock_id) DO UPDATE SET
+				canonical_ipo_id = EXCLUDED.canonical_ipo_id,
+				canonical_stock_id = EXCLUDED.canonical_stock_id,
+				reason = EXCLUDED.reason,
+				merged_by = EXCLUDED.merged_by,
+				merged_at = CURRENT_TIMESTAMP
+		`, source.StockID, source.Name, target.ID, target.StockID, merge.reason, merge.mergedBy); err != nil {
+			return fmt.Errorf("failed to record IPO merge: %w", err)
+		}
+		// The source's earlier log moved to the target above; it keeps this one entry
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_update_log (ipo_id, field_name, old_value, new_value, source, timestamp)
+			VALUES ($1, 'merged_duplicate', $2, $3, $4, $5),
+				($6, 'merged_into', $2, $3, $4, $5)
+		`, target.ID, source.StockID, target.StockID, merge.source, result.MergedAt, source.ID); err != nil {
+			return fmt.Errorf("failed to log IPO merge: %w", err)
+		}
+
+		if err := EnqueueOutboxEvent(ctx, tx, models.EventIPOUpdated, "ipo", target.ID.String(), ipoEventPayload(&target)); err != nil {
+			return err
+		}
+		if err := NotifyCacheInvalidation(ctx, tx, CacheInvalidation{IPOID: source.ID.String()}); err != nil {
+			return err
+		}
+		return NotifyCacheInvalidation(ctx, tx, CacheInvalidation{IPOID: target.ID.String()})
+	})
+
+	var errorMsg *string
+	if err != nil {
+		message := err.Error()
+		errorMsg = &message
+	}
+	s.auditLogger.LogIPOMerge(result, err == nil, errorMsg)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeIPOGMP hands the source's GMP data to the target. When only the source has a
+// GMP row, the row is re-linked to the target; when both have one, the source's history
+// joins the target's series and its row is dropped. History rows moved are counted
+// in moved.
+func mergeIPOGMP(ctx context.Context, tx *sql.Tx, target, source models.IPO, moved map[string]int64) (string, error) {
+	sourceGMP, err := findIPOGMPRow(ctx, tx, source)
+	if err != nil || sourceGMP == nil {
+		return models.IPOMergeGMPNone, err
+	}
+	targetGMP, err := findIPOGMPRow(ctx, tx, target)
+	if err != nil {
+		return "", err
+	}
+	if targetGMP != nil && targetGMP.id == sourceGMP.id {
+		return models.IPOMergeGMPNone, nil
+	}
+
+	if targetGMP == nil {
+		// The content hash covers the stock ID and company code, so the next scrape
+		// rewrites the row instead of skipping it
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE ipo_gmp SET stock_id = $2, company_code = $3, content_hash = NULL WHERE id = $1
+		`, sourceGMP.id, target.StockID, target.CompanyCode); err != nil {
+			return "", fmt.Errorf("failed to move GMP row: %w", err)
+		}
+		history, err := tx.ExecContext(ctx, `
+			UPDATE ipo_gmp_history SET company_code = $2 WHERE ipo_name = $1
+		`, sourceGMP.ipoName, target.CompanyCode)
+		if err != nil {
+			return "", fmt.Errorf("failed to move GMP history: %w", err)
+		}
+		countMovedRows(moved, "ipo_gmp_history", history)
+		return models.IPOMergeGMPMoved, nil
+	}
+
+	history, err := tx.ExecContext(ctx, `
+		UPDATE ipo_gmp_history SET ipo_name = $2, company_code = $3 WHERE ipo_name = $1
+	`, sourceGMP.ipoName, targetGMP.ipoName, target.CompanyCode)
+	if err != nil {
+		return "", fmt.Errorf("failed to move GMP history: %w", err)
+	}
+	countMovedRows(moved, "ipo_gmp_history", history)
+	edits, err := tx.ExecContext(ctx, `
+		UPDATE ipo_gmp_edit_log SET gmp_id = $2, company_code = $3 WHERE gmp_id = $1
+	`, sourceGMP.id, targetGMP.id, target.CompanyCode)
+	if err != nil {
+		return "", fmt.Errorf("failed to move GMP edit log: %w", err)
+	}
+	countMovedRows(moved, "ipo_gmp_edit_log", edits)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ipo_gmp WHERE id = $1`, sourceGMP.id); err != nil {
+		return "", fmt.Errorf("failed to drop source GMP row: %w", err)
+	}
+	return models.IPOMergeGMPHistoryMerged, nil
+}
+
+// findIPOGMPRow returns the GMP row linked to an IPO by stock ID, falling back to its
+// company code, or nil when there is none
+func findIPOGMPRow(ctx context.Context, tx *sql.Tx, ipo models.IPO) (*ipoGMPRow, error) {
+	var row ipoGMPRow
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, ipo_name FROM ipo_gmp
+		WHERE (stock_id = $1 AND $1 <> '') OR company_code = $2
+		ORDER BY CASE WHEN stock_id = $1 THEN 1 ELSE 2 END, last_updated DESC
+		LIMIT 1
+	`, ipo.StockID, ipo.CompanyCode).Scan(&row.id, &row.ipoName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find GMP row of IPO %s: %w", ipo.StockID, err)
+	}
+	return &row, nil
+}
+
+func countMovedRows(moved map[string]int64, table string, result sql.Result) {
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		moved[table] += affected
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// MaxScreenerResults caps how many IPOs one screener query returns
+const MaxScreenerResults = 200
+
+// ScreenIPOs returns up to MaxScreenerResults IPOs with the given list status, filtered
+// and ordered by options, with their issue size and fresh issue / OFS breakdown
+func (s *IPOService) ScreenIPOs(ctx context.Context, status string, options IPOListOptions) ([]models.IPOScreenerEntry, error) {
+	baseQuery := `SELECT id, name, company_code, symbol, slug, status, open_date, close_date, listing_date,
+		issue_size, issue_size_amount, listing_gain,
+		fresh_issue_shares, fresh_issue_amount, ofs_shares, ofs_amount, ofs_percent
+		FROM ipo_list`
+
+	query, args := options.apply(baseQuery, ipoStatusConditions(status))
+	query += fmt.Sprintf(" LIMIT %d", MaxScreenerResults)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO screener: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.IPOScreenerEntry{}
+	for rows.Next() {
+		var entry models.IPOScreenerEntry
+		if err := rows.Scan(&entry.IPOID, &entry.Name, &entry.CompanyCode, &entry.Symbol, &entry.Slug,
+			&entry.Status, &entry.OpenDate, &entry.CloseDate, &entry.ListingDate,
+			&entry.IssueSize, &entry.IssueSizeAmount, &entry.ListingGain,
+			&entry.FreshIssueShares, &entry.FreshIssueAmount, &entry.OFSShares, &entry.OFSAmount, &entry.OFSPercent); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO screener entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IPO screener: %w", err)
+	}
+	return entries, nil
+}
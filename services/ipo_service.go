@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
@@ -118,6 +117,32 @@ func (a *IPOAuditLogger) LogBatchOperation(operation string, totalCount, success
 	a.logAuditEntry(entry)
 }
 
+// LogIPOMerge logs an IPO folded into another, by an admin or the dedup pass, with the
+// rows that moved
+func (a *IPOAuditLogger) LogIPOMerge(result *models.IPOMergeResult, success bool, errorMsg *string) {
+	mergedBy := result.MergedBy
+	entry := AuditEntry{
+		Timestamp:   time.Now(),
+		ServiceName: a.serviceName,
+		Operation:   "MERGE",
+		EntityType:  "IPO",
+		EntityID:    result.TargetStockID,
+		UserID:      &mergedBy,
+		Success:     success,
+		ErrorMsg:    errorMsg,
+		Metadata: map[string]interface{}{
+			"source_id":       result.SourceID,
+			"source_stock_id": result.SourceStockID,
+			"target_id":       result.TargetID,
+			"reason":          result.Reason,
+			"moved_rows":      result.MovedRows,
+			"gmp":             result.GMP,
+		},
+	}
+
+	a.logAuditEntry(entry)
+}
+
 // calculateIPOChanges compares two IPO objects and returns the changes
 func (a *IPOAuditLogger) calculateIPOChanges(before, after *models.IPO) map[string]interface{} {
 	changes := make(map[string]interface{})
@@ -168,14 +193,14 @@ func (a *IPOAuditLogger) calculateIPOChanges(before, after *models.IPO) map[stri
 }
 
 // compareDates compares two time pointers
-func (a *IPOAuditLogger) compareDates(date1, date2 *time.Time) bool {
+func (a *IPOAuditLogger) compareDates(date1, date2 *models.Date) bool {
 	if date1 == nil && date2 == nil {
 		return true
 	}
 	if date1 == nil || date2 == nil {
 		return false
 	}
-	return date1.Equal(*date2)
+	return date1.Equal(date2.Time)
 }
 
 // compareStringPointers compares two string pointers
@@ -228,6 +253,8 @@ func (a *IPOAuditLogger) logAuditEntry(entry AuditEntry) {
 type IPOService struct {
 	DB             *sql.DB
 	UtilityService *UtilityService
+	// TextLimits are the description and about preview lengths of public responses
+	TextLimits     TextPreviewLimits
 	auditLogger    *IPOAuditLogger
 	dbOptimizer    *DatabaseOptimizer
 	serviceMetrics *shared.ServiceMetrics
@@ -239,20 +266,12 @@ type IPOService struct {
 type DatabaseOptimizer struct {
 	db             *sql.DB
 	connectionPool *shared.DatabaseConfig
-	retryConfig    *RetryConfig
+	retryPolicy    shared.RetryPolicy
 	queryOptimizer *QueryOptimizer
 }
 
 // Note: ConnectionPoolConfig is now replaced by shared.DatabaseConfig
 
-// RetryConfig holds retry configuration for database operations
-type RetryConfig struct {
-	MaxRetries    int
-	BaseDelay     time.Duration
-	MaxDelay      time.Duration
-	BackoffFactor float64
-}
-
 // QueryOptimizer provides query optimization features
 type QueryOptimizer struct {
 	enablePreparedStatements bool
@@ -267,12 +286,7 @@ func NewDatabaseOptimizer(db *sql.DB) *DatabaseOptimizer {
 	return &DatabaseOptimizer{
 		db:             db,
 		connectionPool: &config.Database,
-		retryConfig: &RetryConfig{
-			MaxRetries:    3,
-			BaseDelay:     100 * time.Millisecond,
-			MaxDelay:      2 * time.Second,
-			BackoffFactor: 2.0,
-		},
+		retryPolicy:    config.Retry.Database,
 		queryOptimizer: &QueryOptimizer{
 			enablePreparedStatements: true,
 			enableQueryLogging:       true,
@@ -296,33 +310,19 @@ func (opt *DatabaseOptimizer) ConfigureConnectionPool() {
 	}).Info("Database connection pool configured")
 }
 
-// ExecuteWithRetry executes a database operation with exponential backoff retry
-func (opt *DatabaseOptimizer) ExecuteWithRetry(ctx context.Context, operation func() error) error {
-	var lastErr error
-
-	for attempt := 0; attempt <= opt.retryConfig.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Calculate delay with exponential backoff
-			delay := time.Duration(float64(opt.retryConfig.BaseDelay) *
-				math.Pow(opt.retryConfig.BackoffFactor, float64(attempt-1)))
-
-			if delay > opt.retryConfig.MaxDelay {
-				delay = opt.retryConfig.MaxDelay
-			}
-
-			logrus.WithFields(logrus.Fields{
-				"attempt": attempt,
-				"delay":   delay,
-				"error":   lastErr,
-			}).Warn("Retrying database operation")
-
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(delay):
-			}
-		}
+// SetRetryPolicy replaces the retry policy used for database operations
+func (opt *DatabaseOptimizer) SetRetryPolicy(policy shared.RetryPolicy) {
+	if policy.Retryable == nil {
+		policy.Retryable = shared.IsRetryableDatabaseError
+	}
+	opt.retryPolicy = policy
+}
 
+// ExecuteWithRetry executes a database operation, retrying transient errors per the retry policy
+func (opt *DatabaseOptimizer) ExecuteWithRetry(ctx context.Context, operation func() error) error {
+	attempts := 0
+	err := opt.retryPolicy.Do(ctx, func(attempt int) error {
+		attempts = attempt
 		startTime := time.Now()
 		err := operation()
 		duration := time.Since(startTime)
@@ -335,62 +335,43 @@ func (opt *DatabaseOptimizer) ExecuteWithRetry(ctx context.Context, operation fu
 			}).Warn("Slow database query detected")
 		}
 
-		if err == nil {
-			if attempt > 0 {
-				logrus.WithFields(logrus.Fields{
-					"attempt":  attempt,
-					"duration": duration,
-				}).Info("Database operation succeeded after retry")
-			}
-			return nil
+		if err == nil && attempt > 1 {
+			logrus.WithFields(logrus.Fields{
+				"attempt":  attempt,
+				"duration": duration,
+			}).Info("Database operation succeeded after retry")
 		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !opt.isRetryableError(err) {
+		if err != nil && opt.retryPolicy.Retryable(err) && attempt < opt.retryPolicy.MaxAttempts {
 			logrus.WithFields(logrus.Fields{
-				"error": err,
-			}).Debug("Non-retryable database error")
-			return err
+				"attempt": attempt,
+				"error":   err,
+			}).Warn("Retrying database operation")
 		}
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+
+	if err == ctx.Err() || !opt.retryPolicy.Retryable(err) {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Debug("Non-retryable database error")
+		return err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"max_retries": opt.retryConfig.MaxRetries,
-		"final_error": lastErr,
+		"attempts":    attempts,
+		"final_error": err,
 	}).Error("Database operation failed after all retries")
 
-	return fmt.Errorf("database operation failed after %d retries: %w", opt.retryConfig.MaxRetries, lastErr)
+	return shared.CategorizeError(shared.ErrorCategoryDatabase,
+		fmt.Errorf("database operation failed after %d attempts: %w", attempts, err))
 }
 
-// isRetryableError determines if a database error is retryable
-func (opt *DatabaseOptimizer) isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-
-	// Common retryable database errors
-	retryableErrors := []string{
-		"connection refused",
-		"connection reset",
-		"timeout",
-		"temporary failure",
-		"deadlock",
-		"lock wait timeout",
-		"connection lost",
-		"server shutdown",
-	}
-
-	for _, retryableErr := range retryableErrors {
-		if strings.Contains(errStr, retryableErr) {
-			return true
-		}
-	}
-
-	return false
+// SetDatabaseRetryPolicy sets the retry policy used for the service's database queries
+func (s *IPOService) SetDatabaseRetryPolicy(policy shared.RetryPolicy) {
+	s.dbOptimizer.SetRetryPolicy(policy)
 }
 
 func NewIPOService(db *sql.DB) *IPOService {
@@ -403,6 +384,7 @@ func NewIPOService(db *sql.DB) *IPOService {
 	return &IPOService{
 		DB:             db,
 		UtilityService: utilityService,
+		TextLimits:     DefaultTextPreviewLimits(),
 		auditLogger:    NewIPOAuditLogger(),
 		dbOptimizer:    dbOptimizer,
 		serviceMetrics: shared.NewServiceMetrics("IPO_Service"),
@@ -412,12 +394,12 @@ func NewIPOService(db *sql.DB) *IPOService {
 }
 
 func (s *IPOService) recalculateStatus(ipo *models.IPO) {
-	ipo.Status = s.UtilityService.CalculateIPOStatus(ipo.OpenDate, ipo.CloseDate, ipo.ListingDate)
+	s.applyCloseTimes(ipo, shared.ClockOrDefault(s.UtilityService.Clock).Now())
 }
 
 // recalculateStatusWithGMP updates the status of an IPOWithGMP based on current time and dates
 func (s *IPOService) recalculateStatusWithGMP(ipo *models.IPOWithGMP) {
-	ipo.Status = s.UtilityService.CalculateIPOStatus(ipo.OpenDate, ipo.CloseDate, ipo.ListingDate)
+	s.recalculateStatus(&ipo.IPO)
 }
 
 // CalculateEnhancedIPOMetrics calculates enhanced metrics for IPO analysis
@@ -449,7 +431,7 @@ func (s *IPOService) CalculateEnhancedIPOMetrics(ipo *models.IPO) map[string]int
 
 	// Calculate timeline metrics
 	if ipo.OpenDate != nil && ipo.CloseDate != nil {
-		subscriptionDuration := ipo.CloseDate.Sub(*ipo.OpenDate)
+		subscriptionDuration := ipo.CloseDate.Sub(ipo.OpenDate.Time)
 		metrics["subscription_duration_days"] = subscriptionDuration.Hours() / 24
 
 		// Calculate days until open/close
@@ -471,7 +453,7 @@ func (s *IPOService) CalculateEnhancedIPOMetrics(ipo *models.IPO) map[string]int
 			daysUntilListing := ipo.ListingDate.Sub(now)
 			metrics["days_until_listing"] = daysUntilListing.Hours() / 24
 		} else {
-			daysSinceListing := now.Sub(*ipo.ListingDate)
+			daysSinceListing := now.Sub(ipo.ListingDate.Time)
 			metrics["days_since_listing"] = daysSinceListing.Hours() / 24
 		}
 	}
@@ -566,7 +548,7 @@ func (s *IPOService) CalculateRiskMetrics(ipo *models.IPO) map[string]interface{
 
 	// Timeline risk
 	if ipo.OpenDate != nil && ipo.CloseDate != nil {
-		subscriptionDuration := ipo.CloseDate.Sub(*ipo.OpenDate)
+		subscriptionDuration := ipo.CloseDate.Sub(ipo.OpenDate.Time)
 		subscriptionDays := subscriptionDuration.Hours() / 24
 
 		if subscriptionDays < 3 {
@@ -596,6 +578,25 @@ func (s *IPOService) CalculateRiskMetrics(ipo *models.IPO) map[string]interface{
 		riskFactors = append(riskFactors, "High minimum investment")
 	}
 
+	// Promoter holding risk: promoters keeping little of the company after the issue, or
+	// selling down sharply in it, have less at stake in its performance
+	if holding := ipo.PromoterHolding; holding != nil {
+		if holding.PostIssuePercent != nil {
+			riskMetrics["promoter_holding_post_issue_percent"] = *holding.PostIssuePercent
+			if *holding.PostIssuePercent < 50 {
+				riskScore += 1.0
+				riskFactors = append(riskFactors, "Low promoter holding after issue")
+			}
+		}
+		if dilution := promoterDilutionOf(holding); dilution != nil {
+			riskMetrics["promoter_dilution_points"] = *dilution
+			if *dilution >= 25 {
+				riskScore += 1.0
+				riskFactors = append(riskFactors, "High promoter dilution")
+			}
+		}
+	}
+
 	// Calculate overall risk level
 	var riskLevel string
 	if riskScore >= 5.0 {
@@ -615,14 +616,94 @@ func (s *IPOService) CalculateRiskMetrics(ipo *models.IPO) map[string]interface{
 	return riskMetrics
 }
 
+// IPO list sort orders
+const (
+	IPOSortCreated    = "created_at"
+	IPOSortIssueSize  = "issue_size"
+	IPOSortOFSPercent = "ofs_percent"
+)
+
+// IPOListOptions narrows and orders IPO list queries. Issue size bounds are in rupees
+// and compare against issue_size_amount; OFS bounds are percentages of the issue. IPOs
+// without the value are left out once a bound on it is set.
+type IPOListOptions struct {
+	Sort          string
+	Ascending     bool
+	MinIssueSize  *float64
+	MaxIssueSize  *float64
+	MinOFSPercent *float64
+	MaxOFSPercent *float64
+}
+
+// ipoNotDeleted skips IPOs soft-deleted by a merge
+const ipoNotDeleted = "deleted_at IS NULL"
+
+// apply adds the issue size and OFS bounds to conditions and returns baseQuery with the WHERE
+// and ORDER BY clauses and the placeholder arguments
+func (o IPOListOptions) apply(baseQuery string, conditions []string) (string, []interface{}) {
+	var args []interface{}
+	conditions = append([]string{ipoNotDeleted}, conditions...)
+	if o.MinIssueSize != nil {
+		args = append(args, *o.MinIssueSize)
+		conditions = append(conditions, fmt.Sprintf("issue_size_amount >= $%d", len(args)))
+	}
+	if o.MaxIssueSize != nil {
+		args = append(args, *o.MaxIssueSize)
+		conditions = append(conditions, fmt.Sprintf("issue_size_amount <= $%d", len(args)))
+	}
+	if o.MinOFSPercent != nil {
+		args = append(args, *o.MinOFSPercent)
+		conditions = append(conditions, fmt.Sprintf("ofs_percent >= $%d", len(args)))
+	}
+	if o.MaxOFSPercent != nil {
+		args = append(args, *o.MaxOFSPercent)
+		conditions = append(conditions, fmt.Sprintf("ofs_percent <= $%d", len(args)))
+	}
+
+	query := baseQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	direction := "DESC"
+	if o.Ascending {
+		direction = "ASC"
+	}
+	switch o.Sort {
+	case IPOSortIssueSize:
+		query += " ORDER BY issue_size_amount " + direction + " NULLS LAST, created_at DESC"
+	case IPOSortOFSPercent:
+		query += " ORDER BY ofs_percent " + direction + " NULLS LAST, created_at DESC"
+	default:
+		query += " ORDER BY created_at " + direction
+	}
+	return query, args
+}
+
+// ipoStatusConditions returns the WHERE conditions for a list status filter ("live",
+// "upcoming" or "closed"); "all" and unknown statuses filter nothing
+func ipoStatusConditions(status string) []string {
+	switch status {
+	case "live":
+		return []string{`status = 'LIVE'`}
+	case "upcoming":
+		return []string{`status = 'UPCOMING'`}
+	case "closed":
+		return []string{`(status = 'CLOSED' OR status = 'RESULT_OUT')`}
+	default:
+		return nil
+	}
+}
+
 // GetIPOsWithOptimizedQuery retrieves IPOs using optimized query patterns
 func (s *IPOService) GetIPOsWithOptimizedQuery(ctx context.Context, status string, limit, offset int) ([]models.IPO, error) {
 	// Use prepared statement for better performance
-	baseQuery := `SELECT id, name, company_code, description, price_band_low, price_band_high, 
-              issue_size, open_date, close_date, result_date, registrar, stock_id, 
+	baseQuery := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
               form_url, form_fields, form_headers, parser_config, status, subscription_status,
               symbol, slug, listing_date, listing_gain, min_qty, min_amount,
-              logo_url, about, strengths, risks, created_at, updated_at, created_by
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
               FROM ipo_list`
 
 	var query string
@@ -630,7 +711,7 @@ func (s *IPOService) GetIPOsWithOptimizedQuery(ctx context.Context, status strin
 	argIndex := 1
 
 	// Build WHERE clause dynamically
-	var conditions []string
+	conditions := []string{ipoNotDeleted}
 	if status != "" && status != "all" {
 		switch status {
 		case "live":
@@ -689,10 +770,11 @@ func (s *IPOService) GetIPOsWithOptimizedQuery(ctx context.Context, status strin
 		var formFields, formHeaders, parserConfig, strengths, risks []byte
 		err := rows.Scan(
 			&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-			&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+			&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 			&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 			&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 			&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+			&ipo.RetailCutoff, &ipo.HNICutoff,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan IPO row: %w", err)
@@ -717,15 +799,24 @@ func (s *IPOService) GetIPOsWithOptimizedQuery(ctx context.Context, status strin
 }
 
 func (s *IPOService) GetActiveIPOs(ctx context.Context) ([]models.IPO, error) {
+	return s.ListActiveIPOs(ctx, IPOListOptions{})
+}
+
+// ListActiveIPOs returns up to 100 live or result-out IPOs, filtered and ordered by options
+func (s *IPOService) ListActiveIPOs(ctx context.Context, options IPOListOptions) ([]models.IPO, error) {
 	// Optimized query with IN clause instead of OR - including all fields
-	query := `SELECT id, name, company_code, description, price_band_low, price_band_high, 
-              issue_size, open_date, close_date, result_date, registrar, stock_id, 
+	baseQuery := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
               form_url, form_fields, form_headers, parser_config, status, subscription_status,
               symbol, slug, listing_date, listing_gain, min_qty, min_amount,
-              logo_url, about, strengths, risks, created_at, updated_at, created_by
-              FROM ipo_list WHERE status IN ('LIVE', 'RESULT_OUT') ORDER BY created_at DESC LIMIT 100`
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+              FROM ipo_list`
 
-	rows, err := s.DB.QueryContext(ctx, query)
+	query, args := options.apply(baseQuery, []string{`status IN ('LIVE', 'RESULT_OUT')`})
+	query += ` LIMIT 100`
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active IPOs: %w", err)
 	}
@@ -737,10 +828,11 @@ func (s *IPOService) GetActiveIPOs(ctx context.Context) ([]models.IPO, error) {
 		var formFields, formHeaders, parserConfig, strengths, risks []byte
 		err := rows.Scan(
 			&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-			&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+			&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 			&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 			&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 			&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+			&ipo.RetailCutoff, &ipo.HNICutoff,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan IPO row: %w", err)
@@ -760,32 +852,21 @@ func (s *IPOService) GetActiveIPOs(ctx context.Context) ([]models.IPO, error) {
 }
 
 func (s *IPOService) GetIPOs(ctx context.Context, status string) ([]models.IPO, error) {
-	baseQuery := `SELECT id, name, company_code, description, price_band_low, price_band_high, 
-              issue_size, open_date, close_date, result_date, registrar, stock_id, 
+	return s.ListIPOs(ctx, status, IPOListOptions{})
+}
+
+// ListIPOs returns the IPOs with the given status ("live", "upcoming", "closed" or
+// "all"), filtered and ordered by options
+func (s *IPOService) ListIPOs(ctx context.Context, status string, options IPOListOptions) ([]models.IPO, error) {
+	baseQuery := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
               form_url, form_fields, form_headers, parser_config, status, subscription_status,
               symbol, slug, listing_date, listing_gain, min_qty, min_amount,
-              logo_url, about, strengths, risks, created_at, updated_at, created_by
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
               FROM ipo_list`
 
-	var query string
-	var args []interface{}
-
-	// Handle status filtering
-	switch status {
-	case "live":
-		query = baseQuery + ` WHERE status = 'LIVE'`
-	case "upcoming":
-		query = baseQuery + ` WHERE status = 'UPCOMING'`
-	case "closed":
-		query = baseQuery + ` WHERE status = 'CLOSED' OR status = 'RESULT_OUT'`
-	case "all", "":
-		query = baseQuery // No filter, return all
-	default:
-		// If an invalid status is provided, treat it as "all"
-		query = baseQuery
-	}
-
-	query += ` ORDER BY created_at DESC`
+	query, args := options.apply(baseQuery, ipoStatusConditions(status))
 
 	rows, err := s.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -799,10 +880,11 @@ func (s *IPOService) GetIPOs(ctx context.Context, status string) ([]models.IPO,
 		var formFields, formHeaders, parserConfig, strengths, risks []byte
 		err := rows.Scan(
 			&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-			&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+			&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 			&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 			&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 			&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+			&ipo.RetailCutoff, &ipo.HNICutoff,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan IPO row: %w", err)
@@ -821,23 +903,52 @@ func (s *IPOService) GetIPOs(ctx context.Context, status string) ([]models.IPO,
 	return ipos, nil
 }
 
+// CountIPOsByStatus counts IPOs by their current status, computed from dates as in GetIPOs
+func (s *IPOService) CountIPOsByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT open_date, close_date, listing_date, status,
+			to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+		FROM ipo_list WHERE deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO dates: %w", err)
+	}
+	defer rows.Close()
+
+	now := shared.ClockOrDefault(s.UtilityService.Clock).Now()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var openDate, closeDate, listingDate *models.Date
+		var stored string
+		var retailCutoff, hniCutoff *string
+		if err := rows.Scan(&openDate, &closeDate, &listingDate, &stored, &retailCutoff, &hniCutoff); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO dates: %w", err)
+		}
+		closesAt := biddingClosesAt(CloseTimes(closeDate, retailCutoff, hniCutoff))
+		counts[statusWithResults(s.UtilityService.CalculateIPOStatusClosingAt(openDate, closesAt, listingDate, now), stored)]++
+	}
+	return counts, rows.Err()
+}
+
 func (s *IPOService) GetIPOByID(ctx context.Context, id string) (*models.IPO, error) {
-	query := `SELECT id, name, company_code, description, price_band_low, price_band_high, 
-              issue_size, open_date, close_date, result_date, registrar, stock_id, 
+	query := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
               form_url, form_fields, form_headers, parser_config, status, subscription_status,
               symbol, slug, listing_date, listing_gain, min_qty, min_amount,
-              logo_url, about, strengths, risks, created_at, updated_at, created_by
-              FROM ipo_list WHERE id = $1`
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+              FROM ipo_list WHERE id = $1 AND deleted_at IS NULL`
 
 	row := s.DB.QueryRowContext(ctx, query, id)
 	var ipo models.IPO
 	var formFields, formHeaders, parserConfig, strengths, risks []byte
 	err := row.Scan(
 		&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-		&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+		&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 		&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 		&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 		&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+		&ipo.RetailCutoff, &ipo.HNICutoff,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -859,22 +970,64 @@ func (s *IPOService) GetIPOByID(ctx context.Context, id string) (*models.IPO, er
 
 // GetIPOByStockID returns an IPO by its stock ID
 func (s *IPOService) GetIPOByStockID(ctx context.Context, stockID string) (*models.IPO, error) {
-	query := `SELECT id, name, company_code, description, price_band_low, price_band_high, 
-              issue_size, open_date, close_date, result_date, registrar, stock_id, 
+	query := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
               form_url, form_fields, form_headers, parser_config, status, subscription_status,
               symbol, slug, listing_date, listing_gain, min_qty, min_amount,
-              logo_url, about, strengths, risks, created_at, updated_at, created_by
-              FROM ipo_list WHERE stock_id = $1`
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+              FROM ipo_list WHERE stock_id = $1 AND deleted_at IS NULL`
 
 	row := s.DB.QueryRowContext(ctx, query, stockID)
 	var ipo models.IPO
 	var formFields, formHeaders, parserConfig, strengths, risks []byte
 	err := row.Scan(
 		&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-		&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+		&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 		&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 		&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 		&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+		&ipo.RetailCutoff, &ipo.HNICutoff,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan IPO: %w", err)
+	}
+	ipo.FormFields = json.RawMessage(formFields)
+	ipo.FormHeaders = json.RawMessage(formHeaders)
+	ipo.ParserConfig = json.RawMessage(parserConfig)
+	ipo.Strengths = json.RawMessage(strengths)
+	ipo.Risks = json.RawMessage(risks)
+
+	// Recalculate status based on current time
+	s.recalculateStatus(&ipo)
+
+	return &ipo, nil
+}
+
+// GetIPOBySlug returns the most recently updated IPO with the given slug
+func (s *IPOService) GetIPOBySlug(ctx context.Context, slug string) (*models.IPO, error) {
+	query := `SELECT id, name, company_code, COALESCE(description_full, description), price_band_low, price_band_high, 
+              issue_size, issue_size_amount, open_date, close_date, result_date, registrar, stock_id, 
+              form_url, form_fields, form_headers, parser_config, status, subscription_status,
+              symbol, slug, listing_date, listing_gain, min_qty, min_amount,
+              logo_url, COALESCE(about_full, about), strengths, risks, created_at, updated_at, created_by,
+              to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+              FROM ipo_list WHERE slug = $1 AND deleted_at IS NULL
+              ORDER BY updated_at DESC LIMIT 1`
+
+	row := s.DB.QueryRowContext(ctx, query, slug)
+	var ipo models.IPO
+	var formFields, formHeaders, parserConfig, strengths, risks []byte
+	err := row.Scan(
+		&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
+		&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+		&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
+		&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
+		&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+		&ipo.RetailCutoff, &ipo.HNICutoff,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -903,17 +1056,45 @@ func (s *IPOService) CreateIPO(ctx context.Context, ipo *models.IPO) error {
 		slug := s.UtilityService.GenerateSlug(ipo.Name)
 		ipo.Slug = &slug
 	}
+	ipo.IssueSizeAmount = issueSizeAmountOf(ipo.IssueSize)
 
-	query := `INSERT INTO ipo_list (name, company_code, description, price_band_low, price_band_high, 
+	query := `INSERT INTO ipo_list (name, company_code, description_full, price_band_low, price_band_high, 
               issue_size, open_date, close_date, result_date, registrar, stock_id, 
-              form_url, form_fields, form_headers, parser_config, status, created_by) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id`
+              form_url, form_fields, form_headers, parser_config, status, created_by,
+              completeness_score, missing_fields, issue_size_amount, field_lineage) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING id`
 
-	err := s.DB.QueryRowContext(ctx, query,
-		ipo.Name, ipo.CompanyCode, ipo.Description, ipo.PriceBandLow, ipo.PriceBandHigh,
-		ipo.IssueSize, ipo.OpenDate, ipo.CloseDate, ipo.ResultDate, ipo.Registrar, ipo.StockID,
-		ipo.FormURL, ipo.FormFields, ipo.FormHeaders, ipo.ParserConfig, ipo.Status, ipo.CreatedBy,
-	).Scan(&ipo.ID)
+	completenessScore, missingFields, err := completenessColumns(ipo)
+	if err != nil {
+		return fmt.Errorf("failed to create IPO: %w", err)
+	}
+	// Every field of an IPO created through the API was entered by an admin
+	ipo.Lineage = nil
+	tagLineage(ipo, models.LineageSourceAdmin, time.Now())
+	lineage, err := lineageColumn(ipo)
+	if err != nil {
+		return fmt.Errorf("failed to create IPO: %w", err)
+	}
+
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query,
+			ipo.Name, ipo.CompanyCode, ipo.Description, ipo.PriceBandLow, ipo.PriceBandHigh,
+			ipo.IssueSize, ipo.OpenDate, ipo.CloseDate, ipo.ResultDate, ipo.Registrar, ipo.StockID,
+			ipo.FormURL, ipo.FormFields, ipo.FormHeaders, ipo.ParserConfig, ipo.Status, ipo.CreatedBy,
+			completenessScore, missingFields, ipo.IssueSizeAmount, lineage,
+		).Scan(&ipo.ID); err != nil {
+			return err
+		}
+		if priceBandChanged(nil, ipo) {
+			if err := savePriceRevision(ctx, tx, ipo.ID, nil, ipo); err != nil {
+				return err
+			}
+		}
+		if err := EnqueueOutboxEvent(ctx, tx, models.EventIPOCreated, "ipo", ipo.ID.String(), ipoEventPayload(ipo)); err != nil {
+			return err
+		}
+		return NotifyCacheInvalidation(ctx, tx, CacheInvalidation{IPOID: ipo.ID.String()})
+	})
 
 	// Log audit entry for creation attempt
 	var errorMsg *string
@@ -936,7 +1117,43 @@ func (s *IPOService) CreateIPO(ctx context.Context, ipo *models.IPO) error {
 	return nil
 }
 
+// withTransaction runs fn inside a database transaction, committing on success
+func (s *IPOService) withTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ipoEventPayload builds the outbox payload for IPO events
+func ipoEventPayload(ipo *models.IPO) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           ipo.ID,
+		"stock_id":     ipo.StockID,
+		"name":         ipo.Name,
+		"company_code": ipo.CompanyCode,
+		"status":       ipo.Status,
+		"open_date":    ipo.OpenDate,
+		"close_date":   ipo.CloseDate,
+		"result_date":  ipo.ResultDate,
+		"listing_date": ipo.ListingDate,
+	}
+}
+
 func (s *IPOService) UpsertIPO(ctx context.Context, item models.IPO) error {
+	_, err := s.UpsertIPOWithOutcome(ctx, item)
+	return err
+}
+
+// UpsertIPOWithOutcome upserts an IPO and reports whether the row was created, updated
+// or left unchanged in its audited fields, for scrape run diff counts
+func (s *IPOService) UpsertIPOWithOutcome(ctx context.Context, item models.IPO) (string, error) {
 	// Get existing IPO for audit comparison if it exists
 	var existingIPO *models.IPO
 	if existing, err := s.GetIPOByStockID(ctx, item.StockID); err == nil && existing != nil {
@@ -951,32 +1168,43 @@ func (s *IPOService) UpsertIPO(ctx context.Context, item models.IPO) error {
 		slug := s.UtilityService.GenerateSlug(item.Name)
 		item.Slug = &slug
 	}
+	item.IssueSizeAmount = issueSizeAmountOf(item.IssueSize)
+	if item.IssueStructure != nil {
+		item.IssueStructure.OFSPercent = ofsPercentOf(item.IssueStructure)
+	}
 
 	query := `
 		INSERT INTO ipo_list (
 			name, company_code, symbol, slug, 
-			description, price_band_low, price_band_high, issue_size,
+			description_full, price_band_low, price_band_high, issue_size,
 			open_date, close_date, listing_date, result_date,
 			listing_gain, min_qty, min_amount,
-			logo_url, about, strengths, risks,
-			status, registrar, stock_id, form_url, form_fields, parser_config
+			logo_url, about_full, strengths, risks,
+			status, registrar, stock_id, form_url, form_fields, parser_config,
+			completeness_score, missing_fields, faq, timetable, issue_size_amount,
+			fresh_issue_shares, fresh_issue_amount, ofs_shares, ofs_amount, ofs_percent,
+			promoter_holding_pre, promoter_holding_post, field_lineage
 		) VALUES (
 			$1, $2, $3, $4, 
 			$5, $6, $7, $8,
 			$9, $10, $11, $12,
 			$13, $14, $15,
 			$16, $17, $18, $19,
-			$20, $21, $22, '', '{}', '{}'
+			$20, $21, $22, '', '{}', '{}',
+			$23, $24, $25, $26, $27,
+			$28, $29, $30, $31, $32,
+			$33, $34, $35
 		)
 		ON CONFLICT (stock_id) DO UPDATE SET
 			name = EXCLUDED.name,
 			company_code = EXCLUDED.company_code,
 			symbol = EXCLUDED.symbol,
 			slug = EXCLUDED.slug,
-			description = EXCLUDED.description,
+			description_full = EXCLUDED.description_full,
 			price_band_low = EXCLUDED.price_band_low,
 			price_band_high = EXCLUDED.price_band_high,
 			issue_size = EXCLUDED.issue_size,
+			issue_size_amount = EXCLUDED.issue_size_amount,
 			open_date = EXCLUDED.open_date,
 			close_date = EXCLUDED.close_date,
 			listing_date = EXCLUDED.listing_date,
@@ -985,12 +1213,33 @@ func (s *IPOService) UpsertIPO(ctx context.Context, item models.IPO) error {
 			min_qty = EXCLUDED.min_qty,
 			min_amount = EXCLUDED.min_amount,
 			logo_url = EXCLUDED.logo_url,
-			about = EXCLUDED.about,
-			strengths = EXCLUDED.strengths,
-			risks = EXCLUDED.risks,
-			status = EXCLUDED.status,
+			about_full = EXCLUDED.about_full,
+			-- Texts stored before full text had its own column are superseded
+			description = NULL,
+			about = NULL,
+			-- A scrape that found no lists keeps the stored ones
+			strengths = CASE WHEN jsonb_array_length(EXCLUDED.strengths) > 0 THEN EXCLUDED.strengths ELSE ipo_list.strengths END,
+			risks = CASE WHEN jsonb_array_length(EXCLUDED.risks) > 0 THEN EXCLUDED.risks ELSE ipo_list.risks END,
+			-- Released results keep the IPO RESULT_OUT until it lists
+			status = CASE WHEN ipo_list.results_released_at IS NOT NULL AND EXCLUDED.status = 'CLOSED'
+				THEN ipo_list.status ELSE EXCLUDED.status END,
 			registrar = EXCLUDED.registrar,
-			updated_at = CURRENT_TIMESTAMP;
+			completeness_score = EXCLUDED.completeness_score,
+			missing_fields = EXCLUDED.missing_fields,
+			faq = COALESCE(EXCLUDED.faq, ipo_list.faq),
+			timetable = COALESCE(EXCLUDED.timetable, ipo_list.timetable),
+			-- The breakdown is replaced as a whole; a scrape that found none keeps the stored one
+			fresh_issue_shares = CASE WHEN EXCLUDED.ofs_percent IS NULL THEN ipo_list.fresh_issue_shares ELSE EXCLUDED.fresh_issue_shares END,
+			fresh_issue_amount = CASE WHEN EXCLUDED.ofs_percent IS NULL THEN ipo_list.fresh_issue_amount ELSE EXCLUDED.fresh_issue_amount END,
+			ofs_shares = CASE WHEN EXCLUDED.ofs_percent IS NULL THEN ipo_list.ofs_shares ELSE EXCLUDED.ofs_shares END,
+			ofs_amount = CASE WHEN EXCLUDED.ofs_percent IS NULL THEN ipo_list.ofs_amount ELSE EXCLUDED.ofs_amount END,
+			ofs_percent = COALESCE(EXCLUDED.ofs_percent, ipo_list.ofs_percent),
+			promoter_holding_pre = COALESCE(EXCLUDED.promoter_holding_pre, ipo_list.promoter_holding_pre),
+			promoter_holding_post = COALESCE(EXCLUDED.promoter_holding_post, ipo_list.promoter_holding_post),
+			-- Fields this scrape did not find keep their earlier lineage
+			field_lineage = ipo_list.field_lineage || EXCLUDED.field_lineage,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, (xmax = 0) AS inserted
 	`
 
 	// Ensure JSON fields are valid
@@ -1012,14 +1261,89 @@ func (s *IPOService) UpsertIPO(ctx context.Context, item models.IPO) error {
 		registrar = "Unknown"
 	}
 
-	_, err := s.DB.ExecContext(ctx, query,
-		item.Name, item.CompanyCode, item.Symbol, item.Slug,
-		item.Description, item.PriceBandLow, item.PriceBandHigh, item.IssueSize,
-		item.OpenDate, item.CloseDate, item.ListingDate, item.ResultDate,
-		item.ListingGain, item.MinQty, item.MinAmount,
-		item.LogoURL, item.About, item.Strengths, item.Risks,
-		status, registrar, item.StockID,
-	)
+	completenessScore, missingFields, err := completenessColumns(&item)
+	if err != nil {
+		return "", err
+	}
+	faq, timetable, err := contentColumns(&item)
+	if err != nil {
+		return "", err
+	}
+	freshShares, freshAmount, ofsShares, ofsAmount, ofsPercent := issueStructureColumns(&item)
+	promoterPre, promoterPost := promoterHoldingColumns(&item)
+	lineage, err := lineageColumn(&item)
+	if err != nil {
+		return "", err
+	}
+
+	// Write the IPO and its outbox event in one transaction so events are never lost
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		// The stored fields before the write, for the field change log
+		before, err := loadIPOHistoryFields(ctx, tx, item.StockID)
+		if err != nil {
+			return err
+		}
+
+		var inserted bool
+		if err := tx.QueryRowContext(ctx, query,
+			item.Name, item.CompanyCode, item.Symbol, item.Slug,
+			item.Description, item.PriceBandLow, item.PriceBandHigh, item.IssueSize,
+			item.OpenDate, item.CloseDate, item.ListingDate, item.ResultDate,
+			item.ListingGain, item.MinQty, item.MinAmount,
+			item.LogoURL, item.About, item.Strengths, item.Risks,
+			status, registrar, item.StockID,
+			completenessScore, missingFields, faq, timetable, item.IssueSizeAmount,
+			freshShares, freshAmount, ofsShares, ofsAmount, ofsPercent,
+			promoterPre, promoterPost, lineage,
+		).Scan(&item.ID, &inserted); err != nil {
+			return err
+		}
+
+		anchorChanged := false
+		if item.AnchorAllocation != nil {
+			if anchorChanged, err = saveAnchorAllocation(ctx, tx, item.ID, item.AnchorAllocation, item.ListingDate.TimePtr()); err != nil {
+				return err
+			}
+		}
+		if priceBandChanged(existingIPO, &item) {
+			if err := savePriceRevision(ctx, tx, item.ID, existingIPO, &item); err != nil {
+				return err
+			}
+		}
+		if existingIPO != nil {
+			if err := recordSlugChange(ctx, tx, item.ID, existingIPO.Slug, item.Slug); err != nil {
+				return err
+			}
+		}
+		// A new IPO always counts as changed; an update only when a field it logs changed
+		changed := true
+		if before != nil {
+			after, err := loadIPOHistoryFields(ctx, tx, item.StockID)
+			if err != nil {
+				return err
+			}
+			if err := logIPOFieldChanges(ctx, tx, item.ID, before, after, ipoChangeSource(&item)); err != nil {
+				return err
+			}
+			changed = ipoFieldsChanged(before, after)
+		}
+
+		// Rescrapes that change nothing send no ipo.updated event
+		if changed {
+			eventType := models.EventIPOUpdated
+			if inserted {
+				eventType = models.EventIPOCreated
+			}
+			if err := EnqueueOutboxEvent(ctx, tx, eventType, "ipo", item.ID.String(), ipoEventPayload(&item)); err != nil {
+				return err
+			}
+		}
+		if !changed && !anchorChanged {
+			return nil
+		}
+		// Every replica drops its cached copies of this IPO once the write commits
+		return NotifyCacheInvalidation(ctx, tx, CacheInvalidation{IPOID: item.ID.String()})
+	})
 
 	// Log audit entry for upsert operation
 	var errorMsg *string
@@ -1036,16 +1360,25 @@ func (s *IPOService) UpsertIPO(ctx context.Context, item models.IPO) error {
 		s.auditLogger.LogIPOCreation(&item, item.CreatedBy, err == nil, errorMsg)
 	}
 
-	// Log successful upsert
-	if err == nil {
-		logrus.WithFields(logrus.Fields{
-			"ipo_name":     item.Name,
-			"company_code": item.CompanyCode,
-			"stock_id":     item.StockID,
-		}).Info("IPO upserted successfully")
+	if err != nil {
+		return "", err
 	}
 
-	return err
+	// Log successful upsert
+	logrus.WithFields(logrus.Fields{
+		"ipo_name":     item.Name,
+		"company_code": item.CompanyCode,
+		"stock_id":     item.StockID,
+	}).Info("IPO upserted successfully")
+
+	switch {
+	case existingIPO == nil:
+		return models.UpsertCreated, nil
+	case len(s.auditLogger.calculateIPOChanges(existingIPO, &item)) == 0:
+		return models.UpsertUnchanged, nil
+	default:
+		return models.UpsertUpdated, nil
+	}
 }
 
 // GetActiveIPOsWithGMP returns all IPOs that have GMP data available, joined by company_code or name
@@ -1055,11 +1388,12 @@ func (s *IPOService) GetActiveIPOsWithGMP(ctx context.Context) ([]models.IPOWith
 	// Query to get all IPOs that have corresponding GMP data (INNER JOIN ensures only IPOs with GMP data)
 	query := `
 		SELECT 
-			i.id, i.name, i.company_code, i.description, i.price_band_low, i.price_band_high,
-			i.issue_size, i.open_date, i.close_date, i.result_date, i.registrar, i.stock_id,
+			i.id, i.name, i.company_code, COALESCE(i.description_full, i.description), i.price_band_low, i.price_band_high,
+			i.issue_size, i.issue_size_amount, i.open_date, i.close_date, i.result_date, i.registrar, i.stock_id,
 			i.form_url, i.form_fields, i.form_headers, i.parser_config, i.status, i.subscription_status,
 			i.symbol, i.slug, i.listing_date, i.listing_gain, i.min_qty, i.min_amount,
-			i.logo_url, i.about, i.strengths, i.risks, i.created_at, i.updated_at, i.created_by,
+			i.logo_url, COALESCE(i.about_full, i.about), i.strengths, i.risks, i.created_at, i.updated_at, i.created_by,
+			to_char(i.retail_cutoff, 'HH24:MI'), to_char(i.hni_cutoff, 'HH24:MI'),
 			g.gmp_value, g.gain_percent, g.estimated_listing, g.last_updated,
 			g.stock_id, g.subscription_status, g.listing_gain, g.ipo_status, 
 			g.data_source, g.extraction_metadata
@@ -1081,6 +1415,7 @@ func (s *IPOService) GetActiveIPOsWithGMP(ctx context.Context) ([]models.IPOWith
 			OR LOWER(SPLIT_PART(TRIM(i.name), ' ', 1) || ' ' || SPLIT_PART(TRIM(i.name), ' ', 2)) = 
 			   LOWER(SPLIT_PART(TRIM(g.ipo_name), ' ', 1) || ' ' || SPLIT_PART(TRIM(g.ipo_name), ' ', 2))
 		)
+		WHERE i.deleted_at IS NULL
 		ORDER BY 
 			-- Prioritize stock_id matches
 			CASE 
@@ -1113,10 +1448,11 @@ func (s *IPOService) GetActiveIPOsWithGMP(ctx context.Context) ([]models.IPOWith
 
 		err := rows.Scan(
 			&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-			&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+			&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 			&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 			&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 			&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+			&ipo.RetailCutoff, &ipo.HNICutoff,
 			&ipo.GMPValue, &ipo.GainPercent, &ipo.EstimatedListing, &ipo.GMPLastUpdated,
 			&ipo.GMPStockID, &ipo.GMPSubscriptionStatus, &ipo.GMPListingGain, &ipo.GMPIPOStatus,
 			&ipo.GMPDataSource, &extractionMetadataBytes,
@@ -1157,11 +1493,12 @@ func (s *IPOService) GetActiveIPOsWithGMP(ctx context.Context) ([]models.IPOWith
 func (s *IPOService) GetIPOByIDWithGMP(ctx context.Context, id string) (*models.IPOWithGMP, error) {
 	query := `
 		SELECT 
-			i.id, i.name, i.company_code, i.description, i.price_band_low, i.price_band_high,
-			i.issue_size, i.open_date, i.close_date, i.result_date, i.registrar, i.stock_id,
+			i.id, i.name, i.company_code, COALESCE(i.description_full, i.description), i.price_band_low, i.price_band_high,
+			i.issue_size, i.issue_size_amount, i.open_date, i.close_date, i.result_date, i.registrar, i.stock_id,
 			i.form_url, i.form_fields, i.form_headers, i.parser_config, i.status, i.subscription_status,
 			i.symbol, i.slug, i.listing_date, i.listing_gain, i.min_qty, i.min_amount,
-			i.logo_url, i.about, i.strengths, i.risks, i.created_at, i.updated_at, i.created_by,
+			i.logo_url, COALESCE(i.about_full, i.about), i.strengths, i.risks, i.created_at, i.updated_at, i.created_by,
+			to_char(i.retail_cutoff, 'HH24:MI'), to_char(i.hni_cutoff, 'HH24:MI'),
 			g.gmp_value, g.gain_percent, g.estimated_listing, g.last_updated,
 			g.stock_id, g.subscription_status, g.listing_gain, g.ipo_status, 
 			g.data_source, g.extraction_metadata
@@ -1172,7 +1509,7 @@ func (s *IPOService) GetIPOByIDWithGMP(ctx context.Context, id string) (*models.
 			-- Fallback: company_code match
 			OR i.company_code = g.company_code
 		)
-		WHERE i.id = $1
+		WHERE i.id = $1 AND i.deleted_at IS NULL
 		ORDER BY 
 			-- Prioritize stock_id matches
 			CASE 
@@ -1191,10 +1528,11 @@ func (s *IPOService) GetIPOByIDWithGMP(ctx context.Context, id string) (*models.
 
 	err := row.Scan(
 		&ipo.ID, &ipo.Name, &ipo.CompanyCode, &ipo.Description, &ipo.PriceBandLow, &ipo.PriceBandHigh,
-		&ipo.IssueSize, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
+		&ipo.IssueSize, &ipo.IssueSizeAmount, &ipo.OpenDate, &ipo.CloseDate, &ipo.ResultDate, &ipo.Registrar, &ipo.StockID,
 		&ipo.FormURL, &formFields, &formHeaders, &parserConfig, &ipo.Status, &ipo.SubscriptionStatus,
 		&ipo.Symbol, &ipo.Slug, &ipo.ListingDate, &ipo.ListingGain, &ipo.MinQty, &ipo.MinAmount,
 		&ipo.LogoURL, &ipo.About, &strengths, &risks, &ipo.CreatedAt, &ipo.UpdatedAt, &ipo.CreatedBy,
+		&ipo.RetailCutoff, &ipo.HNICutoff,
 		&ipo.GMPValue, &ipo.GainPercent, &ipo.EstimatedListing, &ipo.GMPLastUpdated,
 		&ipo.GMPStockID, &ipo.GMPSubscriptionStatus, &ipo.GMPListingGain, &ipo.GMPIPOStatus,
 		&ipo.GMPDataSource, &extractionMetadataBytes,
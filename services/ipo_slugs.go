@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// recordSlugChange keeps an IPO's replaced slug in ipo_slug_history so links to it can be
+// redirected. A slug that becomes current again leaves the history.
+func recordSlugChange(ctx context.Context, tx *sql.Tx, ipoID uuid.UUID, previous, current *string) error {
+	if previous == nil || *previous == "" || current == nil || *previous == *current {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO ipo_slug_history (slug, ipo_id) VALUES ($1, $2)
+		ON CONFLICT (slug) DO UPDATE SET ipo_id = EXCLUDED.ipo_id, replaced_at = CURRENT_TIMESTAMP
+	`, *previous, ipoID); err != nil {
+		return fmt.Errorf("failed to record slug change: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ipo_slug_history WHERE slug = $1`, *current); err != nil {
+		return fmt.Errorf("failed to clear current slug from history: %w", err)
+	}
+	return nil
+}
+
+// recordMergedSlug redirects a merged duplicate's slug to the canonical IPO
+func recordMergedSlug(ctx context.Context, tx *sql.Tx, canonicalID, duplicateID uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO ipo_slug_history (slug, ipo_id)
+		SELECT d.slug, c.id FROM ipo_list c, ipo_list d
+		WHERE c.id = $1 AND d.id = $2 AND COALESCE(d.slug, '') <> '' AND d.slug IS DISTINCT FROM c.slug
+		ON CONFLICT (slug) DO UPDATE SET ipo_id = EXCLUDED.ipo_id, replaced_at = CURRENT_TIMESTAMP
+	`, canonicalID, duplicateID); err != nil {
+		return fmt.Errorf("failed to record merged slug: %w", err)
+	}
+	return nil
+}
+
+// ResolveSlugRedirect returns where a replaced slug now points, or nil when the slug was
+// never replaced
+func (s *IPOService) ResolveSlugRedirect(ctx context.Context, slug string) (*models.SlugRedirect, error) {
+	redirect := models.SlugRedirect{Slug: slug}
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT l.id, l.slug
+		FROM ipo_slug_history h
+		JOIN ipo_list l ON l.id = h.ipo_id
+		WHERE h.slug = $1 AND COALESCE(l.slug, '') <> ''
+	`, slug).Scan(&redirect.IPOID, &redirect.CanonicalSlug)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve slug redirect: %w", err)
+	}
+	return &redirect, nil
+}
+
+// getPreviousSlugs returns the slugs an IPO had before, most recently replaced first
+func (s *IPOService) getPreviousSlugs(ctx context.Context, ipoID uuid.UUID) ([]string, error) {
+	var slugs []string
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(slug ORDER BY replaced_at DESC), '{}')
+		FROM ipo_slug_history WHERE ipo_id = $1
+	`, ipoID).Scan(pq.Array(&slugs)); err != nil {
+		return nil, fmt.Errorf("failed to load previous slugs: %w", err)
+	}
+	return slugs, nil
+}
+
+// BackfillSlugs generates a slug for IPOs stored without one. Returns how many IPOs
+// were filled.
+func (s *IPOService) BackfillSlugs(ctx context.Context) (int, error) {
+	rows, err := s.DB.QueryContext(ctx, `SELECT id, name FROM ipo_list WHERE COALESCE(slug, '') = '' AND deleted_at IS NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query IPOs without slug: %w", err)
+	}
+
+	slugs := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan IPO without slug: %w", err)
+		}
+		if slug := s.UtilityService.GenerateSlug(name); slug != "" {
+			slugs[id] = slug
+		} else {
+			logrus.WithFields(logrus.Fields{"ipo_id": id, "name": name}).Debug("No slug could be generated, leaving it empty")
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read IPOs without slug: %w", err)
+	}
+
+	filled := 0
+	for id, slug := range slugs {
+		if _, err := s.DB.ExecContext(ctx, `
+			UPDATE ipo_list SET slug = $2 WHERE id = $1 AND COALESCE(slug, '') = ''
+		`, id, slug); err != nil {
+			return filled, fmt.Errorf("failed to backfill slug of IPO %s: %w", id, err)
+		}
+		filled++
+	}
+	return filled, nil
+}
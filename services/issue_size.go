@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// issueSizeUnits maps the unit words used in issue sizes to their value in rupees
+var issueSizeUnits = map[string]float64{
+	"cr":       1e7,
+	"crore":    1e7,
+	"crores":   1e7,
+	"lakh":     1e5,
+	"lakhs":    1e5,
+	"lac":      1e5,
+	"lacs":     1e5,
+	"mn":       1e6,
+	"million":  1e6,
+	"millions": 1e6,
+	"bn":       1e9,
+	"billion":  1e9,
+	"billions": 1e9,
+}
+
+// issueSizeAmountPattern finds an amount with a unit ("₹1,120.50 Cr", "Rs. 50 lakh")
+var issueSizeAmountPattern = regexp.MustCompile(`(?i)([0-9][0-9,]*(?:\.[0-9]+)?)\s*(crores?|cr|lakhs?|lacs?|millions?|mn|billions?|bn)\b`)
+
+// issueSizeRupeesPattern finds an amount in plain rupees ("₹ 25,00,00,000")
+var issueSizeRupeesPattern = regexp.MustCompile(`(?i)(?:₹|\brs\.?|\binr)\s*([0-9][0-9,]*(?:\.[0-9]+)?)`)
+
+// ParseIssueSizeAmount converts a free-text issue size into rupees. The first amount
+// with a unit (crore, lakh, million, billion) wins, so share counts such as
+// "2,00,00,000 shares (aggregating up to ₹1,500 Cr)" are skipped. Without a unit, an
+// amount marked with ₹, Rs or INR is read as rupees. Anything else returns nil.
+func ParseIssueSizeAmount(text string) *float64 {
+	if amount := parseIssueSizeUnitAmount(text); amount != nil {
+		return amount
+	}
+	if match := issueSizeRupeesPattern.FindStringSubmatch(text); match != nil {
+		value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+		if err == nil && value > 0 {
+			amount := roundTo(value, 2)
+			return &amount
+		}
+	}
+	return nil
+}
+
+// parseIssueSizeUnitAmount converts the first amount with a unit in text into rupees.
+// Plain ₹ amounts are ignored, since in issue breakdowns they are the face value.
+func parseIssueSizeUnitAmount(text string) *float64 {
+	match := issueSizeAmountPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil || value <= 0 {
+		return nil
+	}
+	amount := roundTo(value*issueSizeUnits[strings.ToLower(match[2])], 2)
+	return &amount
+}
+
+// issueSharesPattern finds a share count ("1,23,45,678 shares")
+var issueSharesPattern = regexp.MustCompile(`(?i)([0-9][0-9,]*)\s*(?:equity\s+)?shares\b`)
+
+// parseIssueShares returns the first share count in text
+func parseIssueShares(text string) *int64 {
+	match := issueSharesPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	shares, err := strconv.ParseInt(strings.ReplaceAll(match[1], ",", ""), 10, 64)
+	if err != nil || shares <= 0 {
+		return nil
+	}
+	return &shares
+}
+
+// ParseIssueComponent reads the share count and rupee amount of one row of an issue
+// breakdown, such as "50,00,000 shares (aggregating up to ₹500.00 Cr)"
+func ParseIssueComponent(text string) (*int64, *float64) {
+	return parseIssueShares(text), parseIssueSizeUnitAmount(text)
+}
+
+// ofsPercentOf returns the offer for sale's share of the issue, by amount when both
+// amounts are known and by shares when both counts are. Otherwise a missing component
+// counts as zero; nil when neither side can be measured.
+func ofsPercentOf(structure *models.IssueStructure) *float64 {
+	if structure == nil {
+		return nil
+	}
+	share := func(fresh, ofs *float64) *float64 {
+		if fresh == nil && ofs == nil {
+			return nil
+		}
+		var freshValue, ofsValue float64
+		if fresh != nil {
+			freshValue = *fresh
+		}
+		if ofs != nil {
+			ofsValue = *ofs
+		}
+		if freshValue+ofsValue <= 0 {
+			return nil
+		}
+		percent := roundTo(ofsValue*100/(freshValue+ofsValue), 2)
+		return &percent
+	}
+	freshShares, ofsShares := int64AsFloat(structure.FreshIssueShares), int64AsFloat(structure.OFSShares)
+	switch {
+	case structure.FreshIssueAmount != nil && structure.OFSAmount != nil:
+		return share(structure.FreshIssueAmount, structure.OFSAmount)
+	case freshShares != nil && ofsShares != nil:
+		return share(freshShares, ofsShares)
+	case structure.FreshIssueAmount != nil || structure.OFSAmount != nil:
+		return share(structure.FreshIssueAmount, structure.OFSAmount)
+	default:
+		return share(freshShares, ofsShares)
+	}
+}
+
+func int64AsFloat(value *int64) *float64 {
+	if value == nil {
+		return nil
+	}
+	converted := float64(*value)
+	return &converted
+}
+
+// issueSizeAmountOf derives the numeric issue size from the issue size text
+func issueSizeAmountOf(issueSize *string) *float64 {
+	if issueSize == nil {
+		return nil
+	}
+	return ParseIssueSizeAmount(*issueSize)
+}
+
+// BackfillIssueSizeAmounts parses issue_size_amount for rows that have an issue size but
+// no amount, such as rows written before the column existed. It returns how many rows
+// were filled; sizes the parser cannot read stay NULL.
+func (s *IPOService) BackfillIssueSizeAmounts(ctx context.Context) (int, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, issue_size FROM ipo_list
+		WHERE issue_size IS NOT NULL AND issue_size <> '' AND issue_size_amount IS NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query issue sizes: %w", err)
+	}
+
+	amounts := make(map[string]float64)
+	for rows.Next() {
+		var id, issueSize string
+		if err := rows.Scan(&id, &issueSize); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan issue size: %w", err)
+		}
+		if amount := ParseIssueSizeAmount(issueSize); amount != nil {
+			amounts[id] = *amount
+		} else {
+			logrus.WithFields(logrus.Fields{"ipo_id": id, "issue_size": issueSize}).Debug("Issue size not parseable, leaving amount empty")
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read issue sizes: %w", err)
+	}
+
+	filled := 0
+	for id, amount := range amounts {
+		if _, err := s.DB.ExecContext(ctx, `
+			UPDATE ipo_list SET issue_size_amount = $2 WHERE id = $1 AND issue_size_amount IS NULL
+		`, id, amount); err != nil {
+			return filled, fmt.Errorf("failed to backfill issue size of IPO %s: %w", id, err)
+		}
+		filled++
+	}
+	return filled, nil
+}
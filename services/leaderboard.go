@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// LeaderboardMetricListingGain ranks IPOs by their actual listing gain
+const LeaderboardMetricListingGain = "listing_gain"
+
+// Leaderboard sizes: how many IPOs the top and bottom lists each hold
+const (
+	DefaultLeaderboardSize = 10
+	MaxLeaderboardSize     = 50
+)
+
+// LeaderboardMetrics are the metrics IPOs can be ranked by
+var LeaderboardMetrics = []string{LeaderboardMetricListingGain}
+
+// LeaderboardService ranks the IPOs listed in a year by their listing gain. The listing
+// gain is the scraped listing_gain, or the gain of the pre-open auction price over the
+// upper price band when none was scraped. Rankings are computed once per IST day.
+type LeaderboardService struct {
+	IPOService *IPOService
+	// Clock decides the current year and when cached rankings expire
+	Clock shared.Clock
+
+	mutex    sync.Mutex
+	cache    map[string]leaderboardCacheEntry
+	location *time.Location
+}
+
+type leaderboardCacheEntry struct {
+	leaderboard models.Leaderboard
+	expiresAt   time.Time
+}
+
+func NewLeaderboardService(ipoService *IPOService) *LeaderboardService {
+	return &LeaderboardService{
+		IPOService: ipoService,
+		Clock:      shared.DefaultClock,
+		cache:      make(map[string]leaderboardCacheEntry),
+		location:   istLocation(),
+	}
+}
+
+// CurrentYear returns the current year in IST
+func (s *LeaderboardService) CurrentYear() int {
+	return shared.ClockOrDefault(s.Clock).Now().In(s.location).Year()
+}
+
+// GetLeaderboard returns the size best and worst IPOs listed in year by metric, worst
+// last in Top and worst first in Bottom. With fewer than twice size ranked IPOs the two
+// lists overlap.
+func (s *LeaderboardService) GetLeaderboard(ctx context.Context, year int, metric string, size int) (*models.Leaderboard, error) {
+	if metric != LeaderboardMetricListingGain {
+		return nil, fmt.Errorf("unknown leaderboard metric %q", metric)
+	}
+	now := shared.ClockOrDefault(s.Clock).Now()
+	key := fmt.Sprintf("%d:%s", year, metric)
+
+	s.mutex.Lock()
+	entry, found := s.cache[key]
+	s.mutex.Unlock()
+	if !found || !now.Before(entry.expiresAt) {
+		ranked, err := s.rankByListingGain(ctx, year, now)
+		if err != nil {
+			return nil, err
+		}
+		local := now.In(s.location)
+		entry = leaderboardCacheEntry{
+			leaderboard: models.Leaderboard{Year: year, Metric: metric, Count: len(ranked), Top: ranked, ComputedAt: now},
+			expiresAt:   time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, s.location),
+		}
+		s.mutex.Lock()
+		s.cache[key] = entry
+		s.mutex.Unlock()
+	}
+
+	ranked := entry.leaderboard.Top
+	leaderboard := entry.leaderboard
+	leaderboard.Top = ranked[:min(size, len(ranked))]
+	leaderboard.Bottom = make([]models.LeaderboardEntry, 0, min(size, len(ranked)))
+	for i := len(ranked) - 1; i >= 0 && len(leaderboard.Bottom) < size; i-- {
+		leaderboard.Bottom = append(leaderboard.Bottom, ranked[i])
+	}
+	return &leaderboard, nil
+}
+
+// rankByListingGain returns the IPOs listed in year up to now that have a listing gain,
+// best first
+func (s *LeaderboardService) rankByListingGain(ctx context.Context, year int, now time.Time) ([]models.LeaderboardEntry, error) {
+	rows, err := s.IPOService.DB.QueryContext(ctx, `
+		SELECT l.id, l.name, l.company_code, l.symbol, l.slug, l.listing_date,
+			l.price_band_low, l.price_band_high, l.issue_size, l.issue_size_amount,
+			l.subscription_status, l.listing_gain, p.price
+		FROM ipo_list l
+		LEFT JOIN ipo_preopen_prices p ON p.ipo_id = l.id
+		WHERE EXTRACT(YEAR FROM l.listing_date) = $1 AND l.listing_date <= $2::date AND l.deleted_at IS NULL
+	`, year, models.NewDate(now).String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query listed IPOs: %w", err)
+	}
+	defer rows.Close()
+
+	ranked := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		var listingGain *string
+		var preOpenPrice *float64
+		if err := rows.Scan(&entry.IPOID, &entry.Name, &entry.CompanyCode, &entry.Symbol, &entry.Slug,
+			&entry.ListingDate, &entry.PriceBandLow, &entry.PriceBandHigh, &entry.IssueSize,
+			&entry.IssueSizeAmount, &entry.SubscriptionStatus, &listingGain, &preOpenPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan listed IPO: %w", err)
+		}
+
+		var scrapedGain *float64
+		if listingGain != nil {
+			scrapedGain = s.IPOService.UtilityService.ExtractSignedPercentage(*listingGain)
+		}
+		switch {
+		case scrapedGain != nil:
+			entry.ListingGainPercent = *scrapedGain
+			entry.ListingGainSource = models.ListingGainSourceScraped
+		case preOpenPrice != nil && entry.PriceBandHigh != nil && *entry.PriceBandHigh > 0:
+			entry.ListingGainPercent = roundTo((*preOpenPrice-*entry.PriceBandHigh) / *entry.PriceBandHigh * 100, 2)
+			entry.ListingGainSource = models.ListingGainSourcePreOpen
+		default:
+			continue
+		}
+		ranked = append(ranked, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read listed IPOs: %w", err)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].ListingGainPercent != ranked[j].ListingGainPercent {
+			return ranked[i].ListingGainPercent > ranked[j].ListingGainPercent
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// MandateCutoff is the time of day (IST) on an IPO's close date by which investors must
+// accept the UPI mandate of their application
+const MandateCutoff = 17 * time.Hour
+
+// DefaultMandateReminderLead is how long before the mandate deadline reminders go out
+const DefaultMandateReminderLead = 3 * time.Hour
+
+// MandateDeadline returns the UPI mandate deadline of an IPO closing on closeDate, or nil
+// without a close date
+func MandateDeadline(closeDate *models.Date) *time.Time {
+	if closeDate == nil || closeDate.IsZero() {
+		return nil
+	}
+	deadline := closeDate.Add(MandateCutoff)
+	return &deadline
+}
+
+// MandateReminderService reminds investors to accept their UPI mandate before the
+// deadline on the close date. Once an IPO's reminder window opens, one
+// ipo.mandate_reminder outbox event is emitted for apps to push to their watchlist users,
+// and every Telegram chat subscribed with /remind gets a message.
+type MandateReminderService struct {
+	DB *sql.DB
+	// Telegram, when set, sends reminders to subscribed chats
+	Telegram *TelegramBot
+	// Lead is how long before the deadline reminders go out
+	Lead time.Duration
+	// Clock decides whether an IPO's reminder window is open
+	Clock shared.Clock
+
+	logger *logrus.Entry
+}
+
+func NewMandateReminderService(db *sql.DB) *MandateReminderService {
+	return &MandateReminderService{
+		DB:     db,
+		Lead:   DefaultMandateReminderLead,
+		Clock:  shared.DefaultClock,
+		logger: logrus.WithField("component", "mandate_reminder"),
+	}
+}
+
+// Subscribe asks for a mandate reminder for the IPO in a Telegram chat. Subscribing again
+// is a no-op.
+func (s *MandateReminderService) Subscribe(ctx context.Context, ipoID uuid.UUID, chatID int64) error {
+	if _, err := s.DB.ExecContext(ctx, `
+		INSERT INTO mandate_reminder_subscriptions (ipo_id, telegram_chat_id)
+		VALUES ($1, $2)
+		ON CONFLICT (ipo_id, telegram_chat_id) DO NOTHING
+	`, ipoID, chatID); err != nil {
+		return fmt.Errorf("failed to subscribe to mandate reminder: %w", err)
+	}
+	return nil
+}
+
+// mandateReminderIPO is an IPO whose mandate deadline is today
+type mandateReminderIPO struct {
+	id       uuid.UUID
+	name     string
+	deadline time.Time
+}
+
+// RunDue sends the reminders of IPOs whose deadline is within Lead and not yet passed.
+// Each IPO's outbox event and each chat's message are sent once. Returns how many
+// reminders were sent.
+func (s *MandateReminderService) RunDue(ctx context.Context) (int, error) {
+	now := shared.ClockOrDefault(s.Clock).Now()
+	ipos, err := s.closingToday(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, ipo := range ipos {
+		if now.Before(ipo.deadline.Add(-s.Lead)) || !now.Before(ipo.deadline) {
+			continue
+		}
+		emitted, err := s.emit(ctx, ipo)
+		if err != nil {
+			return sent, err
+		}
+		if emitted {
+			sent++
+		}
+		if s.Telegram == nil {
+			continue
+		}
+		chats, err := s.claimChats(ctx, ipo.id)
+		if err != nil {
+			return sent, err
+		}
+		for _, chatID := range chats {
+			s.Telegram.SendMandateReminder(ctx, chatID, ipo.name, ipo.deadline)
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// closingToday returns the IPOs closing on now's IST date with their mandate deadline
+func (s *MandateReminderService) closingToday(ctx context.Context, now time.Time) ([]mandateReminderIPO, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, name, close_date FROM ipo_list WHERE close_date::date = $1::date AND deleted_at IS NULL
+	`, models.NewDate(now).String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPOs closing today: %w", err)
+	}
+	defer rows.Close()
+
+	var ipos []mandateReminderIPO
+	for rows.Next() {
+		var ipo mandateReminderIPO
+		var closeDate models.Date
+		if err := rows.Scan(&ipo.id, &ipo.name, &closeDate); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO closing today: %w", err)
+		}
+		ipo.deadline = *MandateDeadline(&closeDate)
+		ipos = append(ipos, ipo)
+	}
+	return ipos, rows.Err()
+}
+
+// emit records the IPO's reminder and enqueues its outbox event in one transaction,
+// unless another run already did. Reports whether the event was enqueued.
+func (s *MandateReminderService) emit(ctx context.Context, ipo mandateReminderIPO) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO mandate_reminders (ipo_id, mandate_deadline) VALUES ($1, $2)
+		ON CONFLICT (ipo_id) DO NOTHING
+	`, ipo.id, ipo.deadline)
+	if err != nil {
+		return false, fmt.Errorf("failed to record mandate reminder: %w", err)
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		return false, nil
+	}
+	if err := EnqueueOutboxEvent(ctx, tx, models.EventMandateReminder, "ipo", ipo.id.String(), map[string]interface{}{
+		"ipo_id":           ipo.id,
+		"ipo_name":         ipo.name,
+		"mandate_deadline": ipo.deadline,
+	}); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit mandate reminder: %w", err)
+	}
+	s.logger.WithField("ipo", ipo.name).Info("Emitted UPI mandate reminder")
+	return true, nil
+}
+
+// claimChats marks the IPO's unreminded Telegram subscriptions as reminded and returns
+// their chats, so each chat is messaged by one replica only
+func (s *MandateReminderService) claimChats(ctx context.Context, ipoID uuid.UUID) ([]int64, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		UPDATE mandate_reminder_subscriptions
+		SET reminded_at = CURRENT_TIMESTAMP
+		WHERE ipo_id = $1 AND reminded_at IS NULL
+		RETURNING telegram_chat_id
+	`, ipoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim mandate reminder subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var chats []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, fmt.Errorf("failed to scan mandate reminder subscription: %w", err)
+		}
+		chats = append(chats, chatID)
+	}
+	return chats, rows.Err()
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// MaxManualCheckInstructionsLength caps the manual check instructions an admin can set
+const MaxManualCheckInstructionsLength = 2000
+
+// ValidateManualCheck trims a manual check and checks that it has an http(s) URL or
+// instructions, within the length limits
+func ValidateManualCheck(check *models.ManualCheck) error {
+	check.URL = strings.TrimSpace(check.URL)
+	check.Instructions = strings.TrimSpace(check.Instructions)
+	if check.URL == "" && check.Instructions == "" {
+		return shared.ValidationErrorf("manual_check_url or instructions is required")
+	}
+	if check.URL != "" {
+		parsed, err := url.Parse(check.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(check.URL) > 500 {
+			return shared.ValidationErrorf("manual_check_url must be an http(s) URL of at most 500 characters")
+		}
+	}
+	if len(check.Instructions) > MaxManualCheckInstructionsLength {
+		return shared.ValidationErrorf("instructions must be at most %d characters", MaxManualCheckInstructionsLength)
+	}
+	return nil
+}
+
+// SetManualCheck stores the IPO's manual check link and instructions; nil clears them.
+// Returns false when there is no such IPO.
+func (s *IPOService) SetManualCheck(ctx context.Context, ipoID uuid.UUID, check *models.ManualCheck) (bool, error) {
+	var checkURL, instructions *string
+	if check != nil {
+		if err := ValidateManualCheck(check); err != nil {
+			return false, err
+		}
+		if check.URL != "" {
+			checkURL = &check.URL
+		}
+		if check.Instructions != "" {
+			instructions = &check.Instructions
+		}
+	}
+
+	result, err := s.DB.ExecContext(ctx, `
+		UPDATE ipo_list SET manual_check_url = $2, manual_check_instructions = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, ipoID, checkURL, instructions)
+	if err != nil {
+		return false, fmt.Errorf("failed to save manual check: %w", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+// GetManualCheck returns the IPO's manual check, or nil when none is set
+func (s *IPOService) GetManualCheck(ctx context.Context, ipoID uuid.UUID) (*models.ManualCheck, error) {
+	var check models.ManualCheck
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(manual_check_url, ''), COALESCE(manual_check_instructions, '')
+		FROM ipo_list WHERE id = $1
+	`, ipoID).Scan(&check.URL, &check.Instructions)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manual check: %w", err)
+	}
+	if check.URL == "" && check.Instructions == "" {
+		return nil, nil
+	}
+	return &check, nil
+}
+
+// CheckerSupports reports whether the allotment checker can query the IPO's registrar,
+// which needs the registrar's form URL or a submit_url in the parser config
+func CheckerSupports(ipo *models.IPO) bool {
+	if ipo.FormURL != nil && strings.TrimSpace(*ipo.FormURL) != "" {
+		return true
+	}
+	var parserConfig struct {
+		SubmitURL string `json:"submit_url"`
+	}
+	return json.Unmarshal(ipo.ParserConfig, &parserConfig) == nil && strings.TrimSpace(parserConfig.SubmitURL) != ""
+}
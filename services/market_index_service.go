@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// Market index categories
+const (
+	MarketIndexMainboard = "mainboard"
+	MarketIndexSME       = "sme"
+)
+
+// MarketIndexSparklinePoints is the number of intraday values kept per index
+const MarketIndexSparklinePoints = 30
+
+// Trading hours (IST) during which index values move and are polled
+const (
+	MarketOpenTime  = 9*time.Hour + 15*time.Minute
+	MarketCloseTime = 15*time.Hour + 30*time.Minute
+)
+
+// MarketIndexDefinition describes an index served by /market/indices and where its
+// value is read from
+type MarketIndexDefinition struct {
+	ID       string
+	Name     string
+	Exchange string
+	Category string
+	Source   string // a key of MarketIndexService.Providers
+	Symbol   string // the index name or ticker known to the source
+}
+
+// DefaultMarketIndices is the catalog of indices served by /market/indices
+var DefaultMarketIndices = []MarketIndexDefinition{
+	{ID: "nifty50", Name: "NIFTY 50", Exchange: QuoteExchangeNSE, Category: MarketIndexMainboard, Source: "nse", Symbol: "NIFTY 50"},
+	{ID: "sensex", Name: "SENSEX", Exchange: QuoteExchangeBSE, Category: MarketIndexMainboard, Source: "yahoo", Symbol: "^BSESN"},
+	{ID: "banknifty", Name: "BANK NIFTY", Exchange: QuoteExchangeNSE, Category: MarketIndexMainboard, Source: "nse", Symbol: "NIFTY BANK"},
+	{ID: "niftymidcap", Name: "NIFTY MIDCAP 100", Exchange: QuoteExchangeNSE, Category: MarketIndexMainboard, Source: "nse", Symbol: "NIFTY MIDCAP 100"},
+	{ID: "niftysmallcap", Name: "NIFTY SMALLCAP 100", Exchange: QuoteExchangeNSE, Category: MarketIndexMainboard, Source: "nse", Symbol: "NIFTY SMALLCAP 100"},
+	{ID: "nifty_sme_emerge", Name: "NIFTY SME EMERGE", Exchange: QuoteExchangeNSE, Category: MarketIndexSME, Source: "nse", Symbol: "NIFTY SME EMERGE"},
+}
+
+// MarketIndexProvider reads the current value of indices by their source symbol.
+// Symbols the source does not know are left out of the result.
+type MarketIndexProvider interface {
+	FetchIndices(ctx context.Context, symbols []string) (map[string]models.MarketIndex, error)
+}
+
+// NSEIndexProvider reads all NSE indices from one call to NSE's allIndices API
+type NSEIndexProvider struct {
+	*NSESession
+}
+
+// NewNSEIndexProvider creates an NSE index provider
+func NewNSEIndexProvider() *NSEIndexProvider {
+	return &NSEIndexProvider{NSESession: NewNSESession()}
+}
+
+// nseAllIndicesResponse is the subset of NSE's allIndices response used for index values
+type nseAllIndicesResponse struct {
+	Data []struct {
+		Index         string  `json:"index"`
+		Last          float64 `json:"last"`
+		Variation     float64 `json:"variation"`
+		PercentChange float64 `json:"percentChange"`
+	} `json:"data"`
+}
+
+// FetchIndices returns the NSE indices named in symbols
+func (p *NSEIndexProvider) FetchIndices(ctx context.Context, symbols []string) (map[string]models.MarketIndex, error) {
+	var response nseAllIndicesResponse
+	if err := p.GetJSON(ctx, "/api/allIndices", "/market-data/live-market-indices", &response); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+	indices := make(map[string]models.MarketIndex)
+	for _, row := range response.Data {
+		name := strings.ToUpper(strings.TrimSpace(row.Index))
+		if !wanted[name] || row.Last <= 0 {
+			continue
+		}
+		indices[name] = models.MarketIndex{
+			Value:         row.Last,
+			Change:        roundTo(row.Variation, 2),
+			ChangePercent: roundTo(row.PercentChange, 2),
+		}
+	}
+	if len(indices) == 0 && len(symbols) > 0 {
+		return nil, shared.UpstreamChangedErrorf("NSE allIndices returned none of %d requested indices", len(symbols))
+	}
+	return indices, nil
+}
+
+// FetchIndices returns index values from Yahoo Finance tickers; tickers Yahoo does not
+// know are skipped
+func (p *YahooQuoteProvider) FetchIndices(ctx context.Context, symbols []string) (map[string]models.MarketIndex, error) {
+	indices := make(map[string]models.MarketIndex)
+	var lastErr error
+	for _, symbol := range symbols {
+		quote, err := p.FetchTicker(ctx, symbol)
+		if err != nil {
+			if shared.ErrorCategoryOf(err) != shared.ErrorCategoryNotFound {
+				lastErr = err
+			}
+			continue
+		}
+		index := models.MarketIndex{Value: quote.Price}
+		if quote.PreviousClose > 0 {
+			index.Change = roundTo(quote.Price-quote.PreviousClose, 2)
+			index.ChangePercent = roundTo(index.Change/quote.PreviousClose*100, 2)
+		}
+		indices[strings.ToUpper(symbol)] = index
+	}
+	if len(indices) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return indices, nil
+}
+
+// MarketIndexService keeps the latest value and a short intraday series of each index in
+// memory. Values come from Poll, which the market index job calls during trading hours,
+// so requests never wait on the exchanges once the first poll has completed.
+type MarketIndexService struct {
+	Definitions []MarketIndexDefinition
+	Providers   map[string]MarketIndexProvider
+
+	mutex     sync.RWMutex
+	latest    map[string]models.MarketIndex
+	series    map[string][]float64
+	seriesDay string
+	location  *time.Location
+	logger    *logrus.Entry
+}
+
+// NewMarketIndexService creates a market index service for DefaultMarketIndices
+func NewMarketIndexService(providers map[string]MarketIndexProvider) *MarketIndexService {
+	return &MarketIndexService{
+		Definitions: DefaultMarketIndices,
+		Providers:   providers,
+		latest:      make(map[string]models.MarketIndex),
+		series:      make(map[string][]float64),
+		location:    istLocation(),
+		logger:      logrus.WithField("component", "market_indices"),
+	}
+}
+
+// MarketOpen reports whether t falls in trading hours on a weekday
+func (s *MarketIndexService) MarketOpen(t time.Time) bool {
+	local := t.In(s.location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	sinceMidnight := local.Sub(time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location))
+	return sinceMidnight >= MarketOpenTime && sinceMidnight <= MarketCloseTime
+}
+
+// Poll fetches every index from its provider and appends the values to the intraday
+// series, returning the number of indices updated. Indices whose provider fails keep
+// their previous value; an error is returned only when no index could be read.
+func (s *MarketIndexService) Poll(ctx context.Context) (int, error) {
+	symbolsBySource := make(map[string][]string)
+	for _, definition := range s.Definitions {
+		symbolsBySource[definition.Source] = append(symbolsBySource[definition.Source], definition.Symbol)
+	}
+
+	fetched := make(map[string]map[string]models.MarketIndex)
+	var lastErr error
+	for source, symbols := range symbolsBySource {
+		provider, ok := s.Providers[source]
+		if !ok {
+			continue
+		}
+		values, err := provider.FetchIndices(ctx, symbols)
+		if err != nil {
+			shared.DefaultErrorCounter.Record("market_indices", err)
+			s.logger.WithError(err).WithField("source", source).Warn("Failed to fetch market indices")
+			lastErr = err
+			continue
+		}
+		fetched[source] = values
+	}
+
+	now := time.Now()
+	day := now.In(s.location).Format("2006-01-02")
+	updated := 0
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// The sparkline is an intraday series, so it starts over on each trading day
+	if day != s.seriesDay {
+		s.series = make(map[string][]float64)
+		s.seriesDay = day
+	}
+	for _, definition := range s.Definitions {
+		value, ok := fetched[definition.Source][strings.ToUpper(definition.Symbol)]
+		if !ok {
+			continue
+		}
+		value.ID = definition.ID
+		value.Name = definition.Name
+		value.Exchange = definition.Exchange
+		value.Category = definition.Category
+		value.IsPositive = value.Change >= 0
+		value.UpdatedAt = now
+		s.latest[definition.ID] = value
+
+		series := append(s.series[definition.ID], value.Value)
+		if len(series) > MarketIndexSparklinePoints {
+			series = series[len(series)-MarketIndexSparklinePoints:]
+		}
+		s.series[definition.ID] = series
+		updated++
+	}
+
+	if updated == 0 {
+		if lastErr != nil {
+			return 0, lastErr
+		}
+		return 0, fmt.Errorf("no market index provider returned values")
+	}
+	return updated, nil
+}
+
+// Get returns the indices with the given IDs, in that order, or every index when ids is
+// empty. Unknown IDs are a validation error; indices not yet polled are left out.
+func (s *MarketIndexService) Get(ctx context.Context, ids []string) ([]models.MarketIndex, error) {
+	known := make(map[string]bool, len(s.Definitions))
+	for _, definition := range s.Definitions {
+		known[definition.ID] = true
+	}
+	if len(ids) == 0 {
+		for _, definition := range s.Definitions {
+			ids = append(ids, definition.ID)
+		}
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return nil, shared.ValidationErrorf("unknown market index %q", id)
+		}
+	}
+
+	s.mutex.RLock()
+	empty := len(s.latest) == 0
+	s.mutex.RUnlock()
+	// Before the job's first poll completes, read the values inline
+	if empty {
+		if _, err := s.Poll(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	indices := make([]models.MarketIndex, 0, len(ids))
+	for _, id := range ids {
+		index, ok := s.latest[id]
+		if !ok {
+			continue
+		}
+		index.Sparkline = append([]float64{}, s.series[id]...)
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
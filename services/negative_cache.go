@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotFoundCacheTTL is how long a lookup that found no IPO is remembered, so repeated
+// requests for an unknown ID or slug skip the database. Invalidating an IPO drops the
+// entries that could hide it.
+const NotFoundCacheTTL = 30 * time.Second
+
+// notFoundSlugPrefix prefixes negative entries of slug lookups. A new IPO is invalidated
+// by ID only, so every slug miss is dropped when any IPO is invalidated.
+const notFoundSlugPrefix = "ipo_missing_slug:"
+
+// notDeclaredPrefix prefixes the marks of IPOs whose registrar has not declared results
+const notDeclaredPrefix = "result_not_declared:"
+
+// ipoNotFound is cached in place of an IPO for lookups that found none
+type ipoNotFound struct{}
+
+func notFoundIDKey(id string) string {
+	return "ipo_missing:" + id
+}
+
+// knownMissing reports whether key holds a cached miss
+func (cis *CachedIPOService) knownMissing(key string) bool {
+	cached, found := cis.cache.Get(key)
+	if !found {
+		return false
+	}
+	_, missing := cached.(ipoNotFound)
+	return missing
+}
+
+// rememberMissing caches a miss for key
+func (cis *CachedIPOService) rememberMissing(key string) {
+	cis.cache.SetWithTTL(key, ipoNotFound{}, NotFoundCacheTTL)
+}
+
+// NotDeclaredKey is the cache key marking that the IPO's registrar has not declared
+// results yet; publishing it as a prefix invalidation clears the mark on every replica
+func NotDeclaredKey(ipoID uuid.UUID) string {
+	return notDeclaredPrefix + ipoID.String()
+}
+
+// MarkResultsNotDeclared remembers for ttl that the registrar answered "results not
+// declared" for the IPO, so checks in the meantime skip the registrar
+func (cs *CacheService) MarkResultsNotDeclared(ipoID uuid.UUID, ttl time.Duration) {
+	cs.SetWithTTL(NotDeclaredKey(ipoID), true, ttl)
+}
+
+// ResultsKnownNotDeclared reports whether the IPO's results were recently found not declared
+func (cs *CacheService) ResultsKnownNotDeclared(ipoID uuid.UUID) bool {
+	_, found := cs.Get(NotDeclaredKey(ipoID))
+	return found
+}
+
+// ClearResultsNotDeclared drops the IPO's not-declared mark once its results are out
+func (cs *CacheService) ClearResultsNotDeclared(ipoID uuid.UUID) {
+	cs.Delete(NotDeclaredKey(ipoID))
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// nextFlightChunkPattern matches one React Server Components flight chunk pushed by the
+// Next.js app router: self.__next_f.push([1,"<JS string>"])
+var nextFlightChunkPattern = regexp.MustCompile(`self\.__next_f\.push\(\[\s*\d+\s*,\s*("(?:[^"\\]|\\.)*")\s*\]\)`)
+
+// maxPayloadUnescapeDepth bounds how many levels of string escaping are peeled off while
+// looking for a key; flight chunks embed JSON that is itself escaped inside a string
+const maxPayloadUnescapeDepth = 3
+
+// jsonKind is the JSON type a payload field must have
+type jsonKind string
+
+const (
+	jsonString jsonKind = "string"
+	jsonNumber jsonKind = "number"
+)
+
+// payloadField describes one field of an embedded payload object
+type payloadField struct {
+	name     string
+	kind     jsonKind
+	required bool
+}
+
+// ipoDataSchema is the expected shape of the ipoData object on IPO detail pages. Optional
+// fields may be absent or null, but a field present with another type means the upstream
+// format changed.
+var ipoDataSchema = []payloadField{
+	{"id", jsonNumber, true},
+	{"company_name", jsonString, true},
+	{"issue_open_date", jsonString, false},
+	{"issue_close_date", jsonString, false},
+	{"issue_price_lower", jsonNumber, false},
+	{"issue_price_upper", jsonNumber, false},
+	{"nse_symbol", jsonString, false},
+	{"registrar_name", jsonString, false},
+	{"timetable_listing_dt", jsonString, false},
+	{"timetable_boa_dt", jsonString, false},
+	{"market_lot_size", jsonNumber, false},
+	{"minimum_order_quantity", jsonNumber, false},
+	{"issue_size_in_amt", jsonString, false},
+	{"urlrewrite_folder_name", jsonString, false},
+	{"description", jsonString, false},
+	{"about", jsonString, false},
+}
+
+// ExtractNextPayloadValue finds the value of key in the data a Next.js page embeds: the
+// __NEXT_DATA__ script of the pages router, or the concatenated flight chunks of the app
+// router. Escaped payloads are decoded as JSON strings rather than by text replacement.
+// An array value yields its first element. When no payload or key is found the error is
+// in the upstream_changed category. document is the parsed body and may be nil.
+func ExtractNextPayloadValue(body string, document *goquery.Document, key string) (json.RawMessage, error) {
+	var payloads []string
+	if document != nil {
+		if script := document.Find("script#__NEXT_DATA__").First(); script.Length() > 0 {
+			payloads = append(payloads, script.Text())
+		}
+	}
+
+	var flight strings.Builder
+	for _, match := range nextFlightChunkPattern.FindAllStringSubmatch(body, -1) {
+		var chunk string
+		if err := json.Unmarshal([]byte(match[1]), &chunk); err != nil {
+			return nil, shared.ParseErrorf("failed to decode Next.js flight chunk: %v", err)
+		}
+		flight.WriteString(chunk)
+	}
+	if flight.Len() > 0 {
+		payloads = append(payloads, flight.String())
+	}
+
+	// Pages without a recognised payload wrapper are scanned as-is
+	payloads = append(payloads, body)
+
+	for _, payload := range payloads {
+		value, found, err := findPayloadKey(payload, key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return firstArrayElement(value)
+		}
+	}
+	return nil, shared.UpstreamChangedErrorf("no %q found in Next.js page payload", key)
+}
+
+// findPayloadKey decodes the JSON value following "key": in text, unescaping one string
+// level at a time when the key only appears escaped
+func findPayloadKey(text, key string) (json.RawMessage, bool, error) {
+	needle := `"` + key + `"`
+	for depth := 0; depth <= maxPayloadUnescapeDepth; depth++ {
+		if index := strings.Index(text, needle); index >= 0 {
+			rest := strings.TrimLeft(text[index+len(needle):], " \t\r\n")
+			if !strings.HasPrefix(rest, ":") {
+				return nil, false, shared.UpstreamChangedErrorf("%q is not followed by a value", key)
+			}
+			var value json.RawMessage
+			decoder := json.NewDecoder(strings.NewReader(rest[1:]))
+			if err := decoder.Decode(&value); err != nil {
+				return nil, false, shared.ParseErrorf("failed to decode %q payload: %v", key, err)
+			}
+			return value, true, nil
+		}
+		if !strings.Contains(text, `\"`+key+`\"`) {
+			return nil, false, nil
+		}
+		text = unescapeJSStringContent(text)
+	}
+	return nil, false, nil
+}
+
+// unescapeJSStringContent removes one level of JSON/JS string escaping from s. Unlike
+// chained ReplaceAll calls it handles \\" and \uXXXX sequences correctly.
+func unescapeJSStringContent(s string) string {
+	var out strings.Builder
+	out.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '"', '\\', '/', '\'':
+			out.WriteByte(s[i])
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if code, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					out.WriteString(escapeRuneForJSON(rune(code)))
+					i += 4
+					continue
+				}
+			}
+			out.WriteString(`\u`)
+		default:
+			out.WriteByte('\\')
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String()
+}
+
+// escapeRuneForJSON writes a decoded \u escape back into text that will be parsed as JSON:
+// quotes and control characters must stay escaped, and lone surrogates are kept as escapes
+func escapeRuneForJSON(r rune) string {
+	switch {
+	case r == '"' || r == '\\' || r < 0x20 || (r >= 0xD800 && r <= 0xDFFF):
+		return fmt.Sprintf(`\u%04x`, r)
+	case utf8.ValidRune(r):
+		return string(r)
+	default:
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+}
+
+// firstArrayElement returns value itself, or its first element when it is an array
+func firstArrayElement(value json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return trimmed, nil
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(trimmed, &elements); err != nil {
+		return nil, shared.ParseErrorf("failed to decode payload array: %v", err)
+	}
+	if len(elements) == 0 {
+		return nil, shared.UpstreamChangedErrorf("payload array is empty")
+	}
+	return elements[0], nil
+}
+
+// validatePayloadObject checks that raw is an object matching schema, reporting every
+// missing required field and type mismatch as one upstream_changed error
+func validatePayloadObject(raw json.RawMessage, schema []payloadField) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return shared.UpstreamChangedErrorf("payload is not a JSON object: %v", err)
+	}
+
+	var problems []string
+	for _, field := range schema {
+		value, present := object[field.name]
+		isNull := present && string(bytes.TrimSpace(value)) == "null"
+		if !present || isNull {
+			if field.required {
+				problems = append(problems, field.name+" missing")
+			}
+			continue
+		}
+		if kind := jsonKindOf(value); kind != field.kind {
+			problems = append(problems, fmt.Sprintf("%s is %s, want %s", field.name, kind, field.kind))
+		}
+	}
+	if len(problems) > 0 {
+		return shared.UpstreamChangedErrorf("payload shape changed: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// jsonKindOf names the JSON type of a raw value
+func jsonKindOf(value json.RawMessage) jsonKind {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 {
+		return "empty"
+	}
+	switch trimmed[0] {
+	case '"':
+		return jsonString
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		return jsonNumber
+	}
+}
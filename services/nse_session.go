@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// nseSessionTTL is how long NSE session cookies are reused before re-priming
+const nseSessionTTL = 5 * time.Minute
+
+// NSESession calls NSE's JSON APIs. They only answer requests carrying the cookies NSE
+// sets on its home page, so the session keeps its own cookie jar and primes it before
+// the first request and again after they expire.
+type NSESession struct {
+	BaseURL string
+	Client  *http.Client
+	Policy  shared.RetryPolicy
+
+	mutex  sync.Mutex
+	primed time.Time
+}
+
+// NewNSESession creates a session against www.nseindia.com
+func NewNSESession() *NSESession {
+	jar, _ := cookiejar.New(nil)
+	// The factory's clients are shared, so the cookie jar goes on a copy
+	base := shared.NewHTTPClientFactory(10 * time.Second).CreateOptimizedHTTPClient(10 * time.Second)
+	return &NSESession{
+		BaseURL: "https://www.nseindia.com",
+		Client:  &http.Client{Timeout: base.Timeout, Transport: base.Transport, Jar: jar},
+		Policy:  shared.DefaultHTTPRetryPolicy().WithMaxRetries(1),
+	}
+}
+
+// GetJSON decodes the JSON response of an API path into target; referer is the page
+// path a browser would have called the API from
+func (s *NSESession) GetJSON(ctx context.Context, path, referer string, target interface{}) error {
+	if err := s.prime(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create NSE request: %w", err)
+	}
+	shared.SetBrowserLikeHeaders(req, "application/json")
+	req.Header.Set("Referer", s.BaseURL+referer)
+
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(s.Client, req, s.Policy)
+	if err != nil {
+		var statusErr *shared.HTTPStatusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+			// Session cookies expired; re-prime on the next call
+			s.mutex.Lock()
+			s.primed = time.Time{}
+			s.mutex.Unlock()
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return shared.ParseErrorf("failed to decode NSE response: %v", err)
+	}
+	return nil
+}
+
+// prime loads NSE's home page to obtain session cookies when they are stale
+func (s *NSESession) prime(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if time.Since(s.primed) < nseSessionTTL {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.BaseURL+"/", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create NSE session request: %w", err)
+	}
+	shared.SetBrowserLikeHeaders(req, "text/html,application/xhtml+xml")
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(s.Client, req, s.Policy)
+	if err != nil {
+		return fmt.Errorf("failed to open NSE session: %w", err)
+	}
+	resp.Body.Close()
+	s.primed = time.Now()
+	return nil
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// EnqueueOutboxEvent writes an event to the outbox inside the caller's transaction,
+// so the event is persisted if and only if the data change commits
+func EnqueueOutboxEvent(ctx context.Context, tx *sql.Tx, eventType, aggregateType, aggregateID string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO event_outbox (event_type, aggregate_type, aggregate_id, payload)
+		VALUES ($1, $2, $3, $4)
+	`, eventType, aggregateType, aggregateID, payloadJSON)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue outbox event %s: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// EventNotifier delivers outbox events to an external destination
+type EventNotifier interface {
+	Name() string
+	Notify(ctx context.Context, event models.OutboxEvent) error
+}
+
+// WebhookNotifier posts outbox events as JSON to a webhook URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier for the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the notifier name used in logs and errors
+func (n *WebhookNotifier) Name() string {
+	return "webhook:" + n.URL
+}
+
+// Notify posts the event to the webhook and treats any non-2xx response as a failure
+func (n *WebhookNotifier) Notify(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.EventType)
+	req.Header.Set("X-Event-ID", event.ID.String())
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ParseWebhookNotifiers builds webhook notifiers from a comma-separated URL list
+func ParseWebhookNotifiers(urls string) []EventNotifier {
+	var notifiers []EventNotifier
+	for _, url := range strings.Split(urls, ",") {
+		url = strings.TrimSpace(url)
+		if url != "" {
+			notifiers = append(notifiers, NewWebhookNotifier(url))
+		}
+	}
+	return notifiers
+}
+
+// OutboxDispatcher delivers pending outbox events to notifiers and marks them done
+type OutboxDispatcher struct {
+	DB             *sql.DB
+	Notifiers      []EventNotifier
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetentionAfter time.Duration
+	// ClaimTimeout is how long claimed events are left to their dispatcher before other
+	// replicas may claim them again, in case it died mid-batch
+	ClaimTimeout time.Duration
+	logger       *logrus.Entry
+}
+
+// NewOutboxDispatcher creates a dispatcher with default polling and retry settings
+func NewOutboxDispatcher(db *sql.DB, notifiers []EventNotifier) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		DB:             db,
+		Notifiers:      notifiers,
+		PollInterval:   5 * time.Second,
+		BatchSize:      50,
+		MaxAttempts:    10,
+		RetentionAfter: 7 * 24 * time.Hour,
+		ClaimTimeout:   10 * time.Minute,
+		logger:         logrus.WithField("component", "outbox_dispatcher"),
+	}
+}
+
+// Start runs the dispatch loop until ctx is cancelled
+func (d *OutboxDispatcher) Start(ctx context.Context) {
+	d.logger.WithFields(logrus.Fields{
+		"notifiers":     len(d.Notifiers),
+		"poll_interval": d.PollInterval,
+	}).Info("Starting outbox dispatcher")
+
+	pollTicker := time.NewTicker(d.PollInterval)
+	purgeTicker := time.NewTicker(1 * time.Hour)
+
+	go func() {
+		defer pollTicker.Stop()
+		defer purgeTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				d.logger.Info("Outbox dispatcher stopped")
+				return
+			case <-pollTicker.C:
+				if _, err := d.DispatchPending(ctx); err != nil {
+					d.logger.WithError(err).Error("Outbox dispatch failed")
+				}
+			case <-purgeTicker.C:
+				if err := d.PurgeDelivered(ctx); err != nil {
+					d.logger.WithError(err).Warn("Failed to purge delivered outbox events")
+				}
+			}
+		}
+	}()
+}
+
+// DispatchPending claims one batch of due events, delivers them and returns how many
+// were delivered. Claiming moves the events' next attempt ClaimTimeout ahead and commits
+// straight away, so no transaction is held open while notifiers are called and other
+// replicas skip the claimed events.
+func (d *OutboxDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	if d.DB == nil {
+		return 0, nil
+	}
+
+	events, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		deliveredTo, deliverErr := d.deliver(ctx, event)
+		if deliverErr != nil {
+			if err := d.markFailed(ctx, event, deliveredTo, deliverErr); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if _, err := d.DB.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET status = 'DELIVERED', attempts = attempts + 1, delivered_at = CURRENT_TIMESTAMP,
+				last_error = NULL, delivered_to = $2
+			WHERE id = $1
+		`, event.ID, pq.Array(deliveredTo)); err != nil {
+			return delivered, fmt.Errorf("failed to mark outbox event delivered: %w", err)
+		}
+		delivered++
+	}
+
+	if len(events) > 0 {
+		d.logger.WithFields(logrus.Fields{
+			"fetched":   len(events),
+			"delivered": delivered,
+		}).Info("Outbox batch dispatched")
+	}
+
+	return delivered, nil
+}
+
+// claim takes up to BatchSize due events for this dispatcher, oldest first. Rows are
+// locked with SKIP LOCKED only for the claiming update, so several replicas can claim
+// concurrently; events of a dispatcher that dies mid-batch are retried once their claim
+// runs out.
+func (d *OutboxDispatcher) claim(ctx context.Context) ([]models.OutboxEvent, error) {
+	rows, err := d.DB.QueryContext(ctx, `
+		UPDATE event_outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM event_outbox
+			WHERE status = 'PENDING' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, aggregate_type, aggregate_id, payload, attempts, created_at, delivered_to
+	`, d.BatchSize, time.Now().Add(d.ClaimTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.EventType, &event.AggregateType, &event.AggregateID,
+			&payload, &event.Attempts, &event.CreatedAt, pq.Array(&event.DeliveredTo)); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = payload
+		event.Status = models.OutboxStatusPending
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox events: %w", err)
+	}
+
+	// RETURNING does not keep the subquery's order
+	sort.Slice(events, func(i, j int) bool { return events[i].CreatedAt.Before(events[j].CreatedAt) })
+	return events, nil
+}
+
+// deliver sends the event to every notifier that has not received it yet and returns
+// the notifiers that now have it. It fails if any notifier fails.
+func (d *OutboxDispatcher) deliver(ctx context.Context, event models.OutboxEvent) ([]string, error) {
+	deliveredTo := append([]string(nil), event.DeliveredTo...)
+	received := make(map[string]bool, len(deliveredTo))
+	for _, name := range deliveredTo {
+		received[name] = true
+	}
+
+	var failures []string
+	for _, notifier := range d.Notifiers {
+		if received[notifier.Name()] {
+			continue
+		}
+		if err := notifier.Notify(ctx, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", notifier.Name(), err))
+			continue
+		}
+		deliveredTo = append(deliveredTo, notifier.Name())
+	}
+
+	if len(failures) > 0 {
+		return deliveredTo, fmt.Errorf("delivery failed: %s", strings.Join(failures, "; "))
+	}
+	return deliveredTo, nil
+}
+
+// markFailed records the notifiers that did receive the event and schedules a retry
+// for the rest with exponential backoff, or gives up after MaxAttempts
+func (d *OutboxDispatcher) markFailed(ctx context.Context, event models.OutboxEvent, deliveredTo []string, deliverErr error) error {
+	attempts := event.Attempts + 1
+	status := models.OutboxStatusPending
+	if attempts >= d.MaxAttempts {
+		status = models.OutboxStatusFailed
+	}
+
+	backoff := time.Duration(1<<uint(minInt(attempts, 10))) * time.Second
+	errMsg := deliverErr.Error()
+
+	d.logger.WithFields(logrus.Fields{
+		"event_id":   event.ID,
+		"event_type": event.EventType,
+		"attempts":   attempts,
+		"status":     status,
+	}).WithError(deliverErr).Warn("Outbox event delivery failed")
+
+	_, err := d.DB.ExecContext(ctx, `
+		UPDATE event_outbox
+		SET status = $2, attempts = $3, last_error = $4, next_attempt_at = $5, delivered_to = $6
+		WHERE id = $1
+	`, event.ID, status, attempts, errMsg, time.Now().Add(backoff), pq.Array(deliveredTo))
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// StatusCounts returns the number of outbox events in each status
+func (d *OutboxDispatcher) StatusCounts(ctx context.Context) (map[string]int, error) {
+	rows, err := d.DB.QueryContext(ctx, `SELECT status, COUNT(*) FROM event_outbox GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox count: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// PurgeDelivered removes delivered events older than the retention period
+func (d *OutboxDispatcher) PurgeDelivered(ctx context.Context) error {
+	if d.DB == nil {
+		return nil
+	}
+
+	result, err := d.DB.ExecContext(ctx, `
+		DELETE FROM event_outbox
+		WHERE status = 'DELIVERED' AND delivered_at < $1
+	`, time.Now().Add(-d.RetentionAfter))
+	if err != nil {
+		return err
+	}
+
+	if purged, _ := result.RowsAffected(); purged > 0 {
+		d.logger.WithField("purged", purged).Info("Purged delivered outbox events")
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// MaxSavedPANsPerOwner caps how many PANs one device or account can keep in the vault
+const MaxSavedPANsPerOwner = 10
+
+// ErrPANVaultDisabled is returned when no vault encryption key is configured
+var ErrPANVaultDisabled = errors.New("PAN vault is not enabled")
+
+// deviceIDPattern restricts anonymous device IDs to opaque tokens
+var deviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+// PANCipher encrypts PANs with AES-256-GCM. The key comes from the environment (or a
+// data key unwrapped from a KMS at startup); the owner hash is bound in as associated
+// data so a ciphertext copied to another owner's row fails to decrypt.
+type PANCipher struct {
+	aead cipher.AEAD
+}
+
+// NewPANCipher creates a cipher from a 32-byte key
+func NewPANCipher(key []byte) (*PANCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("PAN vault key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PAN cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PAN cipher: %w", err)
+	}
+	return &PANCipher{aead: aead}, nil
+}
+
+// Encrypt returns nonce||ciphertext for pan
+func (c *PANCipher) Encrypt(pan, ownerHash string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, []byte(pan), []byte(ownerHash)), nil
+}
+
+// Decrypt reverses Encrypt
+func (c *PANCipher) Decrypt(data []byte, ownerHash string) (string, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("PAN ciphertext is too short")
+	}
+	plain, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(ownerHash))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt saved PAN: %w", err)
+	}
+	return string(plain), nil
+}
+
+// VaultPAN is a saved PAN together with its decrypted value, for server-side use only
+type VaultPAN struct {
+	models.SavedPAN
+	PAN string
+}
+
+// PANVaultService stores opt-in saved PANs encrypted at rest, keyed by an owner hash
+// derived from a device or account ID. Raw owner IDs and PANs are never persisted.
+type PANVaultService struct {
+	DB     *sql.DB
+	cipher *PANCipher
+}
+
+// NewPANVaultService creates a vault; a nil cipher leaves the vault disabled
+func NewPANVaultService(db *sql.DB, panCipher *PANCipher) *PANVaultService {
+	return &PANVaultService{DB: db, cipher: panCipher}
+}
+
+// Enabled reports whether an encryption key is configured
+func (s *PANVaultService) Enabled() bool {
+	return s != nil && s.cipher != nil
+}
+
+// DeviceOwnerHash validates an anonymous device ID and returns the owner hash stored in place of it
+func DeviceOwnerHash(deviceID string) (string, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if !deviceIDPattern.MatchString(deviceID) {
+		return "", shared.ValidationErrorf("device ID must be 16-128 letters, digits, '-' or '_'")
+	}
+	sum := sha256.Sum256([]byte(deviceID))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AccountOwnerHash returns the owner hash for a signed-in account. The "account:" prefix
+// can't occur in a device ID, so a device can never claim an account's vault.
+func AccountOwnerHash(accountID uuid.UUID) string {
+	sum := sha256.Sum256([]byte("account:" + accountID.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// List returns the owner's saved PANs, masked, oldest first
+func (s *PANVaultService) List(ctx context.Context, ownerHash string) ([]models.SavedPAN, error) {
+	if !s.Enabled() {
+		return nil, ErrPANVaultDisabled
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, masked_pan, COALESCE(label, ''), created_at, last_checked_at
+		FROM saved_pans WHERE owner_hash = $1
+		ORDER BY created_at
+	`, ownerHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved PANs: %w", err)
+	}
+	defer rows.Close()
+
+	saved := []models.SavedPAN{}
+	for rows.Next() {
+		var pan models.SavedPAN
+		if err := rows.Scan(&pan.ID, &pan.MaskedPAN, &pan.Label, &pan.CreatedAt, &pan.LastCheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved PAN: %w", err)
+		}
+		saved = append(saved, pan)
+	}
+	return saved, rows.Err()
+}
+
+// Add saves a PAN for the owner. Saving a PAN that is already in the vault updates its label.
+// The count against MaxSavedPANsPerOwner and the insert run in one transaction holding an
+// advisory lock on the owner, so concurrent saves can't together go over the cap.
+func (s *PANVaultService) Add(ctx context.Context, ownerHash, pan, label string) (*models.SavedPAN, error) {
+	if !s.Enabled() {
+		return nil, ErrPANVaultDisabled
+	}
+	if !shared.IsValidPAN(pan) {
+		return nil, shared.ValidationErrorf("invalid PAN format")
+	}
+	label = strings.TrimSpace(label)
+	if len(label) > 100 {
+		return nil, shared.ValidationErrorf("label must be at most 100 characters")
+	}
+	pan = shared.NormalizePAN(pan)
+
+	ciphertext, err := s.cipher.Encrypt(pan, ownerHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin saving PAN: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, ownerHash); err != nil {
+		return nil, fmt.Errorf("failed to lock saved PANs: %w", err)
+	}
+	var count int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM saved_pans WHERE owner_hash = $1 AND pan_hash != $2
+	`, ownerHash, shared.HashPAN(pan)).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count saved PANs: %w", err)
+	}
+	if count >= MaxSavedPANsPerOwner {
+		return nil, shared.ValidationErrorf("at most %d PANs can be saved", MaxSavedPANsPerOwner)
+	}
+
+	saved := &models.SavedPAN{MaskedPAN: shared.MaskPAN(pan), Label: label}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO saved_pans (owner_hash, pan_hash, pan_ciphertext, masked_pan, label)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (owner_hash, pan_hash) DO UPDATE SET label = EXCLUDED.label
+		RETURNING id, created_at, last_checked_at
+	`, ownerHash, shared.HashPAN(pan), ciphertext, saved.MaskedPAN, label).
+		Scan(&saved.ID, &saved.CreatedAt, &saved.LastCheckedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save PAN: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save PAN: %w", err)
+	}
+	return saved, nil
+}
+
+// Remove deletes one of the owner's saved PANs
+func (s *PANVaultService) Remove(ctx context.Context, ownerHash string, id uuid.UUID) error {
+	if !s.Enabled() {
+		return ErrPANVaultDisabled
+	}
+
+	result, err := s.DB.ExecContext(ctx, `DELETE FROM saved_pans WHERE id = $1 AND owner_hash = $2`, id, ownerHash)
+	if err != nil {
+		return fmt.Errorf("failed to remove saved PAN: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return shared.NotFoundErrorf("saved PAN %s not found", id)
+	}
+	return nil
+}
+
+// Unlock returns the owner's saved PANs with their decrypted values
+func (s *PANVaultService) Unlock(ctx context.Context, ownerHash string) ([]VaultPAN, error) {
+	if !s.Enabled() {
+		return nil, ErrPANVaultDisabled
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, masked_pan, COALESCE(label, ''), created_at, last_checked_at, pan_ciphertext
+		FROM saved_pans WHERE owner_hash = $1
+		ORDER BY created_at
+	`, ownerHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved PANs: %w", err)
+	}
+	defer rows.Close()
+
+	var unlocked []VaultPAN
+	for rows.Next() {
+		var entry VaultPAN
+		var ciphertext []byte
+		if err := rows.Scan(&entry.ID, &entry.MaskedPAN, &entry.Label, &entry.CreatedAt, &entry.LastCheckedAt, &ciphertext); err != nil {
+			return nil, fmt.Errorf("failed to scan saved PAN: %w", err)
+		}
+		if entry.PAN, err = s.cipher.Decrypt(ciphertext, ownerHash); err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, entry)
+	}
+	return unlocked, rows.Err()
+}
+
+// MarkChecked records that the owner's saved PANs were just checked
+func (s *PANVaultService) MarkChecked(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(ids)+1)
+	placeholders := make([]string, 0, len(ids))
+	args = append(args, time.Now())
+	for i, id := range ids {
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+2))
+	}
+
+	_, err := s.DB.ExecContext(ctx, `UPDATE saved_pans SET last_checked_at = $1 WHERE id IN (`+
+		strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark saved PANs checked: %w", err)
+	}
+	return nil
+}
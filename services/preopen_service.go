@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// PreOpenProvider fetches the pre-open call auction state of an exchange symbol
+type PreOpenProvider interface {
+	Name() string
+	FetchPreOpen(ctx context.Context, symbol string) (*models.IndicativeListingPrice, error)
+}
+
+// NSEPreOpenProvider reads the indicative equilibrium price (IEP) from NSE's quote API
+type NSEPreOpenProvider struct {
+	*NSESession
+}
+
+// NewNSEPreOpenProvider creates an NSE pre-open provider
+func NewNSEPreOpenProvider() *NSEPreOpenProvider {
+	return &NSEPreOpenProvider{NSESession: NewNSESession()}
+}
+
+// Name returns the provider name recorded on prices
+func (p *NSEPreOpenProvider) Name() string {
+	return "nse_pre_open"
+}
+
+// nseQuoteResponse is the subset of NSE's quote-equity response used for pre-open prices
+type nseQuoteResponse struct {
+	PreOpenMarket struct {
+		IEP               float64 `json:"IEP"`
+		TotalBuyQuantity  float64 `json:"totalBuyQuantity"`
+		TotalSellQuantity float64 `json:"totalSellQuantity"`
+		LastUpdateTime    string  `json:"lastUpdateTime"`
+	} `json:"preOpenMarket"`
+}
+
+// nseTimeLayout is the layout of NSE's lastUpdateTime, e.g. "15-Jan-2024 09:44:59" (IST)
+const nseTimeLayout = "02-Jan-2006 15:04:05"
+
+// FetchPreOpen returns the current IEP of symbol; a symbol without a discovered price is
+// reported as not_found
+func (p *NSEPreOpenProvider) FetchPreOpen(ctx context.Context, symbol string) (*models.IndicativeListingPrice, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	var quote nseQuoteResponse
+	if err := p.GetJSON(ctx, "/api/quote-equity?symbol="+url.QueryEscape(symbol), "/get-quotes/equity?symbol="+url.QueryEscape(symbol), &quote); err != nil {
+		return nil, err
+	}
+	market := quote.PreOpenMarket
+	if market.IEP <= 0 {
+		return nil, shared.NotFoundErrorf("no pre-open price for %s", symbol)
+	}
+
+	price := &models.IndicativeListingPrice{
+		Symbol:            symbol,
+		Exchange:          QuoteExchangeNSE,
+		Price:             market.IEP,
+		TotalBuyQuantity:  int64(market.TotalBuyQuantity),
+		TotalSellQuantity: int64(market.TotalSellQuantity),
+		MarketTime:        time.Now().UTC(),
+		Source:            p.Name(),
+	}
+	if parsed, err := time.ParseInLocation(nseTimeLayout, market.LastUpdateTime, istLocation()); err == nil {
+		price.MarketTime = parsed.UTC()
+	}
+	return price, nil
+}
+
+// istLocation returns the Indian Standard Time zone exchanges operate in
+func istLocation() *time.Location {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return location
+}
+
+// Pre-open call auction window on an IPO's listing day (IST). Orders are collected from
+// 9:00 and the equilibrium price settles before normal trading starts at 10:00.
+const (
+	PreOpenWindowStart = 9 * time.Hour
+	PreOpenWindowEnd   = 10 * time.Hour
+)
+
+// PreOpenService ingests indicative listing prices for IPOs listing today
+type PreOpenService struct {
+	DB       *sql.DB
+	Provider PreOpenProvider
+	location *time.Location
+}
+
+// NewPreOpenService creates a pre-open service reading prices from provider
+func NewPreOpenService(db *sql.DB, provider PreOpenProvider) *PreOpenService {
+	return &PreOpenService{
+		DB:       db,
+		Provider: provider,
+		location: istLocation(),
+	}
+}
+
+// Window returns the pre-open window of the exchange day containing t
+func (s *PreOpenService) Window(t time.Time) (time.Time, time.Time) {
+	local := t.In(s.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	return day.Add(PreOpenWindowStart), day.Add(PreOpenWindowEnd)
+}
+
+// NextWindow returns the window in progress at t, or the next one to start
+func (s *PreOpenService) NextWindow(t time.Time) (time.Time, time.Time) {
+	start, end := s.Window(t)
+	if !t.Before(end) {
+		start, end = s.Window(start.AddDate(0, 0, 1))
+	}
+	return start, end
+}
+
+// ListingToday returns the IPOs with an exchange symbol listing on t's exchange day
+func (s *PreOpenService) ListingToday(ctx context.Context, t time.Time) ([]models.IPO, error) {
+	local := t.In(s.location)
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, name, symbol, price_band_high
+		FROM ipo_list
+		WHERE listing_date::date = $1::date AND symbol IS NOT NULL AND symbol != '' AND deleted_at IS NULL
+	`, local.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPOs listing today: %w", err)
+	}
+	defer rows.Close()
+
+	var ipos []models.IPO
+	for rows.Next() {
+		var ipo models.IPO
+		if err := rows.Scan(&ipo.ID, &ipo.Name, &ipo.Symbol, &ipo.PriceBandHigh); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO listing today: %w", err)
+		}
+		ipos = append(ipos, ipo)
+	}
+	return ipos, rows.Err()
+}
+
+// Refresh fetches the current pre-open price of a listing IPO and stores it
+func (s *PreOpenService) Refresh(ctx context.Context, ipo *models.IPO) (*models.IndicativeListingPrice, error) {
+	if ipo.Symbol == nil || strings.TrimSpace(*ipo.Symbol) == "" {
+		return nil, shared.NotFoundErrorf("IPO %s has no exchange symbol", ipo.Name)
+	}
+
+	price, err := s.Provider.FetchPreOpen(ctx, *ipo.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	price.IPOID = ipo.ID
+	price.UpdatedAt = time.Now()
+	if ipo.PriceBandHigh != nil && *ipo.PriceBandHigh > 0 {
+		issuePrice := *ipo.PriceBandHigh
+		gainPercent := roundTo((price.Price-issuePrice)/issuePrice*100, 2)
+		price.IssuePrice = &issuePrice
+		price.GainPercent = &gainPercent
+	}
+
+	if _, err := s.DB.ExecContext(ctx, `
+		INSERT INTO ipo_preopen_prices (ipo_id, symbol, exchange, price, total_buy_quantity, total_sell_quantity, market_time, source, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (ipo_id) DO UPDATE SET
+			symbol = EXCLUDED.symbol,
+			exchange = EXCLUDED.exchange,
+			price = EXCLUDED.price,
+			total_buy_quantity = EXCLUDED.total_buy_quantity,
+			total_sell_quantity = EXCLUDED.total_sell_quantity,
+			market_time = EXCLUDED.market_time,
+			source = EXCLUDED.source,
+			updated_at = EXCLUDED.updated_at
+	`, price.IPOID, price.Symbol, price.Exchange, price.Price, price.TotalBuyQuantity, price.TotalSellQuantity,
+		price.MarketTime, price.Source, price.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save pre-open price: %w", err)
+	}
+	return price, nil
+}
+
+// GetIndicativePrice returns the stored pre-open price of an IPO, or nil when none was captured
+func (s *PreOpenService) GetIndicativePrice(ctx context.Context, ipoID uuid.UUID) (*models.IndicativeListingPrice, error) {
+	price := &models.IndicativeListingPrice{IPOID: ipoID}
+	var issuePrice sql.NullFloat64
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT p.symbol, p.exchange, p.price, p.total_buy_quantity, p.total_sell_quantity,
+		       p.market_time, p.source, p.updated_at, i.price_band_high
+		FROM ipo_preopen_prices p
+		JOIN ipo_list i ON i.id = p.ipo_id
+		WHERE p.ipo_id = $1
+	`, ipoID).Scan(&price.Symbol, &price.Exchange, &price.Price, &price.TotalBuyQuantity, &price.TotalSellQuantity,
+		&price.MarketTime, &price.Source, &price.UpdatedAt, &issuePrice)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pre-open price: %w", err)
+	}
+	if issuePrice.Valid && issuePrice.Float64 > 0 {
+		gainPercent := roundTo((price.Price-issuePrice.Float64)/issuePrice.Float64*100, 2)
+		price.IssuePrice = &issuePrice.Float64
+		price.GainPercent = &gainPercent
+	}
+	return price, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/google/uuid"
+)
+
+// PriceRevisionSourceScraper is the source recorded for bands written without a CreatedBy
+const PriceRevisionSourceScraper = "scraper"
+
+// priceBandChanged reports whether an upsert changes the stored price band. A band that
+// is not known yet is not a revision, and neither is a scrape that lost the band.
+func priceBandChanged(before *models.IPO, after *models.IPO) bool {
+	if after.PriceBandLow == nil && after.PriceBandHigh == nil {
+		return false
+	}
+	if before == nil {
+		return true
+	}
+	return !sameFloat(before.PriceBandLow, after.PriceBandLow) || !sameFloat(before.PriceBandHigh, after.PriceBandHigh)
+}
+
+// sameFloat compares optional prices to the paisa, as stored in DECIMAL(10,2)
+func sameFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return roundTo(*a, 2) == roundTo(*b, 2)
+}
+
+// ipoChangeSource is who an upsert of ipo is recorded as: its CreatedBy, or the scraper
+func ipoChangeSource(ipo *models.IPO) string {
+	if ipo.CreatedBy != nil && *ipo.CreatedBy != "" {
+		return *ipo.CreatedBy
+	}
+	return PriceRevisionSourceScraper
+}
+
+// savePriceRevision records a price band change inside the caller's transaction
+func savePriceRevision(ctx context.Context, tx *sql.Tx, ipoID uuid.UUID, before *models.IPO, after *models.IPO) error {
+	source := ipoChangeSource(after)
+	var previousLow, previousHigh *float64
+	if before != nil {
+		previousLow, previousHigh = before.PriceBandLow, before.PriceBandHigh
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO ipo_price_revisions (
+			ipo_id, price_band_low, price_band_high,
+			previous_price_band_low, previous_price_band_high, source, revised_at
+		) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+	`, ipoID, after.PriceBandLow, after.PriceBandHigh, previousLow, previousHigh, source)
+	if err != nil {
+		return fmt.Errorf("failed to save price revision: %w", err)
+	}
+	return nil
+}
+
+// GetPriceRevisions returns the price band history of an IPO, oldest first
+func (s *IPOService) GetPriceRevisions(ctx context.Context, ipoID uuid.UUID) ([]models.PriceRevision, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT price_band_low, price_band_high, previous_price_band_low, previous_price_band_high,
+		       source, revised_at
+		FROM ipo_price_revisions
+		WHERE ipo_id = $1
+		ORDER BY revised_at
+	`, ipoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.PriceRevision{}
+	for rows.Next() {
+		var revision models.PriceRevision
+		if err := rows.Scan(&revision.PriceBandLow, &revision.PriceBandHigh,
+			&revision.PreviousPriceBandLow, &revision.PreviousPriceBandHigh,
+			&revision.Source, &revision.RevisedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
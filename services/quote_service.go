@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// QuoteProvider fetches the latest market price for an exchange symbol
+type QuoteProvider interface {
+	Name() string
+	FetchQuote(ctx context.Context, symbol, exchange string) (*models.StockQuote, error)
+}
+
+// Exchanges tried, in order, when looking up a listed IPO's quote
+const (
+	QuoteExchangeNSE = "NSE"
+	QuoteExchangeBSE = "BSE"
+)
+
+// YahooQuoteProvider reads quotes from Yahoo Finance's public chart endpoint
+type YahooQuoteProvider struct {
+	BaseURL string
+	Client  *http.Client
+	Policy  shared.RetryPolicy
+}
+
+// NewYahooQuoteProvider creates a Yahoo Finance quote provider
+func NewYahooQuoteProvider() *YahooQuoteProvider {
+	return &YahooQuoteProvider{
+		BaseURL: "https://query1.finance.yahoo.com/v8/finance/chart/",
+		Client:  shared.NewHTTPClientFactory(10*time.Second).CreateOptimizedHTTPClient(10 * time.Second),
+		Policy:  shared.DefaultHTTPRetryPolicy().WithMaxRetries(1),
+	}
+}
+
+// Name returns the provider name recorded on quotes
+func (p *YahooQuoteProvider) Name() string {
+	return "yahoo_finance"
+}
+
+// yahooChartResponse is the subset of the chart response used for quotes
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Currency           string  `json:"currency"`
+				Symbol             string  `json:"symbol"`
+				RegularMarketPrice float64 `json:"regularMarketPrice"`
+				ChartPreviousClose float64 `json:"chartPreviousClose"`
+				PreviousClose      float64 `json:"previousClose"`
+				RegularMarketTime  int64   `json:"regularMarketTime"`
+			} `json:"meta"`
+		} `json:"result"`
+	} `json:"chart"`
+}
+
+// yahooSymbolSuffixes maps exchanges to Yahoo ticker suffixes
+var yahooSymbolSuffixes = map[string]string{
+	QuoteExchangeNSE: ".NS",
+	QuoteExchangeBSE: ".BO",
+}
+
+// FetchQuote returns the latest quote; unknown symbols are reported as not_found
+func (p *YahooQuoteProvider) FetchQuote(ctx context.Context, symbol, exchange string) (*models.StockQuote, error) {
+	suffix, ok := yahooSymbolSuffixes[exchange]
+	if !ok {
+		return nil, shared.ValidationErrorf("unsupported exchange %q", exchange)
+	}
+
+	quote, err := p.FetchTicker(ctx, strings.ToUpper(symbol)+suffix)
+	if err != nil {
+		if shared.ErrorCategoryOf(err) == shared.ErrorCategoryNotFound {
+			return nil, shared.NotFoundErrorf("no quote for %s on %s", symbol, exchange)
+		}
+		return nil, err
+	}
+	quote.Symbol = strings.ToUpper(symbol)
+	quote.Exchange = exchange
+	return quote, nil
+}
+
+// FetchTicker returns the latest quote of a raw Yahoo ticker such as "^BSESN"; the
+// returned quote has no exchange set
+func (p *YahooQuoteProvider) FetchTicker(ctx context.Context, ticker string) (*models.StockQuote, error) {
+	requestURL := p.BaseURL + url.PathEscape(ticker) + "?interval=1d&range=1d"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quote request: %w", err)
+	}
+	shared.SetBrowserLikeHeaders(req, "application/json")
+
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(p.Client, req, p.Policy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chart yahooChartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return nil, shared.ParseErrorf("failed to decode quote response: %w", err)
+	}
+	if len(chart.Chart.Result) == 0 || chart.Chart.Result[0].Meta.RegularMarketPrice <= 0 {
+		return nil, shared.NotFoundErrorf("no quote for %s", ticker)
+	}
+
+	meta := chart.Chart.Result[0].Meta
+	previousClose := meta.PreviousClose
+	if previousClose <= 0 {
+		previousClose = meta.ChartPreviousClose
+	}
+
+	return &models.StockQuote{
+		Symbol:        ticker,
+		Currency:      meta.Currency,
+		Price:         meta.RegularMarketPrice,
+		PreviousClose: previousClose,
+		MarketTime:    time.Unix(meta.RegularMarketTime, 0).UTC(),
+		Source:        p.Name(),
+	}, nil
+}
+
+// QuoteService returns post-listing prices for listed IPOs, caching quotes briefly so
+// popular IPO pages do not hit the provider on every request
+type QuoteService struct {
+	provider QuoteProvider
+	cache    *CacheService
+	ttl      time.Duration
+	logger   *logrus.Entry
+}
+
+// NewQuoteService creates a quote service caching quotes for ttl
+func NewQuoteService(provider QuoteProvider, ttl time.Duration) *QuoteService {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &QuoteService{
+		provider: provider,
+		cache:    NewCacheServiceWithConfig(nil, ttl, 500),
+		ttl:      ttl,
+		logger:   logrus.WithField("component", "quote_service"),
+	}
+}
+
+// GetIPOQuote returns the current price of a listed IPO with its day change and gain
+// over the issue price (the upper price band)
+func (s *QuoteService) GetIPOQuote(ctx context.Context, ipo *models.IPO) (*models.IPOQuote, error) {
+	if ipo.ListingDate == nil || ipo.ListingDate.After(time.Now()) {
+		return nil, shared.ValidationErrorf("IPO %s has not listed yet", ipo.Name)
+	}
+	if ipo.Symbol == nil || strings.TrimSpace(*ipo.Symbol) == "" {
+		return nil, shared.NotFoundErrorf("IPO %s has no exchange symbol", ipo.Name)
+	}
+	symbol := strings.TrimSpace(*ipo.Symbol)
+
+	cacheKey := "quote:" + strings.ToUpper(symbol)
+	cached := false
+	var quote *models.StockQuote
+	if value, ok := s.cache.Get(cacheKey); ok {
+		quote = value.(*models.StockQuote)
+		cached = true
+	} else {
+		fetched, err := s.fetchQuote(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		quote = fetched
+		s.cache.SetWithTTL(cacheKey, quote, s.ttl)
+	}
+
+	result := &models.IPOQuote{
+		StockQuote:  *quote,
+		IPOID:       ipo.ID,
+		Name:        ipo.Name,
+		ListingDate: ipo.ListingDate,
+		FetchedAt:   time.Now(),
+		Cached:      cached,
+	}
+	if quote.PreviousClose > 0 {
+		result.DayChange = roundTo(quote.Price-quote.PreviousClose, 2)
+		result.DayChangePercent = roundTo((quote.Price-quote.PreviousClose)/quote.PreviousClose*100, 2)
+	}
+	if ipo.PriceBandHigh != nil && *ipo.PriceBandHigh > 0 {
+		issuePrice := *ipo.PriceBandHigh
+		gain := roundTo(quote.Price-issuePrice, 2)
+		gainPercent := roundTo((quote.Price-issuePrice)/issuePrice*100, 2)
+		result.IssuePrice = &issuePrice
+		result.GainVsIssue = &gain
+		result.GainVsIssuePercent = &gainPercent
+	}
+
+	return result, nil
+}
+
+// fetchQuote tries NSE first, then BSE, since SME IPOs often list on BSE only
+func (s *QuoteService) fetchQuote(ctx context.Context, symbol string) (*models.StockQuote, error) {
+	var lastErr error
+	for _, exchange := range []string{QuoteExchangeNSE, QuoteExchangeBSE} {
+		quote, err := s.provider.FetchQuote(ctx, symbol, exchange)
+		if err == nil {
+			return quote, nil
+		}
+		lastErr = err
+		if !errors.Is(err, shared.ErrNotFound) {
+			break
+		}
+	}
+
+	s.logger.WithError(lastErr).WithField("symbol", symbol).Warn("Failed to fetch market quote")
+	return nil, lastErr
+}
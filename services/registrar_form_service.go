@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// csrfFieldPattern matches hidden field names that carry anti-forgery tokens
+var csrfFieldPattern = regexp.MustCompile(`(?i)csrf|xsrf|requestverificationtoken|^_?token$|authenticity_token`)
+
+// RegistrarFormService reads live metadata from registrar allotment form pages. Results
+// are cached briefly and shared by every client, so they never carry the values of the
+// server's own session, such as its CSRF token.
+type RegistrarFormService struct {
+	Client      *http.Client
+	Policy      shared.RetryPolicy
+	RateLimiter *shared.HTTPRequestRateLimiter
+	cache       *CacheService
+	ttl         time.Duration
+}
+
+// NewRegistrarFormService creates a form metadata service caching results for ttl
+func NewRegistrarFormService(ttl time.Duration) *RegistrarFormService {
+	if ttl <= 0 {
+		ttl = 2 * time.Minute
+	}
+	return &RegistrarFormService{
+		Client:      shared.NewHTTPClientFactory(15 * time.Second).CreateOptimizedHTTPClient(15 * time.Second),
+		Policy:      shared.DefaultHTTPRetryPolicy().WithMaxRetries(1),
+		RateLimiter: shared.NewHTTPRequestRateLimiter(2 * time.Second),
+		cache:       NewCacheServiceWithConfig(nil, ttl, 200),
+		ttl:         ttl,
+	}
+}
+
+// GetFormMetadata returns the live form metadata of an IPO's registrar form. An IPO
+// without a form URL is reported as not_found.
+func (s *RegistrarFormService) GetFormMetadata(ctx context.Context, ipo *models.IPO) (*models.RegistrarFormMetadata, error) {
+	if ipo.FormURL == nil || strings.TrimSpace(*ipo.FormURL) == "" {
+		return nil, shared.NotFoundErrorf("IPO %s has no registrar form URL", ipo.Name)
+	}
+	formURL := strings.TrimSpace(*ipo.FormURL)
+
+	cacheKey := "form_meta:" + ipo.ID.String()
+	if value, ok := s.cache.Get(cacheKey); ok {
+		return value.(*models.RegistrarFormMetadata), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, formURL, nil)
+	if err != nil {
+		return nil, shared.ValidationErrorf("invalid registrar form URL: %v", err)
+	}
+	if err := s.RateLimiter.WaitForHost(ctx, req.URL.Host); err != nil {
+		return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
+	}
+	shared.SetBrowserLikeHeaders(req, "text/html,application/xhtml+xml")
+
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(s.Client, req, s.Policy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	document, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, shared.ParseErrorf("failed to parse registrar form page: %v", err)
+	}
+
+	metadata := ParseRegistrarForm(document, ipo.Name)
+	metadata.FormURL = formURL
+	metadata.FetchedAt = time.Now()
+	metadata.ExpiresAt = metadata.FetchedAt.Add(s.ttl)
+	s.cache.SetWithTTL(cacheKey, metadata, s.ttl)
+	return metadata, nil
+}
+
+// ParseRegistrarForm extracts the hidden and CSRF field names, the company option matching
+// ipoName and captcha requirements from a registrar form page. Field values are left out:
+// they belong to the session that fetched the page.
+func ParseRegistrarForm(document *goquery.Document, ipoName string) *models.RegistrarFormMetadata {
+	metadata := &models.RegistrarFormMetadata{HiddenFields: []string{}}
+
+	document.Find(`input[type="hidden"], input[type="HIDDEN"]`).Each(func(_ int, input *goquery.Selection) {
+		name := strings.TrimSpace(input.AttrOr("name", input.AttrOr("id", "")))
+		if name == "" {
+			return
+		}
+		metadata.HiddenFields = append(metadata.HiddenFields, name)
+		if metadata.CSRFField == "" && csrfFieldPattern.MatchString(name) {
+			metadata.CSRFField = name
+		}
+	})
+	if metadata.CSRFField == "" && document.Find(`meta[name="csrf-token"]`).Length() > 0 {
+		metadata.CSRFField = "csrf-token"
+	}
+
+	// The company dropdown is the select with an option naming this IPO
+	wanted := DedupNameKey(ipoName)
+	document.Find("select").EachWithBreak(func(_ int, selectElement *goquery.Selection) bool {
+		found := false
+		selectElement.Find("option").EachWithBreak(func(_ int, option *goquery.Selection) bool {
+			label := strings.Join(strings.Fields(option.Text()), " ")
+			key := DedupNameKey(label)
+			if wanted == "" || key == "" || !(key == wanted || strings.HasPrefix(key, wanted+" ") || strings.HasPrefix(wanted, key+" ")) {
+				return true
+			}
+			metadata.CompanyField = selectElement.AttrOr("name", selectElement.AttrOr("id", ""))
+			metadata.CompanyValue = option.AttrOr("value", label)
+			metadata.CompanyLabel = label
+			found = true
+			return false
+		})
+		return !found
+	})
+
+	switch {
+	case document.Find(`.g-recaptcha, script[src*="recaptcha"]`).Length() > 0:
+		metadata.CaptchaType = "recaptcha"
+	case document.Find(`.h-captcha, script[src*="hcaptcha"]`).Length() > 0:
+		metadata.CaptchaType = "hcaptcha"
+	case document.Find(`img[src*="captcha" i], img[id*="captcha" i], input[name*="captcha" i]`).Length() > 0:
+		metadata.CaptchaType = "image"
+	}
+	metadata.CaptchaRequired = metadata.CaptchaType != ""
+	return metadata
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// Confidence of a cached allotment result. A result starts at BaseResultConfidence and
+// gains AgreeingSourceConfidence for each further source that returns the same status and
+// shares, up to 100. A different result replaces the row and starts over.
+const (
+	BaseResultConfidence     = 50
+	AgreeingSourceConfidence = 15
+)
+
+// resultSourceKey identifies where a result came from: the channel, the check source
+// (live_check, recheck, ...) and, when known, the client, so repeated checks from the same
+// session do not count as agreement
+func resultSourceKey(result *models.IPOResultCache) string {
+	channel := result.SourceChannel
+	if channel == "" {
+		channel = models.SourceChannelAPI
+	}
+	key := channel + ":" + result.Source
+	if result.ClientFingerprint != "" {
+		key += ":" + result.ClientFingerprint
+	}
+	return key
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Result release watching. From the start of an IPO's result date (IST) the registrar is
+// probed every DefaultResultWatchInterval for DefaultResultWatchWindow, then every
+// DefaultResultWatchSlowInterval until results are out or the IPO lists.
+const (
+	DefaultResultWatchInterval     = 10 * time.Minute
+	DefaultResultWatchWindow       = 48 * time.Hour
+	DefaultResultWatchSlowInterval = time.Hour
+)
+
+// DefaultResultProbePAN is the well-formed PAN the registrar is probed with. Registrars
+// answer it "results not declared" before release and "not found" after, so no investor
+// PAN is needed.
+const DefaultResultProbePAN = "ZZZPZ9999Z"
+
+// Ways a result release is detected
+const (
+	ResultReleaseDetectedByCheck = "check" // a stored check already has a definitive result
+	ResultReleaseDetectedByProbe = "probe" // the registrar stopped answering "not declared"
+)
+
+// ResultReleaseLogSource is the ipo_update_log source of the status change to RESULT_OUT
+const ResultReleaseLogSource = "result_release"
+
+// statusWithResults returns the status an IPO shows: the status computed from its dates,
+// except that a closed IPO whose results were detected keeps the stored RESULT_OUT
+func statusWithResults(computed, stored string) string {
+	if computed == "CLOSED" && stored == models.IPOStatusResultOut {
+		return models.IPOStatusResultOut
+	}
+	return computed
+}
+
+// ResultReleaseWatcher detects when the registrar publishes an IPO's allotment results.
+// On release the IPO moves to RESULT_OUT, an ipo.results_released outbox event is
+// emitted once and every replica drops its "not declared" mark of the IPO.
+type ResultReleaseWatcher struct {
+	DB      *sql.DB
+	Checker *AllotmentChecker
+	IPOs    *IPOService
+	// Interval is how often IPOs inside their watch window are probed
+	Interval time.Duration
+	// Window is how long after the start of the result date IPOs are probed every Interval
+	Window time.Duration
+	// SlowInterval is how often IPOs past their window are probed until they list
+	SlowInterval time.Duration
+	// ProbePAN is the PAN the registrar is queried with
+	ProbePAN string
+	// Clock decides which IPOs are watched and when they are due
+	Clock shared.Clock
+
+	mutex      sync.Mutex
+	lastProbes map[uuid.UUID]time.Time
+	logger     *logrus.Entry
+}
+
+func NewResultReleaseWatcher(db *sql.DB, checker *AllotmentChecker, ipos *IPOService) *ResultReleaseWatcher {
+	return &ResultReleaseWatcher{
+		DB:           db,
+		Checker:      checker,
+		IPOs:         ipos,
+		Interval:     DefaultResultWatchInterval,
+		Window:       DefaultResultWatchWindow,
+		SlowInterval: DefaultResultWatchSlowInterval,
+		ProbePAN:     DefaultResultProbePAN,
+		Clock:        shared.DefaultClock,
+		lastProbes:   make(map[uuid.UUID]time.Time),
+		logger:       logrus.WithField("component", "result_release"),
+	}
+}
+
+// watchedIPO is an IPO whose results are expected but not yet detected
+type watchedIPO struct {
+	id         uuid.UUID
+	resultDate time.Time
+}
+
+// RunDue probes the watched IPOs that are due and records the releases it detects.
+// Returns how many IPOs had their results released.
+func (s *ResultReleaseWatcher) RunDue(ctx context.Context) (int, error) {
+	now := shared.ClockOrDefault(s.Clock).Now()
+	watched, err := s.watching(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, watch := range watched {
+		if ctx.Err() != nil {
+			return released, ctx.Err()
+		}
+		if !s.due(watch, now) {
+			continue
+		}
+		ipo, err := s.IPOs.GetIPOByID(ctx, watch.id.String())
+		if err != nil || ipo == nil {
+			s.logger.WithError(err).WithField("ipo_id", watch.id).Warn("Skipping result release probe of an IPO that could not be loaded")
+			continue
+		}
+
+		detectedBy, err := s.detect(ctx, ipo)
+		s.mutex.Lock()
+		s.lastProbes[watch.id] = now
+		s.mutex.Unlock()
+		if err != nil {
+			s.logger.WithError(err).WithField("ipo", ipo.Name).Warn("Result release probe failed")
+			continue
+		}
+		if detectedBy == "" {
+			continue
+		}
+		marked, err := s.markReleased(ctx, ipo, detectedBy, now)
+		if err != nil {
+			return released, err
+		}
+		if marked {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// watching returns the IPOs whose result date has arrived, whose results were not
+// detected yet and which have not listed
+func (s *ResultReleaseWatcher) watching(ctx context.Context, now time.Time) ([]watchedIPO, error) {
+	today := models.NewDate(now.In(istLocation())).String()
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, result_date FROM ipo_list
+		WHERE result_date IS NOT NULL AND result_date::date <= $1::date
+			AND results_released_at IS NULL
+			AND (listing_date IS NULL OR listing_date::date > $1::date)
+			AND deleted_at IS NULL
+		ORDER BY result_date
+	`, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPOs awaiting results: %w", err)
+	}
+	defer rows.Close()
+
+	var watched []watchedIPO
+	for rows.Next() {
+		var watch watchedIPO
+		var resultDate models.Date
+		if err := rows.Scan(&watch.id, &resultDate); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO awaiting results: %w", err)
+		}
+		watch.resultDate = resultDate.Time
+		watched = append(watched, watch)
+	}
+	return watched, rows.Err()
+}
+
+// due reports whether a watched IPO should be probed now: every Interval inside its
+// window, every SlowInterval after it
+func (s *ResultReleaseWatcher) due(watch watchedIPO, now time.Time) bool {
+	s.mutex.Lock()
+	last, probed := s.lastProbes[watch.id]
+	s.mutex.Unlock()
+	if !probed {
+		return true
+	}
+	interval := s.Interval
+	if !now.Before(watch.resultDate.Add(s.Window)) {
+		interval = s.SlowInterval
+	}
+	// A tick that lands a little early still counts
+	return now.Sub(last) >= interval-time.Minute
+}
+
+// detect looks for evidence that the IPO's results are out: a definitive result from an
+// earlier check, or else a registrar answer to the probe PAN other than "not declared".
+// Returns how the release was detected, or "" while results are not out.
+func (s *ResultReleaseWatcher) detect(ctx context.Context, ipo *models.IPO) (string, error) {
+	var checked bool
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM ipo_result_cache
+			WHERE ipo_id = $1 AND status IN ('ALLOTTED', 'NOT_ALLOTTED')
+		)
+	`, ipo.ID).Scan(&checked); err != nil {
+		return "", fmt.Errorf("failed to look up checked results: %w", err)
+	}
+	if checked {
+		return ResultReleaseDetectedByCheck, nil
+	}
+	if s.Checker == nil || s.ProbePAN == "" {
+		return "", nil
+	}
+
+	status, _, err := s.Checker.CheckAllotmentStatus(ctx, ipo, s.ProbePAN)
+	if err != nil {
+		return "", err
+	}
+	if status == models.AllotmentStatusNotDeclared {
+		return "", nil
+	}
+	return ResultReleaseDetectedByProbe, nil
+}
+
+// markReleased moves the IPO to RESULT_OUT and enqueues its outbox event in one
+// transaction, unless another run already did. Reports whether this run recorded it.
+func (s *ResultReleaseWatcher) markReleased(ctx context.Context, ipo *models.IPO, detectedBy string, now time.Time) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := loadIPOHistoryFields(ctx, tx, ipo.StockID)
+	if err != nil {
+		return false, err
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE ipo_list SET results_released_at = $2, status = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND results_released_at IS NULL
+	`, ipo.ID, now, models.IPOStatusResultOut)
+	if err != nil {
+		return false, fmt.Errorf("failed to record result release: %w", err)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return false, nil
+	}
+	after, err := loadIPOHistoryFields(ctx, tx, ipo.StockID)
+	if err != nil {
+		return false, err
+	}
+	if err := logIPOFieldChanges(ctx, tx, ipo.ID, before, after, ResultReleaseLogSource); err != nil {
+		return false, err
+	}
+	if err := EnqueueOutboxEvent(ctx, tx, models.EventResultsReleased, "ipo", ipo.ID.String(), map[string]interface{}{
+		"ipo_id":      ipo.ID,
+		"ipo_name":    ipo.Name,
+		"result_date": ipo.ResultDate,
+		"released_at": now,
+		"detected_by": detectedBy,
+	}); err != nil {
+		return false, err
+	}
+	// Every replica stops answering checks as not declared and serves the new status
+	if err := NotifyCacheInvalidation(ctx, tx, CacheInvalidation{Prefix: NotDeclaredKey(ipo.ID)}); err != nil {
+		return false, err
+	}
+	if err := NotifyCacheInvalidation(ctx, tx, CacheInvalidation{IPOID: ipo.ID.String()}); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit result release: %w", err)
+	}
+
+	s.mutex.Lock()
+	delete(s.lastProbes, ipo.ID)
+	s.mutex.Unlock()
+	s.logger.WithFields(logrus.Fields{"ipo": ipo.Name, "detected_by": detectedBy}).Info("Allotment results released")
+	return true, nil
+}
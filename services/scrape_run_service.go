@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// maxScrapeRunErrorSamples caps the error messages kept per run
+const maxScrapeRunErrorSamples = 10
+
+// ScrapeRunRecorder accumulates the totals, per-field stats and error samples of one
+// scraper run. It is used from a single job goroutine and is not safe for concurrent use.
+type ScrapeRunRecorder struct {
+	run models.ScrapeRun
+}
+
+// NewScrapeRunRecorder starts recording a run of jobName
+func NewScrapeRunRecorder(jobName string) *ScrapeRunRecorder {
+	return &ScrapeRunRecorder{run: models.ScrapeRun{
+		ID:           uuid.New(),
+		JobName:      jobName,
+		StartedAt:    time.Now(),
+		FieldStats:   make(map[string]models.ScrapeFieldStat),
+		ErrorSamples: []string{},
+	}}
+}
+
+// SetTotal records how many items the run will process
+func (r *ScrapeRunRecorder) SetTotal(total int) {
+	r.run.TotalItems = total
+}
+
+// RecordFields counts one extraction attempt for every tracked field
+func (r *ScrapeRunRecorder) RecordFields(populated, missing []string) {
+	for _, field := range populated {
+		stat := r.run.FieldStats[field]
+		stat.Attempts++
+		stat.Populated++
+		r.run.FieldStats[field] = stat
+	}
+	for _, field := range missing {
+		stat := r.run.FieldStats[field]
+		stat.Attempts++
+		r.run.FieldStats[field] = stat
+	}
+}
+
+// RecordSaved counts a saved item as a full or partial success along with its upsert outcome
+func (r *ScrapeRunRecorder) RecordSaved(complete bool, outcome string) {
+	if complete {
+		r.run.SuccessCount++
+	} else {
+		r.run.PartialCount++
+	}
+
+	switch outcome {
+	case models.UpsertCreated:
+		r.run.CreatedCount++
+	case models.UpsertUpdated:
+		r.run.UpdatedCount++
+	case models.UpsertUnchanged:
+		r.run.UnchangedCount++
+	}
+}
+
+// RecordFailure counts a failed item and keeps the first few error messages
+func (r *ScrapeRunRecorder) RecordFailure(item string, err error) {
+	r.run.FailureCount++
+	r.RecordError(item, err)
+}
+
+// RecordError keeps an error sample without counting an item failure
+func (r *ScrapeRunRecorder) RecordError(item string, err error) {
+	if err == nil || len(r.run.ErrorSamples) >= maxScrapeRunErrorSamples {
+		return
+	}
+	sample := err.Error()
+	if item != "" {
+		sample = item + ": " + sample
+	}
+	r.run.ErrorSamples = append(r.run.ErrorSamples, sample)
+}
+
+// Finish computes success rates and the run status. extraction may be nil.
+func (r *ScrapeRunRecorder) Finish(extraction *ExtractionMetrics) *models.ScrapeRun {
+	r.run.FinishedAt = time.Now()
+	r.run.DurationMs = r.run.FinishedAt.Sub(r.run.StartedAt).Milliseconds()
+
+	for field, stat := range r.run.FieldStats {
+		if stat.Attempts > 0 {
+			stat.SuccessRate = roundTo(float64(stat.Populated)/float64(stat.Attempts)*100, 1)
+		}
+		r.run.FieldStats[field] = stat
+	}
+
+	if extraction != nil {
+		counts := extraction.Snapshot()
+		r.run.ExtractionMetrics = map[string]interface{}{
+			"description_attempts":     counts.DescriptionAttempts,
+			"description_success":      counts.DescriptionSuccess,
+			"description_success_rate": successRate(counts.DescriptionSuccess, counts.DescriptionAttempts),
+			"about_attempts":           counts.AboutAttempts,
+			"about_success":            counts.AboutSuccess,
+			"about_success_rate":       successRate(counts.AboutSuccess, counts.AboutAttempts),
+			"html_parse_errors":        counts.HTMLParseErrors,
+			"text_cleaning_errors":     counts.TextCleaningErrors,
+		}
+	}
+
+	saved := r.run.SuccessCount + r.run.PartialCount
+	switch {
+	case saved == 0 && (r.run.FailureCount > 0 || len(r.run.ErrorSamples) > 0):
+		r.run.Status = models.ScrapeRunFailed
+	case r.run.FailureCount > 0 || saved < r.run.TotalItems:
+		r.run.Status = models.ScrapeRunPartial
+	default:
+		r.run.Status = models.ScrapeRunSucceeded
+	}
+
+	run := r.run
+	return &run
+}
+
+// successRate returns success/attempts as a percentage rounded to one decimal
+func successRate(success, attempts int) float64 {
+	if attempts == 0 {
+		return 0
+	}
+	return roundTo(float64(success)/float64(attempts)*100, 1)
+}
+
+// ScrapeRunService persists scraper run reports for operational review
+type ScrapeRunService struct {
+	db *sql.DB
+}
+
+// NewScrapeRunService creates a run report service
+func NewScrapeRunService(db *sql.DB) *ScrapeRunService {
+	return &ScrapeRunService{db: db}
+}
+
+// Save stores a finished run report
+func (s *ScrapeRunService) Save(ctx context.Context, run *models.ScrapeRun) error {
+	if s.db == nil {
+		return fmt.Errorf("database not available")
+	}
+
+	fieldStats, err := json.Marshal(run.FieldStats)
+	if err != nil {
+		return fmt.Errorf("failed to encode field stats: %w", err)
+	}
+	extraction, err := json.Marshal(run.ExtractionMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode extraction metrics: %w", err)
+	}
+	errorSamples, err := json.Marshal(run.ErrorSamples)
+	if err != nil {
+		return fmt.Errorf("failed to encode error samples: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO scrape_runs (
+			id, job_name, status, started_at, finished_at, duration_ms,
+			total_items, success_count, partial_count, failure_count,
+			created_count, updated_count, unchanged_count,
+			field_stats, extraction_metrics, error_samples
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`, run.ID, run.JobName, run.Status, run.StartedAt, run.FinishedAt, run.DurationMs,
+		run.TotalItems, run.SuccessCount, run.PartialCount, run.FailureCount,
+		run.CreatedCount, run.UpdatedCount, run.UnchangedCount,
+		string(fieldStats), string(extraction), string(errorSamples))
+	if err != nil {
+		return fmt.Errorf("failed to save scrape run: %w", err)
+	}
+	return nil
+}
+
+const scrapeRunColumns = `
+	id, job_name, status, started_at, finished_at, duration_ms,
+	total_items, success_count, partial_count, failure_count,
+	created_count, updated_count, unchanged_count,
+	field_stats, extraction_metrics, error_samples
+`
+
+// List returns the most recent runs, newest first, optionally filtered by job name
+func (s *ScrapeRunService) List(ctx context.Context, jobName string, limit int) ([]models.ScrapeRun, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+scrapeRunColumns+`
+		FROM scrape_runs
+		WHERE ($1 = '' OR job_name = $1)
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, jobName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.ScrapeRun{}
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// Latest returns the most recent run report of each job
+func (s *ScrapeRunService) Latest(ctx context.Context) ([]models.ScrapeRun, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (job_name) `+scrapeRunColumns+`
+		FROM scrape_runs
+		ORDER BY job_name, started_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.ScrapeRun{}
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// LastSucceeded returns when a job last finished a run that saved data (SUCCEEDED or
+// PARTIAL), or nil when it never has
+func (s *ScrapeRunService) LastSucceeded(ctx context.Context, jobName string) (*time.Time, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	var finishedAt sql.NullTime
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT MAX(finished_at) FROM scrape_runs
+		WHERE job_name = $1 AND status IN ($2, $3)
+	`, jobName, models.ScrapeRunSucceeded, models.ScrapeRunPartial).Scan(&finishedAt); err != nil {
+		return nil, fmt.Errorf("failed to query last successful run: %w", err)
+	}
+	if !finishedAt.Valid {
+		return nil, nil
+	}
+	return &finishedAt.Time, nil
+}
+
+// Get returns one run report
+func (s *ScrapeRunService) Get(ctx context.Context, id uuid.UUID) (*models.ScrapeRun, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+scrapeRunColumns+` FROM scrape_runs WHERE id = $1`, id)
+	run, err := scanScrapeRun(row)
+	if err == sql.ErrNoRows {
+		return nil, shared.NotFoundErrorf("scrape run %s not found", id)
+	}
+	return run, err
+}
+
+// scanScrapeRun scans a row selected with scrapeRunColumns
+func scanScrapeRun(row interface{ Scan(...interface{}) error }) (*models.ScrapeRun, error) {
+	var run models.ScrapeRun
+	var fieldStats, extraction, errorSamples []byte
+	if err := row.Scan(
+		&run.ID, &run.JobName, &run.Status, &run.StartedAt, &run.FinishedAt, &run.DurationMs,
+		&run.TotalItems, &run.SuccessCount, &run.PartialCount, &run.FailureCount,
+		&run.CreatedCount, &run.UpdatedCount, &run.UnchangedCount,
+		&fieldStats, &extraction, &errorSamples,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan scrape run: %w", err)
+	}
+
+	if err := json.Unmarshal(fieldStats, &run.FieldStats); err != nil {
+		return nil, fmt.Errorf("failed to decode field stats: %w", err)
+	}
+	if err := json.Unmarshal(extraction, &run.ExtractionMetrics); err != nil {
+		return nil, fmt.Errorf("failed to decode extraction metrics: %w", err)
+	}
+	if err := json.Unmarshal(errorSamples, &run.ErrorSamples); err != nil {
+		return nil, fmt.Errorf("failed to decode error samples: %w", err)
+	}
+	return &run, nil
+}
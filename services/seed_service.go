@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed fixtures/seed.json
+var seedFixturesJSON []byte
+
+// seedResultTTL keeps seeded allotment results cached long enough for a dev session
+const seedResultTTL = 30 * 24 * time.Hour
+
+// seedFixtures is the embedded dev dataset. Dates are day offsets from the seed date so
+// the past, live and upcoming IPOs stay in their lifecycle stage whenever the seed runs.
+type seedFixtures struct {
+	IPOs []seedIPO `json:"ipos"`
+}
+
+type seedIPO struct {
+	StockID            string       `json:"stock_id"`
+	Name               string       `json:"name"`
+	Symbol             string       `json:"symbol"`
+	Registrar          string       `json:"registrar"`
+	OpenOffsetDays     int          `json:"open_offset_days"`
+	CloseOffsetDays    int          `json:"close_offset_days"`
+	ResultOffsetDays   int          `json:"result_offset_days"`
+	ListingOffsetDays  int          `json:"listing_offset_days"`
+	PriceBandLow       float64      `json:"price_band_low"`
+	PriceBandHigh      float64      `json:"price_band_high"`
+	IssueSize          string       `json:"issue_size"`
+	MinQty             int          `json:"min_qty"`
+	SubscriptionStatus string       `json:"subscription_status"`
+	ListingGain        string       `json:"listing_gain"`
+	About              string       `json:"about"`
+	Strengths          []string     `json:"strengths"`
+	Risks              []string     `json:"risks"`
+	GMPSeries          []float64    `json:"gmp_series"` // Daily GMP values, oldest first; the last is today's
+	Results            []seedResult `json:"results"`
+}
+
+type seedResult struct {
+	PAN            string `json:"pan"` // Sample PAN; only its hash is stored
+	Status         string `json:"status"`
+	SharesAllotted int    `json:"shares_allotted"`
+}
+
+// SeedSummary counts the rows written by a seed run
+type SeedSummary struct {
+	IPOs          int `json:"ipos"`
+	GMPPoints     int `json:"gmp_points"`
+	CachedResults int `json:"cached_results"`
+}
+
+// SeedService populates a dev or staging database with the embedded fixture dataset
+// through the same write paths the scrapers and check endpoint use
+type SeedService struct {
+	DB      *sql.DB
+	IPOs    *IPOService
+	GMP     *SimpleGMPService
+	Results *CacheService
+}
+
+func NewSeedService(db *sql.DB) *SeedService {
+	return &SeedService{
+		DB:      db,
+		IPOs:    NewIPOService(db),
+		GMP:     NewSimpleGMPService(db),
+		Results: NewCacheService(db),
+	}
+}
+
+// loadSeedFixtures parses the embedded fixture file
+func loadSeedFixtures() (*seedFixtures, error) {
+	var fixtures seedFixtures
+	if err := json.Unmarshal(seedFixturesJSON, &fixtures); err != nil {
+		return nil, shared.ParseErrorf("invalid seed fixtures: %v", err)
+	}
+	return &fixtures, nil
+}
+
+// Seed replaces the fixture IPOs, their GMP history and cached results with a fresh copy
+// dated relative to now. Rows not from the fixtures are left untouched, so re-running the
+// seed always produces the same dataset.
+func (s *SeedService) Seed(ctx context.Context, now time.Time) (*SeedSummary, error) {
+	fixtures, err := loadSeedFixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reset(ctx, fixtures); err != nil {
+		return nil, err
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	summary := &SeedSummary{}
+	for _, fixture := range fixtures.IPOs {
+		ipo := fixture.toIPO(day, s.IPOs.UtilityService)
+		if err := s.IPOs.UpsertIPO(ctx, ipo); err != nil {
+			return summary, fmt.Errorf("failed to seed IPO %s: %w", fixture.StockID, err)
+		}
+		saved, err := s.IPOs.GetIPOByStockID(ctx, fixture.StockID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to load seeded IPO %s: %w", fixture.StockID, err)
+		}
+		if saved == nil {
+			return summary, shared.NotFoundErrorf("seeded IPO %s not found after upsert", fixture.StockID)
+		}
+		summary.IPOs++
+
+		points, err := s.seedGMPHistory(saved, fixture, now)
+		if err != nil {
+			return summary, err
+		}
+		summary.GMPPoints += points
+
+		for _, result := range fixture.Results {
+			if err := s.Results.StoreResult(ctx, &models.IPOResultCache{
+				PanHash:         shared.HashPAN(result.PAN),
+				IPOID:           saved.ID,
+				Status:          result.Status,
+				SharesAllotted:  result.SharesAllotted,
+				Source:          "seed",
+				Timestamp:       now,
+				ExpiresAt:       now.Add(seedResultTTL),
+				ConfidenceScore: 100,
+				SourceChannel:   models.SourceChannelAdmin,
+			}); err != nil {
+				return summary, fmt.Errorf("failed to seed result for %s: %w", fixture.StockID, err)
+			}
+			summary.CachedResults++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ipos":           summary.IPOs,
+		"gmp_points":     summary.GMPPoints,
+		"cached_results": summary.CachedResults,
+	}).Info("Seeded database from fixtures")
+	return summary, nil
+}
+
+// reset deletes previously seeded rows; cached results go with their IPO by cascade
+func (s *SeedService) reset(ctx context.Context, fixtures *seedFixtures) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin seed reset: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, fixture := range fixtures.IPOs {
+		for _, query := range []string{
+			`DELETE FROM ipo_gmp_history WHERE ipo_name = $1`,
+			`DELETE FROM ipo_gmp WHERE ipo_name = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, fixture.Name); err != nil {
+				return fmt.Errorf("failed to reset seeded GMP data: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM ipo_list WHERE stock_id = $1`, fixture.StockID); err != nil {
+			return fmt.Errorf("failed to reset seeded IPO: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// seedGMPHistory saves one GMP observation per day of the series, oldest first, so the
+// history table and the derived trend signals match what daily scraping would produce
+func (s *SeedService) seedGMPHistory(ipo *models.IPO, fixture seedIPO, now time.Time) (int, error) {
+	series := fixture.GMPSeries
+	if len(series) == 0 || ipo.PriceBandHigh == nil {
+		return 0, nil
+	}
+
+	gmpID := uuid.NewSHA1(uuid.NameSpaceURL, []byte(ipo.StockID)).String()
+	price := *ipo.PriceBandHigh
+	stockID := ipo.StockID
+	status := ipo.Status
+	subscription := optionalString(fixture.SubscriptionStatus)
+	listingGain := optionalString(fixture.ListingGain)
+	for i, gmpValue := range series {
+		gmp := models.EnhancedGMPData{
+			ID:                 gmpID,
+			IPOName:            ipo.Name,
+			CompanyCode:        ipo.CompanyCode,
+			IPOPrice:           price,
+			GMPValue:           gmpValue,
+			EstimatedListing:   price + gmpValue,
+			GainPercent:        math.Round(gmpValue/price*10000) / 100,
+			ListingDate:        ipo.ListingDate.TimePtr(),
+			LastUpdated:        now.AddDate(0, 0, i-len(series)+1),
+			StockID:            &stockID,
+			SubscriptionStatus: subscription,
+			ListingGain:        listingGain,
+			IPOStatus:          &status,
+			DataSource:         "seed",
+		}
+		if _, err := s.GMP.SaveGMPData([]models.EnhancedGMPData{gmp}); err != nil {
+			return i, fmt.Errorf("failed to seed GMP for %s: %w", ipo.StockID, err)
+		}
+	}
+	return len(series), nil
+}
+
+// toIPO builds the IPO row for a fixture, dating it relative to day
+func (f seedIPO) toIPO(day time.Time, utility *UtilityService) models.IPO {
+	offset := func(days int) *models.Date {
+		date := models.NewDate(day.AddDate(0, 0, days))
+		return &date
+	}
+	ipo := models.IPO{
+		StockID:            f.StockID,
+		Name:               f.Name,
+		Symbol:             optionalString(f.Symbol),
+		Registrar:          f.Registrar,
+		OpenDate:           offset(f.OpenOffsetDays),
+		CloseDate:          offset(f.CloseOffsetDays),
+		ResultDate:         offset(f.ResultOffsetDays),
+		ListingDate:        offset(f.ListingOffsetDays),
+		PriceBandLow:       &f.PriceBandLow,
+		PriceBandHigh:      &f.PriceBandHigh,
+		IssueSize:          optionalString(f.IssueSize),
+		MinQty:             &f.MinQty,
+		SubscriptionStatus: optionalString(f.SubscriptionStatus),
+		ListingGain:        optionalString(f.ListingGain),
+		About:              optionalString(f.About),
+	}
+	minAmount := int(math.Round(float64(f.MinQty) * f.PriceBandHigh))
+	ipo.MinAmount = &minAmount
+	ipo.Status = utility.CalculateIPOStatus(ipo.OpenDate, ipo.CloseDate, ipo.ListingDate)
+
+	if len(f.Strengths) > 0 {
+		ipo.Strengths, _ = json.Marshal(f.Strengths)
+	}
+	if len(f.Risks) > 0 {
+		ipo.Risks, _ = json.Marshal(f.Risks)
+	}
+	return ipo
+}
+
+// optionalString maps an empty fixture value to NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
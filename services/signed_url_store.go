@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SignedURLRedemptionStore records redeemed signed URL nonces in signed_url_redemptions,
+// so a URL works once across every replica
+type SignedURLRedemptionStore struct {
+	DB *sql.DB
+}
+
+func NewSignedURLRedemptionStore(db *sql.DB) *SignedURLRedemptionStore {
+	return &SignedURLRedemptionStore{DB: db}
+}
+
+// Redeem marks nonce used, reporting false when it already was. Nonces of expired URLs
+// are purged first: their signatures are rejected before reaching the store.
+func (s *SignedURLRedemptionStore) Redeem(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM signed_url_redemptions WHERE expires_at < NOW()`); err != nil {
+		return false, fmt.Errorf("failed to purge signed URL redemptions: %w", err)
+	}
+
+	result, err := s.DB.ExecContext(ctx, `
+		INSERT INTO signed_url_redemptions (nonce, expires_at, redeemed_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (nonce) DO NOTHING
+	`, nonce, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem signed URL: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem signed URL: %w", err)
+	}
+	return inserted == 1, nil
+}
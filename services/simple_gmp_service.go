@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -12,7 +13,9 @@ import (
 
 	"github.com/chromedp/chromedp"
 	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,6 +23,14 @@ import (
 type SimpleGMPService struct {
 	db     *sql.DB
 	logger *logrus.Logger
+
+	// OutlierThreshold is the percent deviation from the GMP's moving average above which
+	// a scraped value is rejected; DefaultGMPOutlierThreshold when zero
+	OutlierThreshold float64
+	// Corroborators are second GMP sources that can confirm a rejected jump
+	Corroborators []GMPCorroborator
+	// Clock is the time days to listing are counted from; DefaultClock when nil
+	Clock shared.Clock
 }
 
 // NewSimpleGMPService creates a new simple GMP service
@@ -46,6 +57,7 @@ type GMPScrapingResult struct {
 	ListingGain     string  `json:"listing_gain"`     // Listing gain percentage like "+15.2%" or "-5.8%"
 	RatingText      string  `json:"rating_text"`      // Raw rating text with fire emojis
 	SubscriptionRaw string  `json:"subscription_raw"` // Raw subscription text for better parsing
+	GMPParsed       bool    `json:"gmp_parsed"`       // GMP cell contained a number
 }
 
 // FetchGMPData scrapes GMP data from InvestorGain efficiently
@@ -229,7 +241,11 @@ func (s *SimpleGMPService) scrapeInvestorGainData() ([]GMPScrapingResult, error)
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("chromedp execution failed: %w", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			// The page loaded but the GMP table never rendered; usually a layout change
+			return nil, shared.UpstreamChangedErrorf("GMP table did not appear: %w", err)
+		}
+		return nil, shared.NetworkErrorf("chromedp execution failed: %w", err)
 	}
 
 	// Convert raw data to structured format
@@ -265,6 +281,7 @@ func (s *SimpleGMPService) scrapeInvestorGainData() ([]GMPScrapingResult, error)
 		// Parse GMP data
 		if gmpText, ok := item["gmpText"].(string); ok {
 			result.GMPValue, result.GMPPercentage = s.parseGMPString(gmpText)
+			result.GMPParsed = strings.ContainsAny(gmpText, "0123456789")
 		}
 
 		// Parse L/H data
@@ -346,9 +363,15 @@ func (s *SimpleGMPService) convertToEnhancedGMP(raw GMPScrapingResult, index int
 	}
 
 	// Create extraction metadata with all extracted fields
-	extractedFields := []string{"ipo_name", "gmp_value", "gain_percent"}
+	extractedFields := []string{"ipo_name"}
 	failedFields := []string{}
 
+	if raw.GMPParsed {
+		extractedFields = append(extractedFields, "gmp_value", "gain_percent")
+	} else {
+		failedFields = append(failedFields, "gmp_value")
+	}
+
 	if raw.GMPPercentage > 0 {
 		extractedFields = append(extractedFields, "ipo_price", "estimated_listing")
 	}
@@ -530,15 +553,17 @@ func (s *SimpleGMPService) calculateConfidence(raw GMPScrapingResult) float64 {
 	return confidence
 }
 
-// SaveGMPData saves GMP data to database efficiently
-func (s *SimpleGMPService) SaveGMPData(gmpList []models.EnhancedGMPData) error {
+// SaveGMPData saves GMP data to database efficiently. Rows whose content hash matches
+// the stored row are not rewritten; their checked_at is moved instead.
+func (s *SimpleGMPService) SaveGMPData(gmpList []models.EnhancedGMPData) (GMPSaveResult, error) {
+	var saved GMPSaveResult
 	if s.db == nil {
 		s.logger.Warn("Database not available, skipping save")
-		return nil
+		return saved, nil
 	}
 
 	if len(gmpList) == 0 {
-		return nil
+		return saved, nil
 	}
 
 	s.logger.WithField("records", len(gmpList)).Info("Saving GMP data to database")
@@ -546,60 +571,166 @@ func (s *SimpleGMPService) SaveGMPData(gmpList []models.EnhancedGMPData) error {
 	// Use transaction for efficiency
 	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return saved, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Prepare insert statement with all fields
+	// Rows with an unexpired manual override are left untouched
+	overrides, err := loadActiveGMPOverrides(context.Background(), tx)
+	if err != nil {
+		return saved, err
+	}
+	storedHashes, err := loadGMPContentHashes(context.Background(), tx)
+	if err != nil {
+		return saved, err
+	}
+
+	// Prepare insert statement with all fields; the CTE captures the previous
+	// GMP value so change events are only emitted when the value moves. The WHERE
+	// guard keeps an unexpired override even if its row is matched by name here.
+	// Fields an admin corrected (listed in admin_fields) keep their value, and
+	// sub2/kostak are only taken from the scrape when it actually has them. A
+	// rejected outlier keeps the providers and scrape time of the published GMP.
 	stmt, err := tx.Prepare(`
+		WITH previous AS (
+			SELECT gmp_value FROM ipo_gmp WHERE ipo_name = $2
+		)
 		INSERT INTO ipo_gmp (
 			id, ipo_name, company_code, ipo_price, gmp_value, 
 			estimated_listing, gain_percent, sub2, kostak, last_updated, 
 			data_source, stock_id, subscription_status, listing_gain, 
-			ipo_status, extraction_metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			ipo_status, extraction_metadata,
+			raw_gmp_value, smoothed_gmp_value, gmp_outlier_rejected,
+			gmp_providers, gmp_scraped_at, content_hash, checked_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $10)
 		ON CONFLICT (ipo_name) DO UPDATE SET
 			gmp_value = EXCLUDED.gmp_value,
 			gain_percent = EXCLUDED.gain_percent,
 			estimated_listing = EXCLUDED.estimated_listing,
-			subscription_status = EXCLUDED.subscription_status,
-			listing_gain = EXCLUDED.listing_gain,
+			subscription_status = CASE WHEN COALESCE(ipo_gmp.admin_fields, '{}') ? 'subscription_status'
+				THEN ipo_gmp.subscription_status ELSE EXCLUDED.subscription_status END,
+			listing_gain = CASE WHEN COALESCE(ipo_gmp.admin_fields, '{}') ? 'listing_gain'
+				THEN ipo_gmp.listing_gain ELSE EXCLUDED.listing_gain END,
+			sub2 = CASE WHEN COALESCE(ipo_gmp.admin_fields, '{}') ? 'sub2' OR EXCLUDED.sub2 = 0
+				THEN ipo_gmp.sub2 ELSE EXCLUDED.sub2 END,
+			kostak = CASE WHEN COALESCE(ipo_gmp.admin_fields, '{}') ? 'kostak' OR EXCLUDED.kostak = 0
+				THEN ipo_gmp.kostak ELSE EXCLUDED.kostak END,
 			ipo_status = EXCLUDED.ipo_status,
 			extraction_metadata = EXCLUDED.extraction_metadata,
-			last_updated = EXCLUDED.last_updated
+			raw_gmp_value = EXCLUDED.raw_gmp_value,
+			smoothed_gmp_value = EXCLUDED.smoothed_gmp_value,
+			gmp_outlier_rejected = EXCLUDED.gmp_outlier_rejected,
+			gmp_providers = CASE WHEN EXCLUDED.gmp_outlier_rejected
+				THEN ipo_gmp.gmp_providers ELSE EXCLUDED.gmp_providers END,
+			gmp_scraped_at = CASE WHEN EXCLUDED.gmp_outlier_rejected
+				THEN ipo_gmp.gmp_scraped_at ELSE EXCLUDED.gmp_scraped_at END,
+			data_source = EXCLUDED.data_source,
+			last_updated = EXCLUDED.last_updated,
+			content_hash = EXCLUDED.content_hash,
+			checked_at = EXCLUDED.checked_at,
+			is_manual_override = FALSE,
+			override_expires_at = NULL
+		WHERE NOT (ipo_gmp.is_manual_override = TRUE AND ipo_gmp.override_expires_at > NOW())
+		RETURNING (SELECT gmp_value FROM previous)
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return saved, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
 	// Insert/update records
+	var unchanged []string
 	for _, gmp := range gmpList {
+		if overrides.covers(gmp) {
+			s.logger.WithField("company", gmp.IPOName).Debug("Skipping GMP record with active manual override")
+			saved.Overridden++
+			continue
+		}
+
+		// Outliers keep the stored GMP and stay out of the history series
+		rejected, err := s.smoothGMP(context.Background(), tx, &gmp)
+		if err != nil {
+			return saved, err
+		}
+
+		// Rows the scrape found unchanged are neither rewritten nor added to the history
+		contentHash := GMPContentHash(gmp)
+		if storedHashes[gmp.IPOName] == contentHash {
+			unchanged = append(unchanged, gmp.IPOName)
+			continue
+		}
+
 		// Convert extraction metadata to JSON
 		var metadataJSON []byte
 		if gmp.ExtractionMetadata != nil {
 			metadataJSON, _ = json.Marshal(gmp.ExtractionMetadata)
 		}
 
-		_, err := stmt.Exec(
+		var previousGMP sql.NullFloat64
+		err = stmt.QueryRow(
 			gmp.ID, gmp.IPOName, gmp.CompanyCode, gmp.IPOPrice,
 			gmp.GMPValue, gmp.EstimatedListing, gmp.GainPercent,
 			gmp.Sub2, gmp.Kostak, gmp.LastUpdated, gmp.DataSource,
 			gmp.StockID, gmp.SubscriptionStatus, gmp.ListingGain,
 			gmp.IPOStatus, string(metadataJSON),
-		)
+			gmp.Smoothing.RawGMPValue, gmp.Smoothing.SmoothedGMPValue, gmp.Smoothing.OutlierRejected,
+			pq.Array(gmp.Attribution.Providers), gmp.Attribution.ScrapedAt, contentHash,
+		).Scan(&previousGMP)
+		if err == sql.ErrNoRows {
+			// Conflict row is under an active manual override
+			saved.Overridden++
+			continue
+		}
 		if err != nil {
 			s.logger.WithError(err).WithField("company", gmp.IPOName).Error("Failed to save GMP record")
+			saved.Failed++
 			continue
 		}
+		saved.Written++
+
+		if rejected {
+			continue
+		}
+
+		if err := s.recordGMPHistoryAndSignals(context.Background(), tx, gmp); err != nil {
+			return saved, err
+		}
+
+		if !previousGMP.Valid || previousGMP.Float64 != gmp.GMPValue {
+			payload := map[string]interface{}{
+				"ipo_name":     gmp.IPOName,
+				"company_code": gmp.CompanyCode,
+				"gmp_value":    gmp.GMPValue,
+				"gain_percent": gmp.GainPercent,
+				"ipo_price":    gmp.IPOPrice,
+				"last_updated": gmp.LastUpdated,
+			}
+			if previousGMP.Valid {
+				payload["previous_gmp_value"] = previousGMP.Float64
+			}
+			if err := EnqueueOutboxEvent(context.Background(), tx, models.EventGMPUpdated, "gmp", gmp.IPOName, payload); err != nil {
+				return saved, err
+			}
+		}
+	}
+
+	if err := markGMPChecked(context.Background(), tx, unchanged, time.Now()); err != nil {
+		return saved, err
 	}
+	saved.Unchanged = len(unchanged)
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return saved, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	s.logger.WithField("records", len(gmpList)).Info("Successfully saved GMP data")
-	return nil
+	s.logger.WithFields(logrus.Fields{
+		"records":    len(gmpList),
+		"written":    saved.Written,
+		"unchanged":  saved.Unchanged,
+		"overridden": saved.Overridden,
+		"failed":     saved.Failed,
+	}).Info("Successfully saved GMP data")
+	return saved, nil
 }
 
 // FetchAndSaveGMPData combines fetching and saving in one operation
@@ -609,7 +740,7 @@ func (s *SimpleGMPService) FetchAndSaveGMPData() ([]models.EnhancedGMPData, erro
 		return nil, err
 	}
 
-	if err := s.SaveGMPData(gmpData); err != nil {
+	if _, err := s.SaveGMPData(gmpData); err != nil {
 		s.logger.WithError(err).Warn("Failed to save GMP data, but returning scraped data")
 	}
 
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -19,10 +21,16 @@ import (
 
 // IPOScraperConfiguration holds configuration parameters for the IPO scraper service
 type IPOScraperConfiguration struct {
-	BaseURL            string        // Target website base URL
-	HTTPRequestTimeout time.Duration // Maximum time to wait for HTTP responses
-	RequestRateLimit   time.Duration // Minimum delay between consecutive requests
-	MaxRetryAttempts   int           // Maximum number of retry attempts for failed requests
+	BaseURL            string              // Target website base URL
+	HTTPRequestTimeout time.Duration       // Maximum time to wait for HTTP responses
+	RequestRateLimit   time.Duration       // Minimum delay between consecutive requests to a host
+	RequestBurst       int                 // Requests to a host allowed back to back before RequestRateLimit applies
+	MaxRetryAttempts   int                 // Maximum number of retry attempts for failed requests
+	RetryPolicy        *shared.RetryPolicy // Backoff policy; when nil the default HTTP policy with MaxRetryAttempts is used
+	IPOListMirrorURLs  []string            // Mirrors of the IPO list API, tried after the primary and before the HTML listing
+	HTTPCacheDir       string              // When set, GET responses are cached on disk here for local replays
+	HTTPCacheMaxAge    time.Duration       // Age after which disk cache entries are refetched; 0 keeps them forever
+	ShadowExtraction   string              // When set, this extraction strategy (json or html) also runs on each detail page and is compared with the primary
 }
 
 // NewDefaultIPOScraperConfiguration returns production-ready default configuration
@@ -31,6 +39,7 @@ func NewDefaultIPOScraperConfiguration() *IPOScraperConfiguration {
 		BaseURL:            "https://www.chittorgarh.com",
 		HTTPRequestTimeout: 30 * time.Second,
 		RequestRateLimit:   1 * time.Second,
+		RequestBurst:       1,
 		MaxRetryAttempts:   3,
 	}
 }
@@ -42,8 +51,8 @@ type HTMLDataExtractor struct {
 	// Stateless service for extracting structured data from HTML
 }
 
-// ExtractionMetrics tracks success rates and performance of HTML extraction
-type ExtractionMetrics struct {
+// ExtractionCounts is a point-in-time copy of the HTML extraction counters
+type ExtractionCounts struct {
 	DescriptionAttempts int
 	DescriptionSuccess  int
 	AboutAttempts       int
@@ -52,32 +61,95 @@ type ExtractionMetrics struct {
 	TextCleaningErrors  int
 }
 
+// ExtractionMetrics tracks success rates and performance of HTML extraction. It is safe
+// for concurrent use; read the counters through Snapshot.
+type ExtractionMetrics struct {
+	mutex  sync.Mutex
+	counts ExtractionCounts
+}
+
 // NewExtractionMetrics creates a new metrics tracker
 func NewExtractionMetrics() *ExtractionMetrics {
 	return &ExtractionMetrics{}
 }
 
+// RecordDescriptionAttempt records a description extraction attempt
+func (m *ExtractionMetrics) RecordDescriptionAttempt(success bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts.DescriptionAttempts++
+	if success {
+		m.counts.DescriptionSuccess++
+	}
+}
+
+// RecordAboutAttempt records an about extraction attempt
+func (m *ExtractionMetrics) RecordAboutAttempt(success bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts.AboutAttempts++
+	if success {
+		m.counts.AboutSuccess++
+	}
+}
+
+// RecordHTMLParseError records an HTML parsing error
+func (m *ExtractionMetrics) RecordHTMLParseError() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts.HTMLParseErrors++
+}
+
+// RecordTextCleaningError records a text cleaning error
+func (m *ExtractionMetrics) RecordTextCleaningError() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts.TextCleaningErrors++
+}
+
+// Snapshot returns a consistent copy of the counters
+func (m *ExtractionMetrics) Snapshot() ExtractionCounts {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.counts
+}
+
+// Reset sets every counter back to zero
+func (m *ExtractionMetrics) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts = ExtractionCounts{}
+}
+
 // LogSummary logs a summary of extraction metrics
 func (m *ExtractionMetrics) LogSummary() {
+	counts := m.Snapshot()
+
 	descriptionSuccessRate := 0.0
-	if m.DescriptionAttempts > 0 {
-		descriptionSuccessRate = float64(m.DescriptionSuccess) / float64(m.DescriptionAttempts) * 100
+	if counts.DescriptionAttempts > 0 {
+		descriptionSuccessRate = float64(counts.DescriptionSuccess) / float64(counts.DescriptionAttempts) * 100
 	}
 
 	aboutSuccessRate := 0.0
-	if m.AboutAttempts > 0 {
-		aboutSuccessRate = float64(m.AboutSuccess) / float64(m.AboutAttempts) * 100
+	if counts.AboutAttempts > 0 {
+		aboutSuccessRate = float64(counts.AboutSuccess) / float64(counts.AboutAttempts) * 100
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"description_attempts":     m.DescriptionAttempts,
-		"description_success":      m.DescriptionSuccess,
+		"description_attempts":     counts.DescriptionAttempts,
+		"description_success":      counts.DescriptionSuccess,
 		"description_success_rate": fmt.Sprintf("%.1f%%", descriptionSuccessRate),
-		"about_attempts":           m.AboutAttempts,
-		"about_success":            m.AboutSuccess,
+		"about_attempts":           counts.AboutAttempts,
+		"about_success":            counts.AboutSuccess,
 		"about_success_rate":       fmt.Sprintf("%.1f%%", aboutSuccessRate),
-		"html_parse_errors":        m.HTMLParseErrors,
-		"text_cleaning_errors":     m.TextCleaningErrors,
+		"html_parse_errors":        counts.HTMLParseErrors,
+		"text_cleaning_errors":     counts.TextCleaningErrors,
 	}).Info("HTML extraction metrics summary")
 }
 
@@ -336,6 +408,364 @@ func (extractor *HTMLDataExtractor) ExtractStatusInformation(document *goquery.D
 	return information
 }
 
+// ExtractAnchorAllocation extracts the anchor investor table (bid date, shares, amount and
+// lock-in end dates). Returns nil when the page has no anchor table or nothing parsed.
+func (extractor *HTMLDataExtractor) ExtractAnchorAllocation(document *goquery.Document) *models.AnchorAllocation {
+	// Scope to the anchor table so generic labels like "Shares Offered" in the
+	// subscription table are not picked up
+	var anchorTable *goquery.Selection
+	document.Find("table").EachWithBreak(func(_ int, table *goquery.Selection) bool {
+		text := strings.ToLower(table.Text())
+		if strings.Contains(text, "anchor") && strings.Contains(text, "lock-in") {
+			anchorTable = table
+			return false
+		}
+		return true
+	})
+	if anchorTable == nil {
+		return nil
+	}
+
+	allocation := &models.AnchorAllocation{}
+	found := false
+	anchorTable.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		label := strings.ToLower(extractor.normalizeTextContent(cells.Eq(0).Text()))
+		value := extractor.normalizeTextContent(cells.Eq(1).Text())
+
+		switch {
+		case strings.Contains(label, "lock-in") && (strings.Contains(label, "30 days") || strings.Contains(label, "50%")):
+			if date := extractor.parseStandardDateFormats(value); date != nil {
+				allocation.LockIn30Date = date
+				found = true
+			}
+		case strings.Contains(label, "lock-in") && (strings.Contains(label, "90 days") || strings.Contains(label, "remaining")):
+			if date := extractor.parseStandardDateFormats(value); date != nil {
+				allocation.LockIn90Date = date
+				found = true
+			}
+		case strings.Contains(label, "bid date"):
+			if date := extractor.parseStandardDateFormats(value); date != nil {
+				allocation.BidDate = date
+				found = true
+			}
+		case strings.Contains(label, "shares offered"):
+			if shares := extractor.parseNumericValueAsInteger(value); shares != nil {
+				allocation.SharesOffered = shares
+				found = true
+			}
+		case strings.Contains(label, "anchor") && (strings.Contains(label, "cr") || strings.Contains(label, "investment")):
+			amountText := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(value), "crore"), "cr"))
+			if amount := extractor.parseNumericValueAsFloat(amountText); amount != nil {
+				allocation.AmountCrore = amount
+				found = true
+			}
+		}
+	})
+
+	if !found {
+		return nil
+	}
+	return allocation
+}
+
+// ExtractTimetable extracts the IPO timetable table: each row's event, its date when the
+// value is a plain date, and the value as published. Returns nil when there is no table.
+func (extractor *HTMLDataExtractor) ExtractTimetable(document *goquery.Document) []models.TimetableEntry {
+	var timetable *goquery.Selection
+	document.Find("table").EachWithBreak(func(_ int, table *goquery.Selection) bool {
+		text := strings.ToLower(table.Text())
+		if strings.Contains(text, "basis of allotment") && strings.Contains(text, "listing date") {
+			timetable = table
+			return false
+		}
+		return true
+	})
+	if timetable == nil {
+		return nil
+	}
+
+	var entries []models.TimetableEntry
+	timetable.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		event := strings.Join(strings.Fields(cells.Eq(0).Text()), " ")
+		text := strings.Join(strings.Fields(cells.Eq(1).Text()), " ")
+		if event == "" || text == "" {
+			return
+		}
+		entries = append(entries, models.TimetableEntry{
+			Event: event,
+			Date:  models.DateOf(extractor.parseStandardDateFormats(text)),
+			Text:  text,
+		})
+	})
+	return entries
+}
+
+// ExtractIssueStructure extracts the fresh issue and offer for sale rows of the issue
+// details table. Returns nil when the page has neither row.
+func (extractor *HTMLDataExtractor) ExtractIssueStructure(document *goquery.Document) *models.IssueStructure {
+	structure := &models.IssueStructure{}
+	found := false
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		label := strings.ToLower(extractor.normalizeTextContent(cells.Eq(0).Text()))
+		value := extractor.normalizeTextContent(cells.Eq(1).Text())
+
+		var shares **int64
+		var amount **float64
+		switch {
+		case strings.HasPrefix(label, "fresh issue"):
+			shares, amount = &structure.FreshIssueShares, &structure.FreshIssueAmount
+		case strings.HasPrefix(label, "offer for sale"):
+			shares, amount = &structure.OFSShares, &structure.OFSAmount
+		default:
+			return
+		}
+		// The first matching row wins; later tables repeat the labels for reservations
+		if *shares != nil || *amount != nil {
+			return
+		}
+		parsedShares, parsedAmount := ParseIssueComponent(value)
+		if parsedShares != nil || parsedAmount != nil {
+			*shares, *amount = parsedShares, parsedAmount
+			found = true
+		}
+	})
+
+	if !found {
+		return nil
+	}
+	return structure
+}
+
+// holdingPercentPattern finds a shareholding percentage ("73.45%")
+var holdingPercentPattern = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)\s*%`)
+
+// ExtractPromoterHolding extracts the pre-issue and post-issue rows of the promoter
+// holding table. Returns nil when the page has no such table or no percentage parsed.
+func (extractor *HTMLDataExtractor) ExtractPromoterHolding(document *goquery.Document) *models.PromoterHolding {
+	var holdingTable *goquery.Selection
+	document.Find("table").EachWithBreak(func(_ int, table *goquery.Selection) bool {
+		// Pages write both "Pre Issue" and "Pre-Issue"
+		text := strings.ReplaceAll(strings.ToLower(table.Text()), "-", " ")
+		if strings.Contains(text, "pre issue") && strings.Contains(text, "post issue") && strings.Contains(text, "holding") {
+			holdingTable = table
+			return false
+		}
+		return true
+	})
+	if holdingTable == nil {
+		return nil
+	}
+
+	holding := &models.PromoterHolding{}
+	found := false
+	holdingTable.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		label := strings.ReplaceAll(strings.ToLower(extractor.normalizeTextContent(cells.Eq(0).Text())), "-", " ")
+		match := holdingPercentPattern.FindStringSubmatch(cells.Eq(1).Text())
+		if match == nil {
+			return
+		}
+		percent, err := strconv.ParseFloat(match[1], 64)
+		if err != nil || percent > 100 {
+			return
+		}
+
+		switch {
+		case strings.Contains(label, "pre issue") && holding.PreIssuePercent == nil:
+			holding.PreIssuePercent = &percent
+			found = true
+		case strings.Contains(label, "post issue") && holding.PostIssuePercent == nil:
+			holding.PostIssuePercent = &percent
+			found = true
+		}
+	})
+
+	if !found {
+		return nil
+	}
+	return holding
+}
+
+// faqJSONLD is the subset of a schema.org FAQPage block used for FAQ extraction
+type faqJSONLD struct {
+	Type       interface{} `json:"@type"`
+	MainEntity []struct {
+		Name           string `json:"name"`
+		AcceptedAnswer struct {
+			Text string `json:"text"`
+		} `json:"acceptedAnswer"`
+	} `json:"mainEntity"`
+}
+
+// ExtractFAQ extracts the FAQ section as question and answer pairs. The page's FAQPage
+// JSON-LD block is preferred; the visible accordion is the fallback.
+func (extractor *HTMLDataExtractor) ExtractFAQ(document *goquery.Document) []models.IPOFAQItem {
+	var items []models.IPOFAQItem
+	document.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, script *goquery.Selection) bool {
+		items = extractor.parseFAQJSONLD(script.Text())
+		return len(items) == 0
+	})
+	if len(items) > 0 {
+		return items
+	}
+
+	document.Find(".accordion-item").Each(func(_ int, item *goquery.Selection) {
+		question := strings.Join(strings.Fields(item.Find(".accordion-header, .accordion-button").First().Text()), " ")
+		answer := strings.Join(strings.Fields(item.Find(".accordion-body").First().Text()), " ")
+		if question != "" && answer != "" {
+			items = append(items, models.IPOFAQItem{Question: question, Answer: answer})
+		}
+	})
+	return items
+}
+
+// parseFAQJSONLD reads the questions of a JSON-LD block, which may hold one object or an
+// array of them; blocks that are not an FAQPage yield nothing
+func (extractor *HTMLDataExtractor) parseFAQJSONLD(text string) []models.IPOFAQItem {
+	var blocks []faqJSONLD
+	if err := json.Unmarshal([]byte(text), &blocks); err != nil {
+		var block faqJSONLD
+		if err := json.Unmarshal([]byte(text), &block); err != nil {
+			return nil
+		}
+		blocks = []faqJSONLD{block}
+	}
+
+	var items []models.IPOFAQItem
+	for _, block := range blocks {
+		if blockType, _ := block.Type.(string); blockType != "FAQPage" {
+			continue
+		}
+		for _, entity := range block.MainEntity {
+			question := strings.Join(strings.Fields(entity.Name), " ")
+			// Answers may carry inline HTML such as links and lists
+			answer := entity.AcceptedAnswer.Text
+			if fragment, err := goquery.NewDocumentFromReader(strings.NewReader(answer)); err == nil {
+				answer = fragment.Text()
+			}
+			answer = strings.Join(strings.Fields(answer), " ")
+			if question != "" && answer != "" {
+				items = append(items, models.IPOFAQItem{Question: question, Answer: answer})
+			}
+		}
+	}
+	return items
+}
+
+// Limits on extracted strengths and risks; longer items are usually a paragraph caught
+// by a loose list, not a bullet
+const (
+	maxReviewListItems   = 20
+	maxReviewItemLength  = 500
+	minReviewItemLength  = 3
+	maxReviewHeadingText = 60
+)
+
+// reviewListBullet matches the bullet or numbering some lists carry in their text
+var reviewListBullet = regexp.MustCompile(`^(?:[•·\-–—*▪►✓]+\s*|\(?(?:[0-9]{1,2}|[a-zA-Z])[.)]\s+)`)
+
+// ExtractStrengthsAndRisks extracts the bullet lists under the "Strengths" and
+// "Risks"/"Weaknesses" headings of a review or detail page, cleaned and deduplicated.
+// Either is nil when the page has no such list.
+func (extractor *HTMLDataExtractor) ExtractStrengthsAndRisks(document *goquery.Document) ([]string, []string) {
+	var strengths, risks []string
+	document.Find("h2, h3, h4, h5, strong, b").Each(func(_ int, heading *goquery.Selection) {
+		title := strings.ToLower(strings.Join(strings.Fields(heading.Text()), " "))
+		if title == "" || len(title) > maxReviewHeadingText {
+			return
+		}
+		switch {
+		case strengths == nil && strings.Contains(title, "strength"):
+			strengths = extractor.reviewList(heading)
+		case risks == nil && (strings.Contains(title, "risk") || strings.Contains(title, "weakness")):
+			risks = extractor.reviewList(heading)
+		}
+	})
+	return strengths, risks
+}
+
+// reviewList returns the items of the first list following heading, stopping at the
+// next section heading. Inline headings (strong, b) are looked up from their block.
+func (extractor *HTMLDataExtractor) reviewList(heading *goquery.Selection) []string {
+	block := heading
+	if goquery.NodeName(heading) == "strong" || goquery.NodeName(heading) == "b" {
+		if parent := heading.Parent(); parent.Length() > 0 && goquery.NodeName(parent) != "li" {
+			block = parent
+		}
+	}
+
+	var list *goquery.Selection
+	block.NextAll().EachWithBreak(func(_ int, sibling *goquery.Selection) bool {
+		switch goquery.NodeName(sibling) {
+		case "ul", "ol":
+			list = sibling
+			return false
+		case "h2", "h3", "h4", "h5":
+			return false
+		}
+		if nested := sibling.Find("ul, ol").First(); nested.Length() > 0 {
+			list = nested
+			return false
+		}
+		return true
+	})
+	if list == nil {
+		return nil
+	}
+
+	var items []string
+	seen := make(map[string]bool)
+	list.ChildrenFiltered("li").EachWithBreak(func(_ int, item *goquery.Selection) bool {
+		text := strings.Join(strings.Fields(item.Text()), " ")
+		text = strings.TrimSpace(reviewListBullet.ReplaceAllString(text, ""))
+		if len(text) < minReviewItemLength || len(text) > maxReviewItemLength {
+			return true
+		}
+		key := strings.TrimRight(strings.ToLower(text), ".;:, ")
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		items = append(items, text)
+		return len(items) < maxReviewListItems
+	})
+	return items
+}
+
+// ExtractReviewURL returns the absolute URL of the IPO review page linked from a detail
+// page, or "" when there is no link
+func (extractor *HTMLDataExtractor) ExtractReviewURL(document *goquery.Document, baseURL string) string {
+	href, _ := document.Find(`a[href*="/ipo_review/"]`).First().Attr("href")
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	reference, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(reference).String()
+}
+
 // Private helper methods for HTML data extraction and text processing
 
 // ExtractCompanyDescription extracts company description from HTML document
@@ -442,7 +872,6 @@ func (extractor *HTMLDataExtractor) ExtractCompanyDescription(document *goquery.
 
 	// Then remove standard boilerplate
 	cleanedText = extractor.removeBoilerplateTextWithLogging(cleanedText, "description")
-	cleanedText = extractor.truncateText(cleanedText, 2000)
 
 	// Validate minimum length and quality
 	if len(cleanedText) < 10 {
@@ -453,6 +882,10 @@ func (extractor *HTMLDataExtractor) ExtractCompanyDescription(document *goquery.
 		return nil
 	}
 
+	if !extractor.passesTextQuality("description", selectorUsed, cleanedText) {
+		return nil
+	}
+
 	logger.WithFields(logrus.Fields{
 		"final_text_length":  len(cleanedText),
 		"final_text_preview": extractor.truncateForLogging(cleanedText, 100),
@@ -576,7 +1009,6 @@ func (extractor *HTMLDataExtractor) ExtractCompanyAbout(document *goquery.Docume
 
 	// Then remove standard boilerplate
 	cleanedText = extractor.removeBoilerplateTextWithLogging(cleanedText, "about")
-	cleanedText = extractor.truncateText(cleanedText, 5000)
 
 	// Validate minimum length and quality
 	if len(cleanedText) < 10 {
@@ -587,6 +1019,10 @@ func (extractor *HTMLDataExtractor) ExtractCompanyAbout(document *goquery.Docume
 		return nil
 	}
 
+	if !extractor.passesTextQuality("about", selectorUsed, cleanedText) {
+		return nil
+	}
+
 	logger.WithFields(logrus.Fields{
 		"final_text_length":  len(cleanedText),
 		"final_text_preview": extractor.truncateForLogging(cleanedText, 100),
@@ -595,28 +1031,32 @@ func (extractor *HTMLDataExtractor) ExtractCompanyAbout(document *goquery.Docume
 	return &cleanedText
 }
 
-// extractTextFromSelectors attempts multiple CSS selectors and combines text from all matching elements
-func (extractor *HTMLDataExtractor) extractTextFromSelectors(document *goquery.Document, selectors []string) string {
-	var combinedText strings.Builder
-
-	for _, selector := range selectors {
-		document.Find(selector).Each(func(i int, s *goquery.Selection) {
-			text := strings.TrimSpace(s.Text())
-			if text != "" {
-				if combinedText.Len() > 0 {
-					combinedText.WriteString(" ")
-				}
-				combinedText.WriteString(text)
-			}
-		})
+// passesTextQuality classifies cleaned text and records the verdict for the selector that
+// produced it. Rejected text is dropped; flagged text is kept but logged for review.
+func (extractor *HTMLDataExtractor) passesTextQuality(field, selector, text string) bool {
+	result := defaultTextQualityClassifier.Classify(text)
+	DefaultTextQualityMetrics.Record(field, selector, result)
 
-		// If we found content with this selector, return it
-		if combinedText.Len() > 0 {
-			return combinedText.String()
-		}
+	if result.Verdict == TextQualityAccept {
+		return true
 	}
 
-	return ""
+	logger := logrus.WithFields(logrus.Fields{
+		"component":         "HTMLDataExtractor",
+		"field":             field,
+		"selector_used":     selector,
+		"quality_score":     result.Score,
+		"quality_reasons":   result.Reasons,
+		"stopword_ratio":    result.StopwordRatio,
+		"nav_keyword_ratio": result.NavKeywordRatio,
+		"text_preview":      extractor.truncateForLogging(text, 100),
+	})
+	if result.Verdict == TextQualityReject {
+		logger.Warn("Rejecting low-quality extracted text")
+		return false
+	}
+	logger.Info("Keeping extracted text flagged as low quality")
+	return true
 }
 
 // extractTextFromSelectorsWithLogging attempts multiple CSS selectors with detailed logging
@@ -676,30 +1116,6 @@ func (extractor *HTMLDataExtractor) truncateForLogging(text string, maxLength in
 	return text[:maxLength] + "..."
 }
 
-// cleanCompanyText normalizes and cleans extracted text content
-func (extractor *HTMLDataExtractor) cleanCompanyText(text string) string {
-	if text == "" {
-		return ""
-	}
-
-	// Remove HTML tags if any remain
-	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
-	text = htmlTagRegex.ReplaceAllString(text, "")
-
-	// Normalize whitespace
-	whitespaceRegex := regexp.MustCompile(`\s+`)
-	text = whitespaceRegex.ReplaceAllString(text, " ")
-
-	// Remove leading and trailing whitespace
-	text = strings.TrimSpace(text)
-
-	// Handle UTF-8 encoding issues by removing non-printable characters
-	printableRegex := regexp.MustCompile(`[^\x20-\x7E\p{L}\p{N}\p{P}\p{S}]`)
-	text = printableRegex.ReplaceAllString(text, "")
-
-	return text
-}
-
 // cleanCompanyTextWithErrorHandling normalizes and cleans extracted text content with comprehensive error handling
 func (extractor *HTMLDataExtractor) cleanCompanyTextWithErrorHandling(text string, fieldType string) (string, error) {
 	logger := logrus.WithFields(logrus.Fields{
@@ -765,134 +1181,8 @@ func (extractor *HTMLDataExtractor) removeNavigationElements(text string) string
 		return ""
 	}
 
-	// Navigation elements specific to Chittorgarh pages - using more aggressive patterns
-	navigationPatterns := []string{
-		// Remove dashboard and navigation elements (anywhere in text)
-		`(?i)\bdashboard\s*ipo\s*list\b`,
-		`(?i)\bipo\s*list\s*ipo\s*list\b`,
-		`(?i)\bdashboard\b`,
-		`(?i)\bipo\s*list\b`,
-
-		// Remove IPO details navigation (anywhere in text)
-		`(?i)\bipo\s*details\b`,
-		`(?i)\bbookbuilding\s*ipo\b`,
-		`(?i)\|\s*₹\d+\s*cr\s*\|`,
-		`(?i)₹\d+\s*cr\b`,
-
-		// Remove common navigation links (anywhere in text)
-		`(?i)\bmessages\b`,
-		`(?i)\bgmp\b`,
-		`(?i)\bdocs\b`,
-		`(?i)\brhp\b`,
-		`(?i)\bdrhp\b`,
-		`(?i)\banchor\s*investor\s*link\b`,
-		`(?i)\bsubscription\b`,
-		`(?i)\breviews\b`,
-		`(?i)\ballotment\b`,
-		`(?i)\bstock\s*price\b`,
-		`(?i)\bfinal\s*prospectus\b`,
-
-		// Remove listing information (anywhere in text)
-		`(?i)\blisting\s*at\s*bse\b`,
-		`(?i)\blisting\s*at\s*nse\b`,
-		`(?i)\blisted\s*at\s*bse\b`,
-		`(?i)\blisted\s*at\s*nse\b`,
-		`(?i)\bbse\s*nse\b`,
-		`(?i)\bnse\s*bse\b`,
-
-		// Remove additional navigation elements found in testing
-		`(?i)\bipo\s*news\b`,
-		`(?i)\bipo\s*calendar\b`,
-		`(?i)\bipo\s*performance\b`,
-		`(?i)\bipo\s*analysis\b`,
-		`(?i)\bipo\s*rating\b`,
-		`(?i)\bipo\s*recommendation\b`,
-		`(?i)\bipo\s*apply\b`,
-		`(?i)\bapply\s*online\b`,
-		`(?i)\bipo\s*forms\b`,
-		`(?i)\bipo\s*documents\b`,
-
-		// Remove menu and navigation text
-		`(?i)\bmenu\b`,
-		`(?i)\bnavigation\b`,
-		`(?i)\bhome\b`,
-		`(?i)\bback\s*to\s*top\b`,
-		`(?i)\bshare\s*this\b`,
-		`(?i)\bprint\s*this\b`,
-		`(?i)\bemail\s*this\b`,
-
-		// Remove common separators and formatting (anywhere in text)
-		`(?i)\s*\|\s*`,
-		`(?i)\s*-\s*`,
-		`(?i)\s*•\s*`,
-		`(?i)\s*→\s*`,
-		`(?i)\s*»\s*`,
-
-		// Remove standalone numbers and currency amounts that are navigation artifacts
-		`(?i)^\s*\d+\s*$`,
-		`(?i)^\s*₹\s*\d+\s*$`,
-		`(?i)^\s*rs\.?\s*\d+\s*$`,
-
-		// Remove common call-to-action phrases
-		`(?i)\bclick\s*here\b`,
-		`(?i)\bread\s*more\b`,
-		`(?i)\bmore\s*details\b`,
-		`(?i)\bview\s*details\b`,
-		`(?i)\bsee\s*more\b`,
-		`(?i)\blearn\s*more\b`,
-		`(?i)\bfind\s*out\s*more\b`,
-
-		// Remove date and time artifacts
-		`(?i)\bupdated\s*on\b`,
-		`(?i)\bpublished\s*on\b`,
-		`(?i)\blast\s*updated\b`,
-		`(?i)\bposted\s*on\b`,
-	}
-
-	for _, pattern := range navigationPatterns {
-		regex := regexp.MustCompile(pattern)
-		text = regex.ReplaceAllString(text, " ")
-	}
-
-	// Clean up multiple spaces and trim
-	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
-	text = strings.TrimSpace(text)
-
-	return text
-}
-
-// removeBoilerplateText removes common boilerplate phrases from extracted text
-func (extractor *HTMLDataExtractor) removeBoilerplateText(text string) string {
-	if text == "" {
-		return ""
-	}
-
-	// Common boilerplate phrases to remove
-	boilerplatePatterns := []string{
-		`(?i)^company description:\s*`,
-		`(?i)^about us:\s*`,
-		`(?i)^about the company:\s*`,
-		`(?i)^business overview:\s*`,
-		`(?i)^company details:\s*`,
-		`(?i)^business model:\s*`,
-		`(?i)^about:\s*`,
-		`(?i)\s*read more\s*$`,
-		`(?i)\s*click here for more\s*$`,
-		`(?i)\s*more details\s*$`,
-	}
-
-	for _, pattern := range boilerplatePatterns {
-		regex := regexp.MustCompile(pattern)
-		text = regex.ReplaceAllString(text, "")
-	}
-
-	// Ensure proper punctuation at the end
-	text = strings.TrimSpace(text)
-	if text != "" && !strings.HasSuffix(text, ".") && !strings.HasSuffix(text, "!") && !strings.HasSuffix(text, "?") {
-		text += "."
-	}
-
-	return text
+	// Patterns are managed through /admin/scraper/patterns
+	return DefaultTextPatterns.RemoveNavigation(text)
 }
 
 // removeBoilerplateTextWithLogging removes common boilerplate phrases with detailed logging
@@ -911,37 +1201,13 @@ func (extractor *HTMLDataExtractor) removeBoilerplateTextWithLogging(text string
 	originalText := text
 	originalLength := len(text)
 
-	// Common boilerplate phrases to remove
-	boilerplatePatterns := []string{
-		`(?i)^company description:\s*`,
-		`(?i)^about us:\s*`,
-		`(?i)^about the company:\s*`,
-		`(?i)^business overview:\s*`,
-		`(?i)^company details:\s*`,
-		`(?i)^business model:\s*`,
-		`(?i)^about:\s*`,
-		`(?i)\s*read more\s*$`,
-		`(?i)\s*click here for more\s*$`,
-		`(?i)\s*more details\s*$`,
-	}
-
 	patternsMatched := 0
-	for i, pattern := range boilerplatePatterns {
-		regex, err := regexp.Compile(pattern)
-		if err != nil {
-			logger.WithFields(logrus.Fields{
-				"pattern_index": i,
-				"pattern":       pattern,
-				"error":         err,
-			}).Warn("Failed to compile boilerplate regex pattern")
-			continue
-		}
-
+	for _, regex := range DefaultTextPatterns.Boilerplate() {
 		if regex.MatchString(text) {
 			patternsMatched++
 			text = regex.ReplaceAllString(text, "")
 			logger.WithFields(logrus.Fields{
-				"pattern":     pattern,
+				"pattern":     regex.String(),
 				"text_before": extractor.truncateForLogging(originalText, 50),
 				"text_after":  extractor.truncateForLogging(text, 50),
 			}).Debug("Removed boilerplate pattern")
@@ -966,24 +1232,6 @@ func (extractor *HTMLDataExtractor) removeBoilerplateTextWithLogging(text string
 	return text
 }
 
-// truncateText truncates text to specified maximum length with ellipsis
-func (extractor *HTMLDataExtractor) truncateText(text string, maxLength int) string {
-	if text == "" || len(text) <= maxLength {
-		return text
-	}
-
-	// Find the last space before the max length to avoid cutting words
-	truncateAt := maxLength
-	for i := maxLength - 1; i >= maxLength-50 && i >= 0; i-- {
-		if text[i] == ' ' {
-			truncateAt = i
-			break
-		}
-	}
-
-	return text[:truncateAt] + "..."
-}
-
 // extractTextUsingSelectors attempts multiple CSS selectors and returns the first non-empty result
 func (extractor *HTMLDataExtractor) extractTextUsingSelectors(document *goquery.Document, selectors ...string) string {
 	for _, selector := range selectors {
@@ -1031,11 +1279,13 @@ func (extractor *HTMLDataExtractor) parseStandardDateFormats(dateText string) *t
 		"02/01/2006",           // DD/MM/YYYY
 		"2/1/2006",             // D/M/YYYY
 		"Jan 02, 2006",         // Mon DD, YYYY
+		"Jan 2, 2006",          // Mon D, YYYY
 		"January 02, 2006",     // Month DD, YYYY
 		"02 Jan 2006",          // DD Mon YYYY
 		"02 January 2006",      // DD Month YYYY
 		"2006-01-02",           // YYYY-MM-DD (ISO format)
 		"Mon, Jan 02, 2006",    // Day, Mon DD, YYYY
+		"Mon, Jan 2, 2006",     // Day, Mon D, YYYY
 		"Monday, Jan 02, 2006", // Weekday, Mon DD, YYYY
 	}
 
@@ -1200,6 +1450,12 @@ type ChittorgarhIPOScrapingService struct {
 	utilityService     *UtilityService
 	configuration      *IPOScraperConfiguration
 	extractionMetrics  *ExtractionMetrics
+	ipoListFailover    *IPOListFailover
+	extractionShadow   *ExtractionShadow
+
+	// ExtractionOverrides, when set, supplies per-IPO extraction overrides for pages the
+	// generic extractor gets wrong
+	ExtractionOverrides ExtractionOverrideSource
 }
 
 // NewChittorgarhIPOScrapingService creates a new IPO scraping service with the specified configuration
@@ -1244,15 +1500,42 @@ func NewChittorgarhIPOScrapingService(config *IPOScraperConfiguration) *Chittorg
 		},
 	}
 
-	return &ChittorgarhIPOScrapingService{
+	if config.HTTPCacheDir != "" {
+		httpClient.Transport = shared.NewDiskCacheTransport(httpClient.Transport, config.HTTPCacheDir, config.HTTPCacheMaxAge)
+		logrus.WithFields(logrus.Fields{
+			"cache_dir": config.HTTPCacheDir,
+			"max_age":   config.HTTPCacheMaxAge,
+		}).Warn("Scraper HTTP disk cache enabled; responses may be stale")
+	}
+
+	service := &ChittorgarhIPOScrapingService{
 		baseURL:            config.BaseURL,
 		httpClient:         httpClient,
-		requestRateLimiter: shared.NewHTTPRequestRateLimiter(config.RequestRateLimit),
+		requestRateLimiter: shared.NewHTTPRequestRateLimiterWithBurst(config.RequestRateLimit, config.RequestBurst),
 		htmlDataExtractor:  NewHTMLDataExtractor(),
 		utilityService:     NewUtilityService(),
 		configuration:      config,
 		extractionMetrics:  NewExtractionMetrics(),
 	}
+
+	// The list API occasionally 403s for hours, so fall back to mirrors and then the HTML listing
+	sources := []IPOListSource{&ipoListAPISource{name: "api", url: DefaultIPOListAPIURL, service: service}}
+	for i, mirrorURL := range config.IPOListMirrorURLs {
+		sources = append(sources, &ipoListAPISource{name: fmt.Sprintf("mirror-%d", i+1), url: mirrorURL, service: service})
+	}
+	sources = append(sources, &ipoListHTMLSource{name: "html-listing", url: config.BaseURL + DefaultIPOListHTMLPath, service: service})
+	service.ipoListFailover = NewIPOListFailover(sources...)
+
+	switch config.ShadowExtraction {
+	case "":
+	case ExtractionStrategyJSON, ExtractionStrategyHTML:
+		service.extractionShadow = NewExtractionShadow(config.ShadowExtraction)
+		logrus.WithField("strategy", config.ShadowExtraction).Info("Shadow extraction enabled for IPO detail pages")
+	default:
+		logrus.WithField("strategy", config.ShadowExtraction).Warn("Unknown shadow extraction strategy, shadow extraction disabled")
+	}
+
+	return service
 }
 
 // ChittorgarhIPOListItem represents an individual IPO entry from the Chittorgarh API response
@@ -1263,19 +1546,31 @@ type ChittorgarhIPOListItem struct {
 	LogoURL              string `json:"logo_url"`
 }
 
-// FetchAvailableIPOList retrieves the complete list of IPOs from Chittorgarh's internal API
-func (service *ChittorgarhIPOScrapingService) FetchAvailableIPOList() ([]ChittorgarhIPOListItem, error) {
-	apiEndpointURL := "https://webnodejs.chittorgarh.com/cloud/ipo/list-read"
+// FetchAvailableIPOList retrieves the complete list of IPOs, failing over from Chittorgarh's
+// internal API to configured mirrors and the HTML listing page
+func (service *ChittorgarhIPOScrapingService) FetchAvailableIPOList(ctx context.Context) ([]ChittorgarhIPOListItem, error) {
+	return service.ipoListFailover.Fetch(ctx)
+}
+
+// IPOListSourceHealth reports the failover state of each IPO list source
+func (service *ChittorgarhIPOScrapingService) IPOListSourceHealth() []IPOListSourceHealth {
+	return service.ipoListFailover.Health()
+}
 
-	// Enforce rate limiting before making the request
-	service.requestRateLimiter.EnforceRateLimit()
+// fetchIPOListFromAPI retrieves the IPO list from Chittorgarh's JSON list API or a mirror of it
+func (service *ChittorgarhIPOScrapingService) fetchIPOListFromAPI(ctx context.Context, apiEndpointURL string) ([]ChittorgarhIPOListItem, error) {
 
 	// Create HTTP request with appropriate headers
-	httpRequest, requestError := http.NewRequest("GET", apiEndpointURL, nil)
+	httpRequest, requestError := http.NewRequestWithContext(ctx, "GET", apiEndpointURL, nil)
 	if requestError != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", requestError)
 	}
 
+	// Enforce per-host rate limiting before making the request
+	if waitError := service.requestRateLimiter.WaitForHost(ctx, httpRequest.URL.Host); waitError != nil {
+		return nil, fmt.Errorf("rate limit wait cancelled: %w", waitError)
+	}
+
 	// Set browser-like headers to avoid detection as automated scraper
 	service.setBrowserLikeHeaders(httpRequest, "application/json, text/plain, */*")
 
@@ -1294,19 +1589,19 @@ func (service *ChittorgarhIPOScrapingService) FetchAvailableIPOList() ([]Chittor
 	}
 
 	if jsonParseError := json.NewDecoder(httpResponse.Body).Decode(&apiResponse); jsonParseError != nil {
-		return nil, fmt.Errorf("failed to parse IPO list JSON response: %w", jsonParseError)
+		return nil, shared.ParseErrorf("failed to parse IPO list JSON response: %w", jsonParseError)
 	}
 
 	// Validate API response structure and content
 	if apiResponse.Status == 0 && len(apiResponse.IPODropDownList) == 0 {
-		return nil, fmt.Errorf("API returned empty response with status code: %d", apiResponse.Status)
+		return nil, shared.UpstreamChangedErrorf("API returned empty response with status code: %d", apiResponse.Status)
 	}
 
 	return apiResponse.IPODropDownList, nil
 }
 
 // ScrapeDetailedIPOInformation extracts comprehensive IPO data from a specific IPO detail page
-func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoListItem ChittorgarhIPOListItem) (*models.IPO, error) {
+func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ctx context.Context, ipoListItem ChittorgarhIPOListItem) (*models.IPO, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"component": "ChittorgarhIPOScrapingService",
 		"method":    "ScrapeDetailedIPOInformation",
@@ -1316,20 +1611,22 @@ func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoLi
 
 	logger.Info("Starting detailed IPO information scraping")
 
-	// Enforce rate limiting before making the request
-	service.requestRateLimiter.EnforceRateLimit()
-
 	// Construct URL for the IPO detail page - use the correct Chittorgarh URL format
 	ipoDetailPageURL := fmt.Sprintf("%s/ipo/%s/%d/", service.baseURL, ipoListItem.URLRewriteFolderName, ipoListItem.ID)
 	logger.WithField("url", ipoDetailPageURL).Debug("Constructed IPO detail page URL")
 
 	// Create HTTP request with appropriate headers
-	httpRequest, requestError := http.NewRequest("GET", ipoDetailPageURL, nil)
+	httpRequest, requestError := http.NewRequestWithContext(ctx, "GET", ipoDetailPageURL, nil)
 	if requestError != nil {
 		logger.WithError(requestError).Error("Failed to create HTTP request")
 		return nil, fmt.Errorf("failed to create HTTP request for IPO %d: %w", ipoListItem.ID, requestError)
 	}
 
+	// Enforce per-host rate limiting before making the request
+	if waitError := service.requestRateLimiter.WaitForHost(ctx, httpRequest.URL.Host); waitError != nil {
+		return nil, fmt.Errorf("rate limit wait cancelled for IPO %d: %w", ipoListItem.ID, waitError)
+	}
+
 	// Set browser-like headers for HTML content
 	service.setBrowserLikeHeaders(httpRequest, "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 
@@ -1350,7 +1647,7 @@ func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoLi
 	if readError != nil {
 		logger.WithError(readError).Error("Failed to read response body")
 		partialIPOData := service.createPartialIPOFromListItem(ipoListItem)
-		return partialIPOData, fmt.Errorf("failed to read response body for IPO %d: %w", ipoListItem.ID, readError)
+		return partialIPOData, shared.NetworkErrorf("failed to read response body for IPO %d: %w", ipoListItem.ID, readError)
 	}
 
 	bodyText := string(bodyBytes)
@@ -1360,33 +1657,23 @@ func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoLi
 	htmlDocument, parseError := goquery.NewDocumentFromReader(strings.NewReader(bodyText))
 	if parseError != nil {
 		logger.WithError(parseError).Error("Failed to parse HTML document")
-		service.extractionMetrics.HTMLParseErrors++
+		service.extractionMetrics.RecordHTMLParseError()
 		partialIPOData := service.createPartialIPOFromListItem(ipoListItem)
-		return partialIPOData, fmt.Errorf("failed to parse HTML document for IPO %d: %w", ipoListItem.ID, parseError)
+		return partialIPOData, shared.ParseErrorf("failed to parse HTML document for IPO %d: %w", ipoListItem.ID, parseError)
 	}
 
 	logger.Debug("Successfully parsed HTML document")
 
 	// Try to extract JSON data from the JavaScript embedded in the page
+	fetchedAt := time.Now()
+	primaryStrategy := ExtractionStrategyJSON
 	ipoData, jsonError := service.extractIPODataFromJSONWithLogging(bodyText, ipoListItem, htmlDocument)
 	if jsonError != nil {
 		logger.WithError(jsonError).Warn("JSON extraction failed, falling back to HTML parsing")
+		shared.DefaultErrorCounter.Record("ipo_json_extraction", jsonError)
 		// Fallback to HTML parsing if JSON extraction fails
-		// Extract structured data from HTML document (fallback method)
-		basicInformation := service.htmlDataExtractor.ExtractBasicInformation(htmlDocument)
-		dateInformation := service.htmlDataExtractor.ExtractDateInformation(htmlDocument)
-		pricingInformation := service.htmlDataExtractor.ExtractPricingInformation(htmlDocument)
-		statusInformation := service.htmlDataExtractor.ExtractStatusInformation(htmlDocument)
-
-		// Create comprehensive IPO model from extracted data
-		ipoData = service.buildIPOModelFromExtractedDataWithLogging(
-			ipoListItem,
-			basicInformation,
-			dateInformation,
-			pricingInformation,
-			statusInformation,
-			htmlDocument,
-		)
+		primaryStrategy = ExtractionStrategyHTML
+		ipoData = service.extractIPODataFromHTML(ipoListItem, htmlDocument)
 	} else {
 		logger.Info("Successfully extracted IPO data from JSON")
 		// Even if JSON extraction succeeded, try to get additional fields from HTML
@@ -1395,13 +1682,29 @@ func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoLi
 		// Enhance JSON data with HTML-extracted fields
 		if statusInformation.SubscriptionStatus != nil && ipoData.SubscriptionStatus == nil {
 			ipoData.SubscriptionStatus = statusInformation.SubscriptionStatus
+			setLineage(ipoData, models.LineageSourceHTML, fetchedAt, "subscription_status")
 			logger.Debug("Enhanced JSON data with subscription status from HTML")
 		}
 		if statusInformation.ListingPerformance != nil && ipoData.ListingGain == nil {
 			ipoData.ListingGain = statusInformation.ListingPerformance
+			setLineage(ipoData, models.LineageSourceHTML, fetchedAt, "listing_gain")
 			logger.Debug("Enhanced JSON data with listing performance from HTML")
 		}
 	}
+	tagLineage(ipoData, primaryStrategy, fetchedAt)
+	service.runShadowExtraction(primaryStrategy, ipoData, bodyText, ipoListItem, htmlDocument)
+	// The override applies after the shadow comparison, which is about the extractors
+	service.applyExtractionOverride(ctx, ipoData, htmlDocument, fetchedAt, logger)
+
+	// Anchor allocation, the timetable, the issue structure, the promoter holding and the
+	// FAQ are only published as HTML, for both extraction paths
+	ipoData.AnchorAllocation = service.htmlDataExtractor.ExtractAnchorAllocation(htmlDocument)
+	ipoData.Timetable = service.htmlDataExtractor.ExtractTimetable(htmlDocument)
+	ipoData.IssueStructure = service.htmlDataExtractor.ExtractIssueStructure(htmlDocument)
+	ipoData.PromoterHolding = service.htmlDataExtractor.ExtractPromoterHolding(htmlDocument)
+	ipoData.FAQ = service.htmlDataExtractor.ExtractFAQ(htmlDocument)
+	service.attachStrengthsAndRisks(ctx, ipoData, htmlDocument, logger)
+	tagLineage(ipoData, models.LineageSourceHTML, fetchedAt)
 
 	logger.WithFields(logrus.Fields{
 		"ipo_name":        ipoData.Name,
@@ -1413,150 +1716,172 @@ func (service *ChittorgarhIPOScrapingService) ScrapeDetailedIPOInformation(ipoLi
 	return ipoData, nil
 }
 
-// Private helper methods for HTTP request handling and data processing
-
-// setBrowserLikeHeaders configures HTTP request headers to mimic browser behavior
-func (service *ChittorgarhIPOScrapingService) setBrowserLikeHeaders(request *http.Request, acceptHeader string) {
-	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	request.Header.Set("Accept", acceptHeader)
-	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	request.Header.Set("Cache-Control", "no-cache")
+// extractIPODataFromHTML builds the IPO from the detail page's HTML tables
+func (service *ChittorgarhIPOScrapingService) extractIPODataFromHTML(ipoListItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) *models.IPO {
+	basicInformation := service.htmlDataExtractor.ExtractBasicInformation(htmlDocument)
+	dateInformation := service.htmlDataExtractor.ExtractDateInformation(htmlDocument)
+	pricingInformation := service.htmlDataExtractor.ExtractPricingInformation(htmlDocument)
+	statusInformation := service.htmlDataExtractor.ExtractStatusInformation(htmlDocument)
+
+	// Create comprehensive IPO model from extracted data
+	return service.buildIPOModelFromExtractedDataWithLogging(
+		ipoListItem,
+		basicInformation,
+		dateInformation,
+		pricingInformation,
+		statusInformation,
+		htmlDocument,
+	)
 }
 
-// executeHTTPRequestWithRetry executes HTTP requests with exponential backoff retry logic
-func (service *ChittorgarhIPOScrapingService) executeHTTPRequestWithRetry(request *http.Request) (*http.Response, error) {
-	var httpResponse *http.Response
-	var lastExecutionError error
-
-	for attemptNumber := 0; attemptNumber <= service.configuration.MaxRetryAttempts; attemptNumber++ {
-		if attemptNumber > 0 {
-			// Calculate exponential backoff duration with jitter to prevent thundering herd
-			baseBackoffDuration := time.Duration(1<<uint(attemptNumber-1)) * time.Second
-			jitterDuration := time.Duration(float64(baseBackoffDuration) * 0.1 * (0.5 + 0.5*float64(attemptNumber%3)/2))
-			totalBackoffDuration := baseBackoffDuration + jitterDuration
-			time.Sleep(totalBackoffDuration)
-		}
-
-		httpResponse, lastExecutionError = service.httpClient.Do(request)
-		if lastExecutionError == nil && httpResponse.StatusCode == http.StatusOK {
-			return httpResponse, nil // Successful execution
-		}
-
-		// Store detailed error information for potential return
-		if lastExecutionError != nil {
-			lastExecutionError = fmt.Errorf("attempt %d failed with network error: %w", attemptNumber+1, lastExecutionError)
-		} else {
-			lastExecutionError = fmt.Errorf("attempt %d failed with HTTP %d: %s", attemptNumber+1, httpResponse.StatusCode, http.StatusText(httpResponse.StatusCode))
-			httpResponse.Body.Close() // Clean up response body before retrying
+// runShadowExtraction runs the shadow strategy on the page the primary strategy already
+// extracted and records how the two agree. It is skipped when shadow extraction is off
+// or the primary fell back to the shadow strategy itself. The shadow run keeps its own
+// extraction counters, so scrape run reports only count the primary.
+func (service *ChittorgarhIPOScrapingService) runShadowExtraction(primaryStrategy string, primary *models.IPO, bodyText string, ipoListItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) {
+	shadow := service.extractionShadow
+	if shadow == nil || shadow.Strategy == primaryStrategy {
+		return
+	}
+
+	shadowService := *service
+	shadowService.extractionMetrics = NewExtractionMetrics()
+
+	var shadowData *models.IPO
+	switch shadow.Strategy {
+	case ExtractionStrategyJSON:
+		var err error
+		if shadowData, err = shadowService.extractIPODataFromJSONWithLogging(bodyText, ipoListItem, htmlDocument); err != nil {
+			shadow.RecordError(primary.StockID, err)
+			return
 		}
+	case ExtractionStrategyHTML:
+		shadowData = shadowService.extractIPODataFromHTML(ipoListItem, htmlDocument)
 	}
-
-	// All retry attempts exhausted
-	totalAttempts := service.configuration.MaxRetryAttempts + 1
-	return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", totalAttempts, lastExecutionError)
+	shadow.Compare(primary, shadowData)
 }
 
-// createPartialIPOFromListItem creates a partial IPO model when detailed scraping fails
-func (service *ChittorgarhIPOScrapingService) createPartialIPOFromListItem(listItem ChittorgarhIPOListItem) *models.IPO {
-	currentTimestamp := time.Now()
-
-	partialIPO := &models.IPO{
-		Name:        listItem.IPONewsTitle,
-		CompanyCode: service.htmlDataExtractor.extractCompanyCodeFromText(listItem.IPONewsTitle),
-		StockID:     strconv.Itoa(listItem.ID),
-		Status:      "Unknown",
-		Registrar:   "Unknown",
-		CreatedAt:   currentTimestamp,
-		UpdatedAt:   currentTimestamp,
+// ShadowExtractionReport returns the agreement of the shadow extraction with the primary,
+// or nil when shadow extraction is off
+func (service *ChittorgarhIPOScrapingService) ShadowExtractionReport() *ShadowExtractionReport {
+	if service.extractionShadow == nil {
+		return nil
 	}
+	report := service.extractionShadow.Report()
+	return &report
+}
 
-	if listItem.LogoURL != "" {
-		partialIPO.LogoURL = &listItem.LogoURL
+// LogShadowExtractionSummary logs the shadow extraction's agreement so far, if it is on
+func (service *ChittorgarhIPOScrapingService) LogShadowExtractionSummary() {
+	if service.extractionShadow != nil {
+		service.extractionShadow.LogSummary()
 	}
+}
 
-	return partialIPO
+// ResetShadowExtraction drops the shadow comparisons recorded so far
+func (service *ChittorgarhIPOScrapingService) ResetShadowExtraction() {
+	if service.extractionShadow != nil {
+		service.extractionShadow.Reset()
+	}
 }
 
-// buildIPOModelFromExtractedData constructs a comprehensive IPO model from extracted data
-func (service *ChittorgarhIPOScrapingService) buildIPOModelFromExtractedData(
-	listItem ChittorgarhIPOListItem,
-	basicInfo IPOBasicInformation,
-	dateInfo IPODateInformation,
-	pricingInfo IPOPricingInformation,
-	statusInfo IPOStatusInformation,
-	htmlDocument *goquery.Document,
-) *models.IPO {
-	currentTimestamp := time.Now()
-
-	ipoModel := &models.IPO{
-		StockID:   strconv.Itoa(listItem.ID),
-		CreatedAt: currentTimestamp,
-		UpdatedAt: currentTimestamp,
+// attachStrengthsAndRisks sets the IPO's strengths and risks from its detail page or,
+// when the detail page has none, from the review page it links to. A review page that
+// cannot be fetched is logged and leaves both unset, so the stored lists are kept.
+func (service *ChittorgarhIPOScrapingService) attachStrengthsAndRisks(ctx context.Context, ipoData *models.IPO, detailDocument *goquery.Document, logger *logrus.Entry) {
+	strengths, risks := service.htmlDataExtractor.ExtractStrengthsAndRisks(detailDocument)
+	if strengths == nil && risks == nil {
+		if reviewURL := service.htmlDataExtractor.ExtractReviewURL(detailDocument, service.baseURL); reviewURL != "" {
+			reviewDocument, err := service.fetchHTMLDocument(ctx, reviewURL)
+			if err != nil {
+				shared.DefaultErrorCounter.Record("ipo_review", err)
+				logger.WithError(err).WithField("url", reviewURL).Warn("Failed to fetch IPO review page")
+				return
+			}
+			strengths, risks = service.htmlDataExtractor.ExtractStrengthsAndRisks(reviewDocument)
+		}
 	}
 
-	// Set basic information with fallbacks to list item data
-	if basicInfo.CompanyName != "" {
-		ipoModel.Name = basicInfo.CompanyName
-	} else {
-		ipoModel.Name = listItem.IPONewsTitle
+	if len(strengths) > 0 {
+		if encoded, err := json.Marshal(strengths); err == nil {
+			ipoData.Strengths = encoded
+		}
+	}
+	if len(risks) > 0 {
+		if encoded, err := json.Marshal(risks); err == nil {
+			ipoData.Risks = encoded
+		}
 	}
+	logger.WithFields(logrus.Fields{
+		"strengths": len(strengths),
+		"risks":     len(risks),
+	}).Debug("Extracted IPO strengths and risks")
+}
 
-	if basicInfo.CompanyCode != "" {
-		ipoModel.CompanyCode = basicInfo.CompanyCode
-	} else {
-		ipoModel.CompanyCode = service.htmlDataExtractor.extractCompanyCodeFromText(listItem.IPONewsTitle)
+// fetchHTMLDocument fetches and parses a Chittorgarh page under the per-host rate limit
+func (service *ChittorgarhIPOScrapingService) fetchHTMLDocument(ctx context.Context, pageURL string) (*goquery.Document, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", pageURL, err)
+	}
+	if err := service.requestRateLimiter.WaitForHost(ctx, request.URL.Host); err != nil {
+		return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
 	}
+	service.setBrowserLikeHeaders(request, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	if basicInfo.RegistrarName != "" {
-		ipoModel.Registrar = basicInfo.RegistrarName
-	} else {
-		ipoModel.Registrar = "Unknown"
+	response, err := service.executeHTTPRequestWithRetry(request)
+	if err != nil {
+		return nil, err
 	}
+	defer response.Body.Close()
 
-	// Set optional basic information
-	if basicInfo.StockSymbol != nil {
-		ipoModel.Symbol = basicInfo.StockSymbol
+	document, err := goquery.NewDocumentFromReader(response.Body)
+	if err != nil {
+		service.extractionMetrics.RecordHTMLParseError()
+		return nil, shared.ParseErrorf("failed to parse %s: %w", pageURL, err)
 	}
+	return document, nil
+}
 
-	// Set date information
-	ipoModel.OpenDate = dateInfo.SubscriptionOpenDate
-	ipoModel.CloseDate = dateInfo.SubscriptionCloseDate
-	ipoModel.ResultDate = dateInfo.AllotmentResultDate
-	ipoModel.ListingDate = dateInfo.StockListingDate
+// Private helper methods for HTTP request handling and data processing
 
-	// Set pricing information
-	ipoModel.PriceBandLow = pricingInfo.PriceBandMinimum
-	ipoModel.PriceBandHigh = pricingInfo.PriceBandMaximum
-	ipoModel.IssueSize = pricingInfo.TotalIssueSize
-	ipoModel.MinQty = pricingInfo.MinimumLotQuantity
-	ipoModel.MinAmount = pricingInfo.MinimumInvestmentAmount
+// setBrowserLikeHeaders configures HTTP request headers to mimic browser behavior
+func (service *ChittorgarhIPOScrapingService) setBrowserLikeHeaders(request *http.Request, acceptHeader string) {
+	request.Header.Set("User-Agent", shared.BrowserUserAgents[0])
+	request.Header.Set("Accept", acceptHeader)
+	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	request.Header.Set("Cache-Control", "no-cache")
+}
 
-	// Extract description and about from HTML
-	if htmlDescription := service.htmlDataExtractor.ExtractCompanyDescription(htmlDocument); htmlDescription != nil {
-		ipoModel.Description = htmlDescription
-		fmt.Printf("HTML extraction: Found description for IPO %d (%s)\n", listItem.ID, ipoModel.Name)
-	} else {
-		fmt.Printf("No description found for IPO %d (%s) in HTML\n", listItem.ID, ipoModel.Name)
+// executeHTTPRequestWithRetry executes HTTP requests through the shared retry helper so
+// attempts are counted in the per-host HTTP metrics and respect host budgets
+func (service *ChittorgarhIPOScrapingService) executeHTTPRequestWithRetry(request *http.Request) (*http.Response, error) {
+	policy := shared.DefaultHTTPRetryPolicy().WithMaxRetries(service.configuration.MaxRetryAttempts)
+	if service.configuration.RetryPolicy != nil {
+		policy = *service.configuration.RetryPolicy
 	}
+	return shared.ExecuteHTTPRequestWithPolicy(service.httpClient, request, policy)
+}
 
-	if htmlAbout := service.htmlDataExtractor.ExtractCompanyAbout(htmlDocument); htmlAbout != nil {
-		ipoModel.About = htmlAbout
-		fmt.Printf("HTML extraction: Found about for IPO %d (%s)\n", listItem.ID, ipoModel.Name)
-	} else {
-		fmt.Printf("No about found for IPO %d (%s) in HTML\n", listItem.ID, ipoModel.Name)
-	}
+// createPartialIPOFromListItem creates a partial IPO model when detailed scraping fails
+func (service *ChittorgarhIPOScrapingService) createPartialIPOFromListItem(listItem ChittorgarhIPOListItem) *models.IPO {
+	currentTimestamp := time.Now()
 
-	// Calculate status based on dates (override scraped status with dynamic calculation)
-	ipoModel.Status = service.utilityService.CalculateIPOStatus(ipoModel.OpenDate, ipoModel.CloseDate, ipoModel.ListingDate)
-	ipoModel.SubscriptionStatus = statusInfo.SubscriptionStatus
-	ipoModel.ListingGain = statusInfo.ListingPerformance
+	partialIPO := &models.IPO{
+		Name:        listItem.IPONewsTitle,
+		CompanyCode: service.htmlDataExtractor.extractCompanyCodeFromText(listItem.IPONewsTitle),
+		StockID:     strconv.Itoa(listItem.ID),
+		Status:      "Unknown",
+		Registrar:   "Unknown",
+		CreatedAt:   currentTimestamp,
+		UpdatedAt:   currentTimestamp,
+	}
 
-	// Set logo URL from list item
 	if listItem.LogoURL != "" {
-		ipoModel.LogoURL = &listItem.LogoURL
+		partialIPO.LogoURL = &listItem.LogoURL
 	}
 
-	return ipoModel
+	return partialIPO
 }
 
 // buildIPOModelFromExtractedDataWithLogging constructs a comprehensive IPO model from extracted data with detailed logging
@@ -1617,10 +1942,10 @@ func (service *ChittorgarhIPOScrapingService) buildIPOModelFromExtractedDataWith
 	}
 
 	// Set date information
-	ipoModel.OpenDate = dateInfo.SubscriptionOpenDate
-	ipoModel.CloseDate = dateInfo.SubscriptionCloseDate
-	ipoModel.ResultDate = dateInfo.AllotmentResultDate
-	ipoModel.ListingDate = dateInfo.StockListingDate
+	ipoModel.OpenDate = models.DateOf(dateInfo.SubscriptionOpenDate)
+	ipoModel.CloseDate = models.DateOf(dateInfo.SubscriptionCloseDate)
+	ipoModel.ResultDate = models.DateOf(dateInfo.AllotmentResultDate)
+	ipoModel.ListingDate = models.DateOf(dateInfo.StockListingDate)
 
 	// Set pricing information
 	ipoModel.PriceBandLow = pricingInfo.PriceBandMinimum
@@ -1630,10 +1955,8 @@ func (service *ChittorgarhIPOScrapingService) buildIPOModelFromExtractedDataWith
 	ipoModel.MinAmount = pricingInfo.MinimumInvestmentAmount
 
 	// Extract description and about from HTML with metrics tracking
-	service.extractionMetrics.DescriptionAttempts++
 	if htmlDescription := service.htmlDataExtractor.ExtractCompanyDescription(htmlDocument); htmlDescription != nil {
 		ipoModel.Description = htmlDescription
-		service.extractionMetrics.DescriptionSuccess++
 		logger.WithFields(logrus.Fields{
 			"extraction_type": "description",
 			"text_length":     len(*htmlDescription),
@@ -1642,11 +1965,10 @@ func (service *ChittorgarhIPOScrapingService) buildIPOModelFromExtractedDataWith
 	} else {
 		logger.WithField("extraction_type", "description").Warn("No description found in HTML")
 	}
+	service.extractionMetrics.RecordDescriptionAttempt(ipoModel.Description != nil)
 
-	service.extractionMetrics.AboutAttempts++
 	if htmlAbout := service.htmlDataExtractor.ExtractCompanyAbout(htmlDocument); htmlAbout != nil {
 		ipoModel.About = htmlAbout
-		service.extractionMetrics.AboutSuccess++
 		logger.WithFields(logrus.Fields{
 			"extraction_type": "about",
 			"text_length":     len(*htmlAbout),
@@ -1655,6 +1977,7 @@ func (service *ChittorgarhIPOScrapingService) buildIPOModelFromExtractedDataWith
 	} else {
 		logger.WithField("extraction_type", "about").Warn("No about found in HTML")
 	}
+	service.extractionMetrics.RecordAboutAttempt(ipoModel.About != nil)
 
 	// Calculate status based on dates (override scraped status with dynamic calculation)
 	ipoModel.Status = service.utilityService.CalculateIPOStatus(ipoModel.OpenDate, ipoModel.CloseDate, ipoModel.ListingDate)
@@ -1696,12 +2019,11 @@ func (service *ChittorgarhIPOScrapingService) CleanupResources() error {
 
 	// Log final extraction metrics before cleanup
 	service.extractionMetrics.LogSummary()
+	service.LogShadowExtractionSummary()
 
 	if service.httpClient != nil && service.httpClient.Transport != nil {
-		if httpTransport, isHTTPTransport := service.httpClient.Transport.(*http.Transport); isHTTPTransport {
-			httpTransport.CloseIdleConnections()
-			logger.Debug("Closed idle HTTP connections")
-		}
+		service.httpClient.CloseIdleConnections()
+		logger.Debug("Closed idle HTTP connections")
 	}
 
 	logger.Info("Completed cleanup of scraping service resources")
@@ -1715,82 +2037,19 @@ func (service *ChittorgarhIPOScrapingService) GetExtractionMetrics() *Extraction
 
 // ResetExtractionMetrics resets the extraction metrics counters
 func (service *ChittorgarhIPOScrapingService) ResetExtractionMetrics() {
-	service.extractionMetrics = NewExtractionMetrics()
+	service.extractionMetrics.Reset()
 	logrus.WithField("component", "ChittorgarhIPOScrapingService").Info("Reset extraction metrics")
 }
 
 // ProcessAllAvailableIPOs scrapes all available IPOs with optimized batch processing and error isolation
 func (service *ChittorgarhIPOScrapingService) ProcessAllAvailableIPOs() ([]*models.IPO, error) {
-	// Fetch the complete list of available IPOs
-	availableIPOItems, fetchError := service.FetchAvailableIPOList()
-	if fetchError != nil {
-		return nil, fmt.Errorf("failed to fetch available IPO list: %w", fetchError)
-	}
-
-	if len(availableIPOItems) == 0 {
-		return []*models.IPO{}, nil // Return empty slice for no available IPOs
-	}
-
-	// Pre-allocate results slice with exact capacity for memory optimization
-	scrapingResults := make([]*models.IPO, 0, len(availableIPOItems))
-
-	// Error tracking with memory-conscious approach
-	const maxTrackedErrors = 10
-	var collectedErrors []error
-	var totalErrorCount int
-
-	// Process each IPO sequentially with rate limiting and error isolation
-	for itemIndex, ipoItem := range availableIPOItems {
-		scrapedIPOData, scrapingError := service.ScrapeDetailedIPOInformation(ipoItem)
-
-		if scrapingError != nil {
-			totalErrorCount++
-
-			// Collect sample errors for reporting (memory-limited)
-			if len(collectedErrors) < maxTrackedErrors {
-				collectedErrors = append(collectedErrors, fmt.Errorf("failed to scrape IPO %d (%s): %w", ipoItem.ID, ipoItem.IPONewsTitle, scrapingError))
-			}
-
-			// Include partial data if available (error isolation)
-			if scrapedIPOData != nil {
-				scrapingResults = append(scrapingResults, scrapedIPOData)
-			}
-			continue
-		}
-
-		// Successfully scraped IPO data
-		if scrapedIPOData != nil {
-			scrapingResults = append(scrapingResults, scrapedIPOData)
-		}
-
-		// Memory optimization: Trigger garbage collection for large batches
-		if (itemIndex+1)%50 == 0 && len(availableIPOItems) > 100 {
-			// Optional GC trigger to prevent memory buildup during large batch processing
-		}
-	}
-
-	// Generate comprehensive error summary for partial success scenarios
-	if len(scrapingResults) > 0 && totalErrorCount > 0 {
-		errorSummary := service.buildBatchProcessingErrorSummary(len(scrapingResults), totalErrorCount, collectedErrors)
-		return scrapingResults, fmt.Errorf("%s", errorSummary)
-	}
-
-	// Handle complete failure scenarios
-	if len(scrapingResults) == 0 && totalErrorCount > 0 {
-		if len(collectedErrors) > 0 {
-			return nil, fmt.Errorf("failed to scrape any IPOs: %d errors occurred, first error: %w", totalErrorCount, collectedErrors[0])
-		}
-		return nil, fmt.Errorf("failed to scrape any IPOs: %d errors occurred", totalErrorCount)
-	}
-
-	// Complete success
-	return scrapingResults, nil
+	return service.ProcessAllAvailableIPOsWithContext(context.Background())
 }
 
 // ProcessAllAvailableIPOsWithContext scrapes all IPOs with context support for cancellation and timeout
 func (service *ChittorgarhIPOScrapingService) ProcessAllAvailableIPOsWithContext(ctx context.Context) ([]*models.IPO, error) {
 	// Fetch the complete list of available IPOs
-	availableIPOItems, fetchError := service.FetchAvailableIPOList()
+	availableIPOItems, fetchError := service.FetchAvailableIPOList(ctx)
 	if fetchError != nil {
 		return nil, fmt.Errorf("failed to fetch available IPO list: %w", fetchError)
 	}
@@ -1816,7 +2075,7 @@ func (service *ChittorgarhIPOScrapingService) ProcessAllAvailableIPOsWithContext
 		default:
 		}
 
-		scrapedIPOData, scrapingError := service.ScrapeDetailedIPOInformation(ipoItem)
+		scrapedIPOData, scrapingError := service.ScrapeDetailedIPOInformation(ctx, ipoItem)
 
 		if scrapingError != nil {
 			totalErrorCount++
@@ -1904,67 +2163,6 @@ type ChittorgarhIPOData struct {
 	About                string  `json:"about"`
 }
 
-// extractIPODataFromJSON extracts IPO data from JSON embedded in the page
-func (service *ChittorgarhIPOScrapingService) extractIPODataFromJSON(bodyText string, ipoListItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) (*models.IPO, error) {
-	// Look for the JSON data pattern in the JavaScript
-	// The data is embedded in a complex nested structure, we need to find the complete JSON object
-
-	// First, find the start of the ipoData JSON
-	startPattern := `\\"ipoData\\":\s*\[`
-	startRegex := regexp.MustCompile(startPattern)
-	startMatch := startRegex.FindStringIndex(bodyText)
-
-	if startMatch == nil {
-		return nil, fmt.Errorf("could not find ipoData start pattern in page content")
-	}
-
-	// Find the opening brace after the array start
-	searchStart := startMatch[1]
-	openBraceIndex := strings.Index(bodyText[searchStart:], "{")
-	if openBraceIndex == -1 {
-		return nil, fmt.Errorf("could not find opening brace for ipoData JSON")
-	}
-
-	jsonStart := searchStart + openBraceIndex
-
-	// Now find the matching closing brace by counting braces
-	braceCount := 0
-	jsonEnd := -1
-
-	for i := jsonStart; i < len(bodyText); i++ {
-		char := bodyText[i]
-		if char == '{' {
-			braceCount++
-		} else if char == '}' {
-			braceCount--
-			if braceCount == 0 {
-				jsonEnd = i + 1
-				break
-			}
-		}
-	}
-
-	if jsonEnd == -1 {
-		return nil, fmt.Errorf("could not find closing brace for ipoData JSON")
-	}
-
-	jsonStr := bodyText[jsonStart:jsonEnd]
-
-	// Unescape the JSON string (it's escaped for JavaScript)
-	// Replace \" with " and \\ with \
-	unescapedJSON := strings.ReplaceAll(jsonStr, `\"`, `"`)
-	unescapedJSON = strings.ReplaceAll(unescapedJSON, `\\`, `\`)
-
-	// Parse the JSON data
-	var ipoData ChittorgarhIPOData
-	if err := json.Unmarshal([]byte(unescapedJSON), &ipoData); err != nil {
-		return nil, fmt.Errorf("failed to parse IPO JSON data: %w", err)
-	}
-
-	// Convert to our IPO model
-	return service.convertChittorgarhDataToIPO(ipoData, ipoListItem, htmlDocument)
-}
-
 // extractIPODataFromJSONWithLogging extracts IPO data from JSON embedded in the page with comprehensive logging
 func (service *ChittorgarhIPOScrapingService) extractIPODataFromJSONWithLogging(bodyText string, ipoListItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) (*models.IPO, error) {
 	logger := logrus.WithFields(logrus.Fields{
@@ -1976,79 +2174,22 @@ func (service *ChittorgarhIPOScrapingService) extractIPODataFromJSONWithLogging(
 
 	logger.Debug("Starting JSON extraction from page content")
 
-	// Look for the JSON data pattern in the JavaScript
-	// The data is embedded in a complex nested structure, we need to find the complete JSON object
-
-	// First, find the start of the ipoData JSON
-	startPattern := `\\"ipoData\\":\s*\[`
-	startRegex, err := regexp.Compile(startPattern)
+	// Locate ipoData in the Next.js payload and check its shape before decoding, so a
+	// format change is reported as upstream_changed rather than as a bad field value
+	rawIPOData, err := ExtractNextPayloadValue(bodyText, htmlDocument, "ipoData")
 	if err != nil {
-		logger.WithError(err).Error("Failed to compile start pattern regex")
-		return nil, fmt.Errorf("failed to compile start pattern regex: %w", err)
-	}
-
-	startMatch := startRegex.FindStringIndex(bodyText)
-	if startMatch == nil {
-		logger.Warn("Could not find ipoData start pattern in page content")
-		return nil, fmt.Errorf("could not find ipoData start pattern in page content")
-	}
-
-	logger.WithFields(logrus.Fields{
-		"start_position": startMatch[0],
-		"pattern":        startPattern,
-	}).Debug("Found ipoData start pattern")
-
-	// Find the opening brace after the array start
-	searchStart := startMatch[1]
-	openBraceIndex := strings.Index(bodyText[searchStart:], "{")
-	if openBraceIndex == -1 {
-		logger.Warn("Could not find opening brace for ipoData JSON")
-		return nil, fmt.Errorf("could not find opening brace for ipoData JSON")
-	}
-
-	jsonStart := searchStart + openBraceIndex
-	logger.WithField("json_start_position", jsonStart).Debug("Found JSON opening brace")
-
-	// Now find the matching closing brace by counting braces
-	braceCount := 0
-	jsonEnd := -1
-
-	for i := jsonStart; i < len(bodyText); i++ {
-		char := bodyText[i]
-		if char == '{' {
-			braceCount++
-		} else if char == '}' {
-			braceCount--
-			if braceCount == 0 {
-				jsonEnd = i + 1
-				break
-			}
-		}
+		logger.WithError(err).Warn("Could not locate ipoData in page payload")
+		return nil, err
 	}
-
-	if jsonEnd == -1 {
-		logger.Warn("Could not find closing brace for ipoData JSON")
-		return nil, fmt.Errorf("could not find closing brace for ipoData JSON")
+	if err := validatePayloadObject(rawIPOData, ipoDataSchema); err != nil {
+		logger.WithError(err).WithField("json_preview", service.truncateForLogging(string(rawIPOData), 200)).Warn("ipoData failed schema validation")
+		return nil, err
 	}
 
-	jsonStr := bodyText[jsonStart:jsonEnd]
-	logger.WithFields(logrus.Fields{
-		"json_length":  len(jsonStr),
-		"json_preview": service.truncateForLogging(jsonStr, 200),
-	}).Debug("Extracted JSON string")
-
-	// Unescape the JSON string (it's escaped for JavaScript)
-	// Replace \" with " and \\ with \
-	unescapedJSON := strings.ReplaceAll(jsonStr, `\"`, `"`)
-	unescapedJSON = strings.ReplaceAll(unescapedJSON, `\\`, `\`)
-
-	logger.WithField("unescaped_length", len(unescapedJSON)).Debug("Unescaped JSON string")
-
-	// Parse the JSON data
 	var ipoData ChittorgarhIPOData
-	if err := json.Unmarshal([]byte(unescapedJSON), &ipoData); err != nil {
+	if err := json.Unmarshal(rawIPOData, &ipoData); err != nil {
 		logger.WithError(err).Error("Failed to parse IPO JSON data")
-		return nil, fmt.Errorf("failed to parse IPO JSON data: %w", err)
+		return nil, shared.ParseErrorf("failed to parse IPO JSON data: %w", err)
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -2061,131 +2202,6 @@ func (service *ChittorgarhIPOScrapingService) extractIPODataFromJSONWithLogging(
 	return service.convertChittorgarhDataToIPOWithLogging(ipoData, ipoListItem, htmlDocument)
 }
 
-// convertChittorgarhDataToIPO converts Chittorgarh JSON data to our IPO model
-func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPO(data ChittorgarhIPOData, listItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) (*models.IPO, error) {
-	currentTimestamp := time.Now()
-
-	ipo := &models.IPO{
-		StockID:   strconv.Itoa(data.ID),
-		Name:      data.CompanyName,
-		CreatedAt: currentTimestamp,
-		UpdatedAt: currentTimestamp,
-	}
-
-	// Set company code from name
-	ipo.CompanyCode = service.htmlDataExtractor.extractCompanyCodeFromText(data.CompanyName)
-
-	// Set registrar
-	if data.RegistrarName != "" {
-		ipo.Registrar = data.RegistrarName
-	} else {
-		ipo.Registrar = "Unknown"
-	}
-
-	// Set symbol
-	if data.NSESymbol != "" {
-		ipo.Symbol = &data.NSESymbol
-	}
-
-	// Set price band
-	if data.IssuePriceLower > 0 {
-		ipo.PriceBandLow = &data.IssuePriceLower
-	}
-	if data.IssuePriceUpper > 0 {
-		ipo.PriceBandHigh = &data.IssuePriceUpper
-	}
-
-	// Set dates
-	if openDate := service.parseChittorgarhDate(data.IssueOpenDate); openDate != nil {
-		ipo.OpenDate = openDate
-	}
-	if closeDate := service.parseChittorgarhDate(data.IssueCloseDate); closeDate != nil {
-		ipo.CloseDate = closeDate
-	}
-	if listingDate := service.parseChittorgarhDate(data.TimetableListingDate); listingDate != nil {
-		ipo.ListingDate = listingDate
-	}
-	if resultDate := service.parseChittorgarhDate(data.TimetableResultDate); resultDate != nil {
-		ipo.ResultDate = resultDate
-	}
-
-	// Set lot size and minimum amount
-	if data.MarketLotSize > 0 {
-		ipo.MinQty = &data.MarketLotSize
-	}
-	if data.MinimumOrderQuantity > 0 && ipo.MinQty == nil {
-		ipo.MinQty = &data.MinimumOrderQuantity
-	}
-
-	// Calculate minimum amount
-	if ipo.MinQty != nil && ipo.PriceBandHigh != nil {
-		minAmount := int(float64(*ipo.MinQty) * (*ipo.PriceBandHigh))
-		ipo.MinAmount = &minAmount
-	}
-
-	// Set issue size
-	if data.IssueSizeInAmt != "" {
-		ipo.IssueSize = &data.IssueSizeInAmt
-	}
-
-	// Set description and about if available from JSON, otherwise try HTML fallback
-	if data.Description != "" {
-		ipo.Description = &data.Description
-		fmt.Printf("JSON extraction: Found description for IPO %d (%s)\n", data.ID, data.CompanyName)
-	} else {
-		// HTML fallback for description
-		if htmlDescription := service.htmlDataExtractor.ExtractCompanyDescription(htmlDocument); htmlDescription != nil {
-			ipo.Description = htmlDescription
-			fmt.Printf("HTML fallback: Extracted description for IPO %d (%s)\n", data.ID, data.CompanyName)
-		} else {
-			fmt.Printf("No description found for IPO %d (%s) in JSON or HTML\n", data.ID, data.CompanyName)
-		}
-	}
-
-	if data.About != "" {
-		ipo.About = &data.About
-		fmt.Printf("JSON extraction: Found about for IPO %d (%s)\n", data.ID, data.CompanyName)
-	} else {
-		// HTML fallback for about
-		if htmlAbout := service.htmlDataExtractor.ExtractCompanyAbout(htmlDocument); htmlAbout != nil {
-			ipo.About = htmlAbout
-			fmt.Printf("HTML fallback: Extracted about for IPO %d (%s)\n", data.ID, data.CompanyName)
-		} else {
-			fmt.Printf("No about found for IPO %d (%s) in JSON or HTML\n", data.ID, data.CompanyName)
-		}
-	}
-
-	// Generate slug from company name
-	if ipo.Name != "" {
-		slug := service.generateSlugFromName(ipo.Name)
-		ipo.Slug = &slug
-	}
-
-	// Set logo URL - prefer the one from API list, fallback to generated
-	if listItem.LogoURL != "" {
-		// Use the logo URL from the API list (most accurate)
-		fullLogoURL := fmt.Sprintf("https://www.chittorgarh.net/images/ipo/%s", listItem.LogoURL)
-		ipo.LogoURL = &fullLogoURL
-	} else if data.URLRewriteFolderName != "" {
-		// Fallback: generate logo URL using the standard Chittorgarh pattern
-		// Remove -ipo suffix from URLRewriteFolderName for logo URL generation
-		logoFolderName := data.URLRewriteFolderName
-		if strings.HasSuffix(logoFolderName, "-ipo") {
-			logoFolderName = strings.TrimSuffix(logoFolderName, "-ipo")
-		}
-
-		// Try both underscore and hyphen patterns since Chittorgarh is inconsistent
-		// We'll use hyphens as the primary pattern since it matches the URLRewriteFolderName format
-		logoURL := fmt.Sprintf("https://www.chittorgarh.net/images/ipo/%s-logo.png", logoFolderName)
-		ipo.LogoURL = &logoURL
-	}
-
-	// Calculate status based on dates
-	ipo.Status = service.utilityService.CalculateIPOStatus(ipo.OpenDate, ipo.CloseDate, ipo.ListingDate)
-
-	return ipo, nil
-}
-
 // convertChittorgarhDataToIPOWithLogging converts Chittorgarh JSON data to our IPO model with comprehensive logging
 func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLogging(data ChittorgarhIPOData, listItem ChittorgarhIPOListItem, htmlDocument *goquery.Document) (*models.IPO, error) {
 	logger := logrus.WithFields(logrus.Fields{
@@ -2241,16 +2257,16 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 
 	// Set dates
 	if openDate := service.parseChittorgarhDate(data.IssueOpenDate); openDate != nil {
-		ipo.OpenDate = openDate
+		ipo.OpenDate = models.DateOf(openDate)
 	}
 	if closeDate := service.parseChittorgarhDate(data.IssueCloseDate); closeDate != nil {
-		ipo.CloseDate = closeDate
+		ipo.CloseDate = models.DateOf(closeDate)
 	}
 	if listingDate := service.parseChittorgarhDate(data.TimetableListingDate); listingDate != nil {
-		ipo.ListingDate = listingDate
+		ipo.ListingDate = models.DateOf(listingDate)
 	}
 	if resultDate := service.parseChittorgarhDate(data.TimetableResultDate); resultDate != nil {
-		ipo.ResultDate = resultDate
+		ipo.ResultDate = models.DateOf(resultDate)
 	}
 
 	// Set lot size and minimum amount
@@ -2279,10 +2295,8 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 	}
 
 	// Set description and about if available from JSON, otherwise try HTML fallback with metrics tracking
-	service.extractionMetrics.DescriptionAttempts++
 	if data.Description != "" {
 		ipo.Description = &data.Description
-		service.extractionMetrics.DescriptionSuccess++
 		logger.WithFields(logrus.Fields{
 			"source":       "json",
 			"text_length":  len(data.Description),
@@ -2293,7 +2307,7 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 		logger.Debug("Description not found in JSON, attempting HTML fallback")
 		if htmlDescription := service.htmlDataExtractor.ExtractCompanyDescription(htmlDocument); htmlDescription != nil {
 			ipo.Description = htmlDescription
-			service.extractionMetrics.DescriptionSuccess++
+			setLineage(ipo, models.LineageSourceHTML, time.Now(), "description")
 			logger.WithFields(logrus.Fields{
 				"source":       "html_fallback",
 				"text_length":  len(*htmlDescription),
@@ -2303,11 +2317,10 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 			logger.Warn("No description found in JSON or HTML")
 		}
 	}
+	service.extractionMetrics.RecordDescriptionAttempt(ipo.Description != nil)
 
-	service.extractionMetrics.AboutAttempts++
 	if data.About != "" {
 		ipo.About = &data.About
-		service.extractionMetrics.AboutSuccess++
 		logger.WithFields(logrus.Fields{
 			"source":       "json",
 			"text_length":  len(data.About),
@@ -2318,7 +2331,7 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 		logger.Debug("About not found in JSON, attempting HTML fallback")
 		if htmlAbout := service.htmlDataExtractor.ExtractCompanyAbout(htmlDocument); htmlAbout != nil {
 			ipo.About = htmlAbout
-			service.extractionMetrics.AboutSuccess++
+			setLineage(ipo, models.LineageSourceHTML, time.Now(), "about")
 			logger.WithFields(logrus.Fields{
 				"source":       "html_fallback",
 				"text_length":  len(*htmlAbout),
@@ -2328,6 +2341,7 @@ func (service *ChittorgarhIPOScrapingService) convertChittorgarhDataToIPOWithLog
 			logger.Warn("No about found in JSON or HTML")
 		}
 	}
+	service.extractionMetrics.RecordAboutAttempt(ipo.About != nil)
 
 	// Generate slug from company name
 	if ipo.Name != "" {
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// Display label languages, chosen with ?lang=
+const (
+	LabelLanguageEnglish = "en"
+	LabelLanguageHindi   = "hi"
+)
+
+// DefaultLabelLanguage is the language of display labels when none is asked for
+const DefaultLabelLanguage = LabelLanguageEnglish
+
+// statusLabels are the display labels of IPO and allotment statuses by language. Every
+// language lists the same statuses as English.
+var statusLabels = map[string]map[string]string{
+	LabelLanguageEnglish: {
+		"UPCOMING":                        "Upcoming",
+		"ACTIVE":                          "Open",
+		"LIVE":                            "Open",
+		"CLOSED":                          "Closed",
+		models.IPOStatusResultOut:         "Allotment Out",
+		"LISTED":                          "Listed",
+		"ALLOTTED":                        "Allotted",
+		"NOT_ALLOTTED":                    "Not Allotted",
+		models.AllotmentStatusNotDeclared: "Results Not Declared",
+		"NOT_FOUND":                       "Not Found",
+	},
+	LabelLanguageHindi: {
+		"UPCOMING":                        "आगामी",
+		"ACTIVE":                          "खुला",
+		"LIVE":                            "खुला",
+		"CLOSED":                          "बंद",
+		models.IPOStatusResultOut:         "आवंटन घोषित",
+		"LISTED":                          "सूचीबद्ध",
+		"ALLOTTED":                        "आवंटित",
+		"NOT_ALLOTTED":                    "आवंटित नहीं",
+		models.AllotmentStatusNotDeclared: "परिणाम घोषित नहीं",
+		"NOT_FOUND":                       "नहीं मिला",
+	},
+}
+
+// ParseLabelLanguage validates a ?lang= value; empty means DefaultLabelLanguage
+func ParseLabelLanguage(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return DefaultLabelLanguage, nil
+	}
+	if _, ok := statusLabels[lang]; !ok {
+		return "", shared.ValidationErrorf("lang must be %s or %s", LabelLanguageEnglish, LabelLanguageHindi)
+	}
+	return lang, nil
+}
+
+// StatusLabel returns the display label of a status in lang. Statuses without a label
+// fall back to English, then to the status in words ("SOME_STATUS" reads "Some status").
+func StatusLabel(status, lang string) string {
+	if label, ok := statusLabels[lang][status]; ok {
+		return label
+	}
+	if label, ok := statusLabels[DefaultLabelLanguage][status]; ok {
+		return label
+	}
+	words := strings.ToLower(strings.ReplaceAll(status, "_", " "))
+	if words == "" {
+		return ""
+	}
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
+// StatusLabels returns every status's display label in lang
+func StatusLabels(lang string) map[string]string {
+	labels := make(map[string]string, len(statusLabels[DefaultLabelLanguage]))
+	for status := range statusLabels[DefaultLabelLanguage] {
+		labels[status] = StatusLabel(status, lang)
+	}
+	return labels
+}
+
+// LabelIPO sets the IPO's display status in lang
+func LabelIPO(ipo *models.IPO, lang string) {
+	ipo.DisplayStatus = StatusLabel(ipo.Status, lang)
+}
+
+// LabelResult returns a copy of an allotment result with its display status in lang, so
+// results shared with the check queue or caches are not changed
+func LabelResult(result *models.IPOResultCache, lang string) *models.IPOResultCache {
+	if result == nil {
+		return nil
+	}
+	labelled := *result
+	labelled.DisplayStatus = StatusLabel(result.Status, lang)
+	return &labelled
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// IPOStatusProjection is an IPO's status now and at a simulated time
+type IPOStatusProjection struct {
+	ID            uuid.UUID    `json:"id"`
+	Name          string       `json:"name"`
+	OpenDate      *models.Date `json:"open_date"`
+	CloseDate     *models.Date `json:"close_date"`
+	ListingDate   *models.Date `json:"listing_date"`
+	CurrentStatus string       `json:"current_status"`
+	StatusAt      string       `json:"status_at"`
+	Changes       bool         `json:"changes"`
+}
+
+// SimulateStatuses calculates the status every IPO would have at the given time, next
+// to its status now, using the same rules as the IPO endpoints. Nothing is written.
+func (s *IPOService) SimulateStatuses(ctx context.Context, at time.Time) ([]IPOStatusProjection, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, name, open_date, close_date, listing_date,
+			to_char(retail_cutoff, 'HH24:MI'), to_char(hni_cutoff, 'HH24:MI')
+		FROM ipo_list
+		WHERE deleted_at IS NULL
+		ORDER BY open_date DESC NULLS LAST, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPO dates: %w", err)
+	}
+	defer rows.Close()
+
+	now := shared.ClockOrDefault(s.UtilityService.Clock).Now()
+	projections := []IPOStatusProjection{}
+	for rows.Next() {
+		var projection IPOStatusProjection
+		var retailCutoff, hniCutoff *string
+		if err := rows.Scan(&projection.ID, &projection.Name, &projection.OpenDate, &projection.CloseDate, &projection.ListingDate, &retailCutoff, &hniCutoff); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO dates: %w", err)
+		}
+		closesAt := biddingClosesAt(CloseTimes(projection.CloseDate, retailCutoff, hniCutoff))
+		projection.CurrentStatus = s.UtilityService.CalculateIPOStatusClosingAt(projection.OpenDate, closesAt, projection.ListingDate, now)
+		projection.StatusAt = s.UtilityService.CalculateIPOStatusClosingAt(projection.OpenDate, closesAt, projection.ListingDate, at)
+		projection.Changes = projection.StatusAt != projection.CurrentStatus
+		projections = append(projections, projection)
+	}
+	return projections, rows.Err()
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// subscriptionCategoryLabels maps lower-cased row label fragments of the subscription
+// table to categories; the first matching fragment wins, so specific labels come first
+var subscriptionCategoryLabels = []struct {
+	fragment string
+	category string
+}{
+	{"bnii", models.SubscriptionBNII},
+	{"snii", models.SubscriptionSNII},
+	{"qualified institution", models.SubscriptionQIB},
+	{"qib", models.SubscriptionQIB},
+	{"non-institutional", models.SubscriptionNII},
+	{"nii", models.SubscriptionNII},
+	{"retail", models.SubscriptionRetail},
+	{"employee", models.SubscriptionEmployee},
+	{"shareholder", models.SubscriptionShareholder},
+	{"total", models.SubscriptionTotal},
+}
+
+// subscriptionCategoryOrder is the order categories are listed in history responses
+var subscriptionCategoryOrder = []string{
+	models.SubscriptionQIB, models.SubscriptionNII, models.SubscriptionBNII, models.SubscriptionSNII,
+	models.SubscriptionRetail, models.SubscriptionEmployee, models.SubscriptionShareholder, models.SubscriptionTotal,
+}
+
+// SubscriptionService scrapes category-wise subscription multiples for live IPOs and
+// stores them as a time series
+type SubscriptionService struct {
+	DB      *sql.DB
+	BaseURL string
+	Client  *http.Client
+	Policy  shared.RetryPolicy
+}
+
+// NewSubscriptionService creates a subscription service reading Chittorgarh's subscription pages
+func NewSubscriptionService(db *sql.DB) *SubscriptionService {
+	return &SubscriptionService{
+		DB:      db,
+		BaseURL: "https://www.chittorgarh.com",
+		Client:  shared.NewHTTPClientFactory(30 * time.Second).CreateOptimizedHTTPClient(30 * time.Second),
+		Policy:  shared.DefaultHTTPRetryPolicy().WithMaxRetries(2),
+	}
+}
+
+// FetchSubscription scrapes the current subscription multiples of an IPO by category,
+// with the application counts and shares offered the exchange data has
+func (s *SubscriptionService) FetchSubscription(ctx context.Context, ipo *models.IPO) (*models.SubscriptionSnapshot, error) {
+	if _, err := strconv.Atoi(ipo.StockID); err != nil {
+		return nil, shared.ValidationErrorf("IPO %s has no Chittorgarh ID", ipo.Name)
+	}
+	slug := ipo.CompanyCode
+	if ipo.Slug != nil && *ipo.Slug != "" {
+		slug = *ipo.Slug
+	}
+
+	pageURL := fmt.Sprintf("%s/ipo_subscription/%s/%s/", s.BaseURL, slug, ipo.StockID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription request: %w", err)
+	}
+	shared.SetBrowserLikeHeaders(req, "text/html,application/xhtml+xml")
+
+	resp, err := shared.ExecuteHTTPRequestWithPolicy(s.Client, req, s.Policy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	document, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, shared.ParseErrorf("failed to parse subscription page for %s: %v", ipo.Name, err)
+	}
+
+	snapshot := ParseSubscriptionSnapshot(document)
+	if len(snapshot.Multiples) == 0 {
+		return nil, shared.ParseErrorf("no subscription table found for %s", ipo.Name)
+	}
+	return snapshot, nil
+}
+
+// ParseSubscriptionTable reads category multiples from the subscription table, the
+// table with a "Subscription (times)" column
+func ParseSubscriptionTable(document *goquery.Document) map[string]float64 {
+	return ParseSubscriptionSnapshot(document).Multiples
+}
+
+// ParseSubscriptionSnapshot reads the subscription table: each category's multiple and,
+// from its "Applications" and "Shares Offered" columns when it has them, the category's
+// application count and shares offered. Counts are read from the same row as the
+// multiple; rows without a count leave the category out of that map.
+func ParseSubscriptionSnapshot(document *goquery.Document) *models.SubscriptionSnapshot {
+	snapshot := &models.SubscriptionSnapshot{
+		Multiples:     make(map[string]float64),
+		Applications:  make(map[string]int64),
+		SharesOffered: make(map[string]int64),
+	}
+	document.Find("table").EachWithBreak(func(_ int, table *goquery.Selection) bool {
+		column, applicationsColumn, offeredColumn := -1, -1, -1
+		table.Find("tr").First().Find("th, td").Each(func(i int, cell *goquery.Selection) {
+			header := strings.ToLower(cell.Text())
+			switch {
+			case column < 0 && strings.Contains(header, "subscription"):
+				column = i
+			case applicationsColumn < 0 && strings.Contains(header, "application"):
+				applicationsColumn = i
+			case offeredColumn < 0 && strings.Contains(header, "offered"):
+				offeredColumn = i
+			}
+		})
+		if column < 1 {
+			return true
+		}
+
+		table.Find("tr").Each(func(_ int, row *goquery.Selection) {
+			cells := row.Find("td")
+			if cells.Length() <= column {
+				return
+			}
+			category := subscriptionCategory(cells.Eq(0).Text())
+			if category == "" {
+				return
+			}
+			if _, seen := snapshot.Multiples[category]; seen {
+				return
+			}
+			value := strings.ReplaceAll(strings.TrimSpace(cells.Eq(column).Text()), ",", "")
+			multiple := ParseSubscriptionMultiple(value + "x")
+			if multiple <= 0 {
+				return
+			}
+			snapshot.Multiples[category] = multiple
+			if count := subscriptionCount(cells, applicationsColumn); count > 0 {
+				snapshot.Applications[category] = count
+			}
+			if count := subscriptionCount(cells, offeredColumn); count > 0 {
+				snapshot.SharesOffered[category] = count
+			}
+		})
+		return len(snapshot.Multiples) == 0
+	})
+	return snapshot
+}
+
+// subscriptionCount parses the whole number in a row's cell, written with Indian digit
+// grouping ("1,23,45,678"); 0 when the column is missing or the cell holds no count
+func subscriptionCount(cells *goquery.Selection, column int) int64 {
+	if column < 1 || cells.Length() <= column {
+		return 0
+	}
+	value := strings.ReplaceAll(strings.TrimSpace(cells.Eq(column).Text()), ",", "")
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
+
+// subscriptionCategory maps a subscription table row label to a category, or "" if unknown
+func subscriptionCategory(label string) string {
+	label = strings.ToLower(strings.Join(strings.Fields(label), " "))
+	for _, entry := range subscriptionCategoryLabels {
+		if strings.Contains(label, entry.fragment) {
+			return entry.category
+		}
+	}
+	return ""
+}
+
+// RecordSnapshot stores one observation of every category's multiple, with its
+// application count and shares offered when the snapshot has them
+func (s *SubscriptionService) RecordSnapshot(ctx context.Context, ipoID uuid.UUID, snapshot *models.SubscriptionSnapshot, recordedAt time.Time) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin subscription snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	for category, multiple := range snapshot.Multiples {
+		var applications, sharesOffered *int64
+		if count, ok := snapshot.Applications[category]; ok {
+			applications = &count
+		}
+		if count, ok := snapshot.SharesOffered[category]; ok {
+			sharesOffered = &count
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ipo_subscription_history (ipo_id, category, multiple, applications, shares_offered, recorded_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, ipoID, category, multiple, applications, sharesOffered, recordedAt); err != nil {
+			return fmt.Errorf("failed to record subscription snapshot: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetHistory returns the IPO's subscription multiples, application counts and shares
+// offered bucketed by hour; when a category was scraped more than once in an hour, the
+// latest values are used
+func (s *SubscriptionService) GetHistory(ctx context.Context, ipoID uuid.UUID) (*models.SubscriptionHistory, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT DISTINCT ON (date_trunc('hour', recorded_at), category)
+		       date_trunc('hour', recorded_at) AS hour, category, multiple, applications, shares_offered
+		FROM ipo_subscription_history
+		WHERE ipo_id = $1
+		ORDER BY date_trunc('hour', recorded_at), category, recorded_at DESC
+	`, ipoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscription history: %w", err)
+	}
+	defer rows.Close()
+
+	history := &models.SubscriptionHistory{IPOID: ipoID, Categories: []string{}, Points: []models.SubscriptionHistoryPoint{}}
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var hour time.Time
+		var category string
+		var multiple float64
+		var applications, sharesOffered sql.NullInt64
+		if err := rows.Scan(&hour, &category, &multiple, &applications, &sharesOffered); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription history: %w", err)
+		}
+		if n := len(history.Points); n == 0 || !history.Points[n-1].RecordedAt.Equal(hour) {
+			history.Points = append(history.Points, models.SubscriptionHistoryPoint{
+				RecordedAt: hour,
+				Multiples:  make(map[string]float64),
+			})
+		}
+		point := &history.Points[len(history.Points)-1]
+		point.Multiples[category] = multiple
+		if applications.Valid {
+			if point.Applications == nil {
+				point.Applications = make(map[string]int64)
+			}
+			point.Applications[category] = applications.Int64
+		}
+		if sharesOffered.Valid {
+			if point.SharesOffered == nil {
+				point.SharesOffered = make(map[string]int64)
+			}
+			point.SharesOffered[category] = sharesOffered.Int64
+		}
+		seen[category] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscription history: %w", err)
+	}
+
+	for _, category := range subscriptionCategoryOrder {
+		if seen[category] {
+			history.Categories = append(history.Categories, category)
+		}
+	}
+	return history, nil
+}
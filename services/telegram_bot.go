@@ -0,0 +1,464 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// TelegramAPIBaseURL is the Bot API root; the bot token is appended per request
+const TelegramAPIBaseURL = "https://api.telegram.org"
+
+// telegramListLimit caps the IPOs listed by /upcoming
+const telegramListLimit = 10
+
+// TelegramUpdate is the subset of a Bot API update the bot handles
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+// TelegramMessage is an incoming chat message
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+// TelegramChat identifies the chat a message came from and replies go to
+type TelegramChat struct {
+	ID int64 `json:"id"`
+}
+
+// TelegramBot answers IPO queries and allotment checks in Telegram chats. Checks go
+// through the same cache, quota and check queue as POST /check, keyed by PAN hash.
+type TelegramBot struct {
+	Token      string
+	APIBaseURL string
+	Client     *http.Client
+	IPOs       *IPOService
+	Cache      *CacheService
+	Quota      *CheckQuotaService
+	Checks     *AllotmentCheckQueue
+	// CheckWait is how long /check waits for the registrar before replying that the
+	// result will follow in a separate message
+	CheckWait time.Duration
+	// Reminders, when set, enables /remind for UPI mandate deadline reminders
+	Reminders *MandateReminderService
+	// FingerprintKey keys the client fingerprints recorded with each chat's checks
+	FingerprintKey []byte
+
+	limiter *shared.TokenBucketLimiter
+	logger  *logrus.Logger
+}
+
+// NewTelegramBot creates a bot allowing each chat messagesPerMinute commands
+func NewTelegramBot(token string, ipos *IPOService, cache *CacheService, quota *CheckQuotaService, checks *AllotmentCheckQueue, messagesPerMinute int) *TelegramBot {
+	if messagesPerMinute <= 0 {
+		messagesPerMinute = 10
+	}
+	// Commands carry PANs, so the bot's own logger masks them too
+	logger := logrus.New()
+	shared.ScrubLoggerPII(logger)
+	return &TelegramBot{
+		Token:      token,
+		APIBaseURL: TelegramAPIBaseURL,
+		Client:     &http.Client{Timeout: 40 * time.Second},
+		IPOs:       ipos,
+		Cache:      cache,
+		Quota:      quota,
+		Checks:     checks,
+		CheckWait:  10 * time.Second,
+		limiter:    shared.NewTokenBucketLimiter(time.Minute/time.Duration(messagesPerMinute), messagesPerMinute),
+		logger:     logger,
+	}
+}
+
+// Start long-polls getUpdates until ctx is cancelled. Use it when no webhook is
+// configured; Telegram rejects getUpdates while a webhook is set.
+func (b *TelegramBot) Start(ctx context.Context) {
+	go func() {
+		var offset int64
+		for {
+			updates, err := b.getUpdates(ctx, offset)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				b.logger.WithError(err).Warn("Telegram getUpdates failed")
+				select {
+				case <-time.After(5 * time.Second):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			// Updates are answered in the background, like webhook updates, so a /check
+			// waiting on its registrar does not hold up polling for other chats
+			for _, update := range updates {
+				offset = update.UpdateID + 1
+				go b.HandleUpdate(ctx, update)
+			}
+		}
+	}()
+}
+
+// HandleUpdate answers one incoming update; updates without a text message are ignored
+func (b *TelegramBot) HandleUpdate(ctx context.Context, update TelegramUpdate) {
+	message := update.Message
+	if message == nil || strings.TrimSpace(message.Text) == "" {
+		return
+	}
+	chatID := message.Chat.ID
+
+	if !b.limiter.Allow(fmt.Sprintf("%d", chatID)) {
+		b.reply(ctx, chatID, "Too many requests. Please wait a minute and try again.")
+		return
+	}
+
+	fields := strings.Fields(message.Text)
+	// Commands may be addressed to the bot in groups, e.g. /gmp@allotra_bot
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+
+	var text string
+	switch command {
+	case "/start", "/help":
+		text = telegramHelpText
+	case "/upcoming":
+		text = b.upcomingText(ctx)
+	case "/gmp":
+		text = b.gmpText(ctx, strings.Join(args, " "))
+	case "/check":
+		text = b.check(ctx, chatID, args)
+	case "/remind":
+		text = b.remind(ctx, chatID, strings.Join(args, " "))
+	default:
+		text = "Unknown command.\n\n" + telegramHelpText
+	}
+	if text != "" {
+		b.reply(ctx, chatID, text)
+	}
+}
+
+const telegramHelpText = `Commands:
+/upcoming - live and upcoming IPOs
+/gmp <ipo> - grey market premium for an IPO
+/check <ipo> <pan> - allotment status for your PAN
+/remind <ipo> - reminder before the UPI mandate deadline
+
+<ipo> can be the company name, symbol or company code.`
+
+// upcomingText lists live and upcoming IPOs, most recently announced first
+func (b *TelegramBot) upcomingText(ctx context.Context) string {
+	ipos, err := b.IPOs.GetIPOs(ctx, "all")
+	if err != nil {
+		b.logger.WithError(err).Warn("Telegram /upcoming failed to load IPOs")
+		return "Couldn't load IPOs right now. Please try again later."
+	}
+
+	var lines []string
+	for _, ipo := range ipos {
+		if ipo.Status != "UPCOMING" && ipo.Status != "ACTIVE" {
+			continue
+		}
+		lines = append(lines, "• "+ipo.Name+": "+describeIPOForFeed(&ipo))
+		if len(lines) == telegramListLimit {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return "No live or upcoming IPOs right now."
+	}
+	return "Live and upcoming IPOs:\n" + strings.Join(lines, "\n")
+}
+
+// gmpText reports the latest GMP for the IPO matching query
+func (b *TelegramBot) gmpText(ctx context.Context, query string) string {
+	if strings.TrimSpace(query) == "" {
+		return "Usage: /gmp <ipo>"
+	}
+	ipo, reply := b.findIPO(ctx, query)
+	if ipo == nil {
+		return reply
+	}
+
+	withGMP, err := b.IPOs.GetIPOByIDWithGMP(ctx, ipo.ID.String())
+	if err != nil || withGMP == nil || withGMP.GMPValue == nil {
+		return fmt.Sprintf("No GMP data for %s yet.", ipo.Name)
+	}
+
+	text := fmt.Sprintf("%s GMP: ₹%.2f", ipo.Name, *withGMP.GMPValue)
+	if withGMP.GainPercent != nil {
+		text += fmt.Sprintf(" (%.2f%%)", *withGMP.GainPercent)
+	}
+	if withGMP.EstimatedListing != nil {
+		text += fmt.Sprintf("\nEstimated listing: ₹%.2f", *withGMP.EstimatedListing)
+	}
+	if withGMP.GMPLastUpdated != nil {
+		text += "\nUpdated " + withGMP.GMPLastUpdated.Format("2 Jan 2006 15:04")
+	}
+	return text
+}
+
+// check runs an allotment check the same way as POST /check: cached results are returned
+// without using quota, then the per-PAN quota is enforced and the check is queued
+func (b *TelegramBot) check(ctx context.Context, chatID int64, args []string) string {
+	if len(args) < 2 {
+		return "Usage: /check <ipo> <pan>"
+	}
+	pan := args[len(args)-1]
+	if !shared.IsValidPAN(pan) {
+		return "That doesn't look like a valid PAN (e.g. ABCDE1234F)."
+	}
+	ipo, reply := b.findIPO(ctx, strings.Join(args[:len(args)-1], " "))
+	if ipo == nil {
+		return reply
+	}
+	panHash := shared.HashPAN(pan)
+
+	cached, err := b.Cache.GetCachedResult(ctx, ipo.ID.String(), panHash)
+	if err != nil {
+		b.logger.WithError(err).Warn("Failed to read cached allotment result")
+	}
+	if cached != nil {
+		return formatTelegramResult(ipo.Name, cached)
+	}
+
+	var quota *CheckQuotaResult
+	if b.Quota != nil {
+		quota, err = b.Quota.Consume(ctx, panHash, ipo.ID.String())
+		if err != nil {
+			b.logger.WithError(err).Warn("Check quota unavailable, allowing request")
+		} else if !quota.Allowed {
+			return "Daily check limit reached for this PAN and IPO. Try again tomorrow."
+		}
+	}
+
+	fingerprint := fmt.Sprintf("telegram:%d", chatID)
+	check, done, err := b.Checks.Submit(ipo, shared.NormalizePAN(pan), CheckRequestMeta{
+		SourceChannel:     models.SourceChannelTelegram,
+		ClientFingerprint: shared.ClientFingerprint(b.FingerprintKey, fingerprint, "telegram"),
+		UserAgent:         "telegram-bot",
+		TelegramChatID:    chatID,
+	})
+	if err != nil {
+		// The registrar was never asked, so the lookup does not count
+		if b.Quota != nil {
+			if err := b.Quota.Refund(ctx, panHash, ipo.ID.String(), quota); err != nil {
+				b.logger.WithError(err).Warn("Failed to refund check quota")
+			}
+		}
+		return "The checker is busy right now. Please try again in a few minutes."
+	}
+
+	select {
+	case <-done:
+		return b.checkResultText(ipo.Name, check.CheckID)
+	case <-time.After(b.CheckWait):
+	case <-ctx.Done():
+	}
+
+	// Slow registrar: answer now and send the result when the check finishes
+	go func() {
+		<-done
+		b.reply(context.Background(), chatID, b.checkResultText(ipo.Name, check.CheckID))
+	}()
+	return fmt.Sprintf("Checking %s with the registrar. I'll send the result here shortly.", ipo.Name)
+}
+
+// checkResultText renders a finished check from the queue
+func (b *TelegramBot) checkResultText(ipoName, checkID string) string {
+	check := b.Checks.Get(checkID)
+	switch {
+	case check == nil:
+		return "The check result expired. Please run /check again."
+	case check.Status == models.CheckStatusFailed || check.Result == nil:
+		return fmt.Sprintf("Couldn't check %s right now: %s", ipoName, check.Error)
+	case check.Result.Status == models.AllotmentStatusNotDeclared && check.RecheckScheduled:
+		return fmt.Sprintf("%s: the registrar hasn't declared allotment results yet. I'll check again and message you here once they're out.", ipoName)
+	case check.Result.Status == models.AllotmentStatusNotDeclared:
+		return fmt.Sprintf("%s: the registrar hasn't declared allotment results yet. Please try again later.", ipoName)
+	default:
+		return formatTelegramResult(ipoName, check.Result)
+	}
+}
+
+// remind subscribes the chat to the UPI mandate reminder of the IPO matching query
+func (b *TelegramBot) remind(ctx context.Context, chatID int64, query string) string {
+	if b.Reminders == nil {
+		return "Reminders are not available right now."
+	}
+	if strings.TrimSpace(query) == "" {
+		return "Usage: /remind <ipo>"
+	}
+	ipo, notFound := b.findIPO(ctx, query)
+	if ipo == nil {
+		return notFound
+	}
+	deadline := MandateDeadline(ipo.CloseDate)
+	if deadline == nil {
+		return fmt.Sprintf("%s has no close date yet, so there is no mandate deadline to remind you of.", ipo.Name)
+	}
+	if !shared.ClockOrDefault(b.Reminders.Clock).Now().Before(*deadline) {
+		return fmt.Sprintf("The UPI mandate deadline for %s has passed.", ipo.Name)
+	}
+	if err := b.Reminders.Subscribe(ctx, ipo.ID, chatID); err != nil {
+		b.logger.WithError(err).Warn("Telegram bot failed to subscribe to mandate reminder")
+		return "Couldn't set the reminder right now. Please try again later."
+	}
+	return fmt.Sprintf("You'll be reminded before the UPI mandate deadline for %s (%s).",
+		ipo.Name, formatMandateDeadline(*deadline))
+}
+
+// SendMandateReminder reminds a chat to accept the UPI mandate of its IPO application
+func (b *TelegramBot) SendMandateReminder(ctx context.Context, chatID int64, ipoName string, deadline time.Time) {
+	b.reply(ctx, chatID, fmt.Sprintf("Reminder: accept the UPI mandate for your %s application by %s, or the bid lapses.",
+		ipoName, formatMandateDeadline(deadline)))
+}
+
+// formatMandateDeadline renders a mandate deadline in IST
+func formatMandateDeadline(deadline time.Time) string {
+	return deadline.In(istLocation()).Format("3 PM, 2 Jan") + " IST"
+}
+
+// SendRecheckResult sends a chat the result of a check that waited for results to be declared
+func (b *TelegramBot) SendRecheckResult(ctx context.Context, chatID int64, ipoName string, result *models.IPOResultCache) {
+	b.reply(ctx, chatID, "Allotment results are out.\n"+formatTelegramResult(ipoName, result))
+}
+
+// formatTelegramResult renders an allotment result for a chat reply
+func formatTelegramResult(ipoName string, result *models.IPOResultCache) string {
+	text := fmt.Sprintf("%s: %s", ipoName, strings.ReplaceAll(result.Status, "_", " "))
+	if result.SharesAllotted > 0 {
+		text += fmt.Sprintf("\nShares allotted: %d", result.SharesAllotted)
+	}
+	if result.RefundStatus != "" {
+		text += "\nRefund: " + result.RefundStatus
+	}
+	return text
+}
+
+// findIPO resolves a user query to an IPO by company code, symbol, slug or name; it
+// returns the reply to send when nothing or more than one IPO matches
+func (b *TelegramBot) findIPO(ctx context.Context, query string) (*models.IPO, string) {
+	ipos, err := b.IPOs.GetIPOs(ctx, "all")
+	if err != nil {
+		b.logger.WithError(err).Warn("Telegram bot failed to load IPOs")
+		return nil, "Couldn't load IPOs right now. Please try again later."
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []*models.IPO
+	for i := range ipos {
+		ipo := &ipos[i]
+		if strings.ToLower(ipo.CompanyCode) == query ||
+			(ipo.Symbol != nil && strings.ToLower(*ipo.Symbol) == query) ||
+			(ipo.Slug != nil && *ipo.Slug == query) ||
+			strings.ToLower(ipo.Name) == query {
+			return ipo, ""
+		}
+		if strings.Contains(strings.ToLower(ipo.Name), query) {
+			matches = append(matches, ipo)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Sprintf("No IPO found for %q.", query)
+	case 1:
+		return matches[0], ""
+	default:
+		names := make([]string, 0, telegramListLimit)
+		for _, match := range matches {
+			if len(names) == telegramListLimit {
+				break
+			}
+			names = append(names, "• "+match.Name)
+		}
+		return nil, "More than one IPO matches. Be more specific:\n" + strings.Join(names, "\n")
+	}
+}
+
+// reply sends a plain-text message to a chat
+func (b *TelegramBot) reply(ctx context.Context, chatID int64, text string) {
+	body, err := json.Marshal(map[string]interface{}{"chat_id": chatID, "text": text})
+	if err != nil {
+		return
+	}
+	if _, err := b.call(ctx, "sendMessage", body); err != nil {
+		b.logger.WithError(err).WithField("chat_id", chatID).Warn("Failed to send Telegram message")
+	}
+}
+
+// getUpdates long-polls for updates after offset
+func (b *TelegramBot) getUpdates(ctx context.Context, offset int64) ([]TelegramUpdate, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"offset":          offset,
+		"timeout":         30,
+		"allowed_updates": []string{"message"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.call(ctx, "getUpdates", body)
+	if err != nil {
+		return nil, err
+	}
+	var updates []TelegramUpdate
+	if err := json.Unmarshal(result, &updates); err != nil {
+		return nil, shared.ParseErrorf("invalid Telegram updates: %v", err)
+	}
+	return updates, nil
+}
+
+// call invokes a Bot API method and returns its result field. The request URL carries
+// the bot token, so errors name only the method.
+func (b *TelegramBot) call(ctx context.Context, method string, body []byte) (json.RawMessage, error) {
+	endpoint := fmt.Sprintf("%s/bot%s/%s", b.APIBaseURL, b.Token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Telegram %s request: %w", method, withoutURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return nil, shared.NetworkErrorf("Telegram %s request failed: %v", method, withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, shared.ParseErrorf("invalid Telegram %s response: %v", method, err)
+	}
+	if !envelope.OK {
+		return nil, fmt.Errorf("Telegram %s returned HTTP %d: %s", method, resp.StatusCode, envelope.Description)
+	}
+	return envelope.Result, nil
+}
+
+// withoutURL drops the *url.Error wrapper, which quotes the request URL and with it the
+// bot token, keeping only the underlying error
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultNavigationPatterns are Chittorgarh navigation elements, seeded into
+// scraper_text_patterns the first time the store loads from an empty table
+var defaultNavigationPatterns = []string{
+	// Remove dashboard and navigation elements (anywhere in text)
+	`(?i)\bdashboard\s*ipo\s*list\b`,
+	`(?i)\bipo\s*list\s*ipo\s*list\b`,
+	`(?i)\bdashboard\b`,
+	`(?i)\bipo\s*list\b`,
+
+	// Remove IPO details navigation (anywhere in text)
+	`(?i)\bipo\s*details\b`,
+	`(?i)\bbookbuilding\s*ipo\b`,
+	`(?i)\|\s*₹\d+\s*cr\s*\|`,
+	`(?i)₹\d+\s*cr\b`,
+
+	// Remove common navigation links (anywhere in text)
+	`(?i)\bmessages\b`,
+	`(?i)\bgmp\b`,
+	`(?i)\bdocs\b`,
+	`(?i)\brhp\b`,
+	`(?i)\bdrhp\b`,
+	`(?i)\banchor\s*investor\s*link\b`,
+	`(?i)\bsubscription\b`,
+	`(?i)\breviews\b`,
+	`(?i)\ballotment\b`,
+	`(?i)\bstock\s*price\b`,
+	`(?i)\bfinal\s*prospectus\b`,
+
+	// Remove listing information (anywhere in text)
+	`(?i)\blisting\s*at\s*bse\b`,
+	`(?i)\blisting\s*at\s*nse\b`,
+	`(?i)\blisted\s*at\s*bse\b`,
+	`(?i)\blisted\s*at\s*nse\b`,
+	`(?i)\bbse\s*nse\b`,
+	`(?i)\bnse\s*bse\b`,
+
+	// Remove additional navigation elements found in testing
+	`(?i)\bipo\s*news\b`,
+	`(?i)\bipo\s*calendar\b`,
+	`(?i)\bipo\s*performance\b`,
+	`(?i)\bipo\s*analysis\b`,
+	`(?i)\bipo\s*rating\b`,
+	`(?i)\bipo\s*recommendation\b`,
+	`(?i)\bipo\s*apply\b`,
+	`(?i)\bapply\s*online\b`,
+	`(?i)\bipo\s*forms\b`,
+	`(?i)\bipo\s*documents\b`,
+
+	// Remove menu and navigation text
+	`(?i)\bmenu\b`,
+	`(?i)\bnavigation\b`,
+	`(?i)\bhome\b`,
+	`(?i)\bback\s*to\s*top\b`,
+	`(?i)\bshare\s*this\b`,
+	`(?i)\bprint\s*this\b`,
+	`(?i)\bemail\s*this\b`,
+
+	// Remove common separators and formatting (anywhere in text)
+	`(?i)\s*\|\s*`,
+	`(?i)\s*-\s*`,
+	`(?i)\s*•\s*`,
+	`(?i)\s*→\s*`,
+	`(?i)\s*»\s*`,
+
+	// Remove standalone numbers and currency amounts that are navigation artifacts
+	`(?i)^\s*\d+\s*$`,
+	`(?i)^\s*₹\s*\d+\s*$`,
+	`(?i)^\s*rs\.?\s*\d+\s*$`,
+
+	// Remove common call-to-action phrases
+	`(?i)\bclick\s*here\b`,
+	`(?i)\bread\s*more\b`,
+	`(?i)\bmore\s*details\b`,
+	`(?i)\bview\s*details\b`,
+	`(?i)\bsee\s*more\b`,
+	`(?i)\blearn\s*more\b`,
+	`(?i)\bfind\s*out\s*more\b`,
+
+	// Remove date and time artifacts
+	`(?i)\bupdated\s*on\b`,
+	`(?i)\bpublished\s*on\b`,
+	`(?i)\blast\s*updated\b`,
+	`(?i)\bposted\s*on\b`,
+}
+
+// defaultBoilerplatePatterns are section labels and trailing call-to-action phrases
+var defaultBoilerplatePatterns = []string{
+	`(?i)^company description:\s*`,
+	`(?i)^about us:\s*`,
+	`(?i)^about the company:\s*`,
+	`(?i)^business overview:\s*`,
+	`(?i)^company details:\s*`,
+	`(?i)^business model:\s*`,
+	`(?i)^about:\s*`,
+	`(?i)\s*read more\s*$`,
+	`(?i)\s*click here for more\s*$`,
+	`(?i)\s*more details\s*$`,
+}
+
+// whitespacePattern collapses runs of whitespace left behind by navigation removal
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// TextPatternStore holds the compiled navigation and boilerplate patterns shared by
+// the IPO scraper and the GMP service. Patterns are persisted in scraper_text_patterns
+// and reloaded periodically so changes made on one replica reach the others.
+type TextPatternStore struct {
+	db     *sql.DB
+	logger *logrus.Entry
+
+	mutex       sync.RWMutex
+	patterns    []models.TextPattern
+	navigation  []*regexp.Regexp
+	boilerplate []*regexp.Regexp
+	loadedAt    time.Time
+}
+
+// NewTextPatternStore creates a store holding the built-in patterns; call Reload to
+// replace them with the persisted set
+func NewTextPatternStore(db *sql.DB) *TextPatternStore {
+	store := &TextPatternStore{
+		db:     db,
+		logger: logrus.WithField("component", "text_pattern_store"),
+	}
+
+	var patterns []models.TextPattern
+	for i, pattern := range defaultNavigationPatterns {
+		patterns = append(patterns, models.TextPattern{Kind: models.TextPatternNavigation, Pattern: pattern, Position: i})
+	}
+	for i, pattern := range defaultBoilerplatePatterns {
+		patterns = append(patterns, models.TextPattern{Kind: models.TextPatternBoilerplate, Pattern: pattern, Position: i})
+	}
+	store.replace(patterns)
+
+	return store
+}
+
+// DefaultTextPatterns is the pattern set used by the extractors
+var DefaultTextPatterns = NewTextPatternStore(nil)
+
+// SetDB attaches the database the store loads from and persists to
+func (s *TextPatternStore) SetDB(db *sql.DB) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.db = db
+}
+
+// database returns the attached database, or an error when there is none
+func (s *TextPatternStore) database() (*sql.DB, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	return s.db, nil
+}
+
+// Navigation returns the compiled navigation patterns in application order
+func (s *TextPatternStore) Navigation() []*regexp.Regexp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.navigation
+}
+
+// Boilerplate returns the compiled boilerplate patterns in application order
+func (s *TextPatternStore) Boilerplate() []*regexp.Regexp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.boilerplate
+}
+
+// Patterns returns the loaded patterns and when they were loaded
+func (s *TextPatternStore) Patterns() ([]models.TextPattern, time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]models.TextPattern(nil), s.patterns...), s.loadedAt
+}
+
+// RemoveNavigation replaces navigation matches with spaces and collapses whitespace
+func (s *TextPatternStore) RemoveNavigation(text string) string {
+	for _, regex := range s.Navigation() {
+		text = regex.ReplaceAllString(text, " ")
+	}
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+// Start reloads the persisted patterns every interval until ctx is cancelled
+func (s *TextPatternStore) Start(ctx context.Context, interval time.Duration) {
+	if err := s.Reload(ctx); err != nil {
+		s.logger.WithError(err).Warn("Failed to load text patterns, using built-in defaults")
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(ctx); err != nil {
+					s.logger.WithError(err).Warn("Failed to reload text patterns")
+				}
+			}
+		}
+	}()
+}
+
+// Reload replaces the in-memory patterns with the persisted set. The first time the
+// table is found empty it is seeded with the built-in defaults; once seeded, an empty
+// table means an admin removed every pattern and stays empty.
+func (s *TextPatternStore) Reload(ctx context.Context) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+
+	patterns, err := s.loadPatterns(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(patterns) == 0 {
+		seeded, err := s.seedDefaults(ctx, db)
+		if err != nil {
+			return err
+		}
+		if seeded {
+			if patterns, err = s.loadPatterns(ctx, db); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.replace(patterns)
+	return nil
+}
+
+// AddPattern validates and persists a pattern at the end of its kind, then reloads
+func (s *TextPatternStore) AddPattern(ctx context.Context, pattern models.TextPattern) (*models.TextPattern, error) {
+	if err := validateTextPattern(pattern.Kind, pattern.Pattern); err != nil {
+		return nil, err
+	}
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern.ID = uuid.New()
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO scraper_text_patterns (id, kind, pattern, description, position, created_by)
+		SELECT $1, $2, $3, $4, COALESCE(MAX(position) + 1, 0), $5
+		FROM scraper_text_patterns WHERE kind = $2
+		RETURNING position, created_at
+	`, pattern.ID, pattern.Kind, pattern.Pattern, pattern.Description, pattern.CreatedBy).Scan(&pattern.Position, &pattern.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, shared.ValidationErrorf("pattern already exists for kind %s", pattern.Kind)
+		}
+		return nil, fmt.Errorf("failed to save text pattern: %w", err)
+	}
+
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return &pattern, nil
+}
+
+// RemovePattern deletes a persisted pattern, then reloads
+func (s *TextPatternStore) RemovePattern(ctx context.Context, id uuid.UUID) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+
+	// Tables seeded before the flag existed are marked now, so removing their last
+	// pattern does not bring the defaults back
+	if _, err := db.ExecContext(ctx, `INSERT INTO scraper_text_patterns_seeded (id) VALUES (TRUE) ON CONFLICT DO NOTHING`); err != nil {
+		return fmt.Errorf("failed to mark text patterns seeded: %w", err)
+	}
+	result, err := db.ExecContext(ctx, `DELETE FROM scraper_text_patterns WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete text pattern: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return shared.NotFoundErrorf("text pattern %s not found", id)
+	}
+
+	return s.Reload(ctx)
+}
+
+// TestPattern applies a candidate pattern, or the current set of its kind when the
+// pattern is empty, to sample text without persisting anything
+func (s *TextPatternStore) TestPattern(kind, pattern, text string) (*models.TextPatternTestResult, error) {
+	result := &models.TextPatternTestResult{Kind: kind, Pattern: pattern, Matches: []string{}}
+
+	var regexes []*regexp.Regexp
+	switch {
+	case pattern != "":
+		if err := validateTextPattern(kind, pattern); err != nil {
+			return nil, err
+		}
+		regexes = []*regexp.Regexp{regexp.MustCompile(pattern)}
+	case kind == models.TextPatternNavigation:
+		regexes = s.Navigation()
+	case kind == models.TextPatternBoilerplate:
+		regexes = s.Boilerplate()
+	default:
+		return nil, shared.ValidationErrorf("kind must be %q or %q", models.TextPatternNavigation, models.TextPatternBoilerplate)
+	}
+
+	cleaned := text
+	for _, regex := range regexes {
+		result.Matches = append(result.Matches, regex.FindAllString(cleaned, -1)...)
+		if kind == models.TextPatternNavigation {
+			cleaned = regex.ReplaceAllString(cleaned, " ")
+		} else {
+			cleaned = regex.ReplaceAllString(cleaned, "")
+		}
+	}
+	result.CleanedText = strings.TrimSpace(whitespacePattern.ReplaceAllString(cleaned, " "))
+
+	return result, nil
+}
+
+// validateTextPattern checks the kind and that the pattern compiles
+func validateTextPattern(kind, pattern string) error {
+	if kind != models.TextPatternNavigation && kind != models.TextPatternBoilerplate {
+		return shared.ValidationErrorf("kind must be %q or %q", models.TextPatternNavigation, models.TextPatternBoilerplate)
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return shared.ValidationErrorf("pattern is required")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return shared.ValidationErrorf("invalid pattern: %w", err)
+	}
+	return nil
+}
+
+// loadPatterns reads the persisted patterns in application order
+func (s *TextPatternStore) loadPatterns(ctx context.Context, db *sql.DB) ([]models.TextPattern, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, kind, pattern, description, position, created_by, created_at
+		FROM scraper_text_patterns
+		ORDER BY kind, position
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query text patterns: %w", err)
+	}
+	defer rows.Close()
+
+	var patterns []models.TextPattern
+	for rows.Next() {
+		var pattern models.TextPattern
+		if err := rows.Scan(&pattern.ID, &pattern.Kind, &pattern.Pattern, &pattern.Description,
+			&pattern.Position, &pattern.CreatedBy, &pattern.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan text pattern: %w", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, rows.Err()
+}
+
+// seedDefaults inserts the built-in patterns unless the table was seeded before, and
+// reports whether it did. The seeded flag is claimed in the same transaction, so only
+// one replica seeds.
+func (s *TextPatternStore) seedDefaults(ctx context.Context, db *sql.DB) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claimed, err := tx.ExecContext(ctx, `INSERT INTO scraper_text_patterns_seeded (id) VALUES (TRUE) ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim text pattern seeding: %w", err)
+	}
+	if affected, _ := claimed.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	insert := func(kind string, patterns []string) error {
+		for i, pattern := range patterns {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO scraper_text_patterns (id, kind, pattern, position, created_by)
+				VALUES ($1, $2, $3, $4, 'system')
+				ON CONFLICT (kind, pattern) DO NOTHING
+			`, uuid.New(), kind, pattern, i); err != nil {
+				return fmt.Errorf("failed to seed text pattern: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := insert(models.TextPatternNavigation, defaultNavigationPatterns); err != nil {
+		return false, err
+	}
+	if err := insert(models.TextPatternBoilerplate, defaultBoilerplatePatterns); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Seeded scraper_text_patterns with built-in defaults")
+	return true, nil
+}
+
+// replace compiles patterns and swaps them in; patterns that fail to compile are skipped
+func (s *TextPatternStore) replace(patterns []models.TextPattern) {
+	var navigation, boilerplate []*regexp.Regexp
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"pattern_id": pattern.ID,
+				"pattern":    pattern.Pattern,
+				"error":      err,
+			}).Warn("Skipping text pattern that does not compile")
+			continue
+		}
+		switch pattern.Kind {
+		case models.TextPatternNavigation:
+			navigation = append(navigation, regex)
+		case models.TextPatternBoilerplate:
+			boilerplate = append(boilerplate, regex)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.patterns = patterns
+	s.navigation = navigation
+	s.boilerplate = boilerplate
+	s.loadedAt = time.Now()
+}
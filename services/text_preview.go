@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// Default preview lengths of IPO texts in responses, in bytes. The full text is stored;
+// previews are cut at response time.
+const (
+	DefaultDescriptionPreviewLength = 2000
+	DefaultAboutPreviewLength       = 5000
+)
+
+// TruncationMarker ends a text cut to its preview length
+const TruncationMarker = "..."
+
+// previewWordSearch is how far back from the limit a preview looks for a space to cut at
+const previewWordSearch = 50
+
+// TextPreviewLimits are the preview lengths of an IPO's description and about texts
+type TextPreviewLimits struct {
+	Description int
+	About       int
+}
+
+// DefaultTextPreviewLimits returns the default preview lengths
+func DefaultTextPreviewLimits() TextPreviewLimits {
+	return TextPreviewLimits{Description: DefaultDescriptionPreviewLength, About: DefaultAboutPreviewLength}
+}
+
+// Apply cuts the IPO's description and about to their preview lengths and flags the
+// ones that were cut. A limit of 0 or less leaves that text whole.
+func (l TextPreviewLimits) Apply(ipo *models.IPO) {
+	ipo.Description, ipo.DescriptionTruncated = previewTextPtr(ipo.Description, l.Description)
+	ipo.About, ipo.AboutTruncated = previewTextPtr(ipo.About, l.About)
+}
+
+func previewTextPtr(text *string, limit int) (*string, bool) {
+	if text == nil {
+		return nil, false
+	}
+	preview, truncated := PreviewText(*text, limit)
+	return &preview, truncated
+}
+
+// PreviewText cuts text to at most limit bytes plus TruncationMarker, at the last space
+// within the final 50 bytes when there is one so words stay whole, and reports whether
+// it was cut. Text within the limit, or any text when limit is 0 or less, is returned as is.
+func PreviewText(text string, limit int) (string, bool) {
+	if limit <= 0 || len(text) <= limit {
+		return text, false
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if space := strings.LastIndexByte(text[:cut], ' '); space >= 0 && space >= cut-previewWordSearch {
+		cut = space
+	}
+	return strings.TrimRight(text[:cut], " ") + TruncationMarker, true
+}
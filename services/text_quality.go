@@ -0,0 +1,220 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Text quality verdicts
+const (
+	TextQualityAccept = "accept"
+	TextQualityFlag   = "flag"
+	TextQualityReject = "reject"
+)
+
+// TextQualityResult is the classifier's assessment of an extracted description or about text
+type TextQualityResult struct {
+	Score           float64  `json:"score"` // 0-100, higher is more likely real prose
+	Verdict         string   `json:"verdict"`
+	WordCount       int      `json:"word_count"`
+	SentenceCount   int      `json:"sentence_count"`
+	StopwordRatio   float64  `json:"stopword_ratio"`
+	NavKeywordRatio float64  `json:"nav_keyword_ratio"`
+	ShortTokenRatio float64  `json:"short_token_ratio"`
+	Reasons         []string `json:"reasons,omitempty"`
+}
+
+// TextQualityClassifier scores extracted text with simple heuristics so navigation
+// menus and link lists are not persisted as company descriptions
+type TextQualityClassifier struct {
+	RejectBelow float64 // Scores below this are rejected
+	FlagBelow   float64 // Scores below this are kept but logged as suspect
+}
+
+// NewTextQualityClassifier creates a classifier with the default thresholds
+func NewTextQualityClassifier() *TextQualityClassifier {
+	return &TextQualityClassifier{
+		RejectBelow: 40,
+		FlagBelow:   60,
+	}
+}
+
+// defaultTextQualityClassifier is used by HTMLDataExtractor
+var defaultTextQualityClassifier = NewTextQualityClassifier()
+
+// englishStopwords are common function words; real prose uses many of them, menus almost none
+var englishStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true,
+	"in": true, "on": true, "for": true, "with": true, "by": true, "from": true, "as": true,
+	"at": true, "is": true, "are": true, "was": true, "were": true, "be": true, "been": true,
+	"has": true, "have": true, "had": true, "its": true, "it": true, "this": true, "that": true,
+	"which": true, "who": true, "their": true, "they": true, "we": true, "our": true, "into": true,
+	"also": true, "such": true, "other": true, "than": true, "through": true, "across": true,
+}
+
+// navigationKeywords are words typical of site chrome rather than company text
+var navigationKeywords = map[string]bool{
+	"home": true, "menu": true, "login": true, "logout": true, "register": true, "signup": true,
+	"dashboard": true, "subscribe": true, "download": true, "contact": true, "privacy": true,
+	"terms": true, "sitemap": true, "faq": true, "copyright": true, "reserved": true,
+	"next": true, "previous": true, "prev": true, "click": true, "here": true, "more": true,
+	"share": true, "print": true, "email": true, "follow": true, "search": true, "calendar": true,
+	"gmp": true, "rhp": true, "drhp": true, "allotment": true, "reviews": true, "messages": true,
+	"docs": true, "news": true, "forum": true, "mainboard": true, "sme": true, "list": true,
+}
+
+var (
+	textQualityWordPattern     = regexp.MustCompile(`[\p{L}\p{N}']+`)
+	textQualitySentencePattern = regexp.MustCompile(`[.!?](\s|$)`)
+)
+
+// Classify scores text. Each heuristic subtracts from a perfect score and records why.
+func (c *TextQualityClassifier) Classify(text string) TextQualityResult {
+	words := textQualityWordPattern.FindAllString(strings.ToLower(text), -1)
+	result := TextQualityResult{
+		WordCount:     len(words),
+		SentenceCount: len(textQualitySentencePattern.FindAllString(text, -1)),
+	}
+	if len(words) == 0 {
+		result.Verdict = TextQualityReject
+		result.Reasons = []string{"no words"}
+		return result
+	}
+
+	var stopwords, navWords, shortTokens int
+	for _, word := range words {
+		if englishStopwords[word] {
+			stopwords++
+		}
+		if navigationKeywords[word] {
+			navWords++
+		}
+		if len(word) <= 2 && !englishStopwords[word] {
+			shortTokens++
+		}
+	}
+	total := float64(len(words))
+	result.StopwordRatio = roundTo(float64(stopwords)/total, 3)
+	result.NavKeywordRatio = roundTo(float64(navWords)/total, 3)
+	result.ShortTokenRatio = roundTo(float64(shortTokens)/total, 3)
+
+	score := 100.0
+	penalize := func(points float64, reason string) {
+		score -= points
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	// Company descriptions are sentences; menus are fragments without terminal punctuation
+	if result.SentenceCount == 0 {
+		penalize(25, "no complete sentences")
+	}
+	if len(words) < 8 {
+		penalize(20, "fewer than 8 words")
+	}
+
+	// English prose is roughly 30-50% stopwords; link lists are close to 0%
+	switch {
+	case result.StopwordRatio < 0.08:
+		penalize(30, "almost no stopwords")
+	case result.StopwordRatio < 0.15:
+		penalize(15, "low stopword ratio")
+	}
+
+	switch {
+	case result.NavKeywordRatio > 0.25:
+		penalize(40, "dominated by navigation keywords")
+	case result.NavKeywordRatio > 0.1:
+		penalize(20, "many navigation keywords")
+	}
+
+	if result.ShortTokenRatio > 0.3 {
+		penalize(15, "many short tokens")
+	}
+
+	// Long runs without punctuation are usually concatenated link text
+	if result.SentenceCount > 0 && float64(len(words))/float64(result.SentenceCount) > 80 {
+		penalize(10, "very long sentences")
+	}
+
+	result.Score = math.Max(0, score)
+	switch {
+	case result.Score < c.RejectBelow:
+		result.Verdict = TextQualityReject
+	case result.Score < c.FlagBelow:
+		result.Verdict = TextQualityFlag
+	default:
+		result.Verdict = TextQualityAccept
+	}
+	return result
+}
+
+// TextQualityStats aggregates classifier verdicts for one field and selector profile
+type TextQualityStats struct {
+	Field        string  `json:"field"`
+	Selector     string  `json:"selector"`
+	Accepted     int64   `json:"accepted"`
+	Flagged      int64   `json:"flagged"`
+	Rejected     int64   `json:"rejected"`
+	AverageScore float64 `json:"average_score"`
+	scoreSum     float64
+}
+
+// TextQualityMetrics counts classifier verdicts per field and selector, so selectors
+// that mostly match site chrome can be spotted and removed
+type TextQualityMetrics struct {
+	mutex sync.Mutex
+	stats map[string]*TextQualityStats
+}
+
+// NewTextQualityMetrics creates an empty metrics collector
+func NewTextQualityMetrics() *TextQualityMetrics {
+	return &TextQualityMetrics{stats: make(map[string]*TextQualityStats)}
+}
+
+// DefaultTextQualityMetrics collects verdicts from the IPO scraper
+var DefaultTextQualityMetrics = NewTextQualityMetrics()
+
+// Record adds a classifier result for field extracted with selector
+func (m *TextQualityMetrics) Record(field, selector string, result TextQualityResult) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := field + "|" + selector
+	stats, exists := m.stats[key]
+	if !exists {
+		stats = &TextQualityStats{Field: field, Selector: selector}
+		m.stats[key] = stats
+	}
+
+	switch result.Verdict {
+	case TextQualityReject:
+		stats.Rejected++
+	case TextQualityFlag:
+		stats.Flagged++
+	default:
+		stats.Accepted++
+	}
+	stats.scoreSum += result.Score
+	stats.AverageScore = roundTo(stats.scoreSum/float64(stats.Accepted+stats.Flagged+stats.Rejected), 1)
+}
+
+// Snapshot returns the stats sorted by field, then by rejection count
+func (m *TextQualityMetrics) Snapshot() []TextQualityStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot := make([]TextQualityStats, 0, len(m.stats))
+	for _, stats := range m.stats {
+		snapshot = append(snapshot, *stats)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Field != snapshot[j].Field {
+			return snapshot[i].Field < snapshot[j].Field
+		}
+		return snapshot[i].Rejected > snapshot[j].Rejected
+	})
+	return snapshot
+}
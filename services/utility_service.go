@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/shared"
 	"github.com/gocolly/colly/v2"
 	"github.com/sirupsen/logrus"
@@ -14,12 +15,15 @@ import (
 
 // UtilityService provides text processing, normalization, and table parsing utilities
 type UtilityService struct {
+	// Clock is the time IPO statuses are calculated at
+	Clock          shared.Clock
 	serviceMetrics *shared.ServiceMetrics
 }
 
 // NewUtilityService creates a new utility service instance
 func NewUtilityService() *UtilityService {
 	return &UtilityService{
+		Clock:          shared.DefaultClock,
 		serviceMetrics: shared.NewServiceMetrics("Utility_Service"),
 	}
 }
@@ -746,26 +750,38 @@ func (s *UtilityService) cleanCellText(text string) string {
 // - Between open and close date: "ACTIVE"
 // - After close date: "CLOSED"
 // - After listing date: "LISTED"
-func (s *UtilityService) CalculateIPOStatus(openDate, closeDate, listingDate *time.Time) string {
-	now := time.Now()
+func (s *UtilityService) CalculateIPOStatus(openDate, closeDate, listingDate *models.Date) string {
+	return s.CalculateIPOStatusAt(openDate, closeDate, listingDate, shared.ClockOrDefault(s.Clock).Now())
+}
+
+// CalculateIPOStatusAt calculates the status an IPO has at the given time, following the
+// same rules as CalculateIPOStatus. Bidding closes at the default cutoffs on the close
+// date.
+func (s *UtilityService) CalculateIPOStatusAt(openDate, closeDate, listingDate *models.Date, now time.Time) string {
+	return s.CalculateIPOStatusClosingAt(openDate, biddingClosesAt(CloseTimes(closeDate, nil, nil)), listingDate, now)
+}
+
+// CalculateIPOStatusClosingAt calculates the status an IPO whose bidding closes at
+// closesAt has at the given time. The IPO is CLOSED from closesAt on.
+func (s *UtilityService) CalculateIPOStatusClosingAt(openDate *models.Date, closesAt *time.Time, listingDate *models.Date, now time.Time) string {
 
 	// If we have a listing date and it's passed, IPO is listed
-	if listingDate != nil && now.After(*listingDate) {
+	if listingDate != nil && now.After(listingDate.Time) {
 		return "LISTED"
 	}
 
-	// If we have a close date and it's passed, IPO is closed
-	if closeDate != nil && now.After(*closeDate) {
+	// If bidding has closed, IPO is closed
+	if closesAt != nil && !now.Before(*closesAt) {
 		return "CLOSED"
 	}
 
 	// If we have an open date and it's passed but close date hasn't, IPO is active
-	if openDate != nil && now.After(*openDate) {
+	if openDate != nil && now.After(openDate.Time) {
 		return "ACTIVE"
 	}
 
 	// If we have an open date and it's in the future, IPO is upcoming
-	if openDate != nil && now.Before(*openDate) {
+	if openDate != nil && now.Before(openDate.Time) {
 		return "UPCOMING"
 	}
 
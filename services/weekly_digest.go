@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultDigestSendAt is the time of day (IST) on Sundays from which the weekly digest
+// email goes out
+const DefaultDigestSendAt = 18 * time.Hour
+
+// DigestMailer emails a rendered digest to one recipient
+type DigestMailer interface {
+	SendDigest(ctx context.Context, to, subject, body string) error
+}
+
+// SendDigest emails the digest through the login email server
+func (s *SMTPLoginSender) SendDigest(ctx context.Context, to, subject, body string) error {
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", s.From, to, subject, body)
+	if err := sendSMTPMail(ctx, s.Addr, s.Username, s.Password, s.From, []string{to}, message); err != nil {
+		return shared.NetworkErrorf("failed to send digest email: %w", err)
+	}
+	return nil
+}
+
+// WeeklyDigestService summarizes the IPOs opening, closing and listing in the coming
+// week, and emails the summary on Sunday evenings to accounts that subscribed
+type WeeklyDigestService struct {
+	DB *sql.DB
+	// Mailer, when set, emails the digest to subscribed accounts
+	Mailer DigestMailer
+	// SendAt is the time of day (IST) on Sundays from which the email goes out
+	SendAt time.Duration
+	// Clock decides which week is upcoming and whether the email is due
+	Clock shared.Clock
+
+	logger *logrus.Entry
+}
+
+func NewWeeklyDigestService(db *sql.DB) *WeeklyDigestService {
+	return &WeeklyDigestService{
+		DB:     db,
+		SendAt: DefaultDigestSendAt,
+		Clock:  shared.DefaultClock,
+		logger: logrus.WithField("component", "weekly_digest"),
+	}
+}
+
+// UpcomingWeek returns the Monday and Sunday of the week after now's IST date. On a
+// Sunday that is the week starting tomorrow.
+func UpcomingWeek(now time.Time) (models.Date, models.Date) {
+	today := models.NewDate(now)
+	days := (8 - int(today.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+	start := models.Date{Time: today.AddDate(0, 0, days)}
+	return start, models.Date{Time: start.AddDate(0, 0, 6)}
+}
+
+// Upcoming returns the digest of the coming week
+func (s *WeeklyDigestService) Upcoming(ctx context.Context) (*models.WeeklyDigest, error) {
+	now := shared.ClockOrDefault(s.Clock).Now()
+	start, _ := UpcomingWeek(now)
+	ipos, err := s.weekIPOs(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	digest := BuildWeeklyDigest(start, ipos)
+	digest.GeneratedAt = now
+	return digest, nil
+}
+
+// weekIPOs returns the IPOs opening, closing or listing in the week starting on start,
+// with their latest GMP
+func (s *WeeklyDigestService) weekIPOs(ctx context.Context, start models.Date) ([]models.DigestIPO, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT i.id, i.name, i.symbol, i.slug, i.open_date, i.close_date, i.listing_date,
+			i.price_band_low, i.price_band_high, i.issue_size, i.issue_size_amount,
+			g.gmp_value, g.gain_percent
+		FROM ipo_list i
+		LEFT JOIN LATERAL (
+			SELECT gmp.gmp_value, gmp.gain_percent FROM ipo_gmp gmp
+			WHERE (i.stock_id IS NOT NULL AND gmp.stock_id IS NOT NULL AND i.stock_id = gmp.stock_id)
+			   OR i.company_code = gmp.company_code
+			ORDER BY
+				CASE WHEN i.stock_id IS NOT NULL AND gmp.stock_id IS NOT NULL AND i.stock_id = gmp.stock_id THEN 1 ELSE 2 END,
+				gmp.last_updated DESC
+			LIMIT 1
+		) g ON TRUE
+		WHERE i.deleted_at IS NULL
+		  AND (i.open_date::date BETWEEN $1::date AND $1::date + 6
+		       OR i.close_date::date BETWEEN $1::date AND $1::date + 6
+		       OR i.listing_date::date BETWEEN $1::date AND $1::date + 6)
+	`, start.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPOs of the week: %w", err)
+	}
+	defer rows.Close()
+
+	var ipos []models.DigestIPO
+	for rows.Next() {
+		var ipo models.DigestIPO
+		if err := rows.Scan(&ipo.ID, &ipo.Name, &ipo.Symbol, &ipo.Slug, &ipo.OpenDate, &ipo.CloseDate,
+			&ipo.ListingDate, &ipo.PriceBandLow, &ipo.PriceBandHigh, &ipo.IssueSize, &ipo.IssueSizeAmount,
+			&ipo.GMPValue, &ipo.GainPercent); err != nil {
+			return nil, fmt.Errorf("failed to scan IPO of the week: %w", err)
+		}
+		ipos = append(ipos, ipo)
+	}
+	return ipos, rows.Err()
+}
+
+// BuildWeeklyDigest sorts ipos into the ones opening, closing and listing in the week
+// starting on start, each list by date and then name. IPOs with no date in the week are
+// left out.
+func BuildWeeklyDigest(start models.Date, ipos []models.DigestIPO) *models.WeeklyDigest {
+	end := models.Date{Time: start.AddDate(0, 0, 6)}
+	digest := &models.WeeklyDigest{
+		WeekStart: start,
+		WeekEnd:   end,
+		Opening:   []models.DigestIPO{},
+		Closing:   []models.DigestIPO{},
+		Listing:   []models.DigestIPO{},
+	}
+	inWeek := func(date *models.Date) bool {
+		return date != nil && !date.Before(start.Time) && !date.After(end.Time)
+	}
+	for _, ipo := range ipos {
+		if inWeek(ipo.OpenDate) {
+			digest.Opening = append(digest.Opening, ipo)
+		}
+		if inWeek(ipo.CloseDate) {
+			digest.Closing = append(digest.Closing, ipo)
+		}
+		if inWeek(ipo.ListingDate) {
+			digest.Listing = append(digest.Listing, ipo)
+		}
+	}
+
+	sortByDate := func(list []models.DigestIPO, date func(models.DigestIPO) *models.Date) {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := date(list[i]), date(list[j])
+			if !a.Equal(b.Time) {
+				return a.Before(b.Time)
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+	sortByDate(digest.Opening, func(ipo models.DigestIPO) *models.Date { return ipo.OpenDate })
+	sortByDate(digest.Closing, func(ipo models.DigestIPO) *models.Date { return ipo.CloseDate })
+	sortByDate(digest.Listing, func(ipo models.DigestIPO) *models.Date { return ipo.ListingDate })
+	return digest
+}
+
+// RenderWeeklyDigestEmail returns the subject and plain-text body of the digest email
+func RenderWeeklyDigestEmail(digest *models.WeeklyDigest) (string, string) {
+	subject := fmt.Sprintf("IPO week ahead: %s to %s",
+		digest.WeekStart.Format("2 Jan"), digest.WeekEnd.Format("2 Jan 2006"))
+
+	var body strings.Builder
+	section := func(title string, ipos []models.DigestIPO, date func(models.DigestIPO) *models.Date) {
+		if len(ipos) == 0 {
+			return
+		}
+		fmt.Fprintf(&body, "%s\n", title)
+		for _, ipo := range ipos {
+			fmt.Fprintf(&body, "- %s, %s", ipo.Name, date(ipo).Format("Mon 2 Jan"))
+			if ipo.PriceBandLow != nil && ipo.PriceBandHigh != nil {
+				fmt.Fprintf(&body, ", price band Rs %.0f-%.0f", *ipo.PriceBandLow, *ipo.PriceBandHigh)
+			}
+			if ipo.IssueSize != nil && *ipo.IssueSize != "" {
+				fmt.Fprintf(&body, ", issue size %s", *ipo.IssueSize)
+			}
+			if ipo.GMPValue != nil {
+				fmt.Fprintf(&body, ", GMP Rs %.0f", *ipo.GMPValue)
+				if ipo.GainPercent != nil {
+					fmt.Fprintf(&body, " (%+.1f%%)", *ipo.GainPercent)
+				}
+			}
+			body.WriteString("\n")
+		}
+		body.WriteString("\n")
+	}
+	section("Opening", digest.Opening, func(ipo models.DigestIPO) *models.Date { return ipo.OpenDate })
+	section("Closing", digest.Closing, func(ipo models.DigestIPO) *models.Date { return ipo.CloseDate })
+	section("Listing", digest.Listing, func(ipo models.DigestIPO) *models.Date { return ipo.ListingDate })
+	if digest.Empty() {
+		body.WriteString("No IPOs open, close or list in the week.\n\n")
+	}
+	body.WriteString("GMP is the unofficial grey market premium and can change before listing.\n")
+	return subject, body.String()
+}
+
+// Subscribe signs the account up for the weekly digest email. The account needs an
+// email address. Subscribing again is a no-op.
+func (s *WeeklyDigestService) Subscribe(ctx context.Context, account *models.Account) error {
+	if account.Email == nil || *account.Email == "" {
+		return shared.ValidationErrorf("the weekly digest is sent by email; sign in with an email address to subscribe")
+	}
+	if _, err := s.DB.ExecContext(ctx, `
+		INSERT INTO digest_subscriptions (account_id) VALUES ($1)
+		ON CONFLICT (account_id) DO NOTHING
+	`, account.ID); err != nil {
+		return fmt.Errorf("failed to subscribe to weekly digest: %w", err)
+	}
+	return nil
+}
+
+// Unsubscribe stops the weekly digest email of the account
+func (s *WeeklyDigestService) Unsubscribe(ctx context.Context, accountID uuid.UUID) error {
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM digest_subscriptions WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to unsubscribe from weekly digest: %w", err)
+	}
+	return nil
+}
+
+// Subscribed reports whether the account gets the weekly digest email
+func (s *WeeklyDigestService) Subscribed(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	var subscribed bool
+	if err := s.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM digest_subscriptions WHERE account_id = $1)
+	`, accountID).Scan(&subscribed); err != nil {
+		return false, fmt.Errorf("failed to check weekly digest subscription: %w", err)
+	}
+	return subscribed, nil
+}
+
+// SendDue emails the upcoming week's digest to every subscribed account not yet sent
+// it, once it is Sunday past SendAt (IST). Weeks without IPOs are skipped. Returns how
+// many emails were sent.
+func (s *WeeklyDigestService) SendDue(ctx context.Context) (int, error) {
+	if s.Mailer == nil {
+		return 0, nil
+	}
+	now := shared.ClockOrDefault(s.Clock).Now()
+	today := models.NewDate(now)
+	if today.Weekday() != time.Sunday || now.Before(today.Add(s.SendAt)) {
+		return 0, nil
+	}
+
+	digest, err := s.Upcoming(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if digest.Empty() {
+		return 0, nil
+	}
+	recipients, err := s.claimRecipients(ctx, digest.WeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	subject, body := RenderWeeklyDigestEmail(digest)
+	sent := 0
+	for _, recipient := range recipients {
+		if err := s.Mailer.SendDigest(ctx, recipient.email, subject, body); err != nil {
+			s.logger.WithError(err).Warn("Failed to send weekly digest")
+			s.releaseRecipient(ctx, recipient, digest.WeekStart)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// digestRecipient is a claimed subscription, with the values the claim replaced so a
+// failed send can restore them
+type digestRecipient struct {
+	accountID    uuid.UUID
+	email        string
+	lastSentWeek *models.Date
+	lastSentAt   *time.Time
+}
+
+// claimRecipients marks the subscriptions not yet sent the week's digest as sent and
+// returns them, so each account is emailed by one replica only. A failed send releases
+// its claim with releaseRecipient.
+func (s *WeeklyDigestService) claimRecipients(ctx context.Context, weekStart models.Date) ([]digestRecipient, error) {
+	// previous is read from the snapshot before the update, so it holds the replaced values
+	rows, err := s.DB.QueryContext(ctx, `
+		UPDATE digest_subscriptions d
+		SET last_sent_week = $1::date, last_sent_at = CURRENT_TIMESTAMP
+		FROM accounts a, digest_subscriptions previous
+		WHERE a.id = d.account_id AND previous.account_id = d.account_id AND a.email IS NOT NULL
+		  AND (d.last_sent_week IS NULL OR d.last_sent_week < $1::date)
+		RETURNING d.account_id, a.email, previous.last_sent_week, previous.last_sent_at
+	`, weekStart.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim weekly digest subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []digestRecipient
+	for rows.Next() {
+		var recipient digestRecipient
+		if err := rows.Scan(&recipient.accountID, &recipient.email, &recipient.lastSentWeek, &recipient.lastSentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly digest subscription: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+// releaseRecipient restores a claimed subscription after its email failed, so the next
+// run retries it. A subscription claimed for another week since is left alone.
+func (s *WeeklyDigestService) releaseRecipient(ctx context.Context, recipient digestRecipient, weekStart models.Date) {
+	if _, err := s.DB.ExecContext(ctx, `
+		UPDATE digest_subscriptions SET last_sent_week = $2, last_sent_at = $3
+		WHERE account_id = $1 AND last_sent_week = $4::date
+	`, recipient.accountID, recipient.lastSentWeek, recipient.lastSentAt, weekStart.String()); err != nil {
+		s.logger.WithError(err).WithField("account_id", recipient.accountID).
+			Warn("Failed to release weekly digest claim; the account misses this week's digest")
+	}
+}
@@ -0,0 +1,222 @@
+package shared
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CacheEntryOverheadBytes is added to every entry's key and value size when estimating
+// a cache layer's memory: the map slot, entry struct and expiry
+const CacheEntryOverheadBytes = 64
+
+// DefaultHottestCacheKeys is how many of the most read keys a cache layer reports
+const DefaultHottestCacheKeys = 10
+
+// CacheFamilyStats is the state of one key family of a cache layer. Misses include
+// lookups that found an expired entry, which are also counted under Expired.
+type CacheFamilyStats struct {
+	Family      string  `json:"family"`
+	Entries     int     `json:"entries"`
+	MemoryBytes int64   `json:"memory_bytes"`
+	Hits        int64   `json:"hits"`
+	Misses      int64   `json:"misses"`
+	Expired     int64   `json:"expired"`
+	Evictions   int64   `json:"evictions"`
+	HitRate     float64 `json:"hit_rate"`
+}
+
+// CacheKeyStats is a cached key and how often it was read since it was stored
+type CacheKeyStats struct {
+	Key              string `json:"key"`
+	Family           string `json:"family"`
+	Hits             int64  `json:"hits"`
+	MemoryBytes      int64  `json:"memory_bytes"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds"`
+}
+
+// CacheWarmupStatus reports the last warmup of a cache layer
+type CacheWarmupStatus struct {
+	Status     string     `json:"status"` // never, running, ok or failed
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+	Keys       int        `json:"keys"` // Entries the warmup loaded
+	Error      string     `json:"error,omitempty"`
+}
+
+// Cache warmup states
+const (
+	CacheWarmupNever   = "never"
+	CacheWarmupRunning = "running"
+	CacheWarmupOK      = "ok"
+	CacheWarmupFailed  = "failed"
+)
+
+// CacheLayerStats is the state of one cache layer with its key families and hottest
+// keys. MemoryBytes is an estimate: values are sized by their JSON encoding.
+type CacheLayerStats struct {
+	Layer       string             `json:"layer"`
+	Entries     int                `json:"entries"`
+	MaxEntries  int                `json:"max_entries"`
+	MemoryBytes int64              `json:"memory_bytes"`
+	Hits        int64              `json:"hits"`
+	Misses      int64              `json:"misses"`
+	Expired     int64              `json:"expired"`
+	Evictions   int64              `json:"evictions"`
+	HitRate     float64            `json:"hit_rate"`
+	Families    []CacheFamilyStats `json:"families"`
+	HottestKeys []CacheKeyStats    `json:"hottest_keys"`
+	Warmup      *CacheWarmupStatus `json:"warmup,omitempty"`
+	Shadow      *CacheShadowStats  `json:"shadow,omitempty"`
+}
+
+// CacheDivergence is a read whose value in a shadow cache backend differed from the
+// layer's own
+type CacheDivergence struct {
+	Key  string    `json:"key"`
+	Kind string    `json:"kind"` // mismatch, missing or extra
+	At   time.Time `json:"at"`
+}
+
+// CacheShadowStats reports how a shadow cache backend, written alongside a cache layer
+// and read back on every lookup, agrees with it. Missing counts keys the layer had and
+// the shadow did not; extra counts keys only the shadow had.
+type CacheShadowStats struct {
+	Backend           string            `json:"backend"`
+	Writes            int64             `json:"writes"`
+	WriteErrors       int64             `json:"write_errors"`
+	Dropped           int64             `json:"dropped"` // Operations dropped because the queue was full
+	Compares          int64             `json:"compares"`
+	Matches           int64             `json:"matches"`
+	Mismatches        int64             `json:"mismatches"`
+	Missing           int64             `json:"missing"`
+	Extra             int64             `json:"extra"`
+	ReadErrors        int64             `json:"read_errors"`
+	MatchRate         float64           `json:"match_rate"`
+	RecentDivergences []CacheDivergence `json:"recent_divergences"`
+}
+
+// CacheEntryInfo describes a stored entry for CacheCounters.Snapshot
+type CacheEntryInfo struct {
+	Key       string
+	Family    string
+	Bytes     int64
+	Hits      int64
+	ExpiresAt time.Time
+}
+
+// CacheCounters counts a cache layer's lookups and evictions per key family. Safe for
+// concurrent use.
+type CacheCounters struct {
+	mutex    sync.Mutex
+	families map[string]*CacheFamilyStats
+}
+
+// Hit counts a lookup that found a live entry
+func (c *CacheCounters) Hit(family string) {
+	c.count(family, func(stats *CacheFamilyStats) { stats.Hits++ })
+}
+
+// Miss counts a lookup that found no entry
+func (c *CacheCounters) Miss(family string) {
+	c.count(family, func(stats *CacheFamilyStats) { stats.Misses++ })
+}
+
+// Expired counts a lookup that found only an expired entry, as a miss
+func (c *CacheCounters) Expired(family string) {
+	c.count(family, func(stats *CacheFamilyStats) {
+		stats.Misses++
+		stats.Expired++
+	})
+}
+
+// Evict counts an entry dropped to make room
+func (c *CacheCounters) Evict(family string) {
+	c.count(family, func(stats *CacheFamilyStats) { stats.Evictions++ })
+}
+
+func (c *CacheCounters) count(family string, change func(stats *CacheFamilyStats)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.families == nil {
+		c.families = make(map[string]*CacheFamilyStats)
+	}
+	stats, ok := c.families[family]
+	if !ok {
+		stats = &CacheFamilyStats{Family: family}
+		c.families[family] = stats
+	}
+	change(stats)
+}
+
+// Snapshot combines the counters with the layer's current entries: families by name,
+// with their entries and memory, and the top most read keys
+func (c *CacheCounters) Snapshot(layer string, maxEntries int, entries []CacheEntryInfo, top int, now time.Time) CacheLayerStats {
+	stats := CacheLayerStats{
+		Layer:       layer,
+		Entries:     len(entries),
+		MaxEntries:  maxEntries,
+		Families:    []CacheFamilyStats{},
+		HottestKeys: []CacheKeyStats{},
+	}
+
+	families := make(map[string]*CacheFamilyStats)
+	c.mutex.Lock()
+	for name, counted := range c.families {
+		copied := *counted
+		families[name] = &copied
+	}
+	c.mutex.Unlock()
+
+	for _, entry := range entries {
+		family, ok := families[entry.Family]
+		if !ok {
+			family = &CacheFamilyStats{Family: entry.Family}
+			families[entry.Family] = family
+		}
+		family.Entries++
+		family.MemoryBytes += entry.Bytes
+	}
+
+	for _, family := range families {
+		if total := family.Hits + family.Misses; total > 0 {
+			family.HitRate = float64(family.Hits) / float64(total)
+		}
+		stats.MemoryBytes += family.MemoryBytes
+		stats.Hits += family.Hits
+		stats.Misses += family.Misses
+		stats.Expired += family.Expired
+		stats.Evictions += family.Evictions
+		stats.Families = append(stats.Families, *family)
+	}
+	sort.Slice(stats.Families, func(i, j int) bool { return stats.Families[i].Family < stats.Families[j].Family })
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	hottest := make([]CacheEntryInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Hits > 0 {
+			hottest = append(hottest, entry)
+		}
+	}
+	sort.Slice(hottest, func(i, j int) bool {
+		if hottest[i].Hits != hottest[j].Hits {
+			return hottest[i].Hits > hottest[j].Hits
+		}
+		return hottest[i].Key < hottest[j].Key
+	})
+	if len(hottest) > top {
+		hottest = hottest[:top]
+	}
+	for _, entry := range hottest {
+		stats.HottestKeys = append(stats.HottestKeys, CacheKeyStats{
+			Key:              entry.Key,
+			Family:           entry.Family,
+			Hits:             entry.Hits,
+			MemoryBytes:      entry.Bytes,
+			ExpiresInSeconds: int64(entry.ExpiresAt.Sub(now).Seconds()),
+		})
+	}
+	return stats
+}
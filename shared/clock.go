@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of the current time for status, TTL and scheduling decisions, so
+// tests and the status simulator can run that logic at a chosen time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock
+type SystemClock struct{}
+
+// Now returns time.Now()
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// DefaultClock is the clock used when none is injected
+var DefaultClock Clock = SystemClock{}
+
+// ClockOrDefault returns clock, or DefaultClock when clock is nil
+func ClockOrDefault(clock Clock) Clock {
+	if clock == nil {
+		return DefaultClock
+	}
+	return clock
+}
+
+// FakeClock is a Clock that only moves when told to. It is safe for concurrent use.
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// NewFakeClock returns a fake clock stopped at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now, forwards or backwards
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
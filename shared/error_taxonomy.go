@@ -0,0 +1,247 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Error categories shared by scrapers, the GMP service, allotment checks and the DB layer.
+// Network, validation and timeout reuse the categories declared in errors.go.
+const (
+	ErrorCategoryParse           ErrorCategory = "parse"
+	ErrorCategoryNotFound        ErrorCategory = "not_found"
+	ErrorCategoryRateLimited     ErrorCategory = "rate_limited"
+	ErrorCategoryUpstreamChanged ErrorCategory = "upstream_changed"
+	ErrorCategoryInternal        ErrorCategory = "internal"
+)
+
+// Sentinel errors for errors.Is checks against a category
+var (
+	ErrNetwork         = errors.New("network error")
+	ErrParse           = errors.New("parse error")
+	ErrValidation      = errors.New("validation error")
+	ErrNotFound        = errors.New("not found")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrUpstreamChanged = errors.New("upstream page structure changed")
+	ErrTimeout         = errors.New("timeout")
+)
+
+// categorySentinels maps categories to their sentinel errors
+var categorySentinels = map[ErrorCategory]error{
+	ErrorCategoryNetwork:         ErrNetwork,
+	ErrorCategoryParse:           ErrParse,
+	ErrorCategoryValidation:      ErrValidation,
+	ErrorCategoryNotFound:        ErrNotFound,
+	ErrorCategoryRateLimited:     ErrRateLimited,
+	ErrorCategoryUpstreamChanged: ErrUpstreamChanged,
+	ErrorCategoryTimeout:         ErrTimeout,
+}
+
+// CategorizedError attaches a category to an error without changing its message
+type CategorizedError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+// Error implements the error interface
+func (e *CategorizedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *CategorizedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel for this error's category
+func (e *CategorizedError) Is(target error) bool {
+	sentinel, ok := categorySentinels[e.Category]
+	return ok && target == sentinel
+}
+
+// Is reports whether target is the sentinel for this error's category
+func (e *ServiceError) Is(target error) bool {
+	sentinel, ok := categorySentinels[e.Category]
+	return ok && target == sentinel
+}
+
+// Is maps HTTP statuses onto categories so errors.Is(err, ErrRateLimited) matches a 429
+func (e *HTTPStatusError) Is(target error) bool {
+	sentinel, ok := categorySentinels[httpStatusCategory(e.StatusCode)]
+	return ok && target == sentinel
+}
+
+// CategorizeError wraps err with a category; nil stays nil
+func CategorizeError(category ErrorCategory, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CategorizedError{Category: category, Err: err}
+}
+
+// NetworkErrorf formats an error in the network category; %w is supported
+func NetworkErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryNetwork, fmt.Errorf(format, args...))
+}
+
+// ParseErrorf formats an error in the parse category; %w is supported
+func ParseErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryParse, fmt.Errorf(format, args...))
+}
+
+// ValidationErrorf formats an error in the validation category; %w is supported
+func ValidationErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryValidation, fmt.Errorf(format, args...))
+}
+
+// NotFoundErrorf formats an error in the not_found category; %w is supported
+func NotFoundErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryNotFound, fmt.Errorf(format, args...))
+}
+
+// RateLimitedErrorf formats an error in the rate_limited category; %w is supported
+func RateLimitedErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryRateLimited, fmt.Errorf(format, args...))
+}
+
+// UpstreamChangedErrorf formats an error in the upstream_changed category; %w is supported
+func UpstreamChangedErrorf(format string, args ...interface{}) error {
+	return CategorizeError(ErrorCategoryUpstreamChanged, fmt.Errorf(format, args...))
+}
+
+// ErrorCategoryOf returns the category of the outermost categorized error in err's
+// chain, inferring not_found, network and timeout categories for standard library errors
+func ErrorCategoryOf(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+
+	var categorized *CategorizedError
+	if errors.As(err, &categorized) {
+		return categorized.Category
+	}
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Category
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return httpStatusCategory(statusErr.StatusCode)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrorCategoryNotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCategoryTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorCategoryTimeout
+		}
+		return ErrorCategoryNetwork
+	}
+
+	return ErrorCategoryInternal
+}
+
+// ErrorMetricLabel returns the metrics label for err
+func ErrorMetricLabel(err error) string {
+	return string(ErrorCategoryOf(err))
+}
+
+// HTTPStatusForError maps an error's category to the status code returned to API clients
+func HTTPStatusForError(err error) int {
+	return HTTPStatusForCategory(ErrorCategoryOf(err))
+}
+
+// HTTPStatusForCategory maps an error category to an HTTP status code. Upstream
+// failures (network, parse, page changes) are reported as 502 Bad Gateway.
+func HTTPStatusForCategory(category ErrorCategory) int {
+	switch category {
+	case ErrorCategoryValidation:
+		return http.StatusBadRequest
+	case ErrorCategoryNotFound:
+		return http.StatusNotFound
+	case ErrorCategoryRateLimited:
+		return http.StatusTooManyRequests
+	case ErrorCategoryNetwork, ErrorCategoryParse, ErrorCategoryUpstreamChanged:
+		return http.StatusBadGateway
+	case ErrorCategoryTimeout:
+		return http.StatusGatewayTimeout
+	case ErrorCategoryResource:
+		return http.StatusServiceUnavailable
+	case ErrorCategoryAuthentication:
+		return http.StatusUnauthorized
+	case ErrorCategoryAuthorization:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// httpStatusCategory categorizes an unexpected upstream HTTP status
+func httpStatusCategory(statusCode int) ErrorCategory {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCategoryRateLimited
+	case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+		return ErrorCategoryNotFound
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return ErrorCategoryTimeout
+	default:
+		return ErrorCategoryNetwork
+	}
+}
+
+// ErrorCounter counts errors by category and component for the metrics endpoint
+type ErrorCounter struct {
+	mutex  sync.Mutex
+	counts map[string]map[string]int64
+}
+
+// NewErrorCounter creates an empty error counter
+func NewErrorCounter() *ErrorCounter {
+	return &ErrorCounter{counts: make(map[string]map[string]int64)}
+}
+
+// DefaultErrorCounter collects categorized errors across the application
+var DefaultErrorCounter = NewErrorCounter()
+
+// Record counts err under component and its category label
+func (c *ErrorCounter) Record(component string, err error) {
+	if err == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	byCategory, exists := c.counts[component]
+	if !exists {
+		byCategory = make(map[string]int64)
+		c.counts[component] = byCategory
+	}
+	byCategory[ErrorMetricLabel(err)]++
+}
+
+// Snapshot returns a copy of the counts keyed by component, then category
+func (c *ErrorCounter) Snapshot() map[string]map[string]int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	snapshot := make(map[string]map[string]int64, len(c.counts))
+	for component, counts := range c.counts {
+		byCategory := make(map[string]int64, len(counts))
+		for category, count := range counts {
+			byCategory[category] = count
+		}
+		snapshot[component] = byCategory
+	}
+	return snapshot
+}
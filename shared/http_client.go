@@ -78,70 +78,113 @@ func (f *HTTPClientFactory) CreateOptimizedHTTPClient(timeout time.Duration) *ht
 
 // SetBrowserLikeHeaders configures HTTP request headers to mimic browser behavior
 func SetBrowserLikeHeaders(request *http.Request, acceptHeader string) {
-	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	request.Header.Set("User-Agent", BrowserUserAgents[0])
 	request.Header.Set("Accept", acceptHeader)
 	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	request.Header.Set("Cache-Control", "no-cache")
 	request.Header.Set("Connection", "keep-alive")
 }
 
-// ExecuteHTTPRequestWithRetry executes HTTP requests with exponential backoff retry logic
+// ExecuteHTTPRequestWithRetry executes HTTP requests with the default HTTP retry policy,
+// allowing maxRetryAttempts retries after the first attempt
 func ExecuteHTTPRequestWithRetry(client *http.Client, request *http.Request, maxRetryAttempts int) (*http.Response, error) {
+	return ExecuteHTTPRequestWithPolicy(client, request, DefaultHTTPRetryPolicy().WithMaxRetries(maxRetryAttempts))
+}
+
+// ExecuteHTTPRequestWithPolicy executes HTTP requests, retrying according to policy.
+// Any non-200 response is reported as an *HTTPStatusError for the policy's classifier.
+// A request retried after a throttling status moves on to the next browser User-Agent.
+// Each attempt is recorded in DefaultHTTPHostMetrics, with retries counted by
+// HTTPRetryClass, and waits for a slot in the target host's concurrency budget, if one
+// is configured. Failed attempts are kept in DefaultHTTPRecorder while recording is on.
+func ExecuteHTTPRequestWithPolicy(client *http.Client, request *http.Request, policy RetryPolicy) (*http.Response, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"component": "HTTPClientFactory",
-		"method":    "ExecuteHTTPRequestWithRetry",
+		"method":    "ExecuteHTTPRequestWithPolicy",
 		"url":       request.URL.String(),
 	})
 
 	var httpResponse *http.Response
-	var lastExecutionError error
-
-	for attemptNumber := 0; attemptNumber <= maxRetryAttempts; attemptNumber++ {
-		if attemptNumber > 0 {
-			// Calculate exponential backoff duration with jitter to prevent thundering herd
-			baseBackoffDuration := time.Duration(1<<uint(attemptNumber-1)) * time.Second
-			jitterDuration := time.Duration(float64(baseBackoffDuration) * 0.1 * (0.5 + 0.5*float64(attemptNumber%3)/2))
-			totalBackoffDuration := baseBackoffDuration + jitterDuration
-
+	host := requestHost(request)
+	attempts := 0
+	var lastClass string
+
+	lastExecutionError := policy.Do(request.Context(), func(attemptNumber int) error {
+		attempts = attemptNumber
+		if attemptNumber > 1 {
+			DefaultHTTPHostMetrics.RecordRetry(host, lastClass)
+			if lastClass == HTTPRetryThrottled {
+				rotateUserAgent(request)
+			}
 			logger.WithFields(logrus.Fields{
-				"attempt":          attemptNumber + 1,
-				"backoff_duration": totalBackoffDuration,
+				"attempt":     attemptNumber,
+				"retry_class": lastClass,
 			}).Debug("Retrying HTTP request after backoff")
+		}
 
-			time.Sleep(totalBackoffDuration)
+		// The budget slot is held until the response body is closed, so it covers
+		// bodies still streaming, not just the time to headers
+		release, err := DefaultHTTPHostMetrics.Acquire(request.Context(), host)
+		if err != nil {
+			// The policy checks the context before another attempt, so this is the last
+			return fmt.Errorf("attempt %d gave up waiting for the %s request budget: %w", attemptNumber, host, err)
+		}
+		attemptStart := time.Now()
+		response, err := client.Do(request)
+		latency := time.Since(attemptStart)
+		statusCode := 0
+		if response != nil {
+			statusCode = response.StatusCode
+			response.Body = &releasingBody{ReadCloser: response.Body, release: release}
+		} else {
+			release()
 		}
+		DefaultHTTPHostMetrics.RecordRequest(host, statusCode, latency, err)
 
-		httpResponse, lastExecutionError = client.Do(request)
-		if lastExecutionError == nil && httpResponse.StatusCode == http.StatusOK {
-			logger.WithFields(logrus.Fields{
-				"attempt":     attemptNumber + 1,
-				"status_code": httpResponse.StatusCode,
-			}).Debug("HTTP request successful")
-			return httpResponse, nil // Successful execution
+		if err != nil {
+			logger.WithError(err).WithField("attempt", attemptNumber).Debug("HTTP request failed with network error")
+			DefaultHTTPRecorder.RecordResponse("http_client", request, attemptNumber, nil, latency, err)
+			lastClass = HTTPRetryTransient
+			return NetworkErrorf("attempt %d failed with network error: %w", attemptNumber, err)
 		}
 
-		// Store detailed error information for potential return
-		if lastExecutionError != nil {
-			lastExecutionError = fmt.Errorf("attempt %d failed with network error: %w", attemptNumber+1, lastExecutionError)
-			logger.WithError(lastExecutionError).Debug("HTTP request failed with network error")
-		} else {
-			lastExecutionError = fmt.Errorf("attempt %d failed with HTTP %d: %s", attemptNumber+1, httpResponse.StatusCode, http.StatusText(httpResponse.StatusCode))
+		if response.StatusCode != http.StatusOK {
 			logger.WithFields(logrus.Fields{
-				"attempt":     attemptNumber + 1,
-				"status_code": httpResponse.StatusCode,
+				"attempt":     attemptNumber,
+				"status_code": response.StatusCode,
 			}).Debug("HTTP request failed with non-200 status")
-			httpResponse.Body.Close() // Clean up response body before retrying
+			DefaultHTTPRecorder.RecordResponse("http_client", request, attemptNumber, response, latency, nil)
+			response.Body.Close() // Clean up response body before retrying
+			lastClass = httpRetryClassForStatus(response.StatusCode)
+			return fmt.Errorf("attempt %d failed: %w", attemptNumber, &HTTPStatusError{StatusCode: response.StatusCode})
 		}
+
+		logger.WithFields(logrus.Fields{
+			"attempt":     attemptNumber,
+			"status_code": response.StatusCode,
+		}).Debug("HTTP request successful")
+		httpResponse = response
+		return nil
+	})
+
+	if lastExecutionError == nil {
+		return httpResponse, nil
 	}
 
-	// All retry attempts exhausted
-	totalAttempts := maxRetryAttempts + 1
-	logger.WithFields(logrus.Fields{
-		"total_attempts": totalAttempts,
-		"final_error":    lastExecutionError,
-	}).Error("HTTP request failed after all retry attempts")
+	if lastClass == HTTPRetryGone {
+		logger.WithFields(logrus.Fields{
+			"total_attempts": attempts,
+			"final_error":    lastExecutionError,
+		}).Warn("HTTP request found no page; not retried")
+	} else {
+		logger.WithFields(logrus.Fields{
+			"total_attempts": attempts,
+			"final_error":    lastExecutionError,
+			"retry_class":    lastClass,
+		}).Error("HTTP request failed after all retry attempts")
+	}
 
-	return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", totalAttempts, lastExecutionError)
+	return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", attempts, lastExecutionError)
 }
 
 // CleanupHTTPClient properly closes and cleans up HTTP client resources
@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DiskCacheHeader is set to HIT on responses replayed from the on-disk HTTP cache
+const DiskCacheHeader = "X-Disk-Cache"
+
+// DiskCacheTransport is a development HTTP cache: successful GET responses are written
+// to Dir and replayed while younger than MaxAge, so repeated scraper runs do not refetch
+// pages from the source site. Cache-Control headers are deliberately ignored.
+type DiskCacheTransport struct {
+	Base   http.RoundTripper
+	Dir    string
+	MaxAge time.Duration
+}
+
+// NewDiskCacheTransport wraps base with an on-disk cache in dir
+func NewDiskCacheTransport(base http.RoundTripper, dir string, maxAge time.Duration) *DiskCacheTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &DiskCacheTransport{Base: base, Dir: dir, MaxAge: maxAge}
+}
+
+// NewDiskCachedClient returns a copy of client whose requests go through a disk cache;
+// the original client, which may be shared, is left unchanged
+func NewDiskCachedClient(client *http.Client, dir string, maxAge time.Duration) *http.Client {
+	cached := *client
+	cached.Transport = NewDiskCacheTransport(client.Transport, dir, maxAge)
+	return &cached
+}
+
+// RoundTrip serves cacheable requests from disk when a fresh entry exists
+func (t *DiskCacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != http.MethodGet || request.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(request)
+	}
+
+	path := t.entryPath(request)
+	if response, ok := t.load(path, request); ok {
+		return response, nil
+	}
+
+	response, err := t.Base.RoundTrip(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for disk cache: %w", err)
+	}
+	if err := t.store(path, response, body); err != nil {
+		logrus.WithError(err).WithField("url", request.URL.String()).Warn("Failed to write HTTP disk cache entry")
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return response, nil
+}
+
+// CloseIdleConnections closes idle connections of the wrapped transport
+func (t *DiskCacheTransport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// entryPath names a request's cache file by a hash of its URL and Accept header, since
+// the same URL can serve HTML or JSON
+func (t *DiskCacheTransport) entryPath(request *http.Request) string {
+	sum := sha256.Sum256([]byte(request.URL.String() + "\n" + request.Header.Get("Accept")))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:])+".http")
+}
+
+// load replays a fresh entry, reporting false when there is none
+func (t *DiskCacheTransport) load(path string, request *http.Request) (*http.Response, bool) {
+	info, err := os.Stat(path)
+	if err != nil || (t.MaxAge > 0 && time.Since(info.ModTime()) > t.MaxAge) {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), request)
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Warn("Ignoring unreadable HTTP disk cache entry")
+		return nil, false
+	}
+	response.Header.Set(DiskCacheHeader, "HIT")
+	return response, true
+}
+
+// store writes the response atomically so concurrent readers never see a partial entry
+func (t *DiskCacheTransport) store(path string, response *http.Response, body []byte) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+	// The body was read in full, so the stored entry has a plain, known length
+	stored := *response
+	stored.Header = response.Header.Clone()
+	stored.TransferEncoding = nil
+	stored.ContentLength = int64(len(body))
+	stored.Body = io.NopCloser(bytes.NewReader(body))
+	dump, err := httputil.DumpResponse(&stored, true)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(t.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(dump); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,289 @@
+package shared
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPLatencyBuckets are the upper bounds of the per-host latency histogram
+var HTTPLatencyBuckets = []time.Duration{
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// hostHTTPStats holds counters for a single upstream host
+type hostHTTPStats struct {
+	requests     int64
+	successes    int64
+	failures     int64
+	retries      int64
+	inFlight     int64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	statusCodes  map[int]int64
+	errors       map[string]int64
+	buckets      []int64 // len(HTTPLatencyBuckets)+1, last bucket is +Inf
+	budget       chan struct{}
+	budgetWaits  int64
+
+	// Failed attempts and retries by HTTPRetryClass
+	failureClasses map[string]int64
+	retryClasses   map[string]int64
+}
+
+// HostHTTPMetricsSnapshot is a point-in-time copy of one host's HTTP metrics
+type HostHTTPMetricsSnapshot struct {
+	Host             string           `json:"host"`
+	Requests         int64            `json:"requests"`
+	Successes        int64            `json:"successes"`
+	Failures         int64            `json:"failures"`
+	Retries          int64            `json:"retries"`
+	FailuresByClass  map[string]int64 `json:"failures_by_class"`
+	RetriesByClass   map[string]int64 `json:"retries_by_class"`
+	InFlight         int64            `json:"in_flight"`
+	AverageLatencyMs float64          `json:"average_latency_ms"`
+	MaxLatencyMs     float64          `json:"max_latency_ms"`
+	LatencyHistogram map[string]int64 `json:"latency_histogram"`
+	StatusCodes      map[string]int64 `json:"status_codes"`
+	Errors           map[string]int64 `json:"errors,omitempty"`
+	Budget           int              `json:"budget,omitempty"`
+	BudgetWaits      int64            `json:"budget_waits"`
+}
+
+// HTTPHostMetrics tracks requests per upstream host and enforces optional
+// per-host concurrent request budgets
+type HTTPHostMetrics struct {
+	mutex sync.Mutex
+	hosts map[string]*hostHTTPStats
+}
+
+// NewHTTPHostMetrics creates an empty per-host metrics registry
+func NewHTTPHostMetrics() *HTTPHostMetrics {
+	return &HTTPHostMetrics{hosts: make(map[string]*hostHTTPStats)}
+}
+
+// DefaultHTTPHostMetrics is the registry used by ExecuteHTTPRequestWithRetry
+var DefaultHTTPHostMetrics = NewHTTPHostMetrics()
+
+// getHost returns the stats for host, creating them if needed; caller holds the mutex
+func (m *HTTPHostMetrics) getHost(host string) *hostHTTPStats {
+	stats, exists := m.hosts[host]
+	if !exists {
+		stats = &hostHTTPStats{
+			statusCodes:    make(map[int]int64),
+			errors:         make(map[string]int64),
+			failureClasses: make(map[string]int64),
+			retryClasses:   make(map[string]int64),
+			buckets:        make([]int64, len(HTTPLatencyBuckets)+1),
+		}
+		m.hosts[host] = stats
+	}
+	return stats
+}
+
+// SetHostBudget limits concurrent requests to host; zero or less removes the limit.
+// Changing a budget affects requests that start after the call.
+func (m *HTTPHostMetrics) SetHostBudget(host string, maxConcurrent int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := m.getHost(strings.ToLower(host))
+	if maxConcurrent <= 0 {
+		stats.budget = nil
+		return
+	}
+	stats.budget = make(chan struct{}, maxConcurrent)
+}
+
+// Acquire waits for a slot in the host's budget and marks a request in flight. It gives
+// up with ctx's error when ctx ends first. The returned function must be called once
+// when the request completes; calling it again does nothing.
+func (m *HTTPHostMetrics) Acquire(ctx context.Context, host string) (func(), error) {
+	host = strings.ToLower(host)
+
+	m.mutex.Lock()
+	stats := m.getHost(host)
+	budget := stats.budget
+	if budget != nil && len(budget) == cap(budget) {
+		stats.budgetWaits++
+	}
+	m.mutex.Unlock()
+
+	if budget != nil {
+		select {
+		case budget <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	m.mutex.Lock()
+	stats.inFlight++
+	m.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mutex.Lock()
+			stats.inFlight--
+			m.mutex.Unlock()
+			if budget != nil {
+				<-budget
+			}
+		})
+	}, nil
+}
+
+// releasingBody calls release when the response body is closed, so a host's budget
+// covers a request until its body has been read
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// RecordRequest records the outcome of a single HTTP attempt
+func (m *HTTPHostMetrics) RecordRequest(host string, statusCode int, latency time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := m.getHost(strings.ToLower(host))
+	stats.requests++
+	stats.totalLatency += latency
+	if latency > stats.maxLatency {
+		stats.maxLatency = latency
+	}
+
+	bucket := len(HTTPLatencyBuckets)
+	for i, bound := range HTTPLatencyBuckets {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	stats.buckets[bucket]++
+
+	if err != nil {
+		stats.failures++
+		stats.errors[classifyHTTPError(err)]++
+		stats.failureClasses[HTTPRetryTransient]++
+		return
+	}
+
+	stats.statusCodes[statusCode]++
+	if statusCode >= 200 && statusCode < 300 {
+		stats.successes++
+	} else {
+		stats.failures++
+		stats.failureClasses[httpRetryClassForStatus(statusCode)]++
+	}
+}
+
+// RecordRetry records that a request to host is being retried after a failure of the
+// given HTTPRetryClass
+func (m *HTTPHostMetrics) RecordRetry(host, class string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stats := m.getHost(strings.ToLower(host))
+	stats.retries++
+	stats.retryClasses[class]++
+}
+
+// Snapshot returns a copy of the metrics for every host seen so far
+func (m *HTTPHostMetrics) Snapshot() []HostHTTPMetricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshots := make([]HostHTTPMetricsSnapshot, 0, len(m.hosts))
+	for host, stats := range m.hosts {
+		snapshot := HostHTTPMetricsSnapshot{
+			Host:             host,
+			Requests:         stats.requests,
+			Successes:        stats.successes,
+			Failures:         stats.failures,
+			Retries:          stats.retries,
+			InFlight:         stats.inFlight,
+			MaxLatencyMs:     float64(stats.maxLatency.Microseconds()) / 1000,
+			LatencyHistogram: make(map[string]int64, len(stats.buckets)),
+			StatusCodes:      make(map[string]int64, len(stats.statusCodes)),
+			Errors:           make(map[string]int64, len(stats.errors)),
+			FailuresByClass:  make(map[string]int64, len(stats.failureClasses)),
+			RetriesByClass:   make(map[string]int64, len(stats.retryClasses)),
+			BudgetWaits:      stats.budgetWaits,
+		}
+		if stats.requests > 0 {
+			snapshot.AverageLatencyMs = float64(stats.totalLatency.Microseconds()) / 1000 / float64(stats.requests)
+		}
+		if stats.budget != nil {
+			snapshot.Budget = cap(stats.budget)
+		}
+		for i, count := range stats.buckets {
+			label := "+Inf"
+			if i < len(HTTPLatencyBuckets) {
+				label = "le_" + HTTPLatencyBuckets[i].String()
+			}
+			snapshot.LatencyHistogram[label] = count
+		}
+		for code, count := range stats.statusCodes {
+			snapshot.StatusCodes[strconv.Itoa(code)] = count
+		}
+		for errorType, count := range stats.errors {
+			snapshot.Errors[errorType] = count
+		}
+		for class, count := range stats.failureClasses {
+			snapshot.FailuresByClass[class] = count
+		}
+		for class, count := range stats.retryClasses {
+			snapshot.RetriesByClass[class] = count
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// requestHost returns the host used as the metrics key for a request
+func requestHost(request *http.Request) string {
+	if request.URL == nil {
+		return "unknown"
+	}
+	return strings.ToLower(request.URL.Hostname())
+}
+
+// classifyHTTPError maps transport errors to a small set of metric labels
+func classifyHTTPError(err error) string {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return "timeout"
+	}
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "timeout") || strings.Contains(message, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(message, "connection refused"):
+		return "connection_refused"
+	case strings.Contains(message, "connection reset"):
+		return "connection_reset"
+	case strings.Contains(message, "no such host"):
+		return "dns"
+	case strings.Contains(message, "tls") || strings.Contains(message, "certificate"):
+		return "tls"
+	default:
+		return "network"
+	}
+}
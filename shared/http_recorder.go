@@ -0,0 +1,206 @@
+package shared
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxRecordedBodyBytes is how much of each request and response body a recording keeps
+const MaxRecordedBodyBytes = 16 * 1024
+
+// recordedHeaderRedactions are headers replaced in recordings, since they carry
+// credentials or session cookies
+var recordedHeaderRedactions = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// HTTPRecording is one failed outbound request with what came back
+type HTTPRecording struct {
+	ID                int64       `json:"id"`
+	RecordedAt        time.Time   `json:"recorded_at"`
+	Source            string      `json:"source"`
+	Method            string      `json:"method"`
+	URL               string      `json:"url"`
+	Attempt           int         `json:"attempt,omitempty"`
+	RequestHeaders    http.Header `json:"request_headers,omitempty"`
+	RequestBody       string      `json:"request_body,omitempty"`
+	StatusCode        int         `json:"status_code,omitempty"`
+	ResponseHeaders   http.Header `json:"response_headers,omitempty"`
+	ResponseBody      string      `json:"response_body,omitempty"`
+	ResponseTruncated bool        `json:"response_truncated,omitempty"`
+	DurationMs        int64       `json:"duration_ms"`
+	Error             string      `json:"error,omitempty"`
+}
+
+// HTTPRecorder keeps the most recent failed outbound requests in a fixed-size ring
+// buffer, so upstream HTML or JSON changes can be diagnosed from the admin API.
+// Credentials, PANs, application numbers and demat IDs are redacted before a recording
+// is stored. Recording is off until Configure enables it.
+type HTTPRecorder struct {
+	mutex      sync.Mutex
+	enabled    bool
+	recordings []HTTPRecording
+	next       int
+	size       int
+	lastID     int64
+}
+
+// NewHTTPRecorder creates a disabled recorder
+func NewHTTPRecorder() *HTTPRecorder {
+	return &HTTPRecorder{}
+}
+
+// DefaultHTTPRecorder records failures of ExecuteHTTPRequestWithPolicy and registrar calls
+var DefaultHTTPRecorder = NewHTTPRecorder()
+
+// Configure turns recording on with room for capacity recordings, or off when capacity
+// is 0. Existing recordings are dropped.
+func (r *HTTPRecorder) Configure(capacity int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if capacity < 0 {
+		capacity = 0
+	}
+	r.enabled = capacity > 0
+	r.recordings = make([]HTTPRecording, capacity)
+	r.next, r.size = 0, 0
+}
+
+// Enabled reports whether failures are being recorded
+func (r *HTTPRecorder) Enabled() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.enabled
+}
+
+// Capacity returns how many recordings the buffer holds
+func (r *HTTPRecorder) Capacity() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.recordings)
+}
+
+// Record stores recording after redacting it, evicting the oldest recording when the
+// buffer is full. It does nothing while recording is off.
+func (r *HTTPRecorder) Record(recording HTTPRecording) {
+	if !r.Enabled() {
+		return
+	}
+
+	recording.URL = redactRecordedText(recording.URL)
+	recording.RequestHeaders = redactRecordedHeaders(recording.RequestHeaders)
+	recording.ResponseHeaders = redactRecordedHeaders(recording.ResponseHeaders)
+	recording.RequestBody = redactRecordedText(truncateRecordedBody(recording.RequestBody))
+	if len(recording.ResponseBody) > MaxRecordedBodyBytes {
+		recording.ResponseTruncated = true
+	}
+	recording.ResponseBody = redactRecordedText(truncateRecordedBody(recording.ResponseBody))
+	recording.Error = redactRecordedText(recording.Error)
+	if recording.RecordedAt.IsZero() {
+		recording.RecordedAt = time.Now()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// Recording may have been turned off while the recording was redacted
+	if !r.enabled {
+		return
+	}
+	r.lastID++
+	recording.ID = r.lastID
+	r.recordings[r.next] = recording
+	r.next = (r.next + 1) % len(r.recordings)
+	if r.size < len(r.recordings) {
+		r.size++
+	}
+}
+
+// RecordResponse records a failed attempt of request. A non-nil response body is read
+// up to the recording limit and closed; err is set for network failures.
+func (r *HTTPRecorder) RecordResponse(source string, request *http.Request, attempt int, response *http.Response, duration time.Duration, err error) {
+	if !r.Enabled() {
+		return
+	}
+
+	recording := HTTPRecording{
+		Source:         source,
+		Method:         request.Method,
+		URL:            request.URL.String(),
+		Attempt:        attempt,
+		RequestHeaders: request.Header,
+		DurationMs:     duration.Milliseconds(),
+	}
+	if request.GetBody != nil {
+		if body, bodyErr := request.GetBody(); bodyErr == nil {
+			recording.RequestBody = readRecordedBody(body)
+		}
+	}
+	if response != nil {
+		recording.StatusCode = response.StatusCode
+		recording.ResponseHeaders = response.Header
+		recording.ResponseBody = readRecordedBody(response.Body)
+	}
+	if err != nil {
+		recording.Error = err.Error()
+	}
+	r.Record(recording)
+}
+
+// Recordings returns the buffered recordings, newest first
+func (r *HTTPRecorder) Recordings() []HTTPRecording {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]HTTPRecording, 0, r.size)
+	for i := 1; i <= r.size; i++ {
+		index := (r.next - i + len(r.recordings)) % len(r.recordings)
+		result = append(result, r.recordings[index])
+	}
+	return result
+}
+
+// Clear drops every recording, keeping recording on or off as it was
+func (r *HTTPRecorder) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.recordings = make([]HTTPRecording, len(r.recordings))
+	r.next, r.size = 0, 0
+}
+
+// readRecordedBody reads one byte past the recording limit, so Record can tell the
+// body was truncated, and closes body
+func readRecordedBody(body io.ReadCloser) string {
+	if body == nil {
+		return ""
+	}
+	defer body.Close()
+	var buffer bytes.Buffer
+	_, _ = io.Copy(&buffer, io.LimitReader(body, MaxRecordedBodyBytes+1))
+	return buffer.String()
+}
+
+func truncateRecordedBody(body string) string {
+	if len(body) > MaxRecordedBodyBytes {
+		return body[:MaxRecordedBodyBytes]
+	}
+	return body
+}
+
+func redactRecordedText(text string) string {
+	return ScrubPII(text)
+}
+
+// redactRecordedHeaders returns a copy of headers with credential headers replaced
+func redactRecordedHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	redacted := headers.Clone()
+	for _, name := range recordedHeaderRedactions {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "[redacted]")
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTP retry classes: how ExecuteHTTPRequestWithPolicy retries a failed attempt
+const (
+	// HTTPRetryGone is a 404 or 410: the page is not there, so it is not retried
+	HTTPRetryGone = "gone"
+	// HTTPRetryThrottled is a 403 or 429: retried after a longer backoff, with the next
+	// browser User-Agent
+	HTTPRetryThrottled = "throttled"
+	// HTTPRetryTransient is a 5xx or a network error: retried with the standard backoff
+	HTTPRetryTransient = "transient"
+	// HTTPRetryOther is any other unexpected status: retried with the standard backoff
+	HTTPRetryOther = "other"
+)
+
+// DefaultHTTPThrottleFactor is how many times the standard delay a throttled request
+// waits before its retry
+const DefaultHTTPThrottleFactor = 4.0
+
+// BrowserUserAgents are the desktop browser User-Agents scraper requests present. A
+// throttled request is retried with the one after its current User-Agent.
+var BrowserUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+}
+
+// HTTPRetryClass returns the retry class of a failed HTTP attempt. Errors without an
+// *HTTPStatusError in their chain are network errors.
+func HTTPRetryClass(err error) string {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return HTTPRetryTransient
+	}
+	return httpRetryClassForStatus(statusErr.StatusCode)
+}
+
+// httpRetryClassForStatus returns the retry class of an unexpected response status
+func httpRetryClassForStatus(statusCode int) string {
+	switch {
+	case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+		return HTTPRetryGone
+	case statusCode == http.StatusForbidden || statusCode == http.StatusTooManyRequests:
+		return HTTPRetryThrottled
+	case statusCode >= 500:
+		return HTTPRetryTransient
+	default:
+		return HTTPRetryOther
+	}
+}
+
+// IsRetryableHTTPError retries every failed HTTP attempt except missing pages
+func IsRetryableHTTPError(err error) bool {
+	return err != nil && HTTPRetryClass(err) != HTTPRetryGone
+}
+
+// IsThrottledHTTPError reports whether the upstream refused or rate limited the attempt
+func IsThrottledHTTPError(err error) bool {
+	return err != nil && HTTPRetryClass(err) == HTTPRetryThrottled
+}
+
+// rotateUserAgent moves a request presenting one of BrowserUserAgents on to the next.
+// Requests with another User-Agent, such as API clients, keep theirs.
+func rotateUserAgent(request *http.Request) {
+	current := request.Header.Get("User-Agent")
+	for i, userAgent := range BrowserUserAgents {
+		if userAgent == current {
+			request.Header.Set("User-Agent", BrowserUserAgents[(i+1)%len(BrowserUserAgents)])
+			return
+		}
+	}
+}
@@ -493,11 +493,7 @@ func (m *ExtractionMetrics) GetDescriptionSuccessRate() float64 {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	if m.DescriptionAttempts == 0 {
-		return 0.0
-	}
-
-	return float64(m.DescriptionSuccess) / float64(m.DescriptionAttempts) * 100.0
+	return m.descriptionSuccessRate()
 }
 
 // GetAboutSuccessRate returns the about extraction success rate
@@ -505,6 +501,20 @@ func (m *ExtractionMetrics) GetAboutSuccessRate() float64 {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
+	return m.aboutSuccessRate()
+}
+
+// descriptionSuccessRate computes the description success rate; callers hold the lock
+func (m *ExtractionMetrics) descriptionSuccessRate() float64 {
+	if m.DescriptionAttempts == 0 {
+		return 0.0
+	}
+
+	return float64(m.DescriptionSuccess) / float64(m.DescriptionAttempts) * 100.0
+}
+
+// aboutSuccessRate computes the about success rate; callers hold the lock
+func (m *ExtractionMetrics) aboutSuccessRate() float64 {
 	if m.AboutAttempts == 0 {
 		return 0.0
 	}
@@ -517,8 +527,10 @@ func (m *ExtractionMetrics) LogSummary() {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	descriptionSuccessRate := m.GetDescriptionSuccessRate()
-	aboutSuccessRate := m.GetAboutSuccessRate()
+	// The rates are computed under the lock already held; taking it again would
+	// deadlock behind a waiting writer
+	descriptionSuccessRate := m.descriptionSuccessRate()
+	aboutSuccessRate := m.aboutSuccessRate()
 
 	logrus.WithFields(logrus.Fields{
 		"description_attempts":     m.DescriptionAttempts,
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// panPattern matches the Indian PAN format: 5 letters, 4 digits, 1 letter
+var panPattern = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+
+// panHashPattern matches a HashPAN result: 64 lowercase hex digits
+var panHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// NormalizePAN trims and upper-cases a PAN
+func NormalizePAN(pan string) string {
+	return strings.ToUpper(strings.TrimSpace(pan))
+}
+
+// IsValidPAN reports whether pan is a well-formed PAN after normalization
+func IsValidPAN(pan string) bool {
+	return panPattern.MatchString(NormalizePAN(pan))
+}
+
+// HashPAN returns the hex SHA-256 of the normalized PAN, used as the cache key
+// so raw PANs are never persisted
+func HashPAN(pan string) string {
+	sum := sha256.Sum256([]byte(NormalizePAN(pan)))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsValidPANHash reports whether hash has the format HashPAN returns
+func IsValidPANHash(hash string) bool {
+	return panHashPattern.MatchString(hash)
+}
+
+// ClientFingerprint returns a hex HMAC-SHA256 of the client's IP and user agent under
+// key, letting admins group checks by client without storing the raw IP. The key keeps
+// the fingerprint from being reversed by hashing every IPv4 address.
+func ClientFingerprint(key []byte, ip, userAgent string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.TrimSpace(ip) + "|" + strings.TrimSpace(userAgent)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PANReference returns a short reference to a PAN hash for admin views: the first 12
+// hex digits of its HMAC-SHA256 under key. A plain prefix of the hash would identify the
+// PAN, since every PAN can be hashed; without the key the reference cannot be matched.
+func PANReference(key []byte, panHash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(panHash))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// MaskPAN hides the middle of a PAN for display, keeping the first two and last
+// characters (e.g. "AB*******F")
+func MaskPAN(pan string) string {
+	pan = NormalizePAN(pan)
+	if len(pan) < 4 {
+		return strings.Repeat("*", len(pan))
+	}
+	return pan[:2] + strings.Repeat("*", len(pan)-3) + pan[len(pan)-1:]
+}
@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// piiPANPattern finds PANs anywhere in free text
+var piiPANPattern = regexp.MustCompile(`(?i)\b[A-Z]{5}[0-9]{4}[A-Z]\b`)
+
+// piiDematPattern finds demat account numbers: NSDL DP IDs ("IN" and 6 digits), with or
+// without the 8-digit client ID, and 16-digit CDSL BO IDs
+var piiDematPattern = regexp.MustCompile(`(?i)\bIN[0-9]{6}(?:[-/ ]?[0-9]{8})?\b|\b[0-9]{16}\b`)
+
+// piiLabelledPattern finds identifiers after a label naming them, such as
+// "Application No: 1234567890", `"dpid":"12081600"` or "Client ID</td><td>10457890".
+// Group 1 is the label with its separator and group 2 the identifier.
+var piiLabelledPattern = regexp.MustCompile(`(?i)\b((?:app(?:lication|l)?|dp|client|bo|demat)[ ._-]*(?:no|num|number|id|account)\b\.?(?:\s|["':=#]|&nbsp;|<[^<>]{0,40}>){0,12})([A-Z0-9]*[0-9][A-Z0-9]*)`)
+
+// minLabelledIdentifierLength keeps short numbers after a label, such as counts, readable
+const minLabelledIdentifierLength = 6
+
+// ScrubPII masks PANs, application numbers and demat account (DP and client) IDs in text,
+// keeping the last characters so log lines can still be told apart. PANs are masked as
+// by MaskPAN.
+func ScrubPII(text string) string {
+	text = piiLabelledPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := piiLabelledPattern.FindStringSubmatch(match)
+		if len(groups[2]) < minLabelledIdentifierLength {
+			return match
+		}
+		return groups[1] + maskIdentifier(groups[2])
+	})
+	text = piiPANPattern.ReplaceAllStringFunc(text, MaskPAN)
+	return piiDematPattern.ReplaceAllStringFunc(text, maskIdentifier)
+}
+
+// maskIdentifier hides all but the last two characters of an identifier
+func maskIdentifier(identifier string) string {
+	if len(identifier) <= 4 {
+		return strings.Repeat("*", len(identifier))
+	}
+	return strings.Repeat("*", len(identifier)-2) + identifier[len(identifier)-2:]
+}
+
+// scrubbedError is an error whose message has PII masked. It unwraps to the original, so
+// errors.Is and the error categories still see the cause.
+type scrubbedError struct {
+	err     error
+	message string
+}
+
+func (e *scrubbedError) Error() string {
+	return e.message
+}
+
+func (e *scrubbedError) Unwrap() error {
+	return e.err
+}
+
+// ScrubError returns err with PII masked from its message, or err itself when the message
+// has none
+func ScrubError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	scrubbed := ScrubPII(message)
+	if scrubbed == message {
+		return err
+	}
+	return &scrubbedError{err: err, message: scrubbed}
+}
+
+// PIIScrubbingFormatter masks PII in log messages and field values before Formatter
+// writes the entry
+type PIIScrubbingFormatter struct {
+	Formatter logrus.Formatter
+}
+
+func (f *PIIScrubbingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	scrubbed := entry.Dup()
+	scrubbed.Level = entry.Level
+	scrubbed.Caller = entry.Caller
+	scrubbed.Buffer = entry.Buffer
+	scrubbed.Message = ScrubPII(entry.Message)
+	for key, value := range scrubbed.Data {
+		switch value := value.(type) {
+		case string:
+			scrubbed.Data[key] = ScrubPII(value)
+		case error:
+			scrubbed.Data[key] = ScrubError(value)
+		default:
+			// Other values are only replaced when their text carries PII
+			text := fmt.Sprint(value)
+			if clean := ScrubPII(text); clean != text {
+				scrubbed.Data[key] = clean
+			}
+		}
+	}
+	return f.Formatter.Format(scrubbed)
+}
+
+// ScrubLoggerPII makes logger mask PII in everything it writes, keeping its formatter
+func ScrubLoggerPII(logger *logrus.Logger) {
+	if _, installed := logger.Formatter.(*PIIScrubbingFormatter); installed {
+		return
+	}
+	logger.SetFormatter(&PIIScrubbingFormatter{Formatter: logger.Formatter})
+}
@@ -0,0 +1,174 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// What scraping jobs do inside a politeness window
+const (
+	PolitenessSkip = "skip" // hold scheduled scrapes until the window ends
+	PolitenessSlow = "slow" // scrape one source at a time, pausing between requests
+)
+
+// DefaultPolitenessPause is how long slow-mode scrapes wait between source requests
+const DefaultPolitenessPause = 5 * time.Second
+
+// politenessLocation is the zone politeness windows are given in
+var politenessLocation = func() *time.Location {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return location
+}()
+
+// PolitenessWindow is a daily time range (IST), such as a source's peak hours, in which
+// scraping jobs go easy on their sources. Start and End are offsets from midnight; a
+// window whose End is before its Start runs past midnight.
+type PolitenessWindow struct {
+	Start time.Duration
+	End   time.Duration
+	Mode  string
+}
+
+// String formats the window as "HH:MM-HH:MM=mode"
+func (w PolitenessWindow) String() string {
+	clock := func(offset time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+	}
+	return fmt.Sprintf("%s-%s=%s", clock(w.Start), clock(w.End), w.Mode)
+}
+
+// MarshalText serializes the window as its String form
+func (w PolitenessWindow) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// contains reports whether offset from midnight falls in the window
+func (w PolitenessWindow) contains(offset time.Duration) bool {
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// ParsePolitenessWindows parses comma-separated "HH:MM-HH:MM" windows (IST), each
+// optionally followed by "=skip" or "=slow". The mode defaults to skip.
+func ParsePolitenessWindows(spec string) ([]PolitenessWindow, error) {
+	var windows []PolitenessWindow
+	if strings.TrimSpace(spec) == "" {
+		return windows, nil
+	}
+
+	parseClock := func(value string) (time.Duration, error) {
+		parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+		}
+		return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		window := PolitenessWindow{Mode: PolitenessSkip}
+		span := strings.TrimSpace(entry)
+		if parts := strings.SplitN(span, "=", 2); len(parts) == 2 {
+			span = parts[0]
+			window.Mode = strings.ToLower(strings.TrimSpace(parts[1]))
+			if window.Mode != PolitenessSkip && window.Mode != PolitenessSlow {
+				return nil, fmt.Errorf("invalid politeness window %q: unknown mode %q", entry, parts[1])
+			}
+		}
+		bounds := strings.SplitN(span, "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid politeness window %q, expected HH:MM-HH:MM", entry)
+		}
+		var err error
+		if window.Start, err = parseClock(bounds[0]); err != nil {
+			return nil, fmt.Errorf("invalid politeness window %q: %w", entry, err)
+		}
+		if window.End, err = parseClock(bounds[1]); err != nil {
+			return nil, fmt.Errorf("invalid politeness window %q: %w", entry, err)
+		}
+		if window.Start == window.End {
+			return nil, fmt.Errorf("invalid politeness window %q: start and end are equal", entry)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// PolitenessSchedule is the daily windows in which scraping jobs go easy on their
+// sources. A nil schedule has no windows, so jobs without one always scrape normally.
+type PolitenessSchedule struct {
+	Windows []PolitenessWindow
+	// Pause is how long slow-mode scrapes wait between source requests
+	Pause time.Duration
+	// Clock decides whether a window is open
+	Clock Clock
+}
+
+func NewPolitenessSchedule(windows []PolitenessWindow) *PolitenessSchedule {
+	return &PolitenessSchedule{Windows: windows, Pause: DefaultPolitenessPause, Clock: DefaultClock}
+}
+
+// Active returns the window open now and when it ends. When windows overlap, a skip
+// window wins over a slow one.
+func (s *PolitenessSchedule) Active() (PolitenessWindow, time.Time, bool) {
+	if s == nil {
+		return PolitenessWindow{}, time.Time{}, false
+	}
+	now := ClockOrDefault(s.Clock).Now().In(politenessLocation)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, politenessLocation)
+	offset := now.Sub(midnight)
+
+	var active PolitenessWindow
+	var ends time.Time
+	found := false
+	for _, window := range s.Windows {
+		if !window.contains(offset) {
+			continue
+		}
+		end := midnight.Add(window.End)
+		if window.End <= offset {
+			end = midnight.AddDate(0, 0, 1).Add(window.End)
+		}
+		if !found || (window.Mode == PolitenessSkip && active.Mode != PolitenessSkip) {
+			active, ends, found = window, end, true
+		}
+	}
+	return active, ends, found
+}
+
+// SkipUntil reports whether scheduled scrapes should be held now, and until when
+func (s *PolitenessSchedule) SkipUntil() (time.Time, bool) {
+	window, ends, ok := s.Active()
+	if !ok || window.Mode != PolitenessSkip {
+		return time.Time{}, false
+	}
+	return ends, true
+}
+
+// Slow reports whether a slow window is open now
+func (s *PolitenessSchedule) Slow() bool {
+	window, _, ok := s.Active()
+	return ok && window.Mode == PolitenessSlow
+}
+
+// Wait pauses for Pause when a slow window is open, and returns at once otherwise or
+// with ctx's error when ctx is done first
+func (s *PolitenessSchedule) Wait(ctx context.Context) error {
+	if !s.Slow() || s.Pause <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(s.Pause)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
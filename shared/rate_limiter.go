@@ -1,51 +1,251 @@
 package shared
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-// HTTPRequestRateLimiter implements thread-safe rate limiting for HTTP requests
+// TokenBucketLimiter is a context-aware token bucket rate limiter with one bucket per key
+// (typically the request host). Each bucket refills one token per interval and holds at
+// most burst tokens, so short bursts pass immediately while the long-run rate is capped.
+type TokenBucketLimiter struct {
+	mutex     sync.Mutex
+	interval  time.Duration
+	burst     int
+	overrides map[string]bucketLimit
+	buckets   map[string]*tokenBucket
+}
+
+// bucketLimit is the refill interval and capacity of a bucket
+type bucketLimit struct {
+	interval time.Duration
+	burst    int
+}
+
+// tokenBucket holds the state of a single key's bucket. Tokens may go negative while
+// callers are waiting on reservations.
+type tokenBucket struct {
+	limit      bucketLimit
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewTokenBucketLimiter creates a limiter allowing one request per interval per key,
+// with bursts of up to burst requests
+func NewTokenBucketLimiter(interval time.Duration, burst int) *TokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucketLimiter{
+		interval:  interval,
+		burst:     burst,
+		overrides: make(map[string]bucketLimit),
+		buckets:   make(map[string]*tokenBucket),
+	}
+}
+
+// SetLimit changes the default interval and burst; existing buckets adopt it on next use
+func (l *TokenBucketLimiter) SetLimit(interval time.Duration, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.interval = interval
+	l.burst = burst
+	for key, bucket := range l.buckets {
+		if _, overridden := l.overrides[key]; !overridden {
+			bucket.limit = bucketLimit{interval: interval, burst: burst}
+		}
+	}
+}
+
+// SetKeyLimit overrides the interval and burst for a single key
+func (l *TokenBucketLimiter) SetKeyLimit(key string, interval time.Duration, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit := bucketLimit{interval: interval, burst: burst}
+	l.overrides[key] = limit
+	if bucket, exists := l.buckets[key]; exists {
+		bucket.limit = limit
+	}
+}
+
+// Wait blocks until a token for key is available or ctx is done. A cancelled wait
+// returns its reservation so it does not delay later callers.
+func (l *TokenBucketLimiter) Wait(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delay := l.reserve(key)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release(key)
+		return ctx.Err()
+	}
+}
+
+// Allow takes a token for key if one is available without waiting
+func (l *TokenBucketLimiter) Allow(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	bucket := l.bucketLocked(key, time.Now())
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// Reset discards all bucket state; overrides are kept
+func (l *TokenBucketLimiter) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.buckets = make(map[string]*tokenBucket)
+}
+
+// reserve takes a token for key and returns how long the caller must wait for it
+func (l *TokenBucketLimiter) reserve(key string) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	bucket := l.bucketLocked(key, time.Now())
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens * float64(bucket.limit.interval))
+}
+
+// release returns a token taken by a reservation that was abandoned
+func (l *TokenBucketLimiter) release(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if bucket, exists := l.buckets[key]; exists {
+		bucket.tokens++
+	}
+}
+
+// bucketLocked returns key's bucket refilled up to now; the caller holds the mutex
+func (l *TokenBucketLimiter) bucketLocked(key string, now time.Time) *tokenBucket {
+	bucket, exists := l.buckets[key]
+	if !exists {
+		limit, overridden := l.overrides[key]
+		if !overridden {
+			limit = bucketLimit{interval: l.interval, burst: l.burst}
+		}
+		bucket = &tokenBucket{limit: limit, tokens: float64(limit.burst), lastRefill: now}
+		l.buckets[key] = bucket
+		return bucket
+	}
+
+	if bucket.limit.interval <= 0 {
+		bucket.tokens = float64(bucket.limit.burst)
+	} else {
+		refill := float64(now.Sub(bucket.lastRefill)) / float64(bucket.limit.interval)
+		bucket.tokens += refill
+		if bucket.tokens > float64(bucket.limit.burst) {
+			bucket.tokens = float64(bucket.limit.burst)
+		}
+	}
+	bucket.lastRefill = now
+	return bucket
+}
+
+// HTTPRequestRateLimiter implements thread-safe rate limiting for HTTP requests on top of
+// a per-host token bucket
 type HTTPRequestRateLimiter struct {
-	minimumDelay    time.Duration // Minimum delay between requests
+	limiter         *TokenBucketLimiter
+	minimumDelay    time.Duration // Minimum delay between requests once the burst is spent
+	burst           int           // Requests allowed back to back
+	mutex           sync.Mutex    // Ensures thread-safe access to the counters
 	lastRequestTime time.Time     // Timestamp of the last request
-	mutex           sync.Mutex    // Ensures thread-safe access
 	requestCount    int64         // Total number of requests processed
 }
 
 // NewHTTPRequestRateLimiter creates a new rate limiter with the specified minimum delay
 func NewHTTPRequestRateLimiter(minimumDelay time.Duration) *HTTPRequestRateLimiter {
+	return NewHTTPRequestRateLimiterWithBurst(minimumDelay, 1)
+}
+
+// NewHTTPRequestRateLimiterWithBurst creates a rate limiter that allows burst requests
+// back to back before spacing them by minimumDelay
+func NewHTTPRequestRateLimiterWithBurst(minimumDelay time.Duration, burst int) *HTTPRequestRateLimiter {
 	return &HTTPRequestRateLimiter{
+		limiter:         NewTokenBucketLimiter(minimumDelay, burst),
 		minimumDelay:    minimumDelay,
+		burst:           burst,
 		lastRequestTime: time.Now(),
-		requestCount:    0,
 	}
 }
 
-// EnforceRateLimit blocks execution until the minimum delay has elapsed since the last request
-func (limiter *HTTPRequestRateLimiter) EnforceRateLimit() {
+// Wait blocks until a request may be made or ctx is done
+func (limiter *HTTPRequestRateLimiter) Wait(ctx context.Context) error {
+	return limiter.WaitForHost(ctx, "")
+}
+
+// WaitForHost blocks until a request to host may be made or ctx is done. Each host has
+// its own bucket, so a slow host does not hold up requests to another.
+func (limiter *HTTPRequestRateLimiter) WaitForHost(ctx context.Context, host string) error {
+	startTime := time.Now()
+	if err := limiter.limiter.Wait(ctx, host); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"component": "HTTPRequestRateLimiter",
+			"host":      host,
+			"waited":    time.Since(startTime),
+		}).Debug("Rate limit wait cancelled")
+		return err
+	}
+
 	limiter.mutex.Lock()
 	defer limiter.mutex.Unlock()
 
-	elapsedTime := time.Since(limiter.lastRequestTime)
-	if elapsedTime < limiter.minimumDelay {
-		remainingDelay := limiter.minimumDelay - elapsedTime
-
+	if waited := time.Since(startTime); waited > time.Millisecond {
 		logrus.WithFields(logrus.Fields{
-			"component":       "HTTPRequestRateLimiter",
-			"elapsed_time":    elapsedTime,
-			"minimum_delay":   limiter.minimumDelay,
-			"remaining_delay": remainingDelay,
-			"request_count":   limiter.requestCount + 1,
-		}).Debug("Enforcing rate limit delay")
-
-		time.Sleep(remainingDelay)
+			"component":     "HTTPRequestRateLimiter",
+			"host":          host,
+			"waited":        waited,
+			"minimum_delay": limiter.minimumDelay,
+			"request_count": limiter.requestCount + 1,
+		}).Debug("Enforced rate limit delay")
 	}
-
 	limiter.lastRequestTime = time.Now()
 	limiter.requestCount++
+	return nil
+}
+
+// EnforceRateLimit blocks execution until a request may be made.
+//
+// Deprecated: use Wait or WaitForHost so cancellation interrupts the wait.
+func (limiter *HTTPRequestRateLimiter) EnforceRateLimit() {
+	_ = limiter.Wait(context.Background())
+}
+
+// SetHostLimit overrides the delay and burst for a single host
+func (limiter *HTTPRequestRateLimiter) SetHostLimit(host string, minimumDelay time.Duration, burst int) {
+	limiter.limiter.SetKeyLimit(host, minimumDelay, burst)
 }
 
 // GetRequestCount returns the total number of requests processed
@@ -65,10 +265,12 @@ func (limiter *HTTPRequestRateLimiter) GetLastRequestTime() time.Time {
 // UpdateMinimumDelay updates the minimum delay between requests
 func (limiter *HTTPRequestRateLimiter) UpdateMinimumDelay(newDelay time.Duration) {
 	limiter.mutex.Lock()
-	defer limiter.mutex.Unlock()
-
 	oldDelay := limiter.minimumDelay
 	limiter.minimumDelay = newDelay
+	burst := limiter.burst
+	limiter.mutex.Unlock()
+
+	limiter.limiter.SetLimit(newDelay, burst)
 
 	logrus.WithFields(logrus.Fields{
 		"component": "HTTPRequestRateLimiter",
@@ -80,10 +282,11 @@ func (limiter *HTTPRequestRateLimiter) UpdateMinimumDelay(newDelay time.Duration
 // Reset resets the rate limiter state
 func (limiter *HTTPRequestRateLimiter) Reset() {
 	limiter.mutex.Lock()
-	defer limiter.mutex.Unlock()
-
 	limiter.lastRequestTime = time.Now()
 	limiter.requestCount = 0
+	limiter.mutex.Unlock()
+
+	limiter.limiter.Reset()
 
 	logrus.WithField("component", "HTTPRequestRateLimiter").Debug("Reset rate limiter state")
 }
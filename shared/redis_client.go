@@ -0,0 +1,112 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPoolSize is how many connections a RedisClient keeps open at most
+const redisPoolSize = 4
+
+// ErrRedisNil is returned by RedisClient.Do when Redis replies with a nil
+var ErrRedisNil = redis.Nil
+
+// RedisClient is the Redis client the cache uses, a thin wrapper over go-redis covering
+// the commands the cache needs. Safe for concurrent use.
+type RedisClient struct {
+	client *redis.Client
+}
+
+// NewRedisClient creates a client for a redis:// or rediss:// (TLS) URL, in the form
+// redis[s]://[[username]:password@]host[:port][/db]. No connection is made until the
+// first command.
+func NewRedisClient(rawURL string) (*RedisClient, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL %q, expected redis[s]://[[username]:password@]host[:port][/db]: %w", rawURL, err)
+	}
+	options.PoolSize = redisPoolSize
+	options.DialTimeout = 2 * time.Second
+	options.ReadTimeout = 2 * time.Second
+	options.WriteTimeout = 2 * time.Second
+	return &RedisClient{client: redis.NewClient(options)}, nil
+}
+
+// Do sends one command and returns its reply: a string for simple and bulk strings, an
+// int64 for integers and []interface{} for arrays. A nil reply is ErrRedisNil.
+func (c *RedisClient) Do(ctx context.Context, args ...string) (interface{}, error) {
+	command := make([]interface{}, len(args))
+	for i, arg := range args {
+		command[i] = arg
+	}
+	return c.client.Do(ctx, command...).Result()
+}
+
+// Ping checks that Redis answers
+func (c *RedisClient) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Get returns the value of key; ok is false when the key does not exist
+func (c *RedisClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key, expiring after ttl when ttl is positive
+func (c *RedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Del removes keys and returns how many existed
+func (c *RedisClient) Del(ctx context.Context, keys ...string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	removed, err := c.client.Del(ctx, keys...).Result()
+	return int(removed), err
+}
+
+// DeletePrefix removes every key starting with prefix, found with SCAN so Redis is not
+// blocked, and returns how many were removed
+func (c *RedisClient) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	pattern := redisGlobEscaper.Replace(prefix) + "*"
+	var cursor uint64
+	removed := 0
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, 200).Result()
+		if err != nil {
+			return removed, err
+		}
+		count, err := c.Del(ctx, keys...)
+		removed += count
+		if err != nil {
+			return removed, err
+		}
+		if cursor = next; cursor == 0 {
+			return removed, nil
+		}
+	}
+}
+
+// redisGlobEscaper escapes the characters SCAN MATCH treats as a glob
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// Close closes the client's connections
+func (c *RedisClient) Close() error {
+	return c.client.Close()
+}
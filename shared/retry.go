@@ -0,0 +1,260 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JitterStrategy controls how randomness is added to retry delays
+type JitterStrategy string
+
+const (
+	// JitterNone uses the exact exponential delay
+	JitterNone JitterStrategy = "none"
+	// JitterProportional adds up to 10% of the delay on top of it
+	JitterProportional JitterStrategy = "proportional"
+	// JitterEqual keeps half the delay and randomizes the other half
+	JitterEqual JitterStrategy = "equal"
+	// JitterFull picks a random delay between zero and the exponential delay
+	JitterFull JitterStrategy = "full"
+)
+
+// RetryClassifier reports whether an error should be retried
+type RetryClassifier func(err error) bool
+
+// RetryPolicy describes how an operation is retried. MaxAttempts counts the
+// first attempt, so a policy with MaxAttempts 4 retries at most three times.
+// Errors Throttled reports wait ThrottleFactor times the delay before their retry.
+type RetryPolicy struct {
+	MaxAttempts    int             `json:"max_attempts"`
+	BaseDelay      time.Duration   `json:"base_delay"`
+	MaxDelay       time.Duration   `json:"max_delay"`
+	Multiplier     float64         `json:"multiplier"`
+	Jitter         JitterStrategy  `json:"jitter"`
+	ThrottleFactor float64         `json:"throttle_factor,omitempty"`
+	Retryable      RetryClassifier `json:"-"`
+	Throttled      RetryClassifier `json:"-"`
+}
+
+// RetryPolicies holds the policies injected into the HTTP layer, scrapers and database code
+type RetryPolicies struct {
+	HTTP     RetryPolicy `json:"http"`
+	Database RetryPolicy `json:"database"`
+}
+
+// DefaultHTTPRetryPolicy matches the historical scraper backoff: 1s, 2s, 4s plus a little
+// jitter. Missing pages are not retried and throttled requests back off four times longer
+// (see HTTPRetryClass).
+func DefaultHTTPRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		BaseDelay:      1 * time.Second,
+		MaxDelay:       30 * time.Second,
+		Multiplier:     2.0,
+		Jitter:         JitterProportional,
+		ThrottleFactor: DefaultHTTPThrottleFactor,
+		Retryable:      IsRetryableHTTPError,
+		Throttled:      IsThrottledHTTPError,
+	}
+}
+
+// DefaultDatabaseRetryPolicy retries transient database errors quickly
+func DefaultDatabaseRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Multiplier:  2.0,
+		Jitter:      JitterNone,
+		Retryable:   IsRetryableDatabaseError,
+	}
+}
+
+// DefaultRetryPolicies returns the default policy set
+func DefaultRetryPolicies() RetryPolicies {
+	return RetryPolicies{
+		HTTP:     DefaultHTTPRetryPolicy(),
+		Database: DefaultDatabaseRetryPolicy(),
+	}
+}
+
+// WithMaxRetries returns a copy of the policy allowing the given number of retries
+func (p RetryPolicy) WithMaxRetries(maxRetries int) RetryPolicy {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	p.MaxAttempts = maxRetries + 1
+	return p
+}
+
+// Delay returns the wait before the given retry (1 for the first retry)
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	if retry <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(multiplier, float64(retry-1)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+
+	switch p.Jitter {
+	case JitterProportional:
+		delay += time.Duration(rand.Float64() * 0.1 * float64(delay))
+	case JitterEqual:
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(half)+1))
+	case JitterFull:
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+
+	return delay
+}
+
+// retryDelay returns the wait before the given retry of an operation that failed with err.
+// A throttled error's longer delay is still capped at MaxDelay.
+func (p RetryPolicy) retryDelay(retry int, err error) time.Duration {
+	delay := p.Delay(retry)
+	if p.ThrottleFactor > 1 && p.Throttled != nil && p.Throttled(err) {
+		delay = time.Duration(float64(delay) * p.ThrottleFactor)
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Do runs operation until it succeeds, returns a non-retryable error, the
+// attempts are exhausted, or ctx is done. The attempt number passed to
+// operation starts at 1. The last error is returned unwrapped.
+func (p RetryPolicy) Do(ctx context.Context, operation func(attempt int) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.retryDelay(attempt-1, lastErr)):
+			}
+		}
+
+		lastErr = operation(attempt)
+		if lastErr == nil {
+			return nil
+		}
+		if p.Retryable != nil && !p.Retryable(lastErr) {
+			return lastErr
+		}
+	}
+
+	return lastErr
+}
+
+// HTTPStatusError reports an unexpected HTTP response status
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RetryAnyError retries every error
+func RetryAnyError(err error) bool {
+	return err != nil
+}
+
+// IsRetryableDatabaseError reports whether a database error is likely transient
+func IsRetryableDatabaseError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	errStr := strings.ToLower(err.Error())
+	retryableErrors := []string{
+		"connection refused",
+		"connection reset",
+		"timeout",
+		"temporary failure",
+		"deadlock",
+		"lock wait timeout",
+		"connection lost",
+		"server shutdown",
+	}
+
+	for _, retryableErr := range retryableErrors {
+		if strings.Contains(errStr, retryableErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseRetryPolicy applies a "key=value,..." spec on top of base. Supported keys
+// are attempts, base, max (Go durations), multiplier, jitter and throttle.
+func ParseRetryPolicy(spec string, base RetryPolicy) (RetryPolicy, error) {
+	policy := base
+	if strings.TrimSpace(spec) == "" {
+		return policy, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			return base, fmt.Errorf("invalid retry policy entry %q, expected key=value", pair)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+
+		var err error
+		switch key {
+		case "attempts":
+			policy.MaxAttempts, err = strconv.Atoi(value)
+			if err == nil && policy.MaxAttempts < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "base":
+			policy.BaseDelay, err = time.ParseDuration(value)
+		case "max":
+			policy.MaxDelay, err = time.ParseDuration(value)
+		case "multiplier":
+			policy.Multiplier, err = strconv.ParseFloat(value, 64)
+		case "throttle":
+			policy.ThrottleFactor, err = strconv.ParseFloat(value, 64)
+			if err == nil && policy.ThrottleFactor < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "jitter":
+			switch JitterStrategy(strings.ToLower(value)) {
+			case JitterNone, JitterProportional, JitterEqual, JitterFull:
+				policy.Jitter = JitterStrategy(strings.ToLower(value))
+			default:
+				err = fmt.Errorf("unknown jitter strategy")
+			}
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return base, fmt.Errorf("invalid retry policy entry %q: %w", pair, err)
+		}
+	}
+
+	return policy, nil
+}
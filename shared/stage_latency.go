@@ -0,0 +1,161 @@
+package shared
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stageLatencySamples is how many of the latest durations each stage keeps for its
+// percentiles
+const stageLatencySamples = 1000
+
+// StageTiming is how long one stage of a request took
+type StageTiming struct {
+	Stage      string  `json:"stage"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// StageTimer records how long each stage of a request takes, in the order the stages
+// first ran. A stage timed more than once adds up. A nil timer records nothing, so
+// callers that are not instrumented can pass nil. Safe for concurrent use.
+type StageTimer struct {
+	mutex  sync.Mutex
+	stages []StageTiming
+}
+
+func NewStageTimer() *StageTimer {
+	return &StageTimer{}
+}
+
+// Start begins timing stage; calling the returned function ends it
+func (t *StageTimer) Start(stage string) func() {
+	if t == nil {
+		return func() {}
+	}
+	started := time.Now()
+	return func() { t.Add(stage, time.Since(started)) }
+}
+
+// Add records that stage took duration
+func (t *StageTimer) Add(stage string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	ms := float64(duration.Microseconds()) / 1000
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := range t.stages {
+		if t.stages[i].Stage == stage {
+			t.stages[i].DurationMs += ms
+			return
+		}
+	}
+	t.stages = append(t.stages, StageTiming{Stage: stage, DurationMs: ms})
+}
+
+// Stages returns the recorded stages in the order they first ran
+func (t *StageTimer) Stages() []StageTiming {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]StageTiming(nil), t.stages...)
+}
+
+// StageLatencySnapshot summarizes the durations of one stage. Percentiles cover the
+// latest 1000 durations; count, average and max cover every one since startup.
+type StageLatencySnapshot struct {
+	Stage     string  `json:"stage"`
+	Count     int64   `json:"count"`
+	AverageMs float64 `json:"average_ms"`
+	P50Ms     float64 `json:"p50_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+type stageLatency struct {
+	count   int64
+	totalMs float64
+	maxMs   float64
+	samples []float64 // Ring of the latest durations
+	next    int
+}
+
+// StageLatencyMetrics collects stage durations of many requests, to show which stage
+// dominates the tail latency. Safe for concurrent use.
+type StageLatencyMetrics struct {
+	mutex  sync.Mutex
+	stages map[string]*stageLatency
+	order  []string
+}
+
+func NewStageLatencyMetrics() *StageLatencyMetrics {
+	return &StageLatencyMetrics{stages: make(map[string]*stageLatency)}
+}
+
+// Record adds the stages of one request
+func (m *StageLatencyMetrics) Record(stages []StageTiming) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, timing := range stages {
+		stats, ok := m.stages[timing.Stage]
+		if !ok {
+			stats = &stageLatency{}
+			m.stages[timing.Stage] = stats
+			m.order = append(m.order, timing.Stage)
+		}
+		stats.count++
+		stats.totalMs += timing.DurationMs
+		if timing.DurationMs > stats.maxMs {
+			stats.maxMs = timing.DurationMs
+		}
+		if len(stats.samples) < stageLatencySamples {
+			stats.samples = append(stats.samples, timing.DurationMs)
+		} else {
+			stats.samples[stats.next] = timing.DurationMs
+			stats.next = (stats.next + 1) % stageLatencySamples
+		}
+	}
+}
+
+// Snapshot returns every stage seen so far, in the order each was first recorded
+func (m *StageLatencyMetrics) Snapshot() []StageLatencySnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshots := make([]StageLatencySnapshot, 0, len(m.order))
+	for _, stage := range m.order {
+		stats := m.stages[stage]
+		sorted := append([]float64(nil), stats.samples...)
+		sort.Float64s(sorted)
+		snapshots = append(snapshots, StageLatencySnapshot{
+			Stage:     stage,
+			Count:     stats.count,
+			AverageMs: stats.totalMs / float64(stats.count),
+			P50Ms:     percentileOf(sorted, 0.50),
+			P95Ms:     percentileOf(sorted, 0.95),
+			P99Ms:     percentileOf(sorted, 0.99),
+			MaxMs:     stats.maxMs,
+		})
+	}
+	return snapshots
+}
+
+// percentileOf returns the nearest-rank percentile of sorted values
+func percentileOf(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(float64(len(sorted))*percentile)) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
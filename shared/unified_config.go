@@ -15,6 +15,7 @@ type UnifiedConfiguration struct {
 	Batch    BatchConfig    `json:"batch"`
 	Cache    CacheConfig    `json:"cache"`
 	Logging  LoggingConfig  `json:"logging"`
+	Retry    RetryPolicies  `json:"retry"`
 }
 
 // ServiceConfig holds HTTP service configuration
@@ -24,6 +25,16 @@ type ServiceConfig struct {
 	RequestRateLimit   time.Duration `json:"rate_limit"`
 	MaxRetryAttempts   int           `json:"max_retries"`
 	EnableMetrics      bool          `json:"enable_metrics"`
+	RetryPolicy        *RetryPolicy  `json:"retry_policy,omitempty"`
+}
+
+// HTTPRetryPolicy returns the configured retry policy, falling back to the default
+// HTTP policy limited to MaxRetryAttempts retries
+func (c ServiceConfig) HTTPRetryPolicy() RetryPolicy {
+	if c.RetryPolicy != nil {
+		return *c.RetryPolicy
+	}
+	return DefaultHTTPRetryPolicy().WithMaxRetries(c.MaxRetryAttempts)
 }
 
 // DatabaseConfig holds database connection configuration
@@ -94,6 +105,7 @@ func NewDefaultUnifiedConfiguration() *UnifiedConfiguration {
 			EnableJSON:  true,
 			ServiceName: "ipo-backend",
 		},
+		Retry: DefaultRetryPolicies(),
 	}
 }
 
@@ -187,6 +199,17 @@ func (c *UnifiedConfiguration) ValidateAndApplyDefaults() {
 		logger.Debug("Applied default Cache.MaxSize")
 	}
 
+	// Validate Retry Config
+	if c.Retry.HTTP.MaxAttempts <= 0 || c.Retry.HTTP.Retryable == nil {
+		c.Retry.HTTP = DefaultHTTPRetryPolicy()
+		logger.Debug("Applied default Retry.HTTP")
+	}
+
+	if c.Retry.Database.MaxAttempts <= 0 || c.Retry.Database.Retryable == nil {
+		c.Retry.Database = DefaultDatabaseRetryPolicy()
+		logger.Debug("Applied default Retry.Database")
+	}
+
 	// Validate Logging Config
 	if c.Logging.Level == "" {
 		c.Logging.Level = "info"
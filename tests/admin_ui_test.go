@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminUIServesEmbeddedAssets(t *testing.T) {
+	ui := handlers.NewAdminUIHandler()
+	app := fiber.New()
+	app.Get("/admin/ui", ui.GetAsset)
+	app.Get("/admin/ui/*", ui.GetAsset)
+
+	for path, want := range map[string]string{
+		"/admin/ui":         "text/html",
+		"/admin/ui/app.js":  "javascript",
+		"/admin/ui/app.css": "text/css",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != fiber.StatusOK || len(body) == 0 {
+			t.Fatalf("expected %s to be served, got %d", path, resp.StatusCode)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.Contains(got, want) {
+			t.Errorf("expected %s to be %s, got %s", path, want, got)
+		}
+		if !strings.Contains(resp.Header.Get(fiber.HeaderContentSecurityPolicy), "default-src 'self'") {
+			t.Errorf("expected %s to carry the content security policy", path)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/admin/ui/missing.js", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected 404 for a missing asset, got %d", resp.StatusCode)
+	}
+}
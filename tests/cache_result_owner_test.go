@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/handlers"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// TestCachedResultFetchValidatesBeforeLookup checks that malformed parameters and device
+// tokens the server did not issue are rejected before the cache is queried
+func TestCachedResultFetchValidatesBeforeLookup(t *testing.T) {
+	// No database: any request reaching the lookup would fail
+	handler := handlers.NewCacheHandler(&services.CacheService{})
+	handler.FingerprintKey = []byte("test-key")
+	app := fiber.New()
+	app.Get("/cache/:ipo_id/:pan_hash", handler.GetCachedResult)
+
+	forged, err := services.NewDeviceToken([]byte("other-key"))
+	if err != nil {
+		t.Fatalf("failed to issue device token: %v", err)
+	}
+	ipoID := "7f1c9a52-2a43-4e55-9a4b-0c3f8f2f6d11"
+	panHash := shared.HashPAN("ABCDE1234F")
+	cases := []struct {
+		name        string
+		path        string
+		deviceToken string
+		status      int
+	}{
+		{"ipo_id not a UUID", "/cache/1/" + panHash, "", fiber.StatusBadRequest},
+		{"short pan_hash", "/cache/" + ipoID + "/abc123", "", fiber.StatusBadRequest},
+		{"raw PAN as pan_hash", "/cache/" + ipoID + "/ABCDE1234F", "", fiber.StatusBadRequest},
+		{"upper-case pan_hash", "/cache/" + ipoID + "/" + "A" + panHash[1:], "", fiber.StatusBadRequest},
+		{"client-chosen device ID", "/cache/" + ipoID + "/" + panHash, "device-0000000001", fiber.StatusBadRequest},
+		{"token signed with another key", "/cache/" + ipoID + "/" + panHash, forged, fiber.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+		if tc.deviceToken != "" {
+			req.Header.Set(handlers.DeviceTokenHeader, tc.deviceToken)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tc.name, err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.status, resp.StatusCode)
+		}
+	}
+}
+
+// TestDeviceTokenIssuedByServer checks that issued device tokens verify under the
+// issuing key only, and that each names its own device
+func TestDeviceTokenIssuedByServer(t *testing.T) {
+	key := []byte("test-key")
+	handler := handlers.NewCacheHandler(&services.CacheService{})
+	handler.FingerprintKey = key
+	app := fiber.New()
+	app.Post("/cache/device-token", handler.IssueDeviceToken)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/cache/device-token", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Data struct {
+			DeviceToken string `json:"device_token"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	token := body.Data.DeviceToken
+
+	owner, err := services.DeviceTokenOwnerHash(key, token)
+	if err != nil || owner == "" {
+		t.Fatalf("expected the issued token to verify, got %q, %v", owner, err)
+	}
+	if _, err := services.DeviceTokenOwnerHash([]byte("other-key"), token); err == nil {
+		t.Error("expected a token to fail under another key")
+	}
+	deviceID, signature, _ := strings.Cut(token, ".")
+	tampered := strings.Repeat("0", len(deviceID)) + "." + signature
+	if _, err := services.DeviceTokenOwnerHash(key, tampered); err == nil {
+		t.Error("expected a token with a swapped device ID to fail")
+	}
+
+	other, err := services.NewDeviceToken(key)
+	if err != nil {
+		t.Fatalf("failed to issue device token: %v", err)
+	}
+	if otherOwner, _ := services.DeviceTokenOwnerHash(key, other); otherOwner == owner {
+		t.Error("expected two device tokens to name different owners")
+	}
+}
+
+// TestCachedResultReadableByOwner checks that bound results are readable only by their
+// owner and unbound ones by anyone
+func TestCachedResultReadableByOwner(t *testing.T) {
+	owner := services.AccountOwnerHash(uuid.New())
+	other := services.AccountOwnerHash(uuid.New())
+
+	unbound := models.IPOResultCache{}
+	if !unbound.ReadableBy("") || !unbound.ReadableBy(owner) {
+		t.Error("expected an unbound result to be readable by anyone")
+	}
+
+	bound := models.IPOResultCache{OwnerHash: owner}
+	if !bound.ReadableBy(owner) {
+		t.Error("expected a bound result to be readable by its owner")
+	}
+	if bound.ReadableBy(other) || bound.ReadableBy("") {
+		t.Error("expected a bound result to be hidden from other requesters")
+	}
+}
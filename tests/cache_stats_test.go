@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// TestCacheStatsByKeyFamily checks hit, miss and expired counts per key family, the
+// memory estimate and the hottest keys of the data cache
+func TestCacheStatsByKeyFamily(t *testing.T) {
+	clock := shared.NewFakeClock(time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC))
+	cache := services.NewCacheServiceWithConfig(nil, time.Minute, 10)
+	cache.Clock = clock
+
+	cache.Set("ipo_detail:a", map[string]string{"name": "Alpha"})
+	cache.SetWithTTL("ipo_detail:b", "Beta", 10*time.Second)
+	cache.Set("active_ipos", []string{"a", "b"})
+	for i := 0; i < 3; i++ {
+		cache.Get("ipo_detail:a")
+	}
+	cache.Get("active_ipos")
+	cache.Get("ipo_detail:missing")
+	clock.Advance(11 * time.Second)
+	cache.Get("ipo_detail:b")
+
+	stats := cache.Stats(1)
+	if stats.Entries != 3 || stats.Hits != 4 || stats.Misses != 2 || stats.Expired != 1 || stats.MemoryBytes <= 0 {
+		t.Fatalf("unexpected layer totals: %+v", stats)
+	}
+	if len(stats.Families) != 2 || stats.Families[0].Family != "active_ipos" || stats.Families[1].Family != "ipo_detail" {
+		t.Fatalf("expected active_ipos and ipo_detail families, got %+v", stats.Families)
+	}
+	detail := stats.Families[1]
+	if detail.Entries != 2 || detail.Hits != 3 || detail.Misses != 2 || detail.Expired != 1 || detail.HitRate != 0.6 {
+		t.Errorf("unexpected ipo_detail family: %+v", detail)
+	}
+	if len(stats.HottestKeys) != 1 || stats.HottestKeys[0].Key != "ipo_detail:a" || stats.HottestKeys[0].Hits != 3 {
+		t.Errorf("expected ipo_detail:a as the hottest key, got %+v", stats.HottestKeys)
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+)
+
+// TestCheckSandboxReturnsDeterministicResults checks the PAN-selected sandbox outcomes
+func TestCheckSandboxReturnsDeterministicResults(t *testing.T) {
+	sandbox := services.NewCheckSandbox([]string{"qa-key"})
+	if !sandbox.Allows("qa-key") || sandbox.Allows("other") {
+		t.Fatal("expected only the configured sandbox key to be allowed")
+	}
+
+	lot := 50
+	ipo := &models.IPO{ID: uuid.New(), Registrar: "KFin Technologies", MinQty: &lot}
+	now := time.Now()
+
+	cases := []struct {
+		pan    string
+		status string
+		shares int
+	}{
+		{"ABCDE1234A", "ALLOTTED", 50},
+		{"ABCDE1234N", "NOT_ALLOTTED", 0},
+		{"ABCDE1234D", models.AllotmentStatusNotDeclared, 0},
+		{"ABCDE1234F", "NOT_FOUND", 0},
+	}
+	for _, tc := range cases {
+		result, err := sandbox.Result(ipo, tc.pan, now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.pan, err)
+		}
+		if result.Status != tc.status || result.SharesAllotted != tc.shares || result.Source != services.SandboxSource {
+			t.Errorf("%s: expected %s with %d shares from sandbox, got %+v", tc.pan, tc.status, tc.shares, result)
+		}
+	}
+
+	if _, err := sandbox.Result(ipo, "ABCDE1234R", now); shared.ErrorCategoryOf(err) != shared.ErrorCategoryRateLimited {
+		t.Errorf("expected a rate limited error, got %v", err)
+	}
+
+	first, _ := sandbox.Result(ipo, "ABCDE1234K", now)
+	second, _ := sandbox.Result(ipo, "ABCDE1234K", now)
+	if first.Status != second.Status {
+		t.Error("expected the same synthetic result for the same PAN and IPO")
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// TestIPOStatusFollowsInjectedClock walks an IPO through its lifecycle by moving a fake clock
+func TestIPOStatusFollowsInjectedClock(t *testing.T) {
+	openDate, _ := models.ParseDate("2024-01-10")
+	closeDate, _ := models.ParseDate("2024-01-12")
+	listingDate, _ := models.ParseDate("2024-01-17")
+
+	clock := shared.NewFakeClock(openDate.Time.Add(-48 * time.Hour))
+	utility := services.NewUtilityService()
+	utility.Clock = clock
+
+	steps := []struct {
+		advance time.Duration
+		want    string
+	}{
+		{0, "UPCOMING"},
+		{60 * time.Hour, "ACTIVE"},
+		{48 * time.Hour, "ACTIVE"},
+		{5 * time.Hour, "CLOSED"},
+		{5 * 24 * time.Hour, "LISTED"},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		if got := utility.CalculateIPOStatus(&openDate, &closeDate, &listingDate); got != step.want {
+			t.Errorf("at %s: expected %s, got %s", clock.Now().Format(time.RFC3339), step.want, got)
+		}
+	}
+}
+
+// TestCacheEntriesExpireOnInjectedClock checks in-memory TTLs against a fake clock
+func TestCacheEntriesExpireOnInjectedClock(t *testing.T) {
+	clock := shared.NewFakeClock(time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC))
+	cache := services.NewCacheServiceWithConfig(nil, time.Minute, 10)
+	cache.Clock = clock
+
+	cache.Set("key", "value")
+	clock.Advance(59 * time.Second)
+	if _, found := cache.Get("key"); !found {
+		t.Fatal("expected entry before its TTL")
+	}
+	clock.Advance(2 * time.Second)
+	if _, found := cache.Get("key"); found {
+		t.Fatal("expected entry to expire after its TTL")
+	}
+}
+
+// TestGMPDaysToListingUsesISTDate checks that days to listing count from the IST date, so a
+// UTC evening already on the next IST day is one day closer to listing
+func TestGMPDaysToListingUsesISTDate(t *testing.T) {
+	// DATE columns scan as midnight UTC
+	listingDate := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	clock := shared.NewFakeClock(time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC))
+
+	now := clock.Now()
+	signals := services.CalculateGMPTrendSignals(nil, now, models.NewDate(now), &listingDate)
+	if signals.DaysToListing == nil || *signals.DaysToListing != 3 {
+		t.Fatalf("expected 3 days to listing from 17 Oct IST, got %v", signals.DaysToListing)
+	}
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+// TestCloseTimesUseCategoryCutoffs checks the default and per-IPO bidding cutoffs
+func TestCloseTimesUseCategoryCutoffs(t *testing.T) {
+	closeDate, _ := models.ParseDate("2024-01-12")
+	ist := time.FixedZone("IST", 5*3600+1800)
+
+	closeTimes := services.CloseTimes(&closeDate, nil, nil)
+	if want := time.Date(2024, 1, 12, 17, 0, 0, 0, ist); !closeTimes.Retail.ClosesAt.Equal(want) {
+		t.Errorf("expected retail bids to close at %s, got %s", want, closeTimes.Retail.ClosesAt)
+	}
+	if want := time.Date(2024, 1, 12, 16, 0, 0, 0, ist); !closeTimes.HNI.ClosesAt.Equal(want) {
+		t.Errorf("expected HNI bids to close at %s, got %s", want, closeTimes.HNI.ClosesAt)
+	}
+
+	retail, hni := "15:30", "14:00"
+	closeTimes = services.CloseTimes(&closeDate, &retail, &hni)
+	if want := time.Date(2024, 1, 12, 15, 30, 0, 0, ist); !closeTimes.Retail.ClosesAt.Equal(want) {
+		t.Errorf("expected the retail cutoff override, got %s", closeTimes.Retail.ClosesAt)
+	}
+	if want := time.Date(2024, 1, 12, 14, 0, 0, 0, ist); !closeTimes.HNI.ClosesAt.Equal(want) {
+		t.Errorf("expected the HNI cutoff override, got %s", closeTimes.HNI.ClosesAt)
+	}
+
+	if services.CloseTimes(nil, nil, nil) != nil {
+		t.Error("expected no close times without a close date")
+	}
+	for _, invalid := range []string{"5pm", "25:00", "17"} {
+		if _, err := services.ParseCutoff(invalid); err == nil {
+			t.Errorf("expected cutoff %q to be rejected", invalid)
+		}
+	}
+}
+
+// TestIPOClosesExactlyAtCutoff checks the status turns CLOSED at the cutoff on the close
+// date, not at midnight
+func TestIPOClosesExactlyAtCutoff(t *testing.T) {
+	openDate, _ := models.ParseDate("2024-01-10")
+	closeDate, _ := models.ParseDate("2024-01-12")
+	utility := services.NewUtilityService()
+	cutoff := closeDate.Add(services.DefaultRetailCutoff)
+
+	for _, step := range []struct {
+		at   time.Time
+		want string
+	}{
+		{closeDate.Time.Add(time.Minute), "ACTIVE"},
+		{cutoff.Add(-time.Second), "ACTIVE"},
+		{cutoff, "CLOSED"},
+	} {
+		if got := utility.CalculateIPOStatusAt(&openDate, &closeDate, nil, step.at); got != step.want {
+			t.Errorf("at %s: expected %s, got %s", step.at.Format(time.RFC3339), step.want, got)
+		}
+	}
+}
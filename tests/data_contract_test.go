@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/database"
+	"github.com/fenilmodi00/ipo-backend/models"
+)
+
+// TestScraperDataContractMatchesSchema checks that every field the scrapers produce is
+// mapped to a column of schema.sql with a compatible type, or skipped with a reason
+func TestScraperDataContractMatchesSchema(t *testing.T) {
+	if problems := database.CheckDataContractCoverage(database.ScraperDataContracts); len(problems) > 0 {
+		t.Errorf("scraper models and data contract drifted apart:\n%s", strings.Join(problems, "\n"))
+	}
+
+	content, err := os.ReadFile("../database/schema.sql")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	columns := database.SchemaFileColumns(strings.ReplaceAll(string(content), "\r\n", "\n"))
+	for _, result := range database.CheckDataContractColumns(database.ScraperDataContracts, columns) {
+		if !result.IsValid {
+			t.Errorf("%s: missing %v, invalid %v", result.TableName, result.MissingColumns, result.InvalidConstraints)
+		}
+	}
+}
+
+// TestScraperDataContractDetectsDrift checks that unmapped model fields, unknown field
+// paths, missing columns and narrowed types are all reported
+func TestScraperDataContractDetectsDrift(t *testing.T) {
+	contract := database.DataContract{
+		Model: models.SubscriptionSnapshot{},
+		Fields: []database.DataContractField{
+			{Field: "Multiples", Table: "ipo_subscription_history", Column: "multiple", Type: "decimal(10,2)"},
+			{Field: "Applications", Table: "ipo_subscription_history", Column: "applications", Type: "bigint"},
+			{Field: "Orders", Table: "ipo_subscription_history", Column: "orders", Type: "bigint"},
+		},
+	}
+	problems := database.CheckDataContractCoverage([]database.DataContract{contract})
+	if len(problems) != 2 || !strings.Contains(strings.Join(problems, "\n"), "SubscriptionSnapshot.SharesOffered: not mapped") {
+		t.Errorf("expected the unknown Orders path and unmapped SharesOffered, got %v", problems)
+	}
+
+	columns := database.SchemaFileColumns(`
+CREATE TABLE ipo_subscription_history (
+    id UUID PRIMARY KEY,
+    multiple DECIMAL(10, 2) NOT NULL
+);
+ALTER TABLE ipo_subscription_history ADD COLUMN IF NOT EXISTS applications INTEGER;
+`)
+	results := database.CheckDataContractColumns([]database.DataContract{contract}, columns)
+	if len(results) != 1 || results[0].IsValid || len(results[0].MissingColumns) != 1 || len(results[0].InvalidConstraints) != 1 {
+		t.Fatalf("expected orders missing and applications narrowed to integer, got %+v", results)
+	}
+}
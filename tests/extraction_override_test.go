@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+// TestExtractionOverrideAppliesSelectorsThenManualFields checks that selectors replace
+// extracted values, manual fields win over them and failed selectors keep the extracted
+// value
+func TestExtractionOverrideAppliesSelectorsThenManualFields(t *testing.T) {
+	page := `<html><body><table id="summary">
+		<tr><td>Opens</td><td>Jan 15, 2024</td></tr>
+		<tr><td>Price</td><td>₹95 - ₹100</td></tr>
+		<tr><td>Lot</td><td>about 150 shares</td></tr>
+	</table></body></html>`
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("failed to parse page: %v", err)
+	}
+
+	extractedQty := 100
+	extractedName := "Example Ltd"
+	ipo := &models.IPO{Name: extractedName, Registrar: "Unknown", MinQty: &extractedQty}
+	registrar := "Bigshare Services Pvt Ltd"
+	high := 102.0
+	override := &models.ExtractionOverride{
+		Selectors: map[string]string{
+			"open_date":  "#summary tr:nth-child(1) td:last-child",
+			"price_band": "#summary tr:nth-child(2) td:last-child",
+			"min_qty":    "#summary tr:nth-child(3) td:last-child", // not a number
+			"name":       "#missing",
+		},
+		Fields: &models.ExtractionOverrideFields{Registrar: &registrar, PriceBandHigh: &high},
+	}
+
+	fetchedAt := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	applied, failed := services.NewHTMLDataExtractor().ApplyExtractionOverride(ipo, override, document, fetchedAt)
+
+	if ipo.OpenDate == nil || ipo.OpenDate.String() != "2024-01-15" {
+		t.Errorf("expected the open date from the selector, got %v", ipo.OpenDate)
+	}
+	if ipo.PriceBandLow == nil || *ipo.PriceBandLow != 95 {
+		t.Errorf("expected the lower band from the selector, got %v", ipo.PriceBandLow)
+	}
+	if ipo.PriceBandHigh == nil || *ipo.PriceBandHigh != 102 {
+		t.Errorf("expected the manual upper band to win over the selector, got %v", ipo.PriceBandHigh)
+	}
+	if ipo.Registrar != registrar {
+		t.Errorf("expected the manual registrar, got %q", ipo.Registrar)
+	}
+	if ipo.MinQty == nil || *ipo.MinQty != extractedQty || ipo.Name != extractedName {
+		t.Errorf("expected failed selectors to keep the extracted values, got min_qty %v and name %q", ipo.MinQty, ipo.Name)
+	}
+	if strings.Join(failed, ",") != "min_qty,name" {
+		t.Errorf("expected min_qty and name to fail, got %v", failed)
+	}
+	if len(applied) != 4 {
+		t.Errorf("expected open_date, both bands and registrar applied, got %v", applied)
+	}
+
+	if source := ipo.Lineage["open_date"].Source; source != "override:selector" {
+		t.Errorf("expected selector lineage on open_date, got %q", source)
+	}
+	if source := ipo.Lineage["price_band_high"].Source; source != "override:manual" {
+		t.Errorf("expected manual lineage on price_band_high, got %q", source)
+	}
+	if _, tagged := ipo.Lineage["min_qty"]; tagged {
+		t.Error("expected no override lineage on a failed selector")
+	}
+}
+
+// TestValidateExtractionOverrideRejectsBadOverrides checks the admin input checks
+func TestValidateExtractionOverrideRejectsBadOverrides(t *testing.T) {
+	low, high := 110.0, 100.0
+	empty := " "
+	invalid := map[string]*models.ExtractionOverride{
+		"empty":          {},
+		"unknown field":  {Selectors: map[string]string{"gmp_value": "td"}},
+		"bad selector":   {Selectors: map[string]string{"open_date": "td[["}},
+		"blank selector": {Selectors: map[string]string{"open_date": "  "}},
+		"inverted band":  {Fields: &models.ExtractionOverrideFields{PriceBandLow: &low, PriceBandHigh: &high}},
+		"empty name":     {Fields: &models.ExtractionOverrideFields{Name: &empty}},
+		"no fields set":  {Fields: &models.ExtractionOverrideFields{}},
+	}
+	for name, override := range invalid {
+		if err := services.ValidateExtractionOverride(override); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	valid := &models.ExtractionOverride{Selectors: map[string]string{"price_band": " #summary td.price "}}
+	if err := services.ValidateExtractionOverride(valid); err != nil {
+		t.Fatalf("expected a valid override, got %v", err)
+	}
+	if valid.Selectors["price_band"] != "#summary td.price" {
+		t.Errorf("expected the selector trimmed, got %q", valid.Selectors["price_band"])
+	}
+}
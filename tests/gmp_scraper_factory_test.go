@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+// TestGMPScraperFactorySelectsImplementation checks that SCRAPER_IMPL values map to
+// their implementations and unknown values are rejected
+func TestGMPScraperFactorySelectsImplementation(t *testing.T) {
+	for _, impl := range []string{services.ScraperImplSimplified, services.ScraperImplEnhanced} {
+		scraper, err := services.NewGMPScraper(impl, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", impl, err)
+		}
+		if scraper.Name() != impl {
+			t.Errorf("expected %s scraper, got %s", impl, scraper.Name())
+		}
+	}
+
+	if _, err := services.NewGMPScraper("legacy", nil); err == nil {
+		t.Error("expected an error for an unknown scraper implementation")
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+// fastHTTPRetryPolicy is the default HTTP policy with millisecond delays
+func fastHTTPRetryPolicy() shared.RetryPolicy {
+	policy := shared.DefaultHTTPRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 10 * time.Millisecond
+	return policy
+}
+
+func throttledRetries(host string) int64 {
+	for _, snapshot := range shared.DefaultHTTPHostMetrics.Snapshot() {
+		if snapshot.Host == host {
+			return snapshot.RetriesByClass[shared.HTTPRetryThrottled]
+		}
+	}
+	return 0
+}
+
+// TestHTTPRetryDoesNotRetryMissingPages checks 404 and 410 fail after one attempt
+func TestHTTPRetryDoesNotRetryMissingPages(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		var hits int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(status)
+		}))
+
+		request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if _, err := shared.ExecuteHTTPRequestWithPolicy(server.Client(), request, fastHTTPRetryPolicy()); err == nil {
+			t.Errorf("expected %d to fail", status)
+		}
+		if got := atomic.LoadInt32(&hits); got != 1 {
+			t.Errorf("expected %d to be tried once, got %d attempts", status, got)
+		}
+		server.Close()
+	}
+}
+
+// TestHTTPRetryRotatesUserAgentWhenThrottled checks a 429 is retried with the next
+// browser User-Agent and counted as a throttled retry
+func TestHTTPRetryRotatesUserAgentWhenThrottled(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		if len(userAgents) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	shared.SetBrowserLikeHeaders(request, "text/html")
+	host := request.URL.Hostname()
+	before := throttledRetries(host)
+
+	response, err := shared.ExecuteHTTPRequestWithPolicy(server.Client(), request, fastHTTPRetryPolicy())
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	response.Body.Close()
+
+	if len(userAgents) != 2 || userAgents[0] != shared.BrowserUserAgents[0] || userAgents[1] != shared.BrowserUserAgents[1] {
+		t.Errorf("expected the retry to use the next User-Agent, got %q", userAgents)
+	}
+	if got := throttledRetries(host) - before; got != 1 {
+		t.Errorf("expected one throttled retry, got %d", got)
+	}
+}
+
+// TestHTTPRetryClasses checks statuses and network errors map to their retry classes
+func TestHTTPRetryClasses(t *testing.T) {
+	for status, want := range map[int]string{
+		http.StatusNotFound:           shared.HTTPRetryGone,
+		http.StatusGone:               shared.HTTPRetryGone,
+		http.StatusForbidden:          shared.HTTPRetryThrottled,
+		http.StatusTooManyRequests:    shared.HTTPRetryThrottled,
+		http.StatusServiceUnavailable: shared.HTTPRetryTransient,
+		http.StatusBadRequest:         shared.HTTPRetryOther,
+	} {
+		if got := shared.HTTPRetryClass(&shared.HTTPStatusError{StatusCode: status}); got != want {
+			t.Errorf("expected %d to be %s, got %s", status, want, got)
+		}
+	}
+	if got := shared.HTTPRetryClass(shared.NetworkErrorf("connection reset")); got != shared.HTTPRetryTransient {
+		t.Errorf("expected network errors to be transient, got %s", got)
+	}
+
+	policy, err := shared.ParseRetryPolicy("throttle=8", shared.DefaultHTTPRetryPolicy())
+	if err != nil || policy.ThrottleFactor != 8 {
+		t.Errorf("expected throttle=8 to set the throttle factor, got %v (%v)", policy.ThrottleFactor, err)
+	}
+	if _, err := shared.ParseRetryPolicy("throttle=0.5", shared.DefaultHTTPRetryPolicy()); err == nil {
+		t.Error("expected a throttle factor below 1 to be rejected")
+	}
+}
+
+// TestHTTPHostBudgetHeldUntilBodyCloses checks a budget slot stays taken while a body is
+// open, and that a request waiting for it gives up with its context
+func TestHTTPHostBudgetHeldUntilBodyCloses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	shared.DefaultHTTPHostMetrics.SetHostBudget("localhost", 1)
+	defer shared.DefaultHTTPHostMetrics.SetHostBudget("localhost", 0)
+
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	first, err := shared.ExecuteHTTPRequestWithPolicy(server.Client(), request, fastHTTPRetryPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	waiting, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if _, err := shared.ExecuteHTTPRequestWithPolicy(server.Client(), waiting, fastHTTPRetryPolicy()); err == nil {
+		t.Fatal("expected the request to give up while the first body is open")
+	}
+
+	first.Body.Close()
+	request, _ = http.NewRequest(http.MethodGet, url, nil)
+	second, err := shared.ExecuteHTTPRequestWithPolicy(server.Client(), request, fastHTTPRetryPolicy())
+	if err != nil {
+		t.Fatalf("expected the slot to be free once the body closed, got %v", err)
+	}
+	second.Body.Close()
+}
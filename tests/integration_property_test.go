@@ -12,7 +12,6 @@ import (
 	"github.com/fenilmodi00/ipo-backend/models"
 	"github.com/fenilmodi00/ipo-backend/services"
 	"github.com/fenilmodi00/ipo-backend/shared"
-	"github.com/google/uuid"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
@@ -670,91 +669,9 @@ func TestDataValidationConsistencyProperties(t *testing.T) {
 			closeDate := openDate.Add(7 * 24 * time.Hour)
 			listingDate := closeDate.Add(7 * 24 * time.Hour)
 
-			testIPO := &models.IPO{
-				ID:            uuid.New(),
-				StockID:       "TEST_" + companyCode,
-				Name:          companyName,
-				CompanyCode:   companyCode,
-				Registrar:     "Test Registrar",
-				OpenDate:      &openDate,
-				CloseDate:     &closeDate,
-				ListingDate:   &listingDate,
-				PriceBandLow:  &priceLow,
-				PriceBandHigh: &priceHigh,
-				MinQty:        &minQty,
-				MinAmount:     &minAmount,
-				Status:        "UPCOMING",
-			}
-
-			// Test IPO validation consistency
-			// validator1 := services.NewUnifiedValidator(suite.utilityService, suite.db)
-			// validator2 := services.NewUnifiedValidator(services.NewUtilityService(), suite.db)
-
-			// result1 := validator1.Validate(testIPO)
-			// result2 := validator2.Validate(testIPO)
-
 			// Declare variables for later reuse
 			var priceBandValid1, priceBandValid2 bool
 
-			// Validation results should be identical (commented out since validator removed)
-			if result1.IsValid != result2.IsValid {
-				t.Logf("Inconsistent validation results: %v vs %v", result1.IsValid, result2.IsValid)
-				return false
-			}
-
-			if len(result1.Errors) != len(result2.Errors) {
-				t.Logf("Inconsistent error counts: %d vs %d", len(result1.Errors), len(result2.Errors))
-				return false
-			}
-
-			if len(result1.Warnings) != len(result2.Warnings) {
-				t.Logf("Inconsistent warning counts: %d vs %d", len(result1.Warnings), len(result2.Warnings))
-				return false
-			}
-
-			// Field validation results should be identical
-			for field, valid1 := range result1.FieldResults {
-				if valid2, exists := result2.FieldResults[field]; !exists || valid1 != valid2 {
-					t.Logf("Inconsistent field validation for %s: %v vs %v", field, valid1, valid2)
-					return false
-				}
-			}
-
-			// Test utility service validation consistency using unified validator
-			// validator1 = services.NewUnifiedValidator(suite.utilityService, suite.db)
-			// validator2 = services.NewUnifiedValidator(services.NewUtilityService(), suite.db)
-
-			// Create test IPO data
-			calculatedMinQty := int(float64(minAmount) / priceHigh)
-			if calculatedMinQty <= 0 {
-				calculatedMinQty = 1
-			}
-
-			ipo := &models.IPO{
-				Name:          companyName,
-				PriceBandLow:  &priceLow,
-				PriceBandHigh: &priceHigh,
-				MinQty:        &calculatedMinQty,
-				MinAmount:     &minAmount,
-				OpenDate:      &openDate,
-				CloseDate:     &closeDate,
-				ListingDate:   &listingDate,
-			}
-
-			// validationResult1 = validator1.Validate(ipo)
-			// validationResult2 = validator2.Validate(ipo)
-
-			// Utility validation results should be identical (commented out since validator removed)
-			// if validationResult1.IsValid != validationResult2.IsValid {
-			//	t.Logf("Inconsistent utility validation results: %v vs %v", validationResult1.IsValid, validationResult2.IsValid)
-			//	return false
-			// }
-
-			// if len(validationResult1.Errors) != len(validationResult2.Errors) {
-			//	t.Logf("Inconsistent utility error counts: %d vs %d", len(validationResult1.Errors), len(validationResult2.Errors))
-			//	return false
-			// }
-
 			// Test price band validation consistency using business logic
 			priceBandValid1 = (priceLow <= priceHigh && priceLow > 0 && priceHigh > 0) || (priceLow == 0 && priceHigh == 0)
 			priceBandValid2 = (priceLow <= priceHigh && priceLow > 0 && priceHigh > 0) || (priceLow == 0 && priceHigh == 0)
@@ -768,7 +685,7 @@ func TestDataValidationConsistencyProperties(t *testing.T) {
 			// Focus on public interface validation instead
 
 			// Test status calculation consistency
-			status1 := suite.utilityService.CalculateIPOStatus(&openDate, &closeDate, &listingDate)
+			status1 := suite.utilityService.CalculateIPOStatus(models.DateOf(&openDate), models.DateOf(&closeDate), models.DateOf(&listingDate))
 			// Verify status is valid (not empty)
 			if status1 == "" {
 				t.Logf("Invalid status calculation result")
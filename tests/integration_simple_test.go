@@ -104,9 +104,9 @@ func TestSimpleEndToEndDataFlowConsistency(t *testing.T) {
 				Name:          cleanedName,
 				CompanyCode:   normalizedCode,
 				Registrar:     cleanedRegistrar,
-				OpenDate:      &openDate,
-				CloseDate:     &closeDate,
-				ListingDate:   &listingDate,
+				OpenDate:      models.DateOf(&openDate),
+				CloseDate:     models.DateOf(&closeDate),
+				ListingDate:   models.DateOf(&listingDate),
 				PriceBandLow:  &priceLow,
 				PriceBandHigh: &priceHigh,
 				MinQty:        &minQty,
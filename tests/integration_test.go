@@ -105,9 +105,9 @@ func TestEndToEndDataFlowConsistency(t *testing.T) {
 				Name:          cleanedName,
 				CompanyCode:   normalizedCode,
 				Registrar:     cleanedRegistrar,
-				OpenDate:      &openDate,
-				CloseDate:     &closeDate,
-				ListingDate:   &listingDate,
+				OpenDate:      models.DateOf(&openDate),
+				CloseDate:     models.DateOf(&closeDate),
+				ListingDate:   models.DateOf(&listingDate),
 				PriceBandLow:  &priceLow,
 				PriceBandHigh: &priceHigh,
 				MinQty:        &minQty,
@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIPOAsOfRejectsInvalidRequests(t *testing.T) {
+	app := fiber.New()
+	app.Get("/admin/ipos/:id/as-of", (&handlers.AdminHandler{}).GetIPOAsOf)
+
+	id := "6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f"
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	for _, path := range []string{
+		"/admin/ipos/not-a-uuid/as-of?ts=2024-01-10",
+		"/admin/ipos/" + id + "/as-of",
+		"/admin/ipos/" + id + "/as-of?ts=yesterday",
+		"/admin/ipos/" + id + "/as-of?ts=" + future,
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("expected %s to be rejected with 400, got %d", path, resp.StatusCode)
+		}
+	}
+}
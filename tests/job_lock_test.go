@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/jobs"
+)
+
+// fakeLockState stands in for the Postgres state the job locker reads: whether another
+// replica holds the advisory lock, and the job_run_leases rows
+type fakeLockState struct {
+	mutex      sync.Mutex
+	otherHolds bool
+	leases     map[string]time.Time
+	unlocks    int
+}
+
+var (
+	fakeLockStates   = map[string]*fakeLockState{}
+	fakeLockStatesMu sync.Mutex
+	fakeLockRegister sync.Once
+)
+
+// openFakeLockDB returns a database backed by a fresh fakeLockState
+func openFakeLockDB(t *testing.T) (*sql.DB, *fakeLockState) {
+	fakeLockRegister.Do(func() { sql.Register("joblockfake", fakeLockDriver{}) })
+	state := &fakeLockState{leases: map[string]time.Time{}}
+	fakeLockStatesMu.Lock()
+	fakeLockStates[t.Name()] = state
+	fakeLockStatesMu.Unlock()
+
+	db, err := sql.Open("joblockfake", t.Name())
+	if err != nil {
+		t.Fatalf("open fake database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, state
+}
+
+type fakeLockDriver struct{}
+
+func (fakeLockDriver) Open(name string) (driver.Conn, error) {
+	fakeLockStatesMu.Lock()
+	defer fakeLockStatesMu.Unlock()
+	return &fakeLockConn{state: fakeLockStates[name]}, nil
+}
+
+type fakeLockConn struct{ state *fakeLockState }
+
+func (c *fakeLockConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeLockStmt{state: c.state, query: query}, nil
+}
+func (c *fakeLockConn) Close() error              { return nil }
+func (c *fakeLockConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeLockStmt struct {
+	state *fakeLockState
+	query string
+}
+
+func (s *fakeLockStmt) Close() error  { return nil }
+func (s *fakeLockStmt) NumInput() int { return -1 }
+
+func (s *fakeLockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.state.mutex.Lock()
+	defer s.state.mutex.Unlock()
+	if !strings.Contains(s.query, "INSERT INTO job_run_leases") {
+		return nil, errors.New("unexpected exec: " + s.query)
+	}
+	s.state.leases[args[0].(string)] = args[1].(time.Time)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeLockStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.state.mutex.Lock()
+	defer s.state.mutex.Unlock()
+	switch {
+	case strings.Contains(s.query, "pg_try_advisory_lock"):
+		return &fakeLockRows{value: !s.state.otherHolds}, nil
+	case strings.Contains(s.query, "pg_advisory_unlock"):
+		s.state.unlocks++
+		return &fakeLockRows{value: true}, nil
+	case strings.Contains(s.query, "job_run_leases"):
+		start, ok := s.state.leases[args[0].(string)]
+		return &fakeLockRows{value: ok && !start.Before(args[1].(time.Time))}, nil
+	case strings.Contains(s.query, "pg_locks"):
+		return &fakeLockRows{value: true}, nil
+	}
+	return nil, errors.New("unexpected query: " + s.query)
+}
+
+// fakeLockRows is a single row with a single boolean column
+type fakeLockRows struct {
+	value bool
+	read  bool
+}
+
+func (r *fakeLockRows) Columns() []string { return []string{"value"} }
+func (r *fakeLockRows) Close() error      { return nil }
+func (r *fakeLockRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+func TestJobPeriodStartIsAlignedAcrossReplicas(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	at := time.Date(2024, 1, 15, 14, 45, 0, 0, ist) // 09:15 UTC
+
+	if got, want := jobs.JobPeriodStart(at, 8*time.Hour), time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected the 8h period to start at %v, got %v", want, got)
+	}
+	if got, want := jobs.JobPeriodStart(at, time.Hour), time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected the 1h period to start at %v, got %v", want, got)
+	}
+}
+
+func TestJobLockerSkipsCompletedPeriod(t *testing.T) {
+	db, state := openFakeLockDB(t)
+	locker := jobs.NewJobLocker(db)
+	locker.Periods = map[string]time.Duration{"daily_ipo_update": 8 * time.Hour}
+
+	runs := 0
+	job := func() { runs++ }
+
+	if outcome := locker.RunLocked("daily_ipo_update", job); outcome != jobs.LockOutcomeRan {
+		t.Fatalf("expected the first run of the period to run, got %q", outcome)
+	}
+	if _, ok := state.leases["daily_ipo_update"]; !ok {
+		t.Fatal("expected the completed period to be recorded")
+	}
+	if outcome := locker.RunLocked("daily_ipo_update", job); outcome != jobs.LockOutcomeDone {
+		t.Fatalf("expected the completed period to be skipped, got %q", outcome)
+	}
+	if runs != 1 {
+		t.Errorf("expected the job to run once, ran %d times", runs)
+	}
+	if state.unlocks != 2 {
+		t.Errorf("expected the lock to be released after both runs, got %d unlocks", state.unlocks)
+	}
+
+	stats := locker.GetStats()
+	if len(stats) != 1 || stats[0].Acquired != 1 || stats[0].PeriodDone != 1 || stats[0].Held {
+		t.Errorf("expected one acquired run and one completed period, got %+v", stats)
+	}
+}
+
+func TestJobLockerRunsOnceLastPeriodExpired(t *testing.T) {
+	db, state := openFakeLockDB(t)
+	locker := jobs.NewJobLocker(db)
+	locker.Periods = map[string]time.Duration{"hotness_score": time.Hour}
+	state.leases["hotness_score"] = jobs.JobPeriodStart(time.Now(), time.Hour).Add(-time.Hour)
+
+	ran := false
+	if outcome := locker.RunLocked("hotness_score", func() { ran = true }); outcome != jobs.LockOutcomeRan || !ran {
+		t.Fatalf("expected a run once the last period ended, got %q", outcome)
+	}
+}
+
+func TestJobLockerSkipsWhenAnotherReplicaHoldsLock(t *testing.T) {
+	db, state := openFakeLockDB(t)
+	state.otherHolds = true
+	locker := jobs.NewJobLocker(db)
+
+	ran := false
+	if locker.RunExclusive("cache_cleanup", func() { ran = true }) || ran {
+		t.Fatal("expected the run to be skipped while another replica holds the lock")
+	}
+	if stats := locker.GetStats(); len(stats) != 1 || stats[0].Skipped != 1 || stats[0].Acquired != 0 {
+		t.Errorf("expected one skipped run, got %+v", stats)
+	}
+}
+
+func TestJobLockerWithoutPeriodRunsEveryTime(t *testing.T) {
+	db, state := openFakeLockDB(t)
+	locker := jobs.NewJobLocker(db)
+
+	runs := 0
+	for i := 0; i < 2; i++ {
+		if !locker.RunExclusive("gmp_update", func() { runs++ }) {
+			t.Fatalf("expected run %d to run", i+1)
+		}
+	}
+	if runs != 2 || len(state.leases) != 0 {
+		t.Errorf("expected two runs and no leases, got %d runs and %v", runs, state.leases)
+	}
+}
+
+func TestJobSchedulerReportsCompletedPeriod(t *testing.T) {
+	db, _ := openFakeLockDB(t)
+	locker := jobs.NewJobLocker(db)
+	locker.Periods = map[string]time.Duration{"cache_cleanup": 12 * time.Hour}
+	scheduler := jobs.NewJobScheduler(locker, nil)
+
+	if outcome := scheduler.Run("cache_cleanup", func() {}); outcome != jobs.JobOutcomeRan {
+		t.Fatalf("expected the first run to run, got %q", outcome)
+	}
+	if outcome := scheduler.Run("cache_cleanup", func() {}); outcome != jobs.JobOutcomeDone {
+		t.Errorf("expected the second run to find its period done, got %q", outcome)
+	}
+}
+
+func TestJobLockerWithoutDatabaseRunsJob(t *testing.T) {
+	var locker *jobs.JobLocker
+	ran := false
+	if outcome := locker.RunLocked("daily_ipo_update", func() { ran = true }); outcome != jobs.LockOutcomeRan || !ran {
+		t.Errorf("expected a nil locker to run the job, got %q", outcome)
+	}
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/jobs"
+)
+
+func TestJobRunsDeadlinePrecedence(t *testing.T) {
+	runs := jobs.NewJobRuns()
+	runs.DefaultDeadline = 20 * time.Minute
+	runs.Deadlines = map[string]time.Duration{"daily_ipo_update": 25 * time.Minute}
+
+	if got := runs.Deadline("daily_ipo_update", 15*time.Minute); got != 25*time.Minute {
+		t.Errorf("expected the configured override, got %v", got)
+	}
+	if got := runs.Deadline("hotness_score", 5*time.Minute); got != 5*time.Minute {
+		t.Errorf("expected the job's own deadline, got %v", got)
+	}
+	if got := runs.Deadline("refresh.gmp", 0); got != 20*time.Minute {
+		t.Errorf("expected the default deadline, got %v", got)
+	}
+}
+
+func TestJobRunsTracksInFlightAndTimeouts(t *testing.T) {
+	runs := jobs.NewJobRuns()
+	runs.OverrunGrace = 20 * time.Millisecond
+
+	ctx, done := runs.Start(context.Background(), "slow_job", 10*time.Millisecond)
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > 10*time.Millisecond {
+		t.Fatalf("expected the run context to carry its deadline, got %v %v", deadline, ok)
+	}
+	_, quickDone := runs.Start(context.Background(), "quick_job", time.Minute)
+
+	stats := runs.Snapshot()
+	if stats.InFlight != 2 || stats.InFlightByJob["slow_job"] != 1 || len(stats.Active) != 2 {
+		t.Fatalf("expected 2 runs in flight, got %+v", stats)
+	}
+	quickDone()
+
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	stats = runs.Snapshot()
+	if stats.InFlight != 1 || stats.Overdue != 1 || stats.Overran != 1 || !stats.Active[0].Overran {
+		t.Fatalf("expected the slow run overdue and overran, got %+v", stats)
+	}
+
+	done()
+	done()
+	stats = runs.Snapshot()
+	if stats.InFlight != 0 || stats.Started != 2 || stats.TimedOut != 1 {
+		t.Errorf("expected no runs in flight and one timeout, got %+v", stats)
+	}
+}
@@ -0,0 +1,27 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+// TestMandateDeadlineIsFivePMISTOnCloseDate checks the computed UPI mandate deadline
+func TestMandateDeadlineIsFivePMISTOnCloseDate(t *testing.T) {
+	closeDate, _ := models.ParseDate("2024-01-17")
+
+	deadline := services.MandateDeadline(&closeDate)
+	if deadline == nil {
+		t.Fatal("expected a mandate deadline for an IPO with a close date")
+	}
+	want := time.Date(2024, 1, 17, 11, 30, 0, 0, time.UTC)
+	if !deadline.Equal(want) {
+		t.Errorf("expected %s, got %s", want.Format(time.RFC3339), deadline.Format(time.RFC3339))
+	}
+
+	if services.MandateDeadline(nil) != nil {
+		t.Error("expected no mandate deadline without a close date")
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/sirupsen/logrus"
+)
+
+// Run with -race: the metrics structs are shared by concurrent scrapes, so every
+// recording and read below must be synchronized
+
+const (
+	metricsWorkers   = 8
+	metricsPerWorker = 500
+)
+
+// runConcurrently runs record in metricsWorkers goroutines metricsPerWorker times each,
+// alongside a reader calling read until they finish
+func runConcurrently(record func(i int), read func()) {
+	var writers sync.WaitGroup
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				read()
+			}
+		}
+	}()
+
+	for w := 0; w < metricsWorkers; w++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for i := 0; i < metricsPerWorker; i++ {
+				record(i)
+			}
+		}()
+	}
+	writers.Wait()
+	close(done)
+	<-readerDone
+}
+
+func TestExtractionMetricsConcurrentRecording(t *testing.T) {
+	metrics := services.NewExtractionMetrics()
+
+	runConcurrently(func(i int) {
+		metrics.RecordDescriptionAttempt(i%2 == 0)
+		metrics.RecordAboutAttempt(i%4 == 0)
+		metrics.RecordHTMLParseError()
+		metrics.RecordTextCleaningError()
+	}, func() {
+		counts := metrics.Snapshot()
+		if counts.DescriptionSuccess > counts.DescriptionAttempts {
+			t.Errorf("inconsistent snapshot: %d successes of %d attempts", counts.DescriptionSuccess, counts.DescriptionAttempts)
+		}
+	})
+
+	total := metricsWorkers * metricsPerWorker
+	counts := metrics.Snapshot()
+	expected := services.ExtractionCounts{
+		DescriptionAttempts: total,
+		DescriptionSuccess:  total / 2,
+		AboutAttempts:       total,
+		AboutSuccess:        total / 4,
+		HTMLParseErrors:     total,
+		TextCleaningErrors:  total,
+	}
+	if counts != expected {
+		t.Errorf("expected %+v, got %+v", expected, counts)
+	}
+
+	metrics.Reset()
+	if counts := metrics.Snapshot(); counts != (services.ExtractionCounts{}) {
+		t.Errorf("expected zero counts after reset, got %+v", counts)
+	}
+}
+
+func TestGMPExtractionMetricsConcurrentRecording(t *testing.T) {
+	metrics := services.NewGMPExtractionMetrics()
+
+	runConcurrently(func(i int) {
+		metrics.RecordAttempt(false)
+		if i%2 == 0 {
+			metrics.RecordAttemptSucceeded()
+		}
+		metrics.RecordHTTPError()
+		metrics.RecordProcessingError()
+	}, func() {
+		counts := metrics.Snapshot()
+		if counts.SuccessfulParsed+counts.FailedParsed != counts.TotalAttempts {
+			t.Errorf("inconsistent snapshot: %+v", counts)
+		}
+		_ = metrics.GetSuccessRate()
+	})
+
+	total := metricsWorkers * metricsPerWorker
+	expected := services.GMPExtractionCounts{
+		TotalAttempts:    total,
+		SuccessfulParsed: total / 2,
+		FailedParsed:     total / 2,
+		HTTPErrors:       total,
+		ProcessingErrors: total,
+	}
+	if counts := metrics.Snapshot(); counts != expected {
+		t.Errorf("expected %+v, got %+v", expected, counts)
+	}
+	if rate := metrics.GetSuccessRate(); rate != 50.0 {
+		t.Errorf("expected 50%% success rate, got %.1f", rate)
+	}
+}
+
+func TestSharedExtractionMetricsConcurrentRecording(t *testing.T) {
+	metrics := shared.NewExtractionMetrics()
+	// LogSummary takes the read lock while writers wait; keep its output out of the test log
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.WarnLevel)
+	defer logrus.SetLevel(level)
+
+	runConcurrently(func(i int) {
+		metrics.RecordDescriptionAttempt(i%2 == 0)
+		metrics.RecordAboutAttempt(true)
+	}, func() {
+		_ = metrics.GetDescriptionSuccessRate()
+		metrics.LogSummary()
+	})
+
+	if rate := metrics.GetDescriptionSuccessRate(); rate != 50.0 {
+		t.Errorf("expected 50%% description success rate, got %.1f", rate)
+	}
+	if rate := metrics.GetAboutSuccessRate(); rate != 100.0 {
+		t.Errorf("expected 100%% about success rate, got %.1f", rate)
+	}
+}
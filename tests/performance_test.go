@@ -147,22 +147,15 @@ func TestSystemBehaviorUnderLoad(t *testing.T) {
 							}
 
 						case 3:
-							// IPO validation operation
-							testIPO := &models.IPO{
-								ID:          uuid.New(),
-								StockID:     fmt.Sprintf("TEST_%d_%d", userID, op),
-								Name:        fmt.Sprintf("TestCompany_%d_%d", userID, op),
-								CompanyCode: fmt.Sprintf("TC%d%d", userID, op),
-								Registrar:   "Test Registrar",
-								Status:      "UPCOMING",
+							// IPO status operation
+							openDate := time.Now().AddDate(0, 0, op%7-3)
+							closeDate := openDate.AddDate(0, 0, 3)
+							listingDate := closeDate.AddDate(0, 0, 3)
+							status := suite.utilityService.CalculateIPOStatus(models.DateOf(&openDate), models.DateOf(&closeDate), models.DateOf(&listingDate))
+							if status == "" {
+								errorChan <- fmt.Errorf("IPO status calculation failed for user %d, op %d", userID, op)
+								return
 							}
-
-							// validator := services.NewUnifiedValidator(suite.utilityService, suite.db)
-							// validationResult := validator.Validate(testIPO)
-							// if validationResult == nil {
-							//	errorChan <- fmt.Errorf("IPO validation failed for user %d, op %d", userID, op)
-							//	return
-							// }
 						}
 
 						// Record operation metrics
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+	"github.com/fenilmodi00/ipo-backend/shared"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Synthetic PII fed through the scrubbing pipelines; none of it may come out
+var syntheticPII = []string{"ABCPD1234K", "IN30012345678901", "IN300123", "1208160012345678", "7845123690", "10457890"}
+
+func assertNoPII(t *testing.T, where, text string) {
+	t.Helper()
+	for _, value := range syntheticPII {
+		if strings.Contains(strings.ToUpper(text), value) {
+			t.Errorf("%s leaked %s: %s", where, value, text)
+		}
+	}
+}
+
+// TestScrubPIIMasksIdentifiers checks the PAN, application number and demat ID masking
+func TestScrubPIIMasksIdentifiers(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"check failed for abcpd1234k", "check failed for AB*******K"},
+		{"DP ID IN30012345678901 not found", "DP ID **************01 not found"},
+		{"dp: IN300123", "dp: ******23"},
+		{"BO ID 1208160012345678", "BO ID **************78"},
+		{"Application No: 7845123690", "Application No: ********90"},
+		{`{"appl_no":"7845123690","clientid":"10457890"}`, `{"appl_no":"********90","clientid":"******90"}`},
+		{"<td>Application Number</td><td>7845123690</td>", "<td>Application Number</td><td>********90</td>"},
+		// Short numbers after a label, timestamps and plain words stay readable
+		{"application no 42 of 1700000000000 rows", "application no 42 of 1700000000000 rows"},
+		{"Application Number Required", "Application Number Required"},
+	}
+	for _, tc := range cases {
+		if got := shared.ScrubPII(tc.text); got != tc.expected {
+			t.Errorf("ScrubPII(%q) = %q, expected %q", tc.text, got, tc.expected)
+		}
+	}
+}
+
+// TestScrubErrorKeepsCause checks that a scrubbed error still unwraps to its cause
+func TestScrubErrorKeepsCause(t *testing.T) {
+	cause := &shared.HTTPStatusError{StatusCode: http.StatusTooManyRequests}
+	err := shared.ScrubError(fmt.Errorf("registrar rejected ABCPD1234K: %w", cause))
+	assertNoPII(t, "scrubbed error", err.Error())
+	if !errors.Is(err, shared.ErrRateLimited) || shared.ErrorCategoryOf(err) != shared.ErrorCategoryRateLimited {
+		t.Errorf("expected the scrubbed error to keep its rate limited cause, got %v", err)
+	}
+
+	plain := errors.New("registrar unreachable")
+	if shared.ScrubError(plain) != plain || shared.ScrubError(nil) != nil {
+		t.Error("expected errors without PII to be returned unchanged")
+	}
+}
+
+// TestPIIScrubbingFormatterRedactsLogOutput logs synthetic PII in messages and fields
+// through the text and JSON formatters
+func TestPIIScrubbingFormatterRedactsLogOutput(t *testing.T) {
+	for _, formatter := range []logrus.Formatter{&logrus.TextFormatter{DisableColors: true}, &logrus.JSONFormatter{}} {
+		var output bytes.Buffer
+		logger := logrus.New()
+		logger.SetOutput(&output)
+		logger.SetFormatter(formatter)
+		shared.ScrubLoggerPII(logger)
+		shared.ScrubLoggerPII(logger)
+
+		logger.WithFields(logrus.Fields{
+			"pan":     "ABCPD1234K",
+			"demat":   map[string]string{"dp_id": "IN30012345678901", "bo_id": "1208160012345678"},
+			"shares":  50,
+			"attempt": "IN300123",
+		}).WithError(errors.New("Application No: 7845123690 rejected")).
+			Infof("Checking ABCPD1234K with client id 10457890")
+
+		assertNoPII(t, fmt.Sprintf("%T output", formatter), output.String())
+		if !strings.Contains(output.String(), "AB*******K") || !strings.Contains(output.String(), "50") {
+			t.Errorf("%T: expected masked PAN and untouched fields, got %s", formatter, output.String())
+		}
+	}
+}
+
+// TestAllotmentCheckerScrubsRegistrarErrors sends a check to a registrar that fails with
+// the applicant's details in its error page
+func TestAllotmentCheckerScrubsRegistrarErrors(t *testing.T) {
+	registrar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "Error for PAN ABCPD1234K, Application No: 7845123690, DP ID IN30012345678901")
+	}))
+	defer registrar.Close()
+
+	var logs bytes.Buffer
+	previousOutput := logrus.StandardLogger().Out
+	logrus.SetOutput(&logs)
+	defer logrus.SetOutput(previousOutput)
+
+	formFields, _ := json.Marshal(map[string]string{"pan": "USER_INPUT"})
+	ipo := &models.IPO{
+		ID:           uuid.New(),
+		Name:         "Synthetic IPO",
+		Registrar:    "Synthetic Registrar",
+		FormURL:      &registrar.URL,
+		FormFields:   formFields,
+		FormHeaders:  json.RawMessage(`{}`),
+		ParserConfig: json.RawMessage(`{}`),
+	}
+
+	_, _, err := services.NewAllotmentChecker().CheckAllotmentStatus(t.Context(), ipo, "ABCPD1234K")
+	if err == nil {
+		t.Fatal("expected the registrar error")
+	}
+	assertNoPII(t, "checker error", err.Error())
+	assertNoPII(t, "checker logs", logs.String())
+	var statusErr *shared.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the registrar status to survive scrubbing, got %v", err)
+	}
+}
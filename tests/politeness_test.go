@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+func TestParsePolitenessWindows(t *testing.T) {
+	windows, err := shared.ParsePolitenessWindows("09:30-10:30, 23:00-01:00=slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	if got := windows[0].String(); got != "09:30-10:30=skip" {
+		t.Errorf("expected the mode to default to skip, got %s", got)
+	}
+	if got := windows[1].String(); got != "23:00-01:00=slow" {
+		t.Errorf("expected the slow window past midnight, got %s", got)
+	}
+
+	for _, spec := range []string{"09:30", "9:30-25:00", "09:30-10:30=pause", "10:00-10:00"} {
+		if _, err := shared.ParsePolitenessWindows(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestPolitenessScheduleActiveWindow(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	windows, err := shared.ParsePolitenessWindows("09:00-11:00=slow,09:30-10:30=skip,23:00-01:00=slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := shared.NewFakeClock(time.Date(2024, 1, 15, 9, 45, 0, 0, ist))
+	schedule := shared.NewPolitenessSchedule(windows)
+	schedule.Clock = clock
+
+	until, ok := schedule.SkipUntil()
+	if !ok || !until.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, ist)) {
+		t.Errorf("expected the skip window to win until 10:30, got %v %v", until, ok)
+	}
+
+	clock.Set(time.Date(2024, 1, 15, 10, 45, 0, 0, ist))
+	if _, ok := schedule.SkipUntil(); ok || !schedule.Slow() {
+		t.Error("expected only the slow window open at 10:45")
+	}
+
+	clock.Set(time.Date(2024, 1, 15, 23, 30, 0, 0, ist))
+	window, ends, ok := schedule.Active()
+	if !ok || window.Mode != shared.PolitenessSlow || !ends.Equal(time.Date(2024, 1, 16, 1, 0, 0, 0, ist)) {
+		t.Errorf("expected the overnight window to end at 01:00 the next day, got %v %v %v", window, ends, ok)
+	}
+
+	clock.Set(time.Date(2024, 1, 15, 12, 0, 0, 0, ist))
+	if _, _, ok := schedule.Active(); ok {
+		t.Error("expected no window open at noon")
+	}
+
+	var none *shared.PolitenessSchedule
+	if _, _, ok := none.Active(); ok {
+		t.Error("expected a nil schedule to have no windows")
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// memoryNonceStore redeems nonces in memory
+type memoryNonceStore struct {
+	mutex sync.Mutex
+	used  map[string]bool
+}
+
+func (s *memoryNonceStore) Redeem(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.used[nonce] {
+		return false, nil
+	}
+	s.used[nonce] = true
+	return true, nil
+}
+
+// TestRequestSignaturesRejectForgedAndReplayedRequests checks that only correctly signed,
+// fresh and unused requests reach the handler
+func TestRequestSignaturesRejectForgedAndReplayedRequests(t *testing.T) {
+	key := "client-secret"
+	signatures := middleware.NewRequestSignatures(map[string]string{"app": key}, &memoryNonceStore{used: map[string]bool{}})
+	app := fiber.New()
+	app.Post("/cache/store", signatures.Handler(), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals(middleware.RequestClientLocal).(string))
+	})
+
+	body := []byte(`{"status":"ALLOTTED"}`)
+	send := func(client, nonce string, signedAt time.Time, signedBody, sentBody []byte) int {
+		timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+		req := httptest.NewRequest(fiber.MethodPost, "/cache/store", bytes.NewReader(sentBody))
+		req.Header.Set(middleware.RequestClientHeader, client)
+		req.Header.Set(middleware.RequestTimestampHeader, timestamp)
+		req.Header.Set(middleware.RequestNonceHeader, nonce)
+		req.Header.Set(middleware.RequestSignatureHeader, middleware.RequestSignature([]byte(key), fiber.MethodPost, "/cache/store", timestamp, nonce, signedBody))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	now := time.Now()
+	if status := send("app", "nonce-0000000001", now, body, body); status != fiber.StatusOK {
+		t.Fatalf("expected a signed request to pass, got %d", status)
+	}
+	if status := send("app", "nonce-0000000001", now, body, body); status != fiber.StatusConflict {
+		t.Errorf("expected a replayed nonce to be rejected with 409, got %d", status)
+	}
+	if status := send("app", "nonce-0000000002", now, body, []byte(`{"status":"NOT_ALLOTTED"}`)); status != fiber.StatusForbidden {
+		t.Errorf("expected a changed body to be rejected with 403, got %d", status)
+	}
+	if status := send("app", "nonce-0000000003", now.Add(-10*time.Minute), body, body); status != fiber.StatusUnauthorized {
+		t.Errorf("expected a stale timestamp to be rejected with 401, got %d", status)
+	}
+	if status := send("other", "nonce-0000000004", now, body, body); status != fiber.StatusUnauthorized {
+		t.Errorf("expected an unknown client to be rejected with 401, got %d", status)
+	}
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestResponseCacheReplaysContentHeaders checks a cache hit keeps the headers describing
+// the content, such as Content-Disposition
+func TestResponseCacheReplaysContentHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Get("/calendar.ics", middleware.NewResponseCache(time.Minute).Handler(), func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `inline; filename="ipo-calendar.ics"`)
+		return c.SendString("BEGIN:VCALENDAR")
+	})
+
+	for _, want := range []string{"MISS", "HIT"} {
+		response, err := app.Test(httptest.NewRequest("GET", "/calendar.ics", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := response.Header.Get("X-Cache"); got != want {
+			t.Fatalf("expected X-Cache %s, got %s", want, got)
+		}
+		if got := response.Header.Get(fiber.HeaderContentDisposition); got != `inline; filename="ipo-calendar.ics"` {
+			t.Errorf("%s: expected the Content-Disposition header, got %q", want, got)
+		}
+	}
+}
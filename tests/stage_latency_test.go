@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/shared"
+)
+
+func TestStageTimerAddsRepeatedStagesInOrder(t *testing.T) {
+	timer := shared.NewStageTimer()
+	timer.Add("validation", 2*time.Millisecond)
+	timer.Add("parsing", time.Millisecond)
+	timer.Add("validation", 3*time.Millisecond)
+
+	stages := timer.Stages()
+	if len(stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(stages))
+	}
+	if stages[0].Stage != "validation" || stages[0].DurationMs != 5 {
+		t.Errorf("expected validation to add up to 5ms first, got %+v", stages[0])
+	}
+	if stages[1].Stage != "parsing" || stages[1].DurationMs != 1 {
+		t.Errorf("expected parsing 1ms second, got %+v", stages[1])
+	}
+}
+
+func TestNilStageTimerRecordsNothing(t *testing.T) {
+	var timer *shared.StageTimer
+	timer.Start("registrar_request")()
+	timer.Add("parsing", time.Millisecond)
+	if stages := timer.Stages(); stages != nil {
+		t.Errorf("expected no stages from a nil timer, got %v", stages)
+	}
+}
+
+func TestStageLatencyMetricsPercentiles(t *testing.T) {
+	metrics := shared.NewStageLatencyMetrics()
+	for i := 1; i <= 100; i++ {
+		metrics.Record([]shared.StageTiming{
+			{Stage: "cache_lookup", DurationMs: 1},
+			{Stage: "registrar_request", DurationMs: float64(i)},
+		})
+	}
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Stage != "cache_lookup" || snapshot[1].Stage != "registrar_request" {
+		t.Fatalf("expected stages in first-recorded order, got %+v", snapshot)
+	}
+	registrar := snapshot[1]
+	if registrar.Count != 100 || registrar.MaxMs != 100 || registrar.AverageMs != 50.5 {
+		t.Errorf("unexpected count, max or average: %+v", registrar)
+	}
+	if registrar.P50Ms != 50 || registrar.P95Ms != 95 || registrar.P99Ms != 99 {
+		t.Errorf("expected nearest-rank p50/p95/p99 of 50/95/99, got %v/%v/%v", registrar.P50Ms, registrar.P95Ms, registrar.P99Ms)
+	}
+}
+
+func TestStageLatencyMetricsKeepLatestSamples(t *testing.T) {
+	metrics := shared.NewStageLatencyMetrics()
+	for i := 0; i < 1000; i++ {
+		metrics.Record([]shared.StageTiming{{Stage: "queue_wait", DurationMs: 500}})
+	}
+	for i := 0; i < 1000; i++ {
+		metrics.Record([]shared.StageTiming{{Stage: "queue_wait", DurationMs: 1}})
+	}
+
+	stage := metrics.Snapshot()[0]
+	if stage.P99Ms != 1 {
+		t.Errorf("expected percentiles over the latest samples only, got p99 %v", stage.P99Ms)
+	}
+	if stage.Count != 2000 || stage.MaxMs != 500 {
+		t.Errorf("expected count and max over every sample, got %+v", stage)
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/jobs"
+)
+
+// TestStartupOrchestratorGatesOnRequiredPhases checks retries, optional failures and that
+// a failed or timed out required phase keeps the jobs from starting
+func TestStartupOrchestratorGatesOnRequiredPhases(t *testing.T) {
+	attempts := 0
+	startup := jobs.NewStartupOrchestrator(
+		jobs.StartupPhase{
+			Name: "database",
+			Run: func(ctx context.Context) error {
+				attempts++
+				if attempts < 3 {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			Timeout:    time.Second,
+			RetryEvery: time.Millisecond,
+			Required:   true,
+		},
+		jobs.StartupPhase{
+			Name:    "cache_warmup",
+			Run:     func(ctx context.Context) error { return errors.New("no IPOs") },
+			Timeout: time.Second,
+		},
+	)
+	if err := startup.Run(context.Background()); err != nil {
+		t.Fatalf("expected startup to pass, got %v", err)
+	}
+	report := startup.Report()
+	if !startup.Ready() || report[0].Attempts != 3 || report[0].Status != jobs.StartupPhaseOK || report[1].Status != jobs.StartupPhaseFailed {
+		t.Errorf("expected ready after 3 database attempts and a failed optional warmup, got %+v", report)
+	}
+
+	blocked := jobs.NewStartupOrchestrator(
+		jobs.StartupPhase{
+			Name: "schema",
+			Run: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+			Timeout:  10 * time.Millisecond,
+			Required: true,
+		},
+		jobs.StartupPhase{Name: "cache_warmup", Run: func(ctx context.Context) error { return nil }},
+	)
+	if err := blocked.Run(context.Background()); err == nil || blocked.Ready() {
+		t.Fatal("expected a timed out required phase to block startup")
+	}
+	if report := blocked.Report(); report[0].Status != jobs.StartupPhaseFailed || report[1].Status != jobs.StartupPhasePending {
+		t.Errorf("expected the schema phase failed and warmup never run, got %+v", report)
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+// TestPreviewTextCutsAtWordBoundary checks previews keep words whole and flag the cut
+func TestPreviewTextCutsAtWordBoundary(t *testing.T) {
+	text := "The company manufactures specialty chemicals for pharmaceutical customers"
+
+	preview, truncated := services.PreviewText(text, 30)
+	if !truncated || preview != "The company manufactures..." {
+		t.Errorf("expected a word-boundary preview, got %q (truncated %v)", preview, truncated)
+	}
+
+	if preview, truncated := services.PreviewText(text, len(text)); truncated || preview != text {
+		t.Errorf("expected text within the limit unchanged, got %q (truncated %v)", preview, truncated)
+	}
+	if preview, truncated := services.PreviewText(text, 0); truncated || preview != text {
+		t.Errorf("expected limit 0 to keep the full text, got %q (truncated %v)", preview, truncated)
+	}
+
+	// A cut inside a multi-byte character backs off to the character start
+	long := strings.Repeat("₹", 40)
+	preview, truncated = services.PreviewText(long, 10)
+	if !truncated || preview != strings.Repeat("₹", 3)+services.TruncationMarker {
+		t.Errorf("expected whole runes before the marker, got %q", preview)
+	}
+}
+
+// TestTextPreviewLimitsApplyFlagsTruncatedFields checks each text is cut to its own limit
+func TestTextPreviewLimitsApplyFlagsTruncatedFields(t *testing.T) {
+	description := strings.Repeat("word ", 10)
+	about := "Short about text"
+	ipo := &models.IPO{Description: &description, About: &about}
+
+	services.TextPreviewLimits{Description: 20, About: 100}.Apply(ipo)
+	if !ipo.DescriptionTruncated || len(*ipo.Description) > 20+len(services.TruncationMarker) {
+		t.Errorf("expected the description cut and flagged, got %q (truncated %v)", *ipo.Description, ipo.DescriptionTruncated)
+	}
+	if ipo.AboutTruncated || *ipo.About != about {
+		t.Errorf("expected the about text unchanged, got %q (truncated %v)", *ipo.About, ipo.AboutTruncated)
+	}
+	if description != strings.Repeat("word ", 10) {
+		t.Error("expected the stored text not to be modified")
+	}
+}
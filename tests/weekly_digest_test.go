@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fenilmodi00/ipo-backend/models"
+	"github.com/fenilmodi00/ipo-backend/services"
+)
+
+func digestDate(t *testing.T, value string) *models.Date {
+	t.Helper()
+	date, err := models.ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &date
+}
+
+func TestUpcomingWeekStartsNextMonday(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	cases := map[string]string{
+		"2024-01-14T19:00:00": "2024-01-15", // Sunday evening: the week starting tomorrow
+		"2024-01-15T09:00:00": "2024-01-22", // Monday: the next week, not this one
+		"2024-01-17T12:00:00": "2024-01-22",
+	}
+	for now, want := range cases {
+		at, err := time.ParseInLocation("2006-01-02T15:04:05", now, ist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start, end := services.UpcomingWeek(at)
+		if start.String() != want {
+			t.Errorf("%s: expected week starting %s, got %s", now, want, start)
+		}
+		if end.Sub(start.Time) != 6*24*time.Hour {
+			t.Errorf("%s: expected the week to end on Sunday, got %s", now, end)
+		}
+	}
+}
+
+func TestBuildWeeklyDigestSortsIPOsIntoWeekLists(t *testing.T) {
+	start := *digestDate(t, "2024-01-15")
+	ipos := []models.DigestIPO{
+		{Name: "Zeta", OpenDate: digestDate(t, "2024-01-16"), CloseDate: digestDate(t, "2024-01-18"), ListingDate: digestDate(t, "2024-01-23")},
+		{Name: "Alpha", OpenDate: digestDate(t, "2024-01-16"), CloseDate: digestDate(t, "2024-01-22")},
+		{Name: "Beta", OpenDate: digestDate(t, "2024-01-08"), CloseDate: digestDate(t, "2024-01-10"), ListingDate: digestDate(t, "2024-01-15")},
+		{Name: "Gamma", OpenDate: digestDate(t, "2024-01-29")},
+	}
+
+	digest := services.BuildWeeklyDigest(start, ipos)
+	names := func(list []models.DigestIPO) string {
+		var result []string
+		for _, ipo := range list {
+			result = append(result, ipo.Name)
+		}
+		return strings.Join(result, ",")
+	}
+	if got := names(digest.Opening); got != "Alpha,Zeta" {
+		t.Errorf("expected Alpha and Zeta opening by date then name, got %q", got)
+	}
+	if got := names(digest.Closing); got != "Zeta" {
+		t.Errorf("expected only Zeta closing in the week, got %q", got)
+	}
+	if got := names(digest.Listing); got != "Beta" {
+		t.Errorf("expected Beta listing on the Monday, got %q", got)
+	}
+	if digest.WeekEnd.String() != "2024-01-21" {
+		t.Errorf("expected the week to end on 2024-01-21, got %s", digest.WeekEnd)
+	}
+}
+
+func TestRenderWeeklyDigestEmail(t *testing.T) {
+	low, high, gmp, gain := 100.0, 110.0, 25.0, 22.7
+	size := "500 Cr"
+	digest := services.BuildWeeklyDigest(*digestDate(t, "2024-01-15"), []models.DigestIPO{{
+		Name: "Example Tech", OpenDate: digestDate(t, "2024-01-16"),
+		PriceBandLow: &low, PriceBandHigh: &high, IssueSize: &size, GMPValue: &gmp, GainPercent: &gain,
+	}})
+
+	subject, body := services.RenderWeeklyDigestEmail(digest)
+	if subject != "IPO week ahead: 15 Jan to 21 Jan 2024" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	want := "- Example Tech, Tue 16 Jan, price band Rs 100-110, issue size 500 Cr, GMP Rs 25 (+22.7%)"
+	if !strings.Contains(body, "Opening\n"+want+"\n") {
+		t.Errorf("expected the opening IPO line %q in body:\n%s", want, body)
+	}
+	if strings.Contains(body, "Closing") || strings.Contains(body, "Listing") {
+		t.Errorf("expected empty sections to be left out, got:\n%s", body)
+	}
+}